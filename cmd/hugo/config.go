@@ -0,0 +1,225 @@
+package hugo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/audit"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/health"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/logging"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/output"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tracing"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// configSections are the config file keys holding maps of their own, rather
+// than the value of a flag
+var configSections = []string{"auth", "sites"}
+
+// configCmd groups the commands that inspect the configuration
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+	Long: `Inspect the configuration the server loads from flags, environment
+variables and the config file.`,
+}
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration without starting the server",
+	Long: `Load the configuration as the server does and report every invalid
+setting, such as malformed durations, negative limits or unknown tool
+names, along with config file keys the server does not use. Exits with an
+error when any setting is invalid.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := outputFormat(cmd)
+		if err != nil {
+			return err
+		}
+
+		problems := validateConfig()
+		result := map[string]interface{}{
+			"valid":        len(problems) == 0,
+			"config_file":  viper.ConfigFileUsed(),
+			"errors":       problems,
+			"unknown_keys": unknownConfigKeys(),
+		}
+		if err := output.Render(cmd.OutOrStdout(), format, result); err != nil {
+			return err
+		}
+		if len(problems) > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("configuration has %d invalid setting(s)", len(problems))
+		}
+		return nil
+	},
+}
+
+// configShowCmd represents the config show command
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration with secrets redacted",
+	Long: `Print the configuration the server would run with, merged from flag
+defaults, the config file and environment variables. Passwords, tokens,
+custom headers, cookies, URL passwords and the webhook URL are redacted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := outputFormat(cmd)
+		if err != nil {
+			return err
+		}
+		return output.Render(cmd.OutOrStdout(), format, audit.Redact(viper.AllSettings()))
+	},
+}
+
+func init() {
+	addOutputFlag(configValidateCmd)
+	addOutputFlag(configShowCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// validateConfig loads the configuration as runServer does, returning every
+// problem found rather than stopping at the first
+func validateConfig() []string {
+	problems := []string{}
+	check := func(err error) {
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	var notFound viper.ConfigFileNotFoundError
+	if err := viper.ReadInConfig(); err != nil && !errors.As(err, &notFound) {
+		check(fmt.Errorf("invalid config file: %w", err))
+	}
+
+	// Flags are read with viper's lenient getters, which turn values of the
+	// wrong type into zero
+	rootCmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Name != "config" {
+			check(checkConfigType(configKey(flag.Name), flag.Value.Type()))
+		}
+	})
+	if _, err := logging.ParseLevel(viper.GetString("log_level")); err != nil {
+		check(fmt.Errorf("invalid log_level: %w", err))
+	}
+	if timeout, err := strconv.Atoi(viper.GetString("http_timeout")); err != nil || timeout <= 0 {
+		check(fmt.Errorf("invalid http_timeout: %s (must be a positive number of seconds)", viper.GetString("http_timeout")))
+	}
+
+	logger := slog.New(slog.DiscardHandler)
+	cacheInstance, err := cache.FromConfig(cache.WithLogger(logger))
+	check(err)
+	_, err = cache.JanitorInterval()
+	check(err)
+	for _, tool := range []string{"search", "content", "discovery", "taxonomies"} {
+		_, err = cache.ToolTTL(tool, 0)
+		check(err)
+	}
+	httpClient, err := httpclient.FromConfig(httpclient.WithLogger(logger))
+	check(err)
+	siteRegistry, err := sites.FromConfig()
+	check(err)
+
+	tracer, err := tracing.FromConfig(tracing.WithLogger(logger))
+	check(err)
+	if tracer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		tracer.Shutdown(ctx)
+		cancel()
+	}
+	if addr := viper.GetString("metrics_addr"); addr != "" {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			check(fmt.Errorf("invalid metrics_addr: %s", addr))
+		}
+	}
+	_, _, _, err = watchConfig()
+	check(err)
+	_, _, err = resourceConfig()
+	check(err)
+
+	// Creating the tools checks the rest, such as the names of disabled
+	// tools, once what they depend on is valid
+	if len(problems) == 0 {
+		check(registerTools(mcp_golang.NewServer(nil), logger, &slog.LevelVar{}, cacheInstance, httpClient, siteRegistry, health.NewRecorder(), telemetry{}))
+	}
+	return problems
+}
+
+// checkConfigType reports a setting whose value is not of the type of its
+// flag
+func checkConfigType(key, flagType string) error {
+	value := viper.Get(key)
+	if value == nil {
+		return nil
+	}
+	text := strings.TrimSpace(fmt.Sprint(value))
+
+	var err error
+	var want string
+	switch flagType {
+	case "bool":
+		_, err = strconv.ParseBool(text)
+		want = "true or false"
+	case "int", "int64":
+		_, err = strconv.ParseInt(text, 10, 64)
+		want = "a whole number"
+	case "float64":
+		_, err = strconv.ParseFloat(text, 64)
+		want = "a number"
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s: %v (must be %s)", key, value, want)
+	}
+	return nil
+}
+
+// unknownConfigKeys returns the keys set in the config file that are neither
+// flags nor config sections, such as misspelled settings, sorted
+func unknownConfigKeys() []string {
+	known := make(map[string]bool)
+	rootCmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		known[configKey(flag.Name)] = true
+	})
+
+	unknown := []string{}
+	for _, key := range viper.AllKeys() {
+		if known[key] || inConfigSection(key) {
+			continue
+		}
+		unknown = append(unknown, key)
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// inConfigSection reports whether key is within one of configSections
+func inConfigSection(key string) bool {
+	for _, section := range configSections {
+		if key == section || strings.HasPrefix(key, section+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// configKey returns the viper key a flag is bound to
+func configKey(flagName string) string {
+	return strings.ReplaceAll(flagName, "-", "_")
+}
@@ -0,0 +1,21 @@
+package hugo
+
+import (
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// addOutputFlag adds the --output flag to a direct command, which writes
+// its results to stdout rather than serving MCP
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", string(output.Table), "output format (json, table, yaml)")
+}
+
+// outputFormat returns the format selected by a command's --output flag
+func outputFormat(cmd *cobra.Command) (output.Format, error) {
+	value, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return "", err
+	}
+	return output.ParseFormat(value)
+}
@@ -35,12 +35,96 @@ func init() {
 	rootCmd.PersistentFlags().String("server-name", "hugo-reader", "server name")
 	rootCmd.PersistentFlags().String("http-timeout", "10", "HTTP timeout in seconds")
 	rootCmd.PersistentFlags().String("user-agent", "HugoReader/1.0.0", "User Agent string for HTTP requests")
+	rootCmd.PersistentFlags().String("proxy", "", "proxy for all outbound requests, such as http://proxy:3128 or socks5://proxy:1080 (default: HTTP_PROXY, HTTPS_PROXY and NO_PROXY)")
+	rootCmd.PersistentFlags().String("tls-ca-file", "", "PEM bundle of CA certificates trusted in addition to the system roots")
+	rootCmd.PersistentFlags().String("tls-cert-file", "", "PEM client certificate presented to sites that require mutual TLS")
+	rootCmd.PersistentFlags().String("tls-key-file", "", "PEM private key of the client certificate")
+	rootCmd.PersistentFlags().Bool("tls-insecure-skip-verify", false, "disable verification of site certificates (insecure)")
+	rootCmd.PersistentFlags().String("redirect-policy", "any", "which redirects are followed: any, same-host (including http to https) or none")
+	rootCmd.PersistentFlags().Int("max-redirects", 10, "maximum redirects followed by a single request (0 to follow none)")
+	rootCmd.PersistentFlags().Bool("block-private-addresses", true, "refuse requests to loopback, private and link-local addresses, such as cloud metadata services")
+	rootCmd.PersistentFlags().String("allowed-hosts", "", "comma-separated host names, IP addresses or CIDR ranges exempt from block-private-addresses")
+	rootCmd.PersistentFlags().Int64("max-response-size", 50*1024*1024, "maximum size of a fetched resource in bytes after decompression (0 for unlimited)")
+	rootCmd.PersistentFlags().String("cache-ttl", "5m", "how long cached responses without caching headers are served by default")
+	rootCmd.PersistentFlags().String("cache-ttl-search", "2m", "how long the search tool serves cached responses (0 for the default cache TTL)")
+	rootCmd.PersistentFlags().String("cache-ttl-content", "5m", "how long the content tool serves cached responses (0 for the default cache TTL)")
+	rootCmd.PersistentFlags().String("cache-ttl-discovery", "10m", "how long the discovery tool serves cached responses (0 for the default cache TTL)")
+	rootCmd.PersistentFlags().String("cache-ttl-taxonomies", "5m", "how long the taxonomies and terms tools serve cached responses (0 for the default cache TTL)")
+	rootCmd.PersistentFlags().Int("cache-max-entries", 1000, "maximum number of cached responses (0 for unlimited)")
+	rootCmd.PersistentFlags().Int("cache-max-bytes", 50*1024*1024, "maximum total size of cached responses in bytes (0 for unlimited)")
+	rootCmd.PersistentFlags().Int("cache-compress-threshold", 64*1024, "size in bytes from which cached responses are stored gzip-compressed (0 to disable)")
+	rootCmd.PersistentFlags().String("cache-export-dir", "", "directory the cache manager's export and import actions keep their files in (default: hugo-reader/exports in the user cache directory)")
+	rootCmd.PersistentFlags().Bool("persist-indexes", true, "keep the search indexes built from sitemaps on disk, so later sessions only read the pages that changed")
+	rootCmd.PersistentFlags().String("index-dir", "", "directory search indexes built from sitemaps are kept in (default: hugo-reader/indexes in the user cache directory)")
+	rootCmd.PersistentFlags().String("cache-janitor-interval", "1m", "how often expired cache entries are removed (0 to disable)")
+	rootCmd.PersistentFlags().Bool("cache-ignore-headers", false, "ignore Cache-Control and Expires headers and always use the default cache TTL")
+	rootCmd.PersistentFlags().String("cache-negative-ttl", "1m", "how long resources that returned 404 or 410 are remembered (0 to disable)")
+	rootCmd.PersistentFlags().String("cache-endpoint-failure-ttl", "10m", "how long a site endpoint found missing is skipped (0 to always retry)")
+	rootCmd.PersistentFlags().Bool("respect-robots", true, "honor robots.txt Disallow rules and Crawl-delay of the sites fetched")
+	rootCmd.PersistentFlags().String("crawl-delay", "", "delay between requests to a host, replacing robots.txt Crawl-delay (0 to ignore it)")
+	rootCmd.PersistentFlags().Float64("rate-limit", 10, "maximum requests per second to each host (0 for unlimited)")
+	rootCmd.PersistentFlags().Int("rate-burst", 10, "requests to a host that may be made at once before rate-limit applies")
+	rootCmd.PersistentFlags().Int("request-budget", 2000, "maximum HTTP requests a single tool call may make (0 for unlimited)")
+	rootCmd.PersistentFlags().String("resource-sites", "", "comma-separated sites whose pages are exposed as MCP resources")
+	rootCmd.PersistentFlags().Int("resource-max-pages", 500, "maximum pages of each resource site to expose (0 for unlimited)")
+	rootCmd.PersistentFlags().String("default-site", "", "alias or URL of the site tools use when a request names none")
+	rootCmd.PersistentFlags().String("taxonomies", "", "comma-separated taxonomy names probed for when a site publishes no taxonomy list (default: categories, tags, themes, methods, authors, series, topics)")
+	rootCmd.PersistentFlags().String("metrics-addr", "", "address to serve Prometheus metrics on, such as :9090 (disabled when empty)")
+	rootCmd.PersistentFlags().String("otlp-endpoint", "", "OTLP/HTTP collector to export traces to, such as http://localhost:4318 (disabled when empty)")
+	rootCmd.PersistentFlags().String("webhook-url", "", "URL to POST change notifications of watched sites to (disabled when empty)")
+	rootCmd.PersistentFlags().String("watch-interval", "15m", "how often watched sites are checked for changes")
+	rootCmd.PersistentFlags().String("watch-sites", "", "comma-separated aliases or URLs of the sites watched for changes (default: every registered site)")
+	rootCmd.PersistentFlags().String("audit-log", "", "file to append a JSON line to for every tool call, with secrets redacted (disabled when empty)")
+	rootCmd.PersistentFlags().String("disabled-tools", "", "comma-separated names of tools not to register, such as hugo_reader_check_links")
+	rootCmd.PersistentFlags().Bool("wayback", false, "register hugo_reader_page_history, which looks up past versions of pages on the Internet Archive's Wayback Machine")
 
 	// Bind flags to viper
 	viper.BindPFlag("log_level", rootCmd.PersistentFlags().Lookup("log-level"))
 	viper.BindPFlag("server_name", rootCmd.PersistentFlags().Lookup("server-name"))
 	viper.BindPFlag("http_timeout", rootCmd.PersistentFlags().Lookup("http-timeout"))
 	viper.BindPFlag("user_agent", rootCmd.PersistentFlags().Lookup("user-agent"))
+	viper.BindPFlag("proxy", rootCmd.PersistentFlags().Lookup("proxy"))
+	viper.BindPFlag("tls_ca_file", rootCmd.PersistentFlags().Lookup("tls-ca-file"))
+	viper.BindPFlag("tls_cert_file", rootCmd.PersistentFlags().Lookup("tls-cert-file"))
+	viper.BindPFlag("tls_key_file", rootCmd.PersistentFlags().Lookup("tls-key-file"))
+	viper.BindPFlag("tls_insecure_skip_verify", rootCmd.PersistentFlags().Lookup("tls-insecure-skip-verify"))
+	viper.BindPFlag("redirect_policy", rootCmd.PersistentFlags().Lookup("redirect-policy"))
+	viper.BindPFlag("max_redirects", rootCmd.PersistentFlags().Lookup("max-redirects"))
+	viper.BindPFlag("block_private_addresses", rootCmd.PersistentFlags().Lookup("block-private-addresses"))
+	viper.BindPFlag("allowed_hosts", rootCmd.PersistentFlags().Lookup("allowed-hosts"))
+	viper.BindPFlag("max_response_size", rootCmd.PersistentFlags().Lookup("max-response-size"))
+	viper.BindPFlag("cache_ttl", rootCmd.PersistentFlags().Lookup("cache-ttl"))
+	viper.BindPFlag("cache_ttl_search", rootCmd.PersistentFlags().Lookup("cache-ttl-search"))
+	viper.BindPFlag("cache_ttl_content", rootCmd.PersistentFlags().Lookup("cache-ttl-content"))
+	viper.BindPFlag("cache_ttl_discovery", rootCmd.PersistentFlags().Lookup("cache-ttl-discovery"))
+	viper.BindPFlag("cache_ttl_taxonomies", rootCmd.PersistentFlags().Lookup("cache-ttl-taxonomies"))
+	viper.BindPFlag("cache_max_entries", rootCmd.PersistentFlags().Lookup("cache-max-entries"))
+	viper.BindPFlag("cache_max_bytes", rootCmd.PersistentFlags().Lookup("cache-max-bytes"))
+	viper.BindPFlag("cache_compress_threshold", rootCmd.PersistentFlags().Lookup("cache-compress-threshold"))
+	viper.BindPFlag("cache_export_dir", rootCmd.PersistentFlags().Lookup("cache-export-dir"))
+	viper.BindPFlag("persist_indexes", rootCmd.PersistentFlags().Lookup("persist-indexes"))
+	viper.BindPFlag("index_dir", rootCmd.PersistentFlags().Lookup("index-dir"))
+	viper.BindPFlag("cache_janitor_interval", rootCmd.PersistentFlags().Lookup("cache-janitor-interval"))
+	viper.BindPFlag("cache_ignore_headers", rootCmd.PersistentFlags().Lookup("cache-ignore-headers"))
+	viper.BindPFlag("cache_negative_ttl", rootCmd.PersistentFlags().Lookup("cache-negative-ttl"))
+	viper.BindPFlag("cache_endpoint_failure_ttl", rootCmd.PersistentFlags().Lookup("cache-endpoint-failure-ttl"))
+	viper.BindPFlag("respect_robots", rootCmd.PersistentFlags().Lookup("respect-robots"))
+	viper.BindPFlag("crawl_delay", rootCmd.PersistentFlags().Lookup("crawl-delay"))
+	viper.BindPFlag("rate_limit", rootCmd.PersistentFlags().Lookup("rate-limit"))
+	viper.BindPFlag("rate_burst", rootCmd.PersistentFlags().Lookup("rate-burst"))
+	viper.BindPFlag("request_budget", rootCmd.PersistentFlags().Lookup("request-budget"))
+	viper.BindPFlag("resource_sites", rootCmd.PersistentFlags().Lookup("resource-sites"))
+	viper.BindPFlag("resource_max_pages", rootCmd.PersistentFlags().Lookup("resource-max-pages"))
+	viper.BindPFlag("default_site", rootCmd.PersistentFlags().Lookup("default-site"))
+	viper.BindPFlag("taxonomies", rootCmd.PersistentFlags().Lookup("taxonomies"))
+	viper.BindPFlag("metrics_addr", rootCmd.PersistentFlags().Lookup("metrics-addr"))
+	viper.BindPFlag("otlp_endpoint", rootCmd.PersistentFlags().Lookup("otlp-endpoint"))
+	viper.BindPFlag("audit_log", rootCmd.PersistentFlags().Lookup("audit-log"))
+	viper.BindPFlag("disabled_tools", rootCmd.PersistentFlags().Lookup("disabled-tools"))
+	viper.BindPFlag("wayback", rootCmd.PersistentFlags().Lookup("wayback"))
+	viper.BindPFlag("webhook_url", rootCmd.PersistentFlags().Lookup("webhook-url"))
+	viper.BindPFlag("watch_interval", rootCmd.PersistentFlags().Lookup("watch-interval"))
+	viper.BindPFlag("watch_sites", rootCmd.PersistentFlags().Lookup("watch-sites"))
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -1,26 +1,75 @@
 package hugo
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	mcp_golang "github.com/metoro-io/mcp-golang"
 	"github.com/metoro-io/mcp-golang/transport/stdio"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/audit"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/generic"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/health"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/logging"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/metrics"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/prompts"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/resources"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/archive"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/assets"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/benchmark"
 	cachetools "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/changes"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/codeblocks"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/compare"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/content"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/cooccurrence"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/discovery"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/freshness"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/graph"
+	healthtools "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/health"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/info"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/linkcheck"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/links"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/loglevel"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/metadata"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/probe"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/search"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/section"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/series"
+	sitetools "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/stats"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/structured"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/taxonomies"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/terms"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/wayback"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tracing"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+// resourceTimeout bounds how long registering one site's pages may take
+const resourceTimeout = 2 * time.Minute
+
+// metricsShutdownTimeout bounds how long the metrics listener may take to stop
+const metricsShutdownTimeout = 5 * time.Second
+
+// tracingShutdownTimeout bounds how long exporting the last spans may take
+const tracingShutdownTimeout = 5 * time.Second
+
 var serverCmd = &cobra.Command{
 	Use:   "server",
 	Short: "Start the MCP server",
@@ -34,8 +83,52 @@ func init() {
 }
 
 func runServer(cmd *cobra.Command, args []string) error {
-	// Create a logger
-	logger := logging.New()
+	// Create a logger whose level can be changed while the server runs
+	logLevel := &slog.LevelVar{}
+	logger := logging.New(logLevel)
+
+	// Start the uptime clock and tool call record reported by the health tool
+	recorder := health.NewRecorder()
+	observer := toolObservers{recorder}
+
+	// Collect metrics of tool calls, fetches and the cache when a metrics
+	// listener is configured
+	cacheOpts := []cache.CacheOption{cache.WithLogger(logger)}
+	clientOpts := []httpclient.Option{httpclient.WithLogger(logger)}
+	metricsAddr := viper.GetString("metrics_addr")
+	var metricsCollector *metrics.Metrics
+	if metricsAddr != "" {
+		metricsCollector = metrics.New()
+		observer = append(observer, metricsCollector)
+		cacheOpts = append(cacheOpts, cache.WithEndpointObserver(metricsCollector.ObserveEndpointFailure))
+		clientOpts = append(clientOpts, httpclient.WithObserver(metricsCollector))
+	}
+
+	// Trace tool calls and their fetches when an OTLP endpoint is configured
+	tracer, err := tracing.FromConfig(tracing.WithLogger(logger), tracing.WithServiceVersion(build().Version))
+	if err != nil {
+		logger.Error("Failed to configure tracing", "error", err)
+		return err
+	}
+	if tracer != nil {
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+			defer cancel()
+			tracer.Shutdown(ctx)
+		}()
+	}
+
+	// Append every tool call to the audit log when one is configured
+	var auditLog *audit.Logger
+	if path := viper.GetString("audit_log"); path != "" {
+		auditLog, err = audit.Open(path, logger)
+		if err != nil {
+			logger.Error("Failed to open audit log", "error", err)
+			return err
+		}
+		defer auditLog.Close()
+	}
+	tel := telemetry{observer: observer, tracer: tracer, audit: auditLog}
 
 	// Create a channel to listen for OS signals
 	sigChan := make(chan os.Signal, 1)
@@ -49,14 +142,74 @@ func runServer(cmd *cobra.Command, args []string) error {
 	server := mcp_golang.NewServer(transport)
 
 	// Create shared cache instance
-	cacheInstance := cache.New(cache.WithLogger(logger))
+	cacheInstance, err := cache.FromConfig(cacheOpts...)
+	if err != nil {
+		logger.Error("Failed to create cache", "error", err)
+		return err
+	}
+
+	// Sweep expired entries in the background
+	janitorInterval, err := cache.JanitorInterval()
+	if err != nil {
+		logger.Error("Failed to configure cache janitor", "error", err)
+		return err
+	}
+	if janitorInterval > 0 {
+		stopJanitor := cacheInstance.StartJanitor(janitorInterval)
+		defer stopJanitor()
+	}
+
+	// Create shared HTTP client so all tools reuse one connection pool
+	httpClient, err := httpclient.FromConfig(clientOpts...)
+	if err != nil {
+		logger.Error("Failed to create HTTP client", "error", err)
+		return err
+	}
+
+	// Serve metrics for scraping; the MCP protocol keeps stdio to itself
+	if metricsCollector != nil {
+		metricsCollector.SetCache(cacheInstance)
+		stopMetrics, err := serveMetrics(metricsCollector, metricsAddr, logger)
+		if err != nil {
+			logger.Error("Failed to start metrics listener", "error", err)
+			return err
+		}
+		defer stopMetrics()
+	}
+
+	// Create the site registry, seeded with the configured site aliases
+	siteRegistry, err := sites.FromConfig()
+	if err != nil {
+		logger.Error("Failed to load sites", "error", err)
+		return err
+	}
 
 	// Register all tools
-	if err := registerTools(server, logger, cacheInstance); err != nil {
+	if err := registerTools(server, logger, logLevel, cacheInstance, httpClient, siteRegistry, recorder, tel); err != nil {
 		logger.Error("Failed to register tools", "error", err)
 		return err
 	}
 
+	// Register the canned exploration workflows
+	if err := prompts.Register(server); err != nil {
+		logger.Error("Failed to register prompts", "error", err)
+		return err
+	}
+
+	// Expose the pages of configured sites as resources
+	if err := registerResources(server, logger, cacheInstance, httpClient); err != nil {
+		logger.Error("Failed to register resources", "error", err)
+		return err
+	}
+
+	// Post the changes of watched sites to a webhook when one is configured
+	stopPoller, err := startChangePoller(logger, cacheInstance, httpClient, siteRegistry, clientOpts)
+	if err != nil {
+		logger.Error("Failed to start change poller", "error", err)
+		return err
+	}
+	defer stopPoller()
+
 	logger.Info("Server starting with all tools registered")
 
 	// Start server in a goroutine
@@ -88,11 +241,34 @@ func runServer(cmd *cobra.Command, args []string) error {
 }
 
 // registerTools registers all available tools with the MCP server
-func registerTools(server *mcp_golang.Server, logger *slog.Logger, cacheInstance *cache.Cache) error {
+func registerTools(server *mcp_golang.Server, logger *slog.Logger, logLevel *slog.LevelVar, cacheInstance *cache.Cache, httpClient *httpclient.Client, siteRegistry *sites.Registry, recorder *health.Recorder, tel telemetry) error {
+	// Tools serve cached responses for as long as their data stays current;
+	// the terms tool shares the taxonomies TTL
+	searchTTL, err := cache.ToolTTL("search", search.DefaultCacheTTL)
+	if err != nil {
+		return err
+	}
+	contentTTL, err := cache.ToolTTL("content", content.DefaultCacheTTL)
+	if err != nil {
+		return err
+	}
+	discoveryTTL, err := cache.ToolTTL("discovery", discovery.DefaultCacheTTL)
+	if err != nil {
+		return err
+	}
+	taxonomiesTTL, err := cache.ToolTTL("taxonomies", taxonomies.DefaultCacheTTL)
+	if err != nil {
+		return err
+	}
+
 	// Create tool instances
 	taxonomiesTool, err := taxonomies.New(
 		taxonomies.WithLogger(logger),
 		taxonomies.WithCache(cacheInstance),
+		taxonomies.WithTTL(taxonomiesTTL),
+		taxonomies.WithHTTPClient(httpClient),
+		taxonomies.WithSites(siteRegistry),
+		taxonomies.WithCandidates(taxonomies.Candidates(viper.GetString("taxonomies"))),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create taxonomies tool: %w", err)
@@ -101,23 +277,60 @@ func registerTools(server *mcp_golang.Server, logger *slog.Logger, cacheInstance
 	termsTool, err := terms.New(
 		terms.WithLogger(logger),
 		terms.WithCache(cacheInstance),
+		terms.WithTTL(taxonomiesTTL),
+		terms.WithHTTPClient(httpClient),
+		terms.WithSites(siteRegistry),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create terms tool: %w", err)
 	}
 
+	cooccurrenceTool, err := cooccurrence.New(
+		cooccurrence.WithLogger(logger),
+		cooccurrence.WithCache(cacheInstance),
+		cooccurrence.WithHTTPClient(httpClient),
+		cooccurrence.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create term co-occurrence tool: %w", err)
+	}
+
 	contentTool, err := content.New(
 		content.WithLogger(logger),
 		content.WithCache(cacheInstance),
+		content.WithTTL(contentTTL),
+		content.WithHTTPClient(httpClient),
+		content.WithSites(siteRegistry),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create content tool: %w", err)
 	}
 
-	searchTool, err := search.New(
+	metadataTool, err := metadata.New(
+		metadata.WithLogger(logger),
+		metadata.WithCache(cacheInstance),
+		metadata.WithHTTPClient(httpClient),
+		metadata.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create metadata tool: %w", err)
+	}
+
+	searchOptions := []search.ToolOption{
 		search.WithLogger(logger),
 		search.WithCache(cacheInstance),
-	)
+		search.WithTTL(searchTTL),
+		search.WithHTTPClient(httpClient),
+		search.WithSites(siteRegistry),
+	}
+	if viper.GetBool("persist_indexes") {
+		indexDir := viper.GetString("index_dir")
+		if indexDir == "" {
+			indexDir = generic.DefaultStoreDir()
+		}
+		searchOptions = append(searchOptions, search.WithIndexStore(generic.NewStore(indexDir)))
+	}
+	searchTool, err := search.New(searchOptions...)
 	if err != nil {
 		return fmt.Errorf("failed to create search tool: %w", err)
 	}
@@ -125,6 +338,8 @@ func registerTools(server *mcp_golang.Server, logger *slog.Logger, cacheInstance
 	cacheTool, err := cachetools.New(
 		cacheInstance,
 		cachetools.WithLogger(logger),
+		cachetools.WithHTTPClient(httpClient),
+		cachetools.WithExportDir(viper.GetString("cache_export_dir")),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create cache tool: %w", err)
@@ -133,102 +348,444 @@ func registerTools(server *mcp_golang.Server, logger *slog.Logger, cacheInstance
 	discoveryTool, err := discovery.New(
 		discovery.WithLogger(logger),
 		discovery.WithCache(cacheInstance),
+		discovery.WithTTL(discoveryTTL),
+		discovery.WithHTTPClient(httpClient),
+		discovery.WithSites(siteRegistry),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create discovery tool: %w", err)
 	}
 
+	sectionTool, err := section.New(
+		section.WithLogger(logger),
+		section.WithCache(cacheInstance),
+		section.WithHTTPClient(httpClient),
+		section.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create section tool: %w", err)
+	}
+
+	seriesTool, err := series.New(
+		series.WithLogger(logger),
+		series.WithCache(cacheInstance),
+		series.WithHTTPClient(httpClient),
+		series.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create series tool: %w", err)
+	}
+
+	archiveTool, err := archive.New(
+		archive.WithLogger(logger),
+		archive.WithCache(cacheInstance),
+		archive.WithHTTPClient(httpClient),
+		archive.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create archive tool: %w", err)
+	}
+
+	statsTool, err := stats.New(
+		stats.WithLogger(logger),
+		stats.WithCache(cacheInstance),
+		stats.WithHTTPClient(httpClient),
+		stats.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create site statistics tool: %w", err)
+	}
+
+	linksTool, err := links.New(
+		links.WithLogger(logger),
+		links.WithCache(cacheInstance),
+		links.WithHTTPClient(httpClient),
+		links.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create links tool: %w", err)
+	}
+
+	structuredDataTool, err := structured.New(
+		structured.WithLogger(logger),
+		structured.WithCache(cacheInstance),
+		structured.WithHTTPClient(httpClient),
+		structured.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create structured data tool: %w", err)
+	}
+
+	codeTool, err := codeblocks.New(
+		codeblocks.WithLogger(logger),
+		codeblocks.WithCache(cacheInstance),
+		codeblocks.WithHTTPClient(httpClient),
+		codeblocks.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create code blocks tool: %w", err)
+	}
+
+	assetsTool, err := assets.New(
+		assets.WithLogger(logger),
+		assets.WithCache(cacheInstance),
+		assets.WithHTTPClient(httpClient),
+		assets.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create assets tool: %w", err)
+	}
+
+	graphTool, err := graph.New(
+		graph.WithLogger(logger),
+		graph.WithCache(cacheInstance),
+		graph.WithHTTPClient(httpClient),
+		graph.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create graph tool: %w", err)
+	}
+
+	linkCheckTool, err := linkcheck.New(
+		linkcheck.WithLogger(logger),
+		linkcheck.WithCache(cacheInstance),
+		linkcheck.WithHTTPClient(httpClient),
+		linkcheck.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create link check tool: %w", err)
+	}
+
+	changesTool, err := changes.New(
+		changes.WithLogger(logger),
+		changes.WithCache(cacheInstance),
+		changes.WithHTTPClient(httpClient),
+		changes.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create changes tool: %w", err)
+	}
+
+	freshnessTool, err := freshness.New(
+		freshness.WithLogger(logger),
+		freshness.WithCache(cacheInstance),
+		freshness.WithHTTPClient(httpClient),
+		freshness.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create freshness tool: %w", err)
+	}
+
+	compareTool, err := compare.New(
+		compare.WithLogger(logger),
+		compare.WithCache(cacheInstance),
+		compare.WithHTTPClient(httpClient),
+		compare.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create compare tool: %w", err)
+	}
+
+	probeTool, err := probe.New(
+		probe.WithLogger(logger),
+		probe.WithCache(cacheInstance),
+		probe.WithHTTPClient(httpClient),
+		probe.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create probe tool: %w", err)
+	}
+
+	benchmarkTool, err := benchmark.New(
+		benchmark.WithLogger(logger),
+		benchmark.WithCache(cacheInstance),
+		benchmark.WithHTTPClient(httpClient),
+		benchmark.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create benchmark tool: %w", err)
+	}
+
+	siteTool, err := sitetools.New(
+		siteRegistry,
+		sitetools.WithLogger(logger),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create site tool: %w", err)
+	}
+
+	healthTool, err := healthtools.New(
+		healthtools.WithLogger(logger),
+		healthtools.WithCache(cacheInstance),
+		healthtools.WithHTTPClient(httpClient),
+		healthtools.WithSites(siteRegistry),
+		healthtools.WithRecorder(recorder),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create health tool: %w", err)
+	}
+
+	logLevelTool, err := loglevel.New(logLevel, loglevel.WithLogger(logger))
+	if err != nil {
+		return fmt.Errorf("failed to create log level tool: %w", err)
+	}
+
+	registry := tools.NewRegistry(strings.Split(viper.GetString("disabled_tools"), ",")...)
+	buildInfo := build()
 	infoTool, err := info.New(
-		GitCommit,
+		buildInfo.GitCommit,
 		info.WithLogger(logger),
-		info.WithVersion("1.0.0"),
+		info.WithBuild(buildInfo),
+		info.WithRegistry(registry),
+		info.WithCache(cacheInstance),
+		info.WithConfig(viper.AllSettings()),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create info tool: %w", err)
 	}
 
-	// Register tools with handler functions
-	if err := server.RegisterTool(
-		taxonomiesTool.Name(),
-		taxonomiesTool.Description(),
-		func(args *taxonomies.TaxonomiesRequest) (*mcp_golang.ToolResponse, error) {
-			return taxonomiesTool.Execute(args)
-		},
-	); err != nil {
-		return fmt.Errorf("failed to register taxonomies tool: %w", err)
-	}
-
-	if err := server.RegisterTool(
-		termsTool.Name(),
-		termsTool.Description(),
-		func(args *terms.TaxonomyTermsRequest) (*mcp_golang.ToolResponse, error) {
-			return termsTool.Execute(args)
-		},
-	); err != nil {
-		return fmt.Errorf("failed to register terms tool: %w", err)
-	}
-
-	if err := server.RegisterTool(
-		contentTool.Name(),
-		contentTool.Description(),
-		func(args *content.ContentRequest) (*mcp_golang.ToolResponse, error) {
-			return contentTool.Execute(args)
-		},
-	); err != nil {
-		return fmt.Errorf("failed to register content tool: %w", err)
-	}
-
-	if err := server.RegisterTool(
-		searchTool.Name(),
-		searchTool.Description(),
-		func(args *search.SearchRequest) (*mcp_golang.ToolResponse, error) {
-			return searchTool.Execute(args)
-		},
-	); err != nil {
-		return fmt.Errorf("failed to register search tool: %w", err)
-	}
-
-	if err := server.RegisterTool(
-		cacheTool.Name(),
-		cacheTool.Description(),
-		func(args *cachetools.ClearCacheRequest) (*mcp_golang.ToolResponse, error) {
-			return cacheTool.Execute(args)
-		},
-	); err != nil {
-		return fmt.Errorf("failed to register cache tool: %w", err)
-	}
-
-	if err := server.RegisterTool(
-		discoveryTool.Name(),
-		discoveryTool.Description(),
-		func(args *discovery.DiscoveryRequest) (*mcp_golang.ToolResponse, error) {
-			return discoveryTool.Execute(args)
-		},
-	); err != nil {
-		return fmt.Errorf("failed to register discovery tool: %w", err)
-	}
-
-	if err := server.RegisterTool(
-		infoTool.Name(),
-		infoTool.Description(),
-		func(args *info.InfoRequest) (*mcp_golang.ToolResponse, error) {
-			return infoTool.Execute(args)
-		},
-	); err != nil {
-		return fmt.Errorf("failed to register info tool: %w", err)
-	}
-
-	logger.Info("Successfully registered all tools", 
-		"tools", []string{
-			taxonomiesTool.Name(),
-			termsTool.Name(), 
-			contentTool.Name(),
-			searchTool.Name(),
-			cacheTool.Name(),
-			discoveryTool.Name(),
-			infoTool.Name(),
-		})
+	toolList := []interface{ Register(*tools.Registry) }{
+		taxonomiesTool, termsTool, cooccurrenceTool, contentTool, metadataTool,
+		searchTool, cacheTool, discoveryTool, sectionTool, seriesTool,
+		archiveTool, statsTool, linksTool, assetsTool, structuredDataTool,
+		codeTool, graphTool, linkCheckTool, changesTool, freshnessTool, compareTool,
+		probeTool, benchmarkTool, siteTool, healthTool, logLevelTool, infoTool,
+	}
+
+	// The page history tool sends the URLs of pages to the Internet
+	// Archive, so it is only offered when enabled
+	if viper.GetBool("wayback") {
+		waybackTool, err := wayback.New(
+			wayback.WithLogger(logger),
+			wayback.WithCache(cacheInstance),
+			wayback.WithHTTPClient(httpClient),
+			wayback.WithSites(siteRegistry),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create page history tool: %w", err)
+		}
+		toolList = append(toolList, waybackTool)
+	}
+
+	// Each tool adds itself to the registry, which leaves out those disabled
+	for _, tool := range toolList {
+		tool.Register(registry)
+	}
+
+	// Every call gets a request ID to correlate its logs, is instrumented,
+	// recovers from panics, is validated and logged
+	if err := registry.Register(server, tools.RequestID(), tel.instrument, tools.Recover(logger), tools.Validate(), tools.Log(logger)); err != nil {
+		return err
+	}
+
+	var names []string
+	for _, registered := range registry.Tools() {
+		names = append(names, registered.Name)
+	}
+	logger.Info("Successfully registered all tools", "tools", names, "disabled", registry.Disabled())
+
+	return nil
+}
+
+// toolObserver is told about the outcome of every tool call
+type toolObserver interface {
+	Record(tool string, duration time.Duration, err error)
+}
+
+// toolObservers tells each of its observers about every tool call
+type toolObservers []toolObserver
+
+// Record implements toolObserver
+func (o toolObservers) Record(tool string, duration time.Duration, err error) {
+	for _, observer := range o {
+		observer.Record(tool, duration, err)
+	}
+}
+
+// telemetry is told about every tool call
+type telemetry struct {
+	observer toolObserver
+	tracer   *tracing.Tracer
+	audit    *audit.Logger
+}
+
+// instrument is a tools.Middleware recording the outcome of each call,
+// tracing it and appending it to the audit log when those are enabled.
+// Errors are returned to the client in the standard error envelope, with
+// the call's request ID in their context.
+func (tel telemetry) instrument(name string, next tools.Handler) tools.Handler {
+	return func(ctx context.Context, request tools.Request) (*mcp_golang.ToolResponse, error) {
+		ctx, span := tel.tracer.Start(ctx, "tools/call "+name, tracing.KindServer, tracing.String("gen_ai.tool.name", name))
+		start := time.Now()
+		resp, err := next(ctx, request)
+		duration := time.Since(start)
+		tel.observer.Record(name, duration, err)
+		tel.audit.Record(name, request, resp, duration, err, span.TraceID(), logging.RequestID(ctx))
+		span.RecordError(err)
+		span.End()
+		errContext := map[string]interface{}{"tool": name}
+		if id := logging.RequestID(ctx); id != "" {
+			errContext["request_id"] = id
+		}
+		return resp, toolerrors.Envelope(err, errContext)
+	}
+}
+
+// serveMetrics serves metrics on addr in the background, returning a
+// function that stops the listener
+func serveMetrics(collector *metrics.Metrics, addr string, logger *slog.Logger) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	metricsServer := collector.Server(addr)
+	go func() {
+		if err := metricsServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics listener failed", "error", err)
+		}
+	}()
+	logger.Info("Serving metrics", "addr", listener.Addr().String(), "path", metrics.Path)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+		metricsServer.Shutdown(ctx)
+	}, nil
+}
+
+// startChangePoller checks the sites in watch_sites, or every registered
+// site, at watch_interval and posts their changes to webhook_url. It returns
+// a function that stops the poller, and does nothing without a webhook.
+func startChangePoller(logger *slog.Logger, cacheInstance *cache.Cache, httpClient *httpclient.Client, siteRegistry *sites.Registry, clientOpts []httpclient.Option) (func(), error) {
+	webhookURL, interval, watched, err := watchConfig()
+	if err != nil {
+		return nil, err
+	}
+	if webhookURL == "" {
+		return func() {}, nil
+	}
+
+	// Notifications are posted with a client of their own: the webhook is
+	// configured by the operator, so it may be on a private network, but
+	// only its host and port are exempt from private address blocking, and
+	// the tools' client keeps blocking them
+	webhookClient, err := httpclient.FromConfig(append(clientOpts, httpclient.WithAllowedHosts(webhookHost(webhookURL)))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook client: %w", err)
+	}
+
+	changesTool, err := changes.New(
+		changes.WithLogger(logger),
+		changes.WithCache(cacheInstance),
+		changes.WithHTTPClient(httpClient),
+		changes.WithSites(siteRegistry),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create changes tool: %w", err)
+	}
+
+	poller := changes.NewPoller(changesTool, webhookURL,
+		changes.WithPollerLogger(logger),
+		changes.WithPollInterval(interval),
+		changes.WithWatchedSites(watched),
+		changes.WithWebhookClient(&http.Client{Transport: webhookClient.HTTPClient().Transport, Timeout: changes.WebhookTimeout}),
+	)
+	return poller.Start(), nil
+}
+
+// webhookHost returns the host and port notifications are posted to
+func webhookHost(webhookURL string) string {
+	u, _ := url.Parse(webhookURL)
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// watchConfig returns the configured webhook_url, which is empty when
+// watching is disabled, watch_interval and watch_sites
+func watchConfig() (webhookURL string, interval time.Duration, watched []string, err error) {
+	webhookURL = viper.GetString("webhook_url")
+	if webhookURL == "" {
+		return "", 0, nil, nil
+	}
+	// The URL may embed a token, so it is never logged
+	if u, err := url.Parse(webhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", 0, nil, fmt.Errorf("invalid webhook_url: must be an http or https URL")
+	}
 
+	interval = changes.DefaultPollInterval
+	if value := viper.GetString("watch_interval"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return "", 0, nil, fmt.Errorf("invalid watch_interval: %s", value)
+		}
+		interval = parsed
+	}
+
+	for _, site := range strings.Split(viper.GetString("watch_sites"), ",") {
+		if site = strings.TrimSpace(site); site != "" {
+			watched = append(watched, site)
+		}
+	}
+	return webhookURL, interval, watched, nil
+}
+
+// registerResources exposes the pages of the sites in resource_sites as MCP
+// resources. Site indexes are loaded before the server starts serving, as
+// mcp-golang's server state may not be changed while Serve starts; a site
+// that fails to load is skipped.
+func registerResources(server *mcp_golang.Server, logger *slog.Logger, cacheInstance *cache.Cache, httpClient *httpclient.Client) error {
+	sites, maxPages, err := resourceConfig()
+	if err != nil {
+		return err
+	}
+	if len(sites) == 0 {
+		return nil
+	}
+
+	contentTool, err := content.New(
+		content.WithLogger(logger),
+		content.WithCache(cacheInstance),
+		content.WithHTTPClient(httpClient),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create content tool: %w", err)
+	}
+
+	registry := resources.New(
+		contentTool.ReadPage,
+		resources.WithLogger(logger),
+		resources.WithCache(cacheInstance),
+		resources.WithHTTPClient(httpClient),
+		resources.WithMaxPages(maxPages),
+	)
+
+	for _, siteURL := range sites {
+		ctx, cancel := context.WithTimeout(context.Background(), resourceTimeout)
+		if _, err := registry.Register(ctx, server, siteURL); err != nil {
+			logger.Warn("Failed to register site resources", "site", siteURL.String(), "error", err)
+		}
+		cancel()
+	}
 	return nil
 }
 
+// resourceConfig returns the configured resource_sites and
+// resource_max_pages
+func resourceConfig() ([]*url.URL, int, error) {
+	sites, err := resources.Sites(viper.GetString("resource_sites"))
+	if err != nil {
+		return nil, 0, err
+	}
+	maxPages := viper.GetInt("resource_max_pages")
+	if maxPages < 0 {
+		return nil, 0, fmt.Errorf("resource_max_pages must not be negative")
+	}
+	return sites, maxPages, nil
+}
@@ -1,26 +1,49 @@
 package hugo
 
 import (
-	"fmt"
-
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/buildinfo"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/output"
 	"github.com/spf13/cobra"
 )
 
 var (
+	// Version holds the semantic version, injected at build time
+	Version = ""
+
 	// GitCommit holds the git commit hash, injected at build time
 	GitCommit = "unknown"
+
+	// BuildTime holds the build time in RFC 3339, injected at build time
+	BuildTime = ""
 )
 
+// build describes the running build, falling back to the Go module build
+// info for what was not injected
+func build() buildinfo.Info {
+	return buildinfo.Read(Version, GitCommit, BuildTime)
+}
+
 // versionCmd represents the version command
 var versionCmd = &cobra.Command{
 	Use:   "version",
-	Short: "Print the version (git commit hash)",
-	Long:  `Print the version information of the Hugo Reader CLI tool.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("Version (git commit): %s\n", GitCommit)
+	Short: "Print the version, git commit and build time",
+	Long: `Print the version information of the Hugo Reader CLI tool: the version,
+git commit and build time injected at build time, or recorded by Go in the
+binary when they were not, and the Go version it was built with.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := outputFormat(cmd)
+		if err != nil {
+			return err
+		}
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			format = output.JSON
+		}
+		return output.Render(cmd.OutOrStdout(), format, build())
 	},
 }
 
 func init() {
+	addOutputFlag(versionCmd)
+	versionCmd.Flags().Bool("json", false, "shorthand for --output json")
 	rootCmd.AddCommand(versionCmd)
 }
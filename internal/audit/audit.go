@@ -0,0 +1,202 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// Redacted replaces the values of secret parameters
+const Redacted = "[REDACTED]"
+
+// secretKeys are parameter names whose values are always redacted
+var secretKeys = map[string]bool{
+	"password":      true,
+	"bearer_token":  true,
+	"token":         true,
+	"secret":        true,
+	"api_key":       true,
+	"search_key":    true,
+	"authorization": true,
+	"cookie":        true,
+
+	// Webhook URLs carry their secret in the path, as Slack's do
+	"webhook_url": true,
+}
+
+// Entry is one line of the audit log
+type Entry struct {
+	Time         time.Time      `json:"time"`
+	Tool         string         `json:"tool"`
+	Params       any            `json:"params,omitempty"`
+	DurationMs   int64          `json:"duration_ms"`
+	Success      bool           `json:"success"`
+	Error        string         `json:"error,omitempty"`
+	ResultCounts map[string]int `json:"result_counts,omitempty"`
+	TraceID      string         `json:"trace_id,omitempty"`
+	RequestID    string         `json:"request_id,omitempty"`
+}
+
+// Logger appends an Entry for every tool call to a file as JSON lines.
+// A nil *Logger records nothing.
+type Logger struct {
+	mu  sync.Mutex
+	w   io.WriteCloser
+	log *slog.Logger
+	now func() time.Time
+}
+
+// Open opens the audit log at path for appending, creating it readable
+// only by the current user if it does not exist
+func Open(path string, logger *slog.Logger) (*Logger, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return newLogger(file, logger), nil
+}
+
+func newLogger(w io.WriteCloser, logger *slog.Logger) *Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Logger{w: w, log: logger, now: time.Now}
+}
+
+// Record appends an entry for a tool call made with params that returned
+// response or err. Secret parameters are redacted, and the items in each
+// list of a JSON response are counted. The call's trace and request IDs
+// correlate the entry with its spans and log lines.
+func (l *Logger) Record(tool string, params any, response *mcp_golang.ToolResponse, duration time.Duration, err error, traceID, requestID string) {
+	if l == nil {
+		return
+	}
+
+	entry := Entry{
+		Tool:       tool,
+		Params:     Redact(params),
+		DurationMs: duration.Milliseconds(),
+		Success:    err == nil,
+		TraceID:    traceID,
+		RequestID:  requestID,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	} else if result, ok := responseObject(response); ok {
+		if success, ok := result["success"].(bool); ok {
+			entry.Success = success
+		}
+		entry.ResultCounts = resultCounts(result)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry.Time = l.now().UTC()
+	line, marshalErr := json.Marshal(entry)
+	if marshalErr != nil {
+		l.log.Warn("Failed to encode audit entry", "tool", tool, "error", marshalErr)
+		return
+	}
+	if _, writeErr := l.w.Write(append(line, '\n')); writeErr != nil {
+		l.log.Warn("Failed to write audit entry", "tool", tool, "error", writeErr)
+	}
+}
+
+// Close closes the audit log
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Close()
+}
+
+// Redact returns params as generic JSON values with the values of secret
+// keys, and of every custom header and cookie, replaced by Redacted, and
+// the passwords of URLs masked
+func Redact(params any) any {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	return redact(value)
+}
+
+func redact(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, v := range value {
+			switch {
+			case secretKeys[strings.ToLower(key)]:
+				value[key] = Redacted
+			case strings.EqualFold(key, "headers"), strings.EqualFold(key, "cookies"):
+				if headers, ok := v.(map[string]any); ok {
+					for name := range headers {
+						headers[name] = Redacted
+					}
+				}
+			default:
+				value[key] = redact(v)
+			}
+		}
+		return value
+	case []any:
+		for i, v := range value {
+			value[i] = redact(v)
+		}
+		return value
+	case string:
+		if u, err := url.Parse(value); err == nil && u.User != nil {
+			if _, hasPassword := u.User.Password(); hasPassword {
+				return u.Redacted()
+			}
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+// responseObject decodes the JSON object in a tool response's text content
+func responseObject(response *mcp_golang.ToolResponse) (map[string]any, bool) {
+	if response == nil {
+		return nil, false
+	}
+	for _, content := range response.Content {
+		if content == nil || content.TextContent == nil {
+			continue
+		}
+		var result map[string]any
+		if err := json.Unmarshal([]byte(content.TextContent.Text), &result); err == nil {
+			return result, true
+		}
+	}
+	return nil, false
+}
+
+// resultCounts returns the length of each top-level list in a result
+func resultCounts(result map[string]any) map[string]int {
+	counts := make(map[string]int)
+	for key, value := range result {
+		if list, ok := value.([]any); ok {
+			counts[key] = len(list)
+		}
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	return counts
+}
@@ -0,0 +1,91 @@
+// Package buildinfo describes the running build of the server, combining
+// what was injected at build time with the Go module build info
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Unknown is reported for what neither the build nor the module build info
+// records
+const Unknown = "unknown"
+
+// Info describes a build
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildTime string `json:"build_time"`
+
+	// Modified reports whether the build had uncommitted changes
+	Modified bool `json:"modified"`
+
+	GoVersion string `json:"go_version"`
+	Module    string `json:"module,omitempty"`
+}
+
+// Read describes the running build. The version, commit and build time
+// injected with -ldflags take precedence; those left empty or "unknown" are
+// taken from the module build info, which records the version of modules
+// installed with go install and the commit and commit time of builds from
+// a git checkout.
+func Read(version, gitCommit, buildTime string) Info {
+	build, _ := debug.ReadBuildInfo()
+	return fromBuildInfo(build, version, gitCommit, buildTime)
+}
+
+// fromBuildInfo describes a build from the injected values and its module
+// build info, which may be nil
+func fromBuildInfo(build *debug.BuildInfo, version, gitCommit, buildTime string) Info {
+	info := Info{
+		Version:   version,
+		GitCommit: gitCommit,
+		BuildTime: buildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if build != nil {
+		info.Module = build.Main.Path
+		if build.GoVersion != "" {
+			info.GoVersion = build.GoVersion
+		}
+		if unset(info.Version) && build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if unset(info.GitCommit) {
+					info.GitCommit = setting.Value[:min(len(setting.Value), 7)]
+				}
+			case "vcs.time":
+				if unset(info.BuildTime) {
+					info.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+
+	// Versions injected from git describe with --dirty say so themselves
+	if strings.HasSuffix(info.Version, "-dirty") {
+		info.Modified = true
+	}
+	if unset(info.Version) {
+		info.Version = "dev"
+	}
+	if unset(info.GitCommit) {
+		info.GitCommit = Unknown
+	}
+	if unset(info.BuildTime) {
+		info.BuildTime = Unknown
+	}
+	return info
+}
+
+// unset reports whether a value was left out of the build
+func unset(value string) bool {
+	return value == "" || value == Unknown
+}
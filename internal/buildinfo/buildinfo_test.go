@@ -0,0 +1,59 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromBuildInfo(t *testing.T) {
+	build := &debug.BuildInfo{
+		GoVersion: "go1.24.1",
+		Main:      debug.Module{Path: "github.com/rmrfslashbin/mcp/hugo-reader", Version: "v1.2.3"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "ab4fcf0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b"},
+			{Key: "vcs.time", Value: "2025-01-15T09:00:00Z"},
+			{Key: "vcs.modified", Value: "false"},
+		},
+	}
+
+	tests := []struct {
+		name                          string
+		build                         *debug.BuildInfo
+		version, gitCommit, buildTime string
+		want                          Info
+	}{
+		{
+			name:      "injected",
+			build:     build,
+			version:   "v2.0.0-3-gabcdef0-dirty",
+			gitCommit: "abcdef0",
+			buildTime: "2025-02-01T12:00:00Z",
+			want: Info{
+				Version: "v2.0.0-3-gabcdef0-dirty", GitCommit: "abcdef0", BuildTime: "2025-02-01T12:00:00Z",
+				Modified: true, GoVersion: "go1.24.1", Module: "github.com/rmrfslashbin/mcp/hugo-reader",
+			},
+		},
+		{
+			name:      "from build info",
+			build:     build,
+			gitCommit: Unknown,
+			want: Info{
+				Version: "v1.2.3", GitCommit: "ab4fcf0", BuildTime: "2025-01-15T09:00:00Z",
+				GoVersion: "go1.24.1", Module: "github.com/rmrfslashbin/mcp/hugo-reader",
+			},
+		},
+		{
+			name: "neither",
+			want: Info{Version: "dev", GitCommit: Unknown, BuildTime: Unknown, GoVersion: runtime.Version()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, fromBuildInfo(tt.build, tt.version, tt.gitCommit, tt.buildTime))
+		})
+	}
+}
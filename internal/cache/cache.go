@@ -1,10 +1,10 @@
 package cache
 
 import (
+	"container/list"
 	"crypto/md5"
 	"fmt"
 	"log/slog"
-	"net/http"
 	"net/url"
 	"sort"
 	"strings"
@@ -14,11 +14,24 @@ import (
 
 // CacheEntry represents a cached HTTP response
 type CacheEntry struct {
+	// Data is the response body, gzip-compressed when Compressed is set
 	Data         []byte
 	ETag         string
 	LastModified string
 	CachedAt     time.Time
 	TTL          time.Duration
+
+	// Status is the response status of a negative entry, which has no
+	// data; it is zero for cached responses
+	Status int
+
+	// Compressed is set when Data is gzip-compressed, and Size is the
+	// length of the data before compression
+	Compressed bool
+	Size       int
+
+	// element is the entry's position in the LRU list
+	element *list.Element
 }
 
 // IsExpired checks if the cache entry has expired
@@ -26,13 +39,70 @@ func (e *CacheEntry) IsExpired() bool {
 	return time.Since(e.CachedAt) > e.TTL
 }
 
-// Cache provides in-memory caching with smart invalidation
+// olderThan reports whether the entry was cached more than maxAge ago,
+// unless maxAge is zero
+func (e *CacheEntry) olderThan(maxAge time.Duration) bool {
+	return maxAge > 0 && time.Since(e.CachedAt) > maxAge
+}
+
+// revalidatable reports whether an expired entry can still be refreshed with
+// a conditional request rather than discarded
+func (e *CacheEntry) revalidatable() bool {
+	return (e.ETag != "" || e.LastModified != "") && time.Since(e.CachedAt) <= e.TTL+StaleRetention
+}
+
+// Cache provides in-memory caching with smart invalidation. When limits are
+// set, the least recently used entries are evicted to stay within them.
 type Cache struct {
 	entries   map[string]*CacheEntry
+	lru       *list.List // keys, most recently used first
 	mutex     sync.RWMutex
 	logger    *slog.Logger
 	defaultTTL time.Duration
-	httpClient *http.Client
+
+	maxEntries int
+	maxBytes   int
+
+	// ignoreCacheHeaders disables server-provided TTLs in SetFromResponse
+	ignoreCacheHeaders bool
+
+	// negativeTTL is how long SetNegative entries live
+	negativeTTL time.Duration
+
+	// compressThreshold is the size from which entries are stored
+	// compressed; zero disables compression
+	compressThreshold int
+
+	// totalBytes is the size of the entries as stored and rawBytes their
+	// size before compression
+	totalBytes int
+	rawBytes   int
+
+	// Counters reported by Stats
+	hits          int
+	misses        int
+	evictions     int
+	expirations   int
+	revalidations int
+	negativeHits  int
+	sharedFetches int
+
+	// flights de-duplicates concurrent fetches of the same key
+	flights flightGroup
+
+	// snapshots holds per-site page snapshots used for change detection
+	snapshots       map[string][]*Snapshot
+	snapshotHistory int
+
+	// validators holds the caching validators of resources as last checked
+	validators map[string]Validators
+
+	// endpoints records, per site, when endpoints found missing may be tried again
+	endpoints          map[string]map[string]time.Time
+	endpointFailureTTL time.Duration
+
+	// endpointObserver, when set, is told about endpoints found missing
+	endpointObserver func(siteURL, path string)
 }
 
 // CacheOption configures the cache
@@ -41,10 +111,19 @@ type CacheOption func(*Cache)
 // New creates a new cache instance
 func New(opts ...CacheOption) *Cache {
 	c := &Cache{
-		entries:    make(map[string]*CacheEntry),
-		logger:     slog.Default().With("component", "cache"),
-		defaultTTL: 5 * time.Minute,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+		entries:         make(map[string]*CacheEntry),
+		lru:             list.New(),
+		logger:          slog.Default().With("component", "cache"),
+		defaultTTL:      5 * time.Minute,
+		snapshots:       make(map[string][]*Snapshot),
+		snapshotHistory: DefaultSnapshotHistory,
+		validators:      make(map[string]Validators),
+		negativeTTL:     DefaultNegativeTTL,
+
+		compressThreshold: DefaultCompressThreshold,
+
+		endpoints:          make(map[string]map[string]time.Time),
+		endpointFailureTTL: DefaultEndpointFailureTTL,
 	}
 	
 	for _, opt := range opts {
@@ -68,10 +147,19 @@ func WithTTL(ttl time.Duration) CacheOption {
 	}
 }
 
-// WithHTTPClient sets the HTTP client for validation requests
-func WithHTTPClient(client *http.Client) CacheOption {
+// WithMaxEntries limits the number of entries, evicting the least recently
+// used. Zero means unlimited.
+func WithMaxEntries(n int) CacheOption {
 	return func(c *Cache) {
-		c.httpClient = client
+		c.maxEntries = n
+	}
+}
+
+// WithMaxBytes limits the total size of cached data, evicting the least
+// recently used entries. Zero means unlimited.
+func WithMaxBytes(n int) CacheOption {
+	return func(c *Cache) {
+		c.maxBytes = n
 	}
 }
 
@@ -109,11 +197,12 @@ func (c *Cache) BuildKey(baseURL, endpoint string, params map[string]string) str
 		u.RawQuery = query
 	}
 	
-	// Hash long URLs to keep keys manageable
+	// Hash long URLs to keep keys manageable, keeping the host so the entry
+	// can still be found by DeleteByHost
 	key := u.String()
 	if len(key) > 200 {
 		hash := md5.Sum([]byte(key))
-		key = fmt.Sprintf("hash:%x", hash)
+		key = fmt.Sprintf("hash:%s:%x", strings.ToLower(u.Host), hash)
 	}
 	
 	return key
@@ -121,112 +210,194 @@ func (c *Cache) BuildKey(baseURL, endpoint string, params map[string]string) str
 
 // Get retrieves data from cache with smart validation
 func (c *Cache) Get(key string) ([]byte, bool) {
-	c.mutex.RLock()
+	return c.get(key, 0)
+}
+
+// get is Get treating entries cached for longer than maxAge as expired,
+// unless maxAge is zero
+func (c *Cache) get(key string, maxAge time.Duration) ([]byte, bool) {
+	c.mutex.Lock()
 	entry, exists := c.entries[key]
-	c.mutex.RUnlock()
-	
-	if !exists {
+	if !exists || entry.Status != 0 {
+		c.misses++
+		c.mutex.Unlock()
 		c.logger.Debug("Cache miss", "key", key)
 		return nil, false
 	}
 	
-	// Check TTL expiration
-	if entry.IsExpired() {
+	// Check TTL expiration, keeping entries Fetch can revalidate
+	if entry.IsExpired() || entry.olderThan(maxAge) {
+		if entry.IsExpired() && !entry.revalidatable() {
+			c.removeLocked(key)
+			c.expirations++
+		}
+		c.misses++
+		c.mutex.Unlock()
 		c.logger.Debug("Cache entry expired", "key", key, "age", time.Since(entry.CachedAt))
+		return nil, false
+	}
+	
+	c.lru.MoveToFront(entry.element)
+	c.hits++
+	c.mutex.Unlock()
+
+	data, err := entry.data()
+	if err != nil {
+		c.logger.Warn("Failed to decompress cache entry, discarding it", "key", key, "error", err)
 		c.Delete(key)
 		return nil, false
 	}
 	
 	c.logger.Debug("Cache hit", "key", key, "age", time.Since(entry.CachedAt))
-	return entry.Data, true
+	return data, true
 }
 
-// Set stores data in cache with metadata
+// Set stores data in cache with metadata and the default TTL
 func (c *Cache) Set(key string, data []byte, etag, lastModified string) {
+	c.set(key, data, etag, lastModified, c.defaultTTL)
+}
+
+func (c *Cache) set(key string, data []byte, etag, lastModified string, ttl time.Duration) {
 	entry := &CacheEntry{
-		Data:         make([]byte, len(data)),
 		ETag:         etag,
 		LastModified: lastModified,
 		CachedAt:     time.Now(),
-		TTL:          c.defaultTTL,
+		TTL:          ttl,
+		Size:         len(data),
+	}
+	if c.compressThreshold > 0 && len(data) >= c.compressThreshold {
+		if compressed := compress(data); compressed != nil {
+			entry.Data = compressed
+			entry.Compressed = true
+		}
+	}
+	if !entry.Compressed {
+		entry.Data = make([]byte, len(data))
+		copy(entry.Data, data)
+	}
+	
+	if c.maxBytes > 0 && len(entry.Data) > c.maxBytes {
+		c.logger.Debug("Entry larger than cache, not caching", "key", key, "size", len(entry.Data), "max_bytes", c.maxBytes)
+		c.Delete(key)
+		return
 	}
-	copy(entry.Data, data)
 	
 	c.mutex.Lock()
+	c.removeLocked(key)
+	entry.element = c.lru.PushFront(key)
 	c.entries[key] = entry
+	c.totalBytes += len(entry.Data)
+	c.rawBytes += entry.Size
+	evicted := c.evictLocked()
 	c.mutex.Unlock()
 	
-	c.logger.Debug("Cached entry", "key", key, "size", len(data), "etag", etag)
+	c.logger.Debug("Cached entry", "key", key, "size", len(data), "stored_size", len(entry.Data), "etag", etag, "ttl", ttl)
+	if evicted > 0 {
+		c.logger.Debug("Evicted least recently used entries", "count", evicted)
+	}
 }
 
-// Validate checks if cached content is still valid using HTTP headers
-func (c *Cache) Validate(key, originalURL string) ([]byte, bool) {
-	c.mutex.RLock()
+// evictLocked removes least recently used entries until the cache is within
+// its limits, returning how many were evicted. c.mutex must be held.
+func (c *Cache) evictLocked() int {
+	evicted := 0
+	for c.overLimitLocked() {
+		oldest := c.lru.Back()
+		if oldest == nil {
+			break
+		}
+		c.removeLocked(oldest.Value.(string))
+		evicted++
+	}
+	c.evictions += evicted
+	return evicted
+}
+
+func (c *Cache) overLimitLocked() bool {
+	return (c.maxEntries > 0 && len(c.entries) > c.maxEntries) ||
+		(c.maxBytes > 0 && c.totalBytes > c.maxBytes)
+}
+
+// removeLocked removes an entry if present. c.mutex must be held.
+func (c *Cache) removeLocked(key string) {
 	entry, exists := c.entries[key]
-	c.mutex.RUnlock()
-	
 	if !exists {
-		return nil, false
-	}
-	
-	// If TTL hasn't expired, return cached data
-	if !entry.IsExpired() {
-		return entry.Data, true
-	}
-	
-	// Perform conditional request to validate cache
-	req, err := http.NewRequest("HEAD", originalURL, nil)
-	if err != nil {
-		c.logger.Error("Failed to create validation request", "url", originalURL, "error", err)
-		c.Delete(key)
-		return nil, false
+		return
 	}
-	
-	// Add conditional headers
-	if entry.ETag != "" {
-		req.Header.Set("If-None-Match", entry.ETag)
-	}
-	if entry.LastModified != "" {
-		req.Header.Set("If-Modified-Since", entry.LastModified)
-	}
-	
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		c.logger.Warn("Failed to validate cache entry", "url", originalURL, "error", err)
-		// Network error - keep cached entry for now
-		return entry.Data, true
-	}
-	defer resp.Body.Close()
-	
-	// 304 Not Modified - content hasn't changed
-	if resp.StatusCode == http.StatusNotModified {
-		c.logger.Debug("Cache entry validated as current", "key", key)
-		// Update cache timestamp to extend TTL
-		c.mutex.Lock()
-		entry.CachedAt = time.Now()
-		c.mutex.Unlock()
-		return entry.Data, true
-	}
-	
-	// Content has changed, invalidate cache
-	c.logger.Debug("Cache entry invalidated by server", "key", key, "status", resp.StatusCode)
-	c.Delete(key)
-	return nil, false
+	c.lru.Remove(entry.element)
+	c.totalBytes -= len(entry.Data)
+	c.rawBytes -= entry.Size
+	delete(c.entries, key)
 }
 
 // Delete removes an entry from cache
 func (c *Cache) Delete(key string) {
 	c.mutex.Lock()
-	delete(c.entries, key)
+	c.removeLocked(key)
 	c.mutex.Unlock()
 	
 	c.logger.Debug("Deleted cache entry", "key", key)
 }
 
+// DeleteByPrefix removes all entries whose key starts with prefix and returns
+// how many were removed. Hashed keys only match on their "hash:<host>:" prefix.
+func (c *Cache) DeleteByPrefix(prefix string) int {
+	return c.deleteMatching(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// DeleteByHost removes all entries for a host and returns how many were
+// removed. A host without a port matches entries for any port.
+func (c *Cache) DeleteByHost(host string) int {
+	return c.deleteMatching(hostMatcher(host))
+}
+
+// deleteMatching removes all entries whose key satisfies match
+func (c *Cache) deleteMatching(match func(key string) bool) int {
+	c.mutex.Lock()
+	removed := 0
+	for key := range c.entries {
+		if match(key) {
+			c.removeLocked(key)
+			removed++
+		}
+	}
+	for site := range c.endpoints {
+		if match(site) {
+			delete(c.endpoints, site)
+		}
+	}
+	c.mutex.Unlock()
+
+	c.logger.Debug("Deleted matching cache entries", "count", removed)
+	return removed
+}
+
+// hostOf returns the lower-cased host (with port, if any) a cache key belongs to
+func hostOf(key string) string {
+	if rest, ok := strings.CutPrefix(key, "hash:"); ok {
+		// The hash follows the last colon; the host may contain a port
+		if i := strings.LastIndex(rest, ":"); i >= 0 {
+			return rest[:i]
+		}
+		return ""
+	}
+	u, err := url.Parse(key)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
 // Clear removes all entries from cache
 func (c *Cache) Clear() {
 	c.mutex.Lock()
 	c.entries = make(map[string]*CacheEntry)
+	c.lru.Init()
+	c.totalBytes = 0
+	c.rawBytes = 0
+	c.endpoints = make(map[string]map[string]time.Time)
 	c.mutex.Unlock()
 	
 	c.logger.Info("Cleared all cache entries")
@@ -237,43 +408,99 @@ func (c *Cache) Stats() map[string]interface{} {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 	
-	totalSize := 0
 	expiredCount := 0
+	negativeCount := 0
+	compressedCount := 0
 	
 	for _, entry := range c.entries {
-		totalSize += len(entry.Data)
 		if entry.IsExpired() {
 			expiredCount++
 		}
+		if entry.Status != 0 {
+			negativeCount++
+		}
+		if entry.Compressed {
+			compressedCount++
+		}
+	}
+	
+	hitRate := 0.0
+	if lookups := c.hits + c.misses; lookups > 0 {
+		hitRate = float64(c.hits) / float64(lookups)
 	}
 	
 	return map[string]interface{}{
-		"total_entries":   len(c.entries),
-		"expired_entries": expiredCount,
-		"total_size":      totalSize,
-		"default_ttl":     c.defaultTTL.String(),
+		"total_entries":      len(c.entries),
+		"expired_entries":    expiredCount,
+		"total_size":         c.totalBytes,
+		"raw_size":           c.rawBytes,
+		"compressed_entries": compressedCount,
+		"compress_threshold": c.compressThreshold,
+		"default_ttl":        c.defaultTTL.String(),
+		"max_entries":        c.maxEntries,
+		"max_bytes":          c.maxBytes,
+		"hits":               c.hits,
+		"misses":             c.misses,
+		"hit_rate":           hitRate,
+		"evictions":          c.evictions,
+		"expirations":        c.expirations,
+		"revalidations":      c.revalidations,
+		"shared_fetches":     c.sharedFetches,
+		"negative_entries":   negativeCount,
+		"negative_hits":      c.negativeHits,
+		"negative_ttl":       c.negativeTTL.String(),
+		"snapshot_sites":     len(c.snapshots),
+		"endpoint_sites":     len(c.endpoints),
 	}
 }
 
-// CleanExpired removes all expired entries
+// CleanExpired removes all expired entries, except those that can still be
+// revalidated
 func (c *Cache) CleanExpired() int {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 	
 	var expiredKeys []string
 	for key, entry := range c.entries {
-		if entry.IsExpired() {
+		if entry.IsExpired() && !entry.revalidatable() {
 			expiredKeys = append(expiredKeys, key)
 		}
 	}
 	
 	for _, key := range expiredKeys {
-		delete(c.entries, key)
+		c.removeLocked(key)
 	}
+	c.expirations += len(expiredKeys)
 	
 	if len(expiredKeys) > 0 {
 		c.logger.Debug("Cleaned expired cache entries", "count", len(expiredKeys))
 	}
 	
 	return len(expiredKeys)
-}
\ No newline at end of file
+}
+
+// StartJanitor starts a background goroutine that removes expired entries
+// every interval, so they do not linger until next accessed. Call the
+// returned function to stop it.
+func (c *Cache) StartJanitor(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.CleanExpired()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	c.logger.Debug("Started cache janitor", "interval", interval)
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
@@ -1,10 +1,14 @@
 package cache
 
 import (
+	"crypto/rand"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNew(t *testing.T) {
@@ -186,4 +190,293 @@ func TestCacheEntry_IsExpired(t *testing.T) {
 	
 	entry.CachedAt = time.Now()
 	assert.False(t, entry.IsExpired())
-}
\ No newline at end of file
+}
+func TestCache_Snapshots(t *testing.T) {
+	cache := New(WithSnapshotHistory(2))
+
+	_, ok := cache.LatestSnapshot("https://example.com")
+	assert.False(t, ok)
+
+	for i := 0; i < 3; i++ {
+		cache.AddSnapshot("https://Example.com/", &Snapshot{
+			TakenAt: time.Now(),
+			Source:  "index.json",
+			Pages:   map[string]string{"/": string(rune('a' + i))},
+		})
+	}
+
+	// Keys are normalized and history is bounded
+	latest, ok := cache.LatestSnapshot("https://example.com")
+	assert.True(t, ok)
+	assert.Equal(t, "c", latest.Pages["/"])
+	assert.Len(t, cache.Snapshots("https://example.com"), 2)
+
+	// Snapshots are not cache entries
+	cache.Clear()
+	assert.Len(t, cache.Snapshots("https://example.com"), 2)
+	assert.Equal(t, 1, cache.Stats()["snapshot_sites"])
+
+	cache.ClearSnapshots("https://example.com")
+	_, ok = cache.LatestSnapshot("https://example.com")
+	assert.False(t, ok)
+}
+
+func TestCache_DeleteByHost(t *testing.T) {
+	cache := New()
+	longParams := map[string]string{"q": strings.Repeat("x", 250)}
+
+	keys := []string{
+		cache.BuildKey("https://example.com", "/index.json", nil),
+		cache.BuildKey("https://example.com", "/search.json", longParams),
+		cache.BuildKey("http://example.com:8080", "/index.json", nil),
+		cache.BuildKey("https://other.com", "/index.json", nil),
+		cache.BuildKey("https://other.com", "/search.json", longParams),
+	}
+	for _, key := range keys {
+		cache.Set(key, []byte("data"), "", "")
+	}
+	assert.True(t, strings.HasPrefix(keys[1], "hash:example.com:"))
+
+	assert.Equal(t, 1, cache.DeleteByHost("example.com:8080"))
+	assert.Equal(t, 2, cache.DeleteByHost("EXAMPLE.com"))
+	assert.Equal(t, 2, cache.Stats()["total_entries"])
+
+	_, found := cache.Get(keys[3])
+	assert.True(t, found)
+}
+
+func TestCache_DeleteByPrefix(t *testing.T) {
+	cache := New()
+	cache.Set("https://example.com/posts/a.json", []byte("a"), "", "")
+	cache.Set("https://example.com/posts/b.json", []byte("b"), "", "")
+	cache.Set("https://example.com/index.json", []byte("c"), "", "")
+
+	assert.Equal(t, 2, cache.DeleteByPrefix("https://example.com/posts/"))
+	assert.Equal(t, 0, cache.DeleteByPrefix("https://nothing.example/"))
+
+	_, found := cache.Get("https://example.com/index.json")
+	assert.True(t, found)
+}
+
+func TestCache_EvictsLeastRecentlyUsedByCount(t *testing.T) {
+	cache := New(WithMaxEntries(2))
+
+	cache.Set("a", []byte("1"), "", "")
+	cache.Set("b", []byte("2"), "", "")
+
+	// Touch "a" so "b" becomes the least recently used
+	_, found := cache.Get("a")
+	assert.True(t, found)
+
+	cache.Set("c", []byte("3"), "", "")
+
+	_, found = cache.Get("b")
+	assert.False(t, found)
+	_, found = cache.Get("a")
+	assert.True(t, found)
+	_, found = cache.Get("c")
+	assert.True(t, found)
+
+	stats := cache.Stats()
+	assert.Equal(t, 2, stats["total_entries"])
+	assert.Equal(t, 1, stats["evictions"])
+	assert.Equal(t, 2, stats["max_entries"])
+}
+
+func TestCache_EvictsLeastRecentlyUsedBySize(t *testing.T) {
+	cache := New(WithMaxBytes(10))
+
+	cache.Set("a", []byte("12345"), "", "")
+	cache.Set("b", []byte("12345"), "", "")
+	assert.Equal(t, 10, cache.Stats()["total_size"])
+
+	cache.Set("c", []byte("123"), "", "")
+	_, found := cache.Get("a")
+	assert.False(t, found)
+	assert.Equal(t, 8, cache.Stats()["total_size"])
+
+	// Replacing an entry updates the size rather than adding to it
+	cache.Set("b", []byte("1"), "", "")
+	assert.Equal(t, 4, cache.Stats()["total_size"])
+
+	// Entries larger than the whole cache are not stored
+	cache.Set("huge", []byte("12345678901"), "", "")
+	_, found = cache.Get("huge")
+	assert.False(t, found)
+	assert.Equal(t, 2, cache.Stats()["total_entries"])
+}
+
+func TestFromConfig(t *testing.T) {
+	defer viper.Reset()
+
+	viper.Set("cache_max_entries", 50)
+	viper.Set("cache_max_bytes", "1024")
+	viper.Set("cache_ignore_headers", true)
+
+	cache, err := FromConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 50, cache.maxEntries)
+	assert.Equal(t, 1024, cache.maxBytes)
+	assert.True(t, cache.ignoreCacheHeaders)
+
+	// Explicit options override configuration
+	cache, err = FromConfig(WithMaxEntries(5))
+	require.NoError(t, err)
+	assert.Equal(t, 5, cache.maxEntries)
+
+	viper.Set("cache_negative_ttl", "30s")
+	cache, err = FromConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, cache.negativeTTL)
+
+	viper.Set("cache_negative_ttl", "never")
+	_, err = FromConfig()
+	assert.Error(t, err)
+	viper.Set("cache_negative_ttl", "")
+
+	viper.Set("cache_endpoint_failure_ttl", "1m")
+	cache, err = FromConfig()
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, cache.endpointFailureTTL)
+
+	viper.Set("cache_endpoint_failure_ttl", "0")
+	cache, err = FromConfig()
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), cache.endpointFailureTTL)
+
+	viper.Set("cache_endpoint_failure_ttl", "soon")
+	_, err = FromConfig()
+	assert.Error(t, err)
+	viper.Set("cache_endpoint_failure_ttl", "")
+
+	viper.Set("cache_ttl", "90s")
+	cache, err = FromConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, cache.defaultTTL)
+
+	viper.Set("cache_ttl", "0")
+	_, err = FromConfig()
+	assert.Error(t, err)
+	viper.Set("cache_ttl", "")
+
+	viper.Set("cache_max_entries", -1)
+	_, err = FromConfig()
+	assert.Error(t, err)
+}
+
+func TestToolTTL(t *testing.T) {
+	defer viper.Reset()
+
+	ttl, err := ToolTTL("search", 2*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, ttl)
+
+	viper.Set("cache_ttl_search", "30s")
+	ttl, err = ToolTTL("search", 2*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, ttl)
+
+	// Zero defers to the cache's default TTL
+	viper.Set("cache_ttl_search", "0")
+	ttl, err = ToolTTL("search", 2*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), ttl)
+
+	viper.Set("cache_ttl_search", "-1m")
+	_, err = ToolTTL("search", 2*time.Minute)
+	assert.Error(t, err)
+}
+
+func TestCache_HitMissCounters(t *testing.T) {
+	cache := New(WithTTL(10 * time.Millisecond))
+
+	cache.Set("key", []byte("data"), "", "")
+	cache.Get("key")
+	cache.Get("key")
+	cache.Get("missing")
+
+	stats := cache.Stats()
+	assert.Equal(t, 2, stats["hits"])
+	assert.Equal(t, 1, stats["misses"])
+	assert.InDelta(t, 2.0/3.0, stats["hit_rate"], 0.001)
+
+	// Expired entries count as a miss and an expiration
+	time.Sleep(20 * time.Millisecond)
+	cache.Get("key")
+
+	stats = cache.Stats()
+	assert.Equal(t, 2, stats["misses"])
+	assert.Equal(t, 1, stats["expirations"])
+}
+
+func TestCache_StartJanitor(t *testing.T) {
+	cache := New(WithTTL(10 * time.Millisecond))
+	cache.Set("key1", []byte("data1"), "", "")
+	cache.Set("key2", []byte("data2"), "", "")
+
+	stop := cache.StartJanitor(5 * time.Millisecond)
+	defer stop()
+
+	assert.Eventually(t, func() bool {
+		return cache.Stats()["total_entries"] == 0
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 2, cache.Stats()["expirations"])
+
+	// Stopping twice is safe
+	stop()
+}
+
+func TestJanitorInterval(t *testing.T) {
+	defer viper.Reset()
+
+	interval, err := JanitorInterval()
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), interval)
+
+	viper.Set("cache_janitor_interval", "30s")
+	interval, err = JanitorInterval()
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, interval)
+
+	viper.Set("cache_janitor_interval", "often")
+	_, err = JanitorInterval()
+	assert.Error(t, err)
+}
+
+func TestCache_CompressesLargeEntries(t *testing.T) {
+	cache := New(WithCompressThreshold(1024))
+	large := []byte(strings.Repeat(`{"title":"Post","permalink":"https://example.com/posts/"},`, 200))
+	small := []byte(`{"title":"Post"}`)
+
+	cache.Set("large", large, "", "")
+	cache.Set("small", small, "", "")
+
+	stats := cache.Stats()
+	assert.Equal(t, 1, stats["compressed_entries"])
+	assert.Equal(t, len(large)+len(small), stats["raw_size"])
+	assert.Less(t, stats["total_size"], len(large)/4)
+
+	data, ok := cache.Get("large")
+	require.True(t, ok)
+	assert.Equal(t, large, data)
+	data, ok = cache.Get("small")
+	require.True(t, ok)
+	assert.Equal(t, small, data)
+
+	// Incompressible data is stored as it is
+	random := make([]byte, 4096)
+	_, err := rand.Read(random)
+	require.NoError(t, err)
+	cache.Set("random", random, "", "")
+	assert.Equal(t, 1, cache.Stats()["compressed_entries"])
+
+	cache.Delete("large")
+	assert.Equal(t, len(small)+len(random), cache.Stats()["raw_size"])
+	assert.Equal(t, len(small)+len(random), cache.Stats()["total_size"])
+
+	// Disabled compression keeps every entry as it is
+	cache = New(WithCompressThreshold(0))
+	cache.Set("large", large, "", "")
+	assert.Equal(t, 0, cache.Stats()["compressed_entries"])
+	assert.Equal(t, len(large), cache.Stats()["total_size"])
+}
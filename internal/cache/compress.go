@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// DefaultCompressThreshold is the size from which cached data is stored
+// gzip-compressed
+const DefaultCompressThreshold = 64 << 10
+
+// WithCompressThreshold stores entries of at least n bytes gzip-compressed,
+// decompressing them when they are read. Site indexes and feeds are mostly
+// text and shrink several times over. Zero disables compression.
+func WithCompressThreshold(n int) CacheOption {
+	return func(c *Cache) {
+		c.compressThreshold = n
+	}
+}
+
+// compress returns data gzip-compressed, or nil when compressing it doesn't
+// save space
+func compress(data []byte) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(data) / 4)
+	gz, _ := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+	if _, err := gz.Write(data); err != nil {
+		return nil
+	}
+	if err := gz.Close(); err != nil || buf.Len() >= len(data) {
+		return nil
+	}
+	return bytes.Clone(buf.Bytes())
+}
+
+// decompress returns gzip-compressed data of the given size as it was
+// before compression
+func decompress(data []byte, size int) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	out := bytes.NewBuffer(make([]byte, 0, size))
+	if _, err := io.Copy(out, gz); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// data returns the entry's data, decompressing it if it was stored compressed
+func (e *CacheEntry) data() ([]byte, error) {
+	if !e.Compressed {
+		return e.Data, nil
+	}
+	return decompress(e.Data, e.Size)
+}
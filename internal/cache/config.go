@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// FromConfig creates a Cache from the viper settings cache_ttl,
+// cache_max_entries, cache_max_bytes, cache_ignore_headers, cache_negative_ttl,
+// cache_endpoint_failure_ttl and cache_compress_threshold (bytes). The
+// janitor is configured separately with JanitorInterval. Explicit options are
+// applied last and take precedence over configuration.
+func FromConfig(opts ...CacheOption) (*Cache, error) {
+	var configOpts []CacheOption
+
+	if value := viper.GetString("cache_ttl"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid cache_ttl: %s", value)
+		}
+		configOpts = append(configOpts, WithTTL(ttl))
+	}
+
+	maxEntries := viper.GetInt("cache_max_entries")
+	if maxEntries < 0 {
+		return nil, fmt.Errorf("cache_max_entries must not be negative")
+	}
+	configOpts = append(configOpts, WithMaxEntries(maxEntries))
+
+	maxBytes := viper.GetInt("cache_max_bytes")
+	if maxBytes < 0 {
+		return nil, fmt.Errorf("cache_max_bytes must not be negative")
+	}
+	configOpts = append(configOpts, WithMaxBytes(maxBytes))
+
+	configOpts = append(configOpts, WithIgnoreCacheHeaders(viper.GetBool("cache_ignore_headers")))
+
+	if value := viper.GetString("cache_negative_ttl"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if value == "0" {
+			ttl, err = 0, nil
+		}
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid cache_negative_ttl: %s", value)
+		}
+		configOpts = append(configOpts, WithNegativeTTL(ttl))
+	}
+
+	if value := viper.GetString("cache_endpoint_failure_ttl"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if value == "0" {
+			ttl, err = 0, nil
+		}
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid cache_endpoint_failure_ttl: %s", value)
+		}
+		configOpts = append(configOpts, WithEndpointFailureTTL(ttl))
+	}
+
+	if viper.IsSet("cache_compress_threshold") {
+		threshold := viper.GetInt("cache_compress_threshold")
+		if threshold < 0 {
+			return nil, fmt.Errorf("cache_compress_threshold must not be negative")
+		}
+		configOpts = append(configOpts, WithCompressThreshold(threshold))
+	}
+
+	return New(append(configOpts, opts...)...), nil
+}
+
+// ToolTTL returns the cache TTL configured for a tool in cache_ttl_<tool>,
+// or fallback when it is not set. Zero means the cache's default TTL.
+func ToolTTL(tool string, fallback time.Duration) (time.Duration, error) {
+	key := "cache_ttl_" + tool
+	value := viper.GetString(key)
+	if value == "" {
+		return fallback, nil
+	}
+	if value == "0" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("invalid %s: %s", key, value)
+	}
+	return ttl, nil
+}
+
+// JanitorInterval returns the configured cache_janitor_interval. Zero means
+// the janitor is disabled.
+func JanitorInterval() (time.Duration, error) {
+	value := viper.GetString("cache_janitor_interval")
+	if value == "" || value == "0" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < 0 {
+		return 0, fmt.Errorf("invalid cache_janitor_interval: %s", value)
+	}
+	return interval, nil
+}
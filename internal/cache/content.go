@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// utf8BOM is the byte order mark some servers prefix UTF-8 bodies with
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// xmlEncoding matches the encoding an XML document declares, which Go's
+// XML decoder refuses unless it is UTF-8
+var xmlEncoding = regexp.MustCompile(`^<\?xml[^>]*?encoding\s*=\s*["']([a-zA-Z0-9_:.-]+)["']`)
+
+// metaCharset matches the charset an HTML page declares in a meta tag
+var metaCharset = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([a-z0-9_:.-]+)`)
+
+// windows1252 maps the bytes 0x80 to 0x9F of windows-1252 to the runes
+// they stand for; the other bytes are the Unicode code points of the same
+// value
+var windows1252 = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', '\u008D', 'Ž', '\u008F',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', '\u009D', 'ž', 'Ÿ',
+}
+
+// latin1Labels are the charset labels decoded as windows-1252, which, as
+// in browsers, includes ISO-8859-1 and US-ASCII: pages labelled ISO-8859-1
+// routinely hold windows-1252 punctuation
+var latin1Labels = map[string]bool{
+	"windows-1252": true, "cp1252": true, "x-cp1252": true,
+	"iso-8859-1": true, "iso8859-1": true, "iso_8859-1": true, "latin1": true, "l1": true,
+	"us-ascii": true, "ascii": true,
+}
+
+// mediaType returns the media type of a Content-Type header, or of body
+// when the header is missing
+func mediaType(contentType string, body []byte) string {
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	parsed, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return parsed
+}
+
+// isHTML reports whether a response is an HTML page, going by its
+// Content-Type or, when it declares none, by sniffing the body
+func isHTML(contentType string, body []byte) bool {
+	switch mediaType(contentType, body) {
+	case "text/html", "application/xhtml+xml":
+		return true
+	default:
+		return false
+	}
+}
+
+// charset returns the lowercased charset a response declares in its
+// Content-Type or, for XML documents, in the XML declaration or, for HTML
+// pages, in a meta tag near the start of the body
+func charset(contentType string, body []byte) string {
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		return strings.ToLower(strings.Trim(params["charset"], `"' `))
+	}
+	if match := xmlEncoding.FindSubmatch(body); match != nil {
+		return strings.ToLower(string(match[1]))
+	}
+	if isHTML(contentType, body) {
+		head := body[:min(len(body), 1024)]
+		if match := metaCharset.FindSubmatch(head); match != nil {
+			return strings.ToLower(string(match[1]))
+		}
+	}
+	return ""
+}
+
+// toUTF8 returns body as UTF-8 without a byte order mark. Bodies declared
+// as ISO-8859-1 or windows-1252 are transcoded unless they are already
+// valid UTF-8, as servers often label UTF-8 with a stale charset, and the
+// XML declaration of a transcoded document is changed to match. Other
+// charsets are passed through unchanged.
+func toUTF8(contentType string, body []byte) []byte {
+	body = bytes.TrimPrefix(body, utf8BOM)
+	if !latin1Labels[charset(contentType, body)] {
+		return body
+	}
+	if match := xmlEncoding.FindSubmatchIndex(body); match != nil {
+		declared := append([]byte{}, body[:match[2]]...)
+		declared = append(declared, "UTF-8"...)
+		body = append(declared, body[match[3]:]...)
+	}
+	if utf8.Valid(body) {
+		return body
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(body) + len(body)/8)
+	for _, b := range body {
+		switch {
+		case b < 0x80:
+			buf.WriteByte(b)
+		case b < 0xA0:
+			buf.WriteRune(windows1252[b-0x80])
+		default:
+			buf.WriteRune(rune(b))
+		}
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsHTML(t *testing.T) {
+	assert.True(t, isHTML("text/html; charset=utf-8", nil))
+	assert.True(t, isHTML("application/xhtml+xml", nil))
+	assert.True(t, isHTML("", []byte("<!DOCTYPE html><html><body>Not found</body></html>")))
+	assert.False(t, isHTML("application/json", []byte("<html></html>")))
+	assert.False(t, isHTML("", []byte(`{"pages": []}`)))
+}
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		expected    string
+	}{
+		{name: "utf-8", contentType: "application/json", body: []byte(`{"title": "Café"}`), expected: `{"title": "Café"}`},
+		{name: "byte order mark", contentType: "application/json", body: []byte("\xEF\xBB\xBF{}"), expected: `{}`},
+		{name: "iso-8859-1 header", contentType: "text/html; charset=ISO-8859-1", body: []byte("<p>Caf\xE9</p>"), expected: "<p>Café</p>"},
+		{name: "windows-1252 punctuation", contentType: "text/html; charset=windows-1252", body: []byte("\x93quoted\x94 \x96 \x80"), expected: "“quoted” – €"},
+		{name: "meta charset", contentType: "text/html", body: []byte("<html><head><meta charset=\"iso-8859-1\"></head><body>na\xEFve</body></html>"), expected: `<html><head><meta charset="iso-8859-1"></head><body>naïve</body></html>`},
+		{name: "mislabelled utf-8", contentType: "text/html; charset=iso-8859-1", body: []byte("<p>Café</p>"), expected: "<p>Café</p>"},
+		{name: "other charsets untouched", contentType: "text/html; charset=shift_jis", body: []byte("\x82\xa0"), expected: "\x82\xa0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, string(toUTF8(tt.contentType, tt.body)))
+		})
+	}
+}
+
+func TestToUTF8_XMLDeclaration(t *testing.T) {
+	body := []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n<rss><title>Caf\xE9</title></rss>")
+
+	converted := toUTF8("application/rss+xml", body)
+	assert.Equal(t, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<rss><title>Café</title></rss>", string(converted))
+
+	// Go's decoder now accepts the document
+	var doc struct {
+		Title string `xml:"title"`
+	}
+	require.NoError(t, xml.Unmarshal(converted, &doc))
+	assert.Equal(t, "Café", doc.Title)
+}
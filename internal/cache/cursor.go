@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CursorTTL is how long the results a cursor resumes are kept
+const CursorTTL = 15 * time.Minute
+
+// cursorKeyPrefix marks the cache entries holding cursor state
+const cursorKeyPrefix = "cursor:"
+
+// RequestHash returns a hash identifying a request by the parameters that
+// determine its results, so a cursor only resumes the request it was
+// issued for
+func RequestHash(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// EncodeCursor returns an opaque cursor resuming the results of the request
+// with hash at offset
+func EncodeCursor(hash string, offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(hash + ":" + strconv.Itoa(offset)))
+}
+
+// DecodeCursor returns the offset a cursor resumes the request with hash at.
+// An empty cursor starts at the beginning.
+func DecodeCursor(cursor, hash string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	cursorHash, rawOffset, ok := strings.Cut(string(data), ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	offset, err := strconv.Atoi(rawOffset)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	if cursorHash != hash {
+		return 0, fmt.Errorf("cursor was issued for a different request")
+	}
+	return offset, nil
+}
+
+// CursorKey returns the key the state of the request with hash is kept under
+func CursorKey(hash string) string {
+	return cursorKeyPrefix + hash
+}
+
+// SetCursorState keeps the results of the request with hash for its cursors
+// to resume without recomputing them. The state is evicted like any other
+// entry, so callers must be able to recompute it.
+func (c *Cache) SetCursorState(hash string, data []byte) {
+	c.set(CursorKey(hash), data, "", "", CursorTTL)
+}
+
+// CursorState returns the results kept for the request with hash
+func (c *Cache) CursorState(hash string) ([]byte, bool) {
+	return c.Get(CursorKey(hash))
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	hash := RequestHash("section", "https://example.com", "posts")
+	assert.Len(t, hash, 32)
+	assert.Equal(t, hash, RequestHash("section", "https://example.com", "posts"))
+	assert.NotEqual(t, hash, RequestHash("section", "https://example.com", "post", "s"))
+
+	offset, err := DecodeCursor("", hash)
+	require.NoError(t, err)
+	assert.Equal(t, 0, offset)
+
+	offset, err = DecodeCursor(EncodeCursor(hash, 40), hash)
+	require.NoError(t, err)
+	assert.Equal(t, 40, offset)
+
+	// Cursors only resume the request they were issued for
+	_, err = DecodeCursor(EncodeCursor(hash, 40), RequestHash("section", "https://example.com", "docs"))
+	assert.ErrorContains(t, err, "different request")
+
+	for _, cursor := range []string{"not base64!", EncodeCursor(hash, -1), "bm9jb2xvbg"} {
+		_, err := DecodeCursor(cursor, hash)
+		assert.ErrorContains(t, err, "invalid cursor", cursor)
+	}
+}
+
+func TestCache_CursorState(t *testing.T) {
+	cache := New()
+	hash := RequestHash("pages", "https://example.com")
+
+	_, ok := cache.CursorState(hash)
+	assert.False(t, ok)
+
+	cache.SetCursorState(hash, []byte(`{"pages":[]}`))
+	data, ok := cache.CursorState(hash)
+	require.True(t, ok)
+	assert.JSONEq(t, `{"pages":[]}`, string(data))
+
+	// Cursor state is cleared with the rest of the cache
+	cache.Clear()
+	_, ok = cache.CursorState(hash)
+	assert.False(t, ok)
+}
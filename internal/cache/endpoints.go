@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tracing"
+)
+
+// DefaultEndpointFailureTTL is how long an endpoint found missing is skipped
+const DefaultEndpointFailureTTL = 10 * time.Minute
+
+// ErrEndpointMissing is returned by FetchEndpoint for endpoints skipped
+// because they were recently found missing
+var ErrEndpointMissing = errors.New("endpoint recently found missing")
+
+// WithEndpointFailureTTL sets how long an endpoint found missing is skipped
+// by FetchEndpoint. Zero disables skipping.
+func WithEndpointFailureTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.endpointFailureTTL = ttl
+	}
+}
+
+// WithEndpointObserver sets a function told about every site endpoint found
+// missing, for metrics
+func WithEndpointObserver(observe func(siteURL, path string)) CacheOption {
+	return func(c *Cache) {
+		c.endpointObserver = observe
+	}
+}
+
+// Missing reports whether err shows an endpoint does not exist or does not
+// serve what was asked for: a 4xx status other than 408 and 429, or a
+// response that failed validation. Server and network errors may pass.
+func Missing(err error) bool {
+	if errors.Is(err, ErrInvalidResponse) || errors.Is(err, ErrEndpointMissing) {
+		return true
+	}
+	var status *StatusError
+	if !errors.As(err, &status) {
+		return false
+	}
+	return status.StatusCode >= 400 && status.StatusCode < 500 &&
+		status.StatusCode != http.StatusRequestTimeout && status.StatusCode != http.StatusTooManyRequests
+}
+
+// FetchEndpoint is Fetch for one of the endpoints a tool tries on a site in
+// turn. Endpoints recently found Missing fail with ErrEndpointMissing
+// without a request, so later calls go straight to the endpoints that work,
+// unless the Policy of ctx bypasses the cache.
+func (c *Cache) FetchEndpoint(ctx context.Context, client Doer, siteURL, path, key, rawURL string, valid func([]byte) bool) (*FetchResult, error) {
+	ctx, span := tracing.Start(ctx, "endpoint "+path, tracing.KindInternal,
+		tracing.String("hugo_reader.site", siteURL),
+		tracing.String("hugo_reader.endpoint", path))
+	defer span.End()
+
+	// Endpoints are recorded per scope, so one refused to a call without
+	// credentials is still tried by calls with them
+	site := ScopedKey(ctx, siteURL)
+	if !PolicyFrom(ctx).Bypass && c.SkipEndpoint(site, path) {
+		span.SetAttributes(tracing.Bool("hugo_reader.endpoint.skipped", true))
+		return nil, fmt.Errorf("%w: %s", ErrEndpointMissing, path)
+	}
+	result, err := c.Fetch(ctx, client, key, rawURL, valid)
+	c.RecordEndpoint(site, path, err)
+	span.RecordError(err)
+	return result, err
+}
+
+// RecordEndpoint records the outcome of reading a site endpoint. Endpoints
+// that are Missing are skipped until the failure TTL passes; other errors
+// are not recorded, and a success clears the record.
+func (c *Cache) RecordEndpoint(siteURL, path string, err error) {
+	if err != nil && (!Missing(err) || errors.Is(err, ErrEndpointMissing)) {
+		return
+	}
+	if err != nil && c.endpointObserver != nil {
+		site, _, _ := strings.Cut(siteURL, "#scope=")
+		c.endpointObserver(site, path)
+	}
+	key := SnapshotKey(siteURL)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err == nil || c.endpointFailureTTL <= 0 {
+		delete(c.endpoints[key], path)
+		if len(c.endpoints[key]) == 0 {
+			delete(c.endpoints, key)
+		}
+		return
+	}
+	if c.endpoints[key] == nil {
+		c.endpoints[key] = make(map[string]time.Time)
+	}
+	c.endpoints[key][path] = time.Now().Add(c.endpointFailureTTL)
+}
+
+// SkipEndpoint reports whether an endpoint of a site was recently found missing
+func (c *Cache) SkipEndpoint(siteURL, path string) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	expires, found := c.endpoints[SnapshotKey(siteURL)][path]
+	return found && time.Now().Before(expires)
+}
+
+// MissingEndpoints returns the endpoints of a site currently being skipped
+func (c *Cache) MissingEndpoints(siteURL string) []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	now := time.Now()
+	missing := []string{}
+	for path, expires := range c.endpoints[SnapshotKey(siteURL)] {
+		if now.Before(expires) {
+			missing = append(missing, path)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissing(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "not found", err: &StatusError{StatusCode: http.StatusNotFound}, want: true},
+		{name: "forbidden", err: &StatusError{StatusCode: http.StatusForbidden}, want: true},
+		{name: "wrapped", err: fmt.Errorf("fetch: %w", &StatusError{StatusCode: http.StatusGone}), want: true},
+		{name: "invalid response", err: ErrInvalidResponse, want: true},
+		{name: "skipped", err: ErrEndpointMissing, want: true},
+		{name: "timeout", err: &StatusError{StatusCode: http.StatusRequestTimeout}, want: false},
+		{name: "rate limited", err: &StatusError{StatusCode: http.StatusTooManyRequests}, want: false},
+		{name: "server error", err: &StatusError{StatusCode: http.StatusInternalServerError}, want: false},
+		{name: "network error", err: fmt.Errorf("connection refused"), want: false},
+		{name: "no error", err: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Missing(tt.err))
+		})
+	}
+}
+
+func TestCache_FetchEndpoint(t *testing.T) {
+	requests := map[string]int{}
+	available := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch {
+		case r.URL.Path == "/index.json":
+			w.Write([]byte(`{}`))
+		case r.URL.Path == "/flaky.json":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/search.json" && available:
+			w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cache := New(WithTTL(time.Nanosecond))
+	ctx := context.Background()
+
+	// Missing endpoints are requested once and then skipped
+	_, err := cache.FetchEndpoint(ctx, server.Client(), server.URL, "/search.json", "search", server.URL+"/search.json", nil)
+	require.Error(t, err)
+	_, err = cache.FetchEndpoint(ctx, server.Client(), server.URL+"/", "/search.json", "search", server.URL+"/search.json", nil)
+	assert.ErrorIs(t, err, ErrEndpointMissing)
+	assert.Equal(t, 1, requests["/search.json"])
+	assert.Equal(t, []string{"/search.json"}, cache.MissingEndpoints(server.URL))
+
+	// Server errors may pass and are retried
+	for i := 0; i < 2; i++ {
+		_, err = cache.FetchEndpoint(ctx, server.Client(), server.URL, "/flaky.json", "flaky", server.URL+"/flaky.json", nil)
+		require.Error(t, err)
+	}
+	assert.Equal(t, 2, requests["/flaky.json"])
+
+	// Working endpoints are unaffected
+	_, err = cache.FetchEndpoint(ctx, server.Client(), server.URL, "/index.json", "index", server.URL+"/index.json", nil)
+	require.NoError(t, err)
+
+	// Clearing the cache forgets missing endpoints, and a success keeps
+	// the endpoint from being skipped
+	cache.Clear()
+	available = true
+	_, err = cache.FetchEndpoint(ctx, server.Client(), server.URL, "/search.json", "search", server.URL+"/search.json", nil)
+	require.NoError(t, err)
+	assert.Empty(t, cache.MissingEndpoints(server.URL))
+}
+
+func TestCache_RecordEndpoint(t *testing.T) {
+	notFound := &StatusError{StatusCode: http.StatusNotFound}
+
+	cache := New(WithEndpointFailureTTL(10 * time.Millisecond))
+	cache.RecordEndpoint("https://example.com", "/search.json", notFound)
+	assert.True(t, cache.SkipEndpoint("https://example.com/", "/search.json"))
+	assert.False(t, cache.SkipEndpoint("https://other.example.com", "/search.json"))
+	assert.Equal(t, 1, cache.Stats()["endpoint_sites"])
+
+	// Records expire with the failure TTL
+	time.Sleep(20 * time.Millisecond)
+	assert.False(t, cache.SkipEndpoint("https://example.com", "/search.json"))
+	assert.Empty(t, cache.MissingEndpoints("https://example.com"))
+
+	// A zero TTL disables skipping
+	cache = New(WithEndpointFailureTTL(0))
+	cache.RecordEndpoint("https://example.com", "/search.json", notFound)
+	assert.False(t, cache.SkipEndpoint("https://example.com", "/search.json"))
+
+	// Deleting a host forgets its endpoints
+	cache = New()
+	cache.RecordEndpoint("https://example.com", "/search.json", notFound)
+	cache.DeleteByHost("example.com")
+	assert.False(t, cache.SkipEndpoint("https://example.com", "/search.json"))
+}
+
+func TestWithEndpointObserver(t *testing.T) {
+	var observed []string
+	cache := New(WithEndpointObserver(func(siteURL, path string) {
+		observed = append(observed, siteURL+path)
+	}))
+
+	cache.RecordEndpoint("https://example.com", "/search.json", &StatusError{StatusCode: http.StatusNotFound})
+	cache.RecordEndpoint("https://example.com", "/api/search.json", &StatusError{StatusCode: http.StatusServiceUnavailable})
+	cache.RecordEndpoint("https://example.com", "/index.json", nil)
+	assert.Equal(t, []string{"https://example.com/search.json"}, observed)
+}
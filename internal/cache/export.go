@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// exportVersion is the version of the format Export writes
+const exportVersion = 1
+
+// Export is a cache serialized by Cache.Export
+type Export struct {
+	Version    int                             `json:"version"`
+	ExportedAt time.Time                       `json:"exported_at"`
+	Entries    []ExportedEntry                 `json:"entries"`
+	Snapshots  map[string][]*Snapshot          `json:"snapshots,omitempty"`
+	Endpoints  map[string]map[string]time.Time `json:"endpoints,omitempty"`
+}
+
+// ExportedEntry is a cache entry in an Export. Data is kept as stored, so
+// compressed entries stay compressed.
+type ExportedEntry struct {
+	Key          string        `json:"key"`
+	Data         []byte        `json:"data,omitempty"`
+	ETag         string        `json:"etag,omitempty"`
+	LastModified string        `json:"last_modified,omitempty"`
+	CachedAt     time.Time     `json:"cached_at"`
+	TTL          time.Duration `json:"ttl"`
+	Status       int           `json:"status,omitempty"`
+	Compressed   bool          `json:"compressed,omitempty"`
+	Size         int           `json:"size"`
+}
+
+// ExportSummary counts what Export wrote or Import loaded
+type ExportSummary struct {
+	Entries       int `json:"entries"`
+	SnapshotSites int `json:"snapshot_sites"`
+	EndpointSites int `json:"endpoint_sites"`
+
+	// Expired counts the entries Import skipped because they could no
+	// longer be used or revalidated
+	Expired int `json:"expired,omitempty"`
+}
+
+// Export writes the cache's entries, snapshots and missing endpoints as
+// JSON to w, for Import to load later. When host is set, only what belongs
+// to that host is written; a host without a port matches any port. Cursor
+// states, which are only useful for a few minutes, are left out.
+func (c *Cache) Export(w io.Writer, host string) (ExportSummary, error) {
+	match := func(key string) bool { return true }
+	if host != "" {
+		match = hostMatcher(host)
+	}
+
+	export := Export{
+		Version:    exportVersion,
+		ExportedAt: time.Now().UTC(),
+		Entries:    []ExportedEntry{},
+		Snapshots:  make(map[string][]*Snapshot),
+		Endpoints:  make(map[string]map[string]time.Time),
+	}
+
+	c.mutex.RLock()
+	// Least recently used first, so importing restores the LRU order
+	for element := c.lru.Back(); element != nil; element = element.Prev() {
+		key := element.Value.(string)
+		if strings.HasPrefix(key, cursorKeyPrefix) || !match(key) {
+			continue
+		}
+		entry := c.entries[key]
+		export.Entries = append(export.Entries, ExportedEntry{
+			Key:          key,
+			Data:         entry.Data,
+			ETag:         entry.ETag,
+			LastModified: entry.LastModified,
+			CachedAt:     entry.CachedAt,
+			TTL:          entry.TTL,
+			Status:       entry.Status,
+			Compressed:   entry.Compressed,
+			Size:         entry.Size,
+		})
+	}
+	for site, history := range c.snapshots {
+		if match(site) {
+			export.Snapshots[site] = history
+		}
+	}
+	for site, endpoints := range c.endpoints {
+		if match(site) {
+			export.Endpoints[site] = endpoints
+		}
+	}
+	// Encode under the lock: snapshots and endpoint maps are shared
+	err := json.NewEncoder(w).Encode(export)
+	c.mutex.RUnlock()
+	if err != nil {
+		return ExportSummary{}, fmt.Errorf("failed to write cache export: %w", err)
+	}
+
+	summary := ExportSummary{
+		Entries:       len(export.Entries),
+		SnapshotSites: len(export.Snapshots),
+		EndpointSites: len(export.Endpoints),
+	}
+	c.logger.Info("Exported cache", "host", host, "entries", summary.Entries, "snapshot_sites", summary.SnapshotSites)
+	return summary, nil
+}
+
+// Import loads a cache written by Export, replacing entries under the same
+// keys. Entries keep the age they had when exported, so those that have
+// since expired and cannot be revalidated are skipped. Imported snapshots
+// are merged with those already recorded, and the cache's limits apply.
+func (c *Cache) Import(r io.Reader) (ExportSummary, error) {
+	var export Export
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return ExportSummary{}, fmt.Errorf("invalid cache export: %w", err)
+	}
+	if export.Version != exportVersion {
+		return ExportSummary{}, fmt.Errorf("unsupported cache export version %d (expected %d)", export.Version, exportVersion)
+	}
+
+	var summary ExportSummary
+	c.mutex.Lock()
+	for _, exported := range export.Entries {
+		entry := &CacheEntry{
+			Data:         exported.Data,
+			ETag:         exported.ETag,
+			LastModified: exported.LastModified,
+			CachedAt:     exported.CachedAt,
+			TTL:          exported.TTL,
+			Status:       exported.Status,
+			Compressed:   exported.Compressed,
+			Size:         exported.Size,
+		}
+		if exported.Key == "" || (entry.IsExpired() && (entry.Status != 0 || !entry.revalidatable())) {
+			summary.Expired++
+			continue
+		}
+		if (c.maxBytes > 0 && len(entry.Data) > c.maxBytes) || (entry.Status != 0 && c.negativeTTL <= 0) {
+			continue
+		}
+		c.removeLocked(exported.Key)
+		entry.element = c.lru.PushFront(exported.Key)
+		c.entries[exported.Key] = entry
+		c.totalBytes += len(entry.Data)
+		c.rawBytes += entry.Size
+		summary.Entries++
+	}
+	c.evictLocked()
+
+	for site, history := range export.Snapshots {
+		c.snapshots[site] = mergeSnapshots(c.snapshots[site], history, c.snapshotHistory)
+	}
+	for site, endpoints := range export.Endpoints {
+		if c.endpoints[site] == nil {
+			c.endpoints[site] = make(map[string]time.Time)
+		}
+		for path, retryAt := range endpoints {
+			if retryAt.After(c.endpoints[site][path]) {
+				c.endpoints[site][path] = retryAt
+			}
+		}
+	}
+	summary.SnapshotSites = len(export.Snapshots)
+	summary.EndpointSites = len(export.Endpoints)
+	c.mutex.Unlock()
+
+	c.logger.Info("Imported cache", "entries", summary.Entries, "expired", summary.Expired, "snapshot_sites", summary.SnapshotSites)
+	return summary, nil
+}
+
+// mergeSnapshots combines two snapshot histories of a site in the order
+// they were taken, dropping duplicates and keeping the latest limit
+func mergeSnapshots(current, imported []*Snapshot, limit int) []*Snapshot {
+	merged := append(append([]*Snapshot{}, current...), imported...)
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].TakenAt.Before(merged[j].TakenAt) })
+
+	history := merged[:0]
+	for _, snapshot := range merged {
+		if n := len(history); n > 0 && history[n-1].TakenAt.Equal(snapshot.TakenAt) && history[n-1].Source == snapshot.Source {
+			continue
+		}
+		history = append(history, snapshot)
+	}
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	return history
+}
+
+// hostMatcher returns a function reporting whether a cache key, or a site
+// URL snapshots and endpoints are recorded under, belongs to host. A host
+// without a port matches any port.
+func hostMatcher(host string) func(key string) bool {
+	host = strings.ToLower(host)
+	return func(key string) bool {
+		keyHost := hostOf(key)
+		if keyHost == host {
+			return true
+		}
+		if !strings.Contains(host, ":") {
+			if hostname, _, found := strings.Cut(keyHost, ":"); found && hostname == host {
+				return true
+			}
+		}
+		return false
+	}
+}
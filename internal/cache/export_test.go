@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_ExportImport_KeepsCompressedEntries(t *testing.T) {
+	source := New(WithCompressThreshold(1024))
+	large := []byte(strings.Repeat(`{"title":"Post"},`, 500))
+	source.Set("https://example.com/index.json", large, "", "")
+	source.SetCursorState("abc", []byte(`[]`))
+
+	var buf bytes.Buffer
+	summary, err := source.Export(&buf, "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Entries)
+
+	restored := New()
+	_, err = restored.Import(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored.Stats()["compressed_entries"])
+	assert.Equal(t, len(large), restored.Stats()["raw_size"])
+	data, ok := restored.Get("https://example.com/index.json")
+	require.True(t, ok)
+	assert.Equal(t, large, data)
+
+	// Cursor states are not exported
+	_, ok = restored.CursorState("abc")
+	assert.False(t, ok)
+}
+
+func TestCache_Import_SkipsExpiredEntries(t *testing.T) {
+	source := New(WithTTL(time.Millisecond))
+	source.Set("https://example.com/a.json", []byte(`{}`), "", "")
+	source.Set("https://example.com/b.json", []byte(`{}`), `"etag"`, "")
+
+	var buf bytes.Buffer
+	_, err := source.Export(&buf, "")
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	// The entry with a validator can still be revalidated
+	restored := New()
+	summary, err := restored.Import(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Entries)
+	assert.Equal(t, 1, summary.Expired)
+
+	_, err = restored.Import(strings.NewReader(`{"version": 99}`))
+	assert.Error(t, err)
+}
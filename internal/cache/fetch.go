@@ -0,0 +1,232 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tracing"
+)
+
+// StaleRetention is how long an expired entry with an ETag or Last-Modified
+// validator is kept so Fetch can revalidate it with a conditional request
+const StaleRetention = time.Hour
+
+var (
+	// ErrInvalidResponse is returned by Fetch when a response fails validation
+	ErrInvalidResponse = errors.New("response failed validation")
+
+	// ErrUnexpectedHTML is returned by Fetch, together with
+	// ErrInvalidResponse, when a response that fails validation is an HTML
+	// page, such as an error page served with status 200 in place of JSON
+	ErrUnexpectedHTML = errors.New("HTML page served instead of the expected data")
+)
+
+// Doer sends HTTP requests; *http.Client and *httpclient.Client satisfy it
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// StatusError is returned by Fetch for responses other than 200 and 304
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status: %d", e.StatusCode)
+}
+
+// FetchResult is the data returned by Fetch and where it came from
+type FetchResult struct {
+	Data []byte
+
+	// Cached is true when the data was served without a request
+	Cached bool
+
+	// Revalidated is true when an expired entry was confirmed current by a
+	// 304 Not Modified response
+	Revalidated bool
+
+	// Shared is true when the data was fetched by a concurrent call for the
+	// same key, which this call waited for instead of sending its own request
+	Shared bool
+}
+
+// Fetch returns the data for rawURL, serving fresh entries from the cache
+// and otherwise issuing a GET. Expired entries with an ETag or Last-Modified
+// validator are revalidated with a conditional request, so unchanged
+// resources are refreshed by a 304 instead of a full download. Downloaded
+// bodies are transcoded to UTF-8 from the charset they declare. valid, if
+// not nil, rejects cached or downloaded data the caller cannot use; an HTML
+// page rejected by it fails with ErrUnexpectedHTML.
+// Resources that returned 404 or 410 fail with the same StatusError without
+// a request until the negative TTL passes. Concurrent calls for the same key
+// share one request and its outcome. The Policy of ctx can shorten how long
+// entries are served, or bypass them, and its scope keeps the entries of
+// calls with credentials apart.
+func (c *Cache) Fetch(ctx context.Context, client Doer, key, rawURL string, valid func([]byte) bool) (result *FetchResult, err error) {
+	key = ScopedKey(ctx, key)
+	ctx, span := tracing.Start(ctx, "cache fetch", tracing.KindInternal, tracing.String("url.full", rawURL))
+	defer func() {
+		span.SetAttributes(
+			tracing.Bool("hugo_reader.cache.hit", result != nil && result.Cached),
+			tracing.Bool("hugo_reader.cache.revalidated", result != nil && result.Revalidated),
+			tracing.Bool("hugo_reader.cache.shared", result != nil && result.Shared))
+		span.RecordError(err)
+		span.End()
+	}()
+
+	policy := PolicyFrom(ctx)
+	if policy.Bypass {
+		c.logger.DebugContext(ctx, "Bypassing cache", "key", key)
+	} else {
+		if status, hit := c.GetNegative(key); hit {
+			c.logger.DebugContext(ctx, "Negative cache hit", "key", key, "status", status)
+			span.SetAttributes(tracing.Bool("hugo_reader.cache.negative_hit", true))
+			return nil, &StatusError{StatusCode: status}
+		}
+
+		if data, hit := c.get(key, policy.TTL); hit {
+			if valid == nil || valid(data) {
+				return &FetchResult{Data: data, Cached: true}, nil
+			}
+			c.logger.DebugContext(ctx, "Cached data failed validation, invalidating", "key", key)
+			c.Delete(key)
+		}
+	}
+
+	result, err, shared := c.flights.do(key, func() (*FetchResult, error) {
+		return c.fetch(ctx, client, key, rawURL, valid)
+	})
+	if !shared {
+		return result, err
+	}
+
+	c.mutex.Lock()
+	c.sharedFetches++
+	c.mutex.Unlock()
+
+	// The call waited for was bound to another caller's context; when that
+	// caller gave up, fetch again unless this one has too
+	if err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) && ctx.Err() == nil {
+		return c.fetch(ctx, client, key, rawURL, valid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if valid != nil && !valid(result.Data) {
+		return nil, ErrInvalidResponse
+	}
+	return &FetchResult{Data: result.Data, Revalidated: result.Revalidated, Shared: true}, nil
+}
+
+// fetch requests rawURL for Fetch, revalidating a stale entry unless the
+// cache is bypassed, and caches the response
+func (c *Cache) fetch(ctx context.Context, client Doer, key, rawURL string, valid func([]byte) bool) (*FetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := PolicyFrom(ctx)
+	var stale *CacheEntry
+	if !policy.Bypass {
+		stale = c.staleEntry(key, policy.TTL)
+	}
+	if stale != nil {
+		if stale.ETag != "" {
+			req.Header.Set("If-None-Match", stale.ETag)
+		}
+		if stale.LastModified != "" {
+			req.Header.Set("If-Modified-Since", stale.LastModified)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && stale != nil {
+		c.refresh(key, stale, resp.Header, policy.ttl(c.defaultTTL))
+		data, err := stale.data()
+		if err != nil {
+			c.Delete(key)
+			return nil, fmt.Errorf("failed to decompress cached response: %w", err)
+		}
+		return &FetchResult{Data: data, Revalidated: true}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if negativeStatus(resp.StatusCode) {
+			c.SetNegative(key, resp.StatusCode)
+		}
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Validators and callers expect UTF-8
+	contentType := resp.Header.Get("Content-Type")
+	body = toUTF8(contentType, body)
+
+	if valid != nil && !valid(body) {
+		if isHTML(contentType, body) {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidResponse, ErrUnexpectedHTML)
+		}
+		return nil, ErrInvalidResponse
+	}
+
+	// Cache the validated response for as long as the server allows
+	c.setFromResponse(key, body, resp.Header, policy.ttl(c.defaultTTL))
+	return &FetchResult{Data: body}, nil
+}
+
+// staleEntry returns a copy of the expired entry for key, or of one cached
+// more than maxAge ago, if it can still be revalidated
+func (c *Cache) staleEntry(key string, maxAge time.Duration) *CacheEntry {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entry, exists := c.entries[key]
+	if !exists || !(entry.IsExpired() || entry.olderThan(maxAge)) || !entry.revalidatable() {
+		return nil
+	}
+	stale := *entry
+	return &stale
+}
+
+// refresh extends an entry confirmed current by a 304, taking its new
+// lifetime, or else ttl, and validators from the response headers
+func (c *Cache) refresh(key string, stale *CacheEntry, header http.Header, ttl time.Duration) {
+	if !c.ignoreCacheHeaders {
+		if serverTTL, cacheable, ok := ttlFromHeaders(header, time.Now()); ok && cacheable {
+			ttl = serverTTL
+		}
+	}
+
+	c.mutex.Lock()
+	// Skip entries replaced while the request was in flight
+	if entry, exists := c.entries[key]; exists && entry.element == stale.element {
+		entry.CachedAt = time.Now()
+		entry.TTL = ttl
+		if etag := header.Get("ETag"); etag != "" {
+			entry.ETag = etag
+		}
+		if lastModified := header.Get("Last-Modified"); lastModified != "" {
+			entry.LastModified = lastModified
+		}
+		c.lru.MoveToFront(entry.element)
+	}
+	c.revalidations++
+	c.mutex.Unlock()
+
+	c.logger.Debug("Cache entry revalidated", "key", key, "ttl", ttl)
+}
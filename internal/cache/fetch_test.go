@@ -0,0 +1,322 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Fetch(t *testing.T) {
+	requests := 0
+	body := `{"title": "v1"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/index.json":
+			if r.Header.Get("If-None-Match") == `"v1"` && body == `{"title": "v1"}` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(body))
+		case "/plain.json":
+			w.Write([]byte(`{}`))
+		case "/error.html":
+			w.Write([]byte("<html></html>"))
+		case "/latin1.html":
+			w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+			w.Write([]byte("<html><body>Caf\xE9</body></html>"))
+		case "/list.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cache := New(WithTTL(10 * time.Millisecond))
+	ctx := context.Background()
+	valid := func(data []byte) bool { return data[0] == '{' }
+
+	// The first fetch downloads and caches the response
+	result, err := cache.Fetch(ctx, server.Client(), "index", server.URL+"/index.json", valid)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(result.Data))
+	assert.False(t, result.Cached)
+	assert.Equal(t, 1, requests)
+	etag, _, ok := cache.EntryValidators("index")
+	assert.True(t, ok)
+	assert.Equal(t, `"v1"`, etag)
+
+	// Fresh entries are served without a request
+	result, err = cache.Fetch(ctx, server.Client(), "index", server.URL+"/index.json", valid)
+	require.NoError(t, err)
+	assert.True(t, result.Cached)
+	assert.Equal(t, 1, requests)
+
+	// Expired entries are revalidated with a conditional request
+	time.Sleep(20 * time.Millisecond)
+	result, err = cache.Fetch(ctx, server.Client(), "index", server.URL+"/index.json", valid)
+	require.NoError(t, err)
+	assert.True(t, result.Revalidated)
+	assert.Equal(t, body, string(result.Data))
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1, cache.Stats()["revalidations"])
+
+	// Revalidation refreshes the entry
+	_, found := cache.Get("index")
+	assert.True(t, found)
+
+	// Changed resources are downloaded again
+	time.Sleep(20 * time.Millisecond)
+	body = `{"title": "v2"}`
+	result, err = cache.Fetch(ctx, server.Client(), "index", server.URL+"/index.json", valid)
+	require.NoError(t, err)
+	assert.False(t, result.Revalidated)
+	assert.Equal(t, body, string(result.Data))
+
+	_, err = cache.Fetch(ctx, server.Client(), "missing", server.URL+"/missing.json", valid)
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+
+	// HTML served where the caller expects other data is reported as such
+	_, err = cache.Fetch(ctx, server.Client(), "error", server.URL+"/error.html", valid)
+	assert.ErrorIs(t, err, ErrInvalidResponse)
+	assert.ErrorIs(t, err, ErrUnexpectedHTML)
+	_, found = cache.Get("error")
+	assert.False(t, found)
+
+	_, err = cache.Fetch(ctx, server.Client(), "list", server.URL+"/list.json", valid)
+	assert.ErrorIs(t, err, ErrInvalidResponse)
+	assert.NotErrorIs(t, err, ErrUnexpectedHTML)
+
+	// Bodies in other charsets are cached as UTF-8
+	result, err = cache.Fetch(ctx, server.Client(), "latin1", server.URL+"/latin1.html", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "<html><body>Café</body></html>", string(result.Data))
+}
+
+func TestCache_Fetch_WithoutValidators(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("If-None-Match"))
+		assert.Empty(t, r.Header.Get("If-Modified-Since"))
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	cache := New(WithTTL(10 * time.Millisecond))
+	_, err := cache.Fetch(context.Background(), server.Client(), "key", server.URL, nil)
+	require.NoError(t, err)
+
+	// Entries without validators cannot be revalidated and are discarded
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 1, cache.CleanExpired())
+
+	result, err := cache.Fetch(context.Background(), server.Client(), "key", server.URL, nil)
+	require.NoError(t, err)
+	assert.False(t, result.Revalidated)
+}
+
+func TestCache_ExpiredEntriesKeptForRevalidation(t *testing.T) {
+	cache := New(WithTTL(10 * time.Millisecond))
+	cache.Set("etag", []byte("data"), `"abc"`, "")
+	cache.Set("plain", []byte("data"), "", "")
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, found := cache.Get("etag")
+	assert.False(t, found)
+
+	// Only the entry without validators is removed
+	assert.Equal(t, 1, cache.CleanExpired())
+	assert.Equal(t, 1, cache.Stats()["total_entries"])
+	assert.NotNil(t, cache.staleEntry("etag", 0))
+}
+
+func TestCache_Fetch_RevalidatesCompressedEntries(t *testing.T) {
+	body := `{"pages":[` + strings.Repeat(`{"title":"Post"},`, 500) + `{}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	cache := New(WithTTL(10*time.Millisecond), WithCompressThreshold(1024))
+	ctx := context.Background()
+
+	_, err := cache.Fetch(ctx, server.Client(), "index", server.URL, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, cache.Stats()["compressed_entries"])
+
+	time.Sleep(20 * time.Millisecond)
+	result, err := cache.Fetch(ctx, server.Client(), "index", server.URL, nil)
+	require.NoError(t, err)
+	assert.True(t, result.Revalidated)
+	assert.Equal(t, body, string(result.Data))
+}
+
+func TestCache_Fetch_SharesConcurrentRequests(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		w.Write([]byte(`{"title": "shared"}`))
+	}))
+	defer server.Close()
+
+	cache := New()
+	const callers = 5
+	results := make(chan *FetchResult, callers)
+	for range callers {
+		go func() {
+			result, err := cache.Fetch(context.Background(), server.Client(), "index", server.URL, nil)
+			assert.NoError(t, err)
+			results <- result
+		}()
+	}
+
+	// Wait for every caller to join the request in progress before it ends
+	require.Eventually(t, func() bool {
+		cache.flights.mu.Lock()
+		defer cache.flights.mu.Unlock()
+		f := cache.flights.flights["index"]
+		return f != nil && f.waiters == callers-1
+	}, time.Second, time.Millisecond)
+	close(release)
+
+	shared := 0
+	for range callers {
+		result := <-results
+		require.NotNil(t, result)
+		assert.Equal(t, `{"title": "shared"}`, string(result.Data))
+		if result.Shared {
+			shared++
+		}
+	}
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Equal(t, callers-1, shared)
+	assert.Equal(t, callers-1, cache.Stats()["shared_fetches"])
+	assert.Equal(t, 1, cache.Stats()["total_entries"])
+}
+
+func TestCache_Fetch_RetriesWhenSharedRequestIsCanceled(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	cache := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := cache.Fetch(ctx, server.Client(), "index", server.URL, nil)
+		first <- err
+	}()
+	require.Eventually(t, func() bool { return requests.Load() == 1 }, time.Second, time.Millisecond)
+
+	second := make(chan *FetchResult, 1)
+	go func() {
+		result, err := cache.Fetch(context.Background(), server.Client(), "index", server.URL, nil)
+		assert.NoError(t, err)
+		second <- result
+	}()
+	require.Eventually(t, func() bool {
+		cache.flights.mu.Lock()
+		defer cache.flights.mu.Unlock()
+		f := cache.flights.flights["index"]
+		return f != nil && f.waiters == 1
+	}, time.Second, time.Millisecond)
+	cancel()
+
+	// The caller that gave up fails, while the one still waiting fetches
+	// the resource itself
+	assert.ErrorIs(t, <-first, context.Canceled)
+	result := <-second
+	require.NotNil(t, result)
+	assert.Equal(t, `{}`, string(result.Data))
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestCache_Fetch_Policy(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	cache := New(WithTTL(time.Hour))
+	ctx := context.Background()
+	_, err := cache.Fetch(ctx, server.Client(), "index", server.URL, nil)
+	require.NoError(t, err)
+
+	// Entries younger than the policy's TTL are served from the cache
+	result, err := cache.Fetch(ContextWithPolicy(ctx, Policy{TTL: time.Minute}), server.Client(), "index", server.URL, nil)
+	require.NoError(t, err)
+	assert.True(t, result.Cached)
+	assert.Equal(t, int32(1), requests.Load())
+
+	// Older entries are fetched again and stored with the policy's TTL
+	time.Sleep(20 * time.Millisecond)
+	shortCtx := ContextWithPolicy(ctx, Policy{TTL: 10 * time.Millisecond})
+	result, err = cache.Fetch(shortCtx, server.Client(), "index", server.URL, nil)
+	require.NoError(t, err)
+	assert.False(t, result.Cached)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, 10*time.Millisecond, cache.entries["index"].TTL)
+
+	// Bypassing ignores fresh entries and missing resources
+	bypassCtx := ContextWithPolicy(ctx, Policy{Bypass: true})
+	result, err = cache.Fetch(bypassCtx, server.Client(), "index", server.URL, nil)
+	require.NoError(t, err)
+	assert.False(t, result.Cached)
+	assert.Equal(t, int32(3), requests.Load())
+
+	_, err = cache.Fetch(ctx, server.Client(), "missing", server.URL+"/missing", nil)
+	require.Error(t, err)
+	_, err = cache.Fetch(ctx, server.Client(), "missing", server.URL+"/missing", nil)
+	require.Error(t, err)
+	assert.Equal(t, int32(4), requests.Load())
+	_, err = cache.Fetch(bypassCtx, server.Client(), "missing", server.URL+"/missing", nil)
+	require.Error(t, err)
+	assert.Equal(t, int32(5), requests.Load())
+}
+
+func TestCacheOptions(t *testing.T) {
+	options := CacheOptions{CacheTTLSeconds: -1}
+	assert.Error(t, options.Validate())
+	options.CacheTTLSeconds = MaxCacheTTLSeconds + 1
+	assert.Error(t, options.Validate())
+
+	// The tool's TTL applies unless the request sets its own
+	options = CacheOptions{}
+	require.NoError(t, options.Validate())
+	assert.Equal(t, Policy{TTL: 2 * time.Minute}, PolicyFrom(options.Apply(context.Background(), 2*time.Minute)))
+
+	options = CacheOptions{CacheTTLSeconds: 30, BypassCache: true}
+	require.NoError(t, options.Validate())
+	assert.Equal(t, Policy{TTL: 30 * time.Second, Bypass: true}, PolicyFrom(options.Apply(context.Background(), 2*time.Minute)))
+}
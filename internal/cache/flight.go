@@ -0,0 +1,47 @@
+package cache
+
+import "sync"
+
+// flight is a fetch in progress that concurrent callers wait for
+type flight struct {
+	done   chan struct{}
+	result *FetchResult
+	err    error
+
+	// waiters counts the callers sharing the outcome
+	waiters int
+}
+
+// flightGroup runs one fetch per key at a time, sharing its outcome with
+// every caller that asks for the same key while it is in progress
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// do calls fetch for key, or waits for the call already in progress for
+// it. shared is true when the outcome came from another caller's call.
+func (g *flightGroup) do(key string, fetch func() (*FetchResult, error)) (result *FetchResult, err error, shared bool) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+	if f, ok := g.flights[key]; ok {
+		f.waiters++
+		g.mu.Unlock()
+		<-f.done
+		return f.result, f.err, true
+	}
+	f := &flight{done: make(chan struct{})}
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		close(f.done)
+	}()
+	f.result, f.err = fetch()
+	return f.result, f.err, false
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxServerTTL caps lifetimes taken from server caching headers, so a site
+// serving its index with a year-long max-age is still refreshed daily
+const MaxServerTTL = 24 * time.Hour
+
+// WithIgnoreCacheHeaders makes SetFromResponse use the default TTL regardless
+// of Cache-Control and Expires headers
+func WithIgnoreCacheHeaders(ignore bool) CacheOption {
+	return func(c *Cache) {
+		c.ignoreCacheHeaders = ignore
+	}
+}
+
+// SetFromResponse stores data fetched with the given response headers. The
+// entry's TTL comes from Cache-Control max-age or Expires, less Age, falling
+// back to the default TTL. Responses the server marks as uncacheable are not
+// stored.
+func (c *Cache) SetFromResponse(key string, data []byte, header http.Header) {
+	c.setFromResponse(key, data, header, c.defaultTTL)
+}
+
+// setFromResponse is SetFromResponse with ttl in place of the default TTL
+func (c *Cache) setFromResponse(key string, data []byte, header http.Header, ttl time.Duration) {
+	if !c.ignoreCacheHeaders {
+		serverTTL, cacheable, ok := ttlFromHeaders(header, time.Now())
+		if !cacheable {
+			c.logger.Debug("Server marked response as uncacheable", "key", key)
+			c.Delete(key)
+			return
+		}
+		if ok {
+			ttl = serverTTL
+		}
+	}
+
+	c.set(key, data, header.Get("ETag"), header.Get("Last-Modified"), ttl)
+}
+
+// ttlFromHeaders derives an entry lifetime from HTTP caching headers. ok is
+// false when the headers carry no lifetime; cacheable is false when the
+// response must not be reused at all.
+func ttlFromHeaders(header http.Header, now time.Time) (ttl time.Duration, cacheable, ok bool) {
+	maxAge := -1
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0, false, true
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds >= 0 {
+				maxAge = seconds
+			}
+		}
+	}
+
+	switch {
+	case maxAge >= 0:
+		ttl = time.Duration(maxAge) * time.Second
+	case header.Get("Expires") != "":
+		expires, err := http.ParseTime(header.Get("Expires"))
+		if err != nil {
+			// Invalid dates such as "0" mean already expired
+			return 0, false, true
+		}
+		// Measure against the server's clock when it tells us the time
+		if date, err := http.ParseTime(header.Get("Date")); err == nil {
+			now = date
+		}
+		ttl = expires.Sub(now)
+	default:
+		return 0, true, false
+	}
+
+	if age, err := strconv.Atoi(header.Get("Age")); err == nil && age > 0 {
+		ttl -= time.Duration(age) * time.Second
+	}
+
+	if ttl <= 0 {
+		return 0, false, true
+	}
+	return min(ttl, MaxServerTTL), true, true
+}
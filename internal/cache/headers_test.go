@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTTLFromHeaders(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		headers   map[string]string
+		ttl       time.Duration
+		cacheable bool
+		ok        bool
+	}{
+		{name: "no headers", headers: nil, cacheable: true, ok: false},
+		{name: "max-age", headers: map[string]string{"Cache-Control": "public, max-age=600"}, ttl: 10 * time.Minute, cacheable: true, ok: true},
+		{name: "max-age less age", headers: map[string]string{"Cache-Control": "max-age=600", "Age": "120"}, ttl: 8 * time.Minute, cacheable: true, ok: true},
+		{name: "max-age capped", headers: map[string]string{"Cache-Control": "max-age=31536000"}, ttl: MaxServerTTL, cacheable: true, ok: true},
+		{name: "max-age zero", headers: map[string]string{"Cache-Control": "max-age=0, must-revalidate"}, cacheable: false, ok: true},
+		{name: "stale", headers: map[string]string{"Cache-Control": "max-age=60", "Age": "90"}, cacheable: false, ok: true},
+		{name: "no-store", headers: map[string]string{"Cache-Control": "no-store"}, cacheable: false, ok: true},
+		{name: "no-cache", headers: map[string]string{"Cache-Control": "No-Cache"}, cacheable: false, ok: true},
+		{name: "invalid max-age", headers: map[string]string{"Cache-Control": "max-age=soon"}, cacheable: true, ok: false},
+		{
+			name:      "expires",
+			headers:   map[string]string{"Expires": now.Add(time.Hour).Format(http.TimeFormat)},
+			ttl:       time.Hour,
+			cacheable: true,
+			ok:        true,
+		},
+		{
+			name: "expires relative to date",
+			headers: map[string]string{
+				"Date":    now.Add(-time.Hour).Format(http.TimeFormat),
+				"Expires": now.Format(http.TimeFormat),
+			},
+			ttl:       time.Hour,
+			cacheable: true,
+			ok:        true,
+		},
+		{
+			name: "max-age overrides expires",
+			headers: map[string]string{
+				"Cache-Control": "max-age=60",
+				"Expires":       now.Add(time.Hour).Format(http.TimeFormat),
+			},
+			ttl:       time.Minute,
+			cacheable: true,
+			ok:        true,
+		},
+		{name: "invalid expires", headers: map[string]string{"Expires": "0"}, cacheable: false, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for name, value := range tt.headers {
+				header.Set(name, value)
+			}
+
+			ttl, cacheable, ok := ttlFromHeaders(header, now)
+			assert.Equal(t, tt.ttl, ttl)
+			assert.Equal(t, tt.cacheable, cacheable)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func TestCache_SetFromResponse(t *testing.T) {
+	cache := New(WithTTL(time.Minute))
+
+	header := http.Header{}
+	header.Set("Cache-Control", "max-age=3600")
+	header.Set("ETag", `"abc"`)
+	cache.SetFromResponse("long", []byte("data"), header)
+	assert.Equal(t, time.Hour, cache.entries["long"].TTL)
+	assert.Equal(t, `"abc"`, cache.entries["long"].ETag)
+
+	cache.SetFromResponse("default", []byte("data"), http.Header{})
+	assert.Equal(t, time.Minute, cache.entries["default"].TTL)
+
+	// Uncacheable responses are not stored and replace any older entry
+	cache.Set("nostore", []byte("old"), "", "")
+	header = http.Header{}
+	header.Set("Cache-Control", "no-store")
+	cache.SetFromResponse("nostore", []byte("data"), header)
+	_, found := cache.Get("nostore")
+	assert.False(t, found)
+}
+
+func TestCache_SetFromResponse_IgnoreHeaders(t *testing.T) {
+	cache := New(WithTTL(time.Minute), WithIgnoreCacheHeaders(true))
+
+	header := http.Header{}
+	header.Set("Cache-Control", "no-store")
+	cache.SetFromResponse("key", []byte("data"), header)
+
+	_, found := cache.Get("key")
+	assert.True(t, found)
+	assert.Equal(t, time.Minute, cache.entries["key"].TTL)
+}
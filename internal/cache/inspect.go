@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// States of a key reported by Inspect, naming what Fetch would do with it
+const (
+	// StateFresh entries are served without a request
+	StateFresh = "fresh"
+
+	// StateStale entries are revalidated with a conditional request
+	StateStale = "stale"
+
+	// StateNegative keys were recently missing and fail without a request
+	StateNegative = "negative"
+
+	// StateMissing keys are fetched
+	StateMissing = "missing"
+
+	// StateBypassed keys are fetched because the Policy bypasses the cache
+	StateBypassed = "bypassed"
+)
+
+// Inspection is the state of a cache key as Inspect found it
+type Inspection struct {
+	State string `json:"state"`
+
+	// Status is the status recorded for negative entries
+	Status int `json:"status,omitempty"`
+
+	// AgeSeconds is how long ago fresh and stale entries were cached
+	AgeSeconds int `json:"age_seconds,omitempty"`
+}
+
+// Inspect reports what Fetch would do with key under the Policy of ctx,
+// without counting a hit or miss, reordering or expiring entries
+func (c *Cache) Inspect(ctx context.Context, key string) Inspection {
+	policy := PolicyFrom(ctx)
+	if policy.Bypass {
+		return Inspection{State: StateBypassed}
+	}
+
+	key = ScopedKey(ctx, key)
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entry, exists := c.entries[key]
+	switch {
+	case !exists:
+		return Inspection{State: StateMissing}
+	case entry.Status != 0:
+		if entry.IsExpired() {
+			return Inspection{State: StateMissing}
+		}
+		return Inspection{State: StateNegative, Status: entry.Status}
+	}
+
+	age := int(time.Since(entry.CachedAt).Seconds())
+	if !entry.IsExpired() && !entry.olderThan(policy.TTL) {
+		return Inspection{State: StateFresh, AgeSeconds: age}
+	}
+	if entry.revalidatable() {
+		return Inspection{State: StateStale, AgeSeconds: age}
+	}
+	return Inspection{State: StateMissing}
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Inspect(t *testing.T) {
+	cache := New(WithTTL(time.Minute))
+	ctx := context.Background()
+
+	cache.Set("fresh", []byte("data"), "", "")
+	cache.set("stale", []byte("data"), `"v1"`, "", time.Millisecond)
+	cache.set("expired", []byte("data"), "", "", time.Millisecond)
+	cache.SetNegative("gone", http.StatusGone)
+	time.Sleep(5 * time.Millisecond)
+
+	assert.Equal(t, Inspection{State: StateFresh}, cache.Inspect(ctx, "fresh"))
+	assert.Equal(t, StateStale, cache.Inspect(ctx, "stale").State)
+	assert.Equal(t, Inspection{State: StateMissing}, cache.Inspect(ctx, "expired"))
+	assert.Equal(t, Inspection{State: StateNegative, Status: http.StatusGone}, cache.Inspect(ctx, "gone"))
+	assert.Equal(t, Inspection{State: StateMissing}, cache.Inspect(ctx, "absent"))
+
+	// The policy's TTL and bypass apply as they would to Fetch
+	short := ContextWithPolicy(ctx, Policy{TTL: time.Millisecond})
+	assert.Equal(t, Inspection{State: StateMissing}, cache.Inspect(short, "fresh"))
+	bypass := ContextWithPolicy(ctx, Policy{Bypass: true})
+	assert.Equal(t, Inspection{State: StateBypassed}, cache.Inspect(bypass, "fresh"))
+
+	// Inspecting counts no lookups and expires nothing
+	stats := cache.Stats()
+	assert.Equal(t, 0, stats["hits"])
+	assert.Equal(t, 0, stats["misses"])
+	assert.Equal(t, 4, stats["total_entries"])
+}
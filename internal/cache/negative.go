@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultNegativeTTL is how long a missing resource is remembered
+const DefaultNegativeTTL = time.Minute
+
+// WithNegativeTTL sets how long Fetch remembers resources that returned 404
+// Not Found or 410 Gone. Zero disables negative caching.
+func WithNegativeTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.negativeTTL = ttl
+	}
+}
+
+// negativeStatus reports whether a response status is cached negatively
+func negativeStatus(statusCode int) bool {
+	return statusCode == http.StatusNotFound || statusCode == http.StatusGone
+}
+
+// SetNegative records that key's resource answered with status, so requests
+// for it fail without being sent until the negative TTL passes. Negative
+// entries are evicted, expired and cleared like any other entry.
+func (c *Cache) SetNegative(key string, status int) {
+	if c.negativeTTL <= 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	c.removeLocked(key)
+	entry := &CacheEntry{
+		Status:   status,
+		CachedAt: time.Now(),
+		TTL:      c.negativeTTL,
+	}
+	entry.element = c.lru.PushFront(key)
+	c.entries[key] = entry
+	c.evictLocked()
+	c.mutex.Unlock()
+
+	c.logger.Debug("Cached negative entry", "key", key, "status", status, "ttl", c.negativeTTL)
+}
+
+// GetNegative returns the status recorded for key by SetNegative, if the
+// negative entry has not expired
+func (c *Cache) GetNegative(key string) (int, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, exists := c.entries[key]
+	if !exists || entry.Status == 0 {
+		return 0, false
+	}
+	if entry.IsExpired() {
+		c.removeLocked(key)
+		c.expirations++
+		return 0, false
+	}
+	c.lru.MoveToFront(entry.element)
+	c.negativeHits++
+	return entry.Status, true
+}
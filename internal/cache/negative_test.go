@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Fetch_NegativeCaching(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/gone.json":
+			w.WriteHeader(http.StatusGone)
+		case "/error.json":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cache := New(WithNegativeTTL(10 * time.Millisecond))
+	ctx := context.Background()
+
+	// Missing resources are requested once, then fail from the cache
+	for _, path := range []string{"/missing.json", "/gone.json", "/error.json"} {
+		for i := 0; i < 2; i++ {
+			_, err := cache.Fetch(ctx, server.Client(), path, server.URL+path, nil)
+			var status *StatusError
+			require.ErrorAs(t, err, &status, path)
+		}
+	}
+	assert.Equal(t, 1, requests["/missing.json"])
+	assert.Equal(t, 1, requests["/gone.json"])
+	assert.Equal(t, 2, requests["/error.json"])
+
+	_, err := cache.Fetch(ctx, server.Client(), "/gone.json", server.URL+"/gone.json", nil)
+	assert.Equal(t, &StatusError{StatusCode: http.StatusGone}, err)
+
+	stats := cache.Stats()
+	assert.Equal(t, 2, stats["negative_entries"])
+	assert.Equal(t, 3, stats["negative_hits"])
+
+	// Negative entries are not served as data
+	_, found := cache.Get("/missing.json")
+	assert.False(t, found)
+
+	// and are requested again once they expire
+	time.Sleep(20 * time.Millisecond)
+	_, err = cache.Fetch(ctx, server.Client(), "/missing.json", server.URL+"/missing.json", nil)
+	require.Error(t, err)
+	assert.Equal(t, 2, requests["/missing.json"])
+}
+
+func TestCache_SetNegative(t *testing.T) {
+	cache := New(WithMaxEntries(2))
+	cache.SetNegative("https://example.com/missing.json", http.StatusNotFound)
+
+	status, found := cache.GetNegative("https://example.com/missing.json")
+	assert.True(t, found)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// Data replaces a negative entry
+	cache.Set("https://example.com/missing.json", []byte("data"), "", "")
+	_, found = cache.GetNegative("https://example.com/missing.json")
+	assert.False(t, found)
+
+	// Negative entries are cleared with their host and count toward limits
+	cache.SetNegative("https://example.com/other.json", http.StatusNotFound)
+	assert.Equal(t, 2, cache.DeleteByHost("example.com"))
+	cache.SetNegative("https://example.com/a.json", http.StatusNotFound)
+	cache.SetNegative("https://example.com/b.json", http.StatusNotFound)
+	cache.SetNegative("https://example.com/c.json", http.StatusNotFound)
+	assert.Equal(t, 2, cache.Stats()["total_entries"])
+
+	// A zero TTL disables negative caching
+	cache = New(WithNegativeTTL(0))
+	cache.SetNegative("https://example.com/missing.json", http.StatusNotFound)
+	_, found = cache.GetNegative("https://example.com/missing.json")
+	assert.False(t, found)
+}
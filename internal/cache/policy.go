@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MaxCacheTTLSeconds bounds the cache_ttl_seconds of a tool call
+const MaxCacheTTLSeconds = int(MaxServerTTL / time.Second)
+
+// Policy changes how Fetch uses the cache for the calls of one tool
+type Policy struct {
+	// TTL is how long responses stay fresh: entries cached for longer are
+	// fetched again, and fetched responses without caching headers are kept
+	// for TTL instead of the default TTL. Zero keeps the defaults.
+	TTL time.Duration
+
+	// Bypass ignores cached responses, missing resources and skipped
+	// endpoints, downloading everything again. The responses are cached.
+	Bypass bool
+}
+
+// policyKey is the context key of the cache policy
+type policyKey struct{}
+
+// ContextWithPolicy returns a context whose fetches use policy
+func ContextWithPolicy(ctx context.Context, policy Policy) context.Context {
+	return context.WithValue(ctx, policyKey{}, policy)
+}
+
+// PolicyFrom returns the cache policy of ctx
+func PolicyFrom(ctx context.Context) Policy {
+	policy, _ := ctx.Value(policyKey{}).(Policy)
+	return policy
+}
+
+// ttl returns the lifetime of entries stored under the policy
+func (p Policy) ttl(defaultTTL time.Duration) time.Duration {
+	if p.TTL > 0 {
+		return p.TTL
+	}
+	return defaultTTL
+}
+
+// CacheOptions are the optional cache fields shared by tool requests that
+// read site data through the cache. Embed it in request structs.
+type CacheOptions struct {
+	CacheTTLSeconds int  `json:"cache_ttl_seconds,omitempty" jsonschema:"title=Cache TTL (seconds) - refetch cached data older than this,minimum=1,maximum=86400"`
+	BypassCache     bool `json:"bypass_cache,omitempty" jsonschema:"title=Bypass Cache - fetch fresh data instead of cached responses"`
+}
+
+// Validate checks the cache TTL is within range
+func (o *CacheOptions) Validate() error {
+	if o.CacheTTLSeconds < 0 || o.CacheTTLSeconds > MaxCacheTTLSeconds {
+		return fmt.Errorf("cache_ttl_seconds must be between 1 and %d", MaxCacheTTLSeconds)
+	}
+	return nil
+}
+
+// Apply derives a context carrying the cache policy of the tool call, with
+// the tool's TTL unless cache_ttl_seconds overrides it. A zero ttl keeps
+// the cache's default.
+func (o *CacheOptions) Apply(ctx context.Context, ttl time.Duration) context.Context {
+	if o.CacheTTLSeconds > 0 {
+		ttl = time.Duration(o.CacheTTLSeconds) * time.Second
+	}
+	return ContextWithPolicy(ctx, Policy{TTL: ttl, Bypass: o.BypassCache})
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// scopeSalt makes scope fingerprints unique to the process, so they do not
+// reveal the credentials they were made from
+var scopeSalt = func() []byte {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	return salt
+}()
+
+// scopeKey is the context key of the cache scope
+type scopeKey struct{}
+
+// scope keeps the entries of a host fetched with an identity, such as
+// per-call credentials, apart from the entries fetched without it
+type scope struct {
+	host        string
+	fingerprint string
+}
+
+// ContextWithScope returns a copy of ctx whose cache entries for host are
+// kept apart from those fetched under another identity. Requests carrying
+// per-call credentials or headers are scoped by them, so responses only
+// they may read are not served to calls without them, and responses a call
+// without them was refused are not held against them. Scoping ctx again
+// for the same host combines the identities.
+func ContextWithScope(ctx context.Context, host, identity string) context.Context {
+	host = strings.ToLower(host)
+	if previous, ok := ctx.Value(scopeKey{}).(scope); ok && previous.host == host {
+		identity = previous.fingerprint + "\x00" + identity
+	}
+	h := sha256.New()
+	h.Write(scopeSalt)
+	h.Write([]byte(identity))
+	return context.WithValue(ctx, scopeKey{}, scope{host: host, fingerprint: hex.EncodeToString(h.Sum(nil))[:16]})
+}
+
+// ScopeOf returns the fingerprint of the identity ctx scopes host's
+// entries by, or "" when they are not scoped
+func ScopeOf(ctx context.Context, host string) string {
+	if s, ok := ctx.Value(scopeKey{}).(scope); ok && s.host == strings.ToLower(host) {
+		return s.fingerprint
+	}
+	return ""
+}
+
+// ScopedKey returns key qualified by the scope ctx gives the host of key,
+// if any. Scoped keys keep their host, so DeleteByHost still finds them.
+func ScopedKey(ctx context.Context, key string) string {
+	if fingerprint := ScopeOf(ctx, hostOf(key)); fingerprint != "" {
+		return key + "#scope=" + fingerprint
+	}
+	return key
+}
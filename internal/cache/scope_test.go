@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopedKey(t *testing.T) {
+	c := New()
+	key := c.BuildKey("https://example.com", "/index.json", nil)
+	assert.Equal(t, key, ScopedKey(context.Background(), key))
+
+	ctx := ContextWithScope(context.Background(), "Example.com", "credentials:a")
+	scoped := ScopedKey(ctx, key)
+	assert.NotEqual(t, key, scoped)
+	assert.Equal(t, "example.com", hostOf(scoped))
+	assert.NotContains(t, scoped, "credentials:a")
+
+	// Keys of other hosts are not scoped
+	other := c.BuildKey("https://other.example", "/index.json", nil)
+	assert.Equal(t, other, ScopedKey(ctx, other))
+
+	// Other identities, and further identities for the host, scope apart
+	assert.NotEqual(t, scoped, ScopedKey(ContextWithScope(context.Background(), "example.com", "credentials:b"), key))
+	combined := ContextWithScope(ctx, "example.com", "headers:c")
+	assert.NotEqual(t, scoped, ScopedKey(combined, key))
+	assert.NotEmpty(t, ScopeOf(combined, "example.com"))
+
+	// Scoped entries are still removed with their host
+	c.Set(scoped, []byte("data"), "", "")
+	assert.Equal(t, 1, c.DeleteByHost("example.com"))
+}
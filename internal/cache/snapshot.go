@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultSnapshotHistory is the number of snapshots kept per site
+const DefaultSnapshotHistory = 10
+
+// Snapshot records the pages of a site at a point in time so later
+// snapshots can be compared against it
+type Snapshot struct {
+	TakenAt time.Time
+	Source  string
+
+	// Pages maps page URLs to a fingerprint of their content
+	Pages map[string]string
+}
+
+// WithSnapshotHistory sets how many snapshots are kept per site
+func WithSnapshotHistory(n int) CacheOption {
+	return func(c *Cache) {
+		if n > 0 {
+			c.snapshotHistory = n
+		}
+	}
+}
+
+// SnapshotKey normalizes a site URL into the key snapshots are stored under
+func SnapshotKey(siteURL string) string {
+	return strings.TrimRight(strings.ToLower(siteURL), "/")
+}
+
+// AddSnapshot records a snapshot for a site, discarding the oldest snapshots
+// beyond the configured history. Snapshots do not expire and survive Clear.
+func (c *Cache) AddSnapshot(siteURL string, snapshot *Snapshot) {
+	key := SnapshotKey(siteURL)
+
+	c.mutex.Lock()
+	history := append(c.snapshots[key], snapshot)
+	if len(history) > c.snapshotHistory {
+		history = history[len(history)-c.snapshotHistory:]
+	}
+	c.snapshots[key] = history
+	c.mutex.Unlock()
+
+	c.logger.Debug("Recorded snapshot", "site", key, "source", snapshot.Source, "pages", len(snapshot.Pages))
+}
+
+// LatestSnapshot returns the most recent snapshot for a site
+func (c *Cache) LatestSnapshot(siteURL string) (*Snapshot, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	history := c.snapshots[SnapshotKey(siteURL)]
+	if len(history) == 0 {
+		return nil, false
+	}
+	return history[len(history)-1], true
+}
+
+// Snapshots returns the snapshot history for a site, oldest first
+func (c *Cache) Snapshots(siteURL string) []*Snapshot {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	history := c.snapshots[SnapshotKey(siteURL)]
+	return append([]*Snapshot(nil), history...)
+}
+
+// ClearSnapshots removes the snapshot history for a site
+func (c *Cache) ClearSnapshots(siteURL string) {
+	c.mutex.Lock()
+	delete(c.snapshots, SnapshotKey(siteURL))
+	c.mutex.Unlock()
+}
@@ -0,0 +1,45 @@
+package cache
+
+import "time"
+
+// Validators are the caching validators of a resource when it was last
+// checked, kept so later checks can tell whether it changed
+type Validators struct {
+	ETag          string
+	LastModified  string
+	ContentLength int64
+	CheckedAt     time.Time
+
+	// ChangedAt is when a check last found the resource changed, if ever
+	ChangedAt time.Time
+}
+
+// SetValidators records the validators of a resource. Like snapshots, they
+// do not expire and survive Clear.
+func (c *Cache) SetValidators(rawURL string, validators Validators) {
+	c.mutex.Lock()
+	c.validators[rawURL] = validators
+	c.mutex.Unlock()
+}
+
+// LastValidators returns the validators recorded for a resource
+func (c *Cache) LastValidators(rawURL string) (Validators, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	validators, ok := c.validators[rawURL]
+	return validators, ok
+}
+
+// EntryValidators returns the ETag and Last-Modified of the response cached
+// under key, if any
+func (c *Cache) EntryValidators(key string) (etag, lastModified string, ok bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entry, exists := c.entries[key]
+	if !exists || entry.Status != 0 {
+		return "", "", false
+	}
+	return entry.ETag, entry.LastModified, true
+}
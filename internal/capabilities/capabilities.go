@@ -0,0 +1,469 @@
+package capabilities
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/feed"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/opensearch"
+	"github.com/tidwall/gjson"
+)
+
+// Capabilities a site is probed for
+const (
+	Index       = "index"
+	SearchIndex = "search_index"
+	OpenSearch  = "opensearch"
+	Pagefind    = "pagefind"
+	Sitemap     = "sitemap"
+	Feed        = "feed"
+	Taxonomies  = "taxonomies"
+	Robots      = "robots"
+	HomePage    = "home_page"
+	WPJSON      = "wp_json"
+)
+
+// Unavailable is the strategy reported when a site offers no way to do something
+const Unavailable = "unavailable"
+
+// probeConcurrency bounds the number of simultaneous requests while probing
+const probeConcurrency = 6
+
+// SearchIndexPaths are where sites conventionally publish JSON search indexes
+var SearchIndexPaths = []string{"/search.json", "/api/search.json", "/search/index.json"}
+
+// TaxonomyPaths are where sites conventionally publish a taxonomy listing
+var TaxonomyPaths = []string{"/taxonomies/index.json", "/api/taxonomies.json"}
+
+// pagefindPath is the entry file of a Pagefind search bundle
+const pagefindPath = "/pagefind/pagefind-entry.json"
+
+// hugoVersionPattern matches the version in a generator such as "Hugo 0.121.1"
+var hugoVersionPattern = regexp.MustCompile(`(?i)\bhugo\s+v?(\d+\.\d+(?:\.\d+)?)`)
+
+// languagePattern matches language codes such as "en" or "pt-br"
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// Capability is whether a site offers a feature, and where
+type Capability struct {
+	Available bool   `json:"available"`
+	Endpoint  string `json:"endpoint,omitempty"`
+	Format    string `json:"format,omitempty"`
+	Count     int    `json:"count,omitempty"`
+	Cached    bool   `json:"cached,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Profile is what a site offers and how the tools are best used on it
+type Profile struct {
+	Site         string                `json:"site"`
+	Capabilities map[string]Capability `json:"capabilities"`
+	Languages    []string              `json:"languages"`
+	Generator    string                `json:"generator,omitempty"`
+	HugoVersion  string                `json:"hugo_version,omitempty"`
+	IsHugo       bool                  `json:"is_hugo"`
+	Platform     Platform              `json:"platform"`
+	Notice       string                `json:"notice,omitempty"`
+	Strategies   map[string]string     `json:"strategies"`
+	ProbedAt     time.Time             `json:"probed_at"`
+}
+
+// Available reports whether the site offers a capability
+func (p *Profile) Available(name string) bool {
+	return p.Capabilities[name].Available
+}
+
+// prober collects the hints probes find along the way
+type prober struct {
+	cache   *cache.Cache
+	client  *httpclient.Client
+	siteURL *url.URL
+
+	mu            sync.Mutex
+	languages     map[string]bool
+	metaGenerator string
+	feedGenerator string
+	homeHTML      string
+}
+
+// Probe checks which endpoints a site offers, concurrently and through the
+// cache under the keys the tools use, and derives a strategy for each kind
+// of tool from them
+func Probe(ctx context.Context, c *cache.Cache, client *httpclient.Client, siteURL *url.URL) *Profile {
+	p := &prober{cache: c, client: client, siteURL: siteURL, languages: make(map[string]bool)}
+
+	probes := map[string]func(context.Context) Capability{
+		Index:       p.index,
+		SearchIndex: p.searchIndex,
+		OpenSearch:  p.openSearch,
+		Pagefind:    p.pagefind,
+		Sitemap:     p.sitemap,
+		Feed:        p.feed,
+		Taxonomies:  p.taxonomies,
+		Robots:      p.robots,
+		HomePage:    p.homePage,
+		WPJSON:      p.wpJSON,
+	}
+
+	profile := &Profile{Site: siteURL.String(), Capabilities: make(map[string]Capability, len(probes))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, probeConcurrency)
+	for name, probe := range probes {
+		wg.Add(1)
+		go func(name string, probe func(context.Context) Capability) {
+			defer wg.Done()
+			var capability Capability
+			select {
+			case sem <- struct{}{}:
+				capability = probe(ctx)
+				<-sem
+			case <-ctx.Done():
+				capability = Capability{Error: ctx.Err().Error()}
+			}
+			mu.Lock()
+			profile.Capabilities[name] = capability
+			mu.Unlock()
+		}(name, probe)
+	}
+	wg.Wait()
+
+	profile.Languages = make([]string, 0, len(p.languages))
+	for language := range p.languages {
+		profile.Languages = append(profile.Languages, language)
+	}
+	sort.Strings(profile.Languages)
+
+	// The home page names the generator more reliably than feeds do
+	for _, generator := range []string{p.metaGenerator, p.feedGenerator} {
+		if profile.Generator == "" {
+			profile.Generator = generator
+		}
+		if match := hugoVersionPattern.FindStringSubmatch(generator); match != nil && profile.HugoVersion == "" {
+			profile.HugoVersion = match[1]
+		}
+	}
+	profile.Platform = DetectPlatform(PlatformSignals{
+		MetaGenerator: p.metaGenerator,
+		FeedGenerator: p.feedGenerator,
+		HomePage:      p.homeHTML,
+		WPJSON:        profile.Available(WPJSON),
+		HugoIndex:     profile.Available(Index) && profile.Capabilities[Index].Format == hugoindex.FormatHugo,
+	})
+	profile.IsHugo = profile.Platform.Name == PlatformHugo
+	profile.Notice = profile.Platform.Notice(profile.Available(Index))
+
+	profile.Strategies = strategies(profile)
+	profile.ProbedAt = time.Now().UTC()
+	return profile
+}
+
+// strategies chooses how each kind of tool is best used on a site, naming
+// the methods the tools report
+func strategies(p *Profile) map[string]string {
+	first := func(options ...[2]string) string {
+		for _, option := range options {
+			if p.Available(option[0]) {
+				return option[1]
+			}
+		}
+		return Unavailable
+	}
+
+	return map[string]string{
+		"search": first(
+			[2]string{OpenSearch, "hugo_native"},
+			[2]string{SearchIndex, "hugo_native"},
+			[2]string{Index, "content_scan"},
+			[2]string{Feed, "feed_scan"},
+			[2]string{Sitemap, "sitemap_scan"},
+		),
+		"pages": first(
+			[2]string{Index, "index"},
+			[2]string{Feed, "feed"},
+			[2]string{Sitemap, "sitemap"},
+		),
+		"content": first(
+			[2]string{Index, "index"},
+			[2]string{HomePage, "rendered_html"},
+			[2]string{Sitemap, "rendered_html"},
+		),
+		"taxonomies": first(
+			[2]string{Taxonomies, "taxonomy_endpoints"},
+			[2]string{Index, "index"},
+		),
+		"changes": first(
+			[2]string{Index, "index"},
+			[2]string{Sitemap, "sitemap"},
+		),
+	}
+}
+
+// fetch returns a site resource through the cache
+func (p *prober) fetch(ctx context.Context, resourceURL *url.URL, valid func([]byte) bool) (*cache.FetchResult, error) {
+	site := resourceURL.Scheme + "://" + resourceURL.Host
+	if resourceURL.Host == p.siteURL.Host {
+		site = p.siteURL.String()
+	}
+	cacheKey := p.cache.BuildKey(site, resourceURL.RequestURI(), nil)
+	return p.cache.Fetch(ctx, p.client, cacheKey, resourceURL.String(), valid)
+}
+
+// fetchPath is fetch for a path on the site. Probes always make the request
+// and record the outcome so the tools can skip missing endpoints; responses
+// rejected by a probe's validator are left for the tools to judge.
+func (p *prober) fetchPath(ctx context.Context, path string, valid func([]byte) bool) (*cache.FetchResult, string, error) {
+	resourceURL := p.siteURL.ResolveReference(&url.URL{Path: path})
+	result, err := p.fetch(ctx, resourceURL, valid)
+	if !errors.Is(err, cache.ErrInvalidResponse) {
+		p.cache.RecordEndpoint(p.siteURL.String(), path, err)
+	}
+	return result, resourceURL.String(), err
+}
+
+// failed returns the capability of a probe that found nothing. Missing
+// endpoints are expected and carry no error.
+func failed(err error) Capability {
+	var status *cache.StatusError
+	if errors.As(err, &status) && (status.StatusCode == http.StatusNotFound || status.StatusCode == http.StatusGone) {
+		return Capability{}
+	}
+	if errors.Is(err, cache.ErrInvalidResponse) {
+		return Capability{}
+	}
+	return Capability{Error: err.Error()}
+}
+
+func (p *prober) addLanguage(language string) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if !languagePattern.MatchString(language) {
+		return
+	}
+	p.mu.Lock()
+	p.languages[language] = true
+	p.mu.Unlock()
+}
+
+func (p *prober) index(ctx context.Context) Capability {
+	index, err := hugoindex.Load(ctx, p.cache, p.client, p.siteURL)
+	if err != nil {
+		return failed(err)
+	}
+
+	count := 0
+	err = index.Pages(ctx, func(gjson.Result) bool {
+		count++
+		return true
+	})
+	capability := Capability{Available: true, Endpoint: index.URL(), Format: index.Format(), Count: count, Cached: index.Cached()}
+	if err != nil {
+		capability.Error = err.Error()
+	}
+	return capability
+}
+
+func (p *prober) searchIndex(ctx context.Context) Capability {
+	var last Capability
+	for _, path := range SearchIndexPaths {
+		result, endpoint, err := p.fetchPath(ctx, path, validSearchIndex)
+		if err != nil {
+			last = failed(err)
+			continue
+		}
+		return Capability{Available: true, Endpoint: endpoint, Format: "json", Count: searchIndexSize(result.Data), Cached: result.Cached || result.Revalidated}
+	}
+	return last
+}
+
+// validSearchIndex reports whether data is a list of search results or pages
+func validSearchIndex(data []byte) bool {
+	if !gjson.ValidBytes(data) {
+		return false
+	}
+	parsed := gjson.ParseBytes(data)
+	return parsed.IsArray() || parsed.Get("results").IsArray() || parsed.Get("hits").IsArray() || parsed.Get("pages").IsArray()
+}
+
+func searchIndexSize(data []byte) int {
+	parsed := gjson.ParseBytes(data)
+	for _, field := range []string{"results", "hits", "pages"} {
+		if list := parsed.Get(field); list.IsArray() {
+			return len(list.Array())
+		}
+	}
+	return len(parsed.Array())
+}
+
+func (p *prober) openSearch(ctx context.Context) Capability {
+	description, err := opensearch.Load(ctx, p.cache, p.client, p.siteURL)
+	if err != nil {
+		return failed(err)
+	}
+	template, err := description.SearchURL()
+	if err != nil {
+		return Capability{Endpoint: description.URL, Error: err.Error()}
+	}
+	return Capability{Available: true, Endpoint: description.URL, Format: template.Type, Cached: description.Cached}
+}
+
+func (p *prober) pagefind(ctx context.Context) Capability {
+	result, endpoint, err := p.fetchPath(ctx, pagefindPath, func(data []byte) bool {
+		return gjson.GetBytes(data, "languages").IsObject()
+	})
+	if err != nil {
+		return failed(err)
+	}
+
+	languages := gjson.GetBytes(result.Data, "languages").Map()
+	for language := range languages {
+		p.addLanguage(language)
+	}
+	return Capability{Available: true, Endpoint: endpoint, Format: "pagefind", Count: len(languages), Cached: result.Cached || result.Revalidated}
+}
+
+// sitemapDocument is a sitemap or sitemap index
+type sitemapDocument struct {
+	XMLName xml.Name
+	URLs    []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+func validSitemap(data []byte) bool {
+	var doc sitemapDocument
+	return xml.NewDecoder(bytes.NewReader(data)).Decode(&doc) == nil && (doc.XMLName.Local == "urlset" || doc.XMLName.Local == "sitemapindex")
+}
+
+func (p *prober) sitemap(ctx context.Context) Capability {
+	candidates := []*url.URL{}
+	if declared, err := p.client.Sitemaps(ctx, p.siteURL); err == nil {
+		for _, raw := range declared {
+			if u, err := url.Parse(raw); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+				candidates = append(candidates, u)
+			}
+		}
+	}
+	candidates = append(candidates, p.siteURL.ResolveReference(&url.URL{Path: "/sitemap.xml"}))
+
+	var last Capability
+	for _, sitemapURL := range candidates {
+		result, err := p.fetch(ctx, sitemapURL, validSitemap)
+		if err != nil {
+			last = failed(err)
+			continue
+		}
+
+		var doc sitemapDocument
+		if err := xml.Unmarshal(result.Data, &doc); err != nil {
+			continue
+		}
+		capability := Capability{Available: true, Endpoint: sitemapURL.String(), Format: doc.XMLName.Local, Count: len(doc.URLs), Cached: result.Cached || result.Revalidated}
+		if doc.XMLName.Local == "sitemapindex" {
+			// Multilingual Hugo sites list a sitemap per language, such as /en/sitemap.xml
+			capability.Count = len(doc.Sitemaps)
+			for _, child := range doc.Sitemaps {
+				if u, err := url.Parse(strings.TrimSpace(child.Loc)); err == nil && path.Base(u.Path) == "sitemap.xml" {
+					p.addLanguage(path.Base(path.Dir(u.Path)))
+				}
+			}
+		}
+		return capability
+	}
+	return last
+}
+
+func (p *prober) feed(ctx context.Context) Capability {
+	siteFeed, err := feed.Load(ctx, p.cache, p.client, p.siteURL)
+	if err != nil {
+		return failed(err)
+	}
+	p.mu.Lock()
+	p.feedGenerator = siteFeed.Generator
+	p.mu.Unlock()
+	return Capability{Available: true, Endpoint: siteFeed.URL, Format: siteFeed.Format, Count: len(siteFeed.Items), Cached: siteFeed.Cached}
+}
+
+func (p *prober) taxonomies(ctx context.Context) Capability {
+	var last Capability
+	for _, path := range TaxonomyPaths {
+		result, endpoint, err := p.fetchPath(ctx, path, func(data []byte) bool {
+			return gjson.ValidBytes(data) && (gjson.ParseBytes(data).IsObject() || gjson.ParseBytes(data).IsArray())
+		})
+		if err != nil {
+			last = failed(err)
+			continue
+		}
+		parsed := gjson.ParseBytes(result.Data)
+		count := len(parsed.Array())
+		if parsed.IsObject() {
+			count = len(parsed.Map())
+			if taxonomies := parsed.Get("taxonomies"); taxonomies.Exists() {
+				count = max(len(taxonomies.Array()), len(taxonomies.Map()))
+			}
+		}
+		return Capability{Available: true, Endpoint: endpoint, Format: "json", Count: count, Cached: result.Cached || result.Revalidated}
+	}
+	return last
+}
+
+func (p *prober) robots(ctx context.Context) Capability {
+	result, endpoint, err := p.fetchPath(ctx, "/robots.txt", func(data []byte) bool {
+		return !htmltext.LooksLikeHTML(string(data))
+	})
+	if err != nil {
+		return failed(err)
+	}
+	return Capability{Available: true, Endpoint: endpoint, Format: "text", Cached: result.Cached || result.Revalidated}
+}
+
+func (p *prober) homePage(ctx context.Context) Capability {
+	result, endpoint, err := p.fetchPath(ctx, "/", func(data []byte) bool {
+		return htmltext.LooksLikeHTML(string(data))
+	})
+	if err != nil {
+		return failed(err)
+	}
+
+	head := htmltext.ParseHead(string(result.Data))
+	p.addLanguage(head.Lang)
+	p.mu.Lock()
+	p.metaGenerator = head.Generator
+	p.homeHTML = string(result.Data)
+	p.mu.Unlock()
+	for _, alternate := range head.Alternates {
+		if alternate.Hreflang != "" {
+			p.addLanguage(alternate.Hreflang)
+		}
+	}
+	return Capability{Available: true, Endpoint: endpoint, Format: "html", Cached: result.Cached || result.Revalidated}
+}
+
+// wpJSON checks for the WordPress REST API, whose index lists the
+// namespaces it serves
+func (p *prober) wpJSON(ctx context.Context) Capability {
+	result, endpoint, err := p.fetchPath(ctx, WPJSONPath, func(data []byte) bool {
+		return gjson.GetBytes(data, "namespaces").IsArray()
+	})
+	if err != nil {
+		return failed(err)
+	}
+	return Capability{Available: true, Endpoint: endpoint, Format: "wp_rest", Count: len(gjson.GetBytes(result.Data, "namespaces").Array()), Cached: result.Cached || result.Revalidated}
+}
@@ -0,0 +1,142 @@
+package capabilities
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := "http://" + r.Host
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html lang="en"><head><meta name="generator" content="Hugo 0.121.1">
+<link rel="alternate" hreflang="fr" href="/fr/"><link rel="alternate" hreflang="x-default" href="/"></head><body>Home</body></html>`))
+		case "/index.json":
+			w.Write([]byte(`[{"title": "A", "url": "/posts/a/"}, {"title": "B", "url": "/posts/b/"}]`))
+		case "/robots.txt":
+			w.Write([]byte("User-agent: *\nSitemap: " + host + "/sitemap-index.xml\n"))
+		case "/sitemap-index.xml":
+			w.Write([]byte(`<sitemapindex><sitemap><loc>` + host + `/en/sitemap.xml</loc></sitemap><sitemap><loc>` + host + `/de/sitemap.xml</loc></sitemap></sitemapindex>`))
+		case "/index.xml":
+			w.Write([]byte(`<rss version="2.0"><channel><generator>Hugo -- gohugo.io</generator><item><title>A</title><link>/posts/a/</link></item></channel></rss>`))
+		case "/search.json":
+			// A home page served for every path is not a search index
+			w.Write([]byte(`<html><body>Not found</body></html>`))
+		case "/api/taxonomies.json":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	profile := Probe(context.Background(), cache.New(), httpclient.New(httpclient.WithRetryPolicy(httpclient.RetryPolicy{})), siteURL)
+
+	assert.Equal(t, Capability{Available: true, Endpoint: server.URL + "/index.json", Format: hugoindex.FormatHugo, Count: 2}, profile.Capabilities[Index])
+	assert.Equal(t, Capability{Available: true, Endpoint: server.URL + "/sitemap-index.xml", Format: "sitemapindex", Count: 2}, profile.Capabilities[Sitemap])
+	assert.Equal(t, "rss", profile.Capabilities[Feed].Format)
+	assert.True(t, profile.Available(Robots))
+	assert.True(t, profile.Available(HomePage))
+	assert.Equal(t, Capability{}, profile.Capabilities[SearchIndex])
+	assert.Equal(t, Capability{}, profile.Capabilities[OpenSearch])
+	assert.False(t, profile.Available(Taxonomies))
+	assert.NotEmpty(t, profile.Capabilities[Taxonomies].Error)
+	assert.False(t, profile.Available(WPJSON))
+	assert.Len(t, profile.Capabilities, 10)
+
+	assert.Equal(t, []string{"de", "en", "fr"}, profile.Languages)
+	assert.Equal(t, "Hugo 0.121.1", profile.Generator)
+	assert.Equal(t, "0.121.1", profile.HugoVersion)
+	assert.True(t, profile.IsHugo)
+	assert.Equal(t, PlatformHugo, profile.Platform.Name)
+	assert.Empty(t, profile.Notice)
+	assert.Equal(t, map[string]string{
+		"search":     "content_scan",
+		"pages":      "index",
+		"content":    "index",
+		"taxonomies": "index",
+		"changes":    "index",
+	}, profile.Strategies)
+}
+
+func TestProbe_WordPress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><head><meta name="generator" content="WordPress 6.4.2">
+<link rel="stylesheet" href="/wp-content/themes/twentytwentyfour/style.css"></head><body>Home</body></html>`))
+		case "/wp-json/":
+			w.Write([]byte(`{"name": "Blog", "namespaces": ["oembed/1.0", "wp/v2"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	profile := Probe(context.Background(), cache.New(), httpclient.New(httpclient.WithRetryPolicy(httpclient.RetryPolicy{})), siteURL)
+
+	assert.Equal(t, Capability{Available: true, Endpoint: server.URL + "/wp-json/", Format: "wp_rest", Count: 2}, profile.Capabilities[WPJSON])
+	assert.False(t, profile.IsHugo)
+	assert.Equal(t, Platform{Name: PlatformWordPress, Evidence: []string{
+		`generator "WordPress 6.4.2" in the home page`,
+		"WordPress REST API at /wp-json/",
+		"/wp-content/ in the home page",
+	}}, profile.Platform)
+	assert.Contains(t, profile.Notice, "looks like a WordPress site")
+	assert.Equal(t, "rendered_html", profile.Strategies["content"])
+}
+
+func TestStrategies(t *testing.T) {
+	tests := []struct {
+		name      string
+		available []string
+		want      map[string]string
+	}{
+		{
+			name:      "nothing",
+			available: nil,
+			want:      map[string]string{"search": Unavailable, "pages": Unavailable, "content": Unavailable, "taxonomies": Unavailable, "changes": Unavailable},
+		},
+		{
+			name:      "full Hugo site",
+			available: []string{Index, OpenSearch, Taxonomies, Sitemap, Feed, HomePage},
+			want:      map[string]string{"search": "hugo_native", "pages": "index", "content": "index", "taxonomies": "taxonomy_endpoints", "changes": "index"},
+		},
+		{
+			name:      "minimal site",
+			available: []string{Feed, Sitemap, HomePage},
+			want:      map[string]string{"search": "feed_scan", "pages": "feed", "content": "rendered_html", "taxonomies": Unavailable, "changes": "sitemap"},
+		},
+		{
+			name:      "sitemap only",
+			available: []string{Sitemap},
+			want:      map[string]string{"search": "sitemap_scan", "pages": "sitemap", "content": "rendered_html", "taxonomies": Unavailable, "changes": "sitemap"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := &Profile{Capabilities: map[string]Capability{}}
+			for _, name := range tt.available {
+				profile.Capabilities[name] = Capability{Available: true}
+			}
+			assert.Equal(t, tt.want, strategies(profile))
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package capabilities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Platforms a site can be detected as built with
+const (
+	PlatformHugo       = "hugo"
+	PlatformWordPress  = "wordpress"
+	PlatformJekyll     = "jekyll"
+	PlatformGhost      = "ghost"
+	PlatformEleventy   = "eleventy"
+	PlatformGatsby     = "gatsby"
+	PlatformHexo       = "hexo"
+	PlatformPelican    = "pelican"
+	PlatformDocusaurus = "docusaurus"
+	PlatformMkDocs     = "mkdocs"
+	PlatformAstro      = "astro"
+	PlatformNextJS     = "nextjs"
+	PlatformUnknown    = "unknown"
+)
+
+// WPJSONPath is the index of the WordPress REST API
+const WPJSONPath = "/wp-json/"
+
+// platformNames are how platforms are named in notices
+var platformNames = map[string]string{
+	PlatformHugo:       "Hugo",
+	PlatformWordPress:  "WordPress",
+	PlatformJekyll:     "Jekyll",
+	PlatformGhost:      "Ghost",
+	PlatformEleventy:   "Eleventy",
+	PlatformGatsby:     "Gatsby",
+	PlatformHexo:       "Hexo",
+	PlatformPelican:    "Pelican",
+	PlatformDocusaurus: "Docusaurus",
+	PlatformMkDocs:     "MkDocs",
+	PlatformAstro:      "Astro",
+	PlatformNextJS:     "Next.js",
+}
+
+// generatorPlatforms match a generator name, lowercased, to the platform
+// it names
+var generatorPlatforms = []struct{ match, platform string }{
+	{"hugo", PlatformHugo},
+	{"wordpress", PlatformWordPress},
+	{"jekyll", PlatformJekyll},
+	{"ghost", PlatformGhost},
+	{"eleventy", PlatformEleventy},
+	{"gatsby", PlatformGatsby},
+	{"hexo", PlatformHexo},
+	{"pelican", PlatformPelican},
+	{"docusaurus", PlatformDocusaurus},
+	{"mkdocs", PlatformMkDocs},
+	{"astro", PlatformAstro},
+	{"next.js", PlatformNextJS},
+}
+
+// pageMarkers are paths and attributes in a page's HTML that give away the
+// platform of sites that don't name a generator
+var pageMarkers = []struct{ match, platform string }{
+	{"/wp-content/", PlatformWordPress},
+	{"/wp-includes/", PlatformWordPress},
+	{`id="___gatsby"`, PlatformGatsby},
+	{"/_astro/", PlatformAstro},
+	{"/_next/static/", PlatformNextJS},
+}
+
+// Platform is the software a site appears to be built with, and what gave
+// it away
+type Platform struct {
+	Name     string   `json:"name"`
+	Evidence []string `json:"evidence,omitempty"`
+}
+
+// PlatformSignals are what a site reveals about the software that built it
+type PlatformSignals struct {
+	// MetaGenerator is the generator named by the home page's meta tag
+	MetaGenerator string
+
+	// FeedGenerator is the generator named by the site's feed
+	FeedGenerator string
+
+	// HomePage is the HTML of the home page
+	HomePage string
+
+	// WPJSON is true when the site answers with a WordPress REST API index
+	WPJSON bool
+
+	// HugoIndex is true when the site publishes a Hugo site index
+	HugoIndex bool
+}
+
+// DetectPlatform names the platform the signals point to. Generators are
+// trusted first, the home page's generator over the feed's, then the
+// WordPress REST API, markers in the home page and last a Hugo site index.
+// Evidence lists every signal pointing to the platform chosen.
+func DetectPlatform(s PlatformSignals) Platform {
+	type clue struct{ platform, evidence string }
+	var clues []clue
+
+	for _, generator := range []struct{ name, source string }{
+		{s.MetaGenerator, "home page"},
+		{s.FeedGenerator, "feed"},
+	} {
+		if platform := generatorPlatform(generator.name); platform != "" {
+			clues = append(clues, clue{platform, fmt.Sprintf("generator %q in the %s", generator.name, generator.source)})
+		}
+	}
+	if s.WPJSON {
+		clues = append(clues, clue{PlatformWordPress, "WordPress REST API at " + WPJSONPath})
+	}
+	seen := make(map[string]bool)
+	for _, marker := range pageMarkers {
+		if !seen[marker.platform] && strings.Contains(s.HomePage, marker.match) {
+			seen[marker.platform] = true
+			clues = append(clues, clue{marker.platform, marker.match + " in the home page"})
+		}
+	}
+	if s.HugoIndex {
+		clues = append(clues, clue{PlatformHugo, "Hugo site index"})
+	}
+
+	if len(clues) == 0 {
+		return Platform{Name: PlatformUnknown}
+	}
+	platform := Platform{Name: clues[0].platform}
+	for _, c := range clues {
+		if c.platform == platform.Name {
+			platform.Evidence = append(platform.Evidence, c.evidence)
+		}
+	}
+	return platform
+}
+
+// generatorPlatform returns the platform a generator names, if known
+func generatorPlatform(generator string) string {
+	generator = strings.ToLower(generator)
+	if generator == "" {
+		return ""
+	}
+	for _, g := range generatorPlatforms {
+		if strings.Contains(generator, g.match) {
+			return g.platform
+		}
+	}
+	return ""
+}
+
+// Notice explains to users of a site that is not built with Hugo, and
+// publishes no site index, why Hugo endpoints are unavailable. It is empty
+// for Hugo sites, sites with an index and sites of unknown platform.
+func (p Platform) Notice(hasIndex bool) string {
+	name, known := platformNames[p.Name]
+	if !known || p.Name == PlatformHugo || hasIndex {
+		return ""
+	}
+	return fmt.Sprintf("This looks like a %s site, so Hugo endpoints such as index.json are unavailable. Tools fall back to the site's feed, sitemap and rendered HTML where it has them.", name)
+}
@@ -0,0 +1,59 @@
+package capabilities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectPlatform(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals PlatformSignals
+		want    Platform
+	}{
+		{
+			name:    "nothing",
+			signals: PlatformSignals{},
+			want:    Platform{Name: PlatformUnknown},
+		},
+		{
+			name:    "Jekyll feed",
+			signals: PlatformSignals{FeedGenerator: "Jekyll"},
+			want:    Platform{Name: PlatformJekyll, Evidence: []string{`generator "Jekyll" in the feed`}},
+		},
+		{
+			name:    "home page generator is trusted over the feed",
+			signals: PlatformSignals{MetaGenerator: "Hugo 0.121.1", FeedGenerator: "WordPress", HugoIndex: true},
+			want:    Platform{Name: PlatformHugo, Evidence: []string{`generator "Hugo 0.121.1" in the home page`, "Hugo site index"}},
+		},
+		{
+			name:    "WordPress without a generator",
+			signals: PlatformSignals{HomePage: `<script src="/wp-includes/js/jquery.js"></script>`},
+			want:    Platform{Name: PlatformWordPress, Evidence: []string{"/wp-includes/ in the home page"}},
+		},
+		{
+			name:    "Hugo index alone",
+			signals: PlatformSignals{HugoIndex: true},
+			want:    Platform{Name: PlatformHugo, Evidence: []string{"Hugo site index"}},
+		},
+		{
+			name:    "unrecognized generator",
+			signals: PlatformSignals{MetaGenerator: "Handmade"},
+			want:    Platform{Name: PlatformUnknown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectPlatform(tt.signals))
+		})
+	}
+}
+
+func TestPlatform_Notice(t *testing.T) {
+	assert.Contains(t, Platform{Name: PlatformJekyll}.Notice(false), "This looks like a Jekyll site")
+	assert.Empty(t, Platform{Name: PlatformJekyll}.Notice(true))
+	assert.Empty(t, Platform{Name: PlatformHugo}.Notice(false))
+	assert.Empty(t, Platform{Name: PlatformUnknown}.Notice(false))
+}
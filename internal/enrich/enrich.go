@@ -0,0 +1,131 @@
+package enrich
+
+import (
+	"strings"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/tidwall/gjson"
+)
+
+// WordsPerMinute is the reading speed Hugo assumes for .ReadingTime
+const WordsPerMinute = 213
+
+// Fields added to enriched pages
+const (
+	FieldWordCount   = "word_count"
+	FieldReadingTime = "reading_time_minutes"
+	FieldLanguage    = "language"
+)
+
+// wordCountFields are the page fields index templates commonly emit for
+// .WordCount
+var wordCountFields = []string{"wordcount", "wordCount", "word_count"}
+
+// readingTimeFields are the page fields index templates commonly emit for
+// .ReadingTime
+var readingTimeFields = []string{"readingtime", "readingTime", "reading_time"}
+
+// languageFields are the page fields index templates commonly emit for the
+// page language
+var languageFields = []string{"lang", "language", "languageCode", "language_code"}
+
+// textFields are the page fields words are counted from when a page has no
+// word count, in order of preference
+var textFields = []string{"plain", "content", "html", "body"}
+
+// Page returns the word count, reading time and language of page, taking
+// each from the page's own fields when it has them and computing it from
+// the page text otherwise. Fields that cannot be determined are left out.
+func Page(page gjson.Result) map[string]interface{} {
+	fields := make(map[string]interface{})
+
+	words, hasWords := intField(page, wordCountFields)
+	lang := stringField(page, languageFields)
+
+	// The text is only converted when something must be computed from it
+	var text string
+	hasText := false
+	if !hasWords || lang == "" {
+		text, hasText = pageText(page)
+	}
+
+	if !hasWords && hasText {
+		words, hasWords = len(strings.Fields(text)), true
+	}
+	if hasWords {
+		fields[FieldWordCount] = words
+	}
+
+	if minutes, ok := intField(page, readingTimeFields); ok {
+		fields[FieldReadingTime] = minutes
+	} else if hasWords {
+		fields[FieldReadingTime] = ReadingTime(words)
+	}
+
+	if lang == "" {
+		if !hasText {
+			text = page.Get("title").String() + " " + page.Get("summary").String()
+		}
+		lang = DetectLanguage(text)
+	}
+	if lang != "" {
+		fields[FieldLanguage] = lang
+	}
+	return fields
+}
+
+// WordCount returns the word count the page reports, or else counts the
+// words of its plain text or rendered content
+func WordCount(page gjson.Result) (int, bool) {
+	if words, ok := intField(page, wordCountFields); ok {
+		return words, true
+	}
+	if text, ok := pageText(page); ok {
+		return len(strings.Fields(text)), true
+	}
+	return 0, false
+}
+
+// ReadingTime returns the minutes Hugo estimates reading words takes
+func ReadingTime(words int) int {
+	return (words + WordsPerMinute - 1) / WordsPerMinute
+}
+
+// pageText returns the page's plain text, or its rendered content as text
+func pageText(page gjson.Result) (string, bool) {
+	for _, field := range textFields {
+		if value := page.Get(field); value.Type == gjson.String {
+			return toText(value.String()), true
+		}
+	}
+	return "", false
+}
+
+// toText converts HTML to plain text, leaving other text unchanged
+func toText(s string) string {
+	if htmltext.LooksLikeHTML(s) {
+		return htmltext.Text(s)
+	}
+	return s
+}
+
+// intField returns the first of fields the page holds as a number
+func intField(page gjson.Result, fields []string) (int, bool) {
+	for _, field := range fields {
+		if value := page.Get(field); value.Type == gjson.Number {
+			return int(value.Int()), true
+		}
+	}
+	return 0, false
+}
+
+// stringField returns the first of fields the page holds as a non-empty
+// string
+func stringField(page gjson.Result, fields []string) string {
+	for _, field := range fields {
+		if value := page.Get(field); value.Type == gjson.String && value.String() != "" {
+			return value.String()
+		}
+	}
+	return ""
+}
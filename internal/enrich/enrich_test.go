@@ -0,0 +1,93 @@
+package enrich
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+func TestPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     string
+		expected map[string]interface{}
+	}{
+		{
+			name:     "fields from the page",
+			page:     `{"title": "Post", "wordcount": 640, "readingtime": 4, "lang": "de", "content": "<p>The cat sat on the mat.</p>"}`,
+			expected: map[string]interface{}{"word_count": 640, "reading_time_minutes": 4, "language": "de"},
+		},
+		{
+			name:     "computed from HTML content",
+			page:     `{"title": "Post", "content": "<p>The cat sat on the mat and it was happy with the view.</p>"}`,
+			expected: map[string]interface{}{"word_count": 13, "reading_time_minutes": 1, "language": "en"},
+		},
+		{
+			name:     "plain text preferred",
+			page:     `{"plain": "Le chat est sur la table et le chien est dans le jardin", "content": "<p>ignored</p>"}`,
+			expected: map[string]interface{}{"word_count": 13, "reading_time_minutes": 1, "language": "fr"},
+		},
+		{
+			name:     "reading time from word count",
+			page:     `{"wordCount": 500, "language": "en-us"}`,
+			expected: map[string]interface{}{"word_count": 500, "reading_time_minutes": 3, "language": "en-us"},
+		},
+		{
+			name:     "no text",
+			page:     `{"title": "Post"}`,
+			expected: map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Page(gjson.Parse(tt.page)))
+		})
+	}
+}
+
+func TestWordCount(t *testing.T) {
+	words, ok := WordCount(gjson.Parse(`{"wordcount": 42, "content": "one two"}`))
+	assert.True(t, ok)
+	assert.Equal(t, 42, words)
+
+	words, ok = WordCount(gjson.Parse(`{"content": "<h1>One</h1><p>two three</p>"}`))
+	assert.True(t, ok)
+	assert.Equal(t, 3, words)
+
+	_, ok = WordCount(gjson.Parse(`{"title": "Post"}`))
+	assert.False(t, ok)
+}
+
+func TestReadingTime(t *testing.T) {
+	assert.Equal(t, 0, ReadingTime(0))
+	assert.Equal(t, 1, ReadingTime(1))
+	assert.Equal(t, 1, ReadingTime(WordsPerMinute))
+	assert.Equal(t, 2, ReadingTime(WordsPerMinute+1))
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{name: "english", text: "This is a post about the history of the web and how it was built.", expected: "en"},
+		{name: "german", text: "Das ist nicht der Weg, und die Katze ist auch nicht mit dem Hund zu Hause.", expected: "de"},
+		{name: "spanish", text: "El perro y los gatos son amigos, pero el gato es más rápido que el perro.", expected: "es"},
+		{name: "japanese", text: "これは日本語の文章です。", expected: "ja"},
+		{name: "chinese", text: "这是一个中文句子。", expected: "zh"},
+		{name: "russian", text: "Это предложение на русском языке.", expected: "ru"},
+		{name: "too short", text: "Hello world", expected: ""},
+		{name: "empty", text: "", expected: ""},
+		{name: "long text", text: strings.Repeat("the cat and the dog ", 1000), expected: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectLanguage(tt.text))
+		})
+	}
+}
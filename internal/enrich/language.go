@@ -0,0 +1,123 @@
+package enrich
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxDetectWords bounds how many words language detection reads
+const maxDetectWords = 2000
+
+// minStopWords is how many stop words must match before a Latin-script
+// language is reported
+const minStopWords = 3
+
+// stopWords are frequent function words of languages written in the Latin
+// script, keyed by ISO 639-1 code
+var stopWords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "it", "with", "for", "this", "was", "are", "on", "you", "be"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "mit", "ein", "eine", "zu", "den", "auf", "ich", "sich", "auch", "von"},
+	"fr": {"le", "la", "les", "et", "est", "une", "des", "du", "que", "pour", "dans", "pas", "sur", "qui", "au", "avec"},
+	"es": {"el", "los", "las", "y", "es", "una", "del", "que", "por", "con", "para", "como", "pero", "su", "se", "al"},
+	"it": {"il", "di", "che", "è", "gli", "una", "per", "non", "sono", "della", "con", "del", "anche", "come", "più", "nel"},
+	"pt": {"o", "os", "as", "e", "é", "uma", "do", "da", "que", "não", "com", "para", "em", "dos", "um", "mais"},
+	"nl": {"de", "het", "een", "en", "is", "van", "niet", "dat", "op", "met", "voor", "zijn", "ook", "maar", "er", "aan"},
+	"sv": {"och", "att", "det", "är", "som", "en", "på", "för", "med", "inte", "av", "till", "den", "har", "jag", "om"},
+}
+
+// stopWordLanguages maps each stop word to the languages it belongs to
+var stopWordLanguages = func() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range stopWords {
+		for _, word := range words {
+			index[word] = append(index[word], lang)
+		}
+	}
+	return index
+}()
+
+// DetectLanguage guesses the ISO 639-1 code of the language text is written
+// in, from its script or, for the Latin script, its most frequent function
+// words. It returns "" when the text gives too little evidence.
+func DetectLanguage(text string) string {
+	if lang := detectScript(text); lang != "" {
+		return lang
+	}
+
+	scores := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for i, word := range words {
+		if i >= maxDetectWords {
+			break
+		}
+		for _, lang := range stopWordLanguages[word] {
+			scores[lang]++
+		}
+	}
+
+	best, bestScore, tied := "", 0, false
+	for lang, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = lang, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if bestScore < minStopWords || tied {
+		return ""
+	}
+	return best
+}
+
+// detectScript returns the language of text written mostly in a script
+// used by a single common language, or "" for other scripts
+func detectScript(text string) string {
+	counts := make(map[string]int)
+	letters := 0
+	for i, r := range text {
+		if i >= maxDetectWords*8 {
+			break
+		}
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			counts["ja"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts["ru"]++
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			counts["he"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		case unicode.Is(unicode.Devanagari, r):
+			counts["hi"]++
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+
+	// Japanese mixes kana with Han characters
+	if counts["ja"] > 0 && counts["ja"]+counts["zh"] > letters/2 {
+		return "ja"
+	}
+	for lang, count := range counts {
+		if lang != "ja" && count > letters/2 {
+			return lang
+		}
+	}
+	return ""
+}
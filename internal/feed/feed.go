@@ -0,0 +1,280 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// Paths are where Hugo sites conventionally publish feeds, in order of preference
+var Paths = []string{"/index.xml", "/feed.json"}
+
+// Feed formats
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+	FormatJSON = "json_feed"
+)
+
+// ErrNotFeed is returned by Parse for data in no supported feed format
+var ErrNotFeed = errors.New("not an RSS, Atom or JSON feed")
+
+// Feed is a site feed and where it was loaded from
+type Feed struct {
+	URL       string
+	Title     string
+	Format    string
+	Generator string
+	Items     []Item
+	Cached    bool
+}
+
+// Item is a feed entry in the shape of a Hugo index page
+type Item struct {
+	Title   string   `json:"title"`
+	URL     string   `json:"url"`
+	Date    string   `json:"date,omitempty"`
+	Lastmod string   `json:"lastmod,omitempty"`
+	Summary string   `json:"summary,omitempty"`
+	Content string   `json:"content,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Authors []string `json:"authors,omitempty"`
+}
+
+type rssDocument struct {
+	Channel struct {
+		Title     string `xml:"title"`
+		Generator string `xml:"generator"`
+		Items     []struct {
+			Title       string   `xml:"title"`
+			Link        string   `xml:"link"`
+			GUID        string   `xml:"guid"`
+			PubDate     string   `xml:"pubDate"`
+			Description string   `xml:"description"`
+			Encoded     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+			Categories  []string `xml:"category"`
+			Author      string   `xml:"author"`
+			Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atomDocument struct {
+	Title     string `xml:"title"`
+	Generator string `xml:"generator"`
+	Entries   []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		ID         string `xml:"id"`
+		Published  string `xml:"published"`
+		Updated    string `xml:"updated"`
+		Summary    string `xml:"summary"`
+		Content    string `xml:"content"`
+		Categories []struct {
+			Term string `xml:"term,attr"`
+		} `xml:"category"`
+		Authors []struct {
+			Name string `xml:"name"`
+		} `xml:"author"`
+	} `xml:"entry"`
+}
+
+// Load fetches the first feed at Paths that parses through the cache,
+// revalidating expired entries
+func Load(ctx context.Context, c *cache.Cache, client *httpclient.Client, siteURL *url.URL) (*Feed, error) {
+	var lastErr error
+	for _, path := range Paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		feedURL := siteURL.ResolveReference(&url.URL{Path: path}).String()
+		cacheKey := c.BuildKey(siteURL.String(), path, nil)
+		result, err := c.Fetch(ctx, client, cacheKey, feedURL, Valid)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		feed, err := Parse(result.Data)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		feed.URL = feedURL
+		feed.Cached = result.Cached || result.Revalidated
+		return feed, nil
+	}
+	return nil, fmt.Errorf("no feed available: %w", lastErr)
+}
+
+// Valid reports whether data is a feed with at least one item
+func Valid(data []byte) bool {
+	feed, err := Parse(data)
+	return err == nil && len(feed.Items) > 0
+}
+
+// Parse parses an RSS 2.0, Atom or JSON Feed document. HTML descriptions
+// and content are reduced to plain text.
+func Parse(data []byte) (*Feed, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return parseJSON(trimmed)
+	}
+
+	root, err := rootElement(trimmed)
+	if err != nil {
+		return nil, ErrNotFeed
+	}
+	switch root {
+	case "rss":
+		return parseRSS(trimmed)
+	case "feed":
+		return parseAtom(trimmed)
+	default:
+		return nil, ErrNotFeed
+	}
+}
+
+// rootElement returns the local name of the first element of an XML document
+func rootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func parseRSS(data []byte) (*Feed, error) {
+	var doc rssDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid RSS feed: %w", err)
+	}
+
+	feed := &Feed{Title: strings.TrimSpace(doc.Channel.Title), Format: FormatRSS, Generator: strings.TrimSpace(doc.Channel.Generator), Items: []Item{}}
+	for _, entry := range doc.Channel.Items {
+		item := Item{
+			Title:   strings.TrimSpace(entry.Title),
+			URL:     firstNonEmpty(entry.Link, entry.GUID),
+			Date:    strings.TrimSpace(entry.PubDate),
+			Summary: htmltext.Text(entry.Description),
+			Content: htmltext.Text(entry.Encoded),
+			Tags:    trimAll(entry.Categories),
+			Authors: trimAll([]string{firstNonEmpty(entry.Creator, entry.Author)}),
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+func parseAtom(data []byte) (*Feed, error) {
+	var doc atomDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid Atom feed: %w", err)
+	}
+
+	feed := &Feed{Title: strings.TrimSpace(doc.Title), Format: FormatAtom, Generator: strings.TrimSpace(doc.Generator), Items: []Item{}}
+	for _, entry := range doc.Entries {
+		link := ""
+		for _, l := range entry.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = l.Href
+				break
+			}
+		}
+		item := Item{
+			Title:   strings.TrimSpace(entry.Title),
+			URL:     firstNonEmpty(link, entry.ID),
+			Date:    firstNonEmpty(entry.Published, entry.Updated),
+			Lastmod: strings.TrimSpace(entry.Updated),
+			Summary: htmltext.Text(entry.Summary),
+			Content: htmltext.Text(entry.Content),
+		}
+		for _, category := range entry.Categories {
+			item.Tags = append(item.Tags, category.Term)
+		}
+		for _, author := range entry.Authors {
+			item.Authors = append(item.Authors, author.Name)
+		}
+		item.Tags, item.Authors = trimAll(item.Tags), trimAll(item.Authors)
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+func parseJSON(data []byte) (*Feed, error) {
+	parsed := gjson.ParseBytes(data)
+	if !gjson.ValidBytes(data) || !strings.Contains(parsed.Get("version").String(), "jsonfeed.org") || !parsed.Get("items").IsArray() {
+		return nil, ErrNotFeed
+	}
+
+	feed := &Feed{Title: strings.TrimSpace(parsed.Get("title").String()), Format: FormatJSON, Items: []Item{}}
+	parsed.Get("items").ForEach(func(_, entry gjson.Result) bool {
+		content := entry.Get("content_text").String()
+		if html := entry.Get("content_html").String(); html != "" {
+			content = htmltext.Text(html)
+		}
+		item := Item{
+			Title:   strings.TrimSpace(entry.Get("title").String()),
+			URL:     firstNonEmpty(entry.Get("url").String(), entry.Get("external_url").String(), entry.Get("id").String()),
+			Date:    entry.Get("date_published").String(),
+			Lastmod: entry.Get("date_modified").String(),
+			Summary: htmltext.Text(entry.Get("summary").String()),
+			Content: content,
+		}
+		entry.Get("tags").ForEach(func(_, tag gjson.Result) bool {
+			item.Tags = append(item.Tags, tag.String())
+			return true
+		})
+		// Version 1.1 lists authors; 1.0 had a single author
+		if authors := entry.Get("authors"); authors.IsArray() {
+			authors.ForEach(func(_, author gjson.Result) bool {
+				item.Authors = append(item.Authors, author.Get("name").String())
+				return true
+			})
+		} else {
+			item.Authors = []string{entry.Get("author.name").String()}
+		}
+		item.Tags, item.Authors = trimAll(item.Tags), trimAll(item.Authors)
+		feed.Items = append(feed.Items, item)
+		return true
+	})
+	return feed, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// trimAll trims values and drops empty ones, returning nil when none remain
+func trimAll(values []string) []string {
+	var result []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,145 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <title>My Site</title>
+    <generator>Hugo -- gohugo.io</generator>
+    <item>
+      <title>My Post</title>
+      <link>https://example.com/posts/my-post/</link>
+      <pubDate>Mon, 02 Jan 2023 15:04:05 +0000</pubDate>
+      <guid>https://example.com/posts/my-post/</guid>
+      <description>&lt;p&gt;A &lt;em&gt;short&lt;/em&gt; summary&lt;/p&gt;</description>
+      <category>go</category>
+      <dc:creator>Jane</dc:creator>
+    </item>
+    <item>
+      <title>Untitled link</title>
+      <guid>https://example.com/posts/guid-only/</guid>
+    </item>
+  </channel>
+</rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>My Site</title>
+  <entry>
+    <title>Atom Post</title>
+    <link rel="self" href="https://example.com/atom.xml"/>
+    <link href="https://example.com/posts/atom/"/>
+    <id>tag:example.com,2023:atom</id>
+    <published>2023-01-02T15:04:05Z</published>
+    <updated>2023-02-01T00:00:00Z</updated>
+    <summary type="html">&lt;p&gt;Atom summary&lt;/p&gt;</summary>
+    <category term="hugo"/>
+    <author><name>Sam</name></author>
+  </entry>
+</feed>`
+
+const jsonFeed = `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "My Site",
+  "items": [
+    {"id": "1", "url": "https://example.com/posts/json/", "title": "JSON Post", "content_html": "<p>Full <b>content</b></p>",
+     "summary": "Short", "date_published": "2023-01-02T15:04:05Z", "tags": ["go", "hugo"], "authors": [{"name": "Alex"}]},
+    {"id": "https://example.com/posts/old/", "title": "Old", "content_text": "Plain", "author": {"name": "Kim"}}
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	feed, err := Parse([]byte(rssFeed))
+	require.NoError(t, err)
+	assert.Equal(t, FormatRSS, feed.Format)
+	assert.Equal(t, "My Site", feed.Title)
+	assert.Equal(t, "Hugo -- gohugo.io", feed.Generator)
+	require.Len(t, feed.Items, 2)
+	assert.Equal(t, Item{
+		Title:   "My Post",
+		URL:     "https://example.com/posts/my-post/",
+		Date:    "Mon, 02 Jan 2023 15:04:05 +0000",
+		Summary: "A short summary",
+		Tags:    []string{"go"},
+		Authors: []string{"Jane"},
+	}, feed.Items[0])
+	assert.Equal(t, "https://example.com/posts/guid-only/", feed.Items[1].URL)
+
+	feed, err = Parse([]byte(atomFeed))
+	require.NoError(t, err)
+	assert.Equal(t, FormatAtom, feed.Format)
+	require.Len(t, feed.Items, 1)
+	assert.Equal(t, Item{
+		Title:   "Atom Post",
+		URL:     "https://example.com/posts/atom/",
+		Date:    "2023-01-02T15:04:05Z",
+		Lastmod: "2023-02-01T00:00:00Z",
+		Summary: "Atom summary",
+		Tags:    []string{"hugo"},
+		Authors: []string{"Sam"},
+	}, feed.Items[0])
+
+	feed, err = Parse([]byte(jsonFeed))
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, feed.Format)
+	require.Len(t, feed.Items, 2)
+	assert.Equal(t, "Full content", feed.Items[0].Content)
+	assert.Equal(t, []string{"go", "hugo"}, feed.Items[0].Tags)
+	assert.Equal(t, []string{"Alex"}, feed.Items[0].Authors)
+	assert.Equal(t, "https://example.com/posts/old/", feed.Items[1].URL)
+	assert.Equal(t, "Plain", feed.Items[1].Content)
+	assert.Equal(t, []string{"Kim"}, feed.Items[1].Authors)
+
+	for _, data := range []string{`{"pages": []}`, `<html><body>Not a feed</body></html>`, `not xml`, ``} {
+		_, err = Parse([]byte(data))
+		assert.ErrorIs(t, err, ErrNotFeed, data)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.xml":
+			// Hugo sites without a home page feed may serve an HTML page here
+			w.Write([]byte("<html><body>Home</body></html>"))
+		case "/feed.json":
+			w.Write([]byte(jsonFeed))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	c := cache.New()
+
+	feed, err := Load(context.Background(), c, httpclient.New(), siteURL)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/feed.json", feed.URL)
+	assert.Equal(t, FormatJSON, feed.Format)
+	assert.False(t, feed.Cached)
+
+	feed, err = Load(context.Background(), c, httpclient.New(), siteURL)
+	require.NoError(t, err)
+	assert.True(t, feed.Cached)
+
+	empty := httptest.NewServer(http.NotFoundHandler())
+	defer empty.Close()
+	emptyURL, err := url.Parse(empty.URL)
+	require.NoError(t, err)
+	_, err = Load(context.Background(), c, httpclient.New(), emptyURL)
+	assert.Error(t, err)
+}
@@ -0,0 +1,419 @@
+// Package generic reads sites as generic static sites, from their sitemap
+// and rendered HTML alone, for sites that publish none of Hugo's JSON
+// outputs or are not built with Hugo at all.
+package generic
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+)
+
+// Format is the format of site indexes built from a sitemap and the HTML
+// of the pages it lists
+const Format = "sitemap_html"
+
+// MaxPages bounds how many pages of a sitemap are read to build an index
+const MaxPages = 200
+
+// SitemapPath is where sites conventionally publish their sitemap when
+// robots.txt declares none
+const SitemapPath = "/sitemap.xml"
+
+// IndexEndpoint is the cache key path built indexes are kept under
+const IndexEndpoint = "/.hugo-reader/generic-index"
+
+// fetchConcurrency bounds the number of pages read at once
+const fetchConcurrency = 4
+
+// maxChildSitemaps bounds how many sitemaps of a sitemap index are read
+const maxChildSitemaps = 50
+
+// ErrNoSitemap is returned when a site has no sitemap listing pages
+var ErrNoSitemap = errors.New("no sitemap listing pages")
+
+// Options select generic mode. Embed it in request structs.
+type Options struct {
+	Generic bool `json:"generic,omitempty" jsonschema:"title=Read the site as a generic static site from its sitemap and rendered HTML only, ignoring Hugo JSON outputs (used automatically when those are missing)"`
+}
+
+// Entry is a page a sitemap lists
+type Entry struct {
+	Loc     string
+	LastMod string
+}
+
+// sitemapDocument is a sitemap or a sitemap index
+type sitemapDocument struct {
+	URLs []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// Sitemap returns the pages the site's sitemap lists on its host, once
+// each, reading the sitemaps of a sitemap index in turn. The first sitemap
+// robots.txt declares that can be read is used, else SitemapPath. It also
+// returns the URL the sitemap was read from.
+func Sitemap(ctx context.Context, c *cache.Cache, client *httpclient.Client, siteURL *url.URL) ([]Entry, string, error) {
+	read, err := readSitemap(ctx, c, client, siteURL)
+	if read.source == nil {
+		return nil, "", err
+	}
+	return read.entries, read.source.String(), err
+}
+
+// sitemapRead is what readSitemap found
+type sitemapRead struct {
+	entries []Entry
+	source  *url.URL
+
+	// index is set when the sitemap was a sitemap index
+	index bool
+}
+
+// readSitemap is Sitemap, also telling whether a sitemap index was read
+func readSitemap(ctx context.Context, c *cache.Cache, client *httpclient.Client, siteURL *url.URL) (sitemapRead, error) {
+	candidates := []*url.URL{}
+	if declared, err := client.Sitemaps(ctx, siteURL); err == nil {
+		for _, raw := range declared {
+			if u, err := url.Parse(raw); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+				candidates = append(candidates, u)
+			}
+		}
+	}
+	candidates = append(candidates, siteURL.ResolveReference(&url.URL{Path: SitemapPath}))
+
+	var doc sitemapDocument
+	var read sitemapRead
+	var lastErr error
+	for _, candidate := range candidates {
+		data, err := fetch(ctx, c, client, siteURL, candidate, validSitemap)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err := xml.Unmarshal(data, &doc); err == nil {
+			read.source = candidate
+			break
+		}
+	}
+	if read.source == nil {
+		return read, fmt.Errorf("%w: %w", ErrNoSitemap, lastErr)
+	}
+	read.index = len(doc.Sitemaps) > 0
+
+	seen := make(map[string]bool)
+	add := func(doc sitemapDocument) {
+		for _, u := range doc.URLs {
+			loc := strings.TrimSpace(u.Loc)
+			target, err := siteURL.Parse(loc)
+			if loc == "" || err != nil || !strings.EqualFold(target.Host, siteURL.Host) {
+				continue
+			}
+			key := hugoindex.CanonicalURL(siteURL, loc)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			read.entries = append(read.entries, Entry{Loc: target.String(), LastMod: strings.TrimSpace(u.LastMod)})
+		}
+	}
+	add(doc)
+
+	for i, child := range doc.Sitemaps {
+		if i >= maxChildSitemaps || ctx.Err() != nil {
+			break
+		}
+		childURL, err := siteURL.Parse(strings.TrimSpace(child.Loc))
+		if err != nil || !strings.EqualFold(childURL.Host, siteURL.Host) {
+			continue
+		}
+		data, err := fetch(ctx, c, client, siteURL, childURL, validSitemap)
+		if err != nil {
+			continue
+		}
+		var childDoc sitemapDocument
+		if xml.Unmarshal(data, &childDoc) == nil {
+			add(childDoc)
+		}
+	}
+
+	if len(read.entries) == 0 {
+		return read, fmt.Errorf("%w: %s lists no pages on %s", ErrNoSitemap, read.source, siteURL.Host)
+	}
+	return read, nil
+}
+
+// Page reads the page at pageURL through the cache and returns it in the
+// shape of a Hugo index page, with its main content as HTML
+func Page(ctx context.Context, c *cache.Cache, client *httpclient.Client, siteURL, pageURL *url.URL) (map[string]interface{}, error) {
+	data, err := fetch(ctx, c, client, siteURL, pageURL, validHTML)
+	if err != nil {
+		return nil, err
+	}
+	return pageFields(siteURL, pageURL, htmltext.ParsePage(string(data))), nil
+}
+
+// pageFields returns a parsed page as the fields of a Hugo index page.
+// Pages without an article:section are in the section named by the first
+// segment of their path.
+func pageFields(siteURL, pageURL *url.URL, parsed htmltext.Page) map[string]interface{} {
+	path := pageURL.Path
+	if path == "" {
+		path = "/"
+	}
+	fields := map[string]interface{}{
+		"title":     parsed.Title,
+		"url":       path,
+		"permalink": pageURL.String(),
+		"content":   parsed.Content,
+	}
+	if !strings.EqualFold(pageURL.Host, siteURL.Host) {
+		fields["url"] = pageURL.String()
+	}
+	section := parsed.Section
+	if section == "" {
+		if first, _, ok := strings.Cut(strings.TrimPrefix(path, "/"), "/"); ok {
+			section = first
+		}
+	}
+	for name, value := range map[string]string{
+		"summary": parsed.Description,
+		"date":    parsed.Date,
+		"lastmod": parsed.Lastmod,
+		"section": section,
+		"lang":    parsed.Lang,
+	} {
+		if value != "" {
+			fields[name] = value
+		}
+	}
+	if len(parsed.Tags) > 0 {
+		fields["tags"] = parsed.Tags
+	}
+	return fields
+}
+
+// Stats tells how Load came by an index built anew
+type Stats struct {
+	// Restored is the number of pages taken from the stored index, and
+	// Read the number read from their HTML
+	Restored int
+	Read     int
+
+	// SitemapUnchanged is set when a conditional request found the sitemap
+	// the stored index was built from unchanged
+	SitemapUnchanged bool
+
+	// Persisted is set when the index was written to the store, and
+	// PersistErr is why it could not be
+	Persisted  bool
+	PersistErr error
+}
+
+// Load returns a site index of the first MaxPages pages the site's sitemap
+// lists, read from their HTML concurrently through the cache. The index
+// holds the text of each page's main content, and is itself cached under
+// IndexEndpoint so later calls reuse it until it expires.
+//
+// With a store, the index is also kept on disk. Pages of the stored index
+// whose sitemap lastmod is unchanged are reused rather than read again,
+// and all of them when a conditional request finds the sitemap unchanged.
+// Bypassing the cache ignores the stored index. Indexes read with per-call
+// credentials are cached apart and never stored, so calls without them, in
+// this session or later ones, cannot read them.
+func Load(ctx context.Context, c *cache.Cache, client *httpclient.Client, store *Store, siteURL *url.URL) (*hugoindex.SiteIndex, Stats, error) {
+	var stats Stats
+	site := siteURL.String()
+	indexURL := siteURL.ResolveReference(&url.URL{Path: IndexEndpoint}).String()
+	cacheKey := cache.ScopedKey(ctx, c.BuildKey(site, IndexEndpoint, map[string]string{"max_pages": strconv.Itoa(MaxPages)}))
+	if cache.ScopeOf(ctx, siteURL.Host) != "" {
+		store = nil
+	}
+	bypass := cache.PolicyFrom(ctx).Bypass
+	if !bypass {
+		if data, ok := c.Get(cacheKey); ok {
+			return hugoindex.Built(indexURL, data, Format, true), stats, nil
+		}
+	}
+
+	var stored *storedIndex
+	if store != nil && !bypass {
+		// Unreadable stored indexes are replaced
+		stored, _ = store.read(site)
+	}
+
+	built := &storedIndex{Version: StoreVersion, Site: site, BuiltAt: time.Now()}
+	var entries []Entry
+	if stored != nil && sitemapUnchanged(ctx, client, stored) {
+		stats.SitemapUnchanged = true
+		built.Sitemap, built.ETag, built.LastModified = stored.Sitemap, stored.ETag, stored.LastModified
+		for _, page := range stored.Pages {
+			entries = append(entries, Entry{Loc: page.Loc, LastMod: page.LastMod})
+		}
+	} else {
+		read, err := readSitemap(ctx, c, client, siteURL)
+		if err != nil {
+			return nil, stats, err
+		}
+		entries = read.entries
+		built.Sitemap = read.source.String()
+		if !read.index {
+			// The sitemaps of an index may change while the index does not
+			built.ETag, built.LastModified, _ = c.EntryValidators(cache.ScopedKey(ctx, resourceKey(c, siteURL, read.source)))
+		}
+	}
+	if len(entries) > MaxPages {
+		entries = entries[:MaxPages]
+	}
+
+	previous := make(map[string]storedPage)
+	if stored != nil {
+		for _, page := range stored.Pages {
+			previous[page.Loc] = page
+		}
+	}
+
+	pages := make([]*storedPage, len(entries))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, fetchConcurrency)
+	for i, entry := range entries {
+		if page, ok := previous[entry.Loc]; ok && page.current(entry, built.BuiltAt) {
+			pages[i] = &page
+			stats.Restored++
+			continue
+		}
+		pageURL, err := url.Parse(entry.Loc)
+		if err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, entry Entry, pageURL *url.URL) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			page, err := Page(ctx, c, client, siteURL, pageURL)
+			if err != nil {
+				return
+			}
+			page["content"] = htmltext.Text(page["content"].(string))
+			if _, ok := page["lastmod"]; !ok && entry.LastMod != "" {
+				page["lastmod"] = entry.LastMod
+			}
+			pages[i] = &storedPage{Loc: entry.Loc, LastMod: entry.LastMod, ReadAt: time.Now(), Page: page}
+		}(i, entry, pageURL)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, stats, err
+	}
+
+	read := make([]map[string]interface{}, 0, len(pages))
+	for _, page := range pages {
+		if page != nil {
+			read = append(read, page.Page)
+			built.Pages = append(built.Pages, *page)
+		}
+	}
+	stats.Read = len(read) - stats.Restored
+	if len(read) == 0 {
+		return nil, stats, fmt.Errorf("none of the %d pages in the sitemap could be read", len(entries))
+	}
+
+	data, err := json.Marshal(read)
+	if err != nil {
+		return nil, stats, fmt.Errorf("failed to marshal site index: %w", err)
+	}
+	c.Set(cacheKey, data, "", "")
+	if store != nil {
+		stats.PersistErr = store.write(built)
+		stats.Persisted = stats.PersistErr == nil
+	}
+	return hugoindex.Built(indexURL, data, Format, false), stats, nil
+}
+
+// sitemapUnchanged reports whether a conditional HEAD request finds the
+// sitemap a stored index was built from unchanged. Servers that ignore
+// conditions on HEAD requests are judged by the validators they send.
+func sitemapUnchanged(ctx context.Context, client *httpclient.Client, stored *storedIndex) bool {
+	if stored.ETag == "" && stored.LastModified == "" {
+		return false
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, stored.Sitemap, nil)
+	if err != nil {
+		return false
+	}
+	if stored.ETag != "" {
+		req.Header.Set("If-None-Match", stored.ETag)
+	}
+	if stored.LastModified != "" {
+		req.Header.Set("If-Modified-Since", stored.LastModified)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return true
+	case resp.StatusCode != http.StatusOK:
+		return false
+	case stored.ETag != "":
+		return resp.Header.Get("ETag") == stored.ETag
+	default:
+		return resp.Header.Get("Last-Modified") == stored.LastModified
+	}
+}
+
+// fetch returns a resource through the cache
+func fetch(ctx context.Context, c *cache.Cache, client *httpclient.Client, siteURL, resourceURL *url.URL, valid func([]byte) bool) ([]byte, error) {
+	result, err := c.Fetch(ctx, client, resourceKey(c, siteURL, resourceURL), resourceURL.String(), valid)
+	if err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// resourceKey returns the cache key of a resource, under the site's keys
+// when it is on the site's host
+func resourceKey(c *cache.Cache, siteURL, resourceURL *url.URL) string {
+	site := resourceURL.Scheme + "://" + resourceURL.Host
+	if strings.EqualFold(resourceURL.Host, siteURL.Host) {
+		site = siteURL.String()
+	}
+	return c.BuildKey(site, resourceURL.RequestURI(), nil)
+}
+
+func validSitemap(data []byte) bool {
+	var doc sitemapDocument
+	return xml.Unmarshal(data, &doc) == nil && (len(doc.URLs) > 0 || len(doc.Sitemaps) > 0)
+}
+
+func validHTML(data []byte) bool {
+	return htmltext.LooksLikeHTML(string(data))
+}
@@ -0,0 +1,123 @@
+package generic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// newSite serves a site with a sitemap index and HTML pages only, counting
+// the requests for pages
+func newSite(t *testing.T, pageRequests *int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := "http://" + r.Host
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			w.Write([]byte(`<sitemapindex><sitemap><loc>` + host + `/posts-sitemap.xml</loc></sitemap></sitemapindex>`))
+		case "/posts-sitemap.xml":
+			w.Write([]byte(`<urlset>
+<url><loc>` + host + `/posts/learning-go/</loc><lastmod>2024-03-02</lastmod></url>
+<url><loc>` + host + `/posts/learning-go/index.html</loc></url>
+<url><loc>` + host + `/about/</loc></url>
+<url><loc>https://elsewhere.example/page/</loc></url>
+</urlset>`))
+		case "/posts/learning-go/":
+			atomic.AddInt32(pageRequests, 1)
+			w.Write([]byte(`<html lang="en"><head><title>Learning Go</title><meta name="description" content="First steps.">
+<meta property="article:tag" content="go"></head><body><nav>Menu</nav><main><p>Go is <b>simple</b>.</p></main></body></html>`))
+		case "/about/":
+			atomic.AddInt32(pageRequests, 1)
+			w.Write([]byte(`<html><head><title>About</title></head><body><p>About me.</p></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSitemap(t *testing.T) {
+	var requests int32
+	server := newSite(t, &requests)
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	entries, source, err := Sitemap(context.Background(), cache.New(), httpclient.New(), siteURL)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/sitemap.xml", source)
+	assert.Equal(t, []Entry{
+		{Loc: server.URL + "/posts/learning-go/", LastMod: "2024-03-02"},
+		{Loc: server.URL + "/about/"},
+	}, entries)
+}
+
+func TestSitemap_Missing(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	_, _, err = Sitemap(context.Background(), cache.New(), httpclient.New(), siteURL)
+	assert.ErrorIs(t, err, ErrNoSitemap)
+}
+
+func TestPage(t *testing.T) {
+	var requests int32
+	server := newSite(t, &requests)
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	pageURL, err := url.Parse(server.URL + "/posts/learning-go/")
+	require.NoError(t, err)
+
+	page, err := Page(context.Background(), cache.New(), httpclient.New(), siteURL, pageURL)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"title":     "Learning Go",
+		"url":       "/posts/learning-go/",
+		"permalink": server.URL + "/posts/learning-go/",
+		"content":   "<p>Go is <b>simple</b>.</p>",
+		"summary":   "First steps.",
+		"section":   "posts",
+		"lang":      "en",
+		"tags":      []string{"go"},
+	}, page)
+}
+
+func TestLoad(t *testing.T) {
+	var requests int32
+	server := newSite(t, &requests)
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	c := cache.New()
+
+	index, stats, err := Load(context.Background(), c, httpclient.New(), nil, siteURL)
+	require.NoError(t, err)
+	assert.Equal(t, Format, index.Format())
+	assert.False(t, index.Cached())
+	assert.Equal(t, Stats{Read: 2}, stats)
+
+	var pages []gjson.Result
+	require.NoError(t, index.Pages(context.Background(), func(page gjson.Result) bool {
+		pages = append(pages, page)
+		return true
+	}))
+	require.Len(t, pages, 2)
+	assert.Equal(t, "Go is simple.", pages[0].Get("content").String())
+	assert.Equal(t, "2024-03-02", pages[0].Get("lastmod").String())
+	assert.Equal(t, "/about/", pages[1].Get("url").String())
+
+	// The built index is reused
+	index, _, err = Load(context.Background(), c, httpclient.New(), nil, siteURL)
+	require.NoError(t, err)
+	assert.True(t, index.Cached())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
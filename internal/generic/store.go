@@ -0,0 +1,132 @@
+package generic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+)
+
+// StoreVersion is the version of the stored index format. Indexes stored
+// by another version are built again.
+const StoreVersion = 1
+
+// UnversionedMaxAge is how long a stored page the sitemap gives no lastmod
+// for is reused before it is read again
+const UnversionedMaxAge = 24 * time.Hour
+
+// Store keeps the indexes built from sitemaps in a directory, one file per
+// site, so later sessions start from them and only read the pages that
+// changed
+type Store struct {
+	dir string
+}
+
+// NewStore returns a store keeping indexes in dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// DefaultStoreDir returns the directory indexes are kept in unless
+// configured otherwise: hugo-reader/indexes in the user's cache directory
+func DefaultStoreDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "hugo-reader", "indexes")
+}
+
+// Dir returns the directory the store keeps indexes in
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// storedIndex is the index of a site as kept on disk, with the validators
+// of the sitemap it was built from
+type storedIndex struct {
+	Version      int          `json:"version"`
+	Site         string       `json:"site"`
+	BuiltAt      time.Time    `json:"built_at"`
+	Sitemap      string       `json:"sitemap"`
+	ETag         string       `json:"etag,omitempty"`
+	LastModified string       `json:"last_modified,omitempty"`
+	Pages        []storedPage `json:"pages"`
+}
+
+// storedPage is a page of a stored index, with the sitemap lastmod it was
+// read for
+type storedPage struct {
+	Loc     string                 `json:"loc"`
+	LastMod string                 `json:"lastmod,omitempty"`
+	ReadAt  time.Time              `json:"read_at"`
+	Page    map[string]interface{} `json:"page"`
+}
+
+// current reports whether the stored page can stand for the page the
+// sitemap lists as entry: its lastmod is unchanged or, when the sitemap
+// gives none, it was read less than UnversionedMaxAge ago
+func (p storedPage) current(entry Entry, now time.Time) bool {
+	if p.Loc != entry.Loc || p.LastMod != entry.LastMod {
+		return false
+	}
+	return entry.LastMod != "" || now.Sub(p.ReadAt) < UnversionedMaxAge
+}
+
+// path returns the file the index of site is kept in
+func (s *Store) path(site string) string {
+	return filepath.Join(s.dir, cache.RequestHash(site)+".json")
+}
+
+// read returns the stored index of site, or nil when there is none the
+// current version can use
+func (s *Store) read(site string) (*storedIndex, error) {
+	data, err := os.ReadFile(s.path(site))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stored index: %w", err)
+	}
+	var stored storedIndex
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Version != StoreVersion || stored.Site != site {
+		return nil, nil
+	}
+	return &stored, nil
+}
+
+// write replaces the stored index of its site atomically. Files are only
+// readable by the user running the server, as they may hold pages of
+// protected sites.
+func (s *Store) write(stored *storedIndex) error {
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stored index: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+	path := s.path(stored.Site)
+	tmp, err := os.CreateTemp(s.dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create index file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write index file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package generic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// versionedSite serves a sitemap with an ETag, whose entries and pages the
+// test changes, recording the pages requested
+type versionedSite struct {
+	mu       sync.Mutex
+	etag     string
+	lastmods map[string]string
+	titles   map[string]string
+	requests []string
+}
+
+func (s *versionedSite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.URL.Path == "/sitemap.xml" {
+		if r.Header.Get("If-None-Match") == s.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", s.etag)
+		body := `<urlset>`
+		for _, path := range []string{"/one/", "/two/"} {
+			body += `<url><loc>http://` + r.Host + path + `</loc>`
+			if lastmod := s.lastmods[path]; lastmod != "" {
+				body += `<lastmod>` + lastmod + `</lastmod>`
+			}
+			body += `</url>`
+		}
+		w.Write([]byte(body + `</urlset>`))
+		return
+	}
+	title, ok := s.titles[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	s.requests = append(s.requests, r.URL.Path)
+	w.Write([]byte(`<html><head><title>` + title + `</title></head><body><main><p>` + title + `</p></main></body></html>`))
+}
+
+func (s *versionedSite) pagesRequested() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requested := s.requests
+	s.requests = nil
+	return requested
+}
+
+func TestLoad_Store(t *testing.T) {
+	site := &versionedSite{
+		etag:     `"v1"`,
+		lastmods: map[string]string{"/one/": "2024-01-01"},
+		titles:   map[string]string{"/one/": "One", "/two/": "Two"},
+	}
+	server := httptest.NewServer(site)
+	defer server.Close()
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	store := NewStore(t.TempDir())
+	ctx := context.Background()
+
+	titles := func(t *testing.T, c *cache.Cache) ([]string, Stats) {
+		index, stats, err := Load(ctx, c, httpclient.New(), store, siteURL)
+		require.NoError(t, err)
+		var titles []string
+		require.NoError(t, index.Pages(ctx, func(page gjson.Result) bool {
+			titles = append(titles, page.Get("title").String())
+			return true
+		}))
+		return titles, stats
+	}
+
+	// The first build reads every page and stores the index
+	got, stats := titles(t, cache.New())
+	assert.Equal(t, []string{"One", "Two"}, got)
+	assert.Equal(t, Stats{Read: 2, Persisted: true}, stats)
+	assert.ElementsMatch(t, []string{"/one/", "/two/"}, site.pagesRequested())
+	info, err := os.Stat(store.path(server.URL))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// A later session finds the sitemap unchanged and reads no page
+	got, stats = titles(t, cache.New())
+	assert.Equal(t, []string{"One", "Two"}, got)
+	assert.Equal(t, Stats{Restored: 2, SitemapUnchanged: true, Persisted: true}, stats)
+	assert.Empty(t, site.pagesRequested())
+
+	// Only pages whose lastmod changed are read again
+	site.mu.Lock()
+	site.etag = `"v2"`
+	site.lastmods["/one/"] = "2024-02-01"
+	site.titles["/one/"] = "One, revised"
+	site.titles["/two/"] = "Two, revised"
+	site.mu.Unlock()
+	got, stats = titles(t, cache.New())
+	assert.Equal(t, []string{"One, revised", "Two"}, got)
+	assert.Equal(t, Stats{Read: 1, Restored: 1, Persisted: true}, stats)
+	assert.Equal(t, []string{"/one/"}, site.pagesRequested())
+
+	// Pages without a lastmod are read again once they are old
+	stored, err := store.read(server.URL)
+	require.NoError(t, err)
+	stored.Pages[1].ReadAt = time.Now().Add(-UnversionedMaxAge - time.Minute)
+	require.NoError(t, store.write(stored))
+	got, stats = titles(t, cache.New())
+	assert.Equal(t, []string{"One, revised", "Two, revised"}, got)
+	assert.Equal(t, Stats{Read: 1, Restored: 1, SitemapUnchanged: true, Persisted: true}, stats)
+	assert.Equal(t, []string{"/two/"}, site.pagesRequested())
+
+	// Indexes stored by another version are built again
+	stored, err = store.read(server.URL)
+	require.NoError(t, err)
+	stored.Version = StoreVersion + 1
+	require.NoError(t, store.write(stored))
+	_, stats = titles(t, cache.New())
+	assert.Equal(t, Stats{Read: 2, Persisted: true}, stats)
+}
+
+func TestLoad_CredentialsNotStored(t *testing.T) {
+	site := &versionedSite{etag: `"v1"`, titles: map[string]string{"/one/": "One", "/two/": "Two"}}
+	server := httptest.NewServer(site)
+	defer server.Close()
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	store := NewStore(t.TempDir())
+	c := cache.New()
+
+	// An index read with per-call credentials is neither stored nor served
+	// from the cache to calls without them
+	ctx := httpclient.ContextWithCredentials(context.Background(), siteURL.Host, &httpclient.Credentials{BearerToken: "token"})
+	_, stats, err := Load(ctx, c, httpclient.New(), store, siteURL)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Read: 2}, stats)
+	_, err = os.Stat(store.path(server.URL))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, stats, err = Load(context.Background(), c, httpclient.New(), store, siteURL)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Read: 2, Persisted: true}, stats)
+}
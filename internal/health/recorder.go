@@ -0,0 +1,150 @@
+package health
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultWindow is how far back recent tool calls are counted by default
+const DefaultWindow = 15 * time.Minute
+
+// maxEvents bounds how many recent tool calls are remembered
+const maxEvents = 10000
+
+// event is the outcome of one tool call
+type event struct {
+	at       time.Time
+	tool     string
+	duration time.Duration
+	failed   bool
+}
+
+// ToolStats are the recent calls of one tool
+type ToolStats struct {
+	Calls     int     `json:"calls"`
+	Errors    int     `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+	AvgMillis int64   `json:"avg_duration_ms"`
+}
+
+// Stats summarize the tool calls within the window
+type Stats struct {
+	Window    string               `json:"window"`
+	Calls     int                  `json:"calls"`
+	Errors    int                  `json:"errors"`
+	ErrorRate float64              `json:"error_rate"`
+	Tools     map[string]ToolStats `json:"tools"`
+}
+
+// RecorderOption configures a Recorder
+type RecorderOption func(*Recorder)
+
+// Recorder remembers the outcome of recent tool calls and when the server
+// started
+type Recorder struct {
+	mu      sync.Mutex
+	started time.Time
+	window  time.Duration
+	events  []event
+	now     func() time.Time
+}
+
+// NewRecorder creates a Recorder, starting the uptime clock
+func NewRecorder(opts ...RecorderOption) *Recorder {
+	r := &Recorder{
+		window: DefaultWindow,
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.started = r.now()
+	return r
+}
+
+// WithWindow sets how far back recent tool calls are counted
+func WithWindow(window time.Duration) RecorderOption {
+	return func(r *Recorder) {
+		r.window = window
+	}
+}
+
+// Record remembers the outcome of a tool call
+func (r *Recorder) Record(tool string, duration time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event{at: r.now(), tool: tool, duration: duration, failed: err != nil})
+	r.prune()
+}
+
+// Uptime returns how long ago the Recorder was created
+func (r *Recorder) Uptime() time.Duration {
+	return r.now().Sub(r.started)
+}
+
+// Started returns when the Recorder was created
+func (r *Recorder) Started() time.Time {
+	return r.started
+}
+
+// Stats summarizes the tool calls within the window
+func (r *Recorder) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prune()
+
+	stats := Stats{Window: r.window.String(), Tools: make(map[string]ToolStats)}
+	durations := make(map[string]time.Duration)
+	for _, e := range r.events {
+		tool := stats.Tools[e.tool]
+		tool.Calls++
+		stats.Calls++
+		if e.failed {
+			tool.Errors++
+			stats.Errors++
+		}
+		durations[e.tool] += e.duration
+		stats.Tools[e.tool] = tool
+	}
+
+	names := make([]string, 0, len(stats.Tools))
+	for name := range stats.Tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tool := stats.Tools[name]
+		tool.ErrorRate = rate(tool.Errors, tool.Calls)
+		tool.AvgMillis = (durations[name] / time.Duration(tool.Calls)).Milliseconds()
+		stats.Tools[name] = tool
+	}
+	stats.ErrorRate = rate(stats.Errors, stats.Calls)
+	return stats
+}
+
+// prune drops calls older than the window, and the oldest calls beyond
+// maxEvents. Callers must hold r.mu.
+func (r *Recorder) prune() {
+	cutoff := r.now().Add(-r.window)
+	drop := 0
+	for drop < len(r.events) && r.events[drop].at.Before(cutoff) {
+		drop++
+	}
+	if excess := len(r.events) - drop - maxEvents; excess > 0 {
+		drop += excess
+	}
+	if drop > 0 {
+		r.events = append(r.events[:0], r.events[drop:]...)
+	}
+}
+
+// rate returns errors as a fraction of calls, rounded to three places
+func rate(errors, calls int) float64 {
+	if calls == 0 {
+		return 0
+	}
+	return math.Round(float64(errors)/float64(calls)*1000) / 1000
+}
@@ -0,0 +1,46 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := NewRecorder(WithWindow(10 * time.Minute))
+	r.now = func() time.Time { return now }
+	r.started = now
+
+	r.Record("hugo_reader_search", 100*time.Millisecond, nil)
+	r.Record("hugo_reader_search", 300*time.Millisecond, errors.New("search failed"))
+	r.Record("hugo_reader_probe", 50*time.Millisecond, nil)
+
+	now = now.Add(5 * time.Minute)
+	assert.Equal(t, 5*time.Minute, r.Uptime())
+
+	stats := r.Stats()
+	assert.Equal(t, "10m0s", stats.Window)
+	assert.Equal(t, 3, stats.Calls)
+	assert.Equal(t, 1, stats.Errors)
+	assert.Equal(t, 0.333, stats.ErrorRate)
+	assert.Equal(t, ToolStats{Calls: 2, Errors: 1, ErrorRate: 0.5, AvgMillis: 200}, stats.Tools["hugo_reader_search"])
+	assert.Equal(t, ToolStats{Calls: 1, AvgMillis: 50}, stats.Tools["hugo_reader_probe"])
+
+	// Calls older than the window are forgotten
+	now = now.Add(6 * time.Minute)
+	r.Record("hugo_reader_probe", 10*time.Millisecond, nil)
+	stats = r.Stats()
+	assert.Equal(t, 1, stats.Calls)
+	assert.Zero(t, stats.ErrorRate)
+	assert.NotContains(t, stats.Tools, "hugo_reader_search")
+}
+
+func TestRecorder_Empty(t *testing.T) {
+	stats := NewRecorder().Stats()
+	assert.Equal(t, DefaultWindow.String(), stats.Window)
+	assert.Zero(t, stats.Calls)
+	assert.Empty(t, stats.Tools)
+}
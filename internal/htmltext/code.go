@@ -0,0 +1,158 @@
+package htmltext
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CodeBlock is a block of preformatted code, with the heading of the
+// section it appears in
+type CodeBlock struct {
+	Language string
+	Code     string
+
+	// Heading is the nearest heading before the block, if any
+	Heading *Heading
+}
+
+// lineNumberClasses are the classes of the line numbers Chroma, Hugo's
+// highlighter, renders inline or in a table column beside the code
+var lineNumberClasses = map[string]bool{"ln": true, "lnt": true}
+
+// markdownFencePattern matches the opening line of a fenced code block,
+// capturing its fence and info string
+var markdownFencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})(.*)$")
+
+// CodeBlocks returns the code blocks of HTML in document order: the text
+// of each <pre> element, without Chroma's line numbers, and its language
+// as the Markdown converter finds it. As with Links, only the main content
+// of a full document is read. Fenced code blocks are returned when s is
+// not HTML. Blocks holding only whitespace are skipped.
+func CodeBlocks(s string) []CodeBlock {
+	if !LooksLikeHTML(s) {
+		return markdownCodeBlocks(s)
+	}
+
+	c := converter{}
+	var blocks []CodeBlock
+	var heading *Heading
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, child := range n.children {
+			if child.tag == "" || skippedTags[child.tag] {
+				continue
+			}
+			if level := anyHeadingLevel(child.tag); level > 0 {
+				if text := headingText(c.flatten(child)); text != "" {
+					heading = &Heading{Level: level, Text: text, Anchor: strings.TrimSpace(child.attr("id"))}
+				}
+				continue
+			}
+			if child.tag == "pre" {
+				if code := strings.Trim(codeText(child), "\n"); strings.TrimSpace(code) != "" {
+					blocks = append(blocks, CodeBlock{Language: codeLanguage(child), Code: code, Heading: heading})
+				}
+				continue
+			}
+			walk(child)
+		}
+	}
+	walk(mainContent(parse(s)))
+	return blocks
+}
+
+// codeText returns the text of n and its descendants unchanged, less the
+// line numbers highlighters add
+func codeText(n *node) string {
+	if n.tag == "" {
+		return n.text
+	}
+	for _, class := range strings.Fields(n.attr("class")) {
+		if lineNumberClasses[class] {
+			return ""
+		}
+	}
+	if n.tag == "br" {
+		return "\n"
+	}
+	var b strings.Builder
+	for _, child := range n.children {
+		b.WriteString(codeText(child))
+	}
+	return b.String()
+}
+
+// anyHeadingLevel returns the level of an h1 to h6 tag, or 0
+func anyHeadingLevel(tag string) int {
+	if len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6' {
+		return int(tag[1] - '0')
+	}
+	return 0
+}
+
+func markdownCodeBlocks(s string) []CodeBlock {
+	var blocks []CodeBlock
+	var heading *Heading
+
+	var fence, language string
+	var code []string
+	inFence := false
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if inFence {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				if text := strings.Join(code, "\n"); strings.TrimSpace(text) != "" {
+					blocks = append(blocks, CodeBlock{Language: language, Code: text, Heading: heading})
+				}
+				inFence = false
+				continue
+			}
+			code = append(code, line)
+			continue
+		}
+
+		if match := markdownFencePattern.FindStringSubmatch(line); match != nil && !(match[1][0] == '`' && strings.Contains(match[2], "`")) {
+			fence, language, code, inFence = match[1], fenceLanguage(match[2]), nil, true
+			continue
+		}
+		if match := markdownHeadingPattern.FindStringSubmatch(line); match != nil && strings.TrimSpace(match[2]) != "" {
+			text := strings.TrimSpace(match[2])
+			anchor := match[3]
+			if anchor == "" {
+				anchor = anchorize(text)
+			}
+			heading = &Heading{Level: len(match[1]), Text: text, Anchor: anchor}
+		}
+	}
+	// An unclosed fence runs to the end of the document
+	if inFence {
+		if text := strings.Join(code, "\n"); strings.TrimSpace(text) != "" {
+			blocks = append(blocks, CodeBlock{Language: language, Code: text, Heading: heading})
+		}
+	}
+	return blocks
+}
+
+// fenceLanguage returns the language of a fence's info string: its first
+// word, without the attributes Hugo accepts in braces, as in
+// "go {linenos=table}", or the class of an attribute-only info string,
+// as in "{.go}"
+func fenceLanguage(info string) string {
+	info = strings.TrimSpace(info)
+	if strings.HasPrefix(info, "{") {
+		for _, attr := range strings.Fields(strings.Trim(info, "{}")) {
+			if lang, ok := strings.CutPrefix(attr, "."); ok {
+				return lang
+			}
+		}
+		return ""
+	}
+	if i := strings.Index(info, "{"); i >= 0 {
+		info = info[:i]
+	}
+	if fields := strings.Fields(info); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package htmltext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeBlocks(t *testing.T) {
+	page := `<html><body><main>
+<pre><code>before any heading</code></pre>
+<h2 id="install">Install</h2>
+<div class="highlight"><pre tabindex="0" class="chroma"><code class="language-sh" data-lang="sh"><span class="line"><span class="ln">1</span><span class="cl">go install example.com/tool@latest
+</span></span></code></pre></div>
+<h3 id="config">Config</h3>
+<div class="highlight"><div class="chroma"><table class="lntable"><tr><td class="lntd"><pre tabindex="0" class="chroma"><code><span class="lnt">1
+</span><span class="lnt">2
+</span></code></pre></td><td class="lntd"><pre tabindex="0" class="chroma"><code class="language-yaml" data-lang="yaml"><span class="line"><span class="cl">a: 1
+</span></span><span class="line"><span class="cl">b: &lt;2&gt;
+</span></span></code></pre></td></tr></table></div></div>
+<pre>   </pre>
+</main></body></html>`
+
+	blocks := CodeBlocks(page)
+	assert.Equal(t, []CodeBlock{
+		{Code: "before any heading"},
+		{Language: "sh", Code: "go install example.com/tool@latest", Heading: &Heading{Level: 2, Text: "Install", Anchor: "install"}},
+		{Language: "yaml", Code: "a: 1\nb: <2>", Heading: &Heading{Level: 3, Text: "Config", Anchor: "config"}},
+	}, blocks)
+
+	markdown := "# Guide\n\n```go {linenos=table}\nfunc main() {}\n\n# not a heading\n```\n\n## Shell\n\n~~~\nls -l\n~~~\n\n````md\n```\nnested\n```\n````\n\n```{.py}\nprint(1)"
+	assert.Equal(t, []CodeBlock{
+		{Language: "go", Code: "func main() {}\n\n# not a heading", Heading: &Heading{Level: 1, Text: "Guide", Anchor: "guide"}},
+		{Code: "ls -l", Heading: &Heading{Level: 2, Text: "Shell", Anchor: "shell"}},
+		{Language: "md", Code: "```\nnested\n```", Heading: &Heading{Level: 2, Text: "Shell", Anchor: "shell"}},
+		{Language: "py", Code: "print(1)", Heading: &Heading{Level: 2, Text: "Shell", Anchor: "shell"}},
+	}, CodeBlocks(markdown))
+
+	assert.Empty(t, CodeBlocks("<p>No code</p>"))
+}
+
+func TestFenceLanguage(t *testing.T) {
+	for info, want := range map[string]string{
+		"":                     "",
+		"go":                   "go",
+		" js title=\"app.js\"": "js",
+		"go {linenos=table}":   "go",
+		"{.python hl_lines=2}": "python",
+		"{linenos=false}":      "",
+	} {
+		assert.Equal(t, want, fenceLanguage(info), info)
+	}
+}
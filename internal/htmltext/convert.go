@@ -0,0 +1,361 @@
+package htmltext
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// skippedTags are elements with no readable content
+var skippedTags = map[string]bool{
+	"head": true, "script": true, "style": true, "noscript": true,
+	"template": true, "svg": true, "iframe": true, "object": true,
+	"button": true, "input": true, "select": true, "textarea": true,
+}
+
+// blockTags are elements rendered as separate blocks
+var blockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"body": true, "dd": true, "details": true, "div": true, "dl": true,
+	"dt": true, "fieldset": true, "figcaption": true, "figure": true,
+	"footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"html": true, "li": true, "main": true, "nav": true, "ol": true,
+	"p": true, "pre": true, "section": true, "summary": true, "table": true,
+	"tbody": true, "td": true, "tfoot": true, "th": true, "thead": true,
+	"tr": true, "ul": true,
+}
+
+var (
+	spacePattern     = regexp.MustCompile(`[ \t\n\r\f\x{00a0}]+`)
+	blankLinePattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// Markdown converts HTML to Markdown
+func Markdown(html string) string {
+	return convert(html, true)
+}
+
+// Text converts HTML to plain text, keeping paragraph and list structure
+func Text(html string) string {
+	return convert(html, false)
+}
+
+func convert(html string, markdown bool) string {
+	c := converter{markdown: markdown}
+	out := strings.Join(c.blocks(parse(html)), "\n\n")
+	return strings.TrimSpace(blankLinePattern.ReplaceAllString(out, "\n\n"))
+}
+
+// converter renders a parsed tree as Markdown or plain text
+type converter struct {
+	markdown bool
+}
+
+// blocks renders the children of n as a list of blocks, gathering
+// consecutive inline content into paragraphs
+func (c *converter) blocks(n *node) []string {
+	var blocks []string
+	var run strings.Builder
+
+	flush := func() {
+		if text := cleanInline(run.String()); text != "" {
+			blocks = append(blocks, text)
+		}
+		run.Reset()
+	}
+
+	for _, child := range n.children {
+		if child.tag != "" && blockTags[child.tag] {
+			flush()
+			if block := c.block(child); block != "" {
+				blocks = append(blocks, block)
+			}
+			continue
+		}
+		run.WriteString(c.inline(child))
+	}
+	flush()
+	return blocks
+}
+
+// block renders a block element
+func (c *converter) block(n *node) string {
+	switch n.tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := c.flatten(n)
+		if text == "" || !c.markdown {
+			return text
+		}
+		return strings.Repeat("#", int(n.tag[1]-'0')) + " " + text
+	case "hr":
+		if c.markdown {
+			return "---"
+		}
+		return ""
+	case "pre":
+		return c.pre(n)
+	case "ul", "ol":
+		return c.list(n)
+	case "blockquote":
+		inner := strings.Join(c.blocks(n), "\n\n")
+		if !c.markdown || inner == "" {
+			return inner
+		}
+		return prefixLines(inner, "> ", ">")
+	case "table":
+		return c.table(n)
+	}
+	return strings.Join(c.blocks(n), "\n\n")
+}
+
+// inline renders an inline node
+func (c *converter) inline(n *node) string {
+	if n.tag == "" {
+		return spacePattern.ReplaceAllString(n.text, " ")
+	}
+	if skippedTags[n.tag] {
+		return ""
+	}
+	if blockTags[n.tag] {
+		// Block content inside inline elements, as in a link wrapping a div
+		return " " + c.flatten(n) + " "
+	}
+
+	switch n.tag {
+	case "br":
+		return "\n"
+	case "img":
+		alt := n.attr("alt")
+		if !c.markdown {
+			return alt
+		}
+		if n.attr("src") == "" {
+			return alt
+		}
+		return fmt.Sprintf("![%s](%s)", alt, n.attr("src"))
+	}
+
+	inner := c.inlineChildren(n)
+	if !c.markdown {
+		return inner
+	}
+
+	switch n.tag {
+	case "strong", "b":
+		return wrap(inner, "**")
+	case "em", "i", "cite":
+		return wrap(inner, "_")
+	case "del", "s", "strike":
+		return wrap(inner, "~~")
+	case "code", "kbd", "samp":
+		return wrap(inner, "`")
+	case "a":
+		href := n.attr("href")
+		text := strings.TrimSpace(inner)
+		if href == "" || strings.HasPrefix(href, "javascript:") {
+			return inner
+		}
+		if text == "" {
+			// Icon links carry no text
+			return ""
+		}
+		if strings.HasPrefix(href, "#") {
+			// In-page links mean nothing outside the page, and heading
+			// anchors only carry a symbol
+			if strings.Trim(text, "#¶§🔗 ") == "" {
+				return ""
+			}
+			return inner
+		}
+		return fmt.Sprintf("[%s](%s)", text, href)
+	}
+	return inner
+}
+
+func (c *converter) inlineChildren(n *node) string {
+	var b strings.Builder
+	for _, child := range n.children {
+		b.WriteString(c.inline(child))
+	}
+	return b.String()
+}
+
+// flatten renders all content of n on a single line
+func (c *converter) flatten(n *node) string {
+	var parts []string
+	for _, block := range c.blocks(n) {
+		parts = append(parts, strings.Join(strings.Fields(block), " "))
+	}
+	return strings.Join(parts, " ")
+}
+
+// pre renders preformatted text, as a fenced code block in Markdown
+func (c *converter) pre(n *node) string {
+	code := strings.Trim(rawText(n), "\n")
+	if code == "" || !c.markdown {
+		return code
+	}
+
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + codeLanguage(n) + "\n" + code + "\n" + fence
+}
+
+// list renders a list, indenting nested content under each item
+func (c *converter) list(n *node) string {
+	var items []string
+	number := 1
+	if n.tag == "ol" {
+		fmt.Sscanf(n.attr("start"), "%d", &number)
+	}
+
+	for _, child := range n.children {
+		if child.tag != "li" {
+			continue
+		}
+		marker := "- "
+		if n.tag == "ol" {
+			marker = fmt.Sprintf("%d. ", number)
+			number++
+		}
+
+		content := strings.Join(c.blocks(child), "\n")
+		if content == "" {
+			continue
+		}
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, marker+prefixLines(content, indent, "")[len(indent):])
+	}
+	return strings.Join(items, "\n")
+}
+
+// table renders a table as Markdown pipe table rows, or tab-separated
+// text
+func (c *converter) table(n *node) string {
+	var rows [][]string
+	header := false
+
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, child := range n.children {
+			switch child.tag {
+			case "tr":
+				var row []string
+				for _, cell := range child.children {
+					if cell.tag == "td" || cell.tag == "th" {
+						row = append(row, strings.ReplaceAll(c.flatten(cell), "|", `\|`))
+						if cell.tag == "th" && len(rows) == 0 {
+							header = true
+						}
+					}
+				}
+				if len(row) > 0 {
+					rows = append(rows, row)
+				}
+			case "thead", "tbody", "tfoot":
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+
+	if len(rows) == 0 {
+		return ""
+	}
+
+	var lines []string
+	for i, row := range rows {
+		if !c.markdown {
+			lines = append(lines, strings.Join(row, "\t"))
+			continue
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			separator := make([]string, len(row))
+			for j := range separator {
+				separator[j] = "---"
+			}
+			if !header {
+				// Markdown tables need a header row, so give an empty one
+				lines = append([]string{"| " + strings.Repeat(" | ", len(row)-1) + " |"}, "| "+strings.Join(separator, " | ")+" |", lines[0])
+				continue
+			}
+			lines = append(lines, "| "+strings.Join(separator, " | ")+" |")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// rawText returns the text of n and its descendants unchanged
+func rawText(n *node) string {
+	if n.tag == "" {
+		return n.text
+	}
+	if n.tag == "br" {
+		return "\n"
+	}
+	var b strings.Builder
+	for _, child := range n.children {
+		b.WriteString(rawText(child))
+	}
+	return b.String()
+}
+
+// codeLanguage finds a language-* class, or Hugo's data-lang attribute, on
+// a pre element or the code element inside it
+func codeLanguage(n *node) string {
+	candidates := []*node{n}
+	for _, child := range n.children {
+		if child.tag == "code" {
+			candidates = append(candidates, child)
+		}
+	}
+	for _, candidate := range candidates {
+		if lang := candidate.attr("data-lang"); lang != "" {
+			return lang
+		}
+		for _, class := range strings.Fields(candidate.attr("class")) {
+			if lang, ok := strings.CutPrefix(class, "language-"); ok {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+// cleanInline tidies a run of inline content into a paragraph
+func cleanInline(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spacePattern.ReplaceAllString(line, " "))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// wrap surrounds inline content with a marker, keeping surrounding
+// whitespace outside it
+func wrap(s, marker string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	lead := s[:strings.Index(s, trimmed)]
+	trail := s[len(lead)+len(trimmed):]
+	return lead + marker + trimmed + marker + trail
+}
+
+// prefixLines prefixes each line of s, using blank for empty lines
+func prefixLines(s, prefix, blank string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = blank
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
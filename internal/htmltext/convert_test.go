@@ -0,0 +1,92 @@
+package htmltext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "paragraphs and emphasis",
+			html:     "<p>Hello <strong>bold</strong> and <em> italic </em> text.</p>\n<p>Second&nbsp;paragraph &amp; more</p>",
+			expected: "Hello **bold** and _italic_ text.\n\nSecond paragraph & more",
+		},
+		{
+			name:     "headings with anchors",
+			html:     `<h2 id="intro">Intro <a class="anchor" href="#intro">#</a></h2><p>Text</p>`,
+			expected: "## Intro\n\nText",
+		},
+		{
+			name:     "links and images",
+			html:     `<p>See <a href="https://gohugo.io/">Hugo</a>.<img src="/a.png" alt="A diagram"></p>`,
+			expected: "See [Hugo](https://gohugo.io/).![A diagram](/a.png)",
+		},
+		{
+			name:     "nested lists",
+			html:     "<ul>\n<li>One\n<ul><li>Nested</li></ul></li>\n<li>Two</ul><ol start=\"3\"><li>Three<li>Four</ol>",
+			expected: "- One\n  - Nested\n- Two\n\n3. Three\n4. Four",
+		},
+		{
+			name: "highlighted code block",
+			html: `<div class="highlight"><pre tabindex="0" class="chroma"><code class="language-go" data-lang="go"><span class="line"><span class="cl"><span class="kd">func</span> <span class="nf">main</span>() {
+</span></span><span class="line"><span class="cl">	<span class="k">return</span>
+</span></span><span class="line"><span class="cl">}</span></span></code></pre></div>`,
+			expected: "```go\nfunc main() {\n\treturn\n}\n```",
+		},
+		{
+			name:     "inline code and line breaks",
+			html:     "<p>Run <code>hugo server</code><br>\nthen browse</p>",
+			expected: "Run `hugo server`\nthen browse",
+		},
+		{
+			name:     "blockquote",
+			html:     "<blockquote><p>Quoted</p><p>Twice</p></blockquote>",
+			expected: "> Quoted\n>\n> Twice",
+		},
+		{
+			name:     "table",
+			html:     "<table><thead><tr><th>Name<th>Value</thead><tbody><tr><td>a|b<td>1</tbody></table>",
+			expected: "| Name | Value |\n| --- | --- |\n| a\\|b | 1 |",
+		},
+		{
+			name:     "scripts, styles and comments dropped",
+			html:     "<style>p { color: red }</style><!-- note --><p>Visible</p><script>alert('<p>x</p>')</script>",
+			expected: "Visible",
+		},
+		{
+			name:     "unclosed paragraphs",
+			html:     "<p>One<p>Two<div>Three</div>",
+			expected: "One\n\nTwo\n\nThree",
+		},
+		{
+			name:     "plain text",
+			html:     "Just text",
+			expected: "Just text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Markdown(tt.html))
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	html := `<h1>Title</h1><p>Read <a href="/docs/">the <b>docs</b></a>.</p><ul><li>One</li><li>Two</li></ul><pre><code>x := 1</code></pre><hr><table><tr><td>a</td><td>b</td></tr></table>`
+	assert.Equal(t, "Title\n\nRead the docs.\n\n- One\n- Two\n\nx := 1\n\na\tb", Text(html))
+}
+
+func TestLooksLikeHTML(t *testing.T) {
+	assert.True(t, LooksLikeHTML("<p>Hello</p>"))
+	assert.True(t, LooksLikeHTML(`Text with <a href="/x/">a link</a>`))
+	assert.True(t, LooksLikeHTML("Line<br/>break"))
+	assert.False(t, LooksLikeHTML("# Markdown heading\n\nSome *text*"))
+	assert.False(t, LooksLikeHTML("a < b and c > d"))
+}
@@ -0,0 +1,62 @@
+package htmltext
+
+import "strings"
+
+// Head is the metadata of an HTML document
+type Head struct {
+	Lang       string
+	Title      string
+	Generator  string
+	Alternates []Alternate
+}
+
+// Alternate is a <link> to another representation of a document, such as
+// a translation, a feed or an OpenSearch description
+type Alternate struct {
+	Rel      string
+	Href     string
+	Hreflang string
+	Type     string
+	Title    string
+}
+
+// ParseHead returns the language, title, generator and alternate links of
+// an HTML document. Links are read from anywhere in the document, as
+// browsers do.
+func ParseHead(s string) Head {
+	root := parse(s)
+
+	var head Head
+	if html := find(root, "html"); html != nil {
+		head.Lang = strings.TrimSpace(html.attr("lang"))
+	}
+	if title := find(root, "title"); title != nil {
+		head.Title = strings.TrimSpace(rawText(title))
+	}
+
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, child := range n.children {
+			switch child.tag {
+			case "meta":
+				if strings.EqualFold(child.attr("name"), "generator") && head.Generator == "" {
+					head.Generator = strings.TrimSpace(child.attr("content"))
+				}
+			case "link":
+				rel := strings.ToLower(strings.Join(strings.Fields(child.attr("rel")), " "))
+				if rel == "alternate" || rel == "search" {
+					head.Alternates = append(head.Alternates, Alternate{
+						Rel:      rel,
+						Href:     strings.TrimSpace(child.attr("href")),
+						Hreflang: strings.TrimSpace(child.attr("hreflang")),
+						Type:     strings.ToLower(strings.TrimSpace(child.attr("type"))),
+						Title:    strings.TrimSpace(child.attr("title")),
+					})
+				}
+			}
+			walk(child)
+		}
+	}
+	walk(root)
+	return head
+}
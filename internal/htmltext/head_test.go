@@ -0,0 +1,33 @@
+package htmltext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHead(t *testing.T) {
+	page := `<!DOCTYPE html>
+<html lang="en-us"><head>
+<title>My &amp; Site</title>
+<meta name="Generator" content="Hugo 0.121.1">
+<link rel="stylesheet" href="/style.css">
+<link rel="alternate" type="application/rss+xml" href="/index.xml" title="My Site">
+<link rel="alternate" hreflang="de" href="https://example.com/de/">
+<link rel="search" type="application/opensearchdescription+xml" href="/opensearch.xml">
+</head><body><link rel="Alternate" hreflang="x-default" href="/"></body></html>`
+
+	assert.Equal(t, Head{
+		Lang:      "en-us",
+		Title:     "My & Site",
+		Generator: "Hugo 0.121.1",
+		Alternates: []Alternate{
+			{Rel: "alternate", Href: "/index.xml", Type: "application/rss+xml", Title: "My Site"},
+			{Rel: "alternate", Href: "https://example.com/de/", Hreflang: "de"},
+			{Rel: "search", Href: "/opensearch.xml", Type: "application/opensearchdescription+xml"},
+			{Rel: "alternate", Href: "/", Hreflang: "x-default"},
+		},
+	}, ParseHead(page))
+
+	assert.Equal(t, Head{}, ParseHead("plain text"))
+}
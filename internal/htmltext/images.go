@@ -0,0 +1,76 @@
+package htmltext
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Image is an image embedded in content
+type Image struct {
+	Src     string
+	Alt     string
+	Title   string
+	Width   string
+	Height  string
+	Caption string
+}
+
+// markdownImagePattern matches inline Markdown images
+var markdownImagePattern = regexp.MustCompile(`!\[([^\]]*)\]\(\s*<?([^)\s>]+)>?(?:\s+"([^"]*)")?\s*\)`)
+
+// Images returns the images in HTML, in document order, with the caption
+// of the figure each is in. As with Links, only the main content of a full
+// document is read. Markdown inline images are returned when s is not HTML.
+func Images(s string) []Image {
+	if !LooksLikeHTML(s) {
+		var images []Image
+		for _, match := range markdownImagePattern.FindAllStringSubmatch(s, -1) {
+			images = append(images, Image{Src: match[2], Alt: strings.TrimSpace(match[1]), Title: match[3]})
+		}
+		return images
+	}
+
+	c := converter{}
+	var images []Image
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, child := range n.children {
+			if child.tag == "" || skippedTags[child.tag] {
+				continue
+			}
+			if child.tag == "img" {
+				src := child.attr("src")
+				if src == "" {
+					// Lazy loading scripts commonly move the source aside
+					src = child.attr("data-src")
+				}
+				if src != "" {
+					images = append(images, Image{
+						Src:     strings.TrimSpace(src),
+						Alt:     child.attr("alt"),
+						Title:   child.attr("title"),
+						Width:   child.attr("width"),
+						Height:  child.attr("height"),
+						Caption: figureCaption(c, child),
+					})
+				}
+			}
+			walk(child)
+		}
+	}
+	walk(mainContent(parse(s)))
+	return images
+}
+
+// figureCaption returns the caption of the figure containing n, if any
+func figureCaption(c converter, n *node) string {
+	for parent := n.parent; parent != nil; parent = parent.parent {
+		if parent.tag == "figure" {
+			if caption := find(parent, "figcaption"); caption != nil {
+				return c.flatten(caption)
+			}
+			return ""
+		}
+	}
+	return ""
+}
@@ -0,0 +1,25 @@
+package htmltext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImages(t *testing.T) {
+	page := `<html><body><header><img src="/logo.png" alt="Logo"></header>
+<article><p><img src=" /a.png " alt="A diagram" width="640" height="480"></p>
+<figure><img data-src="b.jpg" title="Lazy"><figcaption>The <em>second</em> figure</figcaption></figure>
+<img alt="No source"></article></body></html>`
+
+	assert.Equal(t, []Image{
+		{Src: "/a.png", Alt: "A diagram", Width: "640", Height: "480"},
+		{Src: "b.jpg", Title: "Lazy", Caption: "The second figure"},
+	}, Images(page))
+
+	markdown := "Intro ![A chart](chart.png \"Chart\") and [a link](/x/) then ![](/y.gif)."
+	assert.Equal(t, []Image{
+		{Src: "chart.png", Alt: "A chart", Title: "Chart"},
+		{Src: "/y.gif"},
+	}, Images(markdown))
+}
@@ -0,0 +1,97 @@
+package htmltext
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Link is a hyperlink and its anchor text
+type Link struct {
+	Href string
+	Text string
+}
+
+// markdownLinkPattern matches inline Markdown links and images
+var markdownLinkPattern = regexp.MustCompile(`!?\[([^\]]*)\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+
+// Links returns the links in HTML, in document order. For a full document,
+// only links in the main content are returned: those in the first <main>
+// element, else the first <article>, so navigation and footers are skipped.
+// Markdown inline links are returned when s is not HTML.
+func Links(s string) []Link {
+	if !LooksLikeHTML(s) {
+		return markdownLinks(s)
+	}
+
+	root := mainContent(parse(s))
+
+	c := converter{}
+	var links []Link
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, child := range n.children {
+			if child.tag == "" || skippedTags[child.tag] {
+				continue
+			}
+			if href, ok := child.attrs["href"]; ok && child.tag == "a" {
+				links = append(links, Link{Href: strings.TrimSpace(href), Text: linkText(c, child)})
+			}
+			walk(child)
+		}
+	}
+	walk(root)
+	return links
+}
+
+// linkText returns the text of a link, or the alt text of an image link
+func linkText(c converter, n *node) string {
+	text := c.flatten(n)
+	if text == "" {
+		if img := find(n, "img"); img != nil {
+			text = img.attr("alt")
+		}
+	}
+	if text == "" {
+		text = n.attr("title")
+	}
+	if text == "" {
+		text = n.attr("aria-label")
+	}
+	return text
+}
+
+// mainContent returns the first <main> element below root, else the first
+// <article>, else root itself
+func mainContent(root *node) *node {
+	if main := find(root, "main"); main != nil {
+		return main
+	}
+	if article := find(root, "article"); article != nil {
+		return article
+	}
+	return root
+}
+
+// find returns the first element named tag below n
+func find(n *node, tag string) *node {
+	for _, child := range n.children {
+		if child.tag == tag {
+			return child
+		}
+		if found := find(child, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func markdownLinks(s string) []Link {
+	var links []Link
+	for _, match := range markdownLinkPattern.FindAllStringSubmatch(s, -1) {
+		if strings.HasPrefix(match[0], "!") {
+			continue
+		}
+		links = append(links, Link{Href: match[2], Text: strings.TrimSpace(match[1])})
+	}
+	return links
+}
@@ -0,0 +1,28 @@
+package htmltext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinks(t *testing.T) {
+	page := `<html><body><nav><a href="/">Home</a></nav>
+<main><p>Read <a href="/posts/other/">the <em>other</em> post</a> and <a href="https://gohugo.io/">Hugo</a>.</p>
+<a href="#intro"><img src="/i.png" alt="Intro"></a><a href="mailto:me@example.com" title="Email"></a><a name="no-href">x</a></main>
+<footer><a href="/privacy/">Privacy</a></footer></body></html>`
+
+	assert.Equal(t, []Link{
+		{Href: "/posts/other/", Text: "the other post"},
+		{Href: "https://gohugo.io/", Text: "Hugo"},
+		{Href: "#intro", Text: "Intro"},
+		{Href: "mailto:me@example.com", Text: "Email"},
+	}, Links(page))
+
+	markdown := "See [docs](/docs/ \"Docs\") and ![img](/a.png), then [next](../next/)[!](x)."
+	assert.Equal(t, []Link{
+		{Href: "/docs/", Text: "docs"},
+		{Href: "../next/", Text: "next"},
+		{Href: "x", Text: "!"},
+	}, Links(markdown))
+}
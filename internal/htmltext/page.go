@@ -0,0 +1,116 @@
+package htmltext
+
+import "strings"
+
+// Page is what a rendered page says about itself in its markup, for sites
+// that publish no JSON output of their pages
+type Page struct {
+	Lang        string
+	Title       string
+	Description string
+	Date        string
+	Lastmod     string
+	Section     string
+	Tags        []string
+
+	// Content is the HTML of the page's main content
+	Content string
+}
+
+// ParsePage returns the metadata and main content of an HTML document.
+// The title is read from og:title, else <title>; dates and section from
+// Open Graph article properties, the date else from the first <time>
+// element of the main content; tags from article:tag properties, else
+// the keywords meta tag. The main content is the first <main> element,
+// else the first <article>, else the body.
+func ParsePage(s string) Page {
+	root := parse(s)
+	head := ParseHead(s)
+	page := Page{Lang: head.Lang, Title: head.Title}
+
+	var keywords string
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, child := range n.children {
+			if child.tag == "meta" {
+				name := strings.ToLower(child.attr("property"))
+				if name == "" {
+					name = strings.ToLower(child.attr("name"))
+				}
+				content := strings.TrimSpace(child.attr("content"))
+				switch {
+				case content == "":
+				case name == "og:title":
+					page.Title = content
+				case name == "description" && page.Description == "", name == "og:description" && page.Description == "":
+					page.Description = content
+				case name == "article:published_time" && page.Date == "":
+					page.Date = content
+				case name == "article:modified_time" && page.Lastmod == "":
+					page.Lastmod = content
+				case name == "article:section" && page.Section == "":
+					page.Section = content
+				case name == "article:tag":
+					page.Tags = append(page.Tags, content)
+				case name == "keywords" && keywords == "":
+					keywords = content
+				}
+			}
+			walk(child)
+		}
+	}
+	walk(root)
+
+	if len(page.Tags) == 0 && keywords != "" {
+		for _, keyword := range strings.Split(keywords, ",") {
+			if keyword = strings.TrimSpace(keyword); keyword != "" {
+				page.Tags = append(page.Tags, keyword)
+			}
+		}
+	}
+	if page.Date == "" {
+		if t := find(mainContent(root), "time"); t != nil {
+			page.Date = strings.TrimSpace(t.attr("datetime"))
+		}
+	}
+	page.Content = strings.TrimSpace(mainSource(s))
+	return page
+}
+
+// mainSource returns the markup inside the first <main> element of s, else
+// the first <article>, else <body>, else s itself
+func mainSource(s string) string {
+	lower := strings.ToLower(s)
+	for _, tag := range []string{"main", "article", "body"} {
+		start := openingTag(lower, tag)
+		if start < 0 {
+			continue
+		}
+		end := strings.Index(lower[start:], "</"+tag)
+		if end < 0 {
+			return s[start:]
+		}
+		return s[start : start+end]
+	}
+	return s
+}
+
+// openingTag returns the offset just past the first start tag named tag in
+// the lowercased document s, or -1
+func openingTag(s, tag string) int {
+	for offset := 0; ; {
+		i := strings.Index(s[offset:], "<"+tag)
+		if i < 0 {
+			return -1
+		}
+		i += offset
+		after := i + len(tag) + 1
+		if after < len(s) && (s[after] == '>' || isSpace(s[after])) {
+			if end := strings.IndexByte(s[after:], '>'); end >= 0 {
+				return after + end + 1
+			}
+			return -1
+		}
+		offset = after
+	}
+}
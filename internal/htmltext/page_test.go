@@ -0,0 +1,47 @@
+package htmltext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePage(t *testing.T) {
+	page := `<!DOCTYPE html>
+<html lang="en"><head>
+<title>Learning Go | My Blog</title>
+<meta property="og:title" content="Learning Go">
+<meta name="description" content="Notes from a first month of Go.">
+<meta property="article:published_time" content="2024-03-01T09:00:00Z">
+<meta property="article:section" content="posts">
+<meta property="article:tag" content="go">
+<meta property="article:tag" content="learning">
+<meta name="keywords" content="ignored">
+</head><body><nav><a href="/">Home</a></nav>
+<main class="content"><h1>Learning Go</h1><p>Go is <em>simple</em>.</p></main>
+<footer>Footer</footer></body></html>`
+
+	assert.Equal(t, Page{
+		Lang:        "en",
+		Title:       "Learning Go",
+		Description: "Notes from a first month of Go.",
+		Date:        "2024-03-01T09:00:00Z",
+		Section:     "posts",
+		Tags:        []string{"go", "learning"},
+		Content:     "<h1>Learning Go</h1><p>Go is <em>simple</em>.</p>",
+	}, ParsePage(page))
+}
+
+func TestParsePage_Fallbacks(t *testing.T) {
+	page := `<html><head><title>About</title><meta name="keywords" content="about, me"></head>
+<body><article><time datetime="2023-05-01">May 1</time><p>Hello.</p></article><aside>Related</aside></body></html>`
+
+	parsed := ParsePage(page)
+	assert.Equal(t, "About", parsed.Title)
+	assert.Equal(t, []string{"about", "me"}, parsed.Tags)
+	assert.Equal(t, "2023-05-01", parsed.Date)
+	assert.Equal(t, `<time datetime="2023-05-01">May 1</time><p>Hello.</p>`, parsed.Content)
+
+	assert.Equal(t, "<p>Body only</p>", ParsePage(`<body class="x"><p>Body only</p></body>`).Content)
+	assert.Equal(t, "<p>Fragment</p>", ParsePage(`<p>Fragment</p>`).Content)
+}
@@ -0,0 +1,288 @@
+package htmltext
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// node is an element or, when tag is empty, a text node. The text of a
+// script element is its source.
+type node struct {
+	tag      string
+	attrs    map[string]string
+	text     string
+	children []*node
+	parent   *node
+}
+
+// attr returns the value of an attribute, or ""
+func (n *node) attr(name string) string {
+	return n.attrs[name]
+}
+
+// voidTags are elements that never have content or a closing tag
+var voidTags = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// rawTextTags are elements whose content is not markup
+var rawTextTags = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+}
+
+// closesParagraph are elements whose start tag ends an open paragraph
+var closesParagraph = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"details": true, "div": true, "dl": true, "fieldset": true, "figure": true,
+	"footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"main": true, "nav": true, "ol": true, "p": true, "pre": true,
+	"section": true, "table": true, "ul": true,
+}
+
+// tagPattern matches a start or end tag
+var tagPattern = regexp.MustCompile(`</?[a-zA-Z][a-zA-Z0-9-]*(\s[^<>]*)?/?>`)
+
+// LooksLikeHTML reports whether s contains HTML markup
+func LooksLikeHTML(s string) bool {
+	return tagPattern.MatchString(s)
+}
+
+// parser builds a tree from HTML, recovering from markup errors the way
+// browsers commonly do rather than rejecting it
+type parser struct {
+	src   string
+	pos   int
+	root  *node
+	stack []*node
+}
+
+// parse parses an HTML fragment or document
+func parse(src string) *node {
+	p := &parser{src: src, root: &node{tag: "#root"}}
+	p.stack = []*node{p.root}
+
+	for p.pos < len(p.src) {
+		rest := p.src[p.pos:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			p.skipPast("-->")
+		case strings.HasPrefix(rest, "</") && len(rest) > 2 && isLetter(rest[2]):
+			p.endTag()
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			p.skipPast(">")
+		case rest[0] == '<' && len(rest) > 1 && isLetter(rest[1]):
+			p.startTag()
+		default:
+			end := strings.IndexByte(rest[1:], '<')
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end++
+			}
+			p.addText(rest[:end])
+			p.pos += end
+		}
+	}
+	return p.root
+}
+
+func (p *parser) current() *node {
+	return p.stack[len(p.stack)-1]
+}
+
+func (p *parser) skipPast(marker string) {
+	if i := strings.Index(p.src[p.pos:], marker); i >= 0 {
+		p.pos += i + len(marker)
+		return
+	}
+	p.pos = len(p.src)
+}
+
+func (p *parser) addText(text string) {
+	if text == "" {
+		return
+	}
+	parent := p.current()
+	parent.children = append(parent.children, &node{text: html.UnescapeString(text), parent: parent})
+}
+
+func (p *parser) readName() string {
+	start := p.pos
+	for p.pos < len(p.src) && (isLetter(p.src[p.pos]) || isDigit(p.src[p.pos]) || p.src[p.pos] == '-' || p.src[p.pos] == ':') {
+		p.pos++
+	}
+	return strings.ToLower(p.src[start:p.pos])
+}
+
+func (p *parser) endTag() {
+	p.pos += 2
+	name := p.readName()
+	p.skipPast(">")
+
+	for i := len(p.stack) - 1; i > 0; i-- {
+		if p.stack[i].tag == name {
+			p.stack = p.stack[:i]
+			return
+		}
+	}
+}
+
+func (p *parser) startTag() {
+	p.pos++
+	name := p.readName()
+	attrs, selfClosing := p.readAttrs()
+
+	p.autoClose(name)
+
+	parent := p.current()
+	n := &node{tag: name, attrs: attrs, parent: parent}
+	parent.children = append(parent.children, n)
+
+	if rawTextTags[name] {
+		rest := p.src[p.pos:]
+		end := strings.Index(strings.ToLower(rest), "</"+name)
+		if end < 0 {
+			end = len(rest)
+		}
+		if name == "textarea" || name == "title" {
+			n.children = append(n.children, &node{text: html.UnescapeString(rest[:end]), parent: n})
+		} else if name == "script" {
+			// Scripts keep their source, unescaped, for data blocks such as
+			// JSON-LD; it is not a child, so it is never rendered as text
+			n.text = rest[:end]
+		}
+		p.pos += end
+		if p.pos < len(p.src) {
+			p.skipPast(">")
+		}
+		return
+	}
+
+	if !selfClosing && !voidTags[name] {
+		p.stack = append(p.stack, n)
+	}
+}
+
+// readAttrs reads attributes up to the end of a start tag
+func (p *parser) readAttrs() (map[string]string, bool) {
+	attrs := map[string]string{}
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '>':
+			p.pos++
+			return attrs, false
+		case c == '/' && strings.HasPrefix(p.src[p.pos:], "/>"):
+			p.pos += 2
+			return attrs, true
+		case isSpace(c) || c == '/':
+			p.pos++
+			continue
+		}
+
+		start := p.pos
+		for p.pos < len(p.src) && !isSpace(p.src[p.pos]) && !strings.ContainsRune("=>/", rune(p.src[p.pos])) {
+			p.pos++
+		}
+		if p.pos == start {
+			p.pos++
+			continue
+		}
+		name := strings.ToLower(p.src[start:p.pos])
+
+		p.skipSpace()
+		value := ""
+		if p.pos < len(p.src) && p.src[p.pos] == '=' {
+			p.pos++
+			p.skipSpace()
+			value = p.readValue()
+		}
+		if _, exists := attrs[name]; !exists {
+			attrs[name] = html.UnescapeString(value)
+		}
+	}
+	return attrs, false
+}
+
+func (p *parser) readValue() string {
+	if p.pos >= len(p.src) {
+		return ""
+	}
+	if quote := p.src[p.pos]; quote == '"' || quote == '\'' {
+		end := strings.IndexByte(p.src[p.pos+1:], quote)
+		if end < 0 {
+			value := p.src[p.pos+1:]
+			p.pos = len(p.src)
+			return value
+		}
+		value := p.src[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return value
+	}
+	start := p.pos
+	for p.pos < len(p.src) && !isSpace(p.src[p.pos]) && p.src[p.pos] != '>' {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && isSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// autoClose closes elements that a start tag implicitly ends, such as an
+// open list item when the next one starts
+func (p *parser) autoClose(name string) {
+	switch name {
+	case "li":
+		p.closeOpen([]string{"li"}, "ul", "ol")
+	case "dt", "dd":
+		p.closeOpen([]string{"dt", "dd"}, "dl")
+	case "tr":
+		p.closeOpen([]string{"tr"}, "table", "thead", "tbody", "tfoot")
+	case "td", "th":
+		p.closeOpen([]string{"td", "th"}, "tr", "table")
+	case "thead", "tbody", "tfoot":
+		p.closeOpen([]string{"thead", "tbody", "tfoot"}, "table")
+	}
+	if closesParagraph[name] {
+		p.closeOpen([]string{"p"}, "div", "li", "td", "th", "blockquote", "section", "article")
+	}
+}
+
+// closeOpen closes the innermost open element named in names, unless one
+// of the boundary elements is found first
+func (p *parser) closeOpen(names []string, boundaries ...string) {
+	for i := len(p.stack) - 1; i > 0; i-- {
+		tag := p.stack[i].tag
+		for _, name := range names {
+			if tag == name {
+				p.stack = p.stack[:i]
+				return
+			}
+		}
+		for _, boundary := range boundaries {
+			if tag == boundary {
+				return
+			}
+		}
+	}
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,172 @@
+package htmltext
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// StructuredData is the machine-readable metadata a document embeds for
+// search engines and social networks
+type StructuredData struct {
+	// JSONLD holds the JSON-LD blocks that parse, and InvalidJSONLD the
+	// source of those that don't
+	JSONLD        []json.RawMessage
+	InvalidJSONLD []string
+
+	// OpenGraph holds the og:, article:, book:, profile:, music: and video:
+	// properties, and Twitter the twitter: card properties, by name in
+	// document order. Properties may repeat, such as og:image or article:tag.
+	OpenGraph map[string][]string
+	Twitter   map[string][]string
+
+	// Microdata holds the top-level items of the document
+	Microdata []MicrodataItem
+}
+
+// MicrodataItem is an element with itemscope and the properties set by the
+// elements within it. Property values are strings, or MicrodataItem for
+// nested items.
+type MicrodataItem struct {
+	Type       []string
+	ID         string
+	Properties map[string][]interface{}
+}
+
+// openGraphPrefixes are the prefixes of the properties of the Open Graph
+// protocol and its object types
+var openGraphPrefixes = []string{"og:", "article:", "book:", "profile:", "music:", "video:"}
+
+// ParseStructuredData returns the JSON-LD blocks, Open Graph and Twitter
+// card properties, and microdata items of an HTML document
+func ParseStructuredData(s string) StructuredData {
+	data := StructuredData{OpenGraph: map[string][]string{}, Twitter: map[string][]string{}}
+	c := converter{}
+
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, child := range n.children {
+			switch child.tag {
+			case "":
+				continue
+			case "script":
+				mediaType, _, _ := strings.Cut(child.attr("type"), ";")
+				if strings.EqualFold(strings.TrimSpace(mediaType), "application/ld+json") {
+					addJSONLD(&data, child.text)
+				}
+				continue
+			case "meta":
+				name := strings.TrimSpace(child.attr("property"))
+				if name == "" {
+					name = strings.TrimSpace(child.attr("name"))
+				}
+				name = strings.ToLower(name)
+				content := strings.TrimSpace(child.attr("content"))
+				switch {
+				case content == "":
+				case strings.HasPrefix(name, "twitter:"):
+					data.Twitter[name] = append(data.Twitter[name], content)
+				case hasOpenGraphPrefix(name):
+					data.OpenGraph[name] = append(data.OpenGraph[name], content)
+				}
+			}
+			if _, ok := child.attrs["itemscope"]; ok && child.attr("itemprop") == "" {
+				data.Microdata = append(data.Microdata, microdataItem(c, child))
+				continue
+			}
+			walk(child)
+		}
+	}
+	walk(parse(s))
+	return data
+}
+
+// addJSONLD adds the source of a JSON-LD script to data, without the
+// comment or CDATA markers some templates wrap it in
+func addJSONLD(data *StructuredData, source string) {
+	source = strings.TrimSpace(source)
+	for _, marker := range [][2]string{{"<!--", "-->"}, {"<![CDATA[", "]]>"}, {"//<![CDATA[", "//]]>"}} {
+		if strings.HasPrefix(source, marker[0]) && strings.HasSuffix(source, marker[1]) {
+			source = strings.TrimSpace(source[len(marker[0]) : len(source)-len(marker[1])])
+		}
+	}
+	if source == "" {
+		return
+	}
+	if json.Valid([]byte(source)) {
+		data.JSONLD = append(data.JSONLD, json.RawMessage(source))
+	} else {
+		data.InvalidJSONLD = append(data.InvalidJSONLD, source)
+	}
+}
+
+func hasOpenGraphPrefix(name string) bool {
+	for _, prefix := range openGraphPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// microdataItem returns the item of an element with itemscope, with the
+// properties of the elements within it down to those starting items of
+// their own
+func microdataItem(c converter, n *node) MicrodataItem {
+	item := MicrodataItem{
+		Type:       strings.Fields(n.attr("itemtype")),
+		ID:         strings.TrimSpace(n.attr("itemid")),
+		Properties: map[string][]interface{}{},
+	}
+
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, child := range n.children {
+			if child.tag == "" {
+				continue
+			}
+			_, scoped := child.attrs["itemscope"]
+			if names := strings.Fields(child.attr("itemprop")); len(names) > 0 {
+				var value interface{}
+				if scoped {
+					value = microdataItem(c, child)
+				} else {
+					value = microdataValue(c, child)
+				}
+				for _, name := range names {
+					item.Properties[name] = append(item.Properties[name], value)
+				}
+			}
+			if !scoped {
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	return item
+}
+
+// microdataValue returns the value of a property element: the attribute
+// its tag takes it from, else its text
+func microdataValue(c converter, n *node) string {
+	attr := ""
+	switch n.tag {
+	case "meta":
+		attr = "content"
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		attr = "src"
+	case "a", "area", "link":
+		attr = "href"
+	case "object":
+		attr = "data"
+	case "data", "meter":
+		attr = "value"
+	case "time":
+		if value, ok := n.attrs["datetime"]; ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	if attr != "" {
+		return strings.TrimSpace(n.attr(attr))
+	}
+	return c.flatten(n)
+}
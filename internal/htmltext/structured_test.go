@@ -0,0 +1,73 @@
+package htmltext
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStructuredData(t *testing.T) {
+	page := `<!DOCTYPE html>
+<html><head>
+<meta property="og:title" content="My &amp; Post">
+<meta property="og:image" content="/a.png">
+<meta property="og:image" content="/b.png">
+<meta property="article:tag" content="go">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="description" content="Not structured">
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@type": "BlogPosting", "headline": "My & Post </p>"}
+</script>
+<script type="application/ld+json; charset=utf-8"><!-- {"@type": "Person", "name": "Ann"} --></script>
+<script type="application/ld+json">{"@type": broken}</script>
+<script>var notData = {"@type": "Thing"};</script>
+</head><body>
+<div itemscope itemtype="https://schema.org/Recipe" itemid="urn:recipe:1">
+  <h1 itemprop="name">Pancakes</h1>
+  <time itemprop="datePublished" datetime="2024-01-02">Jan 2</time>
+  <img itemprop="image" src="/pancakes.jpg">
+  <div itemprop="author" itemscope itemtype="https://schema.org/Person">
+    <span itemprop="name">Bo</span>
+  </div>
+  <meta itemprop="recipeYield keywords" content="4">
+</div>
+</body></html>`
+
+	data := ParseStructuredData(page)
+
+	require.Len(t, data.JSONLD, 2)
+	var posting map[string]interface{}
+	require.NoError(t, json.Unmarshal(data.JSONLD[0], &posting))
+	assert.Equal(t, "BlogPosting", posting["@type"])
+	assert.Equal(t, "My & Post </p>", posting["headline"], "script source is not unescaped or cut at markup")
+	assert.JSONEq(t, `{"@type": "Person", "name": "Ann"}`, string(data.JSONLD[1]))
+	assert.Equal(t, []string{`{"@type": broken}`}, data.InvalidJSONLD)
+
+	assert.Equal(t, map[string][]string{
+		"og:title":    {"My & Post"},
+		"og:image":    {"/a.png", "/b.png"},
+		"article:tag": {"go"},
+	}, data.OpenGraph)
+	assert.Equal(t, map[string][]string{"twitter:card": {"summary_large_image"}}, data.Twitter)
+
+	require.Len(t, data.Microdata, 1)
+	recipe := data.Microdata[0]
+	assert.Equal(t, []string{"https://schema.org/Recipe"}, recipe.Type)
+	assert.Equal(t, "urn:recipe:1", recipe.ID)
+	assert.Equal(t, []interface{}{"Pancakes"}, recipe.Properties["name"])
+	assert.Equal(t, []interface{}{"2024-01-02"}, recipe.Properties["datePublished"])
+	assert.Equal(t, []interface{}{"/pancakes.jpg"}, recipe.Properties["image"])
+	assert.Equal(t, []interface{}{"4"}, recipe.Properties["recipeYield"])
+	assert.Equal(t, []interface{}{"4"}, recipe.Properties["keywords"])
+	assert.Equal(t, []interface{}{MicrodataItem{
+		Type:       []string{"https://schema.org/Person"},
+		Properties: map[string][]interface{}{"name": {"Bo"}},
+	}}, recipe.Properties["author"])
+
+	empty := ParseStructuredData("<p>plain</p>")
+	assert.Empty(t, empty.JSONLD)
+	assert.Empty(t, empty.OpenGraph)
+	assert.Empty(t, empty.Microdata)
+}
@@ -0,0 +1,165 @@
+package htmltext
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MaxHeadingLevel is the deepest heading level read into an outline
+const MaxHeadingLevel = 4
+
+// tocStartLevel is the heading level of the outermost entries of Hugo's
+// .TableOfContents with its default markup.tableOfContents.startLevel
+const tocStartLevel = 2
+
+// Heading is an entry of a document's outline: a heading's level (1 for
+// h1), its text and the id it is linked to by, if any
+type Heading struct {
+	Level  int
+	Text   string
+	Anchor string
+}
+
+var (
+	// markdownHeadingPattern matches an ATX heading with an optional
+	// {#id} attribute
+	markdownHeadingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})[ \t]+(.*?)(?:[ \t]+\{#([^}\s]+)\})?[ \t#]*$`)
+
+	// fencePattern matches the opening or closing line of a fenced code
+	// block, in which # lines are not headings
+	fencePattern = regexp.MustCompile("^ {0,3}(```|~~~)")
+)
+
+// Headings returns the h1 to h4 headings of HTML, in document order, with
+// their id attributes as anchors. As with Links, only the main content of
+// a full document is read. Markdown ATX headings are returned when s is not
+// HTML, anchored by their {#id} attribute or, failing that, the id Hugo
+// generates for them.
+func Headings(s string) []Heading {
+	if !LooksLikeHTML(s) {
+		return markdownHeadings(s)
+	}
+
+	c := converter{}
+	var headings []Heading
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, child := range n.children {
+			if child.tag == "" || skippedTags[child.tag] {
+				continue
+			}
+			if level := headingLevel(child.tag); level > 0 {
+				if text := headingText(c.flatten(child)); text != "" {
+					headings = append(headings, Heading{Level: level, Text: text, Anchor: strings.TrimSpace(child.attr("id"))})
+				}
+				continue
+			}
+			walk(child)
+		}
+	}
+	walk(mainContent(parse(s)))
+	return headings
+}
+
+// TableOfContents returns the entries of a table of contents rendered as
+// nested lists of links, such as Hugo's .TableOfContents. Entries are
+// given the level of their nesting from tocStartLevel, as Hugo nests
+// headings by level, and their link's fragment as anchor.
+func TableOfContents(s string) []Heading {
+	c := converter{}
+	var headings []Heading
+	var walk func(n *node, depth int)
+	walk = func(n *node, depth int) {
+		for _, child := range n.children {
+			switch child.tag {
+			case "":
+				continue
+			case "ul", "ol":
+				walk(child, depth+1)
+				continue
+			case "li":
+				if link := find(child, "a"); link != nil && linkIsEntry(child, link) {
+					level := tocStartLevel + depth - 1
+					if text := headingText(c.flatten(link)); text != "" && level <= MaxHeadingLevel {
+						_, anchor, _ := strings.Cut(link.attr("href"), "#")
+						headings = append(headings, Heading{Level: level, Text: text, Anchor: anchor})
+					}
+				}
+			}
+			walk(child, depth)
+		}
+	}
+	walk(parse(s), 0)
+	return headings
+}
+
+// linkIsEntry reports whether link is the entry of list item li itself,
+// rather than of a list nested in it
+func linkIsEntry(li, link *node) bool {
+	for n := link.parent; n != nil && n != li; n = n.parent {
+		if n.tag == "ul" || n.tag == "ol" {
+			return false
+		}
+	}
+	return true
+}
+
+// headingLevel returns the level of a heading tag up to MaxHeadingLevel,
+// or 0
+func headingLevel(tag string) int {
+	if len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '0'+MaxHeadingLevel {
+		return int(tag[1] - '0')
+	}
+	return 0
+}
+
+// headingText trims the permalink markers themes append to headings
+func headingText(text string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(text), "#¶🔗"))
+}
+
+func markdownHeadings(s string) []Heading {
+	var headings []Heading
+	inFence := false
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if fencePattern.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		match := markdownHeadingPattern.FindStringSubmatch(line)
+		if match == nil || len(match[1]) > MaxHeadingLevel {
+			continue
+		}
+		text := strings.TrimSpace(match[2])
+		if text == "" {
+			continue
+		}
+		anchor := match[3]
+		if anchor == "" {
+			anchor = anchorize(text)
+		}
+		headings = append(headings, Heading{Level: len(match[1]), Text: text, Anchor: anchor})
+	}
+	return headings
+}
+
+// anchorize returns the id Hugo's default GitHub style generates for a
+// heading: lowercased, with spaces as hyphens and punctuation other than
+// hyphens and underscores dropped
+func anchorize(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package htmltext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeadings(t *testing.T) {
+	page := `<html><body><nav><h2>Menu</h2></nav><main>
+<h1 id="guide">Guide</h1>
+<p>Intro</p>
+<h2 id="install">Install <a class="anchor" href="#install">#</a></h2>
+<h3 id="linux">On <code>Linux</code></h3>
+<h5 id="deep">Too deep</h5>
+<h2>Usage ¶</h2>
+</main></body></html>`
+
+	assert.Equal(t, []Heading{
+		{Level: 1, Text: "Guide", Anchor: "guide"},
+		{Level: 2, Text: "Install", Anchor: "install"},
+		{Level: 3, Text: "On Linux", Anchor: "linux"},
+		{Level: 2, Text: "Usage"},
+	}, Headings(page))
+
+	markdown := "# Guide\n\n## Install on Linux\n\n```sh\n# not a heading\n```\n\n### Custom ID {#custom}\n\n##### Too deep\n\n## Closed ##\n#NoSpace"
+	assert.Equal(t, []Heading{
+		{Level: 1, Text: "Guide", Anchor: "guide"},
+		{Level: 2, Text: "Install on Linux", Anchor: "install-on-linux"},
+		{Level: 3, Text: "Custom ID", Anchor: "custom"},
+		{Level: 2, Text: "Closed", Anchor: "closed"},
+	}, Headings(markdown))
+
+	assert.Empty(t, Headings("<p>No headings</p>"))
+}
+
+func TestTableOfContents(t *testing.T) {
+	toc := `<nav id="TableOfContents">
+  <ul>
+    <li><a href="#install">Install</a>
+      <ul>
+        <li><a href="#linux">Linux</a></li>
+        <li><a href="#macos">macOS</a></li>
+      </ul>
+    </li>
+    <li><a href="#usage">Usage</a></li>
+  </ul>
+</nav>`
+
+	assert.Equal(t, []Heading{
+		{Level: 2, Text: "Install", Anchor: "install"},
+		{Level: 3, Text: "Linux", Anchor: "linux"},
+		{Level: 3, Text: "macOS", Anchor: "macos"},
+		{Level: 2, Text: "Usage", Anchor: "usage"},
+	}, TableOfContents(toc))
+
+	// Hugo nests entries in empty items when a level is skipped
+	skipped := `<nav id="TableOfContents"><ul><li><ul><li><a href="#only">Only</a></li></ul></li></ul></nav>`
+	assert.Equal(t, []Heading{{Level: 3, Text: "Only", Anchor: "only"}}, TableOfContents(skipped))
+}
@@ -0,0 +1,218 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// ErrPrivateAddress is returned for requests to loopback, private,
+// link-local and other non-public addresses while they are blocked
+var ErrPrivateAddress = errors.New("request to a private address blocked")
+
+// sharedAddressSpace is the carrier-grade NAT range, which, like the
+// private ranges, is not reachable from the internet
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// defaultProxyPorts are the ports proxies listen on when their URL names none
+var defaultProxyPorts = map[string]string{"http": "80", "https": "443", "socks5": "1080", "socks5h": "1080"}
+
+// WithPrivateAddressBlocking makes the client refuse requests to loopback,
+// private, link-local, unspecified and multicast addresses, such as the
+// cloud metadata service at 169.254.169.254, failing with
+// ErrPrivateAddress. Host names are checked both before the request, by
+// resolving them, and when connecting, so a name that resolves to a public
+// address at first and a private one later is refused too. Hosts named by
+// WithAllowedHosts are exempt, as are the proxies requests go through.
+func WithPrivateAddressBlocking() Option {
+	return func(c *Client) {
+		c.blockPrivate = true
+	}
+}
+
+// WithAllowedHosts exempts hosts from private address blocking. Each entry
+// is a host name, an IP address, a CIDR range such as 10.0.0.0/8, or a
+// host and port such as hooks.internal:8443, which exempts only that port.
+func WithAllowedHosts(entries ...string) Option {
+	return func(c *Client) {
+		if c.allowedHosts == nil {
+			c.allowedHosts = make(map[string]bool)
+		}
+		for _, entry := range entries {
+			entry = strings.ToLower(strings.TrimSpace(entry))
+			if prefix, err := netip.ParsePrefix(entry); err == nil {
+				c.allowedPrefixes = append(c.allowedPrefixes, prefix.Masked())
+			} else if addr, err := netip.ParseAddr(entry); err == nil {
+				c.allowedPrefixes = append(c.allowedPrefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			} else if host, port, err := net.SplitHostPort(entry); err == nil && host != "" && port != "" {
+				c.allowedHosts[net.JoinHostPort(strings.TrimSuffix(host, "."), port)] = true
+			} else if entry != "" {
+				c.allowedHosts[strings.TrimSuffix(entry, ".")] = true
+			}
+		}
+	}
+}
+
+// ValidAllowedHost reports whether entry is usable with WithAllowedHosts:
+// entries that look like CIDR ranges must parse as one
+func ValidAllowedHost(entry string) error {
+	entry = strings.TrimSpace(entry)
+	if entry == "" {
+		return fmt.Errorf("empty host")
+	}
+	if strings.Contains(entry, "/") {
+		if _, err := netip.ParsePrefix(entry); err != nil {
+			return fmt.Errorf("invalid CIDR range %q", entry)
+		}
+	}
+	return nil
+}
+
+// isPublic reports whether addr is reachable from the internet
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !addr.IsLoopback() && !addr.IsPrivate() && !addr.IsUnspecified() &&
+		!addr.IsLinkLocalUnicast() && !addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() && !addr.IsMulticast() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// hostAllowed reports whether host, or host on port, was exempted from
+// private address blocking
+func (c *Client) hostAllowed(host, port string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	return c.allowedHosts[host] || (port != "" && c.allowedHosts[net.JoinHostPort(host, port)])
+}
+
+// addrAllowed reports whether a connection to addr may be made
+func (c *Client) addrAllowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if isPublic(addr) {
+		return true
+	}
+	for _, prefix := range c.allowedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAddress refuses requests to u when private addresses are blocked
+// and its host is, or resolves to, one. Names that don't resolve are left
+// for the request to fail on, or for the proxy to resolve.
+func (c *Client) checkAddress(ctx context.Context, u *url.URL) error {
+	if !c.blockPrivate {
+		return nil
+	}
+	host, port := u.Hostname(), u.Port()
+	if port == "" {
+		port = defaultProxyPorts[strings.ToLower(u.Scheme)]
+	}
+	if c.hostAllowed(host, port) {
+		return nil
+	}
+
+	if strings.EqualFold(strings.TrimSuffix(host, "."), "localhost") {
+		return c.checkAddrs(host, []netip.Addr{netip.IPv6Loopback()})
+	}
+	addrs, err := resolve(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	return c.checkAddrs(host, addrs)
+}
+
+// resolve returns the addresses of host on network, "ip", "ip4" or "ip6"
+func resolve(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr}, nil
+	}
+	return net.DefaultResolver.LookupNetIP(ctx, network, host)
+}
+
+// checkAddrs refuses host when any of its addresses is not allowed
+func (c *Client) checkAddrs(host string, addrs []netip.Addr) error {
+	for _, addr := range addrs {
+		if !c.addrAllowed(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrPrivateAddress, host, addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// guardTransport makes transport refuse connections to private addresses
+// that were not allowed, other than to the proxies it routes requests
+// through, whose addresses are noted as it picks them. Host names are
+// resolved and their addresses checked before connecting, and the checked
+// addresses are the ones dialed, so a refused address is never contacted.
+func (c *Client) guardTransport(transport *http.Transport) {
+	dial := transport.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	if proxy := transport.Proxy; proxy != nil {
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			proxyURL, err := proxy(req)
+			if proxyURL != nil {
+				port := proxyURL.Port()
+				if port == "" {
+					port = defaultProxyPorts[strings.ToLower(proxyURL.Scheme)]
+				}
+				c.proxyAddrs.Store(net.JoinHostPort(proxyURL.Hostname(), port), true)
+			}
+			return proxyURL, err
+		}
+	}
+
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if _, proxied := c.proxyAddrs.Load(address); proxied || c.hostAllowed(host, port) {
+			return dial(ctx, network, address)
+		}
+
+		ipNetwork := "ip"
+		switch network {
+		case "tcp4":
+			ipNetwork = "ip4"
+		case "tcp6":
+			ipNetwork = "ip6"
+		}
+		addrs, err := resolve(ctx, ipNetwork, host)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.checkAddrs(host, addrs); err != nil {
+			return nil, err
+		}
+
+		// Dial the checked addresses in turn rather than the name, which
+		// could resolve differently a second time
+		var firstErr error
+		for _, addr := range addrs {
+			conn, err := dial(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		if firstErr == nil {
+			firstErr = fmt.Errorf("no addresses for %s", host)
+		}
+		return nil, firstErr
+	}
+}
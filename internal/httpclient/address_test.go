@@ -0,0 +1,192 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":        true,
+		"2606:2800:220:1::":    true,
+		"127.0.0.1":            false,
+		"::1":                  false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.100.100.200":      false,
+		"0.0.0.0":              false,
+		"fd00:ec2::254":        false,
+		"fe80::1":              false,
+		"::ffff:127.0.0.1":     false,
+		"::ffff:93.184.216.34": true,
+	}
+	for addr, want := range tests {
+		assert.Equal(t, want, isPublic(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestClient_Get_BlocksPrivateAddresses(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	localhostURL := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		url     string
+		wantErr bool
+	}{
+		{name: "not blocking", url: server.URL},
+		{name: "loopback address", opts: []Option{WithPrivateAddressBlocking()}, url: server.URL, wantErr: true},
+		{name: "localhost", opts: []Option{WithPrivateAddressBlocking()}, url: localhostURL, wantErr: true},
+		{name: "metadata service", opts: []Option{WithPrivateAddressBlocking()}, url: "http://169.254.169.254/latest/meta-data/", wantErr: true},
+		{name: "allowed address", opts: []Option{WithPrivateAddressBlocking(), WithAllowedHosts("127.0.0.1")}, url: server.URL},
+		{name: "allowed range", opts: []Option{WithPrivateAddressBlocking(), WithAllowedHosts("127.0.0.0/8")}, url: server.URL},
+		{name: "allowed name", opts: []Option{WithAllowedHosts("LocalHost"), WithPrivateAddressBlocking()}, url: localhostURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = 0
+			client := New(append(tt.opts, WithRetryPolicy(RetryPolicy{MaxRetries: 2}))...)
+			resp, err := client.Get(context.Background(), tt.url)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrPrivateAddress)
+				assert.Zero(t, requests)
+				return
+			}
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, 1, requests)
+		})
+	}
+}
+
+func TestClient_Get_BlocksRedirectsToPrivateAddresses(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret"))
+	}))
+	defer target.Close()
+	server := redirectServer(t, target.URL)
+
+	// The first host is allowed, the one it redirects to is not
+	host := strings.TrimPrefix(strings.Replace(server.URL, "127.0.0.1", "localhost", 1), "http://")
+	client := New(WithPrivateAddressBlocking(), WithAllowedHosts("localhost"))
+	_, err := client.Get(context.Background(), "http://"+host+"/away")
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+}
+
+func TestClient_Get_BlocksPrivateAddressesWhenConnecting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// Requests that skip the checks made before sending are still refused
+	// when the connection is made
+	client := New(WithPrivateAddressBlocking())
+	_, err := client.HTTPClient().Get(server.URL)
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+}
+
+func TestClient_Get_NeverConnectsToBlockedAddresses(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	accepted := make(chan struct{}, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- struct{}{}
+			conn.Close()
+		}
+	}()
+
+	client := New(WithPrivateAddressBlocking())
+	_, err = client.HTTPClient().Get("http://" + listener.Addr().String() + "/")
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+	select {
+	case <-accepted:
+		t.Fatal("blocked address was connected to")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestClient_Get_AllowedHostPort(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	// An exempt host and port is reachable
+	client := New(WithPrivateAddressBlocking(), WithAllowedHosts(serverURL.Host))
+	resp, err := client.Get(context.Background(), server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// but other ports of the host are not
+	_, err = client.Get(context.Background(), "http://"+serverURL.Hostname()+":1/")
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+	_, err = client.HTTPClient().Get("http://" + serverURL.Hostname() + ":1/")
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+}
+
+func TestClient_Get_PrivateProxyIsAllowed(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte("ok"))
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	client := New(WithPrivateAddressBlocking(), WithProxy(proxyURL))
+	resp, err := client.Get(context.Background(), "http://93.184.216.34/index.json")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "http://93.184.216.34/index.json", proxied)
+
+	// The target is still checked when a proxy connects to it
+	_, err = client.Get(context.Background(), "http://10.0.0.1/index.json")
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+}
+
+func TestFromConfig_PrivateAddresses(t *testing.T) {
+	defer viper.Reset()
+
+	client, err := FromConfig()
+	require.NoError(t, err)
+	assert.True(t, client.blockPrivate)
+
+	viper.Set("block_private_addresses", false)
+	viper.Set("allowed_hosts", "intranet.example.com, 10.0.0.0/8")
+	client, err = FromConfig()
+	require.NoError(t, err)
+	assert.False(t, client.blockPrivate)
+	assert.True(t, client.hostAllowed("intranet.example.com", ""))
+	assert.True(t, client.addrAllowed(netip.MustParseAddr("10.9.8.7")))
+	assert.False(t, client.addrAllowed(netip.MustParseAddr("192.168.0.1")))
+
+	viper.Set("allowed_hosts", "10.0.0.0/33")
+	_, err = FromConfig()
+	assert.Error(t, err)
+}
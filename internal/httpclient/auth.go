@@ -0,0 +1,151 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+)
+
+// Credentials authenticate requests to a protected Hugo site. Either basic
+// auth or a bearer token may be used, optionally with extra headers.
+type Credentials struct {
+	Username    string            `json:"username,omitempty" mapstructure:"username" jsonschema:"title=Basic Auth Username"`
+	Password    string            `json:"password,omitempty" mapstructure:"password" jsonschema:"title=Basic Auth Password"`
+	BearerToken string            `json:"bearer_token,omitempty" mapstructure:"bearer_token" jsonschema:"title=Bearer Token"`
+	Headers     map[string]string `json:"headers,omitempty" mapstructure:"headers" jsonschema:"title=Custom Headers"`
+}
+
+// Validate checks the credentials are internally consistent
+func (c *Credentials) Validate() error {
+	if c.BearerToken != "" && (c.Username != "" || c.Password != "") {
+		return fmt.Errorf("auth: use either username/password or bearer_token, not both")
+	}
+	if c.Password != "" && c.Username == "" {
+		return fmt.Errorf("auth: username is required when password is set")
+	}
+	for name := range c.Headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			return fmt.Errorf("auth: invalid header name %q", name)
+		}
+		if strings.EqualFold(name, "Host") {
+			return fmt.Errorf("auth: the Host header cannot be overridden")
+		}
+	}
+	return nil
+}
+
+// apply adds the credentials to req without overriding headers already set
+func (c *Credentials) apply(req *http.Request) {
+	for name, value := range c.Headers {
+		if req.Header.Get(name) == "" {
+			req.Header.Set(name, value)
+		}
+	}
+	if req.Header.Get("Authorization") != "" {
+		return
+	}
+	switch {
+	case c.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.BearerToken)
+	case c.Username != "":
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+}
+
+// LogValue implements slog.LogValuer so credentials are never logged
+func (c Credentials) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Bool("basic_auth", c.Username != ""),
+		slog.Bool("bearer_token", c.BearerToken != ""),
+		slog.Int("headers", len(c.Headers)),
+	)
+}
+
+type credentialsKey struct{}
+
+type scopedCredentials struct {
+	host        string
+	credentials *Credentials
+}
+
+// ContextWithCredentials returns a copy of ctx carrying credentials that are
+// only sent to the given host. The host's cache entries are scoped by them.
+func ContextWithCredentials(ctx context.Context, host string, credentials *Credentials) context.Context {
+	identity, _ := json.Marshal(credentials)
+	ctx = cache.ContextWithScope(ctx, host, "credentials:"+string(identity))
+	return context.WithValue(ctx, credentialsKey{}, scopedCredentials{
+		host:        strings.ToLower(host),
+		credentials: credentials,
+	})
+}
+
+// credentialsFor returns the credentials that apply to req: per-request
+// credentials take precedence over host credentials from configuration
+func (c *Client) credentialsFor(req *http.Request) *Credentials {
+	host := strings.ToLower(req.URL.Host)
+	if scoped, ok := req.Context().Value(credentialsKey{}).(scopedCredentials); ok && scoped.host == host {
+		return scoped.credentials
+	}
+	if creds, ok := c.hostCredentials[host]; ok {
+		return &creds
+	}
+	if creds, ok := c.hostCredentials[strings.ToLower(req.URL.Hostname())]; ok {
+		return &creds
+	}
+	return nil
+}
+
+// redirectCredentials keeps credentials, site headers and cookies to the
+// host they were given for when req is redirected from the request first.
+// Go's client drops Authorization on redirects to another host but
+// forwards every other header, so the custom headers of the original host
+// are removed and the target host's own credentials applied instead.
+func (c *Client) redirectCredentials(req, first *http.Request) {
+	if !strings.EqualFold(first.URL.Host, req.URL.Host) {
+		if creds := c.credentialsFor(first); creds != nil {
+			for name := range creds.Headers {
+				req.Header.Del(name)
+			}
+			req.Header.Del("Authorization")
+		}
+		if headers := headersFor(first); headers != nil {
+			for name := range headers.headers {
+				req.Header.Del(name)
+			}
+			req.Header.Del("Cookie")
+		}
+	}
+	if creds := c.credentialsFor(req); creds != nil {
+		creds.apply(req)
+	}
+	if headers := headersFor(req); headers != nil {
+		headers.apply(req)
+	}
+}
+
+// AuthOptions are the optional credentials shared by every tool request that
+// fetches from a Hugo site. Embed it in request structs.
+type AuthOptions struct {
+	Auth *Credentials `json:"auth,omitempty" jsonschema:"title=Authentication for protected sites"`
+}
+
+// Validate checks the credentials, if any
+func (o *AuthOptions) Validate() error {
+	if o.Auth == nil {
+		return nil
+	}
+	return o.Auth.Validate()
+}
+
+// Apply derives a context whose requests to host carry the credentials
+func (o *AuthOptions) Apply(ctx context.Context, host string) context.Context {
+	if o.Auth == nil {
+		return ctx
+	}
+	return ContextWithCredentials(ctx, host, o.Auth)
+}
@@ -0,0 +1,204 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentials_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		creds   Credentials
+		wantErr bool
+	}{
+		{name: "basic auth", creds: Credentials{Username: "user", Password: "secret"}, wantErr: false},
+		{name: "bearer token", creds: Credentials{BearerToken: "token"}, wantErr: false},
+		{name: "headers only", creds: Credentials{Headers: map[string]string{"X-Api-Key": "key"}}, wantErr: false},
+		{name: "basic and bearer", creds: Credentials{Username: "user", BearerToken: "token"}, wantErr: true},
+		{name: "password without username", creds: Credentials{Password: "secret"}, wantErr: true},
+		{name: "invalid header name", creds: Credentials{Headers: map[string]string{"Bad Header": "x"}}, wantErr: true},
+		{name: "host header", creds: Credentials{Headers: map[string]string{"host": "evil.example.com"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.creds.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClient_Get_SendsRequestCredentialsToSiteHostOnly(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	client := New()
+	creds := &Credentials{Username: "user", Password: "secret", Headers: map[string]string{"X-Site-Key": "abc"}}
+
+	// Credentials scoped to the server's host are sent
+	ctx := ContextWithCredentials(context.Background(), serverURL.Host, creds)
+	resp, err := client.Get(ctx, server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	user, pass, ok := (&http.Request{Header: headers}).BasicAuth()
+	require.True(t, ok)
+	assert.Equal(t, "user", user)
+	assert.Equal(t, "secret", pass)
+	assert.Equal(t, "abc", headers.Get("X-Site-Key"))
+
+	// Credentials scoped to another host are not
+	ctx = ContextWithCredentials(context.Background(), "other.example.com", creds)
+	resp, err = client.Get(ctx, server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Empty(t, headers.Get("Authorization"))
+	assert.Empty(t, headers.Get("X-Site-Key"))
+}
+
+func TestContextWithCredentials_ScopesCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("protected"))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	client := New()
+	c := cache.New()
+	key := c.BuildKey(server.URL, "/page/", nil)
+	authed := ContextWithCredentials(context.Background(), serverURL.Host, &Credentials{BearerToken: "token"})
+
+	// A response refused without credentials is not held against calls with them
+	_, err := c.Fetch(context.Background(), client, key, server.URL+"/page/", nil)
+	assert.Error(t, err)
+	result, err := c.Fetch(authed, client, key, server.URL+"/page/", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "protected", string(result.Data))
+
+	// and a protected response is only served from the cache to them
+	result, err = c.Fetch(authed, client, key, server.URL+"/page/", nil)
+	require.NoError(t, err)
+	assert.True(t, result.Cached)
+	_, err = c.Fetch(context.Background(), client, key, server.URL+"/page/", nil)
+	assert.Error(t, err)
+	other := ContextWithCredentials(context.Background(), serverURL.Host, &Credentials{BearerToken: "other"})
+	_, err = c.Fetch(other, client, key, server.URL+"/page/", nil)
+	assert.Error(t, err)
+}
+
+func TestClient_Get_SendsHostCredentials(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	client := New(WithHostCredentials(map[string]Credentials{
+		serverURL.Host: {BearerToken: "configured"},
+	}))
+
+	resp, err := client.Get(context.Background(), server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "Bearer configured", authorization)
+
+	// Per-request credentials take precedence over configuration
+	ctx := ContextWithCredentials(context.Background(), serverURL.Host, &Credentials{BearerToken: "request"})
+	resp, err = client.Get(ctx, server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "Bearer request", authorization)
+}
+
+func TestClient_Get_DropsCredentialsOnCrossHostRedirect(t *testing.T) {
+	var otherHeaders http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherHeaders = r.Header.Clone()
+	}))
+	defer other.Close()
+
+	var siteHeaders http.Header
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moved":
+			http.Redirect(w, r, "/final", http.StatusMovedPermanently)
+		case "/away":
+			http.Redirect(w, r, other.URL+"/final", http.StatusFound)
+		default:
+			siteHeaders = r.Header.Clone()
+		}
+	}))
+	defer site.Close()
+
+	siteURL, _ := url.Parse(site.URL)
+	client := New(WithHostCredentials(map[string]Credentials{
+		siteURL.Host: {BearerToken: "configured", Headers: map[string]string{"X-Api-Key": "key"}},
+	}))
+
+	// Redirects within the site keep its credentials
+	resp, err := client.Get(context.Background(), site.URL+"/moved")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "Bearer configured", siteHeaders.Get("Authorization"))
+	assert.Equal(t, "key", siteHeaders.Get("X-Api-Key"))
+
+	// Redirects to another host carry none of them
+	resp, err = client.Get(context.Background(), site.URL+"/away")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.NotNil(t, otherHeaders)
+	assert.Empty(t, otherHeaders.Get("Authorization"))
+	assert.Empty(t, otherHeaders.Get("X-Api-Key"))
+}
+
+func TestFromConfig_Auth(t *testing.T) {
+	defer viper.Reset()
+
+	viper.Set("auth", map[string]interface{}{
+		"staging.example.com": map[string]interface{}{
+			"username": "user",
+			"password": "secret",
+		},
+	})
+
+	client, err := FromConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "user", client.hostCredentials["staging.example.com"].Username)
+
+	viper.Set("auth", map[string]interface{}{
+		"staging.example.com": map[string]interface{}{
+			"password": "secret",
+		},
+	})
+	_, err = FromConfig()
+	assert.Error(t, err)
+}
+
+func TestCredentials_LogValue(t *testing.T) {
+	creds := Credentials{Username: "user", Password: "secret", BearerToken: ""}
+	value := creds.LogValue().String()
+	assert.NotContains(t, value, "secret")
+	assert.NotContains(t, value, "user")
+}
@@ -0,0 +1,180 @@
+package httpclient
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxResponseSize is the largest response body read by default
+const DefaultMaxResponseSize int64 = 50 << 20
+
+// acceptEncoding lists the content encodings the client can decode
+const acceptEncoding = "gzip, deflate"
+
+// ErrResponseTooLarge is returned when reading a response body that exceeds
+// the client's maximum response size
+var ErrResponseTooLarge = errors.New("response too large")
+
+type noSizeLimitKey struct{}
+
+// ContextWithoutSizeLimit returns a context whose requests are not subject to
+// the maximum response size, for callers that stream the body instead of
+// reading it into memory
+func ContextWithoutSizeLimit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noSizeLimitKey{}, true)
+}
+
+// WithMaxResponseSize limits the size of response bodies after
+// decompression. Zero means unlimited.
+func WithMaxResponseSize(n int64) Option {
+	return func(c *Client) {
+		c.maxResponseSize = n
+	}
+}
+
+// Stream issues a GET bound to ctx that is exempt from the maximum response
+// size and returns the body of a 200 response for the caller to read
+// incrementally and close
+func (c *Client) Stream(ctx context.Context, rawURL string) (io.ReadCloser, error) {
+	resp, err := c.Get(ContextWithoutSizeLimit(ctx), rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// prepareBody decodes compressed responses to requests the client
+// negotiated an encoding for, and enforces the maximum response size
+func (c *Client) prepareBody(req *http.Request, resp *http.Response, decode bool) error {
+	if decode && hasBody(req, resp) {
+		if err := DecodeBody(resp); err != nil {
+			resp.Body.Close()
+			return err
+		}
+	}
+
+	limit := c.maxResponseSize
+	if noLimit, _ := req.Context().Value(noSizeLimitKey{}).(bool); noLimit || limit <= 0 {
+		return nil
+	}
+
+	body := &limitedBody{ReadCloser: resp.Body, limit: limit}
+	if resp.ContentLength > limit {
+		// Fail on the first read rather than after downloading the limit
+		body.read = resp.ContentLength
+	}
+	resp.Body = body
+	return nil
+}
+
+// hasBody reports whether resp may carry a body: responses to HEAD
+// requests and 1xx, 204 and 304 responses never do, though they may still
+// declare the Content-Encoding of the resource
+func hasBody(req *http.Request, resp *http.Response) bool {
+	if req.Method == http.MethodHead || resp.ContentLength == 0 {
+		return false
+	}
+	switch {
+	case resp.StatusCode < 200,
+		resp.StatusCode == http.StatusNoContent,
+		resp.StatusCode == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// DecodeBody replaces a gzip or deflate encoded body with its decoded
+// form, for callers that set Accept-Encoding themselves to see the encoded
+// body. Empty bodies are left as they are.
+func DecodeBody(resp *http.Response) error {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding == "" || encoding == "identity" {
+		return nil
+	}
+
+	buffered := bufio.NewReader(resp.Body)
+	if _, err := buffered.Peek(1); err == io.EOF {
+		return nil
+	}
+
+	var decoded io.Reader
+	switch encoding {
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return fmt.Errorf("invalid gzip response: %w", err)
+		}
+		decoded = reader
+	case "deflate":
+		// "deflate" should be zlib-wrapped, but some servers send raw deflate
+		header, _ := buffered.Peek(2)
+		if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			reader, err := zlib.NewReader(buffered)
+			if err != nil {
+				return fmt.Errorf("invalid deflate response: %w", err)
+			}
+			decoded = reader
+		} else {
+			decoded = flate.NewReader(buffered)
+		}
+	default:
+		return fmt.Errorf("unsupported content encoding: %s", encoding)
+	}
+
+	resp.Body = &decodedBody{Reader: decoded, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// decodedBody reads decoded data and closes the underlying body
+type decodedBody struct {
+	io.Reader
+	body io.ReadCloser
+}
+
+func (b *decodedBody) Close() error {
+	if closer, ok := b.Reader.(io.Closer); ok {
+		closer.Close()
+	}
+	return b.body.Close()
+}
+
+// limitedBody fails with ErrResponseTooLarge once more than limit bytes
+// have been read
+type limitedBody struct {
+	io.ReadCloser
+	limit int64
+	read  int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.read > b.limit {
+		return 0, b.tooLarge()
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		return n - int(b.read-b.limit), b.tooLarge()
+	}
+	return n, err
+}
+
+func (b *limitedBody) tooLarge() error {
+	return fmt.Errorf("%w: exceeds %d bytes", ErrResponseTooLarge, b.limit)
+}
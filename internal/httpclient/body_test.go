@@ -0,0 +1,200 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compress(t *testing.T, encoding, data string) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		require.NoError(t, err)
+		w = fw
+	}
+	_, err := w.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestClient_Get_DecodesResponses(t *testing.T) {
+	const data = `{"pages": []}`
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{name: "identity", encoding: "", body: []byte(data)},
+		{name: "gzip", encoding: "gzip", body: compress(t, "gzip", data)},
+		{name: "zlib deflate", encoding: "deflate", body: compress(t, "deflate", data)},
+		{name: "raw deflate", encoding: "deflate", body: compress(t, "raw-deflate", data)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, acceptEncoding, r.Header.Get("Accept-Encoding"))
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				w.Write(tt.body)
+			}))
+			defer server.Close()
+
+			resp, err := New().Get(context.Background(), server.URL)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, data, string(body))
+			assert.Empty(t, resp.Header.Get("Content-Encoding"))
+		})
+	}
+}
+
+func TestClient_Do_SkipsDecodingWithoutBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		switch r.URL.Path {
+		case "/not-modified":
+			w.WriteHeader(http.StatusNotModified)
+		case "/no-content":
+			w.WriteHeader(http.StatusNoContent)
+		case "/empty":
+			// Flushing sends the headers first, so the length is unknown
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+		default:
+			w.Header().Set("Content-Length", "120")
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{name: "HEAD", method: http.MethodHead, path: "/", status: http.StatusOK},
+		{name: "304", method: http.MethodGet, path: "/not-modified", status: http.StatusNotModified},
+		{name: "204", method: http.MethodGet, path: "/no-content", status: http.StatusNoContent},
+		{name: "empty body", method: http.MethodGet, path: "/empty", status: http.StatusOK},
+	}
+
+	client := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), tt.method, server.URL+tt.path, nil)
+			require.NoError(t, err)
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Empty(t, body)
+		})
+	}
+}
+
+func TestClient_Get_RejectsUnsupportedEncoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		w.Write([]byte("compressed"))
+	}))
+	defer server.Close()
+
+	_, err := New().Get(context.Background(), server.URL)
+	assert.ErrorContains(t, err, "unsupported content encoding")
+}
+
+func TestClient_Get_LimitsResponseSize(t *testing.T) {
+	large := strings.Repeat("x", 1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gzip" {
+			// Compressed size is small, decoded size is not
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(compress(t, "gzip", large))
+			return
+		}
+		w.Write([]byte(large))
+	}))
+	defer server.Close()
+
+	client := New(WithMaxResponseSize(100))
+
+	for _, path := range []string{"/plain", "/gzip"} {
+		resp, err := client.Get(context.Background(), server.URL+path)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.ErrorIs(t, err, ErrResponseTooLarge, path)
+		assert.LessOrEqual(t, len(body), 100, path)
+	}
+
+	// Streams are exempt from the limit
+	stream, err := client.Stream(context.Background(), server.URL+"/plain")
+	require.NoError(t, err)
+	body, err := io.ReadAll(stream)
+	stream.Close()
+	require.NoError(t, err)
+	assert.Len(t, body, len(large))
+
+	// Unlimited clients read everything
+	resp, err := New(WithMaxResponseSize(0)).Get(context.Background(), server.URL+"/plain")
+	require.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Len(t, body, len(large))
+}
+
+func TestClient_Stream_RejectsErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := New().Stream(context.Background(), server.URL)
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestFromConfig_MaxResponseSize(t *testing.T) {
+	defer viper.Reset()
+
+	client, err := FromConfig()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMaxResponseSize, client.maxResponseSize)
+
+	viper.Set("max_response_size", 1024)
+	client, err = FromConfig()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024), client.maxResponseSize)
+
+	viper.Set("max_response_size", -1)
+	_, err = FromConfig()
+	assert.Error(t, err)
+}
@@ -0,0 +1,390 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tracing"
+)
+
+// DefaultUserAgent is sent when no user agent has been configured
+const DefaultUserAgent = "HugoReader/1.0.0"
+
+// Client wraps an http.Client and retries transient failures with backoff.
+// A single Client is meant to be shared by all tools so that they reuse one
+// connection pool.
+type Client struct {
+	httpClient *http.Client
+	transport  *http.Transport
+	log        *slog.Logger
+	policy     RetryPolicy
+	userAgent  string
+
+	// maxResponseSize bounds decoded response bodies; zero is unlimited
+	maxResponseSize int64
+
+	// hostCredentials are configured per host and sent with every request to it
+	hostCredentials map[string]Credentials
+
+	// robots holds the robots.txt of each host fetched so far
+	robots *robotsCache
+
+	// honorRobots makes the client enforce robots.txt rules and crawl delays
+	honorRobots bool
+
+	// limiter spaces requests to each host; nil is unlimited
+	limiter *rateLimiter
+
+	// requestBudget is how many requests a tool call may make unless it
+	// sets its own budget; zero is unlimited
+	requestBudget int
+
+	// crawlDelay, when set, replaces the Crawl-delay hosts declare
+	crawlDelay *time.Duration
+
+	// observer, when set, is told about every request
+	observer Observer
+
+	// redirectPolicy and maxRedirects decide which redirects are followed
+	redirectPolicy string
+	maxRedirects   int
+
+	// blockPrivate refuses requests to non-public addresses, except to the
+	// allowed hosts and prefixes
+	blockPrivate    bool
+	allowedHosts    map[string]bool
+	allowedPrefixes []netip.Prefix
+
+	// proxyAddrs holds the addresses of the proxies requests were routed
+	// through, which may be dialed even when private
+	proxyAddrs sync.Map
+}
+
+// Option configures the Client
+type Option func(*Client)
+
+// New creates a new Client
+func New(opts ...Option) *Client {
+	transport := NewTransport()
+	c := &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second, Transport: transport},
+		transport:  transport,
+		log:        slog.Default().With("component", "httpclient"),
+		policy:     DefaultRetryPolicy(),
+		userAgent:  DefaultUserAgent,
+
+		maxResponseSize: DefaultMaxResponseSize,
+		robots:          &robotsCache{hosts: make(map[string]*robotsHost)},
+		redirectPolicy:  RedirectAny,
+		maxRedirects:    DefaultMaxRedirects,
+	}
+	c.httpClient.CheckRedirect = c.checkRedirect
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.blockPrivate && c.transport != nil {
+		c.guardTransport(c.transport)
+	}
+
+	return c
+}
+
+// WithLogger sets the logger for the client
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.log = logger.With("component", "httpclient")
+	}
+}
+
+// WithTimeout sets the per-attempt timeout of the underlying http.Client
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithRetryPolicy sets the retry policy used when the request context carries none
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.policy = policy
+	}
+}
+
+// WithHTTPClient replaces the underlying http.Client
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = client
+		c.transport = nil
+		if transport, ok := client.Transport.(*http.Transport); ok {
+			c.transport = transport
+		}
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithHostCredentials sets credentials to send to specific hosts, keyed by
+// host name (optionally with port)
+func WithHostCredentials(credentials map[string]Credentials) Option {
+	return func(c *Client) {
+		c.hostCredentials = make(map[string]Credentials, len(credentials))
+		for host, creds := range credentials {
+			c.hostCredentials[strings.ToLower(host)] = creds
+		}
+	}
+}
+
+// WithProxy routes all requests through the given proxy URL instead of
+// the proxies named by HTTP_PROXY, HTTPS_PROXY and NO_PROXY. HTTP(S) and
+// SOCKS5 proxies are supported.
+func WithProxy(proxyURL *url.URL) Option {
+	return func(c *Client) {
+		if c.transport != nil {
+			c.transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+}
+
+// NewTransport returns a transport tuned for issuing many small requests
+// against a handful of hosts. Proxy settings are taken from the environment.
+func NewTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// UserAgent returns the User-Agent header sent with every request
+func (c *Client) UserAgent() string {
+	return c.userAgent
+}
+
+// HTTPClient returns the underlying http.Client for callers that need to
+// issue requests without retries
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
+// Get issues a GET request bound to ctx.
+func (c *Client) Get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Do sends req, retrying network errors, 429 and 5xx responses according to
+// the retry policy attached to the request context (or the client default).
+// Unless req sets Accept-Encoding, gzip and deflate responses are decoded.
+// Reading a body larger than the maximum response size fails with
+// ErrResponseTooLarge. When the client honors robots.txt, disallowed
+// requests fail with ErrDisallowedByRobots. Every attempt waits for the
+// host's rate limit and counts against the request budget carried by the
+// context, failing with ErrBudgetExceeded once it is used up. Redirects
+// are followed as the redirect policy allows and recorded in the context's
+// redirect log, failing with ErrTooManyRedirects, ErrRedirectLoop or
+// ErrCrossHostRedirect when refused. When private addresses are blocked,
+// requests to them fail with ErrPrivateAddress. Requests
+// whose context carries a trace span are traced as its children, until the
+// body is closed. Each request is logged at debug level with the request ID
+// its context carries.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	_, span := tracing.Start(req.Context(), "HTTP "+req.Method, tracing.KindClient,
+		tracing.String("http.request.method", req.Method),
+		tracing.String("url.full", req.URL.Redacted()),
+		tracing.String("server.address", req.URL.Hostname()))
+	start := time.Now()
+	host := req.URL.Host
+	resp, err := c.do(req)
+	c.log.DebugContext(req.Context(), "Fetched", "host", host, "url", req.URL.Redacted(), "status", statusOf(resp), "duration", time.Since(start), "error", err)
+	if c.observer == nil && span == nil {
+		return resp, err
+	}
+
+	if err != nil {
+		if status := statusOf(resp); status != 0 {
+			span.SetAttributes(tracing.Int("http.response.status_code", status))
+		}
+		span.RecordError(err)
+		span.End()
+		if c.observer != nil {
+			c.observer.ObserveFetch(host, statusOf(resp), 0, time.Since(start), err)
+		}
+		return resp, err
+	}
+
+	span.SetAttributes(tracing.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.RecordError(fmt.Errorf("HTTP %d", resp.StatusCode))
+	}
+	resp.Body = &observedBody{ReadCloser: resp.Body, observe: func(read int64) {
+		span.SetAttributes(tracing.Int("http.response.body.size", int(read)))
+		span.End()
+		if c.observer != nil {
+			c.observer.ObserveFetch(host, resp.StatusCode, read, time.Since(start), nil)
+		}
+	}}
+	return resp, nil
+}
+
+// do sends req as described by Do
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if err := c.checkAddress(ctx, req.URL); err != nil {
+		return nil, err
+	}
+	if err := c.checkRobots(req); err != nil {
+		return nil, err
+	}
+	if c.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	decode := req.Header.Get("Accept-Encoding") == ""
+	if decode {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	if creds := c.credentialsFor(req); creds != nil {
+		creds.apply(req)
+	}
+	if headers := headersFor(req); headers != nil {
+		headers.apply(req)
+	}
+
+	policy := c.policy
+	if p, ok := RetryPolicyFromContext(ctx); ok {
+		policy = p
+	}
+
+	// Requests with a body can only be retried when it can be replayed
+	canRetry := req.Body == nil || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			attemptReq = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		if err := c.spend(ctx); err != nil {
+			return nil, err
+		}
+		if c.limiter != nil {
+			if err := c.limiter.wait(ctx, req.URL.Host); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.httpClient.Do(attemptReq)
+		if !canRetry || attempt >= policy.MaxRetries || !isRetryable(ctx, resp, err) {
+			if err != nil {
+				return resp, err
+			}
+			if err := c.prepareBody(req, resp, decode); err != nil {
+				return nil, err
+			}
+			return resp, nil
+		}
+
+		wait := policy.backoff(attempt)
+		if resp != nil {
+			if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > wait {
+				wait = min(retryAfter, maxRetryAfter)
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		c.log.DebugContext(ctx, "Retrying request",
+			"url", req.URL.String(),
+			"attempt", attempt+1,
+			"max_retries", policy.MaxRetries,
+			"wait", wait,
+			"status", statusOf(resp),
+			"error", err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isRetryable reports whether a response or error indicates a transient failure
+func isRetryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// Never retry once the caller has given up
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+			return false
+		}
+		// Requests refused by policy or robots.txt are refused again
+		return !errors.Is(err, ErrTooManyRedirects) && !errors.Is(err, ErrRedirectLoop) &&
+			!errors.Is(err, ErrCrossHostRedirect) && !errors.Is(err, ErrDisallowedByRobots) &&
+			!errors.Is(err, ErrPrivateAddress)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter parses the delay-seconds form of a Retry-After header
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func statusOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
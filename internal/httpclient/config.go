@@ -0,0 +1,157 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// proxySchemes are the proxy URL schemes the transport supports
+var proxySchemes = map[string]bool{"http": true, "https": true, "socks5": true, "socks5h": true}
+
+// ParseProxy parses an explicit proxy URL: an HTTP(S) proxy such as
+// http://proxy:3128, or a SOCKS5 proxy such as socks5://proxy:1080. Proxy
+// credentials may be given as user:password@. Errors never include the
+// URL, as it may carry credentials.
+func ParseProxy(value string) (*url.URL, error) {
+	proxyURL, err := url.Parse(value)
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL: expected a URL such as http://proxy:3128 or socks5://proxy:1080")
+	}
+	if !proxySchemes[strings.ToLower(proxyURL.Scheme)] {
+		return nil, fmt.Errorf("invalid proxy URL: unsupported scheme %q (must be http, https, socks5 or socks5h)", proxyURL.Scheme)
+	}
+	return proxyURL, nil
+}
+
+// FromConfig creates a Client from the viper settings user_agent,
+// http_timeout (seconds), proxy, max_response_size (bytes), auth
+// (credentials keyed by host), respect_robots, crawl_delay (a duration),
+// rate_limit (requests per second per host), rate_burst and
+// request_budget (requests per tool call), tls_ca_file, tls_cert_file,
+// tls_key_file, tls_insecure_skip_verify, redirect_policy, max_redirects,
+// block_private_addresses (true unless set otherwise) and allowed_hosts
+// (comma-separated).
+// Explicit options are applied last and take precedence over configuration.
+func FromConfig(opts ...Option) (*Client, error) {
+	var configOpts []Option
+
+	if userAgent := viper.GetString("user_agent"); userAgent != "" {
+		configOpts = append(configOpts, WithUserAgent(userAgent))
+	}
+
+	if timeout := viper.GetInt("http_timeout"); timeout > 0 {
+		configOpts = append(configOpts, WithTimeout(time.Duration(timeout)*time.Second))
+	}
+
+	if proxy := viper.GetString("proxy"); proxy != "" {
+		proxyURL, err := ParseProxy(proxy)
+		if err != nil {
+			return nil, err
+		}
+		configOpts = append(configOpts, WithProxy(proxyURL))
+	}
+
+	if viper.IsSet("max_response_size") {
+		maxResponseSize := viper.GetInt64("max_response_size")
+		if maxResponseSize < 0 {
+			return nil, fmt.Errorf("max_response_size must not be negative")
+		}
+		configOpts = append(configOpts, WithMaxResponseSize(maxResponseSize))
+	}
+
+	var hostCredentials map[string]Credentials
+	if err := viper.UnmarshalKey("auth", &hostCredentials); err != nil {
+		return nil, fmt.Errorf("invalid auth configuration: %w", err)
+	}
+	for host, creds := range hostCredentials {
+		if err := creds.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid auth configuration for %s: %w", host, err)
+		}
+	}
+	if len(hostCredentials) > 0 {
+		configOpts = append(configOpts, WithHostCredentials(hostCredentials))
+	}
+
+	tlsOptions := TLSOptions{
+		CAFile:             viper.GetString("tls_ca_file"),
+		CertFile:           viper.GetString("tls_cert_file"),
+		KeyFile:            viper.GetString("tls_key_file"),
+		InsecureSkipVerify: viper.GetBool("tls_insecure_skip_verify"),
+	}
+	if tlsOptions.IsSet() {
+		tlsConfig, err := tlsOptions.Config()
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS configuration: %w", err)
+		}
+		configOpts = append(configOpts, WithTLS(tlsConfig))
+	}
+
+	if redirectPolicy := viper.GetString("redirect_policy"); redirectPolicy != "" {
+		if !ValidRedirectPolicy(redirectPolicy) {
+			return nil, fmt.Errorf("invalid redirect_policy: %s (must be any, same-host or none)", redirectPolicy)
+		}
+		configOpts = append(configOpts, WithRedirectPolicy(redirectPolicy))
+	}
+
+	if viper.IsSet("max_redirects") {
+		maxRedirects := viper.GetInt("max_redirects")
+		if maxRedirects < 0 {
+			return nil, fmt.Errorf("max_redirects must not be negative")
+		}
+		configOpts = append(configOpts, WithMaxRedirects(maxRedirects))
+	}
+
+	if !viper.IsSet("block_private_addresses") || viper.GetBool("block_private_addresses") {
+		configOpts = append(configOpts, WithPrivateAddressBlocking())
+	}
+	var allowedHosts []string
+	for _, host := range strings.Split(viper.GetString("allowed_hosts"), ",") {
+		if host = strings.TrimSpace(host); host == "" {
+			continue
+		}
+		if err := ValidAllowedHost(host); err != nil {
+			return nil, fmt.Errorf("invalid allowed_hosts: %w", err)
+		}
+		allowedHosts = append(allowedHosts, host)
+	}
+	if len(allowedHosts) > 0 {
+		configOpts = append(configOpts, WithAllowedHosts(allowedHosts...))
+	}
+
+	if viper.GetBool("respect_robots") {
+		configOpts = append(configOpts, WithRobots())
+	}
+
+	if crawlDelay := viper.GetString("crawl_delay"); crawlDelay != "" {
+		delay, err := time.ParseDuration(crawlDelay)
+		if err != nil || delay < 0 {
+			return nil, fmt.Errorf("invalid crawl_delay: %s", crawlDelay)
+		}
+		configOpts = append(configOpts, WithCrawlDelay(delay))
+	}
+
+	rateLimit := viper.GetFloat64("rate_limit")
+	rateBurst := viper.GetInt("rate_burst")
+	if rateLimit < 0 || rateBurst < 0 {
+		return nil, fmt.Errorf("rate_limit and rate_burst must not be negative")
+	}
+	if rateLimit > 0 {
+		configOpts = append(configOpts, WithRateLimit(rateLimit, rateBurst))
+	}
+
+	if requestBudget := viper.GetInt("request_budget"); requestBudget < 0 {
+		return nil, fmt.Errorf("request_budget must not be negative")
+	} else if requestBudget > 0 {
+		configOpts = append(configOpts, WithRequestBudget(requestBudget))
+	}
+
+	client := New(append(configOpts, opts...)...)
+	if tlsOptions.InsecureSkipVerify {
+		client.log.Warn("TLS certificate verification is disabled: connections to every site can be intercepted without detection")
+	}
+	return client, nil
+}
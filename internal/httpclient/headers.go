@@ -0,0 +1,88 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+)
+
+type headersKey struct{}
+
+// scopedHeaders are headers and cookies sent with every request to a host
+type scopedHeaders struct {
+	host    string
+	headers map[string]string
+	cookies map[string]string
+}
+
+// ContextWithHeaders returns a copy of ctx whose requests to host carry the
+// given headers and cookies alongside any credentials, for sites that
+// expect a custom header or a cookie such as a password protection cookie.
+// Headers and cookies a request sets itself are kept. The host's cache
+// entries are scoped by them.
+func ContextWithHeaders(ctx context.Context, host string, headers, cookies map[string]string) context.Context {
+	if len(headers) == 0 && len(cookies) == 0 {
+		return ctx
+	}
+	identity, _ := json.Marshal([]map[string]string{headers, cookies})
+	ctx = cache.ContextWithScope(ctx, host, "headers:"+string(identity))
+	return context.WithValue(ctx, headersKey{}, scopedHeaders{
+		host:    strings.ToLower(host),
+		headers: headers,
+		cookies: cookies,
+	})
+}
+
+// ValidateHeaders checks the names and values of headers sent to a site
+func ValidateHeaders(headers map[string]string) error {
+	for name, value := range headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if strings.EqualFold(name, "Host") {
+			return fmt.Errorf("the Host header cannot be overridden")
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid value for header %s", name)
+		}
+	}
+	return nil
+}
+
+// ValidateCookies checks the names and values of cookies sent to a site
+func ValidateCookies(cookies map[string]string) error {
+	for name, value := range cookies {
+		if err := (&http.Cookie{Name: name, Value: value}).Valid(); err != nil {
+			return fmt.Errorf("invalid cookie %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// headersFor returns the headers and cookies that apply to req, if any
+func headersFor(req *http.Request) *scopedHeaders {
+	scoped, ok := req.Context().Value(headersKey{}).(scopedHeaders)
+	if !ok || scoped.host != strings.ToLower(req.URL.Host) {
+		return nil
+	}
+	return &scoped
+}
+
+// apply adds the headers and cookies to req without overriding those
+// already set
+func (h *scopedHeaders) apply(req *http.Request) {
+	for name, value := range h.headers {
+		if req.Header.Get(name) == "" {
+			req.Header.Set(name, value)
+		}
+	}
+	for name, value := range h.cookies {
+		if _, err := req.Cookie(name); err != nil {
+			req.AddCookie(&http.Cookie{Name: name, Value: value})
+		}
+	}
+}
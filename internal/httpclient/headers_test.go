@@ -0,0 +1,83 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateHeadersAndCookies(t *testing.T) {
+	assert.NoError(t, ValidateHeaders(map[string]string{"X-Api-Key": "abc"}))
+	assert.Error(t, ValidateHeaders(map[string]string{"X Api": "abc"}))
+	assert.Error(t, ValidateHeaders(map[string]string{"Host": "example.com"}))
+	assert.Error(t, ValidateHeaders(map[string]string{"X-Api-Key": "abc\r\nX-Injected: 1"}))
+
+	assert.NoError(t, ValidateCookies(map[string]string{"nf_jwt": "token"}))
+	assert.Error(t, ValidateCookies(map[string]string{"bad name": "token"}))
+	assert.Error(t, ValidateCookies(map[string]string{"nf_jwt": "a;b"}))
+}
+
+func TestClient_Get_SendsSiteHeadersAndCookies(t *testing.T) {
+	var otherHeaders http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherHeaders = r.Header.Clone()
+	}))
+	defer other.Close()
+
+	var siteHeaders http.Header
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moved":
+			http.Redirect(w, r, "/final", http.StatusMovedPermanently)
+		case "/away":
+			http.Redirect(w, r, other.URL+"/final", http.StatusFound)
+		default:
+			siteHeaders = r.Header.Clone()
+		}
+	}))
+	defer site.Close()
+
+	siteURL, _ := url.Parse(site.URL)
+	ctx := ContextWithHeaders(context.Background(), siteURL.Host,
+		map[string]string{"X-Api-Key": "key"},
+		map[string]string{"nf_jwt": "token"})
+	client := New()
+
+	// Headers and cookies follow redirects within the site
+	resp, err := client.Get(ctx, site.URL+"/moved")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "key", siteHeaders.Get("X-Api-Key"))
+	cookie, err := (&http.Request{Header: siteHeaders}).Cookie("nf_jwt")
+	require.NoError(t, err)
+	assert.Equal(t, "token", cookie.Value)
+
+	// Headers the request sets itself are kept
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, site.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Api-Key", "mine")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "mine", siteHeaders.Get("X-Api-Key"))
+
+	// Neither is sent to another host, directly or by redirect
+	resp, err = client.Get(ctx, other.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, otherHeaders.Get("X-Api-Key"))
+	assert.Empty(t, otherHeaders.Get("Cookie"))
+
+	otherHeaders = nil
+	resp, err = client.Get(ctx, site.URL+"/away")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.NotNil(t, otherHeaders)
+	assert.Empty(t, otherHeaders.Get("X-Api-Key"))
+	assert.Empty(t, otherHeaders.Get("Cookie"))
+}
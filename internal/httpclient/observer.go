@@ -0,0 +1,43 @@
+package httpclient
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Observer is told about every request the client sends, for metrics
+type Observer interface {
+	// ObserveFetch reports a request to host: the final status code (zero
+	// if there was no response), the bytes of the body read, how long the
+	// request took including reading the body, and any error
+	ObserveFetch(host string, status int, bytes int64, duration time.Duration, err error)
+}
+
+// WithObserver sets the observer told about every request
+func WithObserver(observer Observer) Option {
+	return func(c *Client) {
+		c.observer = observer
+	}
+}
+
+// observedBody counts the bytes read and reports them once, when the body
+// is closed
+type observedBody struct {
+	io.ReadCloser
+	read    int64
+	observe func(read int64)
+	once    sync.Once
+}
+
+func (b *observedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	return n, err
+}
+
+func (b *observedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.observe(b.read) })
+	return err
+}
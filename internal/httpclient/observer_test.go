@@ -0,0 +1,71 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fetch struct {
+	host   string
+	status int
+	bytes  int64
+	err    error
+}
+
+type recordingObserver struct {
+	fetches []fetch
+}
+
+func (o *recordingObserver) ObserveFetch(host string, status int, bytes int64, duration time.Duration, err error) {
+	o.fetches = append(o.fetches, fetch{host: host, status: status, bytes: bytes, err: err})
+}
+
+func TestClient_Get_Observer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+	host := mustHost(t, server.URL)
+
+	observer := &recordingObserver{}
+	client := New(WithObserver(observer))
+
+	resp, err := client.Get(context.Background(), server.URL)
+	require.NoError(t, err)
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body.Close()
+
+	resp, err = client.Get(context.Background(), server.URL+"/missing")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	server.Close()
+	_, err = client.Get(context.Background(), server.URL)
+	require.Error(t, err)
+
+	require.Len(t, observer.fetches, 3)
+	assert.Equal(t, fetch{host: host, status: http.StatusOK, bytes: 11}, observer.fetches[0])
+	assert.Equal(t, host, observer.fetches[1].host)
+	assert.Equal(t, http.StatusNotFound, observer.fetches[1].status)
+	assert.Zero(t, observer.fetches[2].status)
+	assert.Error(t, observer.fetches[2].err)
+}
+
+func mustHost(t *testing.T, rawURL string) string {
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	return u.Host
+}
@@ -0,0 +1,132 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxRequestBudget is the largest per-call request budget accepted from callers
+const MaxRequestBudget = 10000
+
+// ErrBudgetExceeded is returned once a tool call has made as many requests
+// as its budget allows
+var ErrBudgetExceeded = errors.New("request budget exceeded")
+
+// WithRateLimit limits requests to each host to rps per second, allowing
+// bursts of up to burst requests. Zero rps is unlimited.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		c.limiter = nil
+		if rps > 0 {
+			c.limiter = &rateLimiter{rate: rps, burst: float64(max(burst, 1)), buckets: make(map[string]*bucket)}
+		}
+	}
+}
+
+// WithRequestBudget sets how many requests a tool call may make when it
+// does not set a budget itself. Zero is unlimited.
+func WithRequestBudget(n int) Option {
+	return func(c *Client) {
+		c.requestBudget = n
+	}
+}
+
+// rateLimiter is a token bucket per host
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+}
+
+// bucket holds the tokens of one host; a negative count is a queue of
+// requests waiting for tokens
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// wait takes a token for host, waiting until one is available
+func (l *rateLimiter) wait(ctx context.Context, host string) error {
+	host = strings.ToLower(host)
+
+	l.mu.Lock()
+	now := time.Now()
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[host] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	b.tokens--
+	wait := time.Duration(0)
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// requestBudget counts the requests made by one tool call
+type requestBudget struct {
+	mu    sync.Mutex
+	limit int
+	set   bool
+	used  int
+}
+
+type requestBudgetKey struct{}
+
+// ContextWithRequestBudget returns a copy of ctx whose requests are
+// counted against a budget of n. Zero uses the client's default budget.
+func ContextWithRequestBudget(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, requestBudgetKey{}, &requestBudget{limit: n, set: n > 0})
+}
+
+// RequestsMade returns how many requests have been counted against the
+// budget carried by ctx
+func RequestsMade(ctx context.Context) int {
+	budget, ok := ctx.Value(requestBudgetKey{}).(*requestBudget)
+	if !ok {
+		return 0
+	}
+	budget.mu.Lock()
+	defer budget.mu.Unlock()
+	return budget.used
+}
+
+// spend counts a request against the budget carried by ctx, failing once
+// the budget is used up. Requests outside a tool call have no budget.
+func (c *Client) spend(ctx context.Context) error {
+	budget, ok := ctx.Value(requestBudgetKey{}).(*requestBudget)
+	if !ok {
+		return nil
+	}
+
+	budget.mu.Lock()
+	defer budget.mu.Unlock()
+	if !budget.set {
+		budget.limit, budget.set = c.requestBudget, true
+	}
+	if budget.limit > 0 && budget.used >= budget.limit {
+		return fmt.Errorf("%w: limit of %d requests", ErrBudgetExceeded, budget.limit)
+	}
+	budget.used++
+	return nil
+}
@@ -0,0 +1,97 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Wait(t *testing.T) {
+	limiter := &rateLimiter{rate: 20, burst: 2, buckets: make(map[string]*bucket)}
+
+	// The burst is available at once, then requests are spaced by the rate
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		require.NoError(t, limiter.wait(context.Background(), "example.com"))
+	}
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 90*time.Millisecond)
+	assert.Less(t, elapsed, time.Second)
+
+	// Hosts have separate buckets
+	start = time.Now()
+	require.NoError(t, limiter.wait(context.Background(), "other.example"))
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, limiter.wait(ctx, "EXAMPLE.com"), context.Canceled)
+}
+
+func TestClient_Get_RequestBudget(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := New(WithRetryPolicy(RetryPolicy{MaxRetries: 5, Backoff: time.Millisecond}), WithRequestBudget(10))
+
+	// Retries count against the budget
+	opts := RetryOptions{MaxRequests: 3, MaxRetries: intPtr(5), RetryBackoff: "1ms"}
+	ctx, cancel := opts.Apply(context.Background())
+	defer cancel()
+	_, err := client.Get(ctx, server.URL)
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	assert.Equal(t, 3, RequestsMade(ctx))
+
+	// Calls without a budget of their own use the client default
+	atomic.StoreInt32(&requests, 0)
+	ctx, cancel = (&RetryOptions{MaxRetries: intPtr(5), RetryBackoff: "1ms"}).Apply(context.Background())
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		_, err = client.Get(ctx, server.URL)
+		if err != nil {
+			break
+		}
+	}
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Equal(t, int32(10), atomic.LoadInt32(&requests))
+
+	// Requests outside a tool call are not counted
+	resp, err := New(WithRequestBudget(1), WithRetryPolicy(RetryPolicy{})).Get(context.Background(), server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestFromConfig_RateLimit(t *testing.T) {
+	defer viper.Reset()
+
+	viper.Set("rate_limit", 2.5)
+	viper.Set("rate_burst", 4)
+	viper.Set("request_budget", 100)
+	client, err := FromConfig()
+	require.NoError(t, err)
+	require.NotNil(t, client.limiter)
+	assert.Equal(t, 2.5, client.limiter.rate)
+	assert.Equal(t, float64(4), client.limiter.burst)
+	assert.Equal(t, 100, client.requestBudget)
+
+	viper.Set("rate_limit", 0)
+	client, err = FromConfig()
+	require.NoError(t, err)
+	assert.Nil(t, client.limiter)
+
+	viper.Set("request_budget", -1)
+	_, err = FromConfig()
+	assert.Error(t, err)
+}
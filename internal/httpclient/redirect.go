@@ -0,0 +1,138 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Redirect policies
+const (
+	// RedirectAny follows redirects to any host
+	RedirectAny = "any"
+
+	// RedirectSameHost only follows redirects within the host first
+	// requested, including from http to https
+	RedirectSameHost = "same-host"
+
+	// RedirectNone follows no redirects, returning the redirect response
+	RedirectNone = "none"
+)
+
+// DefaultMaxRedirects is how many redirects a request follows by default
+const DefaultMaxRedirects = 10
+
+var (
+	// ErrTooManyRedirects is returned for requests redirected more times
+	// than allowed
+	ErrTooManyRedirects = errors.New("too many redirects")
+
+	// ErrRedirectLoop is returned for requests redirected back to a URL
+	// already visited
+	ErrRedirectLoop = errors.New("redirect loop")
+
+	// ErrCrossHostRedirect is returned for redirects to another host under
+	// the same-host policy
+	ErrCrossHostRedirect = errors.New("redirect to another host not allowed")
+)
+
+// Redirect is one hop of a redirect chain
+type Redirect struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	StatusCode int    `json:"status"`
+}
+
+// ValidRedirectPolicy reports whether policy names a redirect policy
+func ValidRedirectPolicy(policy string) bool {
+	return policy == RedirectAny || policy == RedirectSameHost || policy == RedirectNone
+}
+
+// WithRedirectPolicy sets which redirects are followed: RedirectAny,
+// RedirectSameHost or RedirectNone
+func WithRedirectPolicy(policy string) Option {
+	return func(c *Client) {
+		c.redirectPolicy = policy
+	}
+}
+
+// WithMaxRedirects sets how many redirects a request follows before failing
+// with ErrTooManyRedirects. Zero fails on the first redirect.
+func WithMaxRedirects(n int) Option {
+	return func(c *Client) {
+		c.maxRedirects = n
+	}
+}
+
+type redirectLogKey struct{}
+
+// redirectLog collects the redirects followed by the requests of a tool call
+type redirectLog struct {
+	mu   sync.Mutex
+	hops []Redirect
+}
+
+// ContextWithRedirectLog returns a copy of ctx whose requests record the
+// redirects they follow, for Redirects to report
+func ContextWithRedirectLog(ctx context.Context) context.Context {
+	return context.WithValue(ctx, redirectLogKey{}, &redirectLog{})
+}
+
+// Redirects returns the redirects followed by requests made with ctx, in
+// the order they were followed
+func Redirects(ctx context.Context) []Redirect {
+	log, ok := ctx.Value(redirectLogKey{}).(*redirectLog)
+	if !ok {
+		return []Redirect{}
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	return append([]Redirect{}, log.hops...)
+}
+
+// checkRedirect decides whether to follow the redirect to req, enforcing
+// the redirect policy, the hop limit, loop detection and robots.txt.
+// Credentials are only carried to the host they were given for; the
+// target host's own credentials are applied instead.
+func (c *Client) checkRedirect(req *http.Request, via []*http.Request) error {
+	if c.redirectPolicy == RedirectNone {
+		return http.ErrUseLastResponse
+	}
+	if len(via) > c.maxRedirects {
+		return fmt.Errorf("%w: stopped after %d", ErrTooManyRedirects, c.maxRedirects)
+	}
+	target := req.URL.String()
+	for _, visited := range via {
+		if visited.URL.String() == target {
+			return fmt.Errorf("%w: %s", ErrRedirectLoop, req.URL.Redacted())
+		}
+	}
+
+	first, previous := via[0], via[len(via)-1]
+	if c.redirectPolicy == RedirectSameHost && !strings.EqualFold(first.URL.Hostname(), req.URL.Hostname()) {
+		return fmt.Errorf("%w: %s", ErrCrossHostRedirect, req.URL.Redacted())
+	}
+	if err := c.checkAddress(req.Context(), req.URL); err != nil {
+		return err
+	}
+	if err := c.checkRobots(req); err != nil {
+		return err
+	}
+	c.redirectCredentials(req, first)
+
+	status := 0
+	if req.Response != nil {
+		status = req.Response.StatusCode
+	}
+	hop := Redirect{From: previous.URL.Redacted(), To: req.URL.Redacted(), StatusCode: status}
+	c.log.DebugContext(req.Context(), "Following redirect", "from", hop.From, "to", hop.To, "status", status)
+	if log, ok := req.Context().Value(redirectLogKey{}).(*redirectLog); ok {
+		log.mu.Lock()
+		log.hops = append(log.hops, hop)
+		log.mu.Unlock()
+	}
+	return nil
+}
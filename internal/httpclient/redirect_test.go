@@ -0,0 +1,164 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redirectServer redirects /a to /b to /c, /loop to itself by way of
+// /loop2, and /away to target
+func redirectServer(t *testing.T, target string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusMovedPermanently)
+		case "/b":
+			http.Redirect(w, r, "/c", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop2", http.StatusFound)
+		case "/loop2":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		case "/away":
+			http.Redirect(w, r, target, http.StatusFound)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_Get_RecordsRedirectChain(t *testing.T) {
+	server := redirectServer(t, "")
+
+	ctx := ContextWithRedirectLog(context.Background())
+	resp, err := New().Get(ctx, server.URL+"/a")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []Redirect{
+		{From: server.URL + "/a", To: server.URL + "/b", StatusCode: http.StatusMovedPermanently},
+		{From: server.URL + "/b", To: server.URL + "/c", StatusCode: http.StatusFound},
+	}, Redirects(ctx))
+
+	// Contexts without a log record nothing
+	assert.Equal(t, []Redirect{}, Redirects(context.Background()))
+}
+
+func TestClient_Get_RedirectPolicies(t *testing.T) {
+	// The same-host policy ignores ports, so the other host is named
+	// differently
+	other := redirectServer(t, "")
+	server := redirectServer(t, strings.Replace(other.URL, "127.0.0.1", "localhost", 1)+"/done")
+	noRetries := RetryPolicy{MaxRetries: 0}
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		path       string
+		wantErr    error
+		wantStatus int
+	}{
+		{name: "loop", path: "/loop", wantErr: ErrRedirectLoop},
+		{name: "too many", opts: []Option{WithMaxRedirects(1)}, path: "/a", wantErr: ErrTooManyRedirects},
+		{name: "within limit", opts: []Option{WithMaxRedirects(2)}, path: "/a", wantStatus: http.StatusOK},
+		{name: "zero follows none", opts: []Option{WithMaxRedirects(0)}, path: "/a", wantErr: ErrTooManyRedirects},
+		{name: "cross host allowed", path: "/away", wantStatus: http.StatusOK},
+		{name: "cross host refused", opts: []Option{WithRedirectPolicy(RedirectSameHost)}, path: "/away", wantErr: ErrCrossHostRedirect},
+		{name: "same host allowed", opts: []Option{WithRedirectPolicy(RedirectSameHost)}, path: "/a", wantStatus: http.StatusOK},
+		{name: "none", opts: []Option{WithRedirectPolicy(RedirectNone)}, path: "/a", wantStatus: http.StatusMovedPermanently},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := New(append(tt.opts, WithRetryPolicy(noRetries))...)
+			resp, err := client.Get(context.Background(), server.URL+tt.path)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestClient_Get_RedirectsRespectRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			http.Redirect(w, r, "/robots-live.txt", http.StatusMovedPermanently)
+		case "/robots-live.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /private/\n"))
+		case "/public":
+			http.Redirect(w, r, "/private/page", http.StatusFound)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	// A redirected robots.txt is followed without re-entering the robots
+	// check for its host
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := New(WithRobots(), WithRetryPolicy(RetryPolicy{MaxRetries: 0}))
+
+	_, err := client.Get(ctx, server.URL+"/public")
+	assert.ErrorIs(t, err, ErrDisallowedByRobots)
+	require.NoError(t, ctx.Err())
+
+	resp, err := client.Get(ctx, server.URL+"/allowed")
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestClient_Get_RefusedRedirectsAreNotRetried(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Redirect(w, r, "/again", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := New(WithMaxRedirects(0), WithRetryPolicy(RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}))
+	_, err := client.Get(context.Background(), server.URL)
+	assert.ErrorIs(t, err, ErrTooManyRedirects)
+	assert.Equal(t, 1, requests)
+}
+
+func TestFromConfig_Redirects(t *testing.T) {
+	defer viper.Reset()
+
+	viper.Set("redirect_policy", RedirectSameHost)
+	viper.Set("max_redirects", 0)
+	client, err := FromConfig()
+	require.NoError(t, err)
+	assert.Equal(t, RedirectSameHost, client.redirectPolicy)
+	assert.Equal(t, 0, client.maxRedirects)
+
+	viper.Reset()
+	client, err = FromConfig()
+	require.NoError(t, err)
+	assert.Equal(t, RedirectAny, client.redirectPolicy)
+	assert.Equal(t, DefaultMaxRedirects, client.maxRedirects)
+
+	viper.Set("redirect_policy", "sometimes")
+	_, err = FromConfig()
+	assert.Error(t, err)
+
+	viper.Reset()
+	viper.Set("max_redirects", -1)
+	_, err = FromConfig()
+	assert.Error(t, err)
+}
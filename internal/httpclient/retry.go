@@ -0,0 +1,120 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// MaxTimeoutSeconds is the largest per-request timeout accepted from callers
+	MaxTimeoutSeconds = 300
+
+	// MaxRetries is the largest number of retries accepted from callers
+	MaxRetries = 5
+
+	// MaxRetryBackoff is the largest base backoff accepted from callers
+	MaxRetryBackoff = 30 * time.Second
+
+	// maxRetryAfter caps how long a server-provided Retry-After may delay us
+	maxRetryAfter = 30 * time.Second
+)
+
+// RetryPolicy controls how transient failures are retried
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+
+	// Backoff is the base delay, doubled after every failed attempt up to
+	// MaxRetryBackoff
+	Backoff time.Duration
+}
+
+// DefaultRetryPolicy returns the policy used when a request does not specify one
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 1,
+		Backoff:    500 * time.Millisecond,
+	}
+}
+
+// backoff returns the delay before the retry following the given attempt,
+// capped at MaxRetryBackoff
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.Backoff
+	for i := 0; i < attempt && wait < MaxRetryBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, MaxRetryBackoff)
+}
+
+type retryPolicyKey struct{}
+
+// ContextWithRetryPolicy returns a copy of ctx carrying the retry policy
+func ContextWithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// RetryPolicyFromContext returns the retry policy carried by ctx, if any
+func RetryPolicyFromContext(ctx context.Context) (RetryPolicy, bool) {
+	policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy)
+	return policy, ok
+}
+
+// RetryOptions are the optional timeout and retry fields shared by every tool
+// request that fetches from a Hugo site. Embed it in request structs.
+type RetryOptions struct {
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty" jsonschema:"title=Request Timeout (seconds),minimum=1,maximum=300"`
+	MaxRetries     *int   `json:"max_retries,omitempty" jsonschema:"title=Max Retries,minimum=0,maximum=5"`
+	RetryBackoff   string `json:"retry_backoff,omitempty" jsonschema:"title=Retry Backoff (e.g. 500ms or 2s)"`
+	MaxRequests    int    `json:"max_requests,omitempty" jsonschema:"title=Max HTTP Requests for the Call,minimum=1,maximum=10000"`
+}
+
+// Validate checks the timeout and retry fields are within range
+func (o *RetryOptions) Validate() error {
+	if o.TimeoutSeconds < 0 || o.TimeoutSeconds > MaxTimeoutSeconds {
+		return fmt.Errorf("timeout_seconds must be between 0 (default) and %d", MaxTimeoutSeconds)
+	}
+	if o.MaxRetries != nil && (*o.MaxRetries < 0 || *o.MaxRetries > MaxRetries) {
+		return fmt.Errorf("max_retries must be between 0 and %d", MaxRetries)
+	}
+	if o.MaxRequests < 0 || o.MaxRequests > MaxRequestBudget {
+		return fmt.Errorf("max_requests must be between 0 (default) and %d", MaxRequestBudget)
+	}
+	if o.RetryBackoff != "" {
+		backoff, err := time.ParseDuration(o.RetryBackoff)
+		if err != nil {
+			return fmt.Errorf("invalid retry_backoff: %w", err)
+		}
+		if backoff < 0 || backoff > MaxRetryBackoff {
+			return fmt.Errorf("retry_backoff must be between 0s and %s", MaxRetryBackoff)
+		}
+	}
+	return nil
+}
+
+// Policy returns the retry policy described by the options, using the
+// defaults for any field that was not set
+func (o *RetryOptions) Policy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	if o.MaxRetries != nil {
+		policy.MaxRetries = *o.MaxRetries
+	}
+	if backoff, err := time.ParseDuration(o.RetryBackoff); err == nil {
+		policy.Backoff = backoff
+	}
+	return policy
+}
+
+// Apply derives a context carrying the retry policy, the request budget
+// and redirect log of the tool call and, when timeout_seconds is set, a
+// deadline for it.
+func (o *RetryOptions) Apply(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx = ContextWithRetryPolicy(ctx, o.Policy())
+	ctx = ContextWithRequestBudget(ctx, o.MaxRequests)
+	ctx = ContextWithRedirectLog(ctx)
+	if o.TimeoutSeconds > 0 {
+		return context.WithTimeout(ctx, time.Duration(o.TimeoutSeconds)*time.Second)
+	}
+	return context.WithCancel(ctx)
+}
@@ -0,0 +1,330 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// robotsPath is where a site's robots.txt is served
+	robotsPath = "/robots.txt"
+
+	// maxRobotsSize is the most of a robots.txt that is parsed, as RFC 9309 allows
+	maxRobotsSize = 500 << 10
+
+	// robotsTTL is how long a fetched robots.txt is used before it is fetched again
+	robotsTTL = time.Hour
+
+	// robotsErrorTTL is how long a robots.txt that could not be fetched
+	// allows everything before it is tried again
+	robotsErrorTTL = time.Minute
+
+	// maxCrawlDelay caps the Crawl-delay a site may impose
+	maxCrawlDelay = 30 * time.Second
+)
+
+// ErrDisallowedByRobots is returned for requests to URLs a site's
+// robots.txt disallows
+var ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+
+// WithRobots makes the client honor each site's robots.txt: requests to
+// disallowed URLs fail with ErrDisallowedByRobots and requests to a host
+// are spaced by its Crawl-delay.
+func WithRobots() Option {
+	return func(c *Client) {
+		c.honorRobots = true
+	}
+}
+
+// WithCrawlDelay spaces requests to each host by delay instead of the
+// Crawl-delay declared in its robots.txt. Zero ignores declared delays.
+func WithCrawlDelay(delay time.Duration) Option {
+	return func(c *Client) {
+		c.crawlDelay = &delay
+	}
+}
+
+// robotsCache holds the parsed robots.txt of each host
+type robotsCache struct {
+	mu    sync.Mutex
+	hosts map[string]*robotsHost
+}
+
+// robotsHost is the robots.txt of one host and when it may next be fetched from
+type robotsHost struct {
+	mu      sync.Mutex
+	rules   *robotsRules
+	expires time.Time
+	next    time.Time
+}
+
+// host returns the entry for the scheme and host of u
+func (r *robotsCache) host(u *url.URL) *robotsHost {
+	key := strings.ToLower(u.Scheme + "://" + u.Host)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h, ok := r.hosts[key]
+	if !ok {
+		h = &robotsHost{}
+		r.hosts[key] = h
+	}
+	return h
+}
+
+// robotsRules are the rules of a robots.txt that apply to one user agent
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+	sitemaps   []string
+}
+
+// robotsRule is a single Allow or Disallow line
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// robotsGroup is a group of rules and the user agents it applies to
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// Sitemaps returns the sitemap URLs declared in the robots.txt of siteURL's
+// host, whether or not the client honors robots.txt
+func (c *Client) Sitemaps(ctx context.Context, siteURL *url.URL) ([]string, error) {
+	rules, err := c.robotsRules(ctx, c.robots.host(siteURL), siteURL)
+	if err != nil {
+		return nil, err
+	}
+	return rules.sitemaps, nil
+}
+
+// checkRobots fails requests robots.txt disallows and waits out the crawl
+// delay of the request's host
+func (c *Client) checkRobots(req *http.Request) error {
+	if !c.honorRobots || (req.URL.Scheme != "http" && req.URL.Scheme != "https") || req.URL.Path == robotsPath {
+		return nil
+	}
+	ctx := req.Context()
+	// Redirects of a robots.txt fetch are exempt: the host's rules are
+	// locked while it is fetched
+	if fetching, _ := ctx.Value(robotsFetchKey{}).(bool); fetching {
+		return nil
+	}
+
+	host := c.robots.host(req.URL)
+	rules, err := c.robotsRules(ctx, host, req.URL)
+	if err != nil {
+		return err
+	}
+	if !rules.allows(req.URL) {
+		return fmt.Errorf("%w: %s", ErrDisallowedByRobots, req.URL.Redacted())
+	}
+
+	delay := rules.crawlDelay
+	if c.crawlDelay != nil {
+		delay = *c.crawlDelay
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	// Reserve the next slot for this host so concurrent requests queue up
+	host.mu.Lock()
+	now := time.Now()
+	start := host.next
+	if start.Before(now) {
+		start = now
+	}
+	host.next = start.Add(delay)
+	host.mu.Unlock()
+
+	if wait := start.Sub(now); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
+
+// robotsRules returns the robots.txt rules of a host, fetching them when
+// they have not been fetched or have expired. A missing robots.txt allows
+// everything, as does one that could not be fetched, until it is retried.
+func (c *Client) robotsRules(ctx context.Context, host *robotsHost, u *url.URL) (*robotsRules, error) {
+	host.mu.Lock()
+	defer host.mu.Unlock()
+	if host.rules != nil && time.Now().Before(host.expires) {
+		return host.rules, nil
+	}
+
+	robotsURL := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: robotsPath}
+	rules, ttl, err := c.fetchRobots(ctx, robotsURL.String())
+	if err != nil {
+		// Don't remember failures caused by the caller giving up
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		c.log.DebugContext(ctx, "Failed to fetch robots.txt", "url", robotsURL.String(), "error", err)
+	}
+	host.rules = rules
+	host.expires = time.Now().Add(ttl)
+	return rules, nil
+}
+
+type robotsFetchKey struct{}
+
+// fetchRobots fetches and parses a robots.txt, returning how long the
+// result may be used
+func (c *Client) fetchRobots(ctx context.Context, robotsURL string) (*robotsRules, time.Duration, error) {
+	resp, err := c.Get(context.WithValue(ctx, robotsFetchKey{}, true), robotsURL)
+	if err != nil {
+		return &robotsRules{}, robotsErrorTTL, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return &robotsRules{}, robotsErrorTTL, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		// Missing or inaccessible robots.txt files place no restrictions
+		return &robotsRules{}, robotsTTL, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+	if err != nil {
+		return &robotsRules{}, robotsErrorTTL, err
+	}
+	return parseRobots(string(data), c.userAgent), robotsTTL, nil
+}
+
+// parseRobots parses a robots.txt and returns the rules that apply to
+// userAgent: those of the groups naming its product token, else those of
+// the "*" groups
+func parseRobots(data, userAgent string) *robotsRules {
+	token := strings.ToLower(userAgent)
+	if i := strings.IndexAny(token, "/ "); i >= 0 {
+		token = token[:i]
+	}
+
+	result := &robotsRules{}
+	var groups []*robotsGroup
+	var current *robotsGroup
+	inAgents := false
+
+	for _, line := range strings.Split(data, "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+				inAgents = true
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inAgents = false
+			if current != nil && value != "" {
+				current.rules = append(current.rules, robotsRule{allow: key == "allow", pattern: value})
+			}
+		case "crawl-delay":
+			inAgents = false
+			if seconds, err := strconv.ParseFloat(value, 64); current != nil && err == nil && seconds > 0 {
+				current.crawlDelay = min(time.Duration(seconds*float64(time.Second)), maxCrawlDelay)
+			}
+		case "sitemap":
+			// Sitemaps apply to every user agent
+			if value != "" {
+				result.sitemaps = append(result.sitemaps, value)
+			}
+		}
+	}
+
+	for _, wanted := range []string{token, "*"} {
+		matched := false
+		for _, group := range groups {
+			for _, agent := range group.agents {
+				if agent == wanted {
+					result.rules = append(result.rules, group.rules...)
+					result.crawlDelay = max(result.crawlDelay, group.crawlDelay)
+					matched = true
+					break
+				}
+			}
+		}
+		if matched {
+			break
+		}
+	}
+	return result
+}
+
+// allows reports whether the rules allow fetching u. The longest matching
+// rule wins, and Allow wins over Disallow when they are equally long.
+func (r *robotsRules) allows(u *url.URL) bool {
+	target := u.EscapedPath()
+	if target == "" {
+		target = "/"
+	}
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+
+	allowed, longest := true, -1
+	for _, rule := range r.rules {
+		if !matchRobotsPattern(rule.pattern, target) {
+			continue
+		}
+		if len(rule.pattern) > longest || (len(rule.pattern) == longest && rule.allow) {
+			allowed, longest = rule.allow, len(rule.pattern)
+		}
+	}
+	return allowed
+}
+
+// matchRobotsPattern matches a path against a robots.txt pattern, where "*"
+// matches any characters and a trailing "$" anchors the end of the path
+func matchRobotsPattern(pattern, target string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(target, parts[0]) {
+		return false
+	}
+	rest := target[len(parts[0]):]
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+	return !anchored || rest == ""
+}
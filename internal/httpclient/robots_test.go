@@ -0,0 +1,160 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRobots = `# Example robots.txt
+User-agent: *
+Disallow: /private/
+Allow: /private/public/
+Disallow: /*.pdf$
+Crawl-delay: 2
+
+User-agent: HugoReader
+User-agent: otherbot
+Disallow: /drafts/
+Disallow:
+Crawl-delay: 120
+
+Sitemap: https://example.com/sitemap.xml
+Sitemap: https://example.com/posts/sitemap.xml
+`
+
+func TestParseRobots(t *testing.T) {
+	rules := parseRobots(testRobots, "HugoReader/1.0.0")
+	assert.Equal(t, []robotsRule{{pattern: "/drafts/"}}, rules.rules)
+	assert.Equal(t, maxCrawlDelay, rules.crawlDelay)
+	assert.Equal(t, []string{"https://example.com/sitemap.xml", "https://example.com/posts/sitemap.xml"}, rules.sitemaps)
+
+	rules = parseRobots(testRobots, "SomeBot/2.0")
+	assert.Len(t, rules.rules, 3)
+	assert.Equal(t, 2*time.Second, rules.crawlDelay)
+
+	rules = parseRobots("Disallow: /ignored/\n", "SomeBot")
+	assert.Empty(t, rules.rules)
+}
+
+func TestRobotsRules_Allows(t *testing.T) {
+	rules := parseRobots(testRobots, "SomeBot")
+
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{path: "/", allowed: true},
+		{path: "/posts/hello/", allowed: true},
+		{path: "/private/notes/", allowed: false},
+		{path: "/private/public/page/", allowed: true},
+		{path: "/files/report.pdf", allowed: false},
+		{path: "/files/report.pdf?download=1", allowed: true},
+		{path: "/files/report.pdf.html", allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			u, err := url.Parse("https://example.com" + tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.allowed, rules.allows(u))
+		})
+	}
+}
+
+func TestMatchRobotsPattern(t *testing.T) {
+	assert.True(t, matchRobotsPattern("/", "/anything"))
+	assert.True(t, matchRobotsPattern("/fish*", "/fishheads/yummy.html"))
+	assert.True(t, matchRobotsPattern("/*.php$", "/folder/filename.php"))
+	assert.False(t, matchRobotsPattern("/*.php$", "/filename.php?parameters"))
+	assert.True(t, matchRobotsPattern("/fish*.php", "/fishheads/catfish.php?parameters"))
+	assert.False(t, matchRobotsPattern("/fish*.php", "/Fish.PHP"))
+	assert.True(t, matchRobotsPattern("/exact$", "/exact"))
+	assert.False(t, matchRobotsPattern("/exact$", "/exactly"))
+}
+
+func TestClient_Robots(t *testing.T) {
+	var robotsFetches, pageFetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			atomic.AddInt32(&robotsFetches, 1)
+			w.Write([]byte("User-agent: *\nDisallow: /private/\nCrawl-delay: 0.05\nSitemap: /custom-sitemap.xml\n"))
+			return
+		}
+		atomic.AddInt32(&pageFetches, 1)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := New(WithRobots())
+
+	_, err := client.Get(context.Background(), server.URL+"/private/secret/")
+	assert.ErrorIs(t, err, ErrDisallowedByRobots)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&pageFetches))
+
+	// Requests to a host are spaced by its crawl delay
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(context.Background(), server.URL+"/posts/")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&robotsFetches))
+
+	siteURL, _ := url.Parse(server.URL)
+	sitemaps, err := client.Sitemaps(context.Background(), siteURL)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/custom-sitemap.xml"}, sitemaps)
+
+	// Without robots support nothing is refused, but sitemaps are still read
+	client = New(WithCrawlDelay(0))
+	resp, err := client.Get(context.Background(), server.URL+"/private/secret/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	sitemaps, err = client.Sitemaps(context.Background(), siteURL)
+	require.NoError(t, err)
+	assert.Len(t, sitemaps, 1)
+}
+
+func TestClient_Robots_Unavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	// A robots.txt that can't be fetched places no restrictions
+	client := New(WithRobots(), WithRetryPolicy(RetryPolicy{}))
+	resp, err := client.Get(context.Background(), server.URL+"/private/")
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestFromConfig_Robots(t *testing.T) {
+	defer viper.Reset()
+
+	viper.Set("respect_robots", true)
+	viper.Set("crawl_delay", "1500ms")
+	client, err := FromConfig()
+	require.NoError(t, err)
+	assert.True(t, client.honorRobots)
+	require.NotNil(t, client.crawlDelay)
+	assert.Equal(t, 1500*time.Millisecond, *client.crawlDelay)
+
+	viper.Set("crawl_delay", "soon")
+	_, err = FromConfig()
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSOptions configure how the client verifies servers and identifies
+// itself to them
+type TLSOptions struct {
+	// CAFile is a PEM bundle of CA certificates trusted in addition to the
+	// system roots, for sites with certificates from a private CA
+	CAFile string
+
+	// CertFile and KeyFile are a PEM client certificate and key presented
+	// to servers that require mutual TLS
+	CertFile string
+	KeyFile  string
+
+	// InsecureSkipVerify disables verification of server certificates
+	InsecureSkipVerify bool
+}
+
+// IsSet reports whether any option differs from the default
+func (o TLSOptions) IsSet() bool {
+	return o.CAFile != "" || o.CertFile != "" || o.KeyFile != "" || o.InsecureSkipVerify
+}
+
+// Config loads the files the options name into a TLS configuration
+func (o TLSOptions) Config() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", o.CAFile)
+		}
+		config.RootCAs = roots
+	}
+
+	if (o.CertFile == "") != (o.KeyFile == "") {
+		return nil, fmt.Errorf("a client certificate needs both a certificate and a key file")
+	}
+	if o.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// WithTLS sets the TLS configuration of the client's transport, such as
+// one loaded by TLSOptions.Config
+func WithTLS(config *tls.Config) Option {
+	return func(c *Client) {
+		if c.transport != nil {
+			c.transport.TLSClientConfig = config
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePEM writes a PEM block to a file in dir, returning its path
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+// clientCertificate writes a self-signed client certificate and key to dir
+func clientCertificate(t *testing.T, dir string) (certFile, keyFile string, cert *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "hugo-reader"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return writePEM(t, dir, "client.pem", "CERTIFICATE", der), writePEM(t, dir, "client-key.pem", "EC PRIVATE KEY", keyDER), cert
+}
+
+func TestTLSOptions(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	caFile := writePEM(t, dir, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+
+	get := func(opts TLSOptions) error {
+		config, err := opts.Config()
+		require.NoError(t, err)
+		client := New(WithTLS(config), WithRetryPolicy(RetryPolicy{MaxRetries: 0}))
+		resp, err := client.Get(context.Background(), server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	// The server's private CA is only trusted once configured
+	assert.Error(t, get(TLSOptions{}))
+	assert.NoError(t, get(TLSOptions{CAFile: caFile}))
+	assert.NoError(t, get(TLSOptions{InsecureSkipVerify: true}))
+
+	_, err := TLSOptions{CAFile: filepath.Join(dir, "missing.pem")}.Config()
+	assert.Error(t, err)
+	_, err = TLSOptions{CAFile: writePEM(t, dir, "empty.pem", "NOTHING", nil)}.Config()
+	assert.ErrorContains(t, err, "no certificates")
+	_, err = TLSOptions{CertFile: caFile}.Config()
+	assert.ErrorContains(t, err, "both")
+}
+
+func TestTLSOptions_ClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, cert := clientCertificate(t, dir)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	caFile := writePEM(t, dir, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+
+	config, err := TLSOptions{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}.Config()
+	require.NoError(t, err)
+	resp, err := New(WithTLS(config)).Get(context.Background(), server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestFromConfig_TLS(t *testing.T) {
+	defer viper.Reset()
+
+	viper.Set("tls_insecure_skip_verify", true)
+	client, err := FromConfig()
+	require.NoError(t, err)
+	require.NotNil(t, client.transport.TLSClientConfig)
+	assert.True(t, client.transport.TLSClientConfig.InsecureSkipVerify)
+
+	viper.Set("tls_cert_file", "client.pem")
+	_, err = FromConfig()
+	assert.ErrorContains(t, err, "invalid TLS configuration")
+}
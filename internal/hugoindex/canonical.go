@@ -0,0 +1,63 @@
+package hugoindex
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// indexFiles are the directory index files Hugo publishes pages as
+var indexFiles = []string{"index.html", "index.htm"}
+
+// CanonicalURL normalizes a page URL so the aliases Hugo sites publish for
+// one page compare equal: relative URLs are resolved against base when it
+// is not nil, the scheme and host are lowercased, default ports, fragments
+// and trailing index.html are dropped, and directory paths end with a slash.
+// URLs that cannot be parsed are returned trimmed of surrounding space.
+func CanonicalURL(base *url.URL, rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return ""
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = u.Hostname()
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	p := u.Path
+	for _, file := range indexFiles {
+		if len(p) >= len(file) && strings.EqualFold(p[len(p)-len(file):], file) && (len(p) == len(file) || p[len(p)-len(file)-1] == '/') {
+			p = p[:len(p)-len(file)]
+			break
+		}
+	}
+	// Paths to files keep their extension; every other path is a directory
+	if p == "" || (!strings.HasSuffix(p, "/") && path.Ext(p) == "") {
+		p += "/"
+	}
+	u.Path = p
+	u.RawPath = ""
+	return u.String()
+}
+
+// canonicalPath returns the path and query of a page's canonical URL, which
+// identify the page within one site whether its URL is absolute or relative
+func canonicalPath(rawURL string) string {
+	canonical := CanonicalURL(nil, rawURL)
+	if u, err := url.Parse(canonical); err == nil {
+		u.Scheme, u.Host = "", ""
+		return u.String()
+	}
+	return canonical
+}
@@ -0,0 +1,156 @@
+package hugoindex
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// dateLayouts are the date formats Hugo and its templates commonly emit in
+// JSON outputs, from front matter, .Format and Go's default time formatting
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC822Z,
+	time.RFC822,
+	"Mon, 2 Jan 2006",
+	"Monday, January 2, 2006",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+}
+
+// dayLayouts are date formats without a time of day
+var dayLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"02.01.2006",
+}
+
+// pageDateFields are the page fields DateRange matches, in order of preference
+var pageDateFields = []string{"date", "lastmod", "lastMod"}
+
+// ParseDate parses a date in one of the formats Hugo commonly emits. Dates
+// without a time zone are taken as UTC.
+func ParseDate(date string) (time.Time, bool) {
+	parsed, _, ok := parseDate(date)
+	return parsed, ok
+}
+
+// parseDate parses a date, reporting whether it names a whole day rather
+// than an instant
+func parseDate(date string) (time.Time, bool, bool) {
+	date = strings.TrimSpace(date)
+	if date == "" {
+		return time.Time{}, false, false
+	}
+
+	// Go's default formatting may carry a monotonic clock reading
+	if i := strings.Index(date, " m="); i > 0 {
+		date = date[:i]
+	}
+
+	for _, layout := range dateLayouts {
+		if parsed, err := time.Parse(layout, date); err == nil {
+			return parsed, false, true
+		}
+	}
+	for _, layout := range dayLayouts {
+		if parsed, err := time.Parse(layout, date); err == nil {
+			return parsed, true, true
+		}
+	}
+
+	// Unix timestamps, as emitted by .Unix
+	if seconds, err := strconv.ParseInt(date, 10, 64); err == nil && len(date) >= 9 {
+		return time.Unix(seconds, 0).UTC(), false, true
+	}
+	return time.Time{}, false, false
+}
+
+// pageDate parses the first of fields the page sets to a recognisable date.
+// Hugo leaves unset dates at the zero time, which is treated as unset.
+func pageDate(page gjson.Result, fields []string) (time.Time, bool) {
+	for _, field := range fields {
+		parsed, ok := ParseDate(page.Get(field).String())
+		if ok && parsed.Year() > 1 {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// DateRange selects pages by their date, or their last modification date
+// when they have none. Either bound may be omitted; a bound given as a day
+// covers that whole day.
+type DateRange struct {
+	DateFrom string `json:"date_from,omitempty" jsonschema:"title=Earliest page date (e.g. 2024-01-01)"`
+	DateTo   string `json:"date_to,omitempty" jsonschema:"title=Latest page date (e.g. 2024-12-31)"`
+
+	from time.Time
+	to   time.Time
+}
+
+// Validate parses the bounds and checks they are in order
+func (r *DateRange) Validate() error {
+	r.from, r.to = time.Time{}, time.Time{}
+
+	if r.DateFrom != "" {
+		from, _, ok := parseDate(r.DateFrom)
+		if !ok {
+			return fmt.Errorf("invalid date_from: %s", r.DateFrom)
+		}
+		r.from = from
+	}
+	if r.DateTo != "" {
+		to, day, ok := parseDate(r.DateTo)
+		if !ok {
+			return fmt.Errorf("invalid date_to: %s", r.DateTo)
+		}
+		if day {
+			to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		r.to = to
+	}
+
+	if !r.from.IsZero() && !r.to.IsZero() && r.to.Before(r.from) {
+		return fmt.Errorf("date_to must not be before date_from")
+	}
+	return nil
+}
+
+// IsSet reports whether either bound is set
+func (r DateRange) IsSet() bool {
+	return r.DateFrom != "" || r.DateTo != ""
+}
+
+// Contains reports whether the page's date lies within the range. Pages
+// without a recognisable date are only contained in an unset range.
+func (r DateRange) Contains(page gjson.Result) bool {
+	if !r.IsSet() {
+		return true
+	}
+
+	date, ok := pageDate(page, pageDateFields)
+	if !ok {
+		return false
+	}
+	if !r.from.IsZero() && date.Before(r.from) {
+		return false
+	}
+	if !r.to.IsZero() && date.After(r.to) {
+		return false
+	}
+	return true
+}
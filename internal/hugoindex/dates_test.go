@@ -0,0 +1,109 @@
+package hugoindex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		date     string
+		expected time.Time
+	}{
+		{"2024-01-02T03:04:05Z", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"2024-01-02T03:04:05.123+00:00", time.Date(2024, 1, 2, 3, 4, 5, 123000000, time.UTC)},
+		{"2024-01-02T03:04:05", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"2024-01-02 03:04:05 +0000 UTC", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"2024-01-02 03:04:05.5 +0000 UTC m=+0.000000001", time.Date(2024, 1, 2, 3, 4, 5, 500000000, time.UTC)},
+		{"2024-01-02 03:04", time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)},
+		{"Tue, 02 Jan 2024 03:04:05 +0000", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"January 2, 2024", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"Jan 2, 2024", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"2 January 2024", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{" 2024-01-02 ", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"2024/01/02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"1704164645", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			parsed, ok := ParseDate(tt.date)
+			require.True(t, ok)
+			assert.True(t, tt.expected.Equal(parsed), "got %s", parsed)
+		})
+	}
+
+	for _, date := range []string{"", "yesterday", "2024-13-45", "42"} {
+		_, ok := ParseDate(date)
+		assert.False(t, ok, date)
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		r        DateRange
+		page     string
+		expected bool
+	}{
+		{
+			name:     "unset range",
+			page:     `{"title": "Undated"}`,
+			expected: true,
+		},
+		{
+			name:     "within range",
+			r:        DateRange{DateFrom: "2024-01-01", DateTo: "2024-12-31"},
+			page:     `{"date": "2024-06-01T10:00:00Z"}`,
+			expected: true,
+		},
+		{
+			name:     "before range",
+			r:        DateRange{DateFrom: "2024-01-01"},
+			page:     `{"date": "2023-12-31T23:59:59Z"}`,
+			expected: false,
+		},
+		{
+			name:     "day bound covers the whole day",
+			r:        DateRange{DateTo: "2024-01-31"},
+			page:     `{"date": "2024-01-31T23:00:00Z"}`,
+			expected: true,
+		},
+		{
+			name:     "after range",
+			r:        DateRange{DateTo: "2024-01-31"},
+			page:     `{"date": "2024-02-01"}`,
+			expected: false,
+		},
+		{
+			name:     "falls back to lastmod",
+			r:        DateRange{DateFrom: "2024-01-01"},
+			page:     `{"date": "0001-01-01T00:00:00Z", "lastmod": "2024-03-01"}`,
+			expected: true,
+		},
+		{
+			name:     "undated pages excluded",
+			r:        DateRange{DateFrom: "2024-01-01"},
+			page:     `{"title": "Undated"}`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.r.Validate())
+			assert.Equal(t, tt.expected, tt.r.Contains(gjson.Parse(tt.page)))
+		})
+	}
+}
+
+func TestDateRange_Validate(t *testing.T) {
+	assert.Error(t, (&DateRange{DateFrom: "soon"}).Validate())
+	assert.Error(t, (&DateRange{DateTo: "later"}).Validate())
+	assert.Error(t, (&DateRange{DateFrom: "2024-02-01", DateTo: "2024-01-01"}).Validate())
+	assert.NoError(t, (&DateRange{DateFrom: "2024-01-01", DateTo: "2024-01-01"}).Validate())
+}
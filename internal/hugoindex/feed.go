@@ -0,0 +1,52 @@
+package hugoindex
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/feed"
+)
+
+// Index formats
+const (
+	FormatHugo     = "hugo_index"
+	FormatJSONFeed = feed.FormatJSON
+)
+
+// feedPage is a feed item in the shape of a Hugo index page
+type feedPage struct {
+	feed.Item
+	Permalink string `json:"permalink"`
+	Section   string `json:"section,omitempty"`
+}
+
+// FromFeed returns the items of a feed as a site index. Items on the feed's
+// own host are listed under their path, as Hugo index pages are, with the
+// full URL kept as their permalink and the path's first segment as their
+// section.
+func FromFeed(f *feed.Feed) *SiteIndex {
+	base, _ := url.Parse(f.URL)
+
+	pages := make([]feedPage, 0, len(f.Items))
+	for _, item := range f.Items {
+		page := feedPage{Item: item, Permalink: item.URL}
+		if u, err := url.Parse(item.URL); err == nil && base != nil && u.Host == base.Host {
+			page.URL = u.Path
+			if section, _, ok := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/"); ok {
+				page.Section = section
+			}
+		}
+		pages = append(pages, page)
+	}
+
+	data, _ := json.Marshal(pages)
+	return &SiteIndex{url: f.URL, data: data, cached: f.Cached, format: f.Format}
+}
+
+// isJSONFeed reports whether data is a JSON Feed, which some Hugo sites
+// publish at the index path instead of a page list
+func isJSONFeed(data []byte) bool {
+	parsed, err := feed.Parse(data)
+	return err == nil && parsed.Format == feed.FormatJSON
+}
@@ -0,0 +1,68 @@
+package hugoindex
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/feed"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestFromFeed(t *testing.T) {
+	index := FromFeed(&feed.Feed{
+		URL:    "https://example.com/feed.json",
+		Format: feed.FormatJSON,
+		Items: []feed.Item{
+			{Title: "Local", URL: "https://example.com/posts/local/", Date: "2024-01-02T00:00:00Z"},
+			{Title: "Elsewhere", URL: "https://other.example/post/"},
+		},
+	})
+	assert.Equal(t, FormatJSONFeed, index.Format())
+	assert.Equal(t, "https://example.com/feed.json", index.URL())
+
+	var pages []gjson.Result
+	require.NoError(t, index.Pages(context.Background(), func(page gjson.Result) bool {
+		pages = append(pages, page)
+		return true
+	}))
+	require.Len(t, pages, 2)
+	assert.Equal(t, "/posts/local/", pages[0].Get("url").String())
+	assert.Equal(t, "https://example.com/posts/local/", pages[0].Get("permalink").String())
+	assert.Equal(t, "posts", pages[0].Get("section").String())
+	assert.False(t, pages[1].Get("section").Exists())
+	assert.Equal(t, "https://other.example/post/", pages[1].Get("url").String())
+
+	sections, err := index.Sections(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sections["posts"])
+}
+
+func TestLoad_JSONFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version": "https://jsonfeed.org/version/1", "title": "Feed", "items": [
+			{"id": "1", "url": "http://` + r.Host + `/posts/hello/", "title": "Hello", "content_html": "<p>Hi</p>", "date_published": "2024-01-02T00:00:00Z"}
+		]}`))
+	}))
+	defer server.Close()
+
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	index, err := Load(context.Background(), cache.New(), httpclient.New(), siteURL)
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSONFeed, index.Format())
+
+	page, found, err := index.Page(context.Background(), "/posts/hello/")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "Hello", page.Get("title").String())
+	assert.Equal(t, "Hi", page.Get("content").String())
+	assert.Equal(t, "2024-01-02T00:00:00Z", page.Get("date").String())
+}
@@ -0,0 +1,384 @@
+package hugoindex
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/feed"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/tidwall/gjson"
+)
+
+// Path is where Hugo sites conventionally publish their JSON index
+const Path = "/index.json"
+
+// TaxonomyFields are the page fields Hugo sites commonly use for taxonomies
+var TaxonomyFields = []string{"categories", "tags", "series", "authors", "topics"}
+
+// listFields are page fields holding lists of strings that are not
+// taxonomies, which InferTaxonomies ignores
+var listFields = map[string]bool{
+	"aliases": true, "keywords": true, "images": true, "videos": true, "audio": true,
+	"outputs": true, "resources": true, "links": true, "headings": true, "translations": true,
+}
+
+// urlFields are the page fields that may hold a page's URL, in order of
+// preference
+var urlFields = []string{"url", "permalink", "relpermalink", "uri"}
+
+// pathFields are the page fields Page matches a requested path against
+var pathFields = []string{"url", "permalink", "relpermalink", "uri", "path"}
+
+// SiteIndex is a Hugo site's JSON index, held in memory or, when it exceeds
+// the client's response size limit, streamed each time its pages are read
+type SiteIndex struct {
+	url    string
+	data   []byte
+	client *httpclient.Client
+	cached bool
+	format string
+}
+
+// New returns a SiteIndex over index data already in memory
+func New(indexURL string, data []byte) *SiteIndex {
+	return &SiteIndex{url: indexURL, data: data}
+}
+
+// Built returns a SiteIndex over pages gathered by other means than a
+// published index, such as a site's rendered HTML, reporting format as
+// its Format
+func Built(indexURL string, data []byte, format string, cached bool) *SiteIndex {
+	return &SiteIndex{url: indexURL, data: data, cached: cached, format: format}
+}
+
+type pathKey struct{}
+
+// ContextWithPath returns a copy of ctx under which the site index is read
+// from path instead of Path
+func ContextWithPath(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, pathKey{}, path)
+}
+
+// IndexPath returns where the site index is read from under ctx
+func IndexPath(ctx context.Context) string {
+	if path, ok := ctx.Value(pathKey{}).(string); ok && path != "" {
+		return path
+	}
+	return Path
+}
+
+// Load fetches the site index at IndexPath through the cache
+func Load(ctx context.Context, c *cache.Cache, client *httpclient.Client, siteURL *url.URL) (*SiteIndex, error) {
+	return LoadPath(ctx, c, client, siteURL, IndexPath(ctx))
+}
+
+// LoadPath fetches an index at path through the cache, revalidating expired
+// entries. Responses that are not Hugo indexes are rejected with
+// cache.ErrInvalidResponse, and indexes too large to cache are streamed.
+func LoadPath(ctx context.Context, c *cache.Cache, client *httpclient.Client, siteURL *url.URL, path string) (*SiteIndex, error) {
+	indexURL := siteURL.ResolveReference(&url.URL{Path: path}).String()
+	cacheKey := c.BuildKey(siteURL.String(), path, nil)
+
+	result, err := c.Fetch(ctx, client, cacheKey, indexURL, Valid)
+	if errors.Is(err, httpclient.ErrResponseTooLarge) {
+		return &SiteIndex{url: indexURL, client: client}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cached := result.Cached || result.Revalidated
+	if isJSONFeed(result.Data) {
+		parsed, _ := feed.Parse(result.Data)
+		parsed.URL, parsed.Cached = indexURL, cached
+		return FromFeed(parsed), nil
+	}
+	return &SiteIndex{url: indexURL, data: result.Data, cached: cached}, nil
+}
+
+// Valid reports whether data is a non-empty Hugo index, either a top-level
+// array of pages or an object with a "pages" array, or a JSON Feed with
+// at least one item
+func Valid(data []byte) bool {
+	if !gjson.ValidBytes(data) {
+		return false
+	}
+
+	parsed := gjson.ParseBytes(data)
+	if pages := parsed.Get(pagesKey); pages.IsArray() {
+		parsed = pages
+	} else if items := parsed.Get("items"); items.IsArray() && isJSONFeed(data) {
+		parsed = items
+	}
+	return parsed.IsArray() && len(parsed.Array()) > 0
+}
+
+// URL returns the address the index was loaded from
+func (idx *SiteIndex) URL() string {
+	return idx.url
+}
+
+// Cached reports whether the index was served from the cache
+func (idx *SiteIndex) Cached() bool {
+	return idx.cached
+}
+
+// Format returns the format the index was published in: FormatHugo, or
+// the format of the feed it was read from or the index was built in
+func (idx *SiteIndex) Format() string {
+	if idx.format == "" {
+		return FormatHugo
+	}
+	return idx.format
+}
+
+// Streamed reports whether the index is read as a stream rather than held
+// in memory
+func (idx *SiteIndex) Streamed() bool {
+	return idx.data == nil
+}
+
+// Digest returns a hash of the index's data, identifying what was read
+// across loads, or "" when the index is streamed
+func (idx *SiteIndex) Digest() string {
+	if idx.data == nil {
+		return ""
+	}
+	sum := sha256.Sum256(idx.data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// Pages calls fn for each page of the index, stopping early when fn returns
+// false
+func (idx *SiteIndex) Pages(ctx context.Context, fn func(page gjson.Result) bool) error {
+	if idx.data != nil {
+		return Pages(bytes.NewReader(idx.data), fn)
+	}
+
+	body, err := idx.client.Stream(ctx, idx.url)
+	if err != nil {
+		return fmt.Errorf("failed to stream index: %w", err)
+	}
+	defer body.Close()
+
+	return Pages(body, fn)
+}
+
+// Page finds the page at path. A page whose URL matches exactly is preferred
+// over one whose slug, then title, matches the last segment of path.
+func (idx *SiteIndex) Page(ctx context.Context, path string) (gjson.Result, bool, error) {
+	want := cleanPath(path)
+	if want == "" {
+		return gjson.Result{}, false, nil
+	}
+	last := want[strings.LastIndex(want, "/")+1:]
+
+	var match, slugMatch, titleMatch gjson.Result
+	err := idx.Pages(ctx, func(page gjson.Result) bool {
+		for _, field := range pathFields {
+			if value := page.Get(field); value.Exists() && cleanPath(value.String()) == want {
+				match = page
+				return false
+			}
+		}
+
+		if !slugMatch.Exists() && page.Get("slug").String() == last {
+			slugMatch = page
+		}
+		if !titleMatch.Exists() {
+			titleSlug := strings.ToLower(strings.ReplaceAll(page.Get("title").String(), " ", "-"))
+			if titleSlug == last {
+				titleMatch = page
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return gjson.Result{}, false, err
+	}
+
+	for _, page := range []gjson.Result{match, slugMatch, titleMatch} {
+		if page.Exists() {
+			return page, true, nil
+		}
+	}
+	return gjson.Result{}, false, nil
+}
+
+// Sections counts the pages in each content section, counting pages listed
+// under several aliases of one URL once
+func (idx *SiteIndex) Sections(ctx context.Context) (map[string]int, error) {
+	sections := make(map[string]int)
+	seen := make(map[string]bool)
+	err := idx.Pages(ctx, func(page gjson.Result) bool {
+		if key := canonicalPath(PageURL(page)); key != "" {
+			if seen[key] {
+				return true
+			}
+			seen[key] = true
+		}
+		if section := PageSection(page); section != "" {
+			sections[section]++
+		}
+		return true
+	})
+	return sections, err
+}
+
+// Taxonomies counts the pages assigned each term of the taxonomies found in
+// the index, keyed by taxonomy and then term
+func (idx *SiteIndex) Taxonomies(ctx context.Context) (map[string]map[string]int, error) {
+	taxonomies := make(map[string]map[string]int)
+	add := func(taxonomy string, value gjson.Result) {
+		eachTerm(value, func(term string) {
+			if taxonomies[taxonomy] == nil {
+				taxonomies[taxonomy] = make(map[string]int)
+			}
+			taxonomies[taxonomy][term]++
+		})
+	}
+
+	err := idx.Pages(ctx, func(page gjson.Result) bool {
+		for _, taxonomy := range TaxonomyFields {
+			add(taxonomy, page.Get(taxonomy))
+		}
+		page.Get("taxonomies").ForEach(func(key, value gjson.Result) bool {
+			add(key.String(), value)
+			return true
+		})
+		return true
+	})
+	return taxonomies, err
+}
+
+// Terms counts the pages assigned each term of a taxonomy
+func (idx *SiteIndex) Terms(ctx context.Context, taxonomy string) (map[string]int, error) {
+	terms := make(map[string]int)
+	err := idx.Pages(ctx, func(page gjson.Result) bool {
+		value := page.Get(taxonomy)
+		if !value.Exists() {
+			value = page.Get("taxonomies").Get(taxonomy)
+		}
+		eachTerm(value, func(term string) {
+			terms[term]++
+		})
+		return true
+	})
+	return terms, err
+}
+
+// InferTaxonomies counts the pages with terms in each field that looks like
+// a taxonomy: the candidates, whether they hold one term or a list, and any
+// other field holding a non-empty list of strings, other than the fields Hugo
+// pages use for other lists
+func (idx *SiteIndex) InferTaxonomies(ctx context.Context, candidates []string) (map[string]int, error) {
+	isCandidate := make(map[string]bool, len(candidates))
+	for _, candidate := range candidates {
+		isCandidate[candidate] = true
+	}
+
+	taxonomies := make(map[string]int)
+	err := idx.Pages(ctx, func(page gjson.Result) bool {
+		page.ForEach(func(key, value gjson.Result) bool {
+			field := key.String()
+			if isCandidate[field] || (!listFields[field] && isStringList(value)) {
+				hasTerms := false
+				eachTerm(value, func(string) { hasTerms = true })
+				if hasTerms {
+					taxonomies[field]++
+				}
+			}
+			return true
+		})
+		return true
+	})
+	return taxonomies, err
+}
+
+// isStringList reports whether value is an array of strings
+func isStringList(value gjson.Result) bool {
+	if !value.IsArray() {
+		return false
+	}
+	ok := true
+	value.ForEach(func(_, item gjson.Result) bool {
+		ok = item.Type == gjson.String
+		return ok
+	})
+	return ok
+}
+
+// PageURL returns the URL of a page, or "" if it has none
+func PageURL(page gjson.Result) string {
+	for _, field := range urlFields {
+		if pageURL := page.Get(field).String(); pageURL != "" {
+			return pageURL
+		}
+	}
+	return ""
+}
+
+// PageSection returns the section of a page, falling back to the first
+// segment of its URL for pages below a section, e.g. /posts/my-post/
+func PageSection(page gjson.Result) string {
+	if section := page.Get("section").String(); section != "" {
+		return section
+	}
+
+	if parts := strings.Split(cleanPath(PageURL(page)), "/"); len(parts) > 1 {
+		return parts[0]
+	}
+	return ""
+}
+
+// PageTaxonomies returns the terms a page is assigned, keyed by taxonomy,
+// from the common taxonomy fields and any taxonomies object
+func PageTaxonomies(page gjson.Result) map[string][]string {
+	taxonomies := make(map[string][]string)
+	add := func(taxonomy string, value gjson.Result) {
+		eachTerm(value, func(term string) {
+			taxonomies[taxonomy] = append(taxonomies[taxonomy], term)
+		})
+	}
+
+	for _, taxonomy := range TaxonomyFields {
+		add(taxonomy, page.Get(taxonomy))
+	}
+	page.Get("taxonomies").ForEach(func(key, value gjson.Result) bool {
+		if _, exists := taxonomies[key.String()]; !exists {
+			add(key.String(), value)
+		}
+		return true
+	})
+	return taxonomies
+}
+
+// eachTerm calls fn for each term of a taxonomy value, which is either a
+// single term or an array of them
+func eachTerm(value gjson.Result, fn func(term string)) {
+	if value.IsArray() {
+		value.ForEach(func(_, term gjson.Result) bool {
+			if term.Type == gjson.String && term.String() != "" {
+				fn(term.String())
+			}
+			return true
+		})
+	} else if value.Type == gjson.String && value.String() != "" {
+		fn(value.String())
+	}
+}
+
+// cleanPath reduces a URL or path to its path without surrounding slashes
+func cleanPath(rawPath string) string {
+	if u, err := url.Parse(rawPath); err == nil {
+		rawPath = u.Path
+	}
+	return strings.Trim(rawPath, "/")
+}
@@ -0,0 +1,247 @@
+package hugoindex
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+const testIndex = `{"pages": [
+	{"title": "Home", "url": "/"},
+	{"title": "First Post", "url": "/posts/first-post/", "tags": ["go", "hugo"]},
+	{"title": "Second Post", "permalink": "https://example.com/posts/second/", "slug": "second", "tags": "go"},
+	{"title": "Recipe", "url": "/recipes/bread/", "section": "cooking", "taxonomies": {"cuisines": ["french"]}}
+]}`
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected bool
+	}{
+		{
+			name:     "pages object",
+			data:     `{"pages": [{"title": "Post 1"}]}`,
+			expected: true,
+		},
+		{
+			name:     "top-level array",
+			data:     `[{"title": "Post 1"}]`,
+			expected: true,
+		},
+		{
+			name:     "empty pages array",
+			data:     `{"pages": []}`,
+			expected: false,
+		},
+		{
+			name:     "object without pages",
+			data:     `{"title": "Page"}`,
+			expected: false,
+		},
+		{
+			name:     "JSON Feed",
+			data:     `{"version": "https://jsonfeed.org/version/1.1", "items": [{"id": "1", "title": "Post 1"}]}`,
+			expected: true,
+		},
+		{
+			name:     "items without JSON Feed version",
+			data:     `{"items": [{"title": "Post 1"}]}`,
+			expected: false,
+		},
+		{
+			name:     "invalid JSON",
+			data:     `{invalid}`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Valid([]byte(tt.data)))
+		})
+	}
+}
+
+func TestSiteIndex_Page(t *testing.T) {
+	index := New("https://example.com/index.json", []byte(testIndex))
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "url", path: "/posts/first-post/", expected: "First Post"},
+		{name: "without slashes", path: "posts/first-post", expected: "First Post"},
+		{name: "permalink path", path: "/posts/second", expected: "Second Post"},
+		{name: "slug", path: "/articles/second/", expected: "Second Post"},
+		{name: "title", path: "/recipe", expected: "Recipe"},
+		{name: "missing", path: "/posts/missing/"},
+		{name: "empty", path: "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, found, err := index.Page(context.Background(), tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected != "", found)
+			assert.Equal(t, tt.expected, page.Get("title").String())
+		})
+	}
+}
+
+func TestSiteIndex_Sections(t *testing.T) {
+	sections, err := New("", []byte(testIndex)).Sections(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"posts": 2, "cooking": 1}, sections)
+}
+
+func TestSiteIndex_Taxonomies(t *testing.T) {
+	index := New("", []byte(testIndex))
+
+	taxonomies, err := index.Taxonomies(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]int{
+		"tags":     {"go": 2, "hugo": 1},
+		"cuisines": {"french": 1},
+	}, taxonomies)
+
+	terms, err := index.Terms(context.Background(), "cuisines")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"french": 1}, terms)
+}
+
+func TestSiteIndex_InferTaxonomies(t *testing.T) {
+	index := New("", []byte(`[
+		{"title": "A", "tags": ["go"], "moods": ["calm"], "aliases": ["/old/"], "themes": "dark"},
+		{"title": "B", "moods": ["happy", "calm"], "themes": "", "images": ["a.png"], "mixed": ["a", 1], "empty": []}
+	]`))
+
+	taxonomies, err := index.InferTaxonomies(context.Background(), []string{"themes"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"tags": 1, "moods": 2, "themes": 1}, taxonomies)
+}
+
+func TestPageURL(t *testing.T) {
+	assert.Equal(t, "/a/", PageURL(gjson.Parse(`{"url": "/a/", "permalink": "https://example.com/a/"}`)))
+	assert.Equal(t, "https://example.com/a/", PageURL(gjson.Parse(`{"permalink": "https://example.com/a/"}`)))
+	assert.Equal(t, "", PageURL(gjson.Parse(`{"title": "A"}`)))
+}
+
+func TestPageTaxonomies(t *testing.T) {
+	assert.Equal(t, map[string][]string{
+		"tags":     {"go", "hugo"},
+		"cuisines": {"french"},
+	}, PageTaxonomies(gjson.Parse(`{"tags": ["go", "hugo"], "categories": [], "taxonomies": {"cuisines": ["french"], "tags": ["ignored"]}}`)))
+	assert.Empty(t, PageTaxonomies(gjson.Parse(`{"title": "A"}`)))
+}
+
+func TestLoad(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case Path:
+			requests++
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Write([]byte(testIndex))
+		case "/error.json":
+			w.Write([]byte(`{"error": "not an index"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	c := cache.New()
+	ctx := context.Background()
+
+	index, err := Load(ctx, c, httpclient.New(), siteURL)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+Path, index.URL())
+	assert.False(t, index.Cached())
+	assert.False(t, index.Streamed())
+
+	digest := index.Digest()
+	assert.Len(t, digest, 32)
+
+	index, err = Load(ctx, c, httpclient.New(), siteURL)
+	require.NoError(t, err)
+	assert.True(t, index.Cached())
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, digest, index.Digest())
+
+	_, err = LoadPath(ctx, c, httpclient.New(), siteURL, "/error.json")
+	assert.ErrorIs(t, err, cache.ErrInvalidResponse)
+
+	// A site's own index path replaces the conventional one
+	assert.Equal(t, Path, IndexPath(ctx))
+	_, err = Load(ContextWithPath(ctx, "/error.json"), c, httpclient.New(), siteURL)
+	assert.ErrorIs(t, err, cache.ErrInvalidResponse)
+
+	// Indexes over the response size limit are streamed instead of cached
+	index, err = Load(ctx, cache.New(), httpclient.New(httpclient.WithMaxResponseSize(64)), siteURL)
+	require.NoError(t, err)
+	assert.True(t, index.Streamed())
+	assert.Empty(t, index.Digest())
+
+	count := 0
+	require.NoError(t, index.Pages(ctx, func(page gjson.Result) bool {
+		count++
+		return true
+	}))
+	assert.Equal(t, 4, count)
+}
+
+func TestCanonicalURL(t *testing.T) {
+	base, err := url.Parse("https://example.com/blog/")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		base     *url.URL
+		url      string
+		expected string
+	}{
+		{name: "trailing slash added", url: "/posts/foo", expected: "/posts/foo/"},
+		{name: "index.html dropped", url: "/posts/foo/index.html", expected: "/posts/foo/"},
+		{name: "index.htm dropped", url: "/posts/foo/INDEX.HTM", expected: "/posts/foo/"},
+		{name: "other html file kept", url: "/posts/foo.html", expected: "/posts/foo.html"},
+		{name: "file named like index kept", url: "/posts/myindex.html", expected: "/posts/myindex.html"},
+		{name: "root index", url: "https://example.com/index.html", expected: "https://example.com/"},
+		{name: "host and scheme lowercased", url: "HTTPS://Example.COM/Posts/Foo/", expected: "https://example.com/Posts/Foo/"},
+		{name: "default port dropped", url: "https://example.com:443/a/", expected: "https://example.com/a/"},
+		{name: "other port kept", url: "http://example.com:8080/a", expected: "http://example.com:8080/a/"},
+		{name: "fragment dropped, query kept", url: "/a/?page=2#top", expected: "/a/?page=2"},
+		{name: "resolved against base", base: base, url: "/posts/foo/index.html", expected: "https://example.com/posts/foo/"},
+		{name: "absolute URL ignores base", base: base, url: "https://Example.com/posts/foo", expected: "https://example.com/posts/foo/"},
+		{name: "empty", url: "  ", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CanonicalURL(tt.base, tt.url))
+		})
+	}
+}
+
+func TestSiteIndex_Sections_Aliases(t *testing.T) {
+	index := New("", []byte(`[
+		{"title": "Foo", "url": "/posts/foo/"},
+		{"title": "Foo", "url": "/posts/foo/index.html"},
+		{"title": "Foo", "url": "https://example.com/posts/foo"},
+		{"title": "Bar", "url": "/posts/bar"}
+	]`))
+	sections, err := index.Sections(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"posts": 2}, sections)
+}
@@ -0,0 +1,179 @@
+package hugoindex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBatchSize is the number of pages per batch tools scan at a time
+const DefaultBatchSize = 500
+
+// pagesKey is the object key Hugo indexes conventionally list pages under
+const pagesKey = "pages"
+
+// Iterator reads the pages of a Hugo index one at a time with a
+// json.Decoder, so only the current page is held in memory. The index is
+// either a top-level array or an object with a "pages" array.
+type Iterator struct {
+	dec     *json.Decoder
+	started bool
+	paged   bool
+	page    json.RawMessage
+	err     error
+}
+
+// NewIterator returns an Iterator over the index read from r
+func NewIterator(r io.Reader) *Iterator {
+	return &Iterator{dec: json.NewDecoder(r)}
+}
+
+// Next advances to the next page, returning false at the end of the index
+// or on error
+func (it *Iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		if it.err = it.start(); it.err != nil {
+			return false
+		}
+	}
+
+	if !it.dec.More() {
+		return false
+	}
+
+	it.page = nil
+	if err := it.dec.Decode(&it.page); err != nil {
+		it.err = fmt.Errorf("invalid index page: %w", err)
+		return false
+	}
+	return true
+}
+
+// Raw returns the JSON of the current page. It is only valid until the next
+// call to Next.
+func (it *Iterator) Raw() []byte {
+	return it.page
+}
+
+// Page returns the current page
+func (it *Iterator) Page() gjson.Result {
+	return gjson.ParseBytes(it.page)
+}
+
+// Paged reports whether the pages are listed under "pages" rather than in a
+// top-level array
+func (it *Iterator) Paged() bool {
+	return it.paged
+}
+
+// Err returns the first error encountered while reading the index
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// start consumes the index up to the opening bracket of the pages array
+func (it *Iterator) start() error {
+	tok, err := it.dec.Token()
+	if err != nil {
+		return fmt.Errorf("invalid index: %w", err)
+	}
+
+	switch tok {
+	case json.Delim('['):
+		return nil
+	case json.Delim('{'):
+		for it.dec.More() {
+			keyTok, err := it.dec.Token()
+			if err != nil {
+				return fmt.Errorf("invalid index: %w", err)
+			}
+
+			if key, _ := keyTok.(string); key == pagesKey {
+				valueTok, err := it.dec.Token()
+				if err != nil {
+					return fmt.Errorf("invalid index: %w", err)
+				}
+				if valueTok != json.Delim('[') {
+					return fmt.Errorf("invalid index: %q is not an array", pagesKey)
+				}
+				it.paged = true
+				return nil
+			}
+
+			var skipped json.RawMessage
+			if err := it.dec.Decode(&skipped); err != nil {
+				return fmt.Errorf("invalid index: %w", err)
+			}
+		}
+		return fmt.Errorf("invalid index: no %q array", pagesKey)
+	default:
+		return fmt.Errorf("invalid index: expected an array or object")
+	}
+}
+
+// Pages calls fn for each page of the index read from r, stopping early
+// when fn returns false
+func Pages(r io.Reader, fn func(page gjson.Result) bool) error {
+	it := NewIterator(r)
+	for it.Next() {
+		if !fn(it.Page()) {
+			return nil
+		}
+	}
+	return it.Err()
+}
+
+// Batches groups up to size pages of the index read from r into a document
+// of the same shape as the index, so functions written for whole indexes
+// can process a large index piecewise
+func Batches(r io.Reader, size int, fn func(batch []byte) bool) error {
+	var batch bytes.Buffer
+	count := 0
+
+	it := NewIterator(r)
+	flush := func() bool {
+		if count == 0 {
+			return true
+		}
+		if it.Paged() {
+			batch.WriteString("]}")
+		} else {
+			batch.WriteByte(']')
+		}
+		more := fn(batch.Bytes())
+		batch.Reset()
+		count = 0
+		return more
+	}
+
+	for it.Next() {
+		if count == 0 {
+			if it.Paged() {
+				batch.WriteString(`{"` + pagesKey + `":[`)
+			} else {
+				batch.WriteByte('[')
+			}
+		} else {
+			batch.WriteByte(',')
+		}
+		batch.Write(it.Raw())
+		count++
+
+		if count >= size && !flush() {
+			return nil
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	flush()
+	return nil
+}
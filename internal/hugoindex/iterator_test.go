@@ -0,0 +1,103 @@
+package hugoindex
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestIterator(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		paged   bool
+		wantErr bool
+	}{
+		{
+			name:  "top-level array",
+			input: `[{"title": "A"}, {"title": "B"}]`,
+			want:  []string{`{"title": "A"}`, `{"title": "B"}`},
+		},
+		{
+			name:  "pages object",
+			input: `{"site": {"title": "Blog"}, "pages": [{"title": "A"}], "other": 1}`,
+			want:  []string{`{"title": "A"}`},
+			paged: true,
+		},
+		{
+			name:  "empty array",
+			input: `[]`,
+		},
+		{
+			name:    "object without pages",
+			input:   `{"items": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "pages is not an array",
+			input:   `{"pages": {}}`,
+			wantErr: true,
+		},
+		{
+			name:    "scalar",
+			input:   `"text"`,
+			wantErr: true,
+		},
+		{
+			name:    "truncated",
+			input:   `[{"title": "A"}, {"title"`,
+			want:    []string{`{"title": "A"}`},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			it := NewIterator(strings.NewReader(tt.input))
+			for it.Next() {
+				got = append(got, string(it.Raw()))
+			}
+			if tt.wantErr {
+				assert.Error(t, it.Err())
+			} else {
+				assert.NoError(t, it.Err())
+				assert.Equal(t, tt.paged, it.Paged())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPages(t *testing.T) {
+	var titles []string
+	err := Pages(strings.NewReader(`{"pages": [{"title": "A"}, {"title": "B"}, {"title": "C"}]}`), func(page gjson.Result) bool {
+		titles = append(titles, page.Get("title").String())
+		return len(titles) < 2
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "B"}, titles)
+}
+
+func TestBatches(t *testing.T) {
+	var got []string
+	err := Batches(strings.NewReader(`[1, 2, 3, 4, 5]`), 2, func(batch []byte) bool {
+		got = append(got, string(batch))
+		return true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"[1,2]", "[3,4]", "[5]"}, got)
+
+	// Batches keep the shape of the index
+	got = nil
+	err = Batches(strings.NewReader(`{"pages": [1, 2, 3]}`), 2, func(batch []byte) bool {
+		got = append(got, string(batch))
+		return false
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"pages":[1,2]}`}, got)
+}
@@ -0,0 +1,49 @@
+package hugoindex
+
+import (
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// Reasons a page is excluded by PublishOptions
+const (
+	ExcludedDraft   = "draft"
+	ExcludedFuture  = "future"
+	ExcludedExpired = "expired"
+)
+
+// Front matter fields for publication dates, as Hugo templates commonly
+// spell them
+var (
+	publishDateFields = []string{"publishDate", "publishdate", "publish_date", "date"}
+	expiryDateFields  = []string{"expiryDate", "expirydate", "expiry_date"}
+)
+
+// PublishOptions selects pages by publication status, as Hugo does when
+// building a site. Expired pages are always excluded.
+type PublishOptions struct {
+	IncludeDrafts bool `json:"include_drafts,omitempty" jsonschema:"title=Include draft content"`
+	IncludeFuture bool `json:"include_future,omitempty" jsonschema:"title=Include content with a future publish date"`
+}
+
+// Exclusion returns why page is excluded at now, or "" if it is included
+func (o PublishOptions) Exclusion(page gjson.Result, now time.Time) string {
+	if !o.IncludeDrafts && page.Get("draft").Bool() {
+		return ExcludedDraft
+	}
+	if !o.IncludeFuture {
+		if published, ok := pageDate(page, publishDateFields); ok && published.After(now) {
+			return ExcludedFuture
+		}
+	}
+	if expires, ok := pageDate(page, expiryDateFields); ok && !expires.After(now) {
+		return ExcludedExpired
+	}
+	return ""
+}
+
+// Allows reports whether page is included at now
+func (o PublishOptions) Allows(page gjson.Result, now time.Time) bool {
+	return o.Exclusion(page, now) == ""
+}
@@ -0,0 +1,75 @@
+package hugoindex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+func TestPublishOptions_Exclusion(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		page     string
+		opts     PublishOptions
+		expected string
+	}{
+		{
+			name:     "published page",
+			page:     `{"title": "Post", "date": "2024-01-01"}`,
+			expected: "",
+		},
+		{
+			name:     "draft",
+			page:     `{"title": "Post", "draft": true}`,
+			expected: ExcludedDraft,
+		},
+		{
+			name: "draft included",
+			page: `{"title": "Post", "draft": true}`,
+			opts: PublishOptions{IncludeDrafts: true},
+		},
+		{
+			name:     "future publish date",
+			page:     `{"title": "Post", "publishDate": "2024-07-01T00:00:00Z"}`,
+			expected: ExcludedFuture,
+		},
+		{
+			name:     "future date without publish date",
+			page:     `{"title": "Post", "date": "2025-01-01"}`,
+			expected: ExcludedFuture,
+		},
+		{
+			name: "future included",
+			page: `{"title": "Post", "publishdate": "2024-07-01"}`,
+			opts: PublishOptions{IncludeFuture: true},
+		},
+		{
+			name:     "expired",
+			page:     `{"title": "Post", "expiryDate": "2024-05-01"}`,
+			opts:     PublishOptions{IncludeDrafts: true, IncludeFuture: true},
+			expected: ExcludedExpired,
+		},
+		{
+			name:     "not yet expired",
+			page:     `{"title": "Post", "expiryDate": "2024-07-01"}`,
+			expected: "",
+		},
+		{
+			name:     "zero dates are unset",
+			page:     `{"title": "Post", "publishDate": "0001-01-01T00:00:00Z", "expiryDate": "0001-01-01T00:00:00Z"}`,
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := gjson.Parse(tt.page)
+			assert.Equal(t, tt.expected, tt.opts.Exclusion(page, now))
+			assert.Equal(t, tt.expected == "", tt.opts.Allows(page, now))
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package hugoindex
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// PathScope selects the pages under a path prefix, such as a section
+// (/docs/) or a single page and the pages below it (/docs/install/)
+type PathScope struct {
+	ScopePath string `json:"scope_path,omitempty" jsonschema:"title=Only match pages under this path prefix (e.g. /docs/)"`
+
+	prefix string
+}
+
+// Validate normalizes the prefix as page URLs are for comparison: full URLs
+// are reduced to their path, which gains a leading slash, and paths that
+// are not files end with a slash so /docs does not select /docsify/
+func (s *PathScope) Validate() error {
+	s.prefix = ""
+	if strings.TrimSpace(s.ScopePath) == "" {
+		return nil
+	}
+
+	prefix := scopedPath(s.ScopePath)
+	if strings.Contains(prefix, "?") {
+		return fmt.Errorf("scope_path must be a path without a query: %s", s.ScopePath)
+	}
+	s.prefix = prefix
+	return nil
+}
+
+// IsSet reports whether the scope selects a subset of pages
+func (s PathScope) IsSet() bool {
+	return s.prefix != ""
+}
+
+// Prefix returns the normalized prefix, or "" when the scope is unset
+func (s PathScope) Prefix() string {
+	return s.prefix
+}
+
+// Contains reports whether the page's URL lies under the prefix. Pages
+// without a URL are only contained in an unset scope.
+func (s PathScope) Contains(page gjson.Result) bool {
+	if !s.IsSet() {
+		return true
+	}
+	pageURL := PageURL(page)
+	if pageURL == "" {
+		return false
+	}
+	return strings.HasPrefix(scopedPath(pageURL), s.prefix)
+}
+
+// scopedPath returns the canonical path of a URL with a leading slash, so
+// relative URLs compare equal to absolute ones
+func scopedPath(rawURL string) string {
+	p := canonicalPath(strings.TrimSpace(rawURL))
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return p
+}
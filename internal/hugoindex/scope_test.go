@@ -0,0 +1,47 @@
+package hugoindex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestPathScope_Validate(t *testing.T) {
+	for scopePath, want := range map[string]string{
+		"":                          "",
+		"/docs/":                    "/docs/",
+		"/docs":                     "/docs/",
+		"docs/install":              "/docs/install/",
+		"https://example.com/docs/": "/docs/",
+		"/docs/install/index.html":  "/docs/install/",
+		"/notes/changelog.md":       "/notes/changelog.md",
+	} {
+		scope := PathScope{ScopePath: scopePath}
+		require.NoError(t, scope.Validate(), scopePath)
+		assert.Equal(t, want, scope.Prefix(), scopePath)
+	}
+
+	scope := PathScope{ScopePath: "/docs/?lang=en"}
+	assert.Error(t, scope.Validate())
+}
+
+func TestPathScope_Contains(t *testing.T) {
+	scope := PathScope{ScopePath: "/docs"}
+	require.NoError(t, scope.Validate())
+
+	for page, want := range map[string]bool{
+		`{"url": "/docs/"}`:                                  true,
+		`{"url": "/docs/install/"}`:                          true,
+		`{"permalink": "https://example.com/docs/install/"}`: true,
+		`{"relpermalink": "docs/install/index.html"}`:        true,
+		`{"url": "/docsify/"}`:                               false,
+		`{"url": "/posts/docs/"}`:                            false,
+		`{"title": "No URL"}`:                                false,
+	} {
+		assert.Equal(t, want, scope.Contains(gjson.Parse(page)), page)
+	}
+
+	assert.True(t, PathScope{}.Contains(gjson.Parse(`{"title": "No URL"}`)))
+}
@@ -0,0 +1,28 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextHandler(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(ContextHandler(slog.NewJSONHandler(&out, nil))).With("tool", "hugo_reader_search")
+
+	ctx := WithRequestID(context.Background(), "5f2b8c1d9e3a4b70")
+	logger.InfoContext(ctx, "Fetched")
+	assert.Contains(t, out.String(), `"tool":"hugo_reader_search"`)
+	assert.Contains(t, out.String(), `"request_id":"5f2b8c1d9e3a4b70"`)
+
+	out.Reset()
+	logger.Info("Cache hit")
+	assert.NotContains(t, out.String(), "request_id")
+
+	assert.Len(t, NewRequestID(), 16)
+	assert.NotEqual(t, NewRequestID(), NewRequestID())
+	assert.Empty(t, RequestID(context.Background()))
+}
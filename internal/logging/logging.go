@@ -1,37 +1,54 @@
 package logging
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
-func New() *slog.Logger {
-	logLevel := &slog.LevelVar{}
-	loggerOps := &slog.HandlerOptions{
-		Level: logLevel,
-	}
-
-	switch viper.GetString("log_level") {
+// ParseLevel returns the level named by value: debug, info, warn or error.
+// An empty value is info.
+func ParseLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
 	case "debug":
-		logLevel.Set(slog.LevelDebug)
-	case "info":
-		logLevel.Set(slog.LevelInfo)
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
 	case "warn":
-		logLevel.Set(slog.LevelWarn)
+		return slog.LevelWarn, nil
 	case "error":
-		logLevel.Set(slog.LevelError)
+		return slog.LevelError, nil
 	default:
-		logLevel.Set(slog.LevelInfo)
+		return slog.LevelInfo, fmt.Errorf("invalid log level %q (must be debug, info, warn or error)", value)
+	}
+}
+
+// LevelName returns the name ParseLevel accepts for level
+func LevelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// New creates the server's logger, logging at the level set by log_level.
+// The level is held by logLevel, so setting it later changes what is
+// logged while the server runs.
+func New(logLevel *slog.LevelVar) *slog.Logger {
+	loggerOps := &slog.HandlerOptions{
+		Level: logLevel,
 	}
 
+	// Levels that don't parse fall back to info
+	level, _ := ParseLevel(viper.GetString("log_level"))
+	logLevel.Set(level)
+
 	servername := viper.GetString("server_name")
 	if servername == "" {
 		servername = "omdb"
 	}
 
-	logger := slog.New(slog.NewJSONHandler(os.Stderr, loggerOps))
+	logger := slog.New(ContextHandler(slog.NewJSONHandler(os.Stderr, loggerOps)))
 	slog.SetDefault(logger)
 	hostname, err := os.Hostname()
 	if err != nil {
@@ -0,0 +1,280 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+)
+
+// ContentType is the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Path is where the metrics listener serves metrics
+const Path = "/metrics"
+
+// Histogram bucket upper bounds, in seconds
+var (
+	toolBuckets  = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+	fetchBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+)
+
+// histogram counts observations into cumulative buckets
+type histogram struct {
+	bounds []float64
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, bound := range h.bounds {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// Metrics collects the server's metrics and writes them in the Prometheus
+// text exposition format. The zero value is not usable; create it with New.
+type Metrics struct {
+	mu sync.Mutex
+
+	toolCalls     map[[2]string]uint64 // tool, outcome
+	toolDurations map[string]*histogram
+
+	fetches        map[[2]string]uint64 // host, status code or "error"
+	fetchBytes     map[string]uint64
+	fetchDurations map[string]*histogram
+
+	endpointFailures map[string]uint64
+
+	cache *cache.Cache
+}
+
+// New creates an empty Metrics
+func New() *Metrics {
+	return &Metrics{
+		toolCalls:        make(map[[2]string]uint64),
+		toolDurations:    make(map[string]*histogram),
+		fetches:          make(map[[2]string]uint64),
+		fetchBytes:       make(map[string]uint64),
+		fetchDurations:   make(map[string]*histogram),
+		endpointFailures: make(map[string]uint64),
+	}
+}
+
+// SetCache sets the cache whose statistics are reported
+func (m *Metrics) SetCache(c *cache.Cache) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache = c
+}
+
+// Record counts a tool call and its duration
+func (m *Metrics) Record(tool string, duration time.Duration, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.toolCalls[[2]string{tool, outcome}]++
+	if m.toolDurations[tool] == nil {
+		m.toolDurations[tool] = newHistogram(toolBuckets)
+	}
+	m.toolDurations[tool].observe(duration.Seconds())
+}
+
+// ObserveFetch counts an HTTP request to host, the bytes of its body read
+// and its duration. Requests that failed without a response count under
+// the status "error".
+func (m *Metrics) ObserveFetch(host string, status int, bytes int64, duration time.Duration, err error) {
+	code := strconv.Itoa(status)
+	if err != nil && status == 0 {
+		code = "error"
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fetches[[2]string{host, code}]++
+	if bytes > 0 {
+		m.fetchBytes[host] += uint64(bytes)
+	}
+	if m.fetchDurations[host] == nil {
+		m.fetchDurations[host] = newHistogram(fetchBuckets)
+	}
+	m.fetchDurations[host].observe(duration.Seconds())
+}
+
+// ObserveEndpointFailure counts a site endpoint found missing
+func (m *Metrics) ObserveEndpointFailure(siteURL, path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.endpointFailures[path]++
+}
+
+// Handler serves the metrics
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		m.Write(w)
+	})
+}
+
+// Server returns an HTTP server that serves the metrics at Path on addr
+func (m *Metrics) Server(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(Path, m.Handler())
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// Write writes the metrics in the Prometheus text exposition format
+func (m *Metrics) Write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := bufio.NewWriter(w)
+
+	header(out, "hugo_reader_tool_invocations_total", "counter", "Tool calls by tool and outcome.")
+	for _, key := range sortedPairs(m.toolCalls) {
+		sample(out, "hugo_reader_tool_invocations_total", labels("tool", key[0], "outcome", key[1]), float64(m.toolCalls[key]))
+	}
+
+	header(out, "hugo_reader_tool_duration_seconds", "histogram", "Duration of tool calls.")
+	for _, tool := range sortedKeys(m.toolDurations) {
+		writeHistogram(out, "hugo_reader_tool_duration_seconds", "tool", tool, m.toolDurations[tool])
+	}
+
+	header(out, "hugo_reader_http_requests_total", "counter", "HTTP requests to sites by host and status code.")
+	for _, key := range sortedPairs(m.fetches) {
+		sample(out, "hugo_reader_http_requests_total", labels("host", key[0], "code", key[1]), float64(m.fetches[key]))
+	}
+
+	header(out, "hugo_reader_http_response_bytes_total", "counter", "Response body bytes read from sites by host.")
+	for _, host := range sortedKeys(m.fetchBytes) {
+		sample(out, "hugo_reader_http_response_bytes_total", labels("host", host), float64(m.fetchBytes[host]))
+	}
+
+	header(out, "hugo_reader_http_request_duration_seconds", "histogram", "Duration of HTTP requests to sites, including reading the body.")
+	for _, host := range sortedKeys(m.fetchDurations) {
+		writeHistogram(out, "hugo_reader_http_request_duration_seconds", "host", host, m.fetchDurations[host])
+	}
+
+	header(out, "hugo_reader_endpoint_failures_total", "counter", "Site endpoints found missing, by endpoint path.")
+	for _, path := range sortedKeys(m.endpointFailures) {
+		sample(out, "hugo_reader_endpoint_failures_total", labels("endpoint", path), float64(m.endpointFailures[path]))
+	}
+
+	if m.cache != nil {
+		stats := m.cache.Stats()
+		for _, stat := range []struct{ name, kind, help, key string }{
+			{"hugo_reader_cache_hits_total", "counter", "Cache lookups that found a fresh entry.", "hits"},
+			{"hugo_reader_cache_misses_total", "counter", "Cache lookups that found no fresh entry.", "misses"},
+			{"hugo_reader_cache_negative_hits_total", "counter", "Requests answered by a cached 404 or 410.", "negative_hits"},
+			{"hugo_reader_cache_revalidations_total", "counter", "Expired entries revalidated with a conditional request.", "revalidations"},
+			{"hugo_reader_cache_shared_fetches_total", "counter", "Fetches that waited for a concurrent request for the same resource.", "shared_fetches"},
+			{"hugo_reader_cache_evictions_total", "counter", "Entries evicted to stay within the cache limits.", "evictions"},
+			{"hugo_reader_cache_entries", "gauge", "Entries in the cache.", "total_entries"},
+			{"hugo_reader_cache_bytes", "gauge", "Size of the cached responses in bytes.", "total_size"},
+		} {
+			value, _ := stats[stat.key].(int)
+			header(out, stat.name, stat.kind, stat.help)
+			sample(out, stat.name, "", float64(value))
+		}
+	}
+
+	return out.Flush()
+}
+
+// header writes the HELP and TYPE lines of a metric
+func header(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes one sample line
+func sample(w io.Writer, name, labels string, value float64) {
+	fmt.Fprintf(w, "%s%s %s\n", name, labels, formatValue(value))
+}
+
+// writeHistogram writes the buckets, sum and count of a histogram
+func writeHistogram(w io.Writer, name, label, value string, h *histogram) {
+	for i, bound := range h.bounds {
+		sample(w, name+"_bucket", labels(label, value, "le", formatValue(bound)), float64(h.counts[i]))
+	}
+	sample(w, name+"_bucket", labels(label, value, "le", "+Inf"), float64(h.count))
+	sample(w, name+"_sum", labels(label, value), h.sum)
+	sample(w, name+"_count", labels(label, value), float64(h.count))
+}
+
+// labels formats label name/value pairs
+func labels(pairs ...string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// labelEscaper escapes label values as the text format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatValue formats a sample value
+func formatValue(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedPairs returns the keys of m in sorted order
+func sortedPairs(m map[[2]string]uint64) [][2]string {
+	keys := make([][2]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	return keys
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_Write(t *testing.T) {
+	m := New()
+	m.Record("hugo_reader_search", 200*time.Millisecond, nil)
+	m.Record("hugo_reader_search", 2*time.Second, errors.New("search failed"))
+	m.ObserveFetch("example.com", 200, 1024, 30*time.Millisecond, nil)
+	m.ObserveFetch("example.com", 0, 0, time.Second, errors.New("connection refused"))
+	m.ObserveEndpointFailure("https://example.com", "/search.json")
+
+	c := cache.New()
+	c.Set("key", []byte("data"), "", "")
+	c.Get("key")
+	m.SetCache(c)
+
+	var buf bytes.Buffer
+	require.NoError(t, m.Write(&buf))
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE hugo_reader_tool_invocations_total counter\n",
+		`hugo_reader_tool_invocations_total{tool="hugo_reader_search",outcome="error"} 1` + "\n",
+		`hugo_reader_tool_invocations_total{tool="hugo_reader_search",outcome="success"} 1` + "\n",
+		"# TYPE hugo_reader_tool_duration_seconds histogram\n",
+		`hugo_reader_tool_duration_seconds_bucket{tool="hugo_reader_search",le="0.25"} 1` + "\n",
+		`hugo_reader_tool_duration_seconds_bucket{tool="hugo_reader_search",le="2.5"} 2` + "\n",
+		`hugo_reader_tool_duration_seconds_bucket{tool="hugo_reader_search",le="+Inf"} 2` + "\n",
+		`hugo_reader_tool_duration_seconds_sum{tool="hugo_reader_search"} 2.2` + "\n",
+		`hugo_reader_tool_duration_seconds_count{tool="hugo_reader_search"} 2` + "\n",
+		`hugo_reader_http_requests_total{host="example.com",code="200"} 1` + "\n",
+		`hugo_reader_http_requests_total{host="example.com",code="error"} 1` + "\n",
+		`hugo_reader_http_response_bytes_total{host="example.com"} 1024` + "\n",
+		`hugo_reader_http_request_duration_seconds_count{host="example.com"} 2` + "\n",
+		`hugo_reader_endpoint_failures_total{endpoint="/search.json"} 1` + "\n",
+		"hugo_reader_cache_hits_total 1\n",
+		"hugo_reader_cache_entries 1\n",
+	} {
+		assert.Contains(t, out, want)
+	}
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m := New()
+	m.Record(`tool "quoted"`, time.Millisecond, nil)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `{tool="tool \"quoted\"",outcome="success"} 1`)
+}
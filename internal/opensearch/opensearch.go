@@ -0,0 +1,182 @@
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+)
+
+// Path is where sites conventionally publish their OpenSearch description
+const Path = "/opensearch.xml"
+
+// ErrNotDescription is returned by Parse for data that is not an
+// OpenSearch description
+var ErrNotDescription = errors.New("not an OpenSearch description")
+
+// ErrNoTemplate is returned when a description declares no search URL the
+// tools can read results from
+var ErrNoTemplate = errors.New("no usable search URL template")
+
+// Description is an OpenSearch description document
+type Description struct {
+	XMLName     xml.Name      `xml:"OpenSearchDescription"`
+	URL         string        `xml:"-"`
+	Cached      bool          `xml:"-"`
+	ShortName   string        `xml:"ShortName"`
+	Description string        `xml:"Description"`
+	URLs        []URLTemplate `xml:"Url"`
+}
+
+// URLTemplate is a search URL declared by a description
+type URLTemplate struct {
+	Type        string `xml:"type,attr"`
+	Template    string `xml:"template,attr"`
+	Method      string `xml:"method,attr"`
+	Rel         string `xml:"rel,attr"`
+	IndexOffset string `xml:"indexOffset,attr"`
+	PageOffset  string `xml:"pageOffset,attr"`
+}
+
+// templateParam matches a template parameter such as {searchTerms} or {count?}
+var templateParam = regexp.MustCompile(`\{([^{}]+?)(\??)\}`)
+
+// Load fetches the site's OpenSearch description through the cache,
+// revalidating expired entries
+func Load(ctx context.Context, c *cache.Cache, client *httpclient.Client, siteURL *url.URL) (*Description, error) {
+	descriptionURL := siteURL.ResolveReference(&url.URL{Path: Path}).String()
+	cacheKey := c.BuildKey(siteURL.String(), Path, nil)
+
+	result, err := c.Fetch(ctx, client, cacheKey, descriptionURL, Valid)
+	if err != nil {
+		return nil, fmt.Errorf("no OpenSearch description available: %w", err)
+	}
+
+	description, err := Parse(result.Data)
+	if err != nil {
+		return nil, err
+	}
+	description.URL = descriptionURL
+	description.Cached = result.Cached || result.Revalidated
+	return description, nil
+}
+
+// Valid reports whether data is an OpenSearch description
+func Valid(data []byte) bool {
+	_, err := Parse(data)
+	return err == nil
+}
+
+// Parse parses an OpenSearch description document
+func Parse(data []byte) (*Description, error) {
+	var description Description
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&description); err != nil {
+		return nil, ErrNotDescription
+	}
+	if len(description.URLs) == 0 {
+		return nil, ErrNotDescription
+	}
+	description.ShortName = strings.TrimSpace(description.ShortName)
+	description.Description = strings.TrimSpace(description.Description)
+	return &description, nil
+}
+
+// SearchURL returns the first GET search URL whose results are JSON, RSS
+// or Atom, preferring JSON
+func (d *Description) SearchURL() (*URLTemplate, error) {
+	var feedURL *URLTemplate
+	for i := range d.URLs {
+		u := &d.URLs[i]
+		if (u.Method != "" && !strings.EqualFold(u.Method, "get")) || (u.Rel != "" && u.Rel != "results") {
+			continue
+		}
+		switch {
+		case u.IsJSON():
+			return u, nil
+		case u.IsFeed() && feedURL == nil:
+			feedURL = u
+		}
+	}
+	if feedURL == nil {
+		return nil, ErrNoTemplate
+	}
+	return feedURL, nil
+}
+
+// IsJSON reports whether the URL returns JSON search results. Suggestion
+// lists are not results.
+func (u *URLTemplate) IsJSON() bool {
+	mediaType := u.mediaType()
+	return strings.HasSuffix(mediaType, "json") && !strings.Contains(mediaType, "suggestions")
+}
+
+// IsFeed reports whether the URL returns results as an RSS or Atom feed
+func (u *URLTemplate) IsFeed() bool {
+	switch u.mediaType() {
+	case "application/rss+xml", "application/atom+xml":
+		return true
+	}
+	return false
+}
+
+func (u *URLTemplate) mediaType() string {
+	mediaType, _, _ := strings.Cut(u.Type, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// Expand fills in the template for a search, resolving relative templates
+// against base. Optional parameters the tools don't set are left empty;
+// required ones fail the expansion.
+func (u *URLTemplate) Expand(base *url.URL, searchTerms string, count int) (*url.URL, error) {
+	offset := func(value string) int {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+		return 1
+	}
+	values := map[string]string{
+		"searchTerms":    url.QueryEscape(searchTerms),
+		"startIndex":     strconv.Itoa(offset(u.IndexOffset)),
+		"startPage":      strconv.Itoa(offset(u.PageOffset)),
+		"inputEncoding":  "UTF-8",
+		"outputEncoding": "UTF-8",
+	}
+	if count > 0 {
+		values["count"] = strconv.Itoa(count)
+	}
+
+	var missing []string
+	expanded := templateParam.ReplaceAllStringFunc(u.Template, func(param string) string {
+		match := templateParam.FindStringSubmatch(param)
+		name, optional := match[1], match[2] == "?"
+		if value, ok := values[name]; ok {
+			return value
+		}
+		if !optional {
+			missing = append(missing, name)
+		}
+		return ""
+	})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("template requires unsupported parameters: %s", strings.Join(missing, ", "))
+	}
+
+	ref, err := url.Parse(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	searchURL := base.ResolveReference(ref)
+	if searchURL.Scheme != "http" && searchURL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported template scheme: %q", searchURL.Scheme)
+	}
+	return searchURL, nil
+}
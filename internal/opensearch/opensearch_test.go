@@ -0,0 +1,129 @@
+package opensearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const description = `<?xml version="1.0" encoding="UTF-8"?>
+<OpenSearchDescription xmlns="http://a9.com/-/spec/opensearch/1.1/">
+  <ShortName> Example </ShortName>
+  <Description>Search Example</Description>
+  <Url type="text/html" template="https://example.com/search/?q={searchTerms}"/>
+  <Url type="application/x-suggestions+json" template="/suggest?q={searchTerms}"/>
+  <Url type="application/rss+xml" template="/search.xml?q={searchTerms}"/>
+  <Url type="application/json" template="/search.json?q={searchTerms}&amp;limit={count?}&amp;start={startIndex?}" indexOffset="0"/>
+</OpenSearchDescription>`
+
+func TestParse(t *testing.T) {
+	parsed, err := Parse([]byte(description))
+	require.NoError(t, err)
+	assert.Equal(t, "Example", parsed.ShortName)
+	assert.Len(t, parsed.URLs, 4)
+
+	template, err := parsed.SearchURL()
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", template.Type)
+
+	for _, data := range []string{"", "<rss><channel/></rss>", `<OpenSearchDescription><ShortName>Empty</ShortName></OpenSearchDescription>`} {
+		_, err := Parse([]byte(data))
+		assert.ErrorIs(t, err, ErrNotDescription, data)
+	}
+}
+
+func TestDescription_SearchURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		urls     []URLTemplate
+		wantType string
+		wantErr  bool
+	}{
+		{name: "prefers JSON", urls: []URLTemplate{{Type: "application/atom+xml"}, {Type: "application/feed+json"}}, wantType: "application/feed+json"},
+		{name: "falls back to feeds", urls: []URLTemplate{{Type: "text/html"}, {Type: "application/atom+xml"}}, wantType: "application/atom+xml"},
+		{name: "skips POST and suggestions", urls: []URLTemplate{{Type: "application/json", Method: "POST"}, {Type: "application/json", Rel: "suggestions"}}, wantErr: true},
+		{name: "HTML only", urls: []URLTemplate{{Type: "text/html"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := (&Description{URLs: tt.urls}).SearchURL()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrNoTemplate)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantType, template.Type)
+		})
+	}
+}
+
+func TestURLTemplate_Expand(t *testing.T) {
+	base, err := url.Parse("https://example.com/blog/")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		template URLTemplate
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "relative with optional parameters",
+			template: URLTemplate{Template: "/search.json?q={searchTerms}&limit={count?}&start={startIndex?}&lang={language?}", IndexOffset: "0"},
+			want:     "https://example.com/search.json?q=hugo+themes&limit=20&start=0&lang=",
+		},
+		{
+			name:     "absolute with page offset",
+			template: URLTemplate{Template: "https://search.example.net/?q={searchTerms}&p={startPage}"},
+			want:     "https://search.example.net/?q=hugo+themes&p=1",
+		},
+		{name: "unsupported required parameter", template: URLTemplate{Template: "/search?q={searchTerms}&geo={geo:box}"}, wantErr: true},
+		{name: "unsupported scheme", template: URLTemplate{Template: "javascript:search('{searchTerms}')"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.template.Expand(base, "hugo themes", 20)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != Path {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(description))
+	}))
+	defer server.Close()
+
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	loaded, err := Load(context.Background(), cache.New(), httpclient.New(), siteURL)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+Path, loaded.URL)
+	assert.Equal(t, "Example", loaded.ShortName)
+
+	empty := httptest.NewServer(http.NotFoundHandler())
+	defer empty.Close()
+	emptyURL, err := url.Parse(empty.URL)
+	require.NoError(t, err)
+	_, err = Load(context.Background(), cache.New(), httpclient.New(), emptyURL)
+	assert.Error(t, err)
+}
@@ -0,0 +1,149 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is how command results are written
+type Format string
+
+// Output formats
+const (
+	JSON  Format = "json"
+	Table Format = "table"
+	YAML  Format = "yaml"
+)
+
+// Formats are the supported output formats
+var Formats = []Format{JSON, Table, YAML}
+
+// ParseFormat returns the Format named by s
+func ParseFormat(s string) (Format, error) {
+	format := Format(strings.ToLower(strings.TrimSpace(s)))
+	for _, f := range Formats {
+		if format == f {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("invalid output format %q (must be: json, table, or yaml)", s)
+}
+
+// Render writes v to w in the given format. Tables show an object as
+// key/value rows and a list of objects as one row per object; nested values
+// are shown as compact JSON.
+func Render(w io.Writer, format Format, v any) error {
+	// Round trip through JSON so structs render with their json field names
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	switch format {
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case YAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+		return encoder.Close()
+	case Table:
+		return renderTable(w, value)
+	default:
+		return fmt.Errorf("invalid output format %q", format)
+	}
+}
+
+// renderTable writes value as aligned columns
+func renderTable(w io.Writer, value any) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	switch value := value.(type) {
+	case map[string]any:
+		fmt.Fprintln(tw, "KEY\tVALUE")
+		for _, key := range sortedKeys(value) {
+			fmt.Fprintf(tw, "%s\t%s\n", key, cell(value[key]))
+		}
+	case []any:
+		columns := tableColumns(value)
+		if len(columns) == 0 {
+			for _, item := range value {
+				fmt.Fprintln(tw, cell(item))
+			}
+			break
+		}
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, item := range value {
+			row, _ := item.(map[string]any)
+			cells := make([]string, len(columns))
+			for i, column := range columns {
+				cells[i] = cell(row[column])
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+	default:
+		fmt.Fprintln(tw, cell(value))
+	}
+
+	return tw.Flush()
+}
+
+// tableColumns returns the keys of the objects in a list, in sorted order,
+// or none if the list holds anything but objects
+func tableColumns(items []any) []string {
+	seen := make(map[string]bool)
+	var columns []string
+	for _, item := range items {
+		row, ok := item.(map[string]any)
+		if !ok {
+			return nil
+		}
+		for key := range row {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// cell formats a value for a table cell
+func cell(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.Join(strings.Fields(value), " ")
+	case map[string]any, []any:
+		data, _ := json.Marshal(value)
+		return string(data)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{input: "json", want: JSON},
+		{input: "Table", want: Table},
+		{input: " yaml ", want: YAML},
+		{input: "xml", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseFormat(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	type page struct {
+		Title string   `json:"title"`
+		URL   string   `json:"url"`
+		Tags  []string `json:"tags,omitempty"`
+	}
+	pages := []page{
+		{Title: "Learning Go", URL: "/posts/go/", Tags: []string{"go"}},
+		{Title: "About", URL: "/about/"},
+	}
+
+	tests := []struct {
+		name   string
+		format Format
+		value  any
+		want   string
+	}{
+		{
+			name:   "json",
+			format: JSON,
+			value:  map[string]any{"git_commit": "abc123"},
+			want:   "{\n  \"git_commit\": \"abc123\"\n}\n",
+		},
+		{
+			name:   "yaml",
+			format: YAML,
+			value:  pages[:1],
+			want:   "- tags:\n    - go\n  title: Learning Go\n  url: /posts/go/\n",
+		},
+		{
+			name:   "table of an object",
+			format: Table,
+			value:  map[string]any{"git_commit": "abc123", "count": 2},
+			want:   "KEY         VALUE\ncount       2\ngit_commit  abc123\n",
+		},
+		{
+			name:   "table of objects",
+			format: Table,
+			value:  pages,
+			want:   "TAGS    TITLE        URL\n[\"go\"]  Learning Go  /posts/go/\n        About        /about/\n",
+		},
+		{
+			name:   "table of a scalar",
+			format: Table,
+			value:  "abc123",
+			want:   "abc123\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Render(&buf, tt.format, tt.value))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+
+	assert.Error(t, Render(&bytes.Buffer{}, Format("xml"), pages))
+}
@@ -0,0 +1,151 @@
+package pagelinks
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+)
+
+// Link types
+const (
+	Internal = "internal"
+	External = "external"
+	Anchor   = "anchor"
+)
+
+// Body sources
+const (
+	SourceSiteIndex = "site_index"
+	SourcePageHTML  = "page_html"
+)
+
+// contentFields are the index fields that may hold a page's body, in order
+// of preference
+var contentFields = []string{"content", "html", "body"}
+
+// Link is a link resolved against the page it appears on
+type Link struct {
+	Href     string `json:"href"`
+	URL      string `json:"url"`
+	Text     string `json:"text"`
+	Type     string `json:"type"`
+	Path     string `json:"path,omitempty"`
+	Section  string `json:"section,omitempty"`
+	Host     string `json:"host,omitempty"`
+	Scheme   string `json:"scheme,omitempty"`
+	Fragment string `json:"fragment,omitempty"`
+}
+
+// Body is the body of a page and where it was read from
+type Body struct {
+	Content  string
+	Source   string
+	Endpoint string
+	Cached   bool
+}
+
+// PageURL returns the URL of the page at path, with the trailing slash of
+// Hugo's pretty URLs so relative links resolve as they do in a browser
+func PageURL(siteURL *url.URL, path string) *url.URL {
+	path = "/" + strings.TrimPrefix(path, "/")
+	if last := path[strings.LastIndex(path, "/")+1:]; last != "" && !strings.Contains(last, ".") {
+		path += "/"
+	}
+	return siteURL.ResolveReference(&url.URL{Path: path})
+}
+
+// Fetch returns the body of the page at pageURL, preferring HTML in the
+// site index, then the rendered page, then whatever body the index holds.
+// index may be nil when the site has none.
+func Fetch(ctx context.Context, c *cache.Cache, client *httpclient.Client, index *hugoindex.SiteIndex, siteURL, pageURL *url.URL) (*Body, error) {
+	var indexBody *Body
+	if index != nil {
+		page, found, err := index.Page(ctx, pageURL.Path)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			for _, field := range contentFields {
+				if value := page.Get(field).String(); value != "" {
+					indexBody = &Body{Content: value, Source: SourceSiteIndex, Endpoint: index.URL(), Cached: index.Cached()}
+					break
+				}
+			}
+		}
+	}
+	if indexBody != nil && htmltext.LooksLikeHTML(indexBody.Content) {
+		return indexBody, nil
+	}
+
+	cacheKey := c.BuildKey(siteURL.String(), pageURL.Path, nil)
+	result, err := c.Fetch(ctx, client, cacheKey, pageURL.String(), func(data []byte) bool {
+		return htmltext.LooksLikeHTML(string(data))
+	})
+	if err == nil {
+		return &Body{Content: string(result.Data), Source: SourcePageHTML, Endpoint: pageURL.String(), Cached: result.Cached}, nil
+	}
+	if indexBody != nil {
+		return indexBody, nil
+	}
+	return nil, err
+}
+
+// Resolve resolves links against the page at pageURL and classifies them.
+// Script links are dropped; with unique, so are repeated targets.
+func Resolve(links []htmltext.Link, siteURL, pageURL *url.URL, unique bool) []Link {
+	seen := make(map[string]bool)
+	result := []Link{}
+
+	for _, link := range links {
+		if link.Href == "" {
+			continue
+		}
+		target, err := url.Parse(link.Href)
+		if err != nil || target.Scheme == "javascript" {
+			continue
+		}
+		resolved := pageURL.ResolveReference(target)
+
+		entry := Link{Href: link.Href, URL: resolved.String(), Text: link.Text}
+		onSite := resolved.Host == siteURL.Host && (resolved.Scheme == "http" || resolved.Scheme == "https")
+		switch {
+		case onSite && resolved.Fragment != "" && strings.TrimSuffix(resolved.Path, "/") == strings.TrimSuffix(pageURL.Path, "/"):
+			entry.Type = Anchor
+			entry.Fragment = resolved.Fragment
+		case onSite:
+			entry.Type = Internal
+			entry.Path = resolved.Path
+			entry.Section, _, _ = strings.Cut(strings.Trim(resolved.Path, "/"), "/")
+		default:
+			entry.Type = External
+			if resolved.Host != "" {
+				entry.Host = resolved.Host
+			} else {
+				entry.Scheme = resolved.Scheme
+			}
+		}
+
+		if unique {
+			if seen[entry.URL] {
+				continue
+			}
+			seen[entry.URL] = true
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// Count returns the number of links of each type
+func Count(links []Link) map[string]int {
+	counts := map[string]int{Internal: 0, External: 0, Anchor: 0}
+	for _, link := range links {
+		counts[link.Type]++
+	}
+	return counts
+}
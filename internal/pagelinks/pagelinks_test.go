@@ -0,0 +1,93 @@
+package pagelinks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageURL(t *testing.T) {
+	siteURL, _ := url.Parse("https://example.com")
+	assert.Equal(t, "https://example.com/posts/first/", PageURL(siteURL, "posts/first").String())
+	assert.Equal(t, "https://example.com/posts/first/", PageURL(siteURL, "/posts/first/").String())
+	assert.Equal(t, "https://example.com/about.html", PageURL(siteURL, "/about.html").String())
+	assert.Equal(t, "https://example.com/", PageURL(siteURL, "/").String())
+}
+
+func TestResolve(t *testing.T) {
+	siteURL, _ := url.Parse("https://example.com")
+	pageURL, _ := url.Parse("https://example.com/posts/first/")
+
+	links := Resolve([]htmltext.Link{
+		{Href: "../second/", Text: "Second"},
+		{Href: "/docs/setup/#install", Text: "Install"},
+		{Href: "https://example.com/about/", Text: "About"},
+		{Href: "#comments", Text: "Comments"},
+		{Href: "/posts/first/#top", Text: "Top"},
+		{Href: "https://gohugo.io/", Text: "Hugo"},
+		{Href: "mailto:me@example.com", Text: "Email"},
+		{Href: "javascript:void(0)", Text: "Menu"},
+		{Href: "/posts/second/", Text: "Second again"},
+	}, siteURL, pageURL, true)
+
+	assert.Equal(t, map[string]int{Internal: 3, External: 2, Anchor: 2}, Count(links))
+	require.Len(t, links, 7)
+	assert.Equal(t, "https://example.com/posts/second/", links[0].URL)
+	assert.Equal(t, "posts", links[0].Section)
+	assert.Equal(t, "docs", links[1].Section)
+	assert.Equal(t, Anchor, links[3].Type)
+	assert.Equal(t, "comments", links[3].Fragment)
+	assert.Equal(t, Anchor, links[4].Type)
+	assert.Equal(t, "gohugo.io", links[5].Host)
+	assert.Equal(t, "mailto", links[6].Scheme)
+
+	assert.Len(t, Resolve([]htmltext.Link{{Href: "/a/"}, {Href: "/a/"}}, siteURL, pageURL, false), 2)
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/posts/rendered/":
+			w.Write([]byte(`<html><body><main><a href="/">Home</a></main></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	siteURL, _ := url.Parse(server.URL)
+	index := hugoindex.New(server.URL+"/index.json", []byte(`[
+		{"url": "/posts/indexed/", "content": "<p>Indexed</p>"},
+		{"url": "/posts/rendered/", "content": "Plain"},
+		{"url": "/posts/plain/", "content": "[Markdown](/x/)"}
+	]`))
+	c := cache.New()
+	client := httpclient.New()
+
+	body, err := Fetch(context.Background(), c, client, index, siteURL, PageURL(siteURL, "/posts/indexed/"))
+	require.NoError(t, err)
+	assert.Equal(t, SourceSiteIndex, body.Source)
+	assert.Equal(t, "<p>Indexed</p>", body.Content)
+
+	body, err = Fetch(context.Background(), c, client, index, siteURL, PageURL(siteURL, "/posts/rendered/"))
+	require.NoError(t, err)
+	assert.Equal(t, SourcePageHTML, body.Source)
+
+	// Bodies in the index that are not HTML are a last resort
+	body, err = Fetch(context.Background(), c, client, index, siteURL, PageURL(siteURL, "/posts/plain/"))
+	require.NoError(t, err)
+	assert.Equal(t, SourceSiteIndex, body.Source)
+	assert.Equal(t, "[Markdown](/x/)", body.Content)
+
+	_, err = Fetch(context.Background(), c, client, nil, siteURL, PageURL(siteURL, "/missing/"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package prompts
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// Prompt names
+const (
+	SummarizeSiteName = "hugo_reader_summarize_site"
+	FindPostsName     = "hugo_reader_find_posts"
+	AuditSiteName     = "hugo_reader_audit_site"
+)
+
+// Registrar registers MCP prompts; *mcp_golang.Server satisfies it
+type Registrar interface {
+	RegisterPrompt(name string, description string, handler any) error
+}
+
+// SiteArguments are the arguments of prompts about a whole site. Prompt
+// arguments are advertised by field name, so fields carry no json tags.
+type SiteArguments struct {
+	Site string `jsonschema:"required,description=Complete URL of the Hugo site (e.g. https://example.com)"`
+}
+
+// TopicArguments are the arguments of prompts about a topic on a site
+type TopicArguments struct {
+	Site  string `jsonschema:"required,description=Complete URL of the Hugo site (e.g. https://example.com)"`
+	Topic string `jsonschema:"required,description=What the posts should be about"`
+}
+
+// Register registers the exploration prompts
+func Register(server Registrar) error {
+	prompts := []struct {
+		name        string
+		description string
+		handler     any
+	}{
+		{SummarizeSiteName, "Summarize a Hugo site: what it covers, how it is organized and what it published recently.", SummarizeSite},
+		{FindPostsName, "Find the posts on a Hugo site about a topic, using its search, taxonomies and content.", FindPosts},
+		{AuditSiteName, "Audit a Hugo site's content: orphan pages, broken links and recent changes.", AuditSite},
+	}
+	for _, prompt := range prompts {
+		if err := server.RegisterPrompt(prompt.name, prompt.description, prompt.handler); err != nil {
+			return fmt.Errorf("failed to register %s prompt: %w", prompt.name, err)
+		}
+	}
+	return nil
+}
+
+// SummarizeSite guides a client through discovering a site and summarizing it
+func SummarizeSite(args SiteArguments) (*mcp_golang.PromptResponse, error) {
+	site, err := siteURL(args.Site)
+	if err != nil {
+		return nil, err
+	}
+
+	return response(fmt.Sprintf("Summarize the Hugo site %s", site), `Summarize the Hugo site at %[1]s using the hugo-reader tools, with hugo_site_path set to %[1]s:
+
+1. Call hugo_reader_probe to learn what the site offers and which strategy each tool will use.
+2. Call hugo_reader_discover_site with discovery_type "overview", then "sections", to see how the content is organized.
+3. Call hugo_reader_get_taxonomies to list its taxonomies, and hugo_reader_get_taxonomy_terms for the main ones (such as tags or categories) to find its most common subjects.
+4. Call hugo_reader_discover_site with discovery_type "pages" to find the most recent pages.
+5. Call hugo_reader_get_content with summary_only set to true for up to five representative recent pages.
+
+Then write a summary covering what the site is about, its sections and main subjects, how often it publishes and what it published recently. Cite pages by title and URL. If a step fails, say which and carry on with what the other steps found.`, site), nil
+}
+
+// FindPosts guides a client through finding the posts about a topic
+func FindPosts(args TopicArguments) (*mcp_golang.PromptResponse, error) {
+	site, err := siteURL(args.Site)
+	if err != nil {
+		return nil, err
+	}
+	topic := strings.TrimSpace(args.Topic)
+	if topic == "" {
+		return nil, fmt.Errorf("Topic is required")
+	}
+
+	return response(fmt.Sprintf("Find posts about %q on %s", topic, site), `Find the posts about %[2]q on the Hugo site at %[1]s using the hugo-reader tools, with hugo_site_path set to %[1]s:
+
+1. Call hugo_reader_search with the query %[2]q, then again with one or two closely related terms.
+2. Call hugo_reader_get_taxonomies, then hugo_reader_get_taxonomy_terms for the taxonomies likely to classify it (such as tags or categories), and note any term matching %[2]q.
+3. For each matching term, call hugo_reader_search with the term as the query, filtered by that taxonomy and term, to list the posts filed under it.
+4. Call hugo_reader_get_content with summary_only set to true for the posts found, to confirm each is really about %[2]q.
+
+Then list the relevant posts, most relevant first, each with its title, URL, date and a sentence on how it covers %[2]q. Say if nothing relevant was found rather than listing loosely related pages.`, site, topic), nil
+}
+
+// AuditSite guides a client through auditing a site's content
+func AuditSite(args SiteArguments) (*mcp_golang.PromptResponse, error) {
+	site, err := siteURL(args.Site)
+	if err != nil {
+		return nil, err
+	}
+
+	return response(fmt.Sprintf("Audit the Hugo site %s", site), `Audit the content of the Hugo site at %[1]s using the hugo-reader tools, with hugo_site_path set to %[1]s:
+
+1. Call hugo_reader_probe to learn what the site offers.
+2. Call hugo_reader_site_graph to find orphan pages that no other page links to, and the most linked pages.
+3. Call hugo_reader_check_links to find broken internal and external links.
+4. Call hugo_reader_detect_changes to see what was added, changed or removed since the last snapshot.
+
+Then report the problems found, most important first: broken links with the pages they are on, orphan pages worth linking to, and missing capabilities such as a sitemap or feed. Finish with a short list of suggested fixes.`, site), nil
+}
+
+// response returns a prompt of a single user message
+func response(description, format string, args ...any) *mcp_golang.PromptResponse {
+	return mcp_golang.NewPromptResponse(description,
+		mcp_golang.NewPromptMessage(mcp_golang.NewTextContent(fmt.Sprintf(format, args...)), mcp_golang.RoleUser))
+}
+
+// siteURL validates a site argument, defaulting to https
+func siteURL(site string) (string, error) {
+	site = strings.TrimSpace(site)
+	if site == "" {
+		return "", fmt.Errorf("Site is required")
+	}
+	if !strings.Contains(site, "://") {
+		site = "https://" + site
+	}
+	u, err := url.Parse(site)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid Site URL: %s", site)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported Site URL scheme: %s", u.Scheme)
+	}
+	return u.String(), nil
+}
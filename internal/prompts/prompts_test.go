@@ -0,0 +1,88 @@
+package prompts
+
+import (
+	"testing"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport/stdio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+	server := mcp_golang.NewServer(stdio.NewStdioServerTransport())
+	require.NoError(t, Register(server))
+
+	for _, name := range []string{SummarizeSiteName, FindPostsName, AuditSiteName} {
+		assert.True(t, server.CheckPromptRegistered(name), name)
+	}
+}
+
+func TestPrompts(t *testing.T) {
+	tests := []struct {
+		name     string
+		prompt   func() (*mcp_golang.PromptResponse, error)
+		contains []string
+		wantErr  bool
+	}{
+		{
+			name: "summarize site",
+			prompt: func() (*mcp_golang.PromptResponse, error) {
+				return SummarizeSite(SiteArguments{Site: "https://example.com"})
+			},
+			contains: []string{"hugo_site_path set to https://example.com", "hugo_reader_probe", "hugo_reader_get_taxonomies", "summary_only"},
+		},
+		{
+			name:     "summarize site without scheme",
+			prompt:   func() (*mcp_golang.PromptResponse, error) { return SummarizeSite(SiteArguments{Site: " example.com "}) },
+			contains: []string{"https://example.com"},
+		},
+		{
+			name:    "summarize site without site",
+			prompt:  func() (*mcp_golang.PromptResponse, error) { return SummarizeSite(SiteArguments{}) },
+			wantErr: true,
+		},
+		{
+			name: "find posts",
+			prompt: func() (*mcp_golang.PromptResponse, error) {
+				return FindPosts(TopicArguments{Site: "http://localhost:1313", Topic: "golang"})
+			},
+			contains: []string{`posts about "golang"`, "http://localhost:1313", "hugo_reader_search", "hugo_reader_get_taxonomy_terms"},
+		},
+		{
+			name: "find posts without topic",
+			prompt: func() (*mcp_golang.PromptResponse, error) {
+				return FindPosts(TopicArguments{Site: "https://example.com"})
+			},
+			wantErr: true,
+		},
+		{
+			name: "audit site",
+			prompt: func() (*mcp_golang.PromptResponse, error) {
+				return AuditSite(SiteArguments{Site: "https://example.com/blog/"})
+			},
+			contains: []string{"https://example.com/blog/", "hugo_reader_site_graph", "hugo_reader_check_links", "hugo_reader_detect_changes"},
+		},
+		{
+			name:    "audit site with unsupported scheme",
+			prompt:  func() (*mcp_golang.PromptResponse, error) { return AuditSite(SiteArguments{Site: "ftp://example.com"}) },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := tt.prompt()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, response.Messages, 1)
+			assert.Equal(t, mcp_golang.RoleUser, response.Messages[0].Role)
+			for _, text := range tt.contains {
+				assert.Contains(t, response.Messages[0].Content.TextContent.Text, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/tidwall/gjson"
+)
+
+// MimeType is the type of page resources, which are read as Markdown
+const MimeType = "text/markdown"
+
+// DefaultMaxPages is how many pages of a site are registered by default
+const DefaultMaxPages = 500
+
+// maxDescriptionLength is the longest description given to a resource
+const maxDescriptionLength = 200
+
+// Registrar registers MCP resources; *mcp_golang.Server satisfies it
+type Registrar interface {
+	RegisterResource(uri string, name string, description string, mimeType string, handler any) error
+}
+
+// ReadFunc returns the page at path of a site as Markdown
+type ReadFunc func(ctx context.Context, siteURL *url.URL, path string) (string, error)
+
+// Page is a site page exposed as a resource
+type Page struct {
+	URI         string
+	Path        string
+	Name        string
+	Description string
+}
+
+// Option configures a Registry
+type Option func(*Registry)
+
+// Registry exposes the pages of Hugo sites as MCP resources
+type Registry struct {
+	log        *slog.Logger
+	cache      *cache.Cache
+	httpClient *httpclient.Client
+	read       ReadFunc
+	maxPages   int
+}
+
+// New creates a Registry that reads pages with read
+func New(read ReadFunc, opts ...Option) *Registry {
+	r := &Registry{
+		log:        slog.Default().With("component", "resources"),
+		cache:      cache.New(),
+		httpClient: httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		read:       read,
+		maxPages:   DefaultMaxPages,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithLogger sets the logger for the Registry
+func WithLogger(logger *slog.Logger) Option {
+	return func(r *Registry) {
+		r.log = logger.With("component", "resources")
+	}
+}
+
+// WithCache sets the cache site indexes are read through
+func WithCache(c *cache.Cache) Option {
+	return func(r *Registry) {
+		r.cache = c
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Registry
+func WithHTTPClient(c *httpclient.Client) Option {
+	return func(r *Registry) {
+		r.httpClient = c
+	}
+}
+
+// WithMaxPages limits how many pages of each site are registered. Zero
+// means unlimited.
+func WithMaxPages(n int) Option {
+	return func(r *Registry) {
+		r.maxPages = n
+	}
+}
+
+// Register registers the published pages listed in a site's index as
+// resources, each read when a client asks for it, and returns how many
+// were registered
+func (r *Registry) Register(ctx context.Context, server Registrar, siteURL *url.URL) (int, error) {
+	index, err := hugoindex.Load(ctx, r.cache, r.httpClient, siteURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load site index: %w", err)
+	}
+
+	pages, err := r.Pages(ctx, index, siteURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read site index: %w", err)
+	}
+
+	for i, page := range pages {
+		page := page
+		handler := func(ctx context.Context) (*mcp_golang.ResourceResponse, error) {
+			text, err := r.read(ctx, siteURL, page.Path)
+			if err != nil {
+				r.log.WarnContext(ctx, "Failed to read page resource", "uri", page.URI, "error", err)
+				return nil, err
+			}
+			return mcp_golang.NewResourceResponse(mcp_golang.NewTextEmbeddedResource(page.URI, text, MimeType)), nil
+		}
+		if err := server.RegisterResource(page.URI, page.Name, page.Description, MimeType, handler); err != nil {
+			return i, fmt.Errorf("failed to register resource %s: %w", page.URI, err)
+		}
+	}
+
+	r.log.InfoContext(ctx, "Registered page resources", "site", siteURL.String(), "pages", len(pages), "index", index.URL())
+	return len(pages), nil
+}
+
+// Pages returns the published pages of a site's index that belong to the
+// site, up to the page limit
+func (r *Registry) Pages(ctx context.Context, index *hugoindex.SiteIndex, siteURL *url.URL) ([]Page, error) {
+	now := time.Now()
+	seen := make(map[string]bool)
+	var pages []Page
+
+	err := index.Pages(ctx, func(page gjson.Result) bool {
+		if !(hugoindex.PublishOptions{}).Allows(page, now) {
+			return true
+		}
+		rawURL := hugoindex.PageURL(page)
+		if rawURL == "" {
+			return true
+		}
+		pageURL, err := siteURL.Parse(rawURL)
+		if err != nil || pageURL.Host != siteURL.Host {
+			return true
+		}
+		pageURL.RawQuery, pageURL.Fragment = "", ""
+		uri := pageURL.String()
+		if seen[uri] {
+			return true
+		}
+		seen[uri] = true
+
+		name := strings.TrimSpace(page.Get("title").String())
+		if name == "" {
+			name = pageURL.Path
+		}
+		pages = append(pages, Page{
+			URI:         uri,
+			Path:        pageURL.Path,
+			Name:        name,
+			Description: description(page),
+		})
+		return r.maxPages == 0 || len(pages) < r.maxPages
+	})
+	return pages, err
+}
+
+// description returns a short plain text description of a page from its
+// description or summary
+func description(page gjson.Result) string {
+	text := page.Get("description").String()
+	if text == "" {
+		text = page.Get("summary").String()
+	}
+	if htmltext.LooksLikeHTML(text) {
+		text = htmltext.Text(text)
+	}
+	text = strings.Join(strings.Fields(text), " ")
+
+	if runes := []rune(text); len(runes) > maxDescriptionLength {
+		text = strings.TrimSpace(string(runes[:maxDescriptionLength-1])) + "…"
+	}
+	return text
+}
+
+// Sites parses a list of site URLs separated by commas or whitespace,
+// defaulting to https
+func Sites(value string) ([]*url.URL, error) {
+	var sites []*url.URL
+	for _, raw := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	}) {
+		siteURL, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource site %q: %w", raw, err)
+		}
+		if siteURL.Scheme == "" {
+			siteURL, err = url.Parse("https://" + raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid resource site %q: %w", raw, err)
+			}
+		}
+		if siteURL.Host == "" {
+			return nil, fmt.Errorf("invalid resource site %q: missing host", raw)
+		}
+		sites = append(sites, siteURL)
+	}
+	return sites, nil
+}
@@ -0,0 +1,133 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type resource struct {
+	name        string
+	description string
+	mimeType    string
+	handler     func(ctx context.Context) (*mcp_golang.ResourceResponse, error)
+}
+
+type registrar map[string]resource
+
+func (r registrar) RegisterResource(uri string, name string, description string, mimeType string, handler any) error {
+	r[uri] = resource{name, description, mimeType, handler.(func(ctx context.Context) (*mcp_golang.ResourceResponse, error))}
+	return nil
+}
+
+func TestRegistry_Register(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[
+			{"title": "Hello", "url": "/posts/hello/", "summary": "<p>A <em>first</em> post</p>"},
+			{"title": "Hello again", "url": "/posts/hello/#comments"},
+			{"url": "/about/", "description": "About this site"},
+			{"title": "Draft", "url": "/posts/draft/", "draft": true},
+			{"title": "Elsewhere", "url": "https://elsewhere.example.com/post/"},
+			{"title": "No URL"}
+		]`))
+	}))
+	defer server.Close()
+
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	read := func(ctx context.Context, siteURL *url.URL, path string) (string, error) {
+		if path == "/about/" {
+			return "", fmt.Errorf("content not found")
+		}
+		return "# " + path + "\n", nil
+	}
+	registry := New(read, WithHTTPClient(httpclient.New(httpclient.WithRetryPolicy(httpclient.RetryPolicy{}))))
+
+	registered := registrar{}
+	count, err := registry.Register(context.Background(), registered, siteURL)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	hello := registered[server.URL+"/posts/hello/"]
+	assert.Equal(t, "Hello", hello.name)
+	assert.Equal(t, "A first post", hello.description)
+	assert.Equal(t, MimeType, hello.mimeType)
+
+	response, err := hello.handler(context.Background())
+	require.NoError(t, err)
+	require.Len(t, response.Contents, 1)
+	assert.Equal(t, server.URL+"/posts/hello/", response.Contents[0].TextResourceContents.Uri)
+	assert.Equal(t, "# /posts/hello/\n", response.Contents[0].TextResourceContents.Text)
+
+	about := registered[server.URL+"/about/"]
+	assert.Equal(t, "/about/", about.name)
+	assert.Equal(t, "About this site", about.description)
+	_, err = about.handler(context.Background())
+	assert.Error(t, err)
+
+	// The page limit caps how many pages are registered
+	registered = registrar{}
+	count, err = New(read, WithMaxPages(1)).Register(context.Background(), registered, siteURL)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Len(t, registered, 1)
+
+	// Sites without an index cannot be registered
+	empty := httptest.NewServer(http.NotFoundHandler())
+	defer empty.Close()
+	missingURL, err := url.Parse(empty.URL)
+	require.NoError(t, err)
+	_, err = registry.Register(context.Background(), registrar{}, missingURL)
+	assert.Error(t, err)
+}
+
+func TestDescription(t *testing.T) {
+	long := strings.Repeat("word ", 100)
+	tests := []struct {
+		name string
+		page string
+		want string
+	}{
+		{name: "description", page: `{"description": "Short", "summary": "Longer summary"}`, want: "Short"},
+		{name: "HTML summary", page: `{"summary": "<p>Some <b>bold</b>\n text</p>"}`, want: "Some bold text"},
+		{name: "none", page: `{}`, want: ""},
+		{name: "truncated", page: `{"description": "` + long + `"}`, want: strings.TrimSpace(long[:maxDescriptionLength-1]) + "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, description(gjson.Parse(tt.page)))
+		})
+	}
+}
+
+func TestSites(t *testing.T) {
+	sites, err := Sites("https://example.com, blog.example.org\nhttp://localhost:1313/")
+	require.NoError(t, err)
+	require.Len(t, sites, 3)
+	assert.Equal(t, "https://example.com", sites[0].String())
+	assert.Equal(t, "https://blog.example.org", sites[1].String())
+	assert.Equal(t, "http://localhost:1313/", sites[2].String())
+
+	sites, err = Sites("")
+	require.NoError(t, err)
+	assert.Empty(t, sites)
+
+	_, err = Sites("https://")
+	assert.Error(t, err)
+}
@@ -0,0 +1,101 @@
+package searchbackend
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+)
+
+// AlgoliaMaxHits is the most hits Algolia returns for one query
+const AlgoliaMaxHits = 1000
+
+// appIDPattern is the form of Algolia application IDs
+var appIDPattern = regexp.MustCompile(`^[A-Za-z0-9]{1,32}$`)
+
+// Algolia is the Algolia index a site publishes its pages to
+type Algolia struct {
+	AppID     string `json:"app_id" mapstructure:"app_id" jsonschema:"title=Algolia Application ID"`
+	SearchKey string `json:"search_key,omitempty" mapstructure:"search_key" jsonschema:"title=Algolia Search-Only API Key"`
+	IndexName string `json:"index" mapstructure:"index" jsonschema:"title=Algolia Index Name"`
+
+	// Host replaces the application's Algolia host, for proxies
+	Host string `json:"host,omitempty" mapstructure:"host" jsonschema:"title=Algolia Host (default: https://APP_ID-dsn.algolia.net)"`
+}
+
+// algoliaResponse is Algolia's query response
+type algoliaResponse struct {
+	Hits   []map[string]interface{} `json:"hits"`
+	NbHits int                      `json:"nbHits"`
+}
+
+// Name implements SearchBackend
+func (a *Algolia) Name() string {
+	return "algolia"
+}
+
+// Index implements SearchBackend
+func (a *Algolia) Index() string {
+	return a.IndexName
+}
+
+// Validate implements SearchBackend
+func (a *Algolia) Validate() error {
+	if !appIDPattern.MatchString(a.AppID) {
+		return fmt.Errorf("app_id must be 1-32 letters or digits")
+	}
+	if a.SearchKey == "" {
+		return fmt.Errorf("search_key is required")
+	}
+	if err := validateKey(a.SearchKey); err != nil {
+		return err
+	}
+	if a.IndexName == "" {
+		return fmt.Errorf("index is required")
+	}
+	return validateHost(a.Host)
+}
+
+// QueryURL implements SearchBackend
+func (a *Algolia) QueryURL() string {
+	host := a.Host
+	if host == "" {
+		host = "https://" + strings.ToLower(a.AppID) + "-dsn.algolia.net"
+	}
+	return joinURL(host, "1", "indexes", a.IndexName, "query")
+}
+
+// Redacted returns the configuration without its key, for reporting
+func (a Algolia) Redacted() *Algolia {
+	a.SearchKey = ""
+	return &a
+}
+
+// Search implements SearchBackend
+func (a *Algolia) Search(ctx context.Context, client *httpclient.Client, q string, hits int) (*Result, error) {
+	req, err := postJSON(ctx, a.QueryURL(), map[string]interface{}{
+		"query":       q,
+		"hitsPerPage": max(1, min(hits, AlgoliaMaxHits)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Algolia query: %w", err)
+	}
+
+	var decoded algoliaResponse
+	err = query(client, req, map[string]string{
+		"X-Algolia-Application-Id": a.AppID,
+		"X-Algolia-API-Key":        a.SearchKey,
+	}, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("algolia: %w", err)
+	}
+	if decoded.Hits == nil {
+		return nil, fmt.Errorf("invalid Algolia response: %w", cache.ErrInvalidResponse)
+	}
+
+	hitList := normalize(decoded.Hits, "_highlightResult", "_snippetResult", "_rankingInfo", "_distinctSeqID")
+	return &Result{Hits: hitList, Total: decoded.NbHits}, nil
+}
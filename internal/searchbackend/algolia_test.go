@@ -0,0 +1,74 @@
+package searchbackend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlgolia_Validate(t *testing.T) {
+	valid := Algolia{AppID: "ABC123", SearchKey: "key", IndexName: "blog"}
+	assert.NoError(t, valid.Validate())
+
+	for name, config := range map[string]Algolia{
+		"app id":   {AppID: "not valid", SearchKey: "key", IndexName: "blog"},
+		"key":      {AppID: "ABC123", IndexName: "blog"},
+		"index":    {AppID: "ABC123", SearchKey: "key"},
+		"host":     {AppID: "ABC123", SearchKey: "key", IndexName: "blog", Host: "ftp://example.com"},
+		"key body": {AppID: "ABC123", SearchKey: "key\r\nX: 1", IndexName: "blog"},
+	} {
+		assert.Error(t, config.Validate(), name)
+	}
+}
+
+func TestAlgolia_QueryURL(t *testing.T) {
+	config := Algolia{AppID: "ABC123", SearchKey: "key", IndexName: "blog posts"}
+	assert.Equal(t, "https://abc123-dsn.algolia.net/1/indexes/blog%20posts/query", config.QueryURL())
+
+	config.Host = "https://search.example.com/"
+	assert.Equal(t, "https://search.example.com/1/indexes/blog%20posts/query", config.QueryURL())
+	assert.Empty(t, config.Redacted().SearchKey)
+	assert.Equal(t, "key", config.SearchKey)
+}
+
+func TestAlgolia_Search(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Algolia-API-Key") != "key" || r.Header.Get("X-Algolia-Application-Id") != "ABC123" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/1/indexes/blog/query", r.URL.Path)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]interface{}{"query": "golang", "hitsPerPage": float64(5)}, body)
+
+		w.Write([]byte(`{"nbHits": 12, "hits": [
+			{"objectID": "1", "title": "Learning Go", "permalink": "https://example.com/posts/go/", "_highlightResult": {"title": {}}},
+			{"objectID": "2", "title": "About", "url": "/about/", "uri": "/ignored/"}
+		]}`))
+	}))
+	defer server.Close()
+
+	config := &Algolia{AppID: "ABC123", SearchKey: "key", IndexName: "blog", Host: server.URL}
+	result, err := config.Search(context.Background(), httpclient.New(), "golang", 5)
+	require.NoError(t, err)
+	assert.Equal(t, 12, result.Total)
+	assert.Equal(t, []map[string]interface{}{
+		{"objectID": "1", "title": "Learning Go", "permalink": "https://example.com/posts/go/", "url": "https://example.com/posts/go/"},
+		{"objectID": "2", "title": "About", "url": "/about/", "uri": "/ignored/"},
+	}, result.Hits)
+
+	config.SearchKey = "wrong"
+	_, err = config.Search(context.Background(), httpclient.New(), "golang", 5)
+	var status *cache.StatusError
+	require.ErrorAs(t, err, &status)
+	assert.Equal(t, http.StatusForbidden, status.StatusCode)
+}
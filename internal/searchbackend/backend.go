@@ -0,0 +1,188 @@
+// Package searchbackend queries the hosted search engines Hugo sites commonly
+// index their pages into, such as Algolia, Meilisearch and Typesense, with
+// the search-only key the site's own search page uses.
+package searchbackend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+)
+
+// SearchBackend is a search engine holding the pages of a site
+type SearchBackend interface {
+	// Name is the search method reported for results of the backend
+	Name() string
+
+	// Index names the index or collection of the site's pages
+	Index() string
+
+	// QueryURL returns the URL queries are sent to
+	QueryURL() string
+
+	// Validate checks the configuration is complete
+	Validate() error
+
+	// Search returns the first hits pages of the index matching query
+	Search(ctx context.Context, client *httpclient.Client, query string, hits int) (*Result, error)
+}
+
+// Result is the response of a query
+type Result struct {
+	// Hits are the pages found, with a url field when the hit holds one
+	// under another name and without the backend's highlighting
+	Hits []map[string]interface{}
+
+	// Total is the number of pages matching the query, which some backends
+	// estimate
+	Total int
+}
+
+// urlFields are the hit fields that may hold a page's URL, in order of
+// preference; Hugo's search output formats commonly use uri or permalink
+var urlFields = []string{"url", "permalink", "relpermalink", "uri"}
+
+// validateHost checks host, when set, is an http or https URL
+func validateHost(host string) error {
+	if host == "" {
+		return nil
+	}
+	u, err := url.Parse(host)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("host must be an http or https URL")
+	}
+	return nil
+}
+
+// validateKey checks key, when set, can be sent as a header
+func validateKey(key string) error {
+	if strings.ContainsAny(key, "\r\n") {
+		return fmt.Errorf("search_key must be a single line")
+	}
+	return nil
+}
+
+// joinURL returns host with the path segments appended, each escaped
+func joinURL(host string, segments ...string) string {
+	u := strings.TrimRight(host, "/")
+	for _, segment := range segments {
+		u += "/" + url.PathEscape(segment)
+	}
+	return u
+}
+
+// query sends req with headers and decodes a 200 response as JSON into v.
+// Other responses are returned as a *cache.StatusError.
+func query(client *httpclient.Client, req *http.Request, headers map[string]string, v interface{}) error {
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return &cache.StatusError{StatusCode: resp.StatusCode}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// postJSON returns a POST request of body as JSON to u
+func postJSON(ctx context.Context, u string, body interface{}) (*http.Request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// normalize removes the fields a backend adds to hits that say nothing about
+// the page, and sets the url of hits holding one under another name
+func normalize(hits []map[string]interface{}, internalFields ...string) []map[string]interface{} {
+	for _, hit := range hits {
+		for _, field := range internalFields {
+			delete(hit, field)
+		}
+		if _, ok := hit["url"]; ok {
+			continue
+		}
+		for _, field := range urlFields {
+			if value, ok := hit[field].(string); ok && value != "" {
+				hit["url"] = value
+				break
+			}
+		}
+	}
+	return hits
+}
+
+// Config is the search backend of a site: at most one of them is set
+type Config struct {
+	Algolia     *Algolia     `json:"algolia,omitempty" mapstructure:"algolia" jsonschema:"title=Algolia Index the Site's Search Uses (app_id, search-only search_key, index)"`
+	Meilisearch *Meilisearch `json:"meilisearch,omitempty" mapstructure:"meilisearch" jsonschema:"title=Meilisearch Index the Site's Search Uses (host, search_key, index)"`
+	Typesense   *Typesense   `json:"typesense,omitempty" mapstructure:"typesense" jsonschema:"title=Typesense Collection the Site's Search Uses (host, search-only search_key, collection, query_by)"`
+}
+
+// Backend returns the backend configured, or nil when there is none
+func (c *Config) Backend() SearchBackend {
+	switch {
+	case c.Algolia != nil:
+		return c.Algolia
+	case c.Meilisearch != nil:
+		return c.Meilisearch
+	case c.Typesense != nil:
+		return c.Typesense
+	}
+	return nil
+}
+
+// Validate checks at most one backend is configured, and that it is complete
+func (c *Config) Validate() error {
+	configured := 0
+	for _, set := range []bool{c.Algolia != nil, c.Meilisearch != nil, c.Typesense != nil} {
+		if set {
+			configured++
+		}
+	}
+	if configured > 1 {
+		return fmt.Errorf("only one of algolia, meilisearch and typesense may be set")
+	}
+	if backend := c.Backend(); backend != nil {
+		if err := backend.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", backend.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Redacted returns the configuration without its keys, for reporting
+func (c Config) Redacted() Config {
+	if c.Algolia != nil {
+		c.Algolia = c.Algolia.Redacted()
+	}
+	if c.Meilisearch != nil {
+		c.Meilisearch = c.Meilisearch.Redacted()
+	}
+	if c.Typesense != nil {
+		c.Typesense = c.Typesense.Redacted()
+	}
+	return c
+}
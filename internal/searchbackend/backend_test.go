@@ -0,0 +1,30 @@
+package searchbackend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	algolia := &Algolia{AppID: "ABC123", SearchKey: "key", IndexName: "blog"}
+	meilisearch := &Meilisearch{Host: "https://search.example.com", IndexName: "pages"}
+
+	assert.NoError(t, (&Config{}).Validate())
+	assert.NoError(t, (&Config{Meilisearch: meilisearch}).Validate())
+	assert.EqualError(t, (&Config{Algolia: algolia, Meilisearch: meilisearch}).Validate(), "only one of algolia, meilisearch and typesense may be set")
+	assert.EqualError(t, (&Config{Typesense: &Typesense{Host: "https://search.example.com"}}).Validate(), "typesense: search_key is required")
+}
+
+func TestConfig_Backend(t *testing.T) {
+	assert.Nil(t, (&Config{}).Backend())
+
+	config := Config{Typesense: &Typesense{Host: "https://search.example.com", SearchKey: "key", Collection: "pages"}}
+	backend := config.Backend()
+	assert.Equal(t, "typesense", backend.Name())
+	assert.Equal(t, "pages", backend.Index())
+
+	redacted := config.Redacted()
+	assert.Empty(t, redacted.Typesense.SearchKey)
+	assert.Equal(t, "key", config.Typesense.SearchKey)
+}
@@ -0,0 +1,94 @@
+package searchbackend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+)
+
+// MeilisearchMaxHits is the most hits Meilisearch returns for one query
+// unless an index is configured otherwise
+const MeilisearchMaxHits = 1000
+
+// Meilisearch is the Meilisearch index a site publishes its pages to
+type Meilisearch struct {
+	Host      string `json:"host" mapstructure:"host" jsonschema:"title=Meilisearch URL"`
+	SearchKey string `json:"search_key,omitempty" mapstructure:"search_key" jsonschema:"title=Meilisearch Search API Key (optional)"`
+	IndexName string `json:"index" mapstructure:"index" jsonschema:"title=Meilisearch Index UID"`
+}
+
+// meilisearchResponse is Meilisearch's search response, which reports
+// totalHits instead of estimatedTotalHits when paginating by page
+type meilisearchResponse struct {
+	Hits               []map[string]interface{} `json:"hits"`
+	EstimatedTotalHits int                      `json:"estimatedTotalHits"`
+	TotalHits          int                      `json:"totalHits"`
+}
+
+// Name implements SearchBackend
+func (m *Meilisearch) Name() string {
+	return "meilisearch"
+}
+
+// Index implements SearchBackend
+func (m *Meilisearch) Index() string {
+	return m.IndexName
+}
+
+// Validate implements SearchBackend. The key is optional, as instances
+// without a master key accept any query.
+func (m *Meilisearch) Validate() error {
+	if m.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if err := validateHost(m.Host); err != nil {
+		return err
+	}
+	if m.IndexName == "" {
+		return fmt.Errorf("index is required")
+	}
+	return validateKey(m.SearchKey)
+}
+
+// QueryURL implements SearchBackend
+func (m *Meilisearch) QueryURL() string {
+	return joinURL(m.Host, "indexes", m.IndexName, "search")
+}
+
+// Redacted returns the configuration without its key, for reporting
+func (m Meilisearch) Redacted() *Meilisearch {
+	m.SearchKey = ""
+	return &m
+}
+
+// Search implements SearchBackend
+func (m *Meilisearch) Search(ctx context.Context, client *httpclient.Client, q string, hits int) (*Result, error) {
+	req, err := postJSON(ctx, m.QueryURL(), map[string]interface{}{
+		"q":     q,
+		"limit": max(1, min(hits, MeilisearchMaxHits)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Meilisearch query: %w", err)
+	}
+
+	headers := map[string]string{}
+	if m.SearchKey != "" {
+		headers["Authorization"] = "Bearer " + m.SearchKey
+	}
+	var decoded meilisearchResponse
+	if err := query(client, req, headers, &decoded); err != nil {
+		return nil, fmt.Errorf("meilisearch: %w", err)
+	}
+	if decoded.Hits == nil {
+		return nil, fmt.Errorf("invalid Meilisearch response: %w", cache.ErrInvalidResponse)
+	}
+
+	total := decoded.EstimatedTotalHits
+	if decoded.TotalHits > total {
+		total = decoded.TotalHits
+	}
+	hitList := normalize(decoded.Hits, "_formatted", "_matchesPosition", "_rankingScore", "_rankingScoreDetails")
+	return &Result{Hits: hitList, Total: total}, nil
+}
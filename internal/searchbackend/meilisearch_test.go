@@ -0,0 +1,62 @@
+package searchbackend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeilisearch_Validate(t *testing.T) {
+	assert.NoError(t, (&Meilisearch{Host: "https://search.example.com", IndexName: "pages"}).Validate())
+	assert.NoError(t, (&Meilisearch{Host: "https://search.example.com", IndexName: "pages", SearchKey: "key"}).Validate())
+
+	for name, config := range map[string]Meilisearch{
+		"host":     {IndexName: "pages"},
+		"scheme":   {Host: "search.example.com", IndexName: "pages"},
+		"index":    {Host: "https://search.example.com"},
+		"key body": {Host: "https://search.example.com", IndexName: "pages", SearchKey: "key\nX: 1"},
+	} {
+		assert.Error(t, config.Validate(), name)
+	}
+}
+
+func TestMeilisearch_Search(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/indexes/pages/search", r.URL.Path)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]interface{}{"q": "golang", "limit": float64(10)}, body)
+
+		w.Write([]byte(`{"estimatedTotalHits": 3, "hits": [
+			{"id": "1", "title": "Learning Go", "relpermalink": "/posts/go/", "_formatted": {"title": "<em>Learning</em> Go"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	config := &Meilisearch{Host: server.URL + "/", SearchKey: "key", IndexName: "pages"}
+	assert.Equal(t, server.URL+"/indexes/pages/search", config.QueryURL())
+	result, err := config.Search(context.Background(), httpclient.New(), "golang", 10)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, []map[string]interface{}{
+		{"id": "1", "title": "Learning Go", "relpermalink": "/posts/go/", "url": "/posts/go/"},
+	}, result.Hits)
+
+	config.SearchKey = ""
+	_, err = config.Search(context.Background(), httpclient.New(), "golang", 10)
+	var status *cache.StatusError
+	require.ErrorAs(t, err, &status)
+	assert.Equal(t, http.StatusUnauthorized, status.StatusCode)
+}
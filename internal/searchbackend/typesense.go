@@ -0,0 +1,111 @@
+package searchbackend
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+)
+
+// TypesenseMaxHits is the most hits Typesense returns for one query
+const TypesenseMaxHits = 250
+
+// DefaultTypesenseQueryBy are the fields searched unless configured
+// otherwise, as named by the common Hugo Typesense output formats
+const DefaultTypesenseQueryBy = "title,content"
+
+// Typesense is the Typesense collection a site publishes its pages to
+type Typesense struct {
+	Host       string `json:"host" mapstructure:"host" jsonschema:"title=Typesense URL"`
+	SearchKey  string `json:"search_key,omitempty" mapstructure:"search_key" jsonschema:"title=Typesense Search-Only API Key"`
+	Collection string `json:"collection" mapstructure:"collection" jsonschema:"title=Typesense Collection"`
+
+	// QueryBy lists the fields searched, separated by commas
+	QueryBy string `json:"query_by,omitempty" mapstructure:"query_by" jsonschema:"title=Fields Searched (default: title,content)"`
+}
+
+// typesenseResponse is Typesense's search response
+type typesenseResponse struct {
+	Found int `json:"found"`
+	Hits  []struct {
+		Document map[string]interface{} `json:"document"`
+	} `json:"hits"`
+}
+
+// Name implements SearchBackend
+func (t *Typesense) Name() string {
+	return "typesense"
+}
+
+// Index implements SearchBackend
+func (t *Typesense) Index() string {
+	return t.Collection
+}
+
+// Validate implements SearchBackend
+func (t *Typesense) Validate() error {
+	if t.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if err := validateHost(t.Host); err != nil {
+		return err
+	}
+	if t.SearchKey == "" {
+		return fmt.Errorf("search_key is required")
+	}
+	if err := validateKey(t.SearchKey); err != nil {
+		return err
+	}
+	if t.Collection == "" {
+		return fmt.Errorf("collection is required")
+	}
+	return nil
+}
+
+// QueryURL implements SearchBackend
+func (t *Typesense) QueryURL() string {
+	return joinURL(t.Host, "collections", t.Collection, "documents", "search")
+}
+
+// Redacted returns the configuration without its key, for reporting
+func (t Typesense) Redacted() *Typesense {
+	t.SearchKey = ""
+	return &t
+}
+
+// Search implements SearchBackend
+func (t *Typesense) Search(ctx context.Context, client *httpclient.Client, q string, hits int) (*Result, error) {
+	queryBy := t.QueryBy
+	if queryBy == "" {
+		queryBy = DefaultTypesenseQueryBy
+	}
+	params := url.Values{
+		"q":        {q},
+		"query_by": {queryBy},
+		"per_page": {strconv.Itoa(max(1, min(hits, TypesenseMaxHits)))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.QueryURL()+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Typesense query: %w", err)
+	}
+
+	var decoded typesenseResponse
+	if err := query(client, req, map[string]string{"X-TYPESENSE-API-KEY": t.SearchKey}, &decoded); err != nil {
+		return nil, fmt.Errorf("typesense: %w", err)
+	}
+	if decoded.Hits == nil {
+		return nil, fmt.Errorf("invalid Typesense response: %w", cache.ErrInvalidResponse)
+	}
+
+	documents := make([]map[string]interface{}, 0, len(decoded.Hits))
+	for _, hit := range decoded.Hits {
+		if hit.Document != nil {
+			documents = append(documents, hit.Document)
+		}
+	}
+	return &Result{Hits: normalize(documents), Total: decoded.Found}, nil
+}
@@ -0,0 +1,58 @@
+package searchbackend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypesense_Validate(t *testing.T) {
+	assert.NoError(t, (&Typesense{Host: "https://search.example.com", SearchKey: "key", Collection: "pages"}).Validate())
+
+	for name, config := range map[string]Typesense{
+		"host":       {SearchKey: "key", Collection: "pages"},
+		"key":        {Host: "https://search.example.com", Collection: "pages"},
+		"collection": {Host: "https://search.example.com", SearchKey: "key"},
+	} {
+		assert.Error(t, config.Validate(), name)
+	}
+}
+
+func TestTypesense_Search(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-TYPESENSE-API-KEY") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/collections/pages/documents/search", r.URL.Path)
+		assert.Equal(t, "golang", r.URL.Query().Get("q"))
+		assert.Equal(t, "title,summary", r.URL.Query().Get("query_by"))
+		assert.Equal(t, "250", r.URL.Query().Get("per_page"))
+
+		w.Write([]byte(`{"found": 1, "hits": [
+			{"document": {"id": "1", "title": "Learning Go", "permalink": "https://example.com/posts/go/"}, "highlights": [{"field": "title"}]}
+		]}`))
+	}))
+	defer server.Close()
+
+	config := &Typesense{Host: server.URL, SearchKey: "key", Collection: "pages", QueryBy: "title,summary"}
+	result, err := config.Search(context.Background(), httpclient.New(), "golang", 500)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Total)
+	assert.Equal(t, []map[string]interface{}{
+		{"id": "1", "title": "Learning Go", "permalink": "https://example.com/posts/go/", "url": "https://example.com/posts/go/"},
+	}, result.Hits)
+
+	config.SearchKey = "wrong"
+	_, err = config.Search(context.Background(), httpclient.New(), "golang", 5)
+	var status *cache.StatusError
+	require.ErrorAs(t, err, &status)
+	assert.Equal(t, http.StatusUnauthorized, status.StatusCode)
+}
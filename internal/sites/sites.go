@@ -0,0 +1,309 @@
+package sites
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/searchbackend"
+	"github.com/spf13/viper"
+)
+
+// Endpoint names a site may override
+const (
+	EndpointIndex  = "index"
+	EndpointSearch = "search"
+)
+
+// Sources of the site a request is resolved to, in order of precedence
+const (
+	SourceParameter = "hugo_site_path"
+	SourceAlias     = "site"
+	SourceDefault   = "default"
+)
+
+// endpointNames are the endpoints a site may override
+var endpointNames = []string{EndpointIndex, EndpointSearch}
+
+// aliasPattern is the form of site aliases
+var aliasPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// Site is a Hugo site registered under an alias
+type Site struct {
+	Alias     string                  `json:"alias" mapstructure:"-"`
+	URL       string                  `json:"url" mapstructure:"url"`
+	Auth      *httpclient.Credentials `json:"-" mapstructure:"auth"`
+	Endpoints map[string]string       `json:"endpoints,omitempty" mapstructure:"endpoints"`
+	Headers   map[string]string       `json:"-" mapstructure:"headers"`
+	Cookies   map[string]string       `json:"-" mapstructure:"cookies"`
+
+	// The search backend the site's search uses, if any, under its own key
+	searchbackend.Config `json:"-" mapstructure:",squash"`
+}
+
+// Validate checks the site and normalizes its alias, URL and endpoints
+func (s *Site) Validate() error {
+	s.Alias = strings.ToLower(strings.TrimSpace(s.Alias))
+	if !aliasPattern.MatchString(s.Alias) {
+		return fmt.Errorf("alias must be 1-63 lowercase letters, digits, '-' or '_', starting with a letter or digit")
+	}
+
+	siteURL, err := NormalizeURL(s.URL)
+	if err != nil {
+		return err
+	}
+	s.URL = siteURL
+
+	for name, path := range s.Endpoints {
+		if !validEndpoint(name) {
+			return fmt.Errorf("unknown endpoint %q, expected one of: %s", name, strings.Join(endpointNames, ", "))
+		}
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("endpoint %s must be a path starting with /", name)
+		}
+	}
+
+	if err := httpclient.ValidateHeaders(s.Headers); err != nil {
+		return fmt.Errorf("headers: %w", err)
+	}
+	if err := httpclient.ValidateCookies(s.Cookies); err != nil {
+		return fmt.Errorf("cookies: %w", err)
+	}
+
+	if err := s.Config.Validate(); err != nil {
+		return err
+	}
+
+	if s.Auth != nil {
+		return s.Auth.Validate()
+	}
+	return nil
+}
+
+// validEndpoint reports whether name is an endpoint a site may override
+func validEndpoint(name string) bool {
+	for _, endpoint := range endpointNames {
+		if name == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// Registry holds the sites registered for the lifetime of the server
+type Registry struct {
+	mu          sync.RWMutex
+	sites       map[string]Site
+	defaultSite string
+}
+
+// New creates an empty Registry
+func New() *Registry {
+	return &Registry{sites: make(map[string]Site)}
+}
+
+// FromConfig creates a Registry of the viper settings sites, which maps
+// aliases to a url, optional auth, endpoints, headers, cookies and search
+// backend, and default_site, the alias or URL of the site requests use when
+// they name none
+func FromConfig() (*Registry, error) {
+	r := New()
+
+	if err := r.SetDefault(viper.GetString("default_site")); err != nil {
+		return nil, fmt.Errorf("invalid default_site: %w", err)
+	}
+
+	var configured map[string]Site
+	if err := viper.UnmarshalKey("sites", &configured); err != nil {
+		return nil, fmt.Errorf("invalid sites configuration: %w", err)
+	}
+	for alias, site := range configured {
+		site.Alias = alias
+		if err := r.Register(site); err != nil {
+			return nil, fmt.Errorf("invalid sites configuration for %s: %w", alias, err)
+		}
+	}
+	return r, nil
+}
+
+// SetDefault sets the site requests use when they give neither site nor
+// hugo_site_path: the alias of a registered site, or a site URL. An alias
+// need not be registered yet. An empty value clears the default.
+func (r *Registry) SetDefault(value string) error {
+	value = strings.TrimSpace(value)
+	if value != "" && !aliasPattern.MatchString(strings.ToLower(value)) {
+		siteURL, err := NormalizeURL(value)
+		if err != nil {
+			return err
+		}
+		value = siteURL
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defaultSite = value
+	return nil
+}
+
+// Default returns the alias or URL of the default site, if any
+func (r *Registry) Default() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaultSite
+}
+
+// Register adds a site, replacing any registered under the same alias
+func (r *Registry) Register(site Site) error {
+	if err := site.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sites[site.Alias] = site
+	return nil
+}
+
+// Get returns the site registered under alias
+func (r *Registry) Get(alias string) (Site, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	site, ok := r.sites[strings.ToLower(strings.TrimSpace(alias))]
+	return site, ok
+}
+
+// Remove removes the site registered under alias, reporting whether there was one
+func (r *Registry) Remove(alias string) bool {
+	alias = strings.ToLower(strings.TrimSpace(alias))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.sites[alias]
+	delete(r.sites, alias)
+	return ok
+}
+
+// List returns the registered sites ordered by alias
+func (r *Registry) List() []Site {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]Site, 0, len(r.sites))
+	for _, site := range r.sites {
+		list = append(list, site)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Alias < list[j].Alias
+	})
+	return list
+}
+
+type siteKey struct{}
+
+type sourceKey struct{}
+
+// Endpoint returns the path the site a request was resolved to uses for the
+// named endpoint, if it overrides it
+func Endpoint(ctx context.Context, name string) (string, bool) {
+	site, ok := ctx.Value(siteKey{}).(Site)
+	if !ok {
+		return "", false
+	}
+	path, ok := site.Endpoints[name]
+	return path, ok
+}
+
+// SearchBackend returns the search backend of the site a request was
+// resolved to, if it has one
+func SearchBackend(ctx context.Context) (searchbackend.SearchBackend, bool) {
+	site, ok := ctx.Value(siteKey{}).(Site)
+	if !ok {
+		return nil, false
+	}
+	backend := site.Backend()
+	return backend, backend != nil
+}
+
+// Source returns where the site of a request resolved by SiteOptions.Apply
+// came from: SourceParameter, SourceAlias or SourceDefault
+func Source(ctx context.Context) string {
+	if source, ok := ctx.Value(sourceKey{}).(string); ok {
+		return source
+	}
+	return SourceParameter
+}
+
+// IsAlias reports whether name has the form of a site alias rather than
+// that of a URL
+func IsAlias(name string) bool {
+	return aliasPattern.MatchString(strings.ToLower(name))
+}
+
+// SiteOptions select a registered site by alias instead of repeating its URL
+// and credentials. Embed it in request structs.
+type SiteOptions struct {
+	Site string `json:"site,omitempty" jsonschema:"title=Alias of a registered site to use instead of hugo_site_path"`
+}
+
+// ResolvesSite implements tools.SiteResolver: requests embedding
+// SiteOptions can only be validated once Apply has filled in their site
+func (o *SiteOptions) ResolvesSite() {}
+
+// Apply resolves the site of a request against registry. An explicit
+// hugo_site_path is used as is; otherwise the site alias, or failing that
+// the registry's default site, fills hugoSitePath, and a registered site's
+// credentials are used unless the request has its own. Call it before
+// validating the request. The returned context carries the site's
+// endpoints and the source of the site.
+func (o *SiteOptions) Apply(ctx context.Context, registry *Registry, hugoSitePath *string, auth *httpclient.AuthOptions) (context.Context, error) {
+	switch {
+	case o.Site != "" && *hugoSitePath != "":
+		return ctx, fmt.Errorf("use either site or hugo_site_path, not both")
+	case *hugoSitePath != "":
+		return context.WithValue(ctx, sourceKey{}, SourceParameter), nil
+	case o.Site != "":
+		site, ok := registry.Get(o.Site)
+		if !ok {
+			return ctx, fmt.Errorf("unknown site %q, register it with hugo_reader_register_site", o.Site)
+		}
+		return site.apply(context.WithValue(ctx, sourceKey{}, SourceAlias), hugoSitePath, auth), nil
+	}
+
+	defaultSite := registry.Default()
+	if defaultSite == "" {
+		return ctx, nil
+	}
+	ctx = context.WithValue(ctx, sourceKey{}, SourceDefault)
+	if site, ok := registry.Get(defaultSite); ok {
+		return site.apply(ctx, hugoSitePath, auth), nil
+	}
+	if IsAlias(defaultSite) {
+		return ctx, fmt.Errorf("default site %q is not registered", defaultSite)
+	}
+	*hugoSitePath = defaultSite
+	return ctx, nil
+}
+
+// apply fills a request with the site's URL and, unless the request has its
+// own, credentials, and returns a context carrying its endpoints, headers
+// and cookies
+func (s Site) apply(ctx context.Context, hugoSitePath *string, auth *httpclient.AuthOptions) context.Context {
+	*hugoSitePath = s.URL
+	if auth.Auth == nil && s.Auth != nil {
+		creds := *s.Auth
+		auth.Auth = &creds
+	}
+	if siteURL, err := url.Parse(s.URL); err == nil {
+		ctx = httpclient.ContextWithHeaders(ctx, siteURL.Host, s.Headers, s.Cookies)
+	}
+	if path, ok := s.Endpoints[EndpointIndex]; ok {
+		ctx = hugoindex.ContextWithPath(ctx, path)
+	}
+	return context.WithValue(ctx, siteKey{}, s)
+}
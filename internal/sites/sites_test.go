@@ -0,0 +1,222 @@
+package sites
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/searchbackend"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSite_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		site    Site
+		wantURL string
+		wantErr bool
+	}{
+		{name: "valid", site: Site{Alias: "blog", URL: "https://example.com"}, wantURL: "https://example.com"},
+		{name: "defaults to https", site: Site{Alias: "Blog", URL: "example.com"}, wantURL: "https://example.com"},
+		{name: "custom endpoints", site: Site{Alias: "docs", URL: "http://localhost:1313", Endpoints: map[string]string{"index": "/api/pages.json"}}, wantURL: "http://localhost:1313"},
+		{name: "missing alias", site: Site{URL: "https://example.com"}, wantErr: true},
+		{name: "invalid alias", site: Site{Alias: "my blog", URL: "https://example.com"}, wantErr: true},
+		{name: "missing url", site: Site{Alias: "blog"}, wantErr: true},
+		{name: "unsupported scheme", site: Site{Alias: "blog", URL: "ftp://example.com"}, wantErr: true},
+		{name: "unknown endpoint", site: Site{Alias: "blog", URL: "https://example.com", Endpoints: map[string]string{"feed": "/feed.json"}}, wantErr: true},
+		{name: "relative endpoint", site: Site{Alias: "blog", URL: "https://example.com", Endpoints: map[string]string{"index": "index.json"}}, wantErr: true},
+		{name: "invalid auth", site: Site{Alias: "blog", URL: "https://example.com", Auth: &httpclient.Credentials{Password: "secret"}}, wantErr: true},
+		{name: "headers and cookies", site: Site{Alias: "blog", URL: "https://example.com", Headers: map[string]string{"X-Api-Key": "abc"}, Cookies: map[string]string{"nf_jwt": "token"}}, wantURL: "https://example.com"},
+		{name: "invalid header", site: Site{Alias: "blog", URL: "https://example.com", Headers: map[string]string{"X Api": "abc"}}, wantErr: true},
+		{name: "invalid cookie", site: Site{Alias: "blog", URL: "https://example.com", Cookies: map[string]string{"nf_jwt": "a;b"}}, wantErr: true},
+		{name: "algolia", site: Site{Alias: "blog", URL: "https://example.com", Config: searchbackend.Config{Algolia: &searchbackend.Algolia{AppID: "ABC123", SearchKey: "key", IndexName: "blog"}}}, wantURL: "https://example.com"},
+		{name: "incomplete algolia", site: Site{Alias: "blog", URL: "https://example.com", Config: searchbackend.Config{Algolia: &searchbackend.Algolia{AppID: "ABC123", IndexName: "blog"}}}, wantErr: true},
+		{name: "two search backends", site: Site{Alias: "blog", URL: "https://example.com", Config: searchbackend.Config{
+			Algolia:     &searchbackend.Algolia{AppID: "ABC123", SearchKey: "key", IndexName: "blog"},
+			Meilisearch: &searchbackend.Meilisearch{Host: "https://search.example.com", IndexName: "blog"},
+		}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.site.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantURL, tt.site.URL)
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := New()
+	require.NoError(t, r.Register(Site{Alias: "Blog", URL: "example.com"}))
+	require.NoError(t, r.Register(Site{Alias: "docs", URL: "https://docs.example.com"}))
+	assert.Error(t, r.Register(Site{Alias: "bad"}))
+
+	site, ok := r.Get("BLOG")
+	require.True(t, ok)
+	assert.Equal(t, "blog", site.Alias)
+	assert.Equal(t, "https://example.com", site.URL)
+
+	list := r.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, "blog", list[0].Alias)
+	assert.Equal(t, "docs", list[1].Alias)
+
+	assert.True(t, r.Remove("docs"))
+	assert.False(t, r.Remove("docs"))
+	assert.Len(t, r.List(), 1)
+}
+
+func TestFromConfig(t *testing.T) {
+	defer viper.Reset()
+
+	viper.Set("sites", map[string]interface{}{
+		"blog": map[string]interface{}{
+			"url":       "https://example.com",
+			"auth":      map[string]interface{}{"bearer_token": "secret"},
+			"endpoints": map[string]interface{}{"search": "/api/search.json"},
+			"headers":   map[string]interface{}{"X-Api-Key": "abc"},
+			"cookies":   map[string]interface{}{"nf_jwt": "token"},
+			"typesense": map[string]interface{}{"host": "https://search.example.com", "search_key": "key", "collection": "blog"},
+		},
+	})
+	r, err := FromConfig()
+	require.NoError(t, err)
+	site, ok := r.Get("blog")
+	require.True(t, ok)
+	assert.Equal(t, "https://example.com", site.URL)
+	require.NotNil(t, site.Auth)
+	assert.Equal(t, "secret", site.Auth.BearerToken)
+	assert.Equal(t, "/api/search.json", site.Endpoints[EndpointSearch])
+	assert.Equal(t, map[string]string{"x-api-key": "abc"}, site.Headers)
+	assert.Equal(t, map[string]string{"nf_jwt": "token"}, site.Cookies)
+	assert.Equal(t, &searchbackend.Typesense{Host: "https://search.example.com", SearchKey: "key", Collection: "blog"}, site.Typesense)
+
+	viper.Set("default_site", "blog")
+	r, err = FromConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "blog", r.Default())
+
+	viper.Set("default_site", "ftp://example.com")
+	_, err = FromConfig()
+	assert.Error(t, err)
+
+	viper.Set("default_site", "")
+	viper.Set("sites", map[string]interface{}{"blog": map[string]interface{}{"url": "ftp://example.com"}})
+	_, err = FromConfig()
+	assert.Error(t, err)
+}
+
+func TestIsAlias(t *testing.T) {
+	assert.True(t, IsAlias("blog"))
+	assert.True(t, IsAlias("My-Docs"))
+	assert.False(t, IsAlias("example.com"))
+	assert.False(t, IsAlias("https://example.com"))
+}
+
+func TestSiteOptions_Apply(t *testing.T) {
+	r := New()
+	require.NoError(t, r.Register(Site{
+		Alias:     "blog",
+		URL:       "https://example.com",
+		Auth:      &httpclient.Credentials{BearerToken: "secret"},
+		Endpoints: map[string]string{"index": "/api/pages.json", "search": "/api/search.json"},
+		Config:    searchbackend.Config{Algolia: &searchbackend.Algolia{AppID: "ABC123", SearchKey: "key", IndexName: "blog"}},
+	}))
+	ctx := context.Background()
+
+	// Without a site the request is left alone
+	path, auth := "https://other.example.com", httpclient.AuthOptions{}
+	resolved, err := (&SiteOptions{}).Apply(ctx, r, &path, &auth)
+	require.NoError(t, err)
+	assert.Equal(t, "https://other.example.com", path)
+	assert.Equal(t, hugoindex.Path, hugoindex.IndexPath(resolved))
+	_, ok := SearchBackend(resolved)
+	assert.False(t, ok)
+
+	path = ""
+	resolved, err = (&SiteOptions{Site: "blog"}).Apply(ctx, r, &path, &auth)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", path)
+	require.NotNil(t, auth.Auth)
+	assert.Equal(t, "secret", auth.Auth.BearerToken)
+	assert.Equal(t, "/api/pages.json", hugoindex.IndexPath(resolved))
+	endpoint, ok := Endpoint(resolved, EndpointSearch)
+	assert.True(t, ok)
+	assert.Equal(t, "/api/search.json", endpoint)
+	backend, ok := SearchBackend(resolved)
+	assert.True(t, ok)
+	assert.Equal(t, "algolia", backend.Name())
+	assert.Equal(t, "blog", backend.Index())
+
+	// The request's own credentials take precedence
+	path, auth = "", httpclient.AuthOptions{Auth: &httpclient.Credentials{Username: "user"}}
+	_, err = (&SiteOptions{Site: "blog"}).Apply(ctx, r, &path, &auth)
+	require.NoError(t, err)
+	assert.Equal(t, "user", auth.Auth.Username)
+
+	path = "https://example.com"
+	_, err = (&SiteOptions{Site: "blog"}).Apply(ctx, r, &path, &auth)
+	assert.Error(t, err)
+
+	path = ""
+	_, err = (&SiteOptions{Site: "unknown"}).Apply(ctx, r, &path, &auth)
+	assert.Error(t, err)
+}
+
+func TestSiteOptions_Apply_Default(t *testing.T) {
+	r := New()
+	require.NoError(t, r.Register(Site{Alias: "blog", URL: "https://example.com", Auth: &httpclient.Credentials{BearerToken: "secret"}}))
+	ctx := context.Background()
+
+	// Without a default, requests must name their site
+	path, auth := "", httpclient.AuthOptions{}
+	resolved, err := (&SiteOptions{}).Apply(ctx, r, &path, &auth)
+	require.NoError(t, err)
+	assert.Empty(t, path)
+
+	require.NoError(t, r.SetDefault("docs.example.com"))
+	assert.Equal(t, "https://docs.example.com", r.Default())
+	resolved, err = (&SiteOptions{}).Apply(ctx, r, &path, &auth)
+	require.NoError(t, err)
+	assert.Equal(t, "https://docs.example.com", path)
+	assert.Equal(t, SourceDefault, Source(resolved))
+	assert.Nil(t, auth.Auth)
+
+	// An explicit site takes precedence over the default
+	path = "https://other.example.com"
+	resolved, err = (&SiteOptions{}).Apply(ctx, r, &path, &auth)
+	require.NoError(t, err)
+	assert.Equal(t, "https://other.example.com", path)
+	assert.Equal(t, SourceParameter, Source(resolved))
+
+	path = ""
+	resolved, err = (&SiteOptions{Site: "blog"}).Apply(ctx, r, &path, &auth)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", path)
+	assert.Equal(t, SourceAlias, Source(resolved))
+
+	// A default alias brings the site's credentials
+	require.NoError(t, r.SetDefault("blog"))
+	path, auth = "", httpclient.AuthOptions{}
+	resolved, err = (&SiteOptions{}).Apply(ctx, r, &path, &auth)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", path)
+	assert.Equal(t, SourceDefault, Source(resolved))
+	require.NotNil(t, auth.Auth)
+	assert.Equal(t, "secret", auth.Auth.BearerToken)
+
+	require.NoError(t, r.SetDefault("staging"))
+	path = ""
+	_, err = (&SiteOptions{}).Apply(ctx, r, &path, &auth)
+	assert.Error(t, err)
+
+	assert.Error(t, r.SetDefault("ftp://example.com"))
+}
@@ -0,0 +1,97 @@
+package sites
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// schemePrefix matches a URL that names its scheme, and hostPort one that
+// starts with a host and port instead, such as localhost:1313/blog
+var (
+	schemePrefix = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+	hostPort     = regexp.MustCompile(`^[^:/?#]+:[0-9]+([/?#]|$)`)
+)
+
+// hostLabel is the form of a host name label once converted to ASCII
+var hostLabel = regexp.MustCompile(`^[a-z0-9_]([a-z0-9_-]{0,61}[a-z0-9_])?$`)
+
+// NormalizeURL validates a site URL and returns it in canonical form. URLs
+// without a scheme default to https; any scheme but http and https is
+// refused, as are URLs without a host or holding control characters.
+// Internationalized host names are lowercased and converted to punycode.
+func NormalizeURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("url is required")
+	}
+	if strings.ContainsFunc(raw, unicode.IsControl) {
+		return "", fmt.Errorf("invalid url: contains control characters")
+	}
+	if !schemePrefix.MatchString(raw) || hostPort.MatchString(raw) {
+		raw = "https://" + strings.TrimPrefix(raw, "//")
+	}
+
+	siteURL, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %s", raw)
+	}
+	siteURL.Scheme = strings.ToLower(siteURL.Scheme)
+	if siteURL.Scheme != "http" && siteURL.Scheme != "https" {
+		return "", fmt.Errorf("unsupported url scheme: %s (must be http or https)", siteURL.Scheme)
+	}
+	if siteURL.Host == "" {
+		return "", fmt.Errorf("invalid url: %s has no host", raw)
+	}
+
+	host, err := asciiHost(siteURL.Hostname())
+	if err != nil {
+		return "", err
+	}
+	if port := siteURL.Port(); port != "" {
+		siteURL.Host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		siteURL.Host = "[" + host + "]"
+	} else {
+		siteURL.Host = host
+	}
+	return siteURL.String(), nil
+}
+
+// ValidateSitePath checks the hugo_site_path of a request and replaces it
+// with its canonical form
+func ValidateSitePath(hugoSitePath *string) error {
+	if *hugoSitePath == "" {
+		return fmt.Errorf("hugo_site_path is required")
+	}
+	siteURL, err := NormalizeURL(*hugoSitePath)
+	if err != nil {
+		return fmt.Errorf("invalid hugo_site_path: %w", err)
+	}
+	*hugoSitePath = siteURL
+	return nil
+}
+
+// asciiHost lowercases host and converts its internationalized labels to
+// punycode. IP addresses are returned as they are.
+func asciiHost(host string) (string, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return addr.String(), nil
+	}
+
+	labels := strings.Split(strings.ToLower(strings.TrimSuffix(host, ".")), ".")
+	for i, label := range labels {
+		if strings.ContainsFunc(label, func(r rune) bool { return r >= 0x80 }) {
+			label = "xn--" + punycode(label)
+		}
+		if !hostLabel.MatchString(label) {
+			return "", fmt.Errorf("invalid url: invalid host name %q", host)
+		}
+		labels[i] = label
+	}
+	return strings.Join(labels, "."), nil
+}
@@ -0,0 +1,74 @@
+package sites
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "https://example.com/blog/", want: "https://example.com/blog/"},
+		{raw: "  HTTP://Example.COM  ", want: "http://example.com"},
+		{raw: "example.com", want: "https://example.com"},
+		{raw: "//example.com/docs", want: "https://example.com/docs"},
+		{raw: "localhost:1313/blog", want: "https://localhost:1313/blog"},
+		{raw: "http://127.0.0.1:8080", want: "http://127.0.0.1:8080"},
+		{raw: "http://[::1]:8080/", want: "http://[::1]:8080/"},
+		{raw: "https://bücher.example/", want: "https://xn--bcher-kva.example/"},
+		{raw: "https://MÜNCHEN.de", want: "https://xn--mnchen-3ya.de"},
+		{raw: "", wantErr: true},
+		{raw: "javascript:alert(1)", wantErr: true},
+		{raw: "file:///etc/passwd", wantErr: true},
+		{raw: "ftp://example.com", wantErr: true},
+		{raw: "data:text/html,<script>", wantErr: true},
+		{raw: "https://", wantErr: true},
+		{raw: "https:///path", wantErr: true},
+		{raw: "https://exa mple.com", wantErr: true},
+		{raw: "https://example.com/\x00", wantErr: true},
+		{raw: "https://example.com\r\nX-Injected: 1", wantErr: true},
+		{raw: "https://<script>.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := NormalizeURL(tt.raw)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateSitePath(t *testing.T) {
+	path := ""
+	assert.EqualError(t, ValidateSitePath(&path), "hugo_site_path is required")
+
+	path = "javascript:alert(1)"
+	assert.ErrorContains(t, ValidateSitePath(&path), "invalid hugo_site_path")
+
+	path = "Example.com/blog"
+	require.NoError(t, ValidateSitePath(&path))
+	assert.Equal(t, "https://example.com/blog", path)
+}
+
+func TestPunycode(t *testing.T) {
+	// Samples from RFC 3492 section 7.1
+	tests := map[string]string{
+		"bücher":            "bcher-kva",
+		"münchen":           "mnchen-3ya",
+		"他们为什么不说中文":         "ihqwcrb4cv8a8dqg056pqjye",
+		"ليهمابتكلموشعربي؟": "egbpdaj6bu4bxfgehfvwxn",
+	}
+	for label, want := range tests {
+		assert.Equal(t, want, punycode(label), label)
+	}
+}
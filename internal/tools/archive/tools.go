@@ -0,0 +1,491 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/tidwall/gjson"
+)
+
+// Page sources
+const (
+	SourceAuto    = "auto"
+	SourceIndex   = "index"
+	SourceSitemap = "sitemap"
+)
+
+// sitemapPath is where Hugo publishes a site's sitemap
+const sitemapPath = "/sitemap.xml"
+
+// dateFields are the page fields an index page's publication date is read
+// from, in order of preference
+var dateFields = []string{"date", "publishDate", "publishdate"}
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool groups the pages of a Hugo site by the year and month they were
+// published.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// ArchiveRequest represents the request parameters for the archive tool.
+type ArchiveRequest struct {
+	HugoSitePath   string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	Source         string `json:"source,omitempty" jsonschema:"enum=auto,enum=index,enum=sitemap,title=Page Source"`
+	Year           int    `json:"year,omitempty" jsonschema:"title=Only include this year"`
+	TitlesPerMonth int    `json:"titles_per_month,omitempty" jsonschema:"title=Titles listed per month (default: 3),minimum=0,maximum=20"`
+	NoTitles       bool   `json:"no_titles,omitempty" jsonschema:"title=Only report counts"`
+
+	hugoindex.PublishOptions
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// Year is the pages published in one year
+type Year struct {
+	Year   int      `json:"year"`
+	Count  int      `json:"count"`
+	Months []*Month `json:"months"`
+}
+
+// Month is the pages published in one month
+type Month struct {
+	Month  string   `json:"month"`
+	Count  int      `json:"count"`
+	Titles []string `json:"titles,omitempty"`
+
+	pages []datedPage
+}
+
+// datedPage is a page with the date it was published
+type datedPage struct {
+	title string
+	date  time.Time
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_get_archive"),
+		name:        "hugo_reader_get_archive",
+		description: "Get a publication timeline of a Hugo site: pages grouped by year and month with counts and the latest titles of each month, from index.json or, failing that, the dates in sitemap.xml. Use it to answer how active a blog is and when it was most active.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *ArchiveRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+
+	switch r.Source {
+	case "":
+		r.Source = SourceAuto
+	case SourceAuto, SourceIndex, SourceSitemap:
+	default:
+		return fmt.Errorf("invalid source: %s (must be: auto, index, or sitemap)", r.Source)
+	}
+
+	if r.Year < 0 {
+		return fmt.Errorf("year must not be negative")
+	}
+	if r.NoTitles {
+		r.TitlesPerMonth = 0
+	} else if r.TitlesPerMonth == 0 {
+		r.TitlesPerMonth = 3
+	} else if r.TitlesPerMonth < 0 || r.TitlesPerMonth > 20 {
+		return fmt.Errorf("titles_per_month must be between 0 and 20")
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// Execute builds the publication timeline of a Hugo site.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	archiveRequest, ok := req.(*ArchiveRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := archiveRequest.SiteOptions.Apply(ctx, t.sites, &archiveRequest.HugoSitePath, &archiveRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := archiveRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := archiveRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = archiveRequest.CacheOptions.Apply(ctx, 0)
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(archiveRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", archiveRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = archiveRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	sources := []string{SourceIndex, SourceSitemap}
+	if archiveRequest.Source != SourceAuto {
+		sources = []string{archiveRequest.Source}
+	}
+
+	var tl *timeline
+	var lastErr error
+	for _, source := range sources {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("archive retrieval cancelled: %w", err)
+		}
+
+		switch source {
+		case SourceIndex:
+			tl, err = t.fromIndex(ctx, siteURL, archiveRequest)
+		case SourceSitemap:
+			tl, err = t.fromSitemap(ctx, siteURL, archiveRequest)
+		}
+		if err == nil {
+			break
+		}
+		t.log.DebugContext(ctx, "Archive source unavailable", "source", source, "error", err)
+		lastErr = err
+	}
+	if tl == nil {
+		t.log.ErrorContext(ctx, "No archive source available", "site", archiveRequest.HugoSitePath, "error", lastErr)
+		return nil, toolerrors.Errorf(toolerrors.Code(lastErr), "no index or sitemap available at Hugo site %s: %w", archiveRequest.HugoSitePath, lastErr)
+	}
+
+	years := tl.years(archiveRequest.TitlesPerMonth)
+	metadata := tl.metadata()
+	metadata["site_source"] = sites.Source(ctx)
+	if archiveRequest.Year != 0 {
+		metadata["year"] = archiveRequest.Year
+	}
+
+	response := map[string]interface{}{
+		"success":  true,
+		"years":    years,
+		"metadata": metadata,
+		"errors":   []string{},
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal archive", "error", err)
+		return nil, fmt.Errorf("failed to marshal archive: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Built archive", "site", archiveRequest.HugoSitePath, "source", tl.source, "pages", tl.dated, "years", len(years))
+	return tools.TextResponse(responseJSON), nil
+}
+
+// fromIndex builds the timeline from the dates of the pages in the site index
+func (t *Tool) fromIndex(ctx context.Context, siteURL *url.URL, req *ArchiveRequest) (*timeline, error) {
+	index, err := hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	tl := newTimeline(SourceIndex, index.URL(), "date", req.Year)
+	seen := make(map[string]bool)
+	now := time.Now()
+	err = index.Pages(ctx, func(page gjson.Result) bool {
+		if key := hugoindex.CanonicalURL(siteURL, hugoindex.PageURL(page)); key != "" {
+			if seen[key] {
+				tl.duplicates++
+				return true
+			}
+			seen[key] = true
+		}
+		if !req.PublishOptions.Allows(page, now) {
+			tl.excluded++
+			return true
+		}
+
+		title := page.Get("title").String()
+		if title == "" {
+			title = hugoindex.PageURL(page)
+		}
+		for _, field := range dateFields {
+			if date, ok := hugoindex.ParseDate(page.Get(field).String()); ok {
+				tl.add(title, date)
+				return true
+			}
+		}
+		tl.undated++
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read site index: %w", err)
+	}
+	return tl, nil
+}
+
+// sitemap is the subset of the sitemap protocol the timeline is built from
+type sitemap struct {
+	URLs []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+}
+
+// fromSitemap builds the timeline from the lastmod dates of the sitemap,
+// which carries no titles, so pages are listed by URL
+func (t *Tool) fromSitemap(ctx context.Context, siteURL *url.URL, req *ArchiveRequest) (*timeline, error) {
+	sitemapURL := siteURL.ResolveReference(&url.URL{Path: sitemapPath})
+	cacheKey := t.cache.BuildKey(siteURL.String(), sitemapPath, nil)
+	result, err := t.cache.Fetch(ctx, t.httpClient, cacheKey, sitemapURL.String(), validateSitemap)
+	if err != nil {
+		return nil, err
+	}
+
+	var sm sitemap
+	if err := xml.Unmarshal(result.Data, &sm); err != nil {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeParseError, "invalid sitemap: %w", err)
+	}
+
+	tl := newTimeline(SourceSitemap, sitemapURL.String(), "lastmod", req.Year)
+	seen := make(map[string]bool)
+	for _, u := range sm.URLs {
+		if u.Loc == "" {
+			continue
+		}
+		key := hugoindex.CanonicalURL(siteURL, u.Loc)
+		if seen[key] {
+			tl.duplicates++
+			continue
+		}
+		seen[key] = true
+
+		if date, ok := hugoindex.ParseDate(u.LastMod); ok {
+			tl.add(u.Loc, date)
+		} else {
+			tl.undated++
+		}
+	}
+	return tl, nil
+}
+
+// validateSitemap checks that data is a sitemap listing at least one URL
+func validateSitemap(data []byte) bool {
+	var sm sitemap
+	return xml.Unmarshal(data, &sm) == nil && len(sm.URLs) > 0
+}
+
+// timeline counts pages by the month they were published
+type timeline struct {
+	source     string
+	endpoint   string
+	dateField  string
+	year       int
+	months     map[string]*Month
+	dated      int
+	undated    int
+	excluded   int
+	duplicates int
+	outside    int
+	first      time.Time
+	last       time.Time
+}
+
+func newTimeline(source, endpoint, dateField string, year int) *timeline {
+	return &timeline{
+		source:    source,
+		endpoint:  endpoint,
+		dateField: dateField,
+		year:      year,
+		months:    make(map[string]*Month),
+	}
+}
+
+// add counts a page published at date, unless it is outside the year
+func (tl *timeline) add(title string, date time.Time) {
+	date = date.UTC()
+	if tl.year != 0 && date.Year() != tl.year {
+		tl.outside++
+		return
+	}
+
+	key := date.Format("2006-01")
+	month, ok := tl.months[key]
+	if !ok {
+		month = &Month{Month: key}
+		tl.months[key] = month
+	}
+	month.Count++
+	month.pages = append(month.pages, datedPage{title: title, date: date})
+
+	tl.dated++
+	if tl.first.IsZero() || date.Before(tl.first) {
+		tl.first = date
+	}
+	if date.After(tl.last) {
+		tl.last = date
+	}
+}
+
+// years groups the months by year, newest first, each listing the titles of
+// up to titles of its latest pages
+func (tl *timeline) years(titles int) []*Year {
+	byYear := make(map[int]*Year)
+	for key, month := range tl.months {
+		sort.SliceStable(month.pages, func(i, j int) bool {
+			return month.pages[i].date.After(month.pages[j].date)
+		})
+		for _, page := range month.pages[:min(len(month.pages), titles)] {
+			month.Titles = append(month.Titles, page.title)
+		}
+
+		date, _ := time.Parse("2006-01", key)
+		year, ok := byYear[date.Year()]
+		if !ok {
+			year = &Year{Year: date.Year()}
+			byYear[date.Year()] = year
+		}
+		year.Count += month.Count
+		year.Months = append(year.Months, month)
+	}
+
+	years := make([]*Year, 0, len(byYear))
+	for _, year := range byYear {
+		sort.Slice(year.Months, func(i, j int) bool {
+			return year.Months[i].Month > year.Months[j].Month
+		})
+		years = append(years, year)
+	}
+	sort.Slice(years, func(i, j int) bool {
+		return years[i].Year > years[j].Year
+	})
+	return years
+}
+
+// metadata summarizes the timeline: how many pages it covers, over which
+// span, and how active the site was across it
+func (tl *timeline) metadata() map[string]interface{} {
+	metadata := map[string]interface{}{
+		"source":             tl.source,
+		"source_endpoint":    tl.endpoint,
+		"date_field":         tl.dateField,
+		"total_pages":        tl.dated,
+		"undated_count":      tl.undated,
+		"excluded_count":     tl.excluded,
+		"duplicates_removed": tl.duplicates,
+		"active_months":      len(tl.months),
+	}
+	if tl.year != 0 {
+		metadata["outside_year_count"] = tl.outside
+	}
+	if tl.dated > 0 {
+		span := (tl.last.Year()-tl.first.Year())*12 + int(tl.last.Month()-tl.first.Month()) + 1
+		metadata["first_date"] = tl.first.Format(time.RFC3339)
+		metadata["last_date"] = tl.last.Format(time.RFC3339)
+		metadata["span_months"] = span
+		metadata["pages_per_month"] = float64(tl.dated) / float64(span)
+	}
+	return metadata
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*ArchiveRequest](registry, t, "Site exploration", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
@@ -0,0 +1,127 @@
+package archive
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+const siteIndex = `{"pages": [
+	{"title": "Jan A", "url": "/a/", "date": "2024-01-05"},
+	{"title": "Jan B", "url": "/b/", "date": "2024-01-20T10:00:00Z"},
+	{"title": "Jan B alias", "url": "/b/index.html", "date": "2024-01-20T10:00:00Z"},
+	{"title": "Mar", "url": "/c/", "publishDate": "2024-03-01"},
+	{"title": "Old", "url": "/d/", "date": "2023-12-31"},
+	{"title": "Undated", "url": "/e/"},
+	{"title": "Draft", "url": "/f/", "date": "2024-02-01", "draft": true}
+]}`
+
+const sitemapXML = `<?xml version="1.0" encoding="utf-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/a/</loc><lastmod>2024-05-01T00:00:00Z</lastmod></url>
+  <url><loc>https://example.com/b/</loc><lastmod>2024-05-02</lastmod></url>
+  <url><loc>https://example.com/b/index.html</loc><lastmod>2024-05-02</lastmod></url>
+  <url><loc>https://example.com/</loc></url>
+</urlset>`
+
+func newServer(t *testing.T, withIndex bool) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/index.json" && withIndex:
+			w.Write([]byte(siteIndex))
+		case r.URL.Path == "/sitemap.xml":
+			w.Write([]byte(sitemapXML))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestArchiveRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     ArchiveRequest
+		wantErr string
+	}{
+		{name: "valid", req: ArchiveRequest{HugoSitePath: "https://example.com"}},
+		{name: "missing site", req: ArchiveRequest{}, wantErr: "hugo_site_path is required"},
+		{name: "invalid source", req: ArchiveRequest{HugoSitePath: "https://example.com", Source: "rss"}, wantErr: "invalid source: rss (must be: auto, index, or sitemap)"},
+		{name: "too many titles", req: ArchiveRequest{HugoSitePath: "https://example.com", TitlesPerMonth: 21}, wantErr: "titles_per_month must be between 0 and 20"},
+		{name: "negative year", req: ArchiveRequest{HugoSitePath: "https://example.com", Year: -1}, wantErr: "year must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, SourceAuto, tt.req.Source)
+			assert.Equal(t, 3, tt.req.TitlesPerMonth)
+		})
+	}
+}
+
+func TestTool_Execute_FromIndex(t *testing.T) {
+	server := newServer(t, true)
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &ArchiveRequest{HugoSitePath: server.URL})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+
+	assert.Equal(t, `[2024,2023]`, result.Get("years.#.year").Raw)
+	assert.Equal(t, `[3,1]`, result.Get("years.#.count").Raw)
+	assert.Equal(t, `["2024-03","2024-01"]`, result.Get("years.0.months.#.month").Raw)
+	assert.Equal(t, `["Jan B","Jan A"]`, result.Get("years.0.months.1.titles").Raw)
+
+	metadata := result.Get("metadata")
+	assert.Equal(t, SourceIndex, metadata.Get("source").String())
+	assert.Equal(t, int64(4), metadata.Get("total_pages").Int())
+	assert.Equal(t, int64(1), metadata.Get("undated_count").Int())
+	assert.Equal(t, int64(1), metadata.Get("excluded_count").Int())
+	assert.Equal(t, int64(1), metadata.Get("duplicates_removed").Int())
+	assert.Equal(t, int64(3), metadata.Get("active_months").Int())
+	assert.Equal(t, int64(4), metadata.Get("span_months").Int())
+	assert.Equal(t, 1.0, metadata.Get("pages_per_month").Float())
+
+	// A year narrows the timeline, and titles can be left out
+	resp, err = tool.Execute(context.Background(), &ArchiveRequest{HugoSitePath: server.URL, Year: 2023, NoTitles: true})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `[2023]`, result.Get("years.#.year").Raw)
+	assert.False(t, result.Get("years.0.months.0.titles").Exists())
+	assert.Equal(t, int64(3), result.Get("metadata.outside_year_count").Int())
+}
+
+func TestTool_Execute_FromSitemap(t *testing.T) {
+	server := newServer(t, false)
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &ArchiveRequest{HugoSitePath: server.URL})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+
+	assert.Equal(t, SourceSitemap, result.Get("metadata.source").String())
+	assert.Equal(t, "lastmod", result.Get("metadata.date_field").String())
+	assert.Equal(t, `["2024-05"]`, result.Get("years.0.months.#.month").Raw)
+	assert.Equal(t, `["https://example.com/b/","https://example.com/a/"]`, result.Get("years.0.months.0.titles").Raw)
+	assert.Equal(t, int64(1), result.Get("metadata.undated_count").Int())
+	assert.Equal(t, int64(1), result.Get("metadata.duplicates_removed").Int())
+
+	// An explicit source is not fallen back from
+	_, err = tool.Execute(context.Background(), &ArchiveRequest{HugoSitePath: server.URL, Source: SourceIndex})
+	require.Error(t, err)
+}
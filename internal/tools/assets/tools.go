@@ -0,0 +1,463 @@
+package assets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/pagelinks"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/tidwall/gjson"
+)
+
+// Image sources
+const (
+	SourceContent     = "content"
+	SourceFrontMatter = "front_matter"
+)
+
+// frontMatterImageFields are the front matter fields themes commonly use
+// for a page's images
+var frontMatterImageFields = []string{"images", "image", "featured_image", "featuredImage", "cover", "thumbnail"}
+
+// fileExtensions are the extensions of linked files treated as assets
+var fileExtensions = map[string]bool{
+	".pdf": true, ".zip": true, ".gz": true, ".tgz": true, ".bz2": true, ".xz": true, ".7z": true,
+	".csv": true, ".tsv": true, ".xls": true, ".xlsx": true, ".doc": true, ".docx": true,
+	".ppt": true, ".pptx": true, ".odt": true, ".ods": true, ".epub": true,
+	".mp3": true, ".ogg": true, ".wav": true, ".mp4": true, ".webm": true, ".mov": true,
+	".svg": true, ".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".avif": true,
+}
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool extracts the images and other assets of a page on a Hugo site.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// AssetsRequest represents the request parameters for extracting assets.
+type AssetsRequest struct {
+	HugoSitePath string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	Path         string `json:"path" jsonschema:"title=Page Path (e.g. /posts/my-post/)"`
+	Limit        int    `json:"limit,omitempty" jsonschema:"title=Result Limit per Asset Kind,minimum=1,maximum=1000"`
+
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// Image is an image referenced by a page
+type Image struct {
+	Src     string `json:"src"`
+	URL     string `json:"url"`
+	Alt     string `json:"alt,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Caption string `json:"caption,omitempty"`
+	Width   int    `json:"width,omitempty"`
+	Height  int    `json:"height,omitempty"`
+	Source  string `json:"source"`
+}
+
+// Resource is a page bundle resource listed in the site index
+type Resource struct {
+	Name         string `json:"name,omitempty"`
+	Title        string `json:"title,omitempty"`
+	URL          string `json:"url"`
+	ResourceType string `json:"resource_type,omitempty"`
+	MediaType    string `json:"media_type,omitempty"`
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+}
+
+// File is a downloadable file linked from a page
+type File struct {
+	Href      string `json:"href"`
+	URL       string `json:"url"`
+	Text      string `json:"text,omitempty"`
+	Extension string `json:"extension"`
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_extract_assets"),
+		name:        "hugo_reader_extract_assets",
+		description: "Extract the images and assets of a page on a Hugo site: embedded images with their alt text, captions and dimensions, front matter images, page bundle resources listed in the site index, and linked files such as PDFs. All URLs are resolved so they can be referenced or downloaded.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *AssetsRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+	if r.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if u, err := url.Parse(r.Path); err != nil || u.IsAbs() || u.Host != "" {
+		return fmt.Errorf("invalid path: %s (must be relative to the site)", r.Path)
+	}
+	for _, part := range strings.Split(r.Path, "/") {
+		if part == ".." {
+			return fmt.Errorf("invalid path: %s", r.Path)
+		}
+	}
+
+	if r.Limit == 0 {
+		r.Limit = 200
+	} else if r.Limit < 1 || r.Limit > 1000 {
+		return fmt.Errorf("limit must be between 1 and 1000")
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// Execute extracts the assets of a page.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	assetsRequest, ok := req.(*AssetsRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := assetsRequest.SiteOptions.Apply(ctx, t.sites, &assetsRequest.HugoSitePath, &assetsRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := assetsRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := assetsRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = assetsRequest.CacheOptions.Apply(ctx, 0)
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(assetsRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", assetsRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = assetsRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	pageURL := pagelinks.PageURL(siteURL, assetsRequest.Path)
+
+	// The site index is optional; without one only the rendered page is read
+	var page gjson.Result
+	index, err := hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		t.log.DebugContext(ctx, "Site index unavailable", "site", assetsRequest.HugoSitePath, "error", err)
+		index = nil
+	} else if page, _, err = index.Page(ctx, pageURL.Path); err != nil {
+		t.log.DebugContext(ctx, "Failed to read site index", "site", assetsRequest.HugoSitePath, "error", err)
+	}
+
+	body, err := pagelinks.Fetch(ctx, t.cache, t.httpClient, index, siteURL, pageURL)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Page not found", "site", assetsRequest.HugoSitePath, "path", assetsRequest.Path, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "page '%s' not found at Hugo site: %s", assetsRequest.Path, assetsRequest.HugoSitePath)
+	}
+
+	images := append(contentImages(htmltext.Images(body.Content), pageURL), frontMatterImages(page, pageURL)...)
+	images = uniqueImages(images)
+	resources := bundleResources(page, pageURL)
+	files := linkedFiles(htmltext.Links(body.Content), pageURL)
+
+	counts := map[string]int{
+		"images":    len(images),
+		"resources": len(resources),
+		"files":     len(files),
+	}
+	truncated := false
+	if len(images) > assetsRequest.Limit {
+		images, truncated = images[:assetsRequest.Limit], true
+	}
+	if len(resources) > assetsRequest.Limit {
+		resources, truncated = resources[:assetsRequest.Limit], true
+	}
+	if len(files) > assetsRequest.Limit {
+		files, truncated = files[:assetsRequest.Limit], true
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"path":      assetsRequest.Path,
+		"images":    images,
+		"resources": resources,
+		"files":     files,
+		"metadata": map[string]interface{}{
+			"source":          body.Source,
+			"source_endpoint": body.Endpoint,
+			"cached":          body.Cached,
+			"indexed":         page.Exists(),
+			"counts":          counts,
+			"truncated":       truncated,
+			"site_source":     sites.Source(ctx),
+		},
+		"errors": []string{},
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal assets", "error", err)
+		return nil, fmt.Errorf("failed to marshal assets: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Extracted assets", "site", assetsRequest.HugoSitePath, "path", assetsRequest.Path, "source", body.Source, "images", counts["images"], "resources", counts["resources"], "files", counts["files"])
+	return tools.TextResponse(responseJSON), nil
+}
+
+// contentImages resolves the images embedded in a page's body
+func contentImages(found []htmltext.Image, pageURL *url.URL) []Image {
+	images := []Image{}
+	for _, image := range found {
+		resolved, ok := resolve(pageURL, image.Src)
+		if !ok {
+			continue
+		}
+		images = append(images, Image{
+			Src:     image.Src,
+			URL:     resolved,
+			Alt:     image.Alt,
+			Title:   image.Title,
+			Caption: image.Caption,
+			Width:   dimension(image.Width),
+			Height:  dimension(image.Height),
+			Source:  SourceContent,
+		})
+	}
+	return images
+}
+
+// frontMatterImages returns the images named in a page's front matter.
+// Fields may hold a path, a list of paths, or objects with a src or url.
+func frontMatterImages(page gjson.Result, pageURL *url.URL) []Image {
+	images := []Image{}
+	add := func(value gjson.Result) {
+		src, alt := value.String(), ""
+		if value.IsObject() {
+			src = firstString(value, "src", "url", "image", "permalink")
+			alt = firstString(value, "alt", "caption", "title")
+		}
+		if resolved, ok := resolve(pageURL, src); ok {
+			images = append(images, Image{Src: src, URL: resolved, Alt: alt, Source: SourceFrontMatter})
+		}
+	}
+
+	for _, field := range frontMatterImageFields {
+		value := page.Get(field)
+		if !value.Exists() {
+			value = page.Get("params." + field)
+		}
+		if value.IsArray() {
+			value.ForEach(func(_, item gjson.Result) bool {
+				add(item)
+				return true
+			})
+		} else if value.Exists() {
+			add(value)
+		}
+	}
+	return images
+}
+
+// bundleResources returns the page bundle resources listed in a page's
+// index entry, which templates may emit as objects or plain paths
+func bundleResources(page gjson.Result, pageURL *url.URL) []Resource {
+	resources := []Resource{}
+	page.Get("resources").ForEach(func(_, value gjson.Result) bool {
+		src := value.String()
+		resource := Resource{}
+		if value.IsObject() {
+			src = firstString(value, "permalink", "relPermalink", "RelPermalink", "url", "src", "name")
+			resource = Resource{
+				Name:         firstString(value, "name", "Name"),
+				Title:        firstString(value, "title", "Title"),
+				ResourceType: firstString(value, "resourceType", "ResourceType", "resource_type"),
+				MediaType:    firstString(value, "mediaType", "MediaType", "media_type"),
+				Width:        int(value.Get("width").Int()),
+				Height:       int(value.Get("height").Int()),
+			}
+		}
+		if resolved, ok := resolve(pageURL, src); ok {
+			resource.URL = resolved
+			if resource.Name == "" {
+				resource.Name = path.Base(src)
+			}
+			resources = append(resources, resource)
+		}
+		return true
+	})
+	return resources
+}
+
+// linkedFiles returns the links to downloadable files, once per target
+func linkedFiles(links []htmltext.Link, pageURL *url.URL) []File {
+	seen := make(map[string]bool)
+	files := []File{}
+	for _, link := range links {
+		resolved, ok := resolve(pageURL, link.Href)
+		if !ok || seen[resolved] {
+			continue
+		}
+		u, _ := url.Parse(resolved)
+		ext := strings.ToLower(path.Ext(u.Path))
+		if !fileExtensions[ext] {
+			continue
+		}
+		seen[resolved] = true
+		files = append(files, File{Href: link.Href, URL: resolved, Text: link.Text, Extension: strings.TrimPrefix(ext, ".")})
+	}
+	return files
+}
+
+// uniqueImages drops repeated images, keeping the first, which carries the
+// most detail since content images precede front matter ones
+func uniqueImages(images []Image) []Image {
+	seen := make(map[string]bool)
+	result := []Image{}
+	for _, image := range images {
+		if seen[image.URL] {
+			continue
+		}
+		seen[image.URL] = true
+		result = append(result, image)
+	}
+	return result
+}
+
+// resolve resolves ref against the page URL. Data URIs and script links
+// are not assets that can be referenced, so they are rejected.
+func resolve(pageURL *url.URL, ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", false
+	}
+	target, err := url.Parse(ref)
+	if err != nil || target.Scheme == "data" || target.Scheme == "javascript" {
+		return "", false
+	}
+	return pageURL.ResolveReference(target).String(), true
+}
+
+// dimension parses an image width or height attribute, ignoring
+// relative sizes such as percentages
+func dimension(value string) int {
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "px"))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// firstString returns the first non-empty string among fields of value
+func firstString(value gjson.Result, fields ...string) string {
+	for _, field := range fields {
+		if s := value.Get(field).String(); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*AssetsRequest](registry, t, "Content retrieval", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
@@ -0,0 +1,161 @@
+package assets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/pagelinks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNew(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+	assert.NotNil(t, tool)
+	assert.Equal(t, "hugo_reader_extract_assets", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+	assert.NotNil(t, tool.cache)
+	assert.NotNil(t, tool.httpClient)
+}
+
+func TestAssetsRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *AssetsRequest
+		wantErr bool
+	}{
+		{
+			name:    "valid request with defaults",
+			req:     &AssetsRequest{HugoSitePath: "https://example.com", Path: "/posts/my-post/"},
+			wantErr: false,
+		},
+		{
+			name:    "missing hugo_site_path",
+			req:     &AssetsRequest{Path: "/about/"},
+			wantErr: true,
+		},
+		{
+			name:    "missing path",
+			req:     &AssetsRequest{HugoSitePath: "https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "absolute URL path",
+			req:     &AssetsRequest{HugoSitePath: "https://example.com", Path: "https://other.example/"},
+			wantErr: true,
+		},
+		{
+			name:    "path escaping the site",
+			req:     &AssetsRequest{HugoSitePath: "https://example.com", Path: "/posts/../../etc/"},
+			wantErr: true,
+		},
+		{
+			name:    "limit too high",
+			req:     &AssetsRequest{HugoSitePath: "https://example.com", Path: "/about/", Limit: 1001},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 200, tt.req.Limit)
+			}
+		})
+	}
+}
+
+func TestTool_Execute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			w.Write([]byte(`[
+				{"title": "Bundle", "url": "/posts/bundle/", "images": ["cover.jpg", "/shared/og.png"],
+				 "resources": [{"name": "cover.jpg", "relPermalink": "/posts/bundle/cover.jpg", "resourceType": "image", "mediaType": "image/jpeg", "width": 1200, "height": 800}, "notes.pdf"],
+				 "content": "<figure><img src=\"cover.jpg\" alt=\"Cover\" width=\"1200\" height=\"50%\"><figcaption>Cover photo</figcaption></figure><p><a href=\"notes.pdf\">Notes</a>, <a href=\"/about/\">About</a> and <img src=\"data:image/gif;base64,R0lGOD\"></p>"},
+				{"title": "Plain", "url": "/posts/plain/", "content": "Text only"}
+			]`))
+		case "/posts/plain/":
+			w.Write([]byte(`<html><body><header><img src="/logo.svg"></header><main><p>![not](markdown.png)<img src="diagram.png" alt="Diagram"></p></main></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	// Content images come before front matter ones, each listed once
+	resp, err := tool.Execute(context.Background(), &AssetsRequest{HugoSitePath: server.URL, Path: "/posts/bundle/"})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, pagelinks.SourceSiteIndex, result.Get("metadata.source").String())
+	assert.True(t, result.Get("metadata.indexed").Bool())
+	assert.Equal(t, []string{server.URL + "/posts/bundle/cover.jpg", server.URL + "/shared/og.png"}, stringValues(result.Get("images.#.url")))
+	assert.Equal(t, `["content","front_matter"]`, result.Get("images.#.source").Raw)
+	assert.Equal(t, "Cover photo", result.Get("images.0.caption").String())
+	assert.Equal(t, int64(1200), result.Get("images.0.width").Int())
+	assert.False(t, result.Get("images.0.height").Exists())
+
+	assert.Equal(t, `["cover.jpg","notes.pdf"]`, result.Get("resources.#.name").Raw)
+	assert.Equal(t, "image/jpeg", result.Get("resources.0.media_type").String())
+	assert.Equal(t, server.URL+"/posts/bundle/notes.pdf", result.Get("resources.1.url").String())
+
+	assert.Equal(t, `["pdf"]`, result.Get("files.#.extension").Raw)
+	assert.Equal(t, "Notes", result.Get("files.0.text").String())
+
+	// Without HTML in the index, the rendered page's main content is read
+	resp, err = tool.Execute(context.Background(), &AssetsRequest{HugoSitePath: server.URL, Path: "posts/plain"})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, pagelinks.SourcePageHTML, result.Get("metadata.source").String())
+	assert.Equal(t, []string{server.URL + "/posts/plain/diagram.png"}, stringValues(result.Get("images.#.url")))
+	assert.Equal(t, `[]`, result.Get("resources").Raw)
+
+	_, err = tool.Execute(context.Background(), &AssetsRequest{HugoSitePath: server.URL, Path: "/missing/"})
+	assert.Error(t, err)
+}
+
+func TestLinkedFiles(t *testing.T) {
+	pageURL, _ := url.Parse("https://example.com/posts/a/")
+	files := linkedFiles([]htmltext.Link{
+		{Href: "slides.PDF", Text: "Slides"},
+		{Href: "/posts/a/slides.PDF?dl=1", Text: "Again"},
+		{Href: "../b/"},
+		{Href: "https://cdn.example/data.csv#top", Text: "Data"},
+		{Href: "javascript:void(0)"},
+	}, pageURL)
+
+	require.Len(t, files, 3)
+	assert.Equal(t, File{Href: "slides.PDF", URL: "https://example.com/posts/a/slides.PDF", Text: "Slides", Extension: "pdf"}, files[0])
+	assert.Equal(t, "https://example.com/posts/a/slides.PDF?dl=1", files[1].URL)
+	assert.Equal(t, "csv", files[2].Extension)
+}
+
+func TestDimension(t *testing.T) {
+	assert.Equal(t, 640, dimension("640"))
+	assert.Equal(t, 320, dimension(" 320px"))
+	assert.Equal(t, 0, dimension("50%"))
+	assert.Equal(t, 0, dimension(""))
+	assert.Equal(t, 0, dimension("-5"))
+}
+
+// stringValues returns the string values of an array result
+func stringValues(result gjson.Result) []string {
+	values := []string{}
+	for _, value := range result.Array() {
+		values = append(values, value.String())
+	}
+	return values
+}
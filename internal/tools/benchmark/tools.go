@@ -0,0 +1,537 @@
+package benchmark
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/capabilities"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/tidwall/gjson"
+)
+
+// Kinds of endpoint a benchmark times, in the order they are timed
+const (
+	KindIndex   = "index"
+	KindSitemap = "sitemap"
+	KindSearch  = "search_index"
+	KindPage    = "page"
+)
+
+// DefaultRuns is how many times each endpoint is requested by default
+const DefaultRuns = 3
+
+// Thresholds past which an endpoint is reported as a likely cause of slow
+// tool calls
+const (
+	slowFirstByte     = time.Second
+	uncompressedLimit = 100 << 10
+	largeIndexLimit   = 10 << 20
+)
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool times the endpoints tools read from a Hugo site.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// BenchmarkRequest represents the request parameters for benchmarking a site.
+type BenchmarkRequest struct {
+	HugoSitePath string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	PagePath     string `json:"page_path,omitempty" jsonschema:"title=Page to time (default: the first page of the site index, else the home page)"`
+	Runs         int    `json:"runs,omitempty" jsonschema:"title=Requests per endpoint (default: 3),minimum=1,maximum=10"`
+
+	httpclient.RetryOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// Latency summarizes the durations of an endpoint's requests, in milliseconds
+type Latency struct {
+	Min    float64 `json:"min"`
+	Median float64 `json:"median"`
+	Max    float64 `json:"max"`
+}
+
+// Endpoint is the benchmark of one endpoint
+type Endpoint struct {
+	Kind      string `json:"kind"`
+	Endpoint  string `json:"endpoint"`
+	URL       string `json:"url"`
+	Available bool   `json:"available"`
+	Status    int    `json:"status,omitempty"`
+	Error     string `json:"error,omitempty"`
+
+	// Tried lists the candidate endpoints that did not answer before this one
+	Tried []string `json:"tried,omitempty"`
+
+	Runs            int      `json:"runs"`
+	Latency         *Latency `json:"latency_ms,omitempty"`
+	TimeToFirstByte *Latency `json:"ttfb_ms,omitempty"`
+
+	// Size is the decoded size of the body and TransferSize the bytes
+	// received for it
+	Size             int64   `json:"size_bytes,omitempty"`
+	TransferSize     int64   `json:"transfer_bytes,omitempty"`
+	ContentEncoding  string  `json:"content_encoding,omitempty"`
+	CompressionRatio float64 `json:"compression_ratio,omitempty"`
+	ContentType      string  `json:"content_type,omitempty"`
+
+	// Validators is true when responses carry an ETag or Last-Modified, so
+	// expired cache entries are revalidated instead of downloaded again
+	Validators        bool `json:"validators"`
+	ConnectionsReused int  `json:"connections_reused"`
+}
+
+// run is the outcome of one request
+type run struct {
+	status      int
+	duration    time.Duration
+	firstByte   time.Duration
+	transfer    int64
+	size        int64
+	encoding    string
+	contentType string
+	validators  bool
+	reused      bool
+	err         error
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_benchmark_site"),
+		name:        "hugo_reader_benchmark_site",
+		description: "Time requests to the endpoints tools read from a Hugo site: the site index, sitemap, search index and a sample page. Reports latency, time to first byte, sizes and compression for each, with hints on what makes queries slow. Always requests the site, bypassing the cache.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache the sample page is picked through.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *BenchmarkRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+	if r.Runs == 0 {
+		r.Runs = DefaultRuns
+	} else if r.Runs < 1 || r.Runs > 10 {
+		return fmt.Errorf("runs must be between 1 and 10")
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// Execute benchmarks a site.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	benchmarkRequest, ok := req.(*BenchmarkRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := benchmarkRequest.SiteOptions.Apply(ctx, t.sites, &benchmarkRequest.HugoSitePath, &benchmarkRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := benchmarkRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	// Retries would be timed as part of the request, so there are none
+	// unless asked for
+	if benchmarkRequest.MaxRetries == nil {
+		noRetries := 0
+		benchmarkRequest.MaxRetries = &noRetries
+	}
+	ctx, cancel := benchmarkRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(benchmarkRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", benchmarkRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = benchmarkRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	searchPaths := capabilities.SearchIndexPaths
+	if path, ok := sites.Endpoint(ctx, sites.EndpointSearch); ok {
+		searchPaths = append([]string{path}, searchPaths...)
+	}
+
+	start := time.Now()
+	endpoints := []Endpoint{
+		t.benchmark(ctx, siteURL, KindIndex, []string{hugoindex.IndexPath(ctx)}, benchmarkRequest.Runs),
+		t.benchmark(ctx, siteURL, KindSitemap, []string{"/sitemap.xml"}, benchmarkRequest.Runs),
+		t.benchmark(ctx, siteURL, KindSearch, searchPaths, benchmarkRequest.Runs),
+	}
+	if err := ctx.Err(); err != nil {
+		t.log.WarnContext(ctx, "Benchmark cancelled", "site", benchmarkRequest.HugoSitePath, "error", err)
+		return nil, fmt.Errorf("benchmark cancelled: %w", err)
+	}
+	pagePath := benchmarkRequest.PagePath
+	if pagePath == "" {
+		pagePath = t.samplePage(ctx, siteURL)
+	}
+	endpoints = append(endpoints, t.benchmark(ctx, siteURL, KindPage, []string{pagePath}, benchmarkRequest.Runs))
+	if err := ctx.Err(); err != nil {
+		t.log.WarnContext(ctx, "Benchmark cancelled", "site", benchmarkRequest.HugoSitePath, "error", err)
+		return nil, fmt.Errorf("benchmark cancelled: %w", err)
+	}
+
+	metadata := map[string]interface{}{
+		"runs":          benchmarkRequest.Runs,
+		"requests_made": httpclient.RequestsMade(ctx),
+		"duration_ms":   milliseconds(time.Since(start)),
+		"site_source":   sites.Source(ctx),
+	}
+	if slowest := slowestEndpoint(endpoints); slowest != "" {
+		metadata["slowest"] = slowest
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"site":      siteURL.String(),
+		"endpoints": endpoints,
+		"hints":     hints(endpoints),
+		"metadata":  metadata,
+		"errors":    []string{},
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal benchmark results", "error", err)
+		return nil, fmt.Errorf("failed to marshal benchmark results: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Benchmarked site", "site", benchmarkRequest.HugoSitePath, "runs", benchmarkRequest.Runs, "duration", time.Since(start))
+	return tools.TextResponse(responseJSON), nil
+}
+
+// benchmark times runs requests to the first of candidates that answers
+// with a 200, reporting the last candidate when none do
+func (t *Tool) benchmark(ctx context.Context, siteURL *url.URL, kind string, candidates []string, runs int) Endpoint {
+	var tried []string
+	for i, candidate := range candidates {
+		target, err := siteURL.Parse(candidate)
+		if err != nil {
+			tried = append(tried, candidate)
+			continue
+		}
+		result := Endpoint{Kind: kind, Endpoint: target.RequestURI(), URL: target.String()}
+
+		first := t.time(ctx, target.String())
+		if (first.err != nil || first.status != http.StatusOK) && i < len(candidates)-1 {
+			t.log.DebugContext(ctx, "Benchmark candidate unavailable", "url", target.String(), "status", first.status, "error", first.err)
+			tried = append(tried, candidate)
+			continue
+		}
+
+		timed := []run{first}
+		for len(timed) < runs && first.err == nil && first.status == http.StatusOK && ctx.Err() == nil {
+			next := t.time(ctx, target.String())
+			timed = append(timed, next)
+			if next.err != nil {
+				break
+			}
+		}
+		result.Tried = tried
+		summarize(&result, timed)
+		return result
+	}
+	return Endpoint{Kind: kind, Error: "no endpoint to time", Tried: tried}
+}
+
+// time requests rawURL, reading the whole body, and measures the request.
+// The body is requested in any encoding the client can decode and decoded
+// here, so both its transfer and decoded sizes are known.
+func (t *Tool) time(ctx context.Context, rawURL string) run {
+	var outcome run
+	start := time.Now()
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			outcome.reused = info.Reused
+		},
+		GotFirstResponseByte: func() {
+			outcome.firstByte = time.Since(start)
+		},
+	}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodGet, rawURL, nil)
+	if err != nil {
+		outcome.err = err
+		return outcome
+	}
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		outcome.err = err
+		outcome.duration = time.Since(start)
+		return outcome
+	}
+	defer resp.Body.Close()
+
+	outcome.status = resp.StatusCode
+	outcome.encoding = strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if outcome.encoding == "identity" {
+		outcome.encoding = ""
+	}
+	outcome.contentType = resp.Header.Get("Content-Type")
+	outcome.validators = resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+
+	received := &countingReader{Reader: resp.Body}
+	resp.Body = io.NopCloser(received)
+	if err := httpclient.DecodeBody(resp); err != nil {
+		outcome.err = err
+		outcome.duration = time.Since(start)
+		return outcome
+	}
+	outcome.size, outcome.err = io.Copy(io.Discard, resp.Body)
+	outcome.transfer = received.n
+	outcome.duration = time.Since(start)
+	return outcome
+}
+
+// samplePage returns the path of the first page of the site index on the
+// site's host, read through the cache, or the home page
+func (t *Tool) samplePage(ctx context.Context, siteURL *url.URL) string {
+	index, err := hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		t.log.DebugContext(ctx, "Site index unavailable, timing the home page", "error", err)
+		return "/"
+	}
+
+	sample := "/"
+	index.Pages(ctx, func(page gjson.Result) bool {
+		pageURL, err := siteURL.Parse(hugoindex.PageURL(page))
+		if err != nil || pageURL.Path == "" || !strings.EqualFold(pageURL.Host, siteURL.Host) {
+			return true
+		}
+		sample = pageURL.RequestURI()
+		return false
+	})
+	return sample
+}
+
+// summarize records the outcome of the requests timed for an endpoint
+func summarize(endpoint *Endpoint, runs []run) {
+	last := runs[len(runs)-1]
+	endpoint.Runs = len(runs)
+	endpoint.Status = last.status
+	endpoint.Available = last.err == nil && last.status == http.StatusOK
+	if last.err != nil {
+		endpoint.Error = last.err.Error()
+	}
+
+	var durations, firstBytes []time.Duration
+	for _, r := range runs {
+		if r.reused {
+			endpoint.ConnectionsReused++
+		}
+		if r.err != nil {
+			continue
+		}
+		durations = append(durations, r.duration)
+		firstBytes = append(firstBytes, r.firstByte)
+	}
+	endpoint.Latency = latency(durations)
+	endpoint.TimeToFirstByte = latency(firstBytes)
+
+	if last.err != nil {
+		return
+	}
+	endpoint.Size = last.size
+	endpoint.TransferSize = last.transfer
+	endpoint.ContentEncoding = last.encoding
+	endpoint.ContentType = last.contentType
+	endpoint.Validators = last.validators
+	if last.encoding != "" && last.size > 0 {
+		endpoint.CompressionRatio = math.Round(float64(last.transfer)/float64(last.size)*1000) / 1000
+	}
+}
+
+// latency returns the minimum, median and maximum of durations, or nil
+// when there are none
+func latency(durations []time.Duration) *Latency {
+	if len(durations) == 0 {
+		return nil
+	}
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+	return &Latency{
+		Min:    milliseconds(sorted[0]),
+		Median: milliseconds(median),
+		Max:    milliseconds(sorted[len(sorted)-1]),
+	}
+}
+
+// slowestEndpoint returns the kind of the available endpoint with the
+// highest median latency
+func slowestEndpoint(endpoints []Endpoint) string {
+	slowest := ""
+	highest := -1.0
+	for _, endpoint := range endpoints {
+		if endpoint.Available && endpoint.Latency != nil && endpoint.Latency.Median > highest {
+			slowest, highest = endpoint.Kind, endpoint.Latency.Median
+		}
+	}
+	return slowest
+}
+
+// hints explains what in the benchmark is likely to make tool calls slow
+func hints(endpoints []Endpoint) []string {
+	hints := []string{}
+	for _, endpoint := range endpoints {
+		if !endpoint.Available {
+			if endpoint.Kind == KindSearch {
+				hints = append(hints, "No search index answered, so searches scan the site index or feed instead")
+			}
+			continue
+		}
+		if endpoint.TimeToFirstByte != nil && endpoint.TimeToFirstByte.Median >= milliseconds(slowFirstByte) {
+			hints = append(hints, fmt.Sprintf("%s takes %.0f ms to start responding, so the server or network is slow rather than the download", endpoint.Endpoint, endpoint.TimeToFirstByte.Median))
+		}
+		if endpoint.ContentEncoding == "" && endpoint.Size >= uncompressedLimit {
+			hints = append(hints, fmt.Sprintf("%s is served uncompressed (%s); enabling gzip on the server would shrink the transfer", endpoint.Endpoint, formatSize(endpoint.Size)))
+		}
+		if endpoint.Kind == KindIndex && endpoint.Size >= largeIndexLimit {
+			hints = append(hints, fmt.Sprintf("%s is %s; tools that read the whole index, such as content scans and site statistics, are slowed by its size", endpoint.Endpoint, formatSize(endpoint.Size)))
+		}
+		if endpoint.Kind != KindPage && !endpoint.Validators {
+			hints = append(hints, fmt.Sprintf("%s has no ETag or Last-Modified, so it is downloaded again in full once its cache entry expires", endpoint.Endpoint))
+		}
+	}
+	return hints
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// milliseconds returns d in milliseconds, rounded to a tenth
+func milliseconds(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Millisecond)*10) / 10
+}
+
+// formatSize returns n bytes in the largest unit it makes at least one of
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*BenchmarkRequest](registry, t, "Performance optimization", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
@@ -0,0 +1,187 @@
+package benchmark
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNew(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+	assert.NotNil(t, tool)
+	assert.Equal(t, "hugo_reader_benchmark_site", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+	assert.NotNil(t, tool.cache)
+	assert.NotNil(t, tool.httpClient)
+}
+
+func TestBenchmarkRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *BenchmarkRequest
+		wantErr  bool
+		wantRuns int
+	}{
+		{
+			name:     "valid request",
+			req:      &BenchmarkRequest{HugoSitePath: "https://example.com"},
+			wantRuns: DefaultRuns,
+		},
+		{
+			name:     "explicit runs",
+			req:      &BenchmarkRequest{HugoSitePath: "https://example.com", Runs: 5},
+			wantRuns: 5,
+		},
+		{
+			name:    "missing hugo_site_path",
+			req:     &BenchmarkRequest{},
+			wantErr: true,
+		},
+		{
+			name:    "too many runs",
+			req:     &BenchmarkRequest{HugoSitePath: "https://example.com", Runs: 11},
+			wantErr: true,
+		},
+		{
+			name:    "invalid retry backoff",
+			req:     &BenchmarkRequest{HugoSitePath: "https://example.com", RetryOptions: httpclient.RetryOptions{RetryBackoff: "later"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRuns, tt.req.Runs)
+		})
+	}
+}
+
+func TestTool_Execute(t *testing.T) {
+	index := `[{"title":"First Post","permalink":"/posts/first/"}]`
+	page := strings.Repeat("<p>Hello from the first post.</p>", 5000)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = w.Write([]byte(index))
+		case "/sitemap.xml":
+			w.Header().Set("Content-Type", "application/xml")
+			w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+			_, _ = w.Write([]byte(`<urlset><url><loc>/posts/first/</loc></url></urlset>`))
+		case "/posts/first/":
+			var compressed bytes.Buffer
+			gz := gzip.NewWriter(&compressed)
+			_, _ = gz.Write([]byte(page))
+			_ = gz.Close()
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("Content-Encoding", "gzip")
+			_, _ = w.Write(compressed.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &BenchmarkRequest{HugoSitePath: server.URL, Runs: 2})
+	require.NoError(t, err)
+	body := gjson.Parse(resp.Content[0].TextContent.Text)
+
+	assert.True(t, body.Get("success").Bool())
+	endpoints := body.Get("endpoints").Array()
+	require.Len(t, endpoints, 4)
+
+	indexResult := endpoints[0]
+	assert.Equal(t, KindIndex, indexResult.Get("kind").String())
+	assert.True(t, indexResult.Get("available").Bool())
+	assert.Equal(t, int64(2), indexResult.Get("runs").Int())
+	assert.Equal(t, int64(len(index)), indexResult.Get("size_bytes").Int())
+	assert.True(t, indexResult.Get("validators").Bool())
+	assert.True(t, indexResult.Get("latency_ms.median").Exists())
+	assert.Equal(t, int64(1), indexResult.Get("connections_reused").Int())
+
+	// No search index answers, so every candidate is reported as tried
+	searchResult := endpoints[2]
+	assert.Equal(t, KindSearch, searchResult.Get("kind").String())
+	assert.False(t, searchResult.Get("available").Bool())
+	assert.Equal(t, int64(http.StatusNotFound), searchResult.Get("status").Int())
+	assert.Equal(t, int64(1), searchResult.Get("runs").Int())
+	assert.Len(t, searchResult.Get("tried").Array(), 2)
+
+	// The sample page is the first page of the index, served compressed
+	pageResult := endpoints[3]
+	assert.Equal(t, "/posts/first/", pageResult.Get("endpoint").String())
+	assert.Equal(t, "gzip", pageResult.Get("content_encoding").String())
+	assert.Equal(t, int64(len(page)), pageResult.Get("size_bytes").Int())
+	assert.Less(t, pageResult.Get("transfer_bytes").Int(), pageResult.Get("size_bytes").Int())
+	assert.Less(t, pageResult.Get("compression_ratio").Float(), 0.1)
+
+	hints := body.Get("hints").String()
+	assert.Contains(t, hints, "No search index answered")
+	assert.NotContains(t, hints, "uncompressed")
+	// Two runs of three endpoints, a request to each search index candidate
+	// and one reading the index for the sample page
+	assert.Equal(t, int64(10), body.Get("metadata.requests_made").Int())
+}
+
+func TestTool_Execute_PagePath(t *testing.T) {
+	large := strings.Repeat("x", uncompressedLimit)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/about/" {
+			_, _ = w.Write([]byte(large))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &BenchmarkRequest{HugoSitePath: server.URL, PagePath: "/about/", Runs: 1})
+	require.NoError(t, err)
+	body := gjson.Parse(resp.Content[0].TextContent.Text)
+
+	pageResult := body.Get("endpoints.3")
+	assert.Equal(t, "/about/", pageResult.Get("endpoint").String())
+	assert.True(t, pageResult.Get("available").Bool())
+	assert.Equal(t, "page", body.Get("metadata.slowest").String())
+	assert.Contains(t, body.Get("hints").String(), "/about/ is served uncompressed (100.0 KiB)")
+	assert.False(t, body.Get("endpoints.0.available").Bool())
+}
+
+func TestLatency(t *testing.T) {
+	assert.Nil(t, latency(nil))
+
+	l := latency([]time.Duration{30 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond})
+	assert.Equal(t, &Latency{Min: 10, Median: 25, Max: 40}, l)
+}
+
+func TestTool_Execute_InvalidRequest(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+
+	_, err = tool.Execute(context.Background(), &BenchmarkRequest{})
+	assert.Error(t, err)
+}
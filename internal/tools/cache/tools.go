@@ -1,32 +1,61 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	mcp_golang "github.com/metoro-io/mcp-golang"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
 )
 
+// DefaultExportFile is the file the export and import actions use when a
+// request names none
+const DefaultExportFile = "cache.json"
+
+// exportFilePattern is the form of export file names: plain names, so
+// requests cannot reach outside the export directory
+var exportFilePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
 // Tool provides cache management functionality
 type Tool struct {
-	log   *slog.Logger
-	cache *cache.Cache
+	log        *slog.Logger
+	cache      *cache.Cache
+	httpClient *httpclient.Client
+
+	// exportDir is where the export and import actions keep their files
+	exportDir string
 }
 
 // ClearCacheRequest represents the request parameters for clearing cache
 type ClearCacheRequest struct {
-	Action string `json:"action" jsonschema:"enum=clear,enum=stats,enum=clean,title=Cache Action"`
-	Target string `json:"target,omitempty" jsonschema:"title=Target (optional site URL for selective clearing)"`
+	Action string `json:"action" jsonschema:"enum=clear,enum=stats,enum=clean,enum=warm,enum=export,enum=import,title=Cache Action"`
+	Target string `json:"target,omitempty" jsonschema:"title=Target (site URL to warm; optional site URL, host, or URL prefix for selective clearing; optional site URL or host to export)"`
+	File   string `json:"file,omitempty" jsonschema:"title=Export file name in the server's export directory (default: cache.json)"`
+
+	httpclient.RetryOptions
+	httpclient.AuthOptions
 }
 
 // New creates a new cache management tool
 func New(cacheInstance *cache.Cache, opts ...ToolOption) (*Tool, error) {
 	tool := &Tool{
-		cache: cacheInstance,
-		log:   slog.Default().With("tool", "hugo_reader_cache_manager"),
+		cache:      cacheInstance,
+		log:        slog.Default().With("tool", "hugo_reader_cache_manager"),
+		httpClient: httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		exportDir:  DefaultExportDir(),
 	}
 	
 	for _, opt := range opts {
@@ -49,25 +78,69 @@ func WithLogger(logger *slog.Logger) ToolOption {
 	}
 }
 
+// WithHTTPClient sets the HTTP client used to warm the cache
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithExportDir sets the directory the export and import actions keep
+// their files in
+func WithExportDir(dir string) ToolOption {
+	return func(t *Tool) error {
+		if dir != "" {
+			t.exportDir = dir
+		}
+		return nil
+	}
+}
+
+// DefaultExportDir returns the directory cache exports are kept in unless
+// configured otherwise: hugo-reader/exports in the user's cache directory
+func DefaultExportDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "hugo-reader", "exports")
+}
+
 // Validate implements tools.Request
 func (r *ClearCacheRequest) Validate() error {
 	switch r.Action {
 	case "clear", "stats", "clean":
-		return nil
+	case "warm":
+		if r.Target == "" {
+			return fmt.Errorf("target is required for the warm action")
+		}
+	case "export", "import":
+		if r.File == "" {
+			r.File = DefaultExportFile
+		}
+		if !exportFilePattern.MatchString(r.File) {
+			return fmt.Errorf("file must be a plain file name of letters, digits, '.', '-' or '_'")
+		}
 	default:
-		return fmt.Errorf("invalid action: %s (must be: clear, stats, or clean)", r.Action)
+		return fmt.Errorf("invalid action: %s (must be: clear, stats, clean, warm, export, or import)", r.Action)
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
 	}
+	return r.AuthOptions.Validate()
 }
 
 // Execute manages cache operations
-func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
 	cacheRequest, ok := req.(*ClearCacheRequest)
 	if !ok {
-		return nil, fmt.Errorf("invalid request type: %T", req)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
 	}
 	
 	if err := cacheRequest.Validate(); err != nil {
-		return nil, err
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
 	}
 	
 	switch cacheRequest.Action {
@@ -77,8 +150,22 @@ func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
 		return t.getCacheStats()
 	case "clean":
 		return t.cleanExpired()
+	case "warm":
+		siteURL, err := parseSiteURL(cacheRequest.Target)
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := cacheRequest.RetryOptions.Apply(ctx)
+		defer cancel()
+		// Send credentials, if any, only to the site itself
+		ctx = cacheRequest.AuthOptions.Apply(ctx, siteURL.Host)
+		return t.warmCache(ctx, siteURL)
+	case "export":
+		return t.exportCache(cacheRequest.Target, cacheRequest.File)
+	case "import":
+		return t.importCache(cacheRequest.File)
 	default:
-		return nil, fmt.Errorf("unknown action: %s", cacheRequest.Action)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "unknown action: %s", cacheRequest.Action)
 	}
 }
 
@@ -96,23 +183,48 @@ func (t *Tool) clearCache(target string) (*mcp_golang.ToolResponse, error) {
 		}
 		
 		responseJSON, _ := json.Marshal(response)
-		return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(string(responseJSON))), nil
+		return tools.TextResponse(responseJSON), nil
 	}
 	
-	// TODO: Implement selective clearing by site URL pattern
-	// For now, treat any target as "clear all"
-	t.cache.Clear()
-	t.log.Info("Cleared cache entries", "target", target)
+	var removedCount int
+	var matchedBy string
+	if host, prefix := parseTarget(target); prefix != "" {
+		removedCount = t.cache.DeleteByPrefix(prefix)
+		matchedBy = "prefix"
+	} else {
+		removedCount = t.cache.DeleteByHost(host)
+		matchedBy = "host"
+	}
+	t.log.Info("Cleared cache entries", "target", target, "matched_by", matchedBy, "removed_count", removedCount)
 	
 	response := map[string]interface{}{
-		"success": true,
-		"message": fmt.Sprintf("Cache entries cleared for target: %s", target),
-		"action":  "clear_targeted",
-		"target":  target,
+		"success":       true,
+		"message":       fmt.Sprintf("Removed %d cache entries for target: %s", removedCount, target),
+		"action":        "clear_targeted",
+		"target":        target,
+		"matched_by":    matchedBy,
+		"removed_count": removedCount,
 	}
 	
 	responseJSON, _ := json.Marshal(response)
-	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(string(responseJSON))), nil
+	return tools.TextResponse(responseJSON), nil
+}
+
+// parseTarget interprets a clear target. A bare host or site root URL clears
+// everything for that host; a URL with a path clears entries under that prefix.
+func parseTarget(target string) (host, prefix string) {
+	if !strings.Contains(target, "://") {
+		return strings.TrimRight(target, "/"), ""
+	}
+
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return "", target
+	}
+	if u.Path == "" || u.Path == "/" {
+		return u.Host, ""
+	}
+	return "", target
 }
 
 // getCacheStats returns cache statistics
@@ -132,7 +244,7 @@ func (t *Tool) getCacheStats() (*mcp_golang.ToolResponse, error) {
 	}
 	
 	t.log.Debug("Retrieved cache stats", "stats", stats)
-	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(string(responseJSON))), nil
+	return tools.TextResponse(responseJSON), nil
 }
 
 // cleanExpired removes expired cache entries
@@ -149,7 +261,100 @@ func (t *Tool) cleanExpired() (*mcp_golang.ToolResponse, error) {
 	responseJSON, _ := json.Marshal(response)
 	t.log.Info("Cleaned expired cache entries", "removed_count", removedCount)
 	
-	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(string(responseJSON))), nil
+	return tools.TextResponse(responseJSON), nil
+}
+
+// exportCache writes the cache, or the entries of the target's host, to a
+// file in the export directory. The file is replaced atomically and only
+// readable by the user running the server, as entries may hold content of
+// protected sites.
+func (t *Tool) exportCache(target, file string) (*mcp_golang.ToolResponse, error) {
+	host := ""
+	if target != "" {
+		if strings.Contains(target, "://") {
+			u, err := url.Parse(target)
+			if err != nil || u.Host == "" {
+				return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid target: %s", target)
+			}
+			host = u.Host
+		} else {
+			host = strings.TrimRight(target, "/")
+		}
+	}
+
+	if err := os.MkdirAll(t.exportDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(t.exportDir, "."+file+".*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	summary, err := t.cache.Export(tmp, host)
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write export file: %w", closeErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(t.exportDir, file)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to write export file: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write export file: %w", err)
+	}
+	t.log.Info("Exported cache", "file", path, "host", host, "entries", summary.Entries)
+
+	response := map[string]interface{}{
+		"success":        true,
+		"action":         "export",
+		"file":           path,
+		"size":           info.Size(),
+		"entries":        summary.Entries,
+		"snapshot_sites": summary.SnapshotSites,
+		"endpoint_sites": summary.EndpointSites,
+	}
+	if host != "" {
+		response["host"] = host
+	}
+
+	responseJSON, _ := json.Marshal(response)
+	return tools.TextResponse(responseJSON), nil
+}
+
+// importCache loads a file written by exportCache into the cache
+func (t *Tool) importCache(file string) (*mcp_golang.ToolResponse, error) {
+	path := filepath.Join(t.exportDir, file)
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "no cache export named %s in %s", file, t.exportDir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export file: %w", err)
+	}
+	defer f.Close()
+
+	summary, err := t.cache.Import(f)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeParseError, err)
+	}
+	t.log.Info("Imported cache", "file", path, "entries", summary.Entries, "expired", summary.Expired)
+
+	response := map[string]interface{}{
+		"success":        true,
+		"action":         "import",
+		"file":           path,
+		"entries":        summary.Entries,
+		"expired":        summary.Expired,
+		"snapshot_sites": summary.SnapshotSites,
+		"endpoint_sites": summary.EndpointSites,
+	}
+
+	responseJSON, _ := json.Marshal(response)
+	return tools.TextResponse(responseJSON), nil
 }
 
 // Name returns the tool name
@@ -159,7 +364,12 @@ func (t *Tool) Name() string {
 
 // Description returns the tool description
 func (t *Tool) Description() string {
-	return "Manage Hugo reader cache with smart HTTP validation. Actions: 'clear' (remove all/specific entries), 'stats' (cache statistics), 'clean' (remove expired entries). Use 'clear' if getting stale data."
+	return "Manage Hugo reader cache with smart HTTP validation. Actions: 'clear' (remove all/specific entries), 'stats' (cache statistics), 'clean' (remove expired entries), 'warm' (prefetch a site's index, sitemap, taxonomies and sections), 'export' (save the cache, or one site's entries, to a file) and 'import' (reload an export, e.g. after a restart). Use 'clear' if getting stale data, 'warm' before exploring a site."
+}
+
+// Register adds the tool to registry
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*ClearCacheRequest](registry, t, "Performance optimization", tools.Annotations{DestructiveHint: true, OpenWorldHint: true})
 }
 
 // SetLogger sets the logger for the tool
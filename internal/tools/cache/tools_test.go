@@ -1,11 +1,21 @@
 package cache
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 )
 
 func TestNew(t *testing.T) {
@@ -14,7 +24,7 @@ func TestNew(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(t, tool)
 	assert.Equal(t, "hugo_reader_cache_manager", tool.Name())
-	assert.Equal(t, "Manage Hugo reader cache with smart HTTP validation. Actions: 'clear' (remove all/specific entries), 'stats' (cache statistics), 'clean' (remove expired entries). Use 'clear' if getting stale data.", tool.Description())
+	assert.Equal(t, "Manage Hugo reader cache with smart HTTP validation. Actions: 'clear' (remove all/specific entries), 'stats' (cache statistics), 'clean' (remove expired entries), 'warm' (prefetch a site's index, sitemap, taxonomies and sections), 'export' (save the cache, or one site's entries, to a file) and 'import' (reload an export, e.g. after a restart). Use 'clear' if getting stale data, 'warm' before exploring a site.", tool.Description())
 }
 
 func TestClearCacheRequest_Validate(t *testing.T) {
@@ -71,13 +81,19 @@ func TestTool_Execute_Stats(t *testing.T) {
 	cacheInstance.Set("test-key", []byte("test data"), "", "")
 
 	req := &ClearCacheRequest{Action: "stats"}
-	resp, err := tool.Execute(req)
+	resp, err := tool.Execute(context.Background(), req)
 	require.NoError(t, err)
 	assert.NotNil(t, resp)
 	
 	// Response should be a ToolResponse with text content
 	assert.NotNil(t, resp.Content)
 	assert.Len(t, resp.Content, 1)
+
+	// Counters and limits are reported
+	text := resp.Content[0].TextContent.Text
+	for _, field := range []string{"hits", "misses", "hit_rate", "evictions", "expirations", "max_entries", "max_bytes"} {
+		assert.Contains(t, text, `"`+field+`"`)
+	}
 }
 
 func TestTool_Execute_Clear(t *testing.T) {
@@ -93,7 +109,7 @@ func TestTool_Execute_Clear(t *testing.T) {
 	assert.True(t, found)
 
 	req := &ClearCacheRequest{Action: "clear"}
-	resp, err := tool.Execute(req)
+	resp, err := tool.Execute(context.Background(), req)
 	require.NoError(t, err)
 	assert.NotNil(t, resp)
 
@@ -112,7 +128,7 @@ func TestTool_Execute_Clean(t *testing.T) {
 	require.NoError(t, err)
 
 	req := &ClearCacheRequest{Action: "clean"}
-	resp, err := tool.Execute(req)
+	resp, err := tool.Execute(context.Background(), req)
 	require.NoError(t, err)
 	assert.NotNil(t, resp)
 	
@@ -136,7 +152,7 @@ func TestTool_Execute_InvalidRequest(t *testing.T) {
 
 	// Test with invalid request type
 	req := &invalidRequest{Invalid: "test"}
-	_, err = tool.Execute(req)
+	_, err = tool.Execute(context.Background(), req)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid request type")
 }
@@ -152,4 +168,162 @@ func TestTool_SetLogger(t *testing.T) {
 
 	// Test that it doesn't panic with valid logger
 	// We can't easily test the logger content without more setup
-}
\ No newline at end of file
+}
+func TestTool_Execute_ClearTarget(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		remaining []string
+	}{
+		{
+			name:      "site URL",
+			target:    "https://example.com/",
+			remaining: []string{"https://other.com/index.json"},
+		},
+		{
+			name:      "bare host",
+			target:    "example.com",
+			remaining: []string{"https://other.com/index.json"},
+		},
+		{
+			name:      "URL prefix",
+			target:    "https://example.com/posts/",
+			remaining: []string{"https://example.com/index.json", "https://other.com/index.json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheInstance := cache.New()
+			tool, err := New(cacheInstance)
+			require.NoError(t, err)
+
+			keys := []string{
+				"https://example.com/index.json",
+				"https://example.com/posts/index.json",
+				"https://other.com/index.json",
+			}
+			for _, key := range keys {
+				cacheInstance.Set(key, []byte("data"), "", "")
+			}
+
+			_, err = tool.Execute(context.Background(), &ClearCacheRequest{Action: "clear", Target: tt.target})
+			require.NoError(t, err)
+
+			for _, key := range keys {
+				_, found := cacheInstance.Get(key)
+				assert.Equal(t, slices.Contains(tt.remaining, key), found, key)
+			}
+		})
+	}
+}
+
+func TestTool_Execute_Warm(t *testing.T) {
+	var requests sync.Map
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Store(r.URL.Path, true)
+		switch r.URL.Path {
+		case "/index.json":
+			w.Write([]byte(`{"pages": [{"url": "/posts/a/"}, {"url": "/docs/b/", "section": "docs"}, {"url": "/about/"}]}`))
+		case "/tags/index.json", "/posts/index.json":
+			w.Write([]byte(`{"pages": []}`))
+		case "/sitemap.xml":
+			w.Write([]byte(`<urlset><url><loc>/posts/a/</loc></url></urlset>`))
+		case "/categories/index.json":
+			w.Write([]byte(`<html>not json</html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cacheInstance := cache.New()
+	tool, err := New(cacheInstance)
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &ClearCacheRequest{Action: "warm", Target: server.URL})
+	require.NoError(t, err)
+
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.True(t, result.Get("success").Bool())
+	assert.Equal(t, int64(4), result.Get("warmed_count").Int())
+
+	// Entries are cached under the keys the tools use
+	for _, path := range []string{"/index.json", "/sitemap.xml", "/tags/index.json", "/posts/index.json"} {
+		_, found := cacheInstance.Get(cacheInstance.BuildKey(server.URL, path, nil))
+		assert.True(t, found, path)
+	}
+
+	// Invalid responses are not cached
+	_, found := cacheInstance.Get(cacheInstance.BuildKey(server.URL, "/categories/index.json", nil))
+	assert.False(t, found)
+
+	// Sections are discovered from the index, but not top-level pages
+	_, requested := requests.Load("/docs/index.json")
+	assert.True(t, requested)
+	_, requested = requests.Load("/about/index.json")
+	assert.False(t, requested)
+}
+
+func TestClearCacheRequest_Validate_Warm(t *testing.T) {
+	assert.Error(t, (&ClearCacheRequest{Action: "warm"}).Validate())
+	assert.NoError(t, (&ClearCacheRequest{Action: "warm", Target: "example.com"}).Validate())
+}
+
+func TestTool_Execute_ExportImport(t *testing.T) {
+	dir := t.TempDir()
+	source := cache.New()
+	source.Set("https://example.com/index.json", []byte(`{"pages":[]}`), `"v1"`, "")
+	source.Set("https://other.example.com/index.json", []byte(`{}`), "", "")
+	source.SetNegative("https://example.com/missing.json", http.StatusNotFound)
+	source.AddSnapshot("https://example.com", &cache.Snapshot{TakenAt: time.Now(), Source: "index", Pages: map[string]string{"/a/": "1"}})
+
+	tool, err := New(source, WithExportDir(dir))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Everything is exported by default
+	resp, err := tool.Execute(ctx, &ClearCacheRequest{Action: "export"})
+	require.NoError(t, err)
+	text := resp.Content[0].TextContent.Text
+	assert.Equal(t, filepath.Join(dir, DefaultExportFile), gjson.Get(text, "file").String())
+	assert.Equal(t, int64(3), gjson.Get(text, "entries").Int())
+	assert.Equal(t, int64(1), gjson.Get(text, "snapshot_sites").Int())
+	info, err := os.Stat(filepath.Join(dir, DefaultExportFile))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// A target exports one site's subset
+	resp, err = tool.Execute(ctx, &ClearCacheRequest{Action: "export", Target: "https://example.com/", File: "example.json"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), gjson.Get(resp.Content[0].TextContent.Text, "entries").Int())
+
+	// Importing into an empty cache restores entries, validators and snapshots
+	restored := cache.New()
+	tool, err = New(restored, WithExportDir(dir))
+	require.NoError(t, err)
+	resp, err = tool.Execute(ctx, &ClearCacheRequest{Action: "import", File: "example.json"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), gjson.Get(resp.Content[0].TextContent.Text, "entries").Int())
+
+	data, ok := restored.Get("https://example.com/index.json")
+	require.True(t, ok)
+	assert.Equal(t, `{"pages":[]}`, string(data))
+	status, ok := restored.GetNegative("https://example.com/missing.json")
+	require.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, status)
+	_, ok = restored.Get("https://other.example.com/index.json")
+	assert.False(t, ok)
+	snapshot, ok := restored.LatestSnapshot("https://example.com")
+	require.True(t, ok)
+	assert.Equal(t, "1", snapshot.Pages["/a/"])
+
+	_, err = tool.Execute(ctx, &ClearCacheRequest{Action: "import", File: "missing.json"})
+	assert.Equal(t, toolerrors.ErrCodeNotFound, toolerrors.Code(err))
+
+	// File names cannot leave the export directory
+	for _, file := range []string{"../cache.json", "/etc/passwd", "sub/cache.json", ".hidden"} {
+		_, err = tool.Execute(ctx, &ClearCacheRequest{Action: "export", File: file})
+		assert.Error(t, err, file)
+	}
+}
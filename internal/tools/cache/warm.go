@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	"github.com/tidwall/gjson"
+)
+
+// warmConcurrency bounds the number of simultaneous requests while warming
+const warmConcurrency = 6
+
+// maxWarmSections bounds how many section indexes are prefetched
+const maxWarmSections = 20
+
+// warmEndpoints are the site-wide endpoints the other tools read first
+var warmEndpoints = []string{
+	"/sitemap.xml",
+	"/taxonomies/index.json",
+	"/api/taxonomies.json",
+	"/categories/index.json",
+	"/tags/index.json",
+	"/series/index.json",
+	"/authors/index.json",
+}
+
+// warmResult is the outcome of prefetching a single endpoint
+type warmResult struct {
+	Path   string `json:"path"`
+	Size   int    `json:"size,omitempty"`
+	Cached bool   `json:"cached,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// warmCache prefetches a site's index, sitemap, taxonomies and top-level
+// section indexes into the cache, under the same keys the tools use
+func (t *Tool) warmCache(ctx context.Context, siteURL *url.URL) (*mcp_golang.ToolResponse, error) {
+	start := time.Now()
+
+	// The index tells us which sections exist, so fetch it first
+	indexResult, indexData := t.warmEndpoint(ctx, siteURL, hugoindex.Path)
+	results := []warmResult{indexResult}
+
+	paths := append([]string{}, warmEndpoints...)
+	for _, path := range sectionIndexes(indexData) {
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, warmConcurrency)
+	for _, path := range paths {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				mu.Lock()
+				results = append(results, warmResult{Path: path, Error: ctx.Err().Error()})
+				mu.Unlock()
+				return
+			}
+
+			result, _ := t.warmEndpoint(ctx, siteURL, path)
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}(path)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+
+	warmed := []warmResult{}
+	failed := []warmResult{}
+	for _, result := range results {
+		if result.Error == "" {
+			warmed = append(warmed, result)
+		} else {
+			failed = append(failed, result)
+		}
+	}
+
+	response := map[string]interface{}{
+		"success":      len(warmed) > 0,
+		"action":       "warm",
+		"target":       siteURL.String(),
+		"warmed_count": len(warmed),
+		"warmed":       warmed,
+		"skipped":      failed,
+		"duration_ms":  time.Since(start).Milliseconds(),
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal warm results", "error", err)
+		return nil, fmt.Errorf("failed to marshal warm results: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Warmed cache", "site", siteURL.String(), "warmed", len(warmed), "skipped", len(failed), "duration", time.Since(start))
+	return tools.TextResponse(responseJSON), nil
+}
+
+// parseSiteURL parses a site URL, allowing bare hosts such as example.com
+func parseSiteURL(target string) (*url.URL, error) {
+	if !strings.Contains(target, "://") {
+		target = "https://" + target
+	}
+	siteURL, err := url.Parse(target)
+	if err != nil || siteURL.Host == "" {
+		return nil, fmt.Errorf("invalid site URL: %s", target)
+	}
+	return siteURL, nil
+}
+
+// warmEndpoint fetches a single endpoint and caches it if it is usable
+func (t *Tool) warmEndpoint(ctx context.Context, siteURL *url.URL, path string) (warmResult, []byte) {
+	result := warmResult{Path: path}
+	endpointURL := siteURL.ResolveReference(&url.URL{Path: path})
+
+	// Only cache responses the tools could use, not HTML error pages
+	validator := gjson.ValidBytes
+	if path == hugoindex.Path {
+		validator = hugoindex.Valid
+	} else if strings.HasSuffix(path, ".xml") {
+		validator = func(data []byte) bool {
+			return xml.Unmarshal(data, new(struct{})) == nil
+		}
+	}
+
+	cacheKey := t.cache.BuildKey(siteURL.String(), path, nil)
+	fetched, err := t.cache.Fetch(ctx, t.httpClient, cacheKey, endpointURL.String(), validator)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	result.Size = len(fetched.Data)
+	result.Cached = fetched.Cached || fetched.Revalidated
+	return result, fetched.Data
+}
+
+// sectionIndexes returns the index.json paths of the top-level sections
+// referenced by a Hugo site index
+func sectionIndexes(indexData []byte) []string {
+	if len(indexData) == 0 {
+		return nil
+	}
+
+	counts, _ := hugoindex.New("", indexData).Sections(context.Background())
+
+	sections := make(map[string]bool)
+	for section := range counts {
+		sections[strings.ToLower(section)] = true
+	}
+
+	paths := make([]string, 0, len(sections))
+	for section := range sections {
+		paths = append(paths, fmt.Sprintf("/%s/index.json", section))
+	}
+	sort.Strings(paths)
+
+	if len(paths) > maxWarmSections {
+		paths = paths[:maxWarmSections]
+	}
+	return paths
+}
@@ -0,0 +1,227 @@
+package changes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultPollInterval is how often the poller checks its sites by default
+const DefaultPollInterval = 15 * time.Minute
+
+// WebhookTimeout bounds how long posting a notification may take
+const WebhookTimeout = 10 * time.Second
+
+// Notification is the JSON body posted to the webhook when a site changes
+type Notification struct {
+	Event            string         `json:"event"`
+	Site             string         `json:"site"`
+	Alias            string         `json:"alias,omitempty"`
+	Source           string         `json:"source"`
+	PreviousSnapshot string         `json:"previous_snapshot"`
+	CurrentSnapshot  string         `json:"current_snapshot"`
+	Summary          map[string]int `json:"summary"`
+	Added            []string       `json:"added"`
+	Removed          []string       `json:"removed"`
+	Modified         []string       `json:"modified"`
+	Truncated        bool           `json:"truncated"`
+}
+
+// PollerOption configures a Poller
+type PollerOption func(*Poller)
+
+// Poller checks sites for changes at an interval, posting a notification
+// to a webhook for each site whose pages were added, removed or modified.
+// It records snapshots in the same cache as the tool, so a check by either
+// is compared against the latest snapshot taken by both.
+type Poller struct {
+	log        *slog.Logger
+	tool       *Tool
+	webhookURL string
+	interval   time.Duration
+	sites      []string
+	limit      int
+	client     *http.Client
+}
+
+// NewPoller creates a Poller that detects changes with tool and posts them
+// to webhookURL
+func NewPoller(tool *Tool, webhookURL string, opts ...PollerOption) *Poller {
+	p := &Poller{
+		log:        slog.Default().With("component", "change_poller"),
+		tool:       tool,
+		webhookURL: webhookURL,
+		interval:   DefaultPollInterval,
+		limit:      100,
+	}
+	// Post through the tool's transport, so notifications use its proxy
+	p.client = &http.Client{Transport: tool.httpClient.HTTPClient().Transport, Timeout: WebhookTimeout}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// WithPollerLogger sets the logger for the Poller
+func WithPollerLogger(logger *slog.Logger) PollerOption {
+	return func(p *Poller) {
+		p.log = logger.With("component", "change_poller")
+	}
+}
+
+// WithPollInterval sets how often sites are checked
+func WithPollInterval(interval time.Duration) PollerOption {
+	return func(p *Poller) {
+		if interval > 0 {
+			p.interval = interval
+		}
+	}
+}
+
+// WithWatchedSites sets the aliases or URLs of the sites checked. Without
+// any, every site registered when a check starts is checked.
+func WithWatchedSites(sites []string) PollerOption {
+	return func(p *Poller) {
+		p.sites = sites
+	}
+}
+
+// WithWebhookClient sets the HTTP client notifications are posted with,
+// such as one allowed to reach a webhook on a private network
+func WithWebhookClient(client *http.Client) PollerOption {
+	return func(p *Poller) {
+		p.client = client
+	}
+}
+
+// Start checks the sites every interval in a background goroutine, the
+// first check recording a baseline. Call the returned function to stop it.
+func (p *Poller) Start() (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			p.Poll(ctx)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	p.log.InfoContext(ctx, "Started change poller", "interval", p.interval, "sites", len(p.sites))
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// Poll checks each site once, posting a notification for each that changed
+func (p *Poller) Poll(ctx context.Context) {
+	for _, site := range p.watched() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		req := &DetectChangesRequest{Limit: p.limit}
+		alias := ""
+		if _, ok := p.tool.sites.Get(site); ok {
+			req.Site = site
+			alias = site
+		} else {
+			req.HugoSitePath = site
+		}
+
+		report, err := p.tool.Detect(ctx, req)
+		if err != nil {
+			p.log.WarnContext(ctx, "Change check failed", "site", site, "error", err)
+			continue
+		}
+		if report.Baseline || !report.Changed() {
+			continue
+		}
+
+		if err := p.notify(ctx, notification(report, alias, p.limit)); err != nil {
+			p.log.WarnContext(ctx, "Failed to post change notification", "site", report.Site, "error", err)
+			continue
+		}
+		p.log.InfoContext(ctx, "Posted change notification", "site", report.Site, "added", len(report.Added), "removed", len(report.Removed), "modified", len(report.Modified))
+	}
+}
+
+// watched returns the sites to check
+func (p *Poller) watched() []string {
+	if len(p.sites) > 0 {
+		return p.sites
+	}
+	var aliases []string
+	for _, site := range p.tool.sites.List() {
+		aliases = append(aliases, site.Alias)
+	}
+	return aliases
+}
+
+// notification describes a report for the webhook, listing at most limit
+// pages per change type
+func notification(report *Report, alias string, limit int) Notification {
+	return Notification{
+		Event:            "site_changed",
+		Site:             report.Site,
+		Alias:            alias,
+		Source:           report.Source,
+		PreviousSnapshot: report.Previous.Format(time.RFC3339),
+		CurrentSnapshot:  report.Current.Format(time.RFC3339),
+		Summary: map[string]int{
+			"added":       len(report.Added),
+			"removed":     len(report.Removed),
+			"modified":    len(report.Modified),
+			"unchanged":   report.Unchanged,
+			"total_pages": report.TotalPages,
+		},
+		Added:     truncate(report.Added, limit),
+		Removed:   truncate(report.Removed, limit),
+		Modified:  truncate(report.Modified, limit),
+		Truncated: len(report.Added) > limit || len(report.Removed) > limit || len(report.Modified) > limit,
+	}
+}
+
+// notify posts a notification to the webhook
+func (p *Poller) notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if userAgent := p.tool.httpClient.UserAgent(); userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package changes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoller_Poll(t *testing.T) {
+	index := `[{"title": "One", "url": "/one/"}]`
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(index))
+	}))
+	defer site.Close()
+
+	var notifications []Notification
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var n Notification
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&n))
+		notifications = append(notifications, n)
+	}))
+	defer webhook.Close()
+
+	registry := sites.New()
+	require.NoError(t, registry.Register(sites.Site{Alias: "blog", URL: site.URL}))
+	tool, err := New(WithSites(registry))
+	require.NoError(t, err)
+	poller := NewPoller(tool, webhook.URL)
+
+	// The first poll records a baseline, and unchanged sites are not reported
+	poller.Poll(context.Background())
+	poller.Poll(context.Background())
+	assert.Empty(t, notifications)
+
+	index = `[{"title": "One, edited", "url": "/one/"}, {"title": "Two", "url": "/two/"}]`
+	poller.Poll(context.Background())
+	require.Len(t, notifications, 1)
+	n := notifications[0]
+	assert.Equal(t, "site_changed", n.Event)
+	assert.Equal(t, "blog", n.Alias)
+	assert.Equal(t, []string{"/two/"}, n.Added)
+	assert.Equal(t, []string{"/one/"}, n.Modified)
+	assert.Equal(t, []string{}, n.Removed)
+	assert.Equal(t, 2, n.Summary["total_pages"])
+}
+
+func TestPoller_WatchedSites(t *testing.T) {
+	registry := sites.New()
+	require.NoError(t, registry.Register(sites.Site{Alias: "blog", URL: "https://blog.example.com"}))
+	tool, err := New(WithSites(registry))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"blog"}, NewPoller(tool, "https://hooks.example.com").watched())
+	assert.Equal(t, []string{"https://docs.example.com"}, NewPoller(tool, "https://hooks.example.com", WithWatchedSites([]string{"https://docs.example.com"})).watched())
+}
+
+func TestPoller_WebhookError(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer webhook.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+	err = NewPoller(tool, webhook.URL).notify(context.Background(), Notification{Event: "site_changed"})
+	assert.ErrorContains(t, err, "HTTP 500")
+}
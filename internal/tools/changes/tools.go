@@ -0,0 +1,463 @@
+package changes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/tidwall/gjson"
+)
+
+// Page sources
+const (
+	SourceAuto    = "auto"
+	SourceIndex   = "index"
+	SourceSitemap = "sitemap"
+)
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool detects content changes in Hugo sites by comparing the current page
+// list against the last snapshot taken.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// DetectChangesRequest represents the request parameters for change detection.
+type DetectChangesRequest struct {
+	HugoSitePath string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	Source       string `json:"source,omitempty" jsonschema:"enum=auto,enum=index,enum=sitemap,title=Page Source"`
+	Limit        int    `json:"limit,omitempty" jsonschema:"title=Maximum pages listed per change type,minimum=1,maximum=1000"`
+
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_detect_changes"),
+		name:        "hugo_reader_detect_changes",
+		description: "Detect content changes in a Hugo site since the last check. Compares the current index.json or sitemap.xml against the previous snapshot and reports added, removed, and modified pages. The first call for a site records a baseline.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache that holds snapshots for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *DetectChangesRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+
+	switch r.Source {
+	case "":
+		r.Source = SourceAuto
+	case SourceAuto, SourceIndex, SourceSitemap:
+	default:
+		return fmt.Errorf("invalid source: %s (must be: auto, index, or sitemap)", r.Source)
+	}
+
+	if r.Limit == 0 {
+		r.Limit = 100
+	} else if r.Limit < 1 || r.Limit > 1000 {
+		return fmt.Errorf("limit must be between 1 and 1000")
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// Report describes the changes found by one check of a site
+type Report struct {
+	Site       string
+	SiteSource string
+	Source     string
+	Baseline   bool
+
+	// Previous is the zero time for a baseline
+	Previous time.Time
+	Current  time.Time
+
+	Added      []string
+	Removed    []string
+	Modified   []string
+	Unchanged  int
+	TotalPages int
+
+	// Comparable is false when the previous snapshot came from another
+	// source, so modifications could not be detected
+	Comparable bool
+}
+
+// Changed reports whether any page was added, removed or modified
+func (r *Report) Changed() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0 || len(r.Modified) > 0
+}
+
+// Execute compares the site's current pages with the last snapshot.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	changesRequest, ok := req.(*DetectChangesRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	report, err := t.Detect(ctx, changesRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	response := map[string]interface{}{
+		"success":          true,
+		"site":             report.Site,
+		"site_source":      report.SiteSource,
+		"source":           report.Source,
+		"current_snapshot": report.Current.Format(time.RFC3339),
+		"errors":           []string{},
+	}
+
+	if report.Baseline {
+		response["baseline"] = true
+		response["message"] = "No previous snapshot for this site; recorded a baseline to compare future checks against"
+		response["summary"] = map[string]int{"total_pages": report.TotalPages}
+	} else {
+		response["baseline"] = false
+		response["previous_snapshot"] = report.Previous.Format(time.RFC3339)
+		response["summary"] = map[string]int{
+			"added":       len(report.Added),
+			"removed":     len(report.Removed),
+			"modified":    len(report.Modified),
+			"unchanged":   report.Unchanged,
+			"total_pages": report.TotalPages,
+		}
+		limit := changesRequest.Limit
+		response["added"] = truncate(report.Added, limit)
+		response["removed"] = truncate(report.Removed, limit)
+		response["modified"] = truncate(report.Modified, limit)
+		response["truncated"] = len(report.Added) > limit || len(report.Removed) > limit || len(report.Modified) > limit
+		if !report.Comparable {
+			response["fingerprints_comparable"] = false
+		}
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal change report", "error", err)
+		return nil, fmt.Errorf("failed to marshal change report: %w", err)
+	}
+
+	return tools.TextResponse(responseJSON), nil
+}
+
+// Detect compares the site's current pages with the last snapshot and
+// records a new snapshot, as Execute does, returning the report rather than
+// a tool response.
+func (t *Tool) Detect(ctx context.Context, changesRequest *DetectChangesRequest) (*Report, error) {
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := changesRequest.SiteOptions.Apply(ctx, t.sites, &changesRequest.HugoSitePath, &changesRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := changesRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := changesRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = changesRequest.CacheOptions.Apply(ctx, 0)
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(changesRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", changesRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = changesRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	previous, hasPrevious := t.cache.LatestSnapshot(siteURL.String())
+
+	// Prefer the source of the previous snapshot so fingerprints are comparable
+	sources := []string{SourceIndex, SourceSitemap}
+	switch {
+	case changesRequest.Source != SourceAuto:
+		sources = []string{changesRequest.Source}
+	case hasPrevious && previous.Source == SourceSitemap:
+		sources = []string{SourceSitemap, SourceIndex}
+	}
+
+	current, err := t.takeSnapshot(ctx, siteURL, sources)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to snapshot site", "site", siteURL.String(), "error", err)
+		return nil, fmt.Errorf("change detection failed: %w", err)
+	}
+
+	report := &Report{
+		Site:       siteURL.String(),
+		SiteSource: sites.Source(ctx),
+		Source:     current.Source,
+		Baseline:   !hasPrevious,
+		Current:    current.TakenAt,
+		TotalPages: len(current.Pages),
+		Comparable: true,
+	}
+	if hasPrevious {
+		diff := compareSnapshots(previous, current)
+		report.Previous = previous.TakenAt
+		report.Added = diff.added
+		report.Removed = diff.removed
+		report.Modified = diff.modified
+		report.Unchanged = diff.unchanged
+
+		// Modifications can only be detected between snapshots of the same source
+		report.Comparable = previous.Source == current.Source
+	}
+
+	t.cache.AddSnapshot(siteURL.String(), current)
+
+	t.log.InfoContext(ctx, "Change detection completed", "site", siteURL.String(), "source", current.Source, "baseline", !hasPrevious)
+	return report, nil
+}
+
+// takeSnapshot fetches the site's page list from the first available source.
+// Sources are always fetched fresh rather than from the response cache.
+func (t *Tool) takeSnapshot(ctx context.Context, siteURL *url.URL, sources []string) (*cache.Snapshot, error) {
+	var lastErr error
+	for _, source := range sources {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var pages map[string]string
+		var err error
+		switch source {
+		case SourceIndex:
+			pages, err = t.fetchPages(ctx, siteURL, hugoindex.IndexPath(ctx), parseIndexPages)
+		case SourceSitemap:
+			pages, err = t.fetchPages(ctx, siteURL, "/sitemap.xml", parseSitemapPages)
+		}
+		if err != nil {
+			t.log.DebugContext(ctx, "Snapshot source unavailable", "source", source, "error", err)
+			lastErr = err
+			continue
+		}
+
+		return &cache.Snapshot{TakenAt: time.Now(), Source: source, Pages: pages}, nil
+	}
+	return nil, lastErr
+}
+
+// fetchPages fetches path and parses it into page fingerprints
+func (t *Tool) fetchPages(ctx context.Context, siteURL *url.URL, path string, parse func([]byte) (map[string]string, error)) (map[string]string, error) {
+	endpointURL := siteURL.ResolveReference(&url.URL{Path: path})
+	resp, err := t.httpClient.Get(ctx, endpointURL.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s not available: %w", path, &cache.StatusError{StatusCode: resp.StatusCode})
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return parse(body)
+}
+
+// parseIndexPages fingerprints each page of a Hugo JSON index by hashing its
+// JSON representation, so any change to its front matter or content is detected
+func parseIndexPages(data []byte) (map[string]string, error) {
+	if !gjson.ValidBytes(data) {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeParseError, "invalid JSON in index")
+	}
+
+	pages := make(map[string]string)
+	err := hugoindex.New("", data).Pages(context.Background(), func(page gjson.Result) bool {
+		if pageURL := hugoindex.PageURL(page); pageURL != "" {
+			pages[pageURL] = fingerprint([]byte(page.Raw))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeParseError, "index does not contain a list of pages: %w", err)
+	}
+	return pages, nil
+}
+
+// sitemap is the subset of the sitemap protocol used for change detection
+type sitemap struct {
+	URLs []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+}
+
+// parseSitemapPages fingerprints each sitemap URL by its lastmod date
+func parseSitemapPages(data []byte) (map[string]string, error) {
+	var sm sitemap
+	if err := xml.Unmarshal(data, &sm); err != nil {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeParseError, "invalid sitemap: %w", err)
+	}
+
+	pages := make(map[string]string, len(sm.URLs))
+	for _, u := range sm.URLs {
+		if u.Loc != "" {
+			pages[u.Loc] = u.LastMod
+		}
+	}
+	return pages, nil
+}
+
+func fingerprint(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// snapshotDiff lists the pages that differ between two snapshots
+type snapshotDiff struct {
+	added     []string
+	removed   []string
+	modified  []string
+	unchanged int
+}
+
+// compareSnapshots reports pages added, removed and modified since previous.
+// Modifications are only reported when both snapshots came from the same source.
+func compareSnapshots(previous, current *cache.Snapshot) snapshotDiff {
+	diff := snapshotDiff{added: []string{}, removed: []string{}, modified: []string{}}
+	comparable := previous.Source == current.Source
+
+	for pageURL, currentPrint := range current.Pages {
+		previousPrint, existed := previous.Pages[pageURL]
+		switch {
+		case !existed:
+			diff.added = append(diff.added, pageURL)
+		case comparable && previousPrint != currentPrint:
+			diff.modified = append(diff.modified, pageURL)
+		default:
+			diff.unchanged++
+		}
+	}
+	for pageURL := range previous.Pages {
+		if _, exists := current.Pages[pageURL]; !exists {
+			diff.removed = append(diff.removed, pageURL)
+		}
+	}
+
+	sort.Strings(diff.added)
+	sort.Strings(diff.removed)
+	sort.Strings(diff.modified)
+	return diff
+}
+
+func truncate(pages []string, limit int) []string {
+	if len(pages) > limit {
+		return pages[:limit]
+	}
+	return pages
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*DetectChangesRequest](registry, t, "Change monitoring", tools.Annotations{OpenWorldHint: true})
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
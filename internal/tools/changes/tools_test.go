@@ -0,0 +1,156 @@
+package changes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNew(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+	assert.NotNil(t, tool)
+	assert.Equal(t, "hugo_reader_detect_changes", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+	assert.NotNil(t, tool.cache)
+	assert.NotNil(t, tool.httpClient)
+}
+
+func TestDetectChangesRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *DetectChangesRequest
+		wantErr bool
+	}{
+		{
+			name:    "valid request with defaults",
+			req:     &DetectChangesRequest{HugoSitePath: "https://example.com"},
+			wantErr: false,
+		},
+		{
+			name:    "valid sitemap source",
+			req:     &DetectChangesRequest{HugoSitePath: "https://example.com", Source: "sitemap"},
+			wantErr: false,
+		},
+		{
+			name:    "missing hugo_site_path",
+			req:     &DetectChangesRequest{},
+			wantErr: true,
+		},
+		{
+			name:    "invalid source",
+			req:     &DetectChangesRequest{HugoSitePath: "https://example.com", Source: "rss"},
+			wantErr: true,
+		},
+		{
+			name:    "limit too high",
+			req:     &DetectChangesRequest{HugoSitePath: "https://example.com", Limit: 5000},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, tt.req.Source)
+				assert.Greater(t, tt.req.Limit, 0)
+			}
+		})
+	}
+}
+
+func TestParseSitemapPages(t *testing.T) {
+	data := `<?xml version="1.0" encoding="utf-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/a/</loc><lastmod>2024-01-01</lastmod></url>
+  <url><loc>https://example.com/b/</loc></url>
+</urlset>`
+
+	pages, err := parseSitemapPages([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"https://example.com/a/": "2024-01-01",
+		"https://example.com/b/": "",
+	}, pages)
+
+	_, err = parseSitemapPages([]byte("not xml"))
+	assert.Error(t, err)
+}
+
+func TestCompareSnapshots(t *testing.T) {
+	previous := &cache.Snapshot{Source: SourceIndex, Pages: map[string]string{"/a": "1", "/b": "1", "/c": "1"}}
+	current := &cache.Snapshot{Source: SourceIndex, Pages: map[string]string{"/a": "1", "/b": "2", "/d": "1"}}
+
+	diff := compareSnapshots(previous, current)
+	assert.Equal(t, []string{"/d"}, diff.added)
+	assert.Equal(t, []string{"/c"}, diff.removed)
+	assert.Equal(t, []string{"/b"}, diff.modified)
+	assert.Equal(t, 1, diff.unchanged)
+
+	// Fingerprints from different sources are not comparable
+	current.Source = SourceSitemap
+	diff = compareSnapshots(previous, current)
+	assert.Empty(t, diff.modified)
+	assert.Equal(t, 2, diff.unchanged)
+}
+
+func TestTool_Execute_DetectsChanges(t *testing.T) {
+	index := `{"pages": [
+		{"title": "A", "url": "/a/", "content": "one"},
+		{"title": "B", "url": "/b/", "content": "two"}
+	]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(index))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	// The first run records a baseline
+	resp, err := tool.Execute(context.Background(), &DetectChangesRequest{HugoSitePath: server.URL})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.True(t, result.Get("baseline").Bool())
+	assert.Equal(t, int64(2), result.Get("summary.total_pages").Int())
+
+	index = `{"pages": [
+		{"title": "A", "url": "/a/", "content": "one, revised"},
+		{"title": "C", "url": "/c/", "content": "three"}
+	]}`
+
+	resp, err = tool.Execute(context.Background(), &DetectChangesRequest{HugoSitePath: server.URL})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.False(t, result.Get("baseline").Bool())
+	assert.Equal(t, "index", result.Get("source").String())
+	assert.Equal(t, `["/c/"]`, result.Get("added").Raw)
+	assert.Equal(t, `["/b/"]`, result.Get("removed").Raw)
+	assert.Equal(t, `["/a/"]`, result.Get("modified").Raw)
+	assert.Len(t, tool.cache.Snapshots(server.URL), 2)
+}
+
+func TestTool_Execute_NoSource(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	_, err = tool.Execute(context.Background(), &DetectChangesRequest{HugoSitePath: server.URL})
+	assert.Error(t, err)
+}
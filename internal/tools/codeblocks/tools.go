@@ -0,0 +1,277 @@
+package codeblocks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/pagelinks"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+)
+
+// NoLanguage counts, and selects, the blocks that do not name a language
+const NoLanguage = "none"
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool extracts the code blocks of a page on a Hugo site.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// CodeBlocksRequest represents the request parameters for extracting code
+// blocks.
+type CodeBlocksRequest struct {
+	HugoSitePath string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	Path         string `json:"path" jsonschema:"title=Page Path (e.g. /docs/install/)"`
+	Language     string `json:"language,omitempty" jsonschema:"title=Only return blocks in this language (e.g. go; case-insensitive); none for blocks without one"`
+	Limit        int    `json:"limit,omitempty" jsonschema:"title=Result Limit,minimum=1,maximum=500"`
+
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// codeBlock is a code block of a page in the response
+type codeBlock struct {
+	Index    int    `json:"index"`
+	Language string `json:"language,omitempty"`
+	Code     string `json:"code"`
+	Lines    int    `json:"lines"`
+	Heading  string `json:"heading,omitempty"`
+	Anchor   string `json:"anchor,omitempty"`
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_extract_code"),
+		name:        "hugo_reader_extract_code",
+		description: "Extract the code blocks of a page on a Hugo site, such as a documentation page, each with its language and the heading of the section it appears in. Reads the page body from the site index when it holds HTML, otherwise the rendered page; Markdown bodies give their fenced code blocks. Highlighter line numbers are removed, so blocks can be run as they are. Use it to pull examples from docs without reading whole articles.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *CodeBlocksRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+	if r.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if u, err := url.Parse(r.Path); err != nil || u.IsAbs() || u.Host != "" {
+		return fmt.Errorf("invalid path: %s (must be relative to the site)", r.Path)
+	}
+	for _, part := range strings.Split(r.Path, "/") {
+		if part == ".." {
+			return fmt.Errorf("invalid path: %s", r.Path)
+		}
+	}
+	r.Language = strings.TrimSpace(r.Language)
+
+	if r.Limit == 0 {
+		r.Limit = 50
+	} else if r.Limit < 1 || r.Limit > 500 {
+		return fmt.Errorf("limit must be between 1 and 500")
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// Execute extracts the code blocks of a page.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	codeRequest, ok := req.(*CodeBlocksRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := codeRequest.SiteOptions.Apply(ctx, t.sites, &codeRequest.HugoSitePath, &codeRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := codeRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := codeRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = codeRequest.CacheOptions.Apply(ctx, 0)
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(codeRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", codeRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = codeRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	pageURL := pagelinks.PageURL(siteURL, codeRequest.Path)
+
+	// The site index is optional; without one the rendered page is read
+	index, err := hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		t.log.DebugContext(ctx, "Site index unavailable", "site", codeRequest.HugoSitePath, "error", err)
+		index = nil
+	}
+
+	body, err := pagelinks.Fetch(ctx, t.cache, t.httpClient, index, siteURL, pageURL)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to fetch page", "site", codeRequest.HugoSitePath, "path", codeRequest.Path, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.Code(err), "failed to fetch page '%s' of Hugo site %s: %w", codeRequest.Path, codeRequest.HugoSitePath, err)
+	}
+
+	blocks := htmltext.CodeBlocks(body.Content)
+	languages := make(map[string]int)
+	filtered := []codeBlock{}
+	for i, block := range blocks {
+		language := block.Language
+		if language == "" {
+			language = NoLanguage
+		}
+		languages[language]++
+		if codeRequest.Language != "" && !strings.EqualFold(language, codeRequest.Language) {
+			continue
+		}
+		entry := codeBlock{
+			Index:    i + 1,
+			Language: block.Language,
+			Code:     block.Code,
+			Lines:    strings.Count(block.Code, "\n") + 1,
+		}
+		if block.Heading != nil {
+			entry.Heading, entry.Anchor = block.Heading.Text, block.Heading.Anchor
+		}
+		filtered = append(filtered, entry)
+	}
+	total := len(filtered)
+	if len(filtered) > codeRequest.Limit {
+		filtered = filtered[:codeRequest.Limit]
+	}
+
+	response := map[string]interface{}{
+		"success":     true,
+		"path":        codeRequest.Path,
+		"code_blocks": filtered,
+		"metadata": map[string]interface{}{
+			"source":          body.Source,
+			"source_endpoint": body.Endpoint,
+			"cached":          body.Cached,
+			"total_blocks":    len(blocks),
+			"matched":         total,
+			"returned":        len(filtered),
+			"truncated":       total > len(filtered),
+			"languages":       languages,
+			"site_source":     sites.Source(ctx),
+		},
+		"errors": []string{},
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal code blocks", "error", err)
+		return nil, fmt.Errorf("failed to marshal code blocks: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Extracted code blocks", "site", codeRequest.HugoSitePath, "path", codeRequest.Path, "source", body.Source, "total", len(blocks))
+	return tools.TextResponse(responseJSON), nil
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*CodeBlocksRequest](registry, t, "Content retrieval", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
@@ -0,0 +1,108 @@
+package codeblocks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/pagelinks"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNew(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+	assert.Equal(t, "hugo_reader_extract_code", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+}
+
+func TestCodeBlocksRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *CodeBlocksRequest
+		wantErr bool
+	}{
+		{name: "valid", req: &CodeBlocksRequest{HugoSitePath: "https://example.com", Path: "/docs/install/"}},
+		{name: "language", req: &CodeBlocksRequest{HugoSitePath: "https://example.com", Path: "docs", Language: "go"}},
+		{name: "missing path", req: &CodeBlocksRequest{HugoSitePath: "https://example.com"}, wantErr: true},
+		{name: "absolute URL path", req: &CodeBlocksRequest{HugoSitePath: "https://example.com", Path: "https://other.example/"}, wantErr: true},
+		{name: "path escaping the site", req: &CodeBlocksRequest{HugoSitePath: "https://example.com", Path: "/docs/../../etc/"}, wantErr: true},
+		{name: "limit too high", req: &CodeBlocksRequest{HugoSitePath: "https://example.com", Path: "/docs/", Limit: 501}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Greater(t, tt.req.Limit, 0)
+			}
+		})
+	}
+}
+
+func TestTool_Execute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			w.Write([]byte(`[
+				{"title": "Install", "url": "/docs/install/", "content": "<h2 id=\"linux\">Linux</h2><pre><code class=\"language-sh\">make install</code></pre><h2 id=\"go\">Go</h2><pre><code class=\"language-go\">package main\n\nfunc main() {}</code></pre><pre><code>plain</code></pre>"},
+				{"title": "Usage", "url": "/docs/usage/", "content": "Plain text only"}
+			]`))
+		case "/docs/usage/":
+			w.Write([]byte(`<html><body><nav><pre>not content</pre></nav><main><h1>Usage</h1><pre><code class="language-sh">tool run</code></pre></main></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	// HTML in the site index is used directly
+	resp, err := tool.Execute(context.Background(), &CodeBlocksRequest{HugoSitePath: server.URL, Path: "/docs/install/"})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, pagelinks.SourceSiteIndex, result.Get("metadata.source").String())
+	assert.Equal(t, `["make install","package main\n\nfunc main() {}","plain"]`, result.Get("code_blocks.#.code").Raw)
+	assert.Equal(t, "go", result.Get("code_blocks.1.language").String())
+	assert.False(t, result.Get("code_blocks.2.language").Exists())
+	assert.Equal(t, int64(3), result.Get("code_blocks.1.lines").Int())
+	assert.Equal(t, "Go", result.Get("code_blocks.1.heading").String())
+	assert.Equal(t, "go", result.Get("code_blocks.1.anchor").String())
+	assert.Equal(t, int64(1), result.Get("metadata.languages.none").Int())
+
+	// The language filter keeps each block's position on the page
+	resp, err = tool.Execute(context.Background(), &CodeBlocksRequest{HugoSitePath: server.URL, Path: "/docs/install/", Language: "GO"})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, int64(1), result.Get("code_blocks.#").Int())
+	assert.Equal(t, int64(2), result.Get("code_blocks.0.index").Int())
+	assert.Equal(t, int64(3), result.Get("metadata.total_blocks").Int())
+	assert.Equal(t, int64(1), result.Get("metadata.matched").Int())
+
+	// as does selecting the blocks without a language
+	resp, err = tool.Execute(context.Background(), &CodeBlocksRequest{HugoSitePath: server.URL, Path: "/docs/install/", Language: NoLanguage})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["plain"]`, result.Get("code_blocks.#.code").Raw)
+	assert.Equal(t, int64(3), result.Get("code_blocks.0.index").Int())
+
+	// Otherwise the rendered page's main content is read
+	resp, err = tool.Execute(context.Background(), &CodeBlocksRequest{HugoSitePath: server.URL, Path: "docs/usage", Limit: 1})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, pagelinks.SourcePageHTML, result.Get("metadata.source").String())
+	assert.Equal(t, `["tool run"]`, result.Get("code_blocks.#.code").Raw)
+	assert.Equal(t, "Usage", result.Get("code_blocks.0.heading").String())
+
+	_, err = tool.Execute(context.Background(), &CodeBlocksRequest{HugoSitePath: server.URL, Path: "/missing/"})
+	assert.Equal(t, toolerrors.ErrCodeNotFound, toolerrors.Code(err))
+	assert.Contains(t, err.Error(), "404")
+}
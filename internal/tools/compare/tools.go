@@ -0,0 +1,591 @@
+package compare
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/generic"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/tidwall/gjson"
+)
+
+// Page sources
+const (
+	SourceAuto    = "auto"
+	SourceIndex   = "index"
+	SourceSitemap = "sitemap"
+)
+
+// MaxFetchedPages bounds the pages read from each site's rendered HTML to
+// compare their content, when the sites are compared by their sitemaps
+const MaxFetchedPages = generic.MaxPages
+
+// hashConcurrency bounds how many pages are hashed at once
+const hashConcurrency = 8
+
+// contentFields are the index fields holding a page's content, in order of
+// preference
+var contentFields = []string{"content", "body", "plain", "summary"}
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool compares the pages of two sites, such as the staging and production
+// builds of one site.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// CompareSitesRequest represents the request parameters for a site comparison.
+type CompareSitesRequest struct {
+	HugoSitePath   string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path of the base site (e.g. production)"`
+	CompareTo      string `json:"compare_to" jsonschema:"title=URL or alias of the site compared with the base (e.g. staging)"`
+	Source         string `json:"source,omitempty" jsonschema:"enum=auto,enum=index,enum=sitemap,title=Page Source"`
+	CompareContent bool   `json:"compare_content,omitempty" jsonschema:"title=Hash the content of the pages both sites have to find those that differ"`
+	Limit          int    `json:"limit,omitempty" jsonschema:"title=Maximum pages listed per difference,minimum=1,maximum=1000"`
+
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_compare_sites"),
+		name:        "hugo_reader_compare_sites",
+		description: "Compare the pages of two sites, such as the staging and production builds of a Hugo site. Diffs the page inventories read from both sites' index.json, or sitemaps when either lacks one, and reports pages missing from the compared site and extra pages it has. With compare_content, also hashes the content of the pages both sites have and reports those that differ. Use it to check a deployment before or after promoting it.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *CompareSitesRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+	if r.CompareTo == "" {
+		return fmt.Errorf("compare_to is required")
+	}
+
+	switch r.Source {
+	case "":
+		r.Source = SourceAuto
+	case SourceAuto, SourceIndex, SourceSitemap:
+	default:
+		return fmt.Errorf("invalid source: %s (must be: auto, index, or sitemap)", r.Source)
+	}
+
+	if r.Limit == 0 {
+		r.Limit = 100
+	} else if r.Limit < 1 || r.Limit > 1000 {
+		return fmt.Errorf("limit must be between 1 and 1000")
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// site is one of the sites compared, with the context its requests are
+// made with
+type site struct {
+	ctx   context.Context
+	url   *url.URL
+	alias string
+}
+
+// page is a page of a site's inventory
+type page struct {
+	url  string
+	item gjson.Result
+}
+
+// inventory is the pages of a site, keyed by their path below the site's URL
+type inventory struct {
+	source   string
+	endpoint string
+	pages    map[string]page
+}
+
+// Execute compares the pages of the two sites.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	compareRequest, ok := req.(*CompareSitesRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	baseCtx, err := compareRequest.SiteOptions.Apply(ctx, t.sites, &compareRequest.HugoSitePath, &compareRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := compareRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	// The compared site is resolved as the base is, with its own credentials
+	otherPath, otherAuth := "", httpclient.AuthOptions{}
+	otherOptions := sites.SiteOptions{}
+	if sites.IsAlias(compareRequest.CompareTo) {
+		otherOptions.Site = compareRequest.CompareTo
+	} else {
+		otherPath = compareRequest.CompareTo
+	}
+	otherCtx, err := otherOptions.Apply(ctx, t.sites, &otherPath, &otherAuth)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, fmt.Errorf("compare_to: %w", err))
+	}
+	if err := sites.ValidateSitePath(&otherPath); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, fmt.Errorf("compare_to: %w", err))
+	}
+
+	base, err := t.site(baseCtx, compareRequest, compareRequest.HugoSitePath, compareRequest.AuthOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer base.cancel()
+	other, err := t.site(otherCtx, compareRequest, otherPath, otherAuth)
+	if err != nil {
+		return nil, err
+	}
+	defer other.cancel()
+	base.alias, other.alias = compareRequest.Site, otherOptions.Site
+	if base.url.String() == other.url.String() {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "compare_to must be another site than %s", base.url)
+	}
+
+	baseInv, otherInv, err := t.inventories(base.site, other.site, compareRequest.Source)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Site inventory unavailable", "base", base.url.String(), "compare_to", other.url.String(), "error", err)
+		return nil, toolerrors.Errorf(toolerrors.Code(err), "site comparison failed: %w", err)
+	}
+
+	diff := compareInventories(baseInv, otherInv)
+	limit := compareRequest.Limit
+	summary := map[string]int{
+		"base_pages":       len(baseInv.pages),
+		"compare_to_pages": len(otherInv.pages),
+		"common":           len(diff.common),
+		"missing":          len(diff.missing),
+		"extra":            len(diff.extra),
+	}
+	response := map[string]interface{}{
+		"success":    true,
+		"base":       siteInfo(base.site, baseInv),
+		"compare_to": siteInfo(other.site, otherInv),
+		"source":     baseInv.source,
+		"missing":    truncate(diff.missing, limit),
+		"extra":      truncate(diff.extra, limit),
+		"truncated":  len(diff.missing) > limit || len(diff.extra) > limit,
+		"summary":    summary,
+		"errors":     []toolerrors.ErrorDetail{},
+	}
+
+	if compareRequest.CompareContent {
+		content := t.compareContent(base.site, other.site, baseInv, otherInv, diff.common)
+		summary["differing"] = len(content.differing)
+		summary["identical"] = content.identical
+		response["differing"] = truncate(content.differing, limit)
+		response["truncated"] = response["truncated"].(bool) || len(content.differing) > limit
+		response["content"] = map[string]int{
+			"pages_hashed":  content.hashed,
+			"hash_failures": content.failed,
+			"pages_skipped": content.skipped,
+		}
+		if len(content.errors) > limit {
+			content.errors = content.errors[:limit]
+		}
+		response["errors"] = content.errors
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal site comparison", "error", err)
+		return nil, fmt.Errorf("failed to marshal site comparison: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Site comparison completed", "base", base.url.String(), "compare_to", other.url.String(), "missing", len(diff.missing), "extra", len(diff.extra))
+	return tools.TextResponse(responseJSON), nil
+}
+
+// preparedSite is a site with the function releasing its context
+type preparedSite struct {
+	site
+	cancel context.CancelFunc
+}
+
+// site parses the URL of a compared site and returns the context its
+// requests are made with, under the request's retry and cache options and
+// with auth sent only to it
+func (t *Tool) site(ctx context.Context, req *CompareSitesRequest, rawURL string, auth httpclient.AuthOptions) (*preparedSite, error) {
+	siteURL, err := url.Parse(rawURL)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", rawURL, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	ctx, cancel := req.RetryOptions.Apply(ctx)
+	ctx = req.CacheOptions.Apply(ctx, 0)
+	ctx = auth.Apply(ctx, siteURL.Host)
+	return &preparedSite{site: site{ctx: ctx, url: siteURL}, cancel: cancel}, nil
+}
+
+// inventories reads the pages of both sites from the same source, so their
+// inventories are comparable. Auto prefers index.json, falling back to the
+// sitemaps when either site lacks one.
+func (t *Tool) inventories(base, other site, source string) (*inventory, *inventory, error) {
+	sources := []string{source}
+	if source == SourceAuto {
+		sources = []string{SourceIndex, SourceSitemap}
+	}
+
+	var err error
+	for _, source := range sources {
+		var invs [2]*inventory
+		var errs [2]error
+		var wg sync.WaitGroup
+		for i, s := range []site{base, other} {
+			wg.Add(1)
+			go func(i int, s site) {
+				defer wg.Done()
+				invs[i], errs[i] = t.inventory(s, source)
+			}(i, s)
+		}
+		wg.Wait()
+
+		if errs[0] == nil && errs[1] == nil {
+			return invs[0], invs[1], nil
+		}
+		for i, s := range []site{base, other} {
+			if errs[i] != nil {
+				err = fmt.Errorf("%s: %w", s.url, errs[i])
+				t.log.DebugContext(s.ctx, "Inventory source unavailable", "site", s.url.String(), "source", source, "error", errs[i])
+				break
+			}
+		}
+	}
+	return nil, nil, err
+}
+
+// inventory reads the pages of s from source
+func (t *Tool) inventory(s site, source string) (*inventory, error) {
+	inv := &inventory{source: source, pages: make(map[string]page)}
+	switch source {
+	case SourceIndex:
+		index, err := hugoindex.Load(s.ctx, t.cache, t.httpClient, s.url)
+		if err != nil {
+			return nil, err
+		}
+		inv.endpoint = index.URL()
+		err = index.Pages(s.ctx, func(item gjson.Result) bool {
+			if pageURL := hugoindex.PageURL(item); pageURL != "" {
+				if path, ok := sitePath(s.url, pageURL); ok {
+					inv.pages[path] = page{url: pageURL, item: item}
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read site index: %w", err)
+		}
+	case SourceSitemap:
+		entries, endpoint, err := generic.Sitemap(s.ctx, t.cache, t.httpClient, s.url)
+		if err != nil {
+			return nil, err
+		}
+		inv.endpoint = endpoint
+		for _, entry := range entries {
+			if path, ok := sitePath(s.url, entry.Loc); ok {
+				inv.pages[path] = page{url: entry.Loc}
+			}
+		}
+	}
+	return inv, nil
+}
+
+// sitePath returns the path of a page below the site's URL, so the pages of
+// sites published at different hosts or paths compare equal. Aliases of a
+// page, such as /about/index.html, share the path of the page (/about/).
+// Pages on other hosts or outside the site's path have none.
+func sitePath(siteURL *url.URL, rawURL string) (string, bool) {
+	canonical, err := url.Parse(hugoindex.CanonicalURL(siteURL, rawURL))
+	if err != nil || !strings.EqualFold(canonical.Host, siteURL.Host) {
+		return "", false
+	}
+
+	basePath := siteURL.Path
+	if !strings.HasSuffix(basePath, "/") {
+		basePath += "/"
+	}
+	if !strings.HasPrefix(canonical.Path, basePath) {
+		return "", false
+	}
+	path := "/" + strings.TrimPrefix(canonical.Path, basePath)
+	if canonical.RawQuery != "" {
+		path += "?" + canonical.RawQuery
+	}
+	return path, true
+}
+
+// inventoryDiff is how the pages of two inventories differ
+type inventoryDiff struct {
+	missing []string
+	extra   []string
+	common  []string
+}
+
+// compareInventories lists the pages of base missing from other, the extra
+// pages other has, and the pages they have in common, each in path order
+func compareInventories(base, other *inventory) inventoryDiff {
+	diff := inventoryDiff{missing: []string{}, extra: []string{}, common: []string{}}
+	for path := range base.pages {
+		if _, ok := other.pages[path]; ok {
+			diff.common = append(diff.common, path)
+		} else {
+			diff.missing = append(diff.missing, path)
+		}
+	}
+	for path := range other.pages {
+		if _, ok := base.pages[path]; !ok {
+			diff.extra = append(diff.extra, path)
+		}
+	}
+
+	sort.Strings(diff.missing)
+	sort.Strings(diff.extra)
+	sort.Strings(diff.common)
+	return diff
+}
+
+// contentDiff is how the content of the pages two sites have in common
+// differs
+type contentDiff struct {
+	differing []string
+	identical int
+	hashed    int
+	failed    int
+	skipped   int
+
+	// errors are why the pages counted as failures could not be read
+	errors []toolerrors.ErrorDetail
+}
+
+// compareContent hashes the content of each common page on both sites, a
+// bounded number at a time, and lists the pages whose hashes differ. Pages
+// of index inventories are hashed from the index; pages of sitemap
+// inventories are read from their rendered HTML, up to MaxFetchedPages.
+// Pages that could not be read are counted as failures, with an error for
+// each site they failed on, rather than reported as differing.
+func (t *Tool) compareContent(base, other site, baseInv, otherInv *inventory, common []string) contentDiff {
+	diff := contentDiff{differing: []string{}, errors: []toolerrors.ErrorDetail{}}
+	if baseInv.source == SourceSitemap && len(common) > MaxFetchedPages {
+		diff.skipped = len(common) - MaxFetchedPages
+		common = common[:MaxFetchedPages]
+	}
+
+	type hashes struct {
+		base, other       string
+		baseErr, otherErr error
+	}
+	results := make([]hashes, len(common))
+	sem := make(chan struct{}, hashConcurrency)
+	var wg sync.WaitGroup
+	for i, path := range common {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			baseHash, baseErr := t.hash(base, baseInv.pages[path])
+			otherHash, otherErr := t.hash(other, otherInv.pages[path])
+			results[i] = hashes{base: baseHash, other: otherHash, baseErr: baseErr, otherErr: otherErr}
+			if baseErr != nil || otherErr != nil {
+				t.log.DebugContext(base.ctx, "Failed to hash page", "path", path, "base_error", baseErr, "compare_to_error", otherErr)
+			}
+		}(i, path)
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		switch {
+		case result.baseErr != nil || result.otherErr != nil:
+			diff.failed++
+			for _, failure := range []struct {
+				site site
+				err  error
+			}{{base, result.baseErr}, {other, result.otherErr}} {
+				if failure.err != nil {
+					diff.errors = append(diff.errors, toolerrors.FromError(failure.err, map[string]interface{}{
+						"path": common[i],
+						"site": failure.site.url.String(),
+					}))
+				}
+			}
+		case result.base != result.other:
+			diff.hashed++
+			diff.differing = append(diff.differing, common[i])
+		default:
+			diff.hashed++
+			diff.identical++
+		}
+	}
+	return diff
+}
+
+// hash fingerprints the title and text of a page, with the site's own URL
+// removed so absolute links to the site don't make every page differ
+func (t *Tool) hash(s site, p page) (string, error) {
+	item := p.item
+	if !item.Exists() {
+		pageURL, err := url.Parse(p.url)
+		if err != nil {
+			return "", err
+		}
+		fields, err := generic.Page(s.ctx, t.cache, t.httpClient, s.url, pageURL)
+		if err != nil {
+			return "", err
+		}
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return "", err
+		}
+		item = gjson.ParseBytes(data)
+	}
+
+	text := ""
+	for _, field := range contentFields {
+		if value := item.Get(field).String(); value != "" {
+			text = value
+			break
+		}
+	}
+	if htmltext.LooksLikeHTML(text) {
+		text = htmltext.Text(text)
+	}
+	normalized := strings.Join(strings.Fields(item.Get("title").String()+"\n"+text), " ")
+	normalized = strings.ReplaceAll(normalized, strings.TrimSuffix(s.url.String(), "/"), "")
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// siteInfo describes a compared site in the response
+func siteInfo(s site, inv *inventory) map[string]interface{} {
+	info := map[string]interface{}{
+		"site":            s.url.String(),
+		"source_endpoint": inv.endpoint,
+		"pages":           len(inv.pages),
+		"site_source":     sites.Source(s.ctx),
+	}
+	if s.alias != "" {
+		info["alias"] = s.alias
+	}
+	return info
+}
+
+func truncate(pages []string, limit int) []string {
+	if len(pages) > limit {
+		return pages[:limit]
+	}
+	return pages
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*CompareSitesRequest](registry, t, "Content audits", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
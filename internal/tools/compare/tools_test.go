@@ -0,0 +1,190 @@
+package compare
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// indexServer serves an index.json built by index from the server's URL
+func indexServer(index func(base string) string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(index(server.URL)))
+	}))
+	return server
+}
+
+func TestNew(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+	assert.Equal(t, "hugo_reader_compare_sites", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+}
+
+func TestCompareSitesRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *CompareSitesRequest
+		wantErr bool
+	}{
+		{name: "valid", req: &CompareSitesRequest{HugoSitePath: "https://example.com", CompareTo: "https://staging.example.com"}},
+		{name: "missing compare_to", req: &CompareSitesRequest{HugoSitePath: "https://example.com"}, wantErr: true},
+		{name: "invalid source", req: &CompareSitesRequest{HugoSitePath: "https://example.com", CompareTo: "staging", Source: "rss"}, wantErr: true},
+		{name: "limit too high", req: &CompareSitesRequest{HugoSitePath: "https://example.com", CompareTo: "staging", Limit: 5000}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSitePath(t *testing.T) {
+	siteURL, _ := url.Parse("https://example.com/blog")
+	for raw, want := range map[string]string{
+		"/blog/posts/a/": "/posts/a/",
+		"https://example.com/blog/about/index.html": "/about/",
+	} {
+		path, ok := sitePath(siteURL, raw)
+		assert.True(t, ok, raw)
+		assert.Equal(t, want, path, raw)
+	}
+
+	for _, raw := range []string{"https://other.example.com/blog/a/", "/docs/a/"} {
+		_, ok := sitePath(siteURL, raw)
+		assert.False(t, ok, raw)
+	}
+}
+
+func TestTool_Execute_Index(t *testing.T) {
+	production := indexServer(func(base string) string {
+		return `[
+			{"title": "A", "permalink": "` + base + `/a/", "content": "<p>See <a href=\"` + base + `/b/\">B</a></p>"},
+			{"title": "B", "permalink": "` + base + `/b/", "content": "Bee"},
+			{"title": "C", "permalink": "` + base + `/c/", "content": "Old text"}
+		]`
+	})
+	defer production.Close()
+	staging := indexServer(func(base string) string {
+		return `[
+			{"title": "A", "permalink": "` + base + `/a/index.html", "content": "<p>See  <a href=\"` + base + `/b/\">B</a></p>"},
+			{"title": "C", "permalink": "` + base + `/c/", "content": "New text"},
+			{"title": "D", "permalink": "` + base + `/d/", "content": "Dee"}
+		]`
+	})
+	defer staging.Close()
+
+	registry := sites.New()
+	require.NoError(t, registry.Register(sites.Site{Alias: "staging", URL: staging.URL}))
+	tool, err := New(WithSites(registry))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &CompareSitesRequest{HugoSitePath: production.URL, CompareTo: "staging", CompareContent: true})
+	require.NoError(t, err)
+
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, SourceIndex, result.Get("source").String())
+	assert.Equal(t, `["/b/"]`, result.Get("missing").Raw)
+	assert.Equal(t, `["/d/"]`, result.Get("extra").Raw)
+	assert.Equal(t, `["/c/"]`, result.Get("differing").Raw, "links to each site's own URL are ignored")
+	assert.Equal(t, "staging", result.Get("compare_to.alias").String())
+	assert.Equal(t, staging.URL+"/index.json", result.Get("compare_to.source_endpoint").String())
+	assert.Equal(t, int64(2), result.Get("summary.common").Int())
+	assert.Equal(t, int64(1), result.Get("summary.identical").Int())
+	assert.Equal(t, int64(2), result.Get("content.pages_hashed").Int())
+
+	// Without compare_content only the inventories are compared
+	resp, err = tool.Execute(context.Background(), &CompareSitesRequest{HugoSitePath: production.URL, CompareTo: staging.URL})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.False(t, result.Get("differing").Exists())
+	assert.False(t, result.Get("compare_to.alias").Exists())
+}
+
+func TestTool_Execute_Sitemap(t *testing.T) {
+	newSite := func(pages map[string]string) *httptest.Server {
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/sitemap.xml" {
+				w.Write([]byte(`<?xml version="1.0"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`))
+				for path := range pages {
+					fmt.Fprintf(w, "<url><loc>%s%s</loc></url>", server.URL, path)
+				}
+				w.Write([]byte(`</urlset>`))
+				return
+			}
+			// Pages listed without a body are missing
+			body, ok := pages[r.URL.Path]
+			if !ok || body == "" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprintf(w, "<html><head><title>Page</title></head><body><main><p>%s</p></main></body></html>", body)
+		}))
+		return server
+	}
+	production := newSite(map[string]string{"/a/": "Same", "/b/": "Old", "/e/": ""})
+	defer production.Close()
+	// Staging has no index.json, so both sites are compared by sitemap
+	staging := newSite(map[string]string{"/a/": "Same", "/b/": "New", "/c/": "Extra", "/e/": "Broken"})
+	defer staging.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &CompareSitesRequest{HugoSitePath: production.URL, CompareTo: staging.URL, CompareContent: true})
+	require.NoError(t, err)
+
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, SourceSitemap, result.Get("source").String())
+	assert.Equal(t, `[]`, result.Get("missing").Raw)
+	assert.Equal(t, `["/c/"]`, result.Get("extra").Raw)
+	assert.Equal(t, `["/b/"]`, result.Get("differing").Raw)
+
+	// Pages that could not be read are failures, with an error for the site
+	// they failed on
+	assert.Equal(t, int64(1), result.Get("content.hash_failures").Int())
+	assert.Equal(t, int64(1), result.Get("errors.#").Int())
+	assert.Equal(t, toolerrors.ErrCodeNotFound, result.Get("errors.0.code").String())
+	assert.Equal(t, "/e/", result.Get("errors.0.context.path").String())
+	assert.Equal(t, production.URL, result.Get("errors.0.context.site").String())
+}
+
+func TestTool_Execute_Errors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	_, err = tool.Execute(context.Background(), &CompareSitesRequest{HugoSitePath: server.URL, CompareTo: server.URL})
+	assert.Equal(t, toolerrors.ErrCodeInvalidRequest, toolerrors.Code(err))
+
+	_, err = tool.Execute(context.Background(), &CompareSitesRequest{HugoSitePath: server.URL, CompareTo: "unknown"})
+	assert.Equal(t, toolerrors.ErrCodeInvalidRequest, toolerrors.Code(err))
+
+	other := httptest.NewServer(http.NotFoundHandler())
+	defer other.Close()
+	_, err = tool.Execute(context.Background(), &CompareSitesRequest{HugoSitePath: server.URL, CompareTo: other.URL})
+	assert.ErrorContains(t, err, "site comparison failed")
+}
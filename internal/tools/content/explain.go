@@ -0,0 +1,62 @@
+package content
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+)
+
+// Plan methods: a page is read from its own JSON output when the site
+// publishes one, else found in the site index, else read from its HTML
+const (
+	MethodPageJSON     = "page_json"
+	MethodSiteIndex    = "site_index"
+	MethodRenderedHTML = "rendered_html"
+)
+
+// explain returns the plan of a content request: for each path within the
+// limit, the endpoints that would be tried in order and the cache keys they
+// would be read from, without fetching any of them
+func (t *Tool) explain(ctx context.Context, siteURL *url.URL, req *ContentRequest) (*mcp_golang.ToolResponse, error) {
+	site := siteURL.String()
+	plan := tools.NewPlan(ctx, t.cache, site)
+
+	paths := req.Paths
+	if len(paths) > req.Limit {
+		paths = paths[:req.Limit]
+	}
+	indexPath := hugoindex.IndexPath(ctx)
+	indexURL := siteURL.ResolveReference(&url.URL{Path: indexPath}).String()
+	for _, path := range paths {
+		if !req.Generic {
+			for _, endpoint := range contentEndpoints(path) {
+				contentURL := siteURL.ResolveReference(&url.URL{Path: endpoint.path}).String()
+				plan.Add(MethodPageJSON, endpoint.path, contentURL, t.contentKey(siteURL, endpoint, path, req.Include)).Path = path
+			}
+			plan.Add(MethodSiteIndex, indexPath, indexURL, t.cache.BuildKey(site, indexPath, nil)).Path = path
+		}
+		pagePath := "/" + strings.TrimPrefix(path, "/")
+		pageURL := siteURL.ResolveReference(&url.URL{Path: pagePath})
+		plan.Add(MethodRenderedHTML, pagePath, pageURL.String(), t.cache.BuildKey(site, pageURL.RequestURI(), nil)).Path = path
+	}
+
+	plan.Filter("include_drafts", req.IncludeDrafts)
+	plan.Filter("include_future", req.IncludeFuture)
+
+	return tools.ExplainResponse(plan, map[string]interface{}{
+		"paths":        req.Paths,
+		"include":      req.Include,
+		"format":       req.Format,
+		"max_length":   req.MaxLength,
+		"summary_only": req.SummaryOnly,
+		"generic":      req.Generic,
+		"limit":        req.Limit,
+		"site":         site,
+		"site_source":  sites.Source(ctx),
+	})
+}
@@ -0,0 +1,68 @@
+package content
+
+import (
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/tidwall/gjson"
+)
+
+// Table of contents sources
+const (
+	TOCSourceHeadings = "headings"
+	TOCSourceTOCField = "toc_field"
+)
+
+// tocFields are the page fields index templates emit Hugo's
+// .TableOfContents in
+var tocFields = []string{"tableofcontents", "tableOfContents", "TableOfContents", "toc"}
+
+// tocEntry is a heading of a page's outline and the headings below it
+type tocEntry struct {
+	Level    int         `json:"level"`
+	Text     string      `json:"text"`
+	Anchor   string      `json:"anchor,omitempty"`
+	Children []*tocEntry `json:"children,omitempty"`
+}
+
+// pageTOC returns the outline of a page and where it was read from: the
+// h1 to h4 headings of its body, else the table of contents its index
+// entry holds. Bodies that are Markdown give their ATX headings.
+func pageTOC(page gjson.Result) ([]*tocEntry, string) {
+	body := ""
+	for _, field := range bodyFields {
+		if value := page.Get(field).String(); htmltext.LooksLikeHTML(value) {
+			body = value
+			break
+		} else if body == "" {
+			body = value
+		}
+	}
+	if headings := htmltext.Headings(body); len(headings) > 0 {
+		return outline(headings), TOCSourceHeadings
+	}
+
+	for _, field := range tocFields {
+		if value := page.Get(field).String(); htmltext.LooksLikeHTML(value) {
+			if headings := htmltext.TableOfContents(value); len(headings) > 0 {
+				return outline(headings), TOCSourceTOCField
+			}
+		}
+	}
+	return []*tocEntry{}, ""
+}
+
+// outline nests headings below the nearest preceding heading of a higher
+// level
+func outline(headings []htmltext.Heading) []*tocEntry {
+	root := &tocEntry{Children: []*tocEntry{}}
+	stack := []*tocEntry{root}
+	for _, heading := range headings {
+		entry := &tocEntry{Level: heading.Level, Text: heading.Text, Anchor: heading.Anchor}
+		for len(stack) > 1 && stack[len(stack)-1].Level >= heading.Level {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		parent.Children = append(parent.Children, entry)
+		stack = append(stack, entry)
+	}
+	return root.Children
+}
@@ -0,0 +1,81 @@
+package content
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestOutline(t *testing.T) {
+	toc := outline([]htmltext.Heading{
+		{Level: 2, Text: "Install", Anchor: "install"},
+		{Level: 3, Text: "Linux", Anchor: "linux"},
+		{Level: 4, Text: "Debian", Anchor: "debian"},
+		{Level: 3, Text: "macOS", Anchor: "macos"},
+		{Level: 2, Text: "Usage", Anchor: "usage"},
+		{Level: 1, Text: "Appendix"},
+	})
+
+	data, err := json.Marshal(toc)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"level": 2, "text": "Install", "anchor": "install", "children": [
+			{"level": 3, "text": "Linux", "anchor": "linux", "children": [
+				{"level": 4, "text": "Debian", "anchor": "debian"}
+			]},
+			{"level": 3, "text": "macOS", "anchor": "macos"}
+		]},
+		{"level": 2, "text": "Usage", "anchor": "usage"},
+		{"level": 1, "text": "Appendix"}
+	]`, string(data))
+}
+
+func TestPageTOC(t *testing.T) {
+	tests := []struct {
+		name       string
+		page       string
+		wantSource string
+		wantFirst  string
+		wantCount  int
+	}{
+		{
+			name:       "headings of the HTML body",
+			page:       `{"content": "<h2 id=\"a\">A</h2><p>x</p><h3 id=\"b\">B</h3>", "tableofcontents": "<nav><ul><li><a href=\"#z\">Z</a></li></ul></nav>"}`,
+			wantSource: TOCSourceHeadings,
+			wantFirst:  "A",
+			wantCount:  1,
+		},
+		{
+			name:       "table of contents field",
+			page:       `{"content": "Plain text without headings", "tableofcontents": "<nav id=\"TableOfContents\"><ul><li><a href=\"#z\">Z</a></li><li><a href=\"#y\">Y</a></li></ul></nav>"}`,
+			wantSource: TOCSourceTOCField,
+			wantFirst:  "Z",
+			wantCount:  2,
+		},
+		{
+			name:       "Markdown body",
+			page:       `{"body": "## Setup\n\nSteps\n\n## Usage"}`,
+			wantSource: TOCSourceHeadings,
+			wantFirst:  "Setup",
+			wantCount:  2,
+		},
+		{
+			name: "no headings",
+			page: `{"content": "<p>Short</p>"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toc, source := pageTOC(gjson.Parse(tt.page))
+			assert.Equal(t, tt.wantSource, source)
+			require.Len(t, toc, tt.wantCount)
+			if tt.wantCount > 0 {
+				assert.Equal(t, tt.wantFirst, toc[0].Text)
+			}
+		})
+	}
+}
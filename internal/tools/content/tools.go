@@ -1,17 +1,25 @@
 package content
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
 	mcp_golang "github.com/metoro-io/mcp-golang"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/enrich"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/generic"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
 	"github.com/tidwall/gjson"
 )
 
@@ -23,33 +31,86 @@ type Tool struct {
 	log        *slog.Logger
 	name       string
 	description string
-	httpClient *http.Client
+	httpClient *httpclient.Client
 	cache      *cache.Cache
+	ttl        time.Duration
+	sites      *sites.Registry
 }
 
 // ContentRequest represents the request parameters for the content tool.
 type ContentRequest struct {
-	HugoSitePath string   `json:"hugo_site_path" jsonschema:"title=Hugo Site Path"`
+	HugoSitePath string   `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
 	Paths        []string `json:"paths" jsonschema:"title=Content Paths,minItems=1"`
-	Include      []string `json:"include" jsonschema:"title=Include Fields,enum=metadata,enum=body,enum=both"`
+	Include      []string `json:"include" jsonschema:"title=Include Fields,enum=metadata,enum=body,enum=both,enum=toc"`
 	Limit        int      `json:"limit,omitempty" jsonschema:"title=Limit,minimum=1,maximum=100"`
+	Format       string   `json:"format,omitempty" jsonschema:"title=Body Format,enum=raw,enum=html,enum=markdown,enum=text"`
+	MaxLength    int      `json:"max_length,omitempty" jsonschema:"title=Maximum body length in characters; longer bodies are split into chunks,minimum=100,maximum=1000000"`
+	Chunk        int      `json:"chunk,omitempty" jsonschema:"title=1-based chunk to return when the body exceeds max_length (default: 1),minimum=1"`
+	SummaryOnly  bool     `json:"summary_only,omitempty" jsonschema:"title=Return a short summary with word count and reading time instead of the body"`
+	Paragraphs   int      `json:"paragraphs,omitempty" jsonschema:"title=Paragraphs to summarize when the page has no summary (default: 2),minimum=1,maximum=20"`
+	Enrich       bool     `json:"enrich,omitempty" jsonschema:"title=Add word_count, reading_time_minutes and language, computing them when the page lacks them"`
+
+	tools.ExplainOptions
+	generic.Options
+	hugoindex.PublishOptions
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
 }
 
+// Body formats
+const (
+	FormatRaw      = "raw"
+	FormatHTML     = "html"
+	FormatMarkdown = "markdown"
+	FormatText     = "text"
+)
+
+// Summary sources
+const (
+	SummaryMoreSplit  = "more_split"
+	SummaryField      = "summary"
+	SummaryParagraphs = "paragraphs"
+)
+
+// bodyFields are the page fields that may hold the body, in order of
+// preference
+var bodyFields = []string{"content", "html", "body"}
+
+// pageFields are the lowercased page variables that index templates emit
+// alongside front matter; every other top-level field is a custom param
+var pageFields = map[string]bool{
+	"title": true, "linktitle": true, "date": true, "lastmod": true, "publishdate": true, "expirydate": true,
+	"slug": true, "url": true, "permalink": true, "relpermalink": true, "path": true, "kind": true, "type": true,
+	"section": true, "layout": true, "lang": true, "language": true, "weight": true, "draft": true,
+	"summary": true, "description": true, "keywords": true, "tags": true, "categories": true, "series": true,
+	"author": true, "authors": true, "aliases": true, "wordcount": true, "readingtime": true, "toc": true,
+	"tableofcontents": true, "content": true, "plain": true, "body": true, "html": true, "params": true,
+}
+
+// moreDivider matches Hugo's manual summary divider
+var moreDivider = regexp.MustCompile(`(?i)<!--\s*more\s*-->`)
+
 // EndpointConfig represents an endpoint with its validation function
 type EndpointConfig struct {
 	path      string
 	validator func([]byte) bool
 }
 
+// DefaultCacheTTL is how long fetched pages are served from the cache
+const DefaultCacheTTL = 5 * time.Minute
+
 // New creates a new Tool.
 func New(opts ...ToolOption) (*Tool, error) {
 	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_get_content"),
 		name:        "hugo_reader_get_content",
 		description: "Get content from Hugo sites by path. Supports bulk retrieval and flexible response options (metadata, body, or both). Tries multiple endpoint patterns automatically. Example paths: '/posts/my-post/', '/recipes/cookies/', '/about/'. Use with or without trailing slashes.",
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		cache: cache.New(cache.WithTTL(5 * time.Minute)),
+		httpClient: httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache: cache.New(cache.WithTTL(DefaultCacheTTL)),
+		ttl:   DefaultCacheTTL,
+		sites: sites.New(),
 	}
 	for _, opt := range opts {
 		if err := opt(tool); err != nil {
@@ -64,9 +125,6 @@ func New(opts ...ToolOption) (*Tool, error) {
 func WithLogger(logger *slog.Logger) ToolOption {
 	return func(t *Tool) error {
 		t.log = logger.With("tool", t.name)
-		if t.cache != nil {
-			t.cache = cache.New(cache.WithLogger(logger), cache.WithTTL(5*time.Minute))
-		}
 		return nil
 	}
 }
@@ -79,10 +137,35 @@ func WithCache(c *cache.Cache) ToolOption {
 	}
 }
 
+// WithTTL sets how long the Tool serves cached responses. Zero uses the
+// cache's default TTL.
+func WithTTL(ttl time.Duration) ToolOption {
+	return func(t *Tool) error {
+		t.ttl = ttl
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
 // Validate implements tools.Request
 func (r *ContentRequest) Validate() error {
-	if r.HugoSitePath == "" {
-		return fmt.Errorf("hugo_site_path is required")
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
 	}
 	if len(r.Paths) == 0 {
 		return fmt.Errorf("at least one path is required")
@@ -94,10 +177,10 @@ func (r *ContentRequest) Validate() error {
 	}
 	
 	// Validate include values
-	validIncludes := map[string]bool{"metadata": true, "body": true, "both": true}
+	validIncludes := map[string]bool{"metadata": true, "body": true, "both": true, "toc": true}
 	for _, include := range r.Include {
 		if !validIncludes[include] {
-			return fmt.Errorf("invalid include value: %s (must be: metadata, body, or both)", include)
+			return fmt.Errorf("invalid include value: %s (must be: metadata, body, both, or toc)", include)
 		}
 	}
 	
@@ -107,31 +190,73 @@ func (r *ContentRequest) Validate() error {
 	} else if r.Limit < 1 || r.Limit > 100 {
 		return fmt.Errorf("limit must be between 1 and 100")
 	}
+
+	switch r.Format {
+	case "":
+		r.Format = FormatRaw
+	case FormatRaw, FormatHTML, FormatMarkdown, FormatText:
+	default:
+		return fmt.Errorf("invalid format: %s (must be: raw, html, markdown, or text)", r.Format)
+	}
+
+	if r.MaxLength != 0 && (r.MaxLength < 100 || r.MaxLength > 1000000) {
+		return fmt.Errorf("max_length must be between 100 and 1000000")
+	}
+	if r.Chunk < 0 {
+		return fmt.Errorf("chunk must be at least 1")
+	}
+	if r.Chunk > 0 && r.MaxLength == 0 {
+		return fmt.Errorf("chunk requires max_length")
+	}
+	if r.Chunk == 0 {
+		r.Chunk = 1
+	}
+
+	if r.Paragraphs == 0 {
+		r.Paragraphs = 2
+	} else if r.Paragraphs < 1 || r.Paragraphs > 20 {
+		return fmt.Errorf("paragraphs must be between 1 and 20")
+	}
+	// Summaries are made from the body
+	if r.SummaryOnly && !contains(r.Include, "body") && !contains(r.Include, "both") {
+		r.Include = append(r.Include, "body")
+	}
 	
-	return nil
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
 }
 
 // Execute retrieves content from a Hugo site.
-func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
-	// Check if logger is initialized
-	if t.log == nil {
-		t.log = slog.Default().With("tool", t.name)
-	}
-
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
 	contentRequest, ok := req.(*ContentRequest)
 	if !ok {
-		return nil, fmt.Errorf("invalid request type: %T", req)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := contentRequest.SiteOptions.Apply(ctx, t.sites, &contentRequest.HugoSitePath, &contentRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
 	}
 
 	if err := contentRequest.Validate(); err != nil {
-		return nil, err
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
 	}
 
+	ctx, cancel := contentRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = contentRequest.CacheOptions.Apply(ctx, t.ttl)
+
 	// Parse and validate the Hugo site URL
 	siteURL, err := url.Parse(contentRequest.HugoSitePath)
 	if err != nil {
-		t.log.Error("Invalid Hugo site URL", "url", contentRequest.HugoSitePath, "error", err)
-		return nil, fmt.Errorf("invalid Hugo site URL: %w", err)
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", contentRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
 	}
 
 	// Ensure URL has scheme
@@ -139,28 +264,59 @@ func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
 		siteURL.Scheme = "https"
 	}
 
+	// Send credentials, if any, only to the site itself
+	ctx = contentRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	if contentRequest.Explain {
+		return t.explain(ctx, siteURL, contentRequest)
+	}
+
 	var allContent []map[string]interface{}
-	var errors []string
+	var errors []toolerrors.ErrorDetail
 	processedCount := 0
 
 	for _, path := range contentRequest.Paths {
 		if processedCount >= contentRequest.Limit {
 			break
 		}
+		if err := ctx.Err(); err != nil {
+			t.log.WarnContext(ctx, "Content retrieval cancelled", "site", contentRequest.HugoSitePath, "error", err)
+			return nil, fmt.Errorf("content retrieval cancelled: %w", err)
+		}
 
-		content, err := t.getContentForPath(siteURL, path, contentRequest.Include)
+		getContent := t.getContentForPath
+		if contentRequest.Generic {
+			getContent = t.getContentFromHTML
+		}
+		content, err := getContent(ctx, siteURL, path, contentRequest.Include, contentRequest.PublishOptions, contentRequest.Enrich)
 		if err != nil {
-			t.log.Warn("Failed to retrieve content for path", "path", path, "error", err)
-			errors = append(errors, fmt.Sprintf("Path '%s': %s", path, err.Error()))
+			t.log.WarnContext(ctx, "Failed to retrieve content for path", "path", path, "error", err)
+			errors = append(errors, toolerrors.FromError(err, map[string]interface{}{"path": path}))
 			continue
 		}
 
 		if content != nil {
+			if contentRequest.SummaryOnly {
+				summarizeBody(content, contentRequest.Paragraphs, contentRequest.Format)
+				allContent = append(allContent, content)
+				processedCount++
+				continue
+			}
+			formatBody(content, contentRequest.Format)
+			if err := chunkBody(content, contentRequest.MaxLength, contentRequest.Chunk); err != nil {
+				errors = append(errors, toolerrors.FromError(toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err), map[string]interface{}{"path": path}))
+				continue
+			}
 			allContent = append(allContent, content)
 			processedCount++
 		}
 	}
 
+	redirectsJSON, err := json.Marshal(httpclient.Redirects(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal redirects: %w", err)
+	}
+
 	// Format response with comprehensive metadata
 	responseData := fmt.Sprintf(`{
   "success": true,
@@ -170,17 +326,78 @@ func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
     "retrieved_count": %d,
     "error_count": %d,
     "limit_applied": %d,
-    "include_fields": %s
+    "include_fields": %s,
+    "format": "%s",
+    "max_length": %d,
+    "site_source": "%s",
+    "redirects": %s
   },
   "errors": %s
-}`, formatContent(allContent), len(contentRequest.Paths), len(allContent), len(errors), contentRequest.Limit, formatStringArray(contentRequest.Include), formatErrors(errors))
+}`, formatContent(allContent), len(contentRequest.Paths), len(allContent), len(errors), contentRequest.Limit, formatStringArray(contentRequest.Include), contentRequest.Format, contentRequest.MaxLength, sites.Source(ctx), redirectsJSON, toolerrors.FormatErrors(errors))
 
-	t.log.Info("Successfully retrieved content", "requested", len(contentRequest.Paths), "retrieved", len(allContent), "errors", len(errors), "site", contentRequest.HugoSitePath)
-	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(responseData)), nil
+	t.log.InfoContext(ctx, "Successfully retrieved content", "requested", len(contentRequest.Paths), "retrieved", len(allContent), "errors", len(errors), "site", contentRequest.HugoSitePath)
+	return tools.TextResponse([]byte(responseData)), nil
 }
 
-// getContentForPath retrieves content for a single path
-func (t *Tool) getContentForPath(siteURL *url.URL, path string, include []string) (map[string]interface{}, error) {
+// getContentForPath retrieves content for a single path from its JSON
+// output, else the site index, else its rendered HTML
+func (t *Tool) getContentForPath(ctx context.Context, siteURL *url.URL, path string, include []string, publish hugoindex.PublishOptions, enrichPage bool) (map[string]interface{}, error) {
+	var contentData []byte
+	var found bool
+	var usedEndpoint string
+
+	for _, endpointConfig := range contentEndpoints(path) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		contentURL := siteURL.ResolveReference(&url.URL{Path: endpointConfig.path})
+		cacheKey := t.contentKey(siteURL, endpointConfig, path, include)
+
+		t.log.DebugContext(ctx, "Trying content endpoint", "url", contentURL.String(), "cache_key", cacheKey)
+
+		// Serve from cache, revalidating expired entries
+		result, err := t.cache.Fetch(ctx, t.httpClient, cacheKey, contentURL.String(), endpointConfig.validator)
+		if err != nil {
+			t.log.DebugContext(ctx, "Content endpoint unavailable", "url", contentURL.String(), "error", err)
+			continue
+		}
+
+		contentData = result.Data
+		found = true
+		usedEndpoint = contentURL.String()
+		t.log.DebugContext(ctx, "Found content", "url", contentURL.String(), "path", path, "cached", result.Cached, "revalidated", result.Revalidated)
+		break
+	}
+
+	if !found {
+		content, err := t.getContentFromIndex(ctx, siteURL, path, include, publish, enrichPage)
+		if toolerrors.Code(err) != toolerrors.ErrCodeNotFound || ctx.Err() != nil {
+			return content, err
+		}
+		t.log.DebugContext(ctx, "Content not in site index, reading rendered HTML", "path", path, "error", err)
+		if content, htmlErr := t.getContentFromHTML(ctx, siteURL, path, include, publish, enrichPage); htmlErr == nil {
+			return content, nil
+		}
+		return nil, err
+	}
+
+	page := gjson.ParseBytes(contentData)
+	if err := checkPublished(page, publish); err != nil {
+		return nil, err
+	}
+
+	// Extract content from validated JSON
+	content := extractPageContent(page, path, include, usedEndpoint)
+	if enrichPage {
+		addEnrichment(content, page)
+	}
+	return content, nil
+}
+
+// contentEndpoints returns the endpoints a page's JSON output may be
+// published at, in the order they are tried
+func contentEndpoints(path string) []EndpointConfig {
 	// Clean and normalize the path
 	cleanPath := strings.TrimPrefix(path, "/")
 	cleanPath = strings.TrimSuffix(cleanPath, "/")
@@ -191,87 +408,121 @@ func (t *Tool) getContentForPath(siteURL *url.URL, path string, include []string
 	// Try common Hugo content endpoints with better path handling
 	// Also try underscore variations since Hugo may convert hyphens to underscores
 	underscorePath := strings.ReplaceAll(cleanPath, "-", "_")
-	contentEndpoints := []EndpointConfig{
+	return []EndpointConfig{
 		{path: fmt.Sprintf("/%s.json", cleanPath), validator: validateContentStructure},
 		{path: fmt.Sprintf("/%s/index.json", cleanPath), validator: validateContentStructure},
 		{path: fmt.Sprintf("/%s.json", underscorePath), validator: validateContentStructure},
 		{path: fmt.Sprintf("/%s/index.json", underscorePath), validator: validateContentStructure},
 		{path: fmt.Sprintf("/content/%s.json", cleanPath), validator: validateContentStructure},
 		{path: fmt.Sprintf("/content/%s/index.json", cleanPath), validator: validateContentStructure},
-		{path: "/index.json", validator: validateHugoIndexForContent},
 	}
+}
 
-	var contentData []byte
-	var found bool
-	var usedEndpoint string
+// contentKey returns the key the response of a content endpoint is cached
+// under for the requested path and fields
+func (t *Tool) contentKey(siteURL *url.URL, endpoint EndpointConfig, path string, include []string) string {
+	return t.cache.BuildKey(siteURL.String(), endpoint.path, map[string]string{"path": path, "include": strings.Join(include, ",")})
+}
 
-	for _, endpointConfig := range contentEndpoints {
-		contentURL := siteURL.ResolveReference(&url.URL{Path: endpointConfig.path})
-		cacheKey := t.cache.BuildKey(siteURL.String(), endpointConfig.path, map[string]string{"path": path, "include": strings.Join(include, ",")})
-		
-		t.log.Debug("Trying content endpoint", "url", contentURL.String(), "cache_key", cacheKey)
-
-		// Check cache first
-		if cachedData, hit := t.cache.Get(cacheKey); hit {
-			t.log.Debug("Cache hit for content endpoint", "url", contentURL.String())
-			if endpointConfig.validator(cachedData) {
-				contentData = cachedData
-				found = true
-				usedEndpoint = contentURL.String()
-				break
-			} else {
-				t.log.Debug("Cached content data failed validation, invalidating", "url", contentURL.String())
-				t.cache.Delete(cacheKey)
-			}
-		}
+// ReadPage returns the published page at path as a Markdown document
+// headed by its title, for clients that read pages as MCP resources
+func (t *Tool) ReadPage(ctx context.Context, siteURL *url.URL, path string) (string, error) {
+	content, err := t.getContentForPath(ctx, siteURL, path, []string{"both"}, hugoindex.PublishOptions{}, false)
+	if err != nil {
+		return "", err
+	}
+	formatBody(content, FormatMarkdown)
 
-		// Fetch from network
-		resp, err := t.httpClient.Get(contentURL.String())
-		if err != nil {
-			t.log.Debug("Failed to fetch content endpoint", "url", contentURL.String(), "error", err)
-			continue
+	var doc strings.Builder
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		if title, _ := metadata["title"].(string); title != "" {
+			fmt.Fprintf(&doc, "# %s\n\n", title)
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == http.StatusOK {
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				t.log.Debug("Failed to read content response body", "url", contentURL.String(), "error", err)
-				continue
-			}
-
-			// Validate response contains content data
-			if endpointConfig.validator(body) {
-				// Cache the validated response
-				etag := resp.Header.Get("ETag")
-				lastModified := resp.Header.Get("Last-Modified")
-				t.cache.Set(cacheKey, body, etag, lastModified)
-				
-				contentData = body
-				found = true
-				usedEndpoint = contentURL.String()
-				t.log.Debug("Found and cached content", "url", contentURL.String(), "path", path)
-				break
-			} else {
-				t.log.Debug("Response failed content validation", "url", contentURL.String(), "path", path)
-			}
-		} else {
-			t.log.Debug("HTTP error from content endpoint", "url", contentURL.String(), "status", resp.StatusCode)
+	}
+	if body, ok := content["body"].(map[string]interface{}); ok {
+		if text := strings.TrimSpace(selectBody(body)); text != "" {
+			doc.WriteString(text)
+			doc.WriteString("\n")
 		}
 	}
+	return doc.String(), nil
+}
+
+// getContentFromIndex finds the content for a path in the site index
+func (t *Tool) getContentFromIndex(ctx context.Context, siteURL *url.URL, path string, include []string, publish hugoindex.PublishOptions, enrichPage bool) (map[string]interface{}, error) {
+	index, err := hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		t.log.DebugContext(ctx, "Site index unavailable", "site", siteURL.String(), "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "content not found")
+	}
 
+	page, found, err := index.Page(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
 	if !found {
-		return nil, fmt.Errorf("content not found")
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "content not found in index")
+	}
+	if err := checkPublished(page, publish); err != nil {
+		return nil, err
 	}
 
-	// Extract content from validated JSON
-	content := extractContent(contentData, path, include, usedEndpoint)
-	if content == nil {
-		return nil, fmt.Errorf("content not found in index")
+	t.log.DebugContext(ctx, "Found content in index", "url", index.URL(), "path", path, "cached", index.Cached(), "streamed", index.Streamed())
+	content := extractPageContent(page, path, include, index.URL())
+	if enrichPage {
+		addEnrichment(content, page)
+	}
+	return content, nil
+}
+
+// getContentFromHTML reads the content for a path from the page's rendered
+// HTML, for sites that publish no JSON output of their pages
+func (t *Tool) getContentFromHTML(ctx context.Context, siteURL *url.URL, path string, include []string, publish hugoindex.PublishOptions, enrichPage bool) (map[string]interface{}, error) {
+	pageURL := siteURL.ResolveReference(&url.URL{Path: "/" + strings.TrimPrefix(path, "/")})
+	fields, err := generic.Page(ctx, t.cache, t.httpClient, siteURL, pageURL)
+	if err != nil {
+		t.log.DebugContext(ctx, "Rendered page unavailable", "url", pageURL.String(), "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "content not found: %w", err)
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal page: %w", err)
+	}
+
+	page := gjson.ParseBytes(data)
+	if err := checkPublished(page, publish); err != nil {
+		return nil, err
+	}
+
+	t.log.DebugContext(ctx, "Read content from rendered HTML", "url", pageURL.String(), "path", path)
+	content := extractPageContent(page, path, include, pageURL.String())
+	if enrichPage {
+		addEnrichment(content, page)
 	}
 	return content, nil
 }
 
+// addEnrichment adds the word count, reading time and language of page to
+// content, computing those the page lacks
+func addEnrichment(content map[string]interface{}, page gjson.Result) {
+	for field, value := range enrich.Page(page) {
+		content[field] = value
+	}
+}
+
+// checkPublished returns an error if publish excludes the page
+func checkPublished(page gjson.Result, publish hugoindex.PublishOptions) error {
+	switch publish.Exclusion(page, time.Now()) {
+	case hugoindex.ExcludedDraft:
+		return fmt.Errorf("content is a draft (set include_drafts to retrieve it)")
+	case hugoindex.ExcludedFuture:
+		return fmt.Errorf("content is scheduled for future publication (set include_future to retrieve it)")
+	case hugoindex.ExcludedExpired:
+		return fmt.Errorf("content has expired")
+	}
+	return nil
+}
+
 // validateContentStructure checks if the JSON contains valid content data
 func validateContentStructure(data []byte) bool {
 	if !gjson.ValidBytes(data) {
@@ -294,47 +545,13 @@ func validateContentStructure(data []byte) bool {
 	return foundFields >= 2
 }
 
-// validateHugoIndexForContent checks if the Hugo index can provide content
-func validateHugoIndexForContent(data []byte) bool {
-	if !gjson.ValidBytes(data) {
-		return false
-	}
-
-	parsed := gjson.ParseBytes(data)
-
-	// Check if it's a direct array (common in Hugo index.json)
-	if parsed.IsArray() {
-		arr := parsed.Array()
-		if len(arr) > 0 {
-			// Check if at least one item has content-like fields
-			for _, item := range arr {
-				if item.Get("content").Exists() || item.Get("body").Exists() ||
-					item.Get("summary").Exists() || item.Get("title").Exists() {
-					return true
-				}
-			}
-		}
-	}
-
-	// Look for pages with content
-	if pages := parsed.Get("pages"); pages.Exists() && pages.IsArray() {
-		hasContentData := false
-		pages.ForEach(func(key, page gjson.Result) bool {
-			if page.Get("content").Exists() || page.Get("body").Exists() || page.Get("summary").Exists() {
-				hasContentData = true
-				return false // Stop iteration
-			}
-			return true
-		})
-		return hasContentData
-	}
-
-	return validateContentStructure(data)
-}
-
 // extractContent parses content from validated JSON data
 func extractContent(data []byte, requestedPath string, include []string, sourceEndpoint string) map[string]interface{} {
-	parsed := gjson.ParseBytes(data)
+	return extractPageContent(gjson.ParseBytes(data), requestedPath, include, sourceEndpoint)
+}
+
+// extractPageContent builds the requested content fields of a page
+func extractPageContent(parsed gjson.Result, requestedPath string, include []string, sourceEndpoint string) map[string]interface{} {
 	content := make(map[string]interface{})
 
 	includeMetadata := contains(include, "metadata") || contains(include, "both")
@@ -344,81 +561,6 @@ func extractContent(data []byte, requestedPath string, include []string, sourceE
 	content["path"] = requestedPath
 	content["source_endpoint"] = sourceEndpoint
 
-	// Clean the requested path for comparison
-	cleanPath := strings.TrimPrefix(requestedPath, "/")
-	cleanPath = strings.TrimSuffix(cleanPath, "/")
-
-	// If this is a pages array, find the matching page
-	if pages := parsed.Get("pages"); pages.Exists() && pages.IsArray() {
-		var matchedPage gjson.Result
-		pages.ForEach(func(key, page gjson.Result) bool {
-			if pageURL := page.Get("url"); pageURL.Exists() {
-				// Clean and normalize URL for comparison
-				pageURLClean := strings.TrimPrefix(pageURL.String(), "/")
-				pageURLClean = strings.TrimSuffix(pageURLClean, "/")
-				if pageURLClean == cleanPath {
-					matchedPage = page
-					return false // Stop iteration
-				}
-			}
-			if pageSlug := page.Get("slug"); pageSlug.Exists() {
-				// Check slug match
-				if strings.Contains(cleanPath, pageSlug.String()) {
-					matchedPage = page
-					return false // Stop iteration
-				}
-			}
-			return true
-		})
-
-		if matchedPage.Exists() {
-			parsed = matchedPage
-		} else {
-			// If no exact match, return nil to indicate content not found
-			return nil
-		}
-	} else if parsed.IsArray() {
-		// Handle direct array format (common in Hugo index.json)
-		var matchedItem gjson.Result
-		found := false
-
-		parsed.ForEach(func(key, item gjson.Result) bool {
-			// Check various URL/path fields
-			for _, field := range []string{"url", "permalink", "path", "slug"} {
-				if itemPath := item.Get(field); itemPath.Exists() {
-					// Clean and normalize for comparison
-					itemPathClean := strings.TrimPrefix(itemPath.String(), "/")
-					itemPathClean = strings.TrimSuffix(itemPathClean, "/")
-					if itemPathClean == cleanPath {
-						matchedItem = item
-						found = true
-						return false // Stop iteration
-					}
-				}
-			}
-
-			// Also check if title matches the last part of the path
-			if title := item.Get("title"); title.Exists() {
-				titleSlug := strings.ToLower(strings.ReplaceAll(title.String(), " ", "-"))
-				pathParts := strings.Split(cleanPath, "/")
-				if len(pathParts) > 0 && titleSlug == pathParts[len(pathParts)-1] {
-					matchedItem = item
-					found = true
-					return false // Stop iteration
-				}
-			}
-
-			return true
-		})
-
-		if found {
-			parsed = matchedItem
-		} else {
-			// If no match found in array, return nil
-			return nil
-		}
-	}
-	
 	// Extract metadata if requested
 	if includeMetadata {
 		metadata := make(map[string]interface{})
@@ -440,6 +582,7 @@ func extractContent(data []byte, requestedPath string, include []string, sourceE
 			}
 			return true
 		})
+		metadata["params"] = extractParams(parsed)
 		
 		content["metadata"] = metadata
 	}
@@ -463,10 +606,194 @@ func extractContent(data []byte, requestedPath string, include []string, sourceE
 		
 		content["body"] = body
 	}
+
+	// Extract the outline if requested
+	if contains(include, "toc") {
+		toc, source := pageTOC(parsed)
+		content["toc"] = toc
+		if source != "" {
+			content["toc_source"] = source
+		}
+	}
 	
 	return content
 }
 
+// extractParams returns the custom front matter of a page: the fields of
+// its params object and any top-level fields that are not page variables.
+// Values are kept as raw JSON so nested objects, arrays, numbers and
+// booleans keep their types.
+func extractParams(parsed gjson.Result) map[string]json.RawMessage {
+	params := make(map[string]json.RawMessage)
+	parsed.ForEach(func(key, value gjson.Result) bool {
+		if !pageFields[strings.ToLower(key.String())] {
+			params[key.String()] = json.RawMessage(value.Raw)
+		}
+		return true
+	})
+	// Params set explicitly by the index template take precedence
+	if explicit := parsed.Get("params"); explicit.IsObject() {
+		explicit.ForEach(func(key, value gjson.Result) bool {
+			params[key.String()] = json.RawMessage(value.Raw)
+			return true
+		})
+	}
+	return params
+}
+
+// formatBody replaces the body fields of content with a single content
+// field in format, converting HTML to Markdown or text. Bodies that are
+// not HTML, such as raw Markdown, are returned as they are.
+func formatBody(content map[string]interface{}, format string) {
+	body, ok := content["body"].(map[string]interface{})
+	if !ok || format == FormatRaw {
+		return
+	}
+
+	formatted := map[string]interface{}{"content": convertBody(selectBody(body), format)}
+	if summary, _ := body["summary"].(string); summary != "" {
+		formatted["summary"] = convertBody(summary, format)
+	}
+	content["body"] = formatted
+}
+
+// selectBody returns the body to present from the body fields, preferring
+// rendered HTML
+func selectBody(body map[string]interface{}) string {
+	for _, field := range bodyFields {
+		if value, _ := body[field].(string); htmltext.LooksLikeHTML(value) {
+			return value
+		}
+	}
+	for _, field := range bodyFields {
+		if value, _ := body[field].(string); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// summarizeBody replaces the body fields of content with a summary: the
+// content before Hugo's <!--more--> divider, the page summary, or else the
+// first paragraphs. Summaries are plain text for the text format and
+// Markdown otherwise.
+func summarizeBody(content map[string]interface{}, paragraphs int, format string) {
+	body, ok := content["body"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	if format != FormatText {
+		format = FormatMarkdown
+	}
+
+	full := selectBody(body)
+	words := len(strings.Fields(convertBody(moreDivider.ReplaceAllString(full, ""), FormatText)))
+
+	var summary, source string
+	for _, field := range bodyFields {
+		value, _ := body[field].(string)
+		if loc := moreDivider.FindStringIndex(value); loc != nil {
+			summary, source = convertBody(value[:loc[0]], format), SummaryMoreSplit
+			break
+		}
+	}
+	if summary == "" {
+		if value, _ := body["summary"].(string); strings.TrimSpace(value) != "" {
+			summary, source = convertBody(value, format), SummaryField
+		}
+	}
+	if summary == "" {
+		blocks := strings.Split(convertBody(full, format), "\n\n")
+		summary, source = strings.Join(blocks[:min(paragraphs, len(blocks))], "\n\n"), SummaryParagraphs
+	}
+
+	content["body"] = map[string]interface{}{
+		"summary":        strings.TrimSpace(summary),
+		"summary_source": source,
+		"word_count":     words,
+		"reading_time":   enrich.ReadingTime(words),
+	}
+}
+
+// convertBody converts an HTML body to format
+func convertBody(body, format string) string {
+	if !htmltext.LooksLikeHTML(body) {
+		return body
+	}
+	switch format {
+	case FormatMarkdown:
+		return htmltext.Markdown(body)
+	case FormatText:
+		return htmltext.Text(body)
+	}
+	return body
+}
+
+// chunkBody splits the body fields of content into chunks of at most
+// maxLength characters and keeps only the requested 1-based chunk,
+// recording chunk_index, total_chunks and truncated in the body
+func chunkBody(content map[string]interface{}, maxLength, chunk int) error {
+	body, ok := content["body"].(map[string]interface{})
+	if !ok || maxLength == 0 {
+		return nil
+	}
+
+	totalChunks := 1
+	chunks := map[string][]string{}
+	for key, value := range body {
+		text, ok := value.(string)
+		if !ok || key == "summary" {
+			continue
+		}
+		chunks[key] = splitChunks(text, maxLength)
+		totalChunks = max(totalChunks, len(chunks[key]))
+	}
+
+	if chunk > totalChunks {
+		return fmt.Errorf("chunk %d out of range (total_chunks: %d)", chunk, totalChunks)
+	}
+
+	for key, parts := range chunks {
+		if chunk <= len(parts) {
+			body[key] = parts[chunk-1]
+		} else {
+			body[key] = ""
+		}
+	}
+	body["chunk_index"] = chunk
+	body["total_chunks"] = totalChunks
+	body["truncated"] = chunk < totalChunks
+	return nil
+}
+
+// splitChunks splits s into chunks of at most maxLength characters,
+// preferring to break between paragraphs, then lines, then words
+func splitChunks(s string, maxLength int) []string {
+	runes := []rune(s)
+	if len(runes) <= maxLength {
+		return []string{s}
+	}
+
+	var chunks []string
+	for len(runes) > maxLength {
+		window := string(runes[:maxLength])
+		cut := len(runes[:maxLength])
+		for _, sep := range []string{"\n\n", "\n", " "} {
+			// Only break early when it keeps at least half the chunk
+			if i := strings.LastIndex(window, sep); i > 0 && len([]rune(window[:i])) >= maxLength/2 {
+				cut = len([]rune(window[:i+len(sep)]))
+				break
+			}
+		}
+		chunks = append(chunks, strings.TrimRight(string(runes[:cut]), "\n "))
+		runes = runes[cut:]
+	}
+	if len(runes) > 0 {
+		chunks = append(chunks, string(runes))
+	}
+	return chunks
+}
+
 // Helper functions
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -497,11 +824,16 @@ func formatContentItem(item map[string]interface{}) string {
 	for key, value := range item {
 		switch v := value.(type) {
 		case string:
-			parts = append(parts, fmt.Sprintf(`"%s": "%s"`, key, strings.ReplaceAll(v, `"`, `\"`)))
+			encoded, _ := json.Marshal(v)
+			parts = append(parts, fmt.Sprintf(`"%s": %s`, key, encoded))
 		case map[string]interface{}:
 			parts = append(parts, fmt.Sprintf(`"%s": %s`, key, formatContentItem(v)))
 		default:
-			parts = append(parts, fmt.Sprintf(`"%s": %v`, key, v))
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				encoded = []byte("null")
+			}
+			parts = append(parts, fmt.Sprintf(`"%s": %s`, key, encoded))
 		}
 	}
 	
@@ -521,19 +853,6 @@ func formatStringArray(arr []string) string {
 	return "[" + strings.Join(quoted, ", ") + "]"
 }
 
-func formatErrors(errors []string) string {
-	if len(errors) == 0 {
-		return "[]"
-	}
-	
-	var quoted []string
-	for _, err := range errors {
-		quoted = append(quoted, fmt.Sprintf(`"%s"`, strings.ReplaceAll(err, `"`, `\"`)))
-	}
-	
-	return "[\n    " + strings.Join(quoted, ",\n    ") + "\n  ]"
-}
-
 // Name returns the name of the tool.
 func (t *Tool) Name() string {
 	return t.name
@@ -544,6 +863,11 @@ func (t *Tool) Description() string {
 	return t.description
 }
 
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*ContentRequest](registry, t, "Content retrieval", tools.ReadOnly)
+}
+
 // SetLogger sets the logger for the Tool.
 func (t *Tool) SetLogger(logger *slog.Logger) {
 	if logger == nil {
@@ -1,10 +1,24 @@
 package content
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/generic"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 )
 
 func TestNew(t *testing.T) {
@@ -16,6 +30,28 @@ func TestNew(t *testing.T) {
 	assert.NotNil(t, tool.httpClient)
 }
 
+func TestWithCache_SharedWithLogger(t *testing.T) {
+	shared := cache.New()
+	logger := slog.New(slog.DiscardHandler)
+
+	// The shared cache is kept whichever order the options are given in
+	for _, opts := range [][]ToolOption{
+		{WithLogger(logger), WithCache(shared)},
+		{WithCache(shared), WithLogger(logger)},
+	} {
+		tool, err := New(opts...)
+		require.NoError(t, err)
+		assert.Same(t, shared, tool.cache)
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	client := httpclient.New()
+	tool, err := New(WithHTTPClient(client))
+	require.NoError(t, err)
+	assert.Same(t, client, tool.httpClient)
+}
+
 func TestContentRequest_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -56,6 +92,15 @@ func TestContentRequest_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "toc include",
+			req: &ContentRequest{
+				HugoSitePath: "https://example.com",
+				Paths:        []string{"docs/install"},
+				Include:      []string{"metadata", "toc"},
+			},
+			wantErr: false,
+		},
 		{
 			name: "invalid include value",
 			req: &ContentRequest{
@@ -83,6 +128,71 @@ func TestContentRequest_Validate(t *testing.T) {
 			},
 			wantErr: false, // 0 gets set to default (50)
 		},
+		{
+			name: "markdown format",
+			req: &ContentRequest{
+				HugoSitePath: "https://example.com",
+				Paths:        []string{"posts/article1"},
+				Format:       FormatMarkdown,
+			},
+			wantErr: false,
+		},
+		{
+			name: "summary only adds body",
+			req: &ContentRequest{
+				HugoSitePath: "https://example.com",
+				Paths:        []string{"posts/article1"},
+				Include:      []string{"metadata"},
+				SummaryOnly:  true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "too many paragraphs",
+			req: &ContentRequest{
+				HugoSitePath: "https://example.com",
+				Paths:        []string{"posts/article1"},
+				SummaryOnly:  true,
+				Paragraphs:   21,
+			},
+			wantErr: true,
+		},
+		{
+			name: "chunk without max_length",
+			req: &ContentRequest{
+				HugoSitePath: "https://example.com",
+				Paths:        []string{"posts/article1"},
+				Chunk:        2,
+			},
+			wantErr: true,
+		},
+		{
+			name: "max_length too low",
+			req: &ContentRequest{
+				HugoSitePath: "https://example.com",
+				Paths:        []string{"posts/article1"},
+				MaxLength:    10,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid format",
+			req: &ContentRequest{
+				HugoSitePath: "https://example.com",
+				Paths:        []string{"posts/article1"},
+				Format:       "pdf",
+			},
+			wantErr: true,
+		},
+		{
+			name: "cache TTL out of range",
+			req: &ContentRequest{
+				HugoSitePath: "https://example.com",
+				Paths:        []string{"posts/article1"},
+				CacheOptions: cache.CacheOptions{CacheTTLSeconds: -1},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -145,40 +255,348 @@ func TestValidateContentStructure(t *testing.T) {
 	}
 }
 
-func TestValidateHugoIndexForContent(t *testing.T) {
+func TestTool_GetContentFromIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"pages": [
+			{"title": "My Post", "url": "/posts/my-post/", "content": "Post content"},
+			{"title": "Draft", "url": "/posts/draft/", "draft": true}
+		]}`))
+	}))
+	defer server.Close()
+
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	content, err := tool.getContentForPath(context.Background(), siteURL, "/posts/my-post/", []string{"both"}, hugoindex.PublishOptions{}, false)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/index.json", content["source_endpoint"])
+	assert.Equal(t, "My Post", content["metadata"].(map[string]interface{})["title"])
+
+	_, err = tool.getContentForPath(context.Background(), siteURL, "/posts/missing/", []string{"both"}, hugoindex.PublishOptions{}, false)
+	assert.Error(t, err)
+
+	// Drafts are only returned on request
+	_, err = tool.getContentForPath(context.Background(), siteURL, "/posts/draft/", []string{"both"}, hugoindex.PublishOptions{}, false)
+	assert.ErrorContains(t, err, "include_drafts")
+
+	content, err = tool.getContentForPath(context.Background(), siteURL, "/posts/draft/", []string{"both"}, hugoindex.PublishOptions{IncludeDrafts: true}, false)
+	require.NoError(t, err)
+	assert.Equal(t, "Draft", content["metadata"].(map[string]interface{})["title"])
+	assert.NotContains(t, content, "word_count")
+
+	// Enrichment is opt-in
+	content, err = tool.getContentForPath(context.Background(), siteURL, "/posts/my-post/", []string{"metadata"}, hugoindex.PublishOptions{}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, content["word_count"])
+	assert.Equal(t, 1, content["reading_time_minutes"])
+	assert.NotContains(t, content, "language")
+}
+
+func TestTool_ReadPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[
+			{"title": "My Post", "url": "/posts/my-post/", "content": "<p>Some <strong>bold</strong> text.</p>"},
+			{"title": "Draft", "url": "/posts/draft/", "draft": true}
+		]`))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	page, err := tool.ReadPage(context.Background(), siteURL, "/posts/my-post/")
+	require.NoError(t, err)
+	assert.Equal(t, "# My Post\n\nSome **bold** text.\n", page)
+
+	_, err = tool.ReadPage(context.Background(), siteURL, "/posts/draft/")
+	assert.Error(t, err)
+}
+
+func TestTool_Execute_Format(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/posts/html.json":
+			w.Write([]byte(`{"title": "HTML", "url": "/posts/html/", "content": "<h2>Intro</h2>\n<p>Some <em>\"quoted\"</em> text.</p>", "summary": "<p>Short</p>"}`))
+		case "/posts/markdown.json":
+			w.Write([]byte(`{"title": "Markdown", "url": "/posts/markdown/", "body": "## Intro\n\nSome <text>", "html": "<p>Rendered</p>"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
 	tests := []struct {
-		name     string
-		data     string
-		expected bool
+		format   string
+		path     string
+		expected string
+		summary  string
+	}{
+		{format: FormatMarkdown, path: "posts/html", expected: "## Intro\n\nSome _\"quoted\"_ text.", summary: "Short"},
+		{format: FormatText, path: "posts/html", expected: "Intro\n\nSome \"quoted\" text.", summary: "Short"},
+		{format: FormatHTML, path: "posts/html", expected: "<h2>Intro</h2>\n<p>Some <em>\"quoted\"</em> text.</p>", summary: "<p>Short</p>"},
+		{format: FormatMarkdown, path: "posts/markdown", expected: "Rendered"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.path, func(t *testing.T) {
+			resp, err := tool.Execute(context.Background(), &ContentRequest{HugoSitePath: server.URL, Paths: []string{tt.path}, Include: []string{"body"}, Format: tt.format})
+			require.NoError(t, err)
+
+			text := resp.Content[0].TextContent.Text
+			require.True(t, gjson.Valid(text), text)
+			result := gjson.Parse(text)
+			assert.Equal(t, tt.format, result.Get("metadata.format").String())
+			assert.Equal(t, tt.expected, result.Get("content.0.body.content").String())
+			assert.Equal(t, tt.summary, result.Get("content.0.body.summary").String())
+			assert.False(t, result.Get("content.0.body.html").Exists())
+		})
+	}
+
+	// Raw returns every body field as served
+	resp, err := tool.Execute(context.Background(), &ContentRequest{HugoSitePath: server.URL, Paths: []string{"posts/markdown"}, Include: []string{"body"}})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, "## Intro\n\nSome <text>", result.Get("content.0.body.body").String())
+	assert.Equal(t, "<p>Rendered</p>", result.Get("content.0.body.html").String())
+}
+
+func TestTool_Execute_CacheOptions(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/posts/page.json" {
+			http.NotFound(w, r)
+			return
+		}
+		requests++
+		fmt.Fprintf(w, `{"title": "Version %d", "url": "/posts/page/"}`, requests)
+	}))
+	defer server.Close()
+
+	tool, err := New(WithCache(cache.New()), WithTTL(time.Hour))
+	require.NoError(t, err)
+
+	title := func(req *ContentRequest) string {
+		req.HugoSitePath = server.URL
+		req.Paths = []string{"posts/page"}
+		resp, err := tool.Execute(context.Background(), req)
+		require.NoError(t, err)
+		return gjson.Get(resp.Content[0].TextContent.Text, "content.0.metadata.title").String()
+	}
+
+	assert.Equal(t, "Version 1", title(&ContentRequest{}))
+	assert.Equal(t, "Version 1", title(&ContentRequest{}))
+	assert.Equal(t, 1, requests)
+
+	// Bypassing the cache fetches the page again and caches the new version
+	assert.Equal(t, "Version 2", title(&ContentRequest{CacheOptions: cache.CacheOptions{BypassCache: true}}))
+	assert.Equal(t, "Version 2", title(&ContentRequest{}))
+	assert.Equal(t, 2, requests)
+}
+
+func TestTool_Execute_Explain(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/posts/page.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"title": "Page", "url": "/posts/page/"}`))
+	}))
+	defer server.Close()
+
+	tool, err := New(WithCache(cache.New()))
+	require.NoError(t, err)
+	_, err = tool.Execute(context.Background(), &ContentRequest{HugoSitePath: server.URL, Paths: []string{"posts/page"}})
+	require.NoError(t, err)
+	before := requests
+
+	resp, err := tool.Execute(context.Background(), &ContentRequest{
+		HugoSitePath:   server.URL,
+		Paths:          []string{"posts/page", "/my-post/", "/ignored/"},
+		Limit:          2,
+		ExplainOptions: tools.ExplainOptions{Explain: true},
+		PublishOptions: hugoindex.PublishOptions{IncludeDrafts: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, before, requests, "explain fetches nothing")
+
+	body := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.True(t, body.Get("plan.filters.include_drafts").Bool())
+	steps := body.Get("plan.steps").Array()
+	require.Len(t, steps, 2*8)
+	assert.Equal(t, "/posts/page.json", steps[0].Get("endpoint").String())
+	assert.Equal(t, cache.StateFresh, steps[0].Get("cache.state").String())
+	assert.Equal(t, MethodSiteIndex, steps[6].Get("method").String())
+	assert.Equal(t, MethodRenderedHTML, steps[7].Get("method").String())
+	assert.Equal(t, "/posts/page", steps[7].Get("endpoint").String())
+	assert.Equal(t, "/my-post/", steps[8].Get("path").String())
+	assert.Equal(t, "/my_post.json", steps[10].Get("endpoint").String())
+	assert.Equal(t, cache.StateMissing, steps[10].Get("cache.state").String())
+
+	// Generic mode only reads rendered HTML
+	resp, err = tool.Execute(context.Background(), &ContentRequest{
+		HugoSitePath:   server.URL,
+		Paths:          []string{"/my-post/"},
+		ExplainOptions: tools.ExplainOptions{Explain: true},
+		Options:        generic.Options{Generic: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `["rendered_html"]`, gjson.Get(resp.Content[0].TextContent.Text, "plan.steps.#.method").Raw)
+	assert.Equal(t, before, requests, "explain fetches nothing")
+}
+
+func TestTool_Execute_RenderedHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/posts/plain/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`<html><head><title>Plain Page</title><meta name="description" content="No JSON here."></head>
+<body><nav>Menu</nav><main><h1>Plain Page</h1><p>Rendered <strong>only</strong>.</p></main></body></html>`))
+	}))
+	defer server.Close()
+
+	tool, err := New(WithCache(cache.New()))
+	require.NoError(t, err)
+
+	// Pages with no JSON output or index entry fall back to their HTML,
+	// which generic mode reads directly
+	for _, genericMode := range []bool{false, true} {
+		resp, err := tool.Execute(context.Background(), &ContentRequest{
+			HugoSitePath: server.URL,
+			Paths:        []string{"/posts/plain/"},
+			Format:       FormatMarkdown,
+			Options:      generic.Options{Generic: genericMode},
+		})
+		require.NoError(t, err)
+		body := gjson.Parse(resp.Content[0].TextContent.Text)
+		assert.Equal(t, int64(1), body.Get("metadata.retrieved_count").Int())
+		item := body.Get("content.0")
+		assert.Equal(t, server.URL+"/posts/plain/", item.Get("source_endpoint").String())
+		assert.Equal(t, "Plain Page", item.Get("metadata.title").String())
+		assert.Equal(t, "posts", item.Get("metadata.section").String())
+		assert.Equal(t, "# Plain Page\n\nRendered **only**.", item.Get("body.content").String())
+	}
+}
+
+func TestSummarizeBody(t *testing.T) {
+	article := "<h2>Intro</h2><p>First paragraph here.</p><p>Second <em>one</em>.</p><p>Third.</p>"
+
+	tests := []struct {
+		name       string
+		body       map[string]interface{}
+		paragraphs int
+		format     string
+		summary    string
+		source     string
+		words      int
 	}{
 		{
-			name:     "hugo index with pages containing content",
-			data:     `{"pages": [{"title": "Post 1", "content": "Content here"}, {"title": "Post 2", "summary": "Summary"}]}`,
-			expected: true,
+			name:       "more divider",
+			body:       map[string]interface{}{"body": "Lead **text**.\n\n<!-- more -->\n\nRest of the post.", "summary": "Auto summary"},
+			paragraphs: 2,
+			summary:    "Lead **text**.",
+			source:     SummaryMoreSplit,
+			words:      6,
 		},
 		{
-			name:     "hugo index without content in pages",
-			data:     `{"pages": [{"title": "Post 1", "random": "data"}]}`,
-			expected: false,
+			name:       "summary field",
+			body:       map[string]interface{}{"content": article, "summary": "<p>Hugo <b>summary</b></p>"},
+			paragraphs: 2,
+			format:     FormatText,
+			summary:    "Hugo summary",
+			source:     SummaryField,
+			words:      7,
 		},
 		{
-			name:     "fallback to direct content validation",
-			data:     `{"title": "Page", "content": "Content", "date": "2023-01-01"}`,
-			expected: true,
+			name:       "first paragraphs",
+			body:       map[string]interface{}{"content": article},
+			paragraphs: 3,
+			summary:    "## Intro\n\nFirst paragraph here.\n\nSecond _one_.",
+			source:     SummaryParagraphs,
+			words:      7,
 		},
 		{
-			name:     "invalid JSON",
-			data:     `{invalid}`,
-			expected: false,
+			name:       "fewer paragraphs than requested",
+			body:       map[string]interface{}{"content": "<p>Only one.</p>"},
+			paragraphs: 5,
+			summary:    "Only one.",
+			source:     SummaryParagraphs,
+			words:      2,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := validateHugoIndexForContent([]byte(tt.data))
-			assert.Equal(t, tt.expected, result)
+			content := map[string]interface{}{"body": tt.body}
+			summarizeBody(content, tt.paragraphs, tt.format)
+
+			body := content["body"].(map[string]interface{})
+			assert.Equal(t, tt.summary, body["summary"])
+			assert.Equal(t, tt.source, body["summary_source"])
+			assert.Equal(t, tt.words, body["word_count"])
+			assert.Equal(t, 1, body["reading_time"])
 		})
 	}
+
+	content := map[string]interface{}{"body": map[string]interface{}{"content": strings.Repeat("word ", 500)}}
+	summarizeBody(content, 2, FormatRaw)
+	assert.Equal(t, 3, content["body"].(map[string]interface{})["reading_time"])
+}
+
+func TestSplitChunks(t *testing.T) {
+	paragraph := strings.Repeat("word ", 15) + "end."
+	text := paragraph + "\n\n" + paragraph + "\n\n" + paragraph
+
+	chunks := splitChunks(text, 100)
+	require.Len(t, chunks, 3)
+	for _, chunk := range chunks {
+		assert.Equal(t, paragraph, chunk)
+	}
+
+	// Text without breaks is cut at the limit, counting characters
+	chunks = splitChunks(strings.Repeat("é", 250), 100)
+	require.Len(t, chunks, 3)
+	assert.Equal(t, strings.Repeat("é", 100), chunks[0])
+	assert.Equal(t, strings.Repeat("é", 50), chunks[2])
+
+	assert.Equal(t, []string{"short"}, splitChunks("short", 100))
+}
+
+func TestChunkBody(t *testing.T) {
+	long := strings.Repeat("a", 250)
+	content := map[string]interface{}{"body": map[string]interface{}{"content": long, "summary": "Short"}}
+
+	require.NoError(t, chunkBody(content, 100, 3))
+	body := content["body"].(map[string]interface{})
+	assert.Equal(t, strings.Repeat("a", 50), body["content"])
+	assert.Equal(t, "Short", body["summary"])
+	assert.Equal(t, 3, body["chunk_index"])
+	assert.Equal(t, 3, body["total_chunks"])
+	assert.Equal(t, false, body["truncated"])
+
+	content = map[string]interface{}{"body": map[string]interface{}{"content": long}}
+	require.NoError(t, chunkBody(content, 100, 1))
+	assert.Equal(t, true, content["body"].(map[string]interface{})["truncated"])
+
+	assert.ErrorContains(t, chunkBody(map[string]interface{}{"body": map[string]interface{}{"content": long}}, 100, 4), "total_chunks: 3")
 }
 
 func TestExtractContent(t *testing.T) {
@@ -210,6 +628,13 @@ func TestExtractContent(t *testing.T) {
 			include:        []string{"both"},
 			expectedFields: []string{"path", "source_endpoint", "metadata", "body"},
 		},
+		{
+			name:           "outline only",
+			data:           `{"title": "My Post", "content": "<h2 id=\"intro\">Intro</h2><p>Text</p>"}`,
+			requestedPath:  "posts/my-post",
+			include:        []string{"toc"},
+			expectedFields: []string{"path", "source_endpoint", "toc", "toc_source"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -268,37 +693,6 @@ func TestFormatStringArray(t *testing.T) {
 	}
 }
 
-func TestFormatErrors(t *testing.T) {
-	tests := []struct {
-		name     string
-		errors   []string
-		expected string
-	}{
-		{
-			name:     "no errors",
-			errors:   []string{},
-			expected: "[]",
-		},
-		{
-			name:     "single error",
-			errors:   []string{"Path not found"},
-			expected: "[\n    \"Path not found\"\n  ]",
-		},
-		{
-			name:     "multiple errors",
-			errors:   []string{"Path not found", "Invalid format"},
-			expected: "[\n    \"Path not found\",\n    \"Invalid format\"\n  ]",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := formatErrors(tt.errors)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 func TestTool_SetLogger(t *testing.T) {
 	tool, err := New()
 	require.NoError(t, err)
@@ -309,4 +703,42 @@ func TestTool_SetLogger(t *testing.T) {
 
 	// Test that it doesn't panic with valid logger
 	// We can't easily test the logger content without more setup
-}
\ No newline at end of file
+}
+func TestExtractParams(t *testing.T) {
+	page := gjson.Parse(`{
+		"title": "My Post",
+		"tags": ["go"],
+		"featured": true,
+		"rating": 4.5,
+		"params": {
+			"rating": 5,
+			"series": {"name": "Hugo", "part": 2, "parts": [1, 2, 3]},
+			"sources": [{"url": "https://gohugo.io/", "archived": false}],
+			"big": 9007199254740993,
+			"empty": null
+		}
+	}`)
+
+	params := extractParams(page)
+	assert.NotContains(t, params, "title")
+	assert.NotContains(t, params, "tags")
+	assert.NotContains(t, params, "params")
+	assert.JSONEq(t, `true`, string(params["featured"]))
+	assert.JSONEq(t, `5`, string(params["rating"]))
+	assert.JSONEq(t, `{"name": "Hugo", "part": 2, "parts": [1, 2, 3]}`, string(params["series"]))
+	assert.JSONEq(t, `[{"url": "https://gohugo.io/", "archived": false}]`, string(params["sources"]))
+	assert.Equal(t, `9007199254740993`, string(params["big"]))
+	assert.Equal(t, `null`, string(params["empty"]))
+
+	assert.Empty(t, extractParams(gjson.Parse(`{"title": "No params", "content": "Body"}`)))
+
+	// Nested params survive the response encoding with their types
+	item := extractContent([]byte(page.Raw), "posts/my-post", []string{"metadata"}, "http://example.com/test.json")
+	text := formatContentItem(item)
+	require.True(t, gjson.Valid(text), text)
+	result := gjson.Parse(text)
+	assert.Equal(t, gjson.True, result.Get("metadata.params.featured").Type)
+	assert.Equal(t, int64(2), result.Get("metadata.params.series.part").Int())
+	assert.JSONEq(t, `[1, 2, 3]`, result.Get("metadata.params.series.parts").Raw)
+	assert.Equal(t, `["go"]`, result.Get("metadata.tags").Raw)
+}
@@ -0,0 +1,449 @@
+package cooccurrence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/tidwall/gjson"
+)
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool reports which taxonomy terms appear together on the pages of a Hugo
+// site.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// CooccurrenceRequest represents the request parameters for the term
+// co-occurrence tool.
+type CooccurrenceRequest struct {
+	HugoSitePath string   `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	Taxonomy     string   `json:"taxonomy" jsonschema:"title=Taxonomy Name (e.g. tags)"`
+	Term         string   `json:"term,omitempty" jsonschema:"title=Term to report co-occurring terms for; omit to report the most frequent term pairs"`
+	Taxonomies   []string `json:"taxonomies,omitempty" jsonschema:"title=Taxonomies co-occurring terms are taken from (default: all)"`
+	Limit        int      `json:"limit,omitempty" jsonschema:"title=Result Limit,minimum=1,maximum=200"`
+	MinCount     int      `json:"min_count,omitempty" jsonschema:"title=Minimum pages a pair must share (default: 1),minimum=1"`
+
+	hugoindex.PublishOptions
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// TermRef identifies a term within its taxonomy
+type TermRef struct {
+	Taxonomy string `json:"taxonomy"`
+	Term     string `json:"term"`
+}
+
+// Cooccurrence is a term appearing on pages with the requested term
+type Cooccurrence struct {
+	TermRef
+	Count int     `json:"count"`
+	Ratio float64 `json:"ratio"`
+}
+
+// Pair is two terms appearing on the same pages
+type Pair struct {
+	Terms [2]TermRef `json:"terms"`
+	Count int        `json:"count"`
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_term_cooccurrence"),
+		name:        "hugo_reader_term_cooccurrence",
+		description: "Report which taxonomy terms most often appear on the same pages of a Hugo site, computed from the site index. Give a taxonomy and term (e.g. tags 'golang') to rank the terms used alongside it, or just a taxonomy to rank its most frequent term pairs. Useful for understanding the topical structure of a blog.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *CooccurrenceRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+	r.Taxonomy = strings.TrimSpace(r.Taxonomy)
+	if r.Taxonomy == "" {
+		return fmt.Errorf("taxonomy is required")
+	}
+	r.Term = strings.TrimSpace(r.Term)
+
+	if r.Limit == 0 {
+		r.Limit = 20
+	} else if r.Limit < 1 || r.Limit > 200 {
+		return fmt.Errorf("limit must be between 1 and 200")
+	}
+	if r.MinCount == 0 {
+		r.MinCount = 1
+	} else if r.MinCount < 1 {
+		return fmt.Errorf("min_count must be at least 1")
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// Execute reports co-occurring terms.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	cooccurrenceRequest, ok := req.(*CooccurrenceRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := cooccurrenceRequest.SiteOptions.Apply(ctx, t.sites, &cooccurrenceRequest.HugoSitePath, &cooccurrenceRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := cooccurrenceRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := cooccurrenceRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = cooccurrenceRequest.CacheOptions.Apply(ctx, 0)
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(cooccurrenceRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", cooccurrenceRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = cooccurrenceRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	index, err := hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Site index unavailable", "site", cooccurrenceRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.Code(err), "site index not available at Hugo site %s: %w", cooccurrenceRequest.HugoSitePath, err)
+	}
+
+	counter := newCounter(siteURL, cooccurrenceRequest)
+	if err := index.Pages(ctx, func(page gjson.Result) bool {
+		counter.add(page)
+		return true
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read site index: %w", err)
+	}
+
+	if counter.taxonomyPages == 0 {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "no pages use taxonomy '%s' at Hugo site: %s", cooccurrenceRequest.Taxonomy, cooccurrenceRequest.HugoSitePath)
+	}
+	if cooccurrenceRequest.Term != "" && counter.termPages == 0 {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "no pages use %s term '%s' at Hugo site: %s", cooccurrenceRequest.Taxonomy, cooccurrenceRequest.Term, cooccurrenceRequest.HugoSitePath)
+	}
+
+	response := map[string]interface{}{
+		"success":  true,
+		"taxonomy": cooccurrenceRequest.Taxonomy,
+	}
+	var total int
+	if cooccurrenceRequest.Term != "" {
+		cooccurring := counter.cooccurring()
+		total = len(cooccurring)
+		response["term"] = cooccurrenceRequest.Term
+		response["cooccurring"] = cooccurring[:min(len(cooccurring), cooccurrenceRequest.Limit)]
+	} else {
+		pairs := counter.topPairs()
+		total = len(pairs)
+		response["pairs"] = pairs[:min(len(pairs), cooccurrenceRequest.Limit)]
+	}
+
+	metadata := map[string]interface{}{
+		"source_endpoint":     index.URL(),
+		"cached":              index.Cached(),
+		"streamed":            index.Streamed(),
+		"pages_scanned":       counter.pages,
+		"pages_with_taxonomy": counter.taxonomyPages,
+		"excluded_count":      counter.excluded,
+		"total_results":       total,
+		"min_count":           cooccurrenceRequest.MinCount,
+		"limit":               cooccurrenceRequest.Limit,
+		"site_source":         sites.Source(ctx),
+	}
+	if cooccurrenceRequest.Term != "" {
+		metadata["pages_with_term"] = counter.termPages
+	}
+	response["metadata"] = metadata
+	response["errors"] = []string{}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal term co-occurrence", "error", err)
+		return nil, fmt.Errorf("failed to marshal term co-occurrence: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Computed term co-occurrence", "site", cooccurrenceRequest.HugoSitePath, "taxonomy", cooccurrenceRequest.Taxonomy, "term", cooccurrenceRequest.Term, "pages", counter.pages, "results", total)
+	return tools.TextResponse(responseJSON), nil
+}
+
+// counter tallies the terms that appear together on published pages, once
+// per page however many aliases the index lists it under
+type counter struct {
+	siteURL    *url.URL
+	taxonomy   string
+	term       string
+	taxonomies map[string]bool
+	minCount   int
+	publish    hugoindex.PublishOptions
+	now        time.Time
+	seen       map[string]bool
+
+	pages         int
+	taxonomyPages int
+	termPages     int
+	excluded      int
+	counts        map[TermRef]int
+	pairs         map[[2]TermRef]int
+}
+
+func newCounter(siteURL *url.URL, req *CooccurrenceRequest) *counter {
+	c := &counter{
+		siteURL:  siteURL,
+		taxonomy: req.Taxonomy,
+		term:     req.Term,
+		minCount: req.MinCount,
+		publish:  req.PublishOptions,
+		now:      time.Now(),
+		seen:     make(map[string]bool),
+		counts:   make(map[TermRef]int),
+		pairs:    make(map[[2]TermRef]int),
+	}
+	if len(req.Taxonomies) > 0 {
+		c.taxonomies = make(map[string]bool)
+		for _, taxonomy := range req.Taxonomies {
+			c.taxonomies[taxonomy] = true
+		}
+	}
+	return c
+}
+
+// add counts the terms of page
+func (c *counter) add(page gjson.Result) {
+	if key := hugoindex.CanonicalURL(c.siteURL, hugoindex.PageURL(page)); key != "" {
+		if c.seen[key] {
+			return
+		}
+		c.seen[key] = true
+	}
+	if !c.publish.Allows(page, c.now) {
+		c.excluded++
+		return
+	}
+	c.pages++
+
+	own, others := c.pageTerms(page)
+	if len(own) == 0 {
+		return
+	}
+	c.taxonomyPages++
+
+	if c.term != "" {
+		var match *TermRef
+		for i := range own {
+			if strings.EqualFold(own[i].Term, c.term) {
+				match = &own[i]
+				break
+			}
+		}
+		if match == nil {
+			return
+		}
+		c.termPages++
+		for _, other := range others {
+			if other != *match {
+				c.counts[other]++
+			}
+		}
+		return
+	}
+
+	// Count each pair with a term of the taxonomy once, in a fixed order
+	for _, a := range own {
+		for _, b := range others {
+			if a == b || (b.Taxonomy == c.taxonomy && !less(a, b)) {
+				continue
+			}
+			c.pairs[orderedPair(a, b)]++
+		}
+	}
+}
+
+// pageTerms returns the distinct terms of the requested taxonomy on page,
+// and those of the taxonomies co-occurring terms are taken from
+func (c *counter) pageTerms(page gjson.Result) (own, others []TermRef) {
+	seen := make(map[TermRef]bool)
+	for taxonomy, terms := range hugoindex.PageTaxonomies(page) {
+		for _, term := range terms {
+			ref := TermRef{Taxonomy: taxonomy, Term: term}
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+			if taxonomy == c.taxonomy {
+				own = append(own, ref)
+			}
+			if c.taxonomies == nil || c.taxonomies[taxonomy] {
+				others = append(others, ref)
+			}
+		}
+	}
+	return own, others
+}
+
+// cooccurring returns the terms found with the requested term on at least
+// minCount pages, most frequent first
+func (c *counter) cooccurring() []Cooccurrence {
+	results := []Cooccurrence{}
+	for ref, count := range c.counts {
+		if count < c.minCount {
+			continue
+		}
+		results = append(results, Cooccurrence{TermRef: ref, Count: count, Ratio: float64(count) / float64(c.termPages)})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Count != results[j].Count {
+			return results[i].Count > results[j].Count
+		}
+		return less(results[i].TermRef, results[j].TermRef)
+	})
+	return results
+}
+
+// topPairs returns the pairs found on at least minCount pages, most
+// frequent first
+func (c *counter) topPairs() []Pair {
+	results := []Pair{}
+	for terms, count := range c.pairs {
+		if count >= c.minCount {
+			results = append(results, Pair{Terms: terms, Count: count})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Count != results[j].Count {
+			return results[i].Count > results[j].Count
+		}
+		if results[i].Terms[0] != results[j].Terms[0] {
+			return less(results[i].Terms[0], results[j].Terms[0])
+		}
+		return less(results[i].Terms[1], results[j].Terms[1])
+	})
+	return results
+}
+
+// orderedPair returns a and b in a fixed order
+func orderedPair(a, b TermRef) [2]TermRef {
+	if less(b, a) {
+		return [2]TermRef{b, a}
+	}
+	return [2]TermRef{a, b}
+}
+
+// less orders terms by taxonomy and then term
+func less(a, b TermRef) bool {
+	if a.Taxonomy != b.Taxonomy {
+		return a.Taxonomy < b.Taxonomy
+	}
+	return a.Term < b.Term
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*CooccurrenceRequest](registry, t, "Content organization exploration", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
@@ -0,0 +1,125 @@
+package cooccurrence
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+const siteIndex = `{"pages": [
+	{"title": "A", "url": "/a/", "tags": ["go", "concurrency"], "categories": ["programming"]},
+	{"title": "A alias", "url": "/a/index.html", "tags": ["go", "concurrency"], "categories": ["programming"]},
+	{"title": "B", "url": "/b/", "tags": ["go", "concurrency", "go"]},
+	{"title": "C", "url": "/c/", "tags": ["Go", "testing"], "categories": ["programming"]},
+	{"title": "D", "url": "/d/", "tags": ["travel"]},
+	{"title": "E", "url": "/e/", "tags": ["go", "rust"], "draft": true},
+	{"title": "F", "url": "/f/", "categories": ["misc"]}
+]}`
+
+func newServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(siteIndex))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCooccurrenceRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     CooccurrenceRequest
+		wantErr string
+	}{
+		{name: "valid", req: CooccurrenceRequest{HugoSitePath: "https://example.com", Taxonomy: "tags"}},
+		{name: "missing site", req: CooccurrenceRequest{Taxonomy: "tags"}, wantErr: "hugo_site_path is required"},
+		{name: "missing taxonomy", req: CooccurrenceRequest{HugoSitePath: "https://example.com", Taxonomy: " "}, wantErr: "taxonomy is required"},
+		{name: "limit too high", req: CooccurrenceRequest{HugoSitePath: "https://example.com", Taxonomy: "tags", Limit: 201}, wantErr: "limit must be between 1 and 200"},
+		{name: "negative min count", req: CooccurrenceRequest{HugoSitePath: "https://example.com", Taxonomy: "tags", MinCount: -1}, wantErr: "min_count must be at least 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 20, tt.req.Limit)
+			assert.Equal(t, 1, tt.req.MinCount)
+		})
+	}
+}
+
+func TestTool_Execute_Term(t *testing.T) {
+	server := newServer(t)
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &CooccurrenceRequest{HugoSitePath: server.URL, Taxonomy: "tags", Term: "go"})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+
+	assert.Equal(t, "go", result.Get("term").String())
+	assert.Equal(t, int64(3), result.Get("metadata.pages_with_term").Int())
+	assert.Equal(t, int64(5), result.Get("metadata.pages_scanned").Int())
+	assert.Equal(t, int64(1), result.Get("metadata.excluded_count").Int())
+	assert.Equal(t, `["programming","concurrency","testing"]`, result.Get("cooccurring.#.term").Raw)
+	assert.Equal(t, int64(2), result.Get("cooccurring.0.count").Int())
+	assert.InDelta(t, 2.0/3.0, result.Get("cooccurring.0.ratio").Float(), 0.001)
+	assert.Equal(t, "categories", result.Get("cooccurring.0.taxonomy").String())
+
+	// Restricting taxonomies and requiring shared pages narrows the results
+	resp, err = tool.Execute(context.Background(), &CooccurrenceRequest{HugoSitePath: server.URL, Taxonomy: "tags", Term: "go", Taxonomies: []string{"tags"}, MinCount: 2})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["concurrency"]`, result.Get("cooccurring.#.term").Raw)
+
+	// Drafts are counted when requested
+	resp, err = tool.Execute(context.Background(), &CooccurrenceRequest{HugoSitePath: server.URL, Taxonomy: "tags", Term: "rust", PublishOptions: hugoindex.PublishOptions{IncludeDrafts: true}})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["go"]`, result.Get("cooccurring.#.term").Raw)
+}
+
+func TestTool_Execute_Pairs(t *testing.T) {
+	server := newServer(t)
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &CooccurrenceRequest{HugoSitePath: server.URL, Taxonomy: "tags", Limit: 2})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+
+	require.Equal(t, int64(2), result.Get("pairs.#").Int())
+	assert.Equal(t, `["concurrency","go"]`, result.Get("pairs.0.terms.#.term").Raw)
+	assert.Equal(t, int64(2), result.Get("pairs.0.count").Int())
+	assert.Equal(t, int64(4), result.Get("metadata.pages_with_taxonomy").Int())
+	assert.Equal(t, int64(6), result.Get("metadata.total_results").Int())
+}
+
+func TestTool_Execute_NotFound(t *testing.T) {
+	server := newServer(t)
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+
+	_, err = tool.Execute(context.Background(), &CooccurrenceRequest{HugoSitePath: server.URL, Taxonomy: "series"})
+	require.Error(t, err)
+	assert.Equal(t, toolerrors.ErrCodeNotFound, toolerrors.Code(err))
+
+	_, err = tool.Execute(context.Background(), &CooccurrenceRequest{HugoSitePath: server.URL, Taxonomy: "tags", Term: "python"})
+	require.Error(t, err)
+	assert.Equal(t, toolerrors.ErrCodeNotFound, toolerrors.Code(err))
+}
@@ -0,0 +1,234 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/enrich"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/tidwall/gjson"
+)
+
+// Crawl byte budgets
+const (
+	DefaultCrawlBytes = 2 * 1024 * 1024
+	MaxCrawlBytes     = 50 * 1024 * 1024
+)
+
+// maxChildSitemaps bounds how many sitemaps of a sitemap index are read
+const maxChildSitemaps = 50
+
+// sitemapDocument is a sitemap or a sitemap index
+type sitemapDocument struct {
+	URLs []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// crawlTarget is a sitemap URL to crawl
+type crawlTarget struct {
+	Loc     string `json:"loc"`
+	LastMod string `json:"lastmod,omitempty"`
+}
+
+// crawlState is the sitemap a crawl walks, kept for its cursors to resume
+type crawlState struct {
+	Targets    []crawlTarget `json:"targets"`
+	Source     string        `json:"source"`
+	Skipped    int           `json:"skipped"`
+	Duplicates int           `json:"duplicates"`
+}
+
+// discoverCrawl walks the site's sitemap from offset, fetching each page's
+// JSON output or else its HTML, until limit pages are read or maxBytes are
+// fetched. At least one page is read per call, so a crawl always makes
+// progress. The sitemap is read once per crawl and kept under hash for the
+// cursors that resume it.
+func (t *Tool) discoverCrawl(ctx context.Context, siteURL *url.URL, limit int, maxBytes int, hash string, offset int) ([]map[string]interface{}, map[string]interface{}, error) {
+	var state crawlState
+	resumed := false
+	if data, ok := t.cache.CursorState(hash); ok && offset > 0 {
+		resumed = json.Unmarshal(data, &state) == nil
+	}
+	if !resumed {
+		var err error
+		state.Targets, state.Source, state.Skipped, state.Duplicates, err = t.crawlTargets(ctx, siteURL)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	targets := state.Targets
+	if offset > len(targets) {
+		return nil, nil, fmt.Errorf("cursor is past the end of the sitemap (%d URLs)", len(targets))
+	}
+
+	results := []map[string]interface{}{}
+	bytesRead := 0
+	next := offset
+	for next < len(targets) && len(results) < limit {
+		if len(results) > 0 && bytesRead >= maxBytes {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		record, size := t.crawlPage(ctx, siteURL, targets[next])
+		results = append(results, record)
+		bytesRead += size
+		next++
+	}
+
+	metadata := map[string]interface{}{
+		"discovery_method":   "crawl",
+		"source":             state.Source,
+		"total_urls":         len(targets),
+		"offset":             offset,
+		"returned":           len(results),
+		"bytes_read":         bytesRead,
+		"max_bytes":          maxBytes,
+		"budget_exhausted":   bytesRead >= maxBytes && next < len(targets),
+		"complete":           next >= len(targets),
+		"skipped_external":   state.Skipped,
+		"duplicates_removed": state.Duplicates,
+		"resumed":            resumed,
+	}
+	if next < len(targets) {
+		metadata["next_cursor"] = cache.EncodeCursor(hash, next)
+		if !resumed {
+			if data, err := json.Marshal(state); err == nil {
+				t.cache.SetCursorState(hash, data)
+			}
+		}
+	}
+	return results, metadata, nil
+}
+
+// crawlTargets lists the URLs of the site's sitemap, reading the sitemaps
+// of a sitemap index in turn, once each and only on the site's host. It
+// returns where the sitemap was read from, and how many URLs were skipped
+// as external or duplicate.
+func (t *Tool) crawlTargets(ctx context.Context, siteURL *url.URL) ([]crawlTarget, string, int, int, error) {
+	body, source, _, err := t.loadSitemap(ctx, siteURL)
+	if err != nil {
+		return nil, "", 0, 0, err
+	}
+
+	var doc sitemapDocument
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, "", 0, 0, fmt.Errorf("invalid sitemap: %w", err)
+	}
+
+	var targets []crawlTarget
+	seen := make(map[string]bool)
+	skipped, duplicates := 0, 0
+	add := func(doc sitemapDocument) {
+		for _, u := range doc.URLs {
+			loc := strings.TrimSpace(u.Loc)
+			target, err := siteURL.Parse(loc)
+			if loc == "" || err != nil || !strings.EqualFold(target.Host, siteURL.Host) {
+				skipped++
+				continue
+			}
+			key := hugoindex.CanonicalURL(siteURL, loc)
+			if seen[key] {
+				duplicates++
+				continue
+			}
+			seen[key] = true
+			targets = append(targets, crawlTarget{Loc: target.String(), LastMod: strings.TrimSpace(u.LastMod)})
+		}
+	}
+	add(doc)
+
+	for i, child := range doc.Sitemaps {
+		if i >= maxChildSitemaps {
+			t.log.WarnContext(ctx, "Sitemap index lists too many sitemaps, ignoring the rest", "source", source, "sitemaps", len(doc.Sitemaps))
+			break
+		}
+		childURL, err := siteURL.Parse(strings.TrimSpace(child.Loc))
+		if err != nil || !strings.EqualFold(childURL.Host, siteURL.Host) {
+			continue
+		}
+		data, err := t.fetchURL(ctx, siteURL.String(), childURL, childURL.RequestURI(), nil)
+		if err != nil {
+			t.log.DebugContext(ctx, "Child sitemap not available", "url", childURL.String(), "error", err)
+			continue
+		}
+		var childDoc sitemapDocument
+		if err := xml.Unmarshal(data, &childDoc); err != nil {
+			t.log.DebugContext(ctx, "Invalid child sitemap", "url", childURL.String(), "error", err)
+			continue
+		}
+		add(childDoc)
+	}
+
+	return targets, source, skipped, duplicates, nil
+}
+
+// crawlPage returns a compact record of a page, read from its JSON output
+// when the site publishes one and from its HTML otherwise, and the bytes
+// read for it
+func (t *Tool) crawlPage(ctx context.Context, siteURL *url.URL, target crawlTarget) (map[string]interface{}, int) {
+	pageURL, _ := url.Parse(target.Loc)
+	record := map[string]interface{}{
+		"url":  target.Loc,
+		"path": pageURL.Path,
+	}
+	if target.LastMod != "" {
+		record["lastmod"] = target.LastMod
+	}
+
+	// Hugo publishes a page's JSON output beside its HTML
+	if strings.HasSuffix(pageURL.Path, "/") {
+		jsonURL := pageURL.ResolveReference(&url.URL{Path: "index.json"})
+		if data, err := t.fetchURL(ctx, siteURL.String(), jsonURL, jsonURL.Path, validatePageJSON); err == nil {
+			page := gjson.ParseBytes(data)
+			record["source"] = "json"
+			record["title"] = page.Get("title").String()
+			if date := page.Get("date").String(); date != "" {
+				record["date"] = date
+			}
+			if section := hugoindex.PageSection(page); section != "" {
+				record["section"] = section
+			}
+			if words, ok := enrich.WordCount(page); ok {
+				record["word_count"] = words
+			}
+			return record, len(data)
+		}
+	}
+
+	data, err := t.fetchURL(ctx, siteURL.String(), pageURL, pageURL.RequestURI(), nil)
+	if err != nil {
+		record["source"] = "html"
+		record["error"] = err.Error()
+		return record, 0
+	}
+	head := htmltext.ParseHead(string(data))
+	record["source"] = "html"
+	record["title"] = head.Title
+	if head.Lang != "" {
+		record["language"] = head.Lang
+	}
+	return record, len(data)
+}
+
+// validatePageJSON checks that data is the JSON output of a single page
+func validatePageJSON(data []byte) bool {
+	if !gjson.ValidBytes(data) {
+		return false
+	}
+	page := gjson.ParseBytes(data)
+	return page.IsObject() && (page.Get("title").Exists() || page.Get("date").Exists())
+}
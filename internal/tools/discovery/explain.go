@@ -0,0 +1,79 @@
+package discovery
+
+import (
+	"context"
+	"net/url"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/feed"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+)
+
+// Plan methods, naming where each step reads from
+const (
+	MethodEndpoint    = "endpoint"
+	MethodCursorState = "cursor_state"
+	MethodSiteIndex   = "site_index"
+	MethodFeed        = "feed"
+	MethodRobots      = "robots"
+	MethodSitemap     = "sitemap"
+	MethodHomePage    = "home_page"
+)
+
+// explain returns the plan of a discovery request: the resources its
+// discovery type would read in order and the cache keys consulted for
+// them, without fetching any of them
+func (t *Tool) explain(ctx context.Context, siteURL *url.URL, req *DiscoveryRequest, hash string, offset int) (*mcp_golang.ToolResponse, error) {
+	site := siteURL.String()
+	plan := tools.NewPlan(ctx, t.cache, site)
+	add := func(method, path string) *tools.PlanStep {
+		return plan.Add(method, path, siteURL.ResolveReference(&url.URL{Path: path}).String(), t.cache.BuildKey(site, path, nil))
+	}
+	bypass := cache.PolicyFrom(ctx).Bypass
+
+	// A resumed listing is served from its state while it is kept
+	if offset > 0 && (req.DiscoveryType == "pages" || req.DiscoveryType == "crawl") {
+		plan.Add(MethodCursorState, "", "", cache.CursorKey(hash)).Note = "when kept, the listing is resumed without reading the site again"
+	}
+
+	switch req.DiscoveryType {
+	case "overview":
+		// Overviews request every endpoint, consulting the cache only for
+		// those recently found missing
+		for _, endpoint := range overviewEndpoints {
+			step := add(MethodEndpoint, endpoint)
+			step.Skipped = !bypass && step.Cache.State == cache.StateNegative
+		}
+		add(MethodHomePage, "/").Note = "read for its generator, to name the platform the site is built with"
+	case "sections", "pages":
+		add(MethodSiteIndex, hugoindex.IndexPath(ctx))
+		for _, path := range feed.Paths {
+			add(MethodFeed, path)
+		}
+	case "sitemap", "crawl":
+		robotsURL := siteURL.ResolveReference(&url.URL{Path: "/robots.txt"}).String()
+		plan.Add(MethodRobots, "/robots.txt", robotsURL, "").Note = "sitemaps declared here are tried first, cached under their own URLs"
+		step := add(MethodSitemap, "/sitemap.xml")
+		if req.DiscoveryType == "crawl" {
+			step.Note = "each page listed is then read from its JSON output, else its HTML, within max_bytes"
+		}
+	}
+
+	plan.Filter("date_from", req.DateFrom)
+	plan.Filter("date_to", req.DateTo)
+
+	fields := map[string]interface{}{
+		"discovery_type": req.DiscoveryType,
+		"limit":          req.Limit,
+		"offset":         offset,
+		"site":           site,
+		"site_source":    sites.Source(ctx),
+	}
+	if req.MaxBytes > 0 {
+		fields["max_bytes"] = req.MaxBytes
+	}
+	return tools.ExplainResponse(plan, fields)
+}
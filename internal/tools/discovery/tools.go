@@ -1,17 +1,29 @@
 package discovery
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"path"
+	"sort"
 	"strings"
 	"time"
 
 	mcp_golang "github.com/metoro-io/mcp-golang"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/capabilities"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/feed"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/opensearch"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
 	"github.com/tidwall/gjson"
 )
 
@@ -23,26 +35,53 @@ type Tool struct {
 	log        *slog.Logger
 	name       string
 	description string
-	httpClient *http.Client
+	httpClient *httpclient.Client
 	cache      *cache.Cache
+	ttl        time.Duration
+	sites      *sites.Registry
 }
 
 // DiscoveryRequest represents the request parameters for site discovery.
 type DiscoveryRequest struct {
-	HugoSitePath string `json:"hugo_site_path" jsonschema:"title=Hugo Site Path"`
-	DiscoveryType string `json:"discovery_type,omitempty" jsonschema:"enum=overview,enum=sections,enum=pages,enum=sitemap,title=Discovery Type"`
+	HugoSitePath string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	DiscoveryType string `json:"discovery_type,omitempty" jsonschema:"enum=overview,enum=sections,enum=pages,enum=sitemap,enum=crawl,title=Discovery Type"`
 	Limit        int    `json:"limit,omitempty" jsonschema:"title=Result Limit,minimum=1,maximum=200"`
+	Cursor       string `json:"cursor,omitempty" jsonschema:"title=Cursor from a previous pages or crawl response's next_cursor"`
+	MaxBytes     int    `json:"max_bytes,omitempty" jsonschema:"title=Crawl byte budget per call (default: 2 MiB),minimum=1,maximum=52428800"`
+
+	tools.ExplainOptions
+	hugoindex.DateRange
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// overviewEndpoints are the endpoints an overview checks, in order
+var overviewEndpoints = []string{
+	"/index.json",
+	"/api/index.json",
+	"/feed.json",
+	opensearch.Path,
+	"/sitemap.xml",
+	"/robots.txt",
+	capabilities.WPJSONPath,
 }
 
+// DefaultCacheTTL is how long site structure is served from the cache;
+// it changes less often than content
+const DefaultCacheTTL = 10 * time.Minute
+
 // New creates a new Tool.
 func New(opts ...ToolOption) (*Tool, error) {
 	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_discover_site"),
 		name:        "hugo_reader_discover_site",
-		description: "Discover available content and structure in Hugo sites. Types: 'overview' (site structure), 'sections' (content sections), 'pages' (all pages), 'sitemap' (from sitemap.xml). Use this to explore what content is available.",
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		cache: cache.New(cache.WithTTL(10 * time.Minute)), // Longer TTL for discovery
+		description: "Discover available content and structure in Hugo sites. Types: 'overview' (site structure), 'sections' (content sections), 'pages' (all pages), 'sitemap' (from sitemap.xml), 'crawl' (fetch each sitemap page in resumable batches). Use this to explore what content is available.",
+		httpClient: httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache: cache.New(cache.WithTTL(DefaultCacheTTL)),
+		ttl:   DefaultCacheTTL,
+		sites: sites.New(),
 	}
 	for _, opt := range opts {
 		if err := opt(tool); err != nil {
@@ -57,9 +96,6 @@ func New(opts ...ToolOption) (*Tool, error) {
 func WithLogger(logger *slog.Logger) ToolOption {
 	return func(t *Tool) error {
 		t.log = logger.With("tool", t.name)
-		if t.cache != nil {
-			t.cache = cache.New(cache.WithLogger(logger), cache.WithTTL(10*time.Minute))
-		}
 		return nil
 	}
 }
@@ -72,10 +108,35 @@ func WithCache(c *cache.Cache) ToolOption {
 	}
 }
 
+// WithTTL sets how long the Tool serves cached responses. Zero uses the
+// cache's default TTL.
+func WithTTL(ttl time.Duration) ToolOption {
+	return func(t *Tool) error {
+		t.ttl = ttl
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
 // Validate implements tools.Request
 func (r *DiscoveryRequest) Validate() error {
-	if r.HugoSitePath == "" {
-		return fmt.Errorf("hugo_site_path is required")
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
 	}
 	
 	// Set default discovery type if not specified
@@ -84,9 +145,9 @@ func (r *DiscoveryRequest) Validate() error {
 	}
 	
 	// Validate discovery type
-	validTypes := map[string]bool{"overview": true, "sections": true, "pages": true, "sitemap": true}
+	validTypes := map[string]bool{"overview": true, "sections": true, "pages": true, "sitemap": true, "crawl": true}
 	if !validTypes[r.DiscoveryType] {
-		return fmt.Errorf("invalid discovery_type: %s (must be: overview, sections, pages, or sitemap)", r.DiscoveryType)
+		return fmt.Errorf("invalid discovery_type: %s (must be: overview, sections, pages, sitemap, or crawl)", r.DiscoveryType)
 	}
 	
 	// Set default limit if not specified or validate
@@ -95,31 +156,73 @@ func (r *DiscoveryRequest) Validate() error {
 	} else if r.Limit < 1 || r.Limit > 200 {
 		return fmt.Errorf("limit must be between 1 and 200")
 	}
+
+	// Only page listings and crawls can be resumed, and byte budgets only
+	// bound crawls
+	if r.Cursor != "" && r.DiscoveryType != "pages" && r.DiscoveryType != "crawl" {
+		return fmt.Errorf("cursor is only supported for the pages and crawl discovery types")
+	}
+	if r.DiscoveryType != "crawl" && r.MaxBytes != 0 {
+		return fmt.Errorf("max_bytes is only supported for the crawl discovery type")
+	}
+	if r.DiscoveryType == "crawl" && r.MaxBytes == 0 {
+		r.MaxBytes = DefaultCrawlBytes
+	} else if r.MaxBytes < 0 || r.MaxBytes > MaxCrawlBytes {
+		return fmt.Errorf("max_bytes must be between 1 and %d", MaxCrawlBytes)
+	}
+	if _, err := cache.DecodeCursor(r.Cursor, r.requestHash()); err != nil {
+		return err
+	}
+
+	// Only page listings carry dates to filter on
+	if r.DateRange.IsSet() && r.DiscoveryType != "pages" {
+		return fmt.Errorf("date_from and date_to are only supported for the pages discovery type")
+	}
+	if err := r.DateRange.Validate(); err != nil {
+		return err
+	}
 	
-	return nil
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
 }
 
-// Execute discovers site content and structure.
-func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
-	// Check if logger is initialized
-	if t.log == nil {
-		t.log = slog.Default().With("tool", t.name)
-	}
+// requestHash identifies the results of the request, ignoring the limit and
+// byte budget that only bound how many are returned at once
+func (r *DiscoveryRequest) requestHash() string {
+	return cache.RequestHash("discovery", r.DiscoveryType, strings.TrimRight(r.HugoSitePath, "/"), r.DateFrom, r.DateTo)
+}
 
+// Execute discovers site content and structure.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
 	discoveryRequest, ok := req.(*DiscoveryRequest)
 	if !ok {
-		return nil, fmt.Errorf("invalid request type: %T", req)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := discoveryRequest.SiteOptions.Apply(ctx, t.sites, &discoveryRequest.HugoSitePath, &discoveryRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
 	}
 
 	if err := discoveryRequest.Validate(); err != nil {
-		return nil, err
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
 	}
 
+	ctx, cancel := discoveryRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = discoveryRequest.CacheOptions.Apply(ctx, t.ttl)
+
 	// Parse and validate the Hugo site URL
 	siteURL, err := url.Parse(discoveryRequest.HugoSitePath)
 	if err != nil {
-		t.log.Error("Invalid Hugo site URL", "url", discoveryRequest.HugoSitePath, "error", err)
-		return nil, fmt.Errorf("invalid Hugo site URL: %w", err)
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", discoveryRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
 	}
 
 	// Ensure URL has scheme
@@ -127,27 +230,43 @@ func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
 		siteURL.Scheme = "https"
 	}
 
+	// Send credentials, if any, only to the site itself
+	ctx = discoveryRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	// Validate has checked the cursor
+	hash := discoveryRequest.requestHash()
+	offset, _ := cache.DecodeCursor(discoveryRequest.Cursor, hash)
+
+	if discoveryRequest.Explain {
+		return t.explain(ctx, siteURL, discoveryRequest, hash, offset)
+	}
+
 	var results []map[string]interface{}
 	var metadata map[string]interface{}
 
 	switch discoveryRequest.DiscoveryType {
 	case "overview":
-		results, metadata, err = t.discoverOverview(siteURL, discoveryRequest.Limit)
+		results, metadata, err = t.discoverOverview(ctx, siteURL, discoveryRequest.Limit)
 	case "sections":
-		results, metadata, err = t.discoverSections(siteURL, discoveryRequest.Limit)
+		results, metadata, err = t.discoverSections(ctx, siteURL, discoveryRequest.Limit)
 	case "pages":
-		results, metadata, err = t.discoverPages(siteURL, discoveryRequest.Limit)
+		results, metadata, err = t.discoverPages(ctx, siteURL, discoveryRequest.Limit, discoveryRequest.DateRange, hash, offset)
 	case "sitemap":
-		results, metadata, err = t.discoverSitemap(siteURL, discoveryRequest.Limit)
+		results, metadata, err = t.discoverSitemap(ctx, siteURL, discoveryRequest.Limit)
+	case "crawl":
+		results, metadata, err = t.discoverCrawl(ctx, siteURL, discoveryRequest.Limit, discoveryRequest.MaxBytes, hash, offset)
 	default:
-		return nil, fmt.Errorf("unsupported discovery type: %s", discoveryRequest.DiscoveryType)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "unsupported discovery type: %s", discoveryRequest.DiscoveryType)
 	}
 
 	if err != nil {
-		t.log.Error("Discovery failed", "type", discoveryRequest.DiscoveryType, "error", err)
+		t.log.ErrorContext(ctx, "Discovery failed", "type", discoveryRequest.DiscoveryType, "error", err)
 		return nil, fmt.Errorf("discovery failed: %w", err)
 	}
 
+	metadata["site_source"] = sites.Source(ctx)
+	metadata["redirects"] = httpclient.Redirects(ctx)
+
 	// Format response
 	responseData := fmt.Sprintf(`{
   "success": true,
@@ -157,31 +276,38 @@ func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
   "errors": []
 }`, discoveryRequest.DiscoveryType, formatResults(results), formatMetadata(metadata))
 
-	t.log.Info("Discovery completed", "type", discoveryRequest.DiscoveryType, "results", len(results), "site", discoveryRequest.HugoSitePath)
-	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(responseData)), nil
+	t.log.InfoContext(ctx, "Discovery completed", "type", discoveryRequest.DiscoveryType, "results", len(results), "site", discoveryRequest.HugoSitePath)
+	return tools.TextResponse([]byte(responseData)), nil
 }
 
 // discoverOverview provides a general overview of site structure
-func (t *Tool) discoverOverview(siteURL *url.URL, limit int) ([]map[string]interface{}, map[string]interface{}, error) {
+func (t *Tool) discoverOverview(ctx context.Context, siteURL *url.URL, limit int) ([]map[string]interface{}, map[string]interface{}, error) {
 	results := []map[string]interface{}{}
 	
-	// Try multiple discovery endpoints
-	endpoints := []string{
-		"/index.json",
-		"/api/index.json",
-		"/sitemap.xml",
-		"/robots.txt",
-	}
-	
 	foundEndpoints := []string{}
 	
-	for _, endpoint := range endpoints {
+	for _, endpoint := range overviewEndpoints {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
 		endpointURL := siteURL.ResolveReference(&url.URL{Path: endpoint})
-		resp, err := t.httpClient.Get(endpointURL.String())
+
+		// Skip endpoints any tool recently found missing
+		cacheKey := cache.ScopedKey(ctx, t.cache.BuildKey(siteURL.String(), endpoint, nil))
+		if _, missing := t.cache.GetNegative(cacheKey); missing && !cache.PolicyFrom(ctx).Bypass {
+			continue
+		}
+
+		resp, err := t.httpClient.Get(ctx, endpointURL.String())
 		if err != nil {
 			continue
 		}
 		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+			t.cache.SetNegative(cacheKey, resp.StatusCode)
+		}
 		
 		if resp.StatusCode == http.StatusOK {
 			foundEndpoints = append(foundEndpoints, endpoint)
@@ -202,6 +328,13 @@ func (t *Tool) discoverOverview(siteURL *url.URL, limit int) ([]map[string]inter
 					if pages := parsed.Get("pages"); pages.Exists() && pages.IsArray() {
 						result["pages_count"] = len(pages.Array())
 					}
+					if siteFeed, err := feed.Parse(body); err == nil {
+						result["type"] = siteFeed.Format
+						result["pages_count"] = len(siteFeed.Items)
+						if siteFeed.Title != "" {
+							result["title"] = siteFeed.Title
+						}
+					}
 					if sections := parsed.Get("sections"); sections.Exists() {
 						result["sections"] = sections.Value()
 					}
@@ -209,6 +342,24 @@ func (t *Tool) discoverOverview(siteURL *url.URL, limit int) ([]map[string]inter
 						result["taxonomies"] = taxonomies.Value()
 					}
 					
+					results = append(results, result)
+				}
+			} else if endpoint == opensearch.Path {
+				body, err := io.ReadAll(resp.Body)
+				if err != nil {
+					continue
+				}
+				if description, err := opensearch.Parse(body); err == nil {
+					result := map[string]interface{}{
+						"endpoint": endpoint,
+						"type": "opensearch",
+						"url": endpointURL.String(),
+						"short_name": description.ShortName,
+					}
+					if template, err := description.SearchURL(); err == nil {
+						result["search_template"] = template.Template
+						result["search_type"] = template.Type
+					}
 					results = append(results, result)
 				}
 			} else {
@@ -225,72 +376,110 @@ func (t *Tool) discoverOverview(siteURL *url.URL, limit int) ([]map[string]inter
 	metadata := map[string]interface{}{
 		"discovery_method": "overview",
 		"endpoints_found": len(foundEndpoints),
-		"endpoints_checked": len(endpoints),
+		"endpoints_checked": len(overviewEndpoints),
 		"available_endpoints": foundEndpoints,
 	}
+
+	// Name the platform, so sites not built with Hugo are recognized as such
+	// instead of merely lacking its endpoints
+	signals := capabilities.PlatformSignals{}
+	hasIndex := false
+	for _, endpoint := range foundEndpoints {
+		switch endpoint {
+		case "/index.json", "/api/index.json":
+			hasIndex = true
+		case capabilities.WPJSONPath:
+			signals.WPJSON = true
+		}
+	}
+	if home, err := t.fetch(ctx, siteURL, "/", func(data []byte) bool { return htmltext.LooksLikeHTML(string(data)) }); err == nil {
+		signals.HomePage = string(home)
+		signals.MetaGenerator = htmltext.ParseHead(signals.HomePage).Generator
+	}
+	platform := capabilities.DetectPlatform(signals)
+	metadata["platform"] = platform
+	if notice := platform.Notice(hasIndex); notice != "" {
+		metadata["notice"] = notice
+	}
 	
 	return results, metadata, nil
 }
 
-// discoverSections finds content sections
-func (t *Tool) discoverSections(siteURL *url.URL, limit int) ([]map[string]interface{}, map[string]interface{}, error) {
-	// Try to get sections from index
-	indexURL := siteURL.ResolveReference(&url.URL{Path: "/index.json"})
-	resp, err := t.httpClient.Get(indexURL.String())
+// fetch returns a site resource through the cache, revalidating expired
+// entries. Resources share the keys the other tools use.
+func (t *Tool) fetch(ctx context.Context, siteURL *url.URL, path string, valid func([]byte) bool) ([]byte, error) {
+	return t.fetchURL(ctx, siteURL.String(), siteURL.ResolveReference(&url.URL{Path: path}), path, valid)
+}
+
+// fetchURL is fetch for a resource at any URL, cached under the site and path given
+func (t *Tool) fetchURL(ctx context.Context, site string, resourceURL *url.URL, path string, valid func([]byte) bool) ([]byte, error) {
+	cacheKey := t.cache.BuildKey(site, path, nil)
+
+	result, err := t.cache.Fetch(ctx, t.httpClient, cacheKey, resourceURL.String(), valid)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to fetch index: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return nil, nil, fmt.Errorf("index not available (status: %d)", resp.StatusCode)
+	t.log.DebugContext(ctx, "Fetched site resource", "url", resourceURL.String(), "cached", result.Cached, "revalidated", result.Revalidated)
+	return result.Data, nil
+}
+
+// loadPages returns the site index or, for sites without one, the pages
+// listed in the site's feed
+func (t *Tool) loadPages(ctx context.Context, siteURL *url.URL) (*hugoindex.SiteIndex, error) {
+	index, err := hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err == nil {
+		t.log.DebugContext(ctx, "Loaded site index", "url", index.URL(), "format", index.Format(), "cached", index.Cached(), "streamed", index.Streamed())
+		return index, nil
 	}
-	
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read index: %w", err)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
 	}
-	
-	if !gjson.ValidBytes(body) {
-		return nil, nil, fmt.Errorf("invalid JSON in index")
+
+	siteFeed, feedErr := feed.Load(ctx, t.cache, t.httpClient, siteURL)
+	if feedErr != nil {
+		return nil, fmt.Errorf("index not available: %w", err)
 	}
-	
-	parsed := gjson.ParseBytes(body)
+	t.log.DebugContext(ctx, "Loaded site feed", "url", siteFeed.URL, "format", siteFeed.Format, "cached", siteFeed.Cached)
+	return hugoindex.FromFeed(siteFeed), nil
+}
+
+// sourceName returns the file name of the endpoint an index was read from
+func sourceName(index *hugoindex.SiteIndex) string {
+	if u, err := url.Parse(index.URL()); err == nil {
+		return path.Base(u.Path)
+	}
+	return index.URL()
+}
+
+// discoverSections finds content sections
+func (t *Tool) discoverSections(ctx context.Context, siteURL *url.URL, limit int) ([]map[string]interface{}, map[string]interface{}, error) {
 	results := []map[string]interface{}{}
-	sections := make(map[string]int)
-	
-	// Extract sections from pages
-	if pages := parsed.Get("pages"); pages.Exists() && pages.IsArray() {
-		pages.ForEach(func(key, page gjson.Result) bool {
-			if len(results) >= limit {
-				return false
-			}
-			
-			if section := page.Get("section"); section.Exists() {
-				sectionName := section.String()
-				sections[sectionName]++
-			}
-			
-			if url := page.Get("url"); url.Exists() {
-				urlStr := url.String()
-				parts := strings.Split(strings.Trim(urlStr, "/"), "/")
-				if len(parts) > 0 {
-					sections[parts[0]]++
-				}
-			}
-			
-			return true
-		})
+
+	index, err := t.loadPages(ctx, siteURL)
+	if err != nil {
+		return nil, nil, err
 	}
-	
+
+	// Extract sections from the pages in the index
+	sections, err := index.Sections(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, 0, len(sections))
+	for section := range sections {
+		names = append(names, section)
+	}
+	sort.Strings(names)
+
 	// Convert sections map to results
-	for section, count := range sections {
+	for _, section := range names {
 		if len(results) >= limit {
 			break
 		}
 		results = append(results, map[string]interface{}{
 			"section": section,
-			"count": count,
+			"count": sections[section],
 			"example_path": fmt.Sprintf("/%s/", section),
 		})
 	}
@@ -298,98 +487,141 @@ func (t *Tool) discoverSections(siteURL *url.URL, limit int) ([]map[string]inter
 	metadata := map[string]interface{}{
 		"discovery_method": "sections",
 		"total_sections": len(sections),
-		"source": "index.json",
+		"source": sourceName(index),
+		"source_format": index.Format(),
 	}
 	
 	return results, metadata, nil
 }
 
-// discoverPages finds available pages
-func (t *Tool) discoverPages(siteURL *url.URL, limit int) ([]map[string]interface{}, map[string]interface{}, error) {
-	// Try to get pages from index
-	indexURL := siteURL.ResolveReference(&url.URL{Path: "/index.json"})
-	resp, err := t.httpClient.Get(indexURL.String())
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to fetch index: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return nil, nil, fmt.Errorf("index not available (status: %d)", resp.StatusCode)
-	}
-	
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read index: %w", err)
+// pagesState is a page listing, kept for its cursors to resume
+type pagesState struct {
+	Pages      []map[string]interface{} `json:"pages"`
+	Source     string                   `json:"source"`
+	Format     string                   `json:"format"`
+	Duplicates int                      `json:"duplicates"`
+}
+
+// discoverPages lists available pages from offset. The full listing is kept
+// under hash while pages remain, so the cursors that resume it don't read
+// the index again.
+func (t *Tool) discoverPages(ctx context.Context, siteURL *url.URL, limit int, dates hugoindex.DateRange, hash string, offset int) ([]map[string]interface{}, map[string]interface{}, error) {
+	var state pagesState
+	resumed := false
+	if data, ok := t.cache.CursorState(hash); ok && offset > 0 {
+		resumed = json.Unmarshal(data, &state) == nil
+	}
+	if !resumed {
+		var err error
+		state, err = t.listPages(ctx, siteURL, dates)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
-	
-	if !gjson.ValidBytes(body) {
-		return nil, nil, fmt.Errorf("invalid JSON in index")
+	if offset > len(state.Pages) {
+		return nil, nil, fmt.Errorf("cursor is past the end of the page listing (%d pages)", len(state.Pages))
 	}
-	
-	parsed := gjson.ParseBytes(body)
-	results := []map[string]interface{}{}
-	
-	// Extract pages
-	if pages := parsed.Get("pages"); pages.Exists() && pages.IsArray() {
-		pages.ForEach(func(key, page gjson.Result) bool {
-			if len(results) >= limit {
-				return false
-			}
-			
-			result := map[string]interface{}{}
-			
-			if title := page.Get("title"); title.Exists() {
-				result["title"] = title.String()
-			}
-			if url := page.Get("url"); url.Exists() {
-				result["url"] = url.String()
-				result["path"] = url.String()
-			}
-			if date := page.Get("date"); date.Exists() {
-				result["date"] = date.String()
-			}
-			if section := page.Get("section"); section.Exists() {
-				result["section"] = section.String()
-			}
-			
-			results = append(results, result)
-			return true
-		})
+
+	end := offset + limit
+	if end > len(state.Pages) {
+		end = len(state.Pages)
 	}
+	results := state.Pages[offset:end]
 	
 	metadata := map[string]interface{}{
 		"discovery_method": "pages",
 		"total_found": len(results),
-		"source": "index.json",
-		"limited": len(results) >= limit,
+		"total_pages": len(state.Pages),
+		"offset": offset,
+		"source": state.Source,
+		"source_format": state.Format,
+		"limited": end < len(state.Pages),
+		"duplicates_removed": state.Duplicates,
+		"resumed": resumed,
+	}
+	if end < len(state.Pages) {
+		metadata["next_cursor"] = cache.EncodeCursor(hash, end)
+		if !resumed {
+			if data, err := json.Marshal(state); err == nil {
+				t.cache.SetCursorState(hash, data)
+			}
+		}
+	}
+	if dates.DateFrom != "" {
+		metadata["date_from"] = dates.DateFrom
+	}
+	if dates.DateTo != "" {
+		metadata["date_to"] = dates.DateTo
 	}
 	
 	return results, metadata, nil
 }
 
-// discoverSitemap extracts URLs from sitemap.xml
-func (t *Tool) discoverSitemap(siteURL *url.URL, limit int) ([]map[string]interface{}, map[string]interface{}, error) {
-	sitemapURL := siteURL.ResolveReference(&url.URL{Path: "/sitemap.xml"})
-	resp, err := t.httpClient.Get(sitemapURL.String())
+// listPages lists the pages of the index dated within dates, listing
+// aliases of one URL once
+func (t *Tool) listPages(ctx context.Context, siteURL *url.URL, dates hugoindex.DateRange) (pagesState, error) {
+	index, err := t.loadPages(ctx, siteURL)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to fetch sitemap: %w", err)
+		return pagesState{}, err
 	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return nil, nil, fmt.Errorf("sitemap not available (status: %d)", resp.StatusCode)
-	}
-	
-	body, err := io.ReadAll(resp.Body)
+
+	state := pagesState{
+		Pages:  []map[string]interface{}{},
+		Source: sourceName(index),
+		Format: index.Format(),
+	}
+	seen := make(map[string]bool)
+	err = index.Pages(ctx, func(page gjson.Result) bool {
+		if !dates.Contains(page) {
+			return true
+		}
+		if key := hugoindex.CanonicalURL(siteURL, hugoindex.PageURL(page)); key != "" {
+			if seen[key] {
+				state.Duplicates++
+				return true
+			}
+			seen[key] = true
+		}
+		
+		result := map[string]interface{}{}
+		
+		if title := page.Get("title"); title.Exists() {
+			result["title"] = title.String()
+		}
+		if url := page.Get("url"); url.Exists() {
+			result["url"] = url.String()
+			result["path"] = url.String()
+		}
+		if date := page.Get("date"); date.Exists() {
+			result["date"] = date.String()
+		}
+		if lastmod := page.Get("lastmod"); lastmod.Exists() {
+			result["lastmod"] = lastmod.String()
+		}
+		if section := page.Get("section"); section.Exists() {
+			result["section"] = section.String()
+		}
+		
+		state.Pages = append(state.Pages, result)
+		return true
+	})
+	return state, err
+}
+
+// discoverSitemap extracts URLs from the first sitemap the site's
+// robots.txt declares that can be read, else from sitemap.xml
+func (t *Tool) discoverSitemap(ctx context.Context, siteURL *url.URL, limit int) ([]map[string]interface{}, map[string]interface{}, error) {
+	body, source, declared, err := t.loadSitemap(ctx, siteURL)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read sitemap: %w", err)
+		return nil, nil, err
 	}
 	
 	bodyStr := string(body)
 	results := []map[string]interface{}{}
 	
-	// Simple XML parsing for URLs
+	// Simple XML parsing for URLs, listing aliases of one URL once
+	seen := make(map[string]bool)
+	duplicates := 0
 	lines := strings.Split(bodyStr, "\n")
 	for _, line := range lines {
 		if len(results) >= limit {
@@ -403,11 +635,17 @@ func (t *Tool) discoverSitemap(siteURL *url.URL, limit int) ([]map[string]interf
 			if start < end {
 				urlStr := line[start:end]
 				if strings.HasPrefix(urlStr, "http") {
+					key := hugoindex.CanonicalURL(nil, urlStr)
+					if seen[key] {
+						duplicates++
+						continue
+					}
+					seen[key] = true
 					path := strings.TrimPrefix(urlStr, siteURL.String())
 					results = append(results, map[string]interface{}{
 						"url": urlStr,
 						"path": path,
-						"source": "sitemap.xml",
+						"source": source,
 					})
 				}
 			}
@@ -417,13 +655,45 @@ func (t *Tool) discoverSitemap(siteURL *url.URL, limit int) ([]map[string]interf
 	metadata := map[string]interface{}{
 		"discovery_method": "sitemap",
 		"total_found": len(results),
-		"source": "sitemap.xml",
+		"source": source,
 		"limited": len(results) >= limit,
+		"duplicates_removed": duplicates,
+	}
+	if len(declared) > 0 {
+		metadata["declared_sitemaps"] = declared
 	}
 	
 	return results, metadata, nil
 }
 
+// loadSitemap returns the first sitemap the site's robots.txt declares that
+// can be read, else sitemap.xml, with where it was read from and the
+// sitemaps declared
+func (t *Tool) loadSitemap(ctx context.Context, siteURL *url.URL) ([]byte, string, []string, error) {
+	declared, err := t.httpClient.Sitemaps(ctx, siteURL)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, sitemap := range declared {
+		sitemapURL, err := url.Parse(sitemap)
+		if err != nil || (sitemapURL.Scheme != "http" && sitemapURL.Scheme != "https") {
+			continue
+		}
+		body, err := t.fetchURL(ctx, sitemapURL.Scheme+"://"+sitemapURL.Host, sitemapURL, sitemapURL.RequestURI(), nil)
+		if err == nil {
+			return body, sitemap, declared, nil
+		}
+		t.log.DebugContext(ctx, "Declared sitemap not available", "url", sitemap, "error", err)
+	}
+
+	body, err := t.fetch(ctx, siteURL, "/sitemap.xml", nil)
+	if err != nil {
+		return nil, "", declared, fmt.Errorf("sitemap not available: %w", err)
+	}
+	return body, "sitemap.xml", declared, nil
+}
+
 // Formatting functions
 func formatResults(results []map[string]interface{}) string {
 	if len(results) == 0 {
@@ -441,10 +711,12 @@ func formatResults(results []map[string]interface{}) string {
 func formatResult(result map[string]interface{}) string {
 	var parts []string
 	
-	for key, value := range result {
+	for _, key := range sortedKeys(result) {
+		value := result[key]
 		switch v := value.(type) {
 		case string:
-			parts = append(parts, fmt.Sprintf(`"%s": "%s"`, key, strings.ReplaceAll(v, `"`, `\"`)))
+			quoted, _ := json.Marshal(v)
+			parts = append(parts, fmt.Sprintf(`"%s": %s`, key, quoted))
 		case int:
 			parts = append(parts, fmt.Sprintf(`"%s": %d`, key, v))
 		case []interface{}:
@@ -464,7 +736,8 @@ func formatResult(result map[string]interface{}) string {
 func formatMetadata(metadata map[string]interface{}) string {
 	var parts []string
 	
-	for key, value := range metadata {
+	for _, key := range sortedKeys(metadata) {
+		value := metadata[key]
 		switch v := value.(type) {
 		case string:
 			parts = append(parts, fmt.Sprintf(`"%s": "%s"`, key, v))
@@ -479,13 +752,24 @@ func formatMetadata(metadata map[string]interface{}) string {
 		case bool:
 			parts = append(parts, fmt.Sprintf(`"%s": %t`, key, v))
 		default:
-			parts = append(parts, fmt.Sprintf(`"%s": %v`, key, v))
+			encoded, _ := json.Marshal(v)
+			parts = append(parts, fmt.Sprintf(`"%s": %s`, key, encoded))
 		}
 	}
 	
 	return "{\n    " + strings.Join(parts, ",\n    ") + "\n  }"
 }
 
+// sortedKeys returns the map keys in sorted order so output is deterministic
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // Name returns the name of the tool.
 func (t *Tool) Name() string {
 	return t.name
@@ -496,6 +780,11 @@ func (t *Tool) Description() string {
 	return t.description
 }
 
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*DiscoveryRequest](registry, t, "Site exploration", tools.ReadOnly)
+}
+
 // SetLogger sets the logger for the Tool.
 func (t *Tool) SetLogger(logger *slog.Logger) {
 	if logger == nil {
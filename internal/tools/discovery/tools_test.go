@@ -1,10 +1,22 @@
 package discovery
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/capabilities"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 )
 
 func TestNew(t *testing.T) {
@@ -16,6 +28,21 @@ func TestNew(t *testing.T) {
 	assert.NotNil(t, tool.httpClient)
 }
 
+func TestWithCache_SharedWithLogger(t *testing.T) {
+	shared := cache.New()
+	logger := slog.New(slog.DiscardHandler)
+
+	// The shared cache is kept whichever order the options are given in
+	for _, opts := range [][]ToolOption{
+		{WithLogger(logger), WithCache(shared)},
+		{WithCache(shared), WithLogger(logger)},
+	} {
+		tool, err := New(opts...)
+		require.NoError(t, err)
+		assert.Same(t, shared, tool.cache)
+	}
+}
+
 func TestDiscoveryRequest_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -79,6 +106,33 @@ func TestDiscoveryRequest_Validate(t *testing.T) {
 			},
 			wantErr: false, // Should set default
 		},
+		{
+			name: "valid date range for pages",
+			req: &DiscoveryRequest{
+				HugoSitePath: "https://example.com",
+				DiscoveryType: "pages",
+				DateRange: hugoindex.DateRange{DateFrom: "2024-01-01", DateTo: "January 31, 2024"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "date range for sections",
+			req: &DiscoveryRequest{
+				HugoSitePath: "https://example.com",
+				DiscoveryType: "sections",
+				DateRange: hugoindex.DateRange{DateFrom: "2024-01-01"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid date",
+			req: &DiscoveryRequest{
+				HugoSitePath: "https://example.com",
+				DiscoveryType: "pages",
+				DateRange: hugoindex.DateRange{DateTo: "tomorrow"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -100,6 +154,172 @@ func TestDiscoveryRequest_Validate(t *testing.T) {
 	}
 }
 
+func TestTool_DiscoverPages_DateRange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"title": "Old", "url": "/posts/old/", "date": "2023-05-01"},
+			{"title": "New", "url": "/posts/new/", "date": "2024-02-10T08:00:00Z"},
+			{"title": "Updated", "url": "/about/", "lastmod": "Feb 20, 2024"},
+			{"title": "Undated", "url": "/contact/"}
+		]`))
+	}))
+	defer server.Close()
+
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	dates := hugoindex.DateRange{DateFrom: "2024-01-01", DateTo: "2024-02-29"}
+	require.NoError(t, dates.Validate())
+
+	results, _, err := tool.discoverPages(context.Background(), siteURL, 50, dates, "", 0)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "New", results[0]["title"])
+	assert.Equal(t, "Feb 20, 2024", results[1]["lastmod"])
+
+	results, _, err = tool.discoverPages(context.Background(), siteURL, 50, hugoindex.DateRange{}, "", 0)
+	require.NoError(t, err)
+	assert.Len(t, results, 4)
+}
+
+func TestTool_DiscoverPages_DedupesAliases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			w.Write([]byte(`[
+				{"title": "Foo", "url": "/posts/foo/"},
+				{"title": "Foo", "url": "/posts/foo/index.html"},
+				{"title": "Foo", "url": "http://` + strings.ToUpper(r.Host) + `/posts/foo"},
+				{"title": "Bar", "url": "/posts/bar/"}
+			]`))
+		case "/sitemap.xml":
+			w.Write([]byte("<urlset>\n<url><loc>http://" + r.Host + "/about/</loc></url>\n<url><loc>http://" + r.Host + "/about/index.html</loc></url>\n</urlset>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	results, metadata, err := tool.discoverPages(context.Background(), siteURL, 50, hugoindex.DateRange{}, "", 0)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "Bar", results[1]["title"])
+	assert.Equal(t, 2, metadata["duplicates_removed"])
+
+	sections, _, err := tool.discoverSections(context.Background(), siteURL, 50)
+	require.NoError(t, err)
+	require.Len(t, sections, 1)
+	assert.Equal(t, 2, sections[0]["count"])
+
+	results, metadata, err = tool.discoverSitemap(context.Background(), siteURL, 50)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, 1, metadata["duplicates_removed"])
+}
+
+func TestTool_DiscoverPages_JSONFeed(t *testing.T) {
+	const jsonFeed = `{
+		"version": "https://jsonfeed.org/version/1.1",
+		"title": "Example",
+		"items": [
+			{"id": "1", "url": "%s/posts/hello/", "title": "Hello", "date_published": "2024-02-10T08:00:00Z", "tags": ["go"]},
+			{"id": "2", "url": "%s/docs/setup/", "title": "Setup"}
+		]
+	}`
+	tests := []struct {
+		name       string
+		path       string
+		wantSource string
+	}{
+		{name: "feed.json only", path: "/feed.json", wantSource: "feed.json"},
+		{name: "JSON Feed at index.json", path: "/index.json", wantSource: "index.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path {
+					http.NotFound(w, r)
+					return
+				}
+				host := "http://" + r.Host
+				w.Write([]byte(fmt.Sprintf(jsonFeed, host, host)))
+			}))
+			defer server.Close()
+
+			tool, err := New(WithLogger(slog.Default()))
+			require.NoError(t, err)
+			siteURL, err := url.Parse(server.URL)
+			require.NoError(t, err)
+
+			results, metadata, err := tool.discoverPages(context.Background(), siteURL, 50, hugoindex.DateRange{}, "", 0)
+			require.NoError(t, err)
+			require.Len(t, results, 2)
+			assert.Equal(t, "Hello", results[0]["title"])
+			assert.Equal(t, "/posts/hello/", results[0]["path"])
+			assert.Equal(t, "posts", results[0]["section"])
+			assert.Equal(t, tt.wantSource, metadata["source"])
+			assert.Equal(t, hugoindex.FormatJSONFeed, metadata["source_format"])
+
+			sections, _, err := tool.discoverSections(context.Background(), siteURL, 50)
+			require.NoError(t, err)
+			assert.Len(t, sections, 2)
+		})
+	}
+}
+
+func TestTool_DiscoverSitemap(t *testing.T) {
+	declared := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			if declared {
+				w.Write([]byte("User-agent: *\nSitemap: http://" + r.Host + "/missing.xml\nSitemap: http://" + r.Host + "/en/sitemap.xml\n"))
+				return
+			}
+			http.NotFound(w, r)
+		case "/en/sitemap.xml":
+			w.Write([]byte("<urlset>\n<url><loc>http://" + r.Host + "/en/posts/hello/</loc></url>\n</urlset>"))
+		case "/sitemap.xml":
+			w.Write([]byte("<urlset>\n<url><loc>http://" + r.Host + "/about/</loc></url>\n</urlset>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	// Sitemaps declared in robots.txt are tried first
+	results, metadata, err := tool.discoverSitemap(context.Background(), siteURL, 50)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "/en/posts/hello/", results[0]["path"])
+	assert.Equal(t, server.URL+"/en/sitemap.xml", metadata["source"])
+	assert.Len(t, metadata["declared_sitemaps"], 2)
+
+	// Otherwise sitemap.xml is read
+	declared = false
+	tool, err = New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+	results, metadata, err = tool.discoverSitemap(context.Background(), siteURL, 50)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "/about/", results[0]["path"])
+	assert.Equal(t, "sitemap.xml", metadata["source"])
+	assert.NotContains(t, metadata, "declared_sitemaps")
+}
+
 func TestFormatResults(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -144,9 +364,9 @@ func TestFormatMetadata(t *testing.T) {
 				"limited": true,
 			},
 			expected: `{
-    "method": "overview",
     "count": 5,
-    "limited": true
+    "limited": true,
+    "method": "overview"
   }`,
 		},
 	}
@@ -169,4 +389,206 @@ func TestTool_SetLogger(t *testing.T) {
 
 	// Test that it doesn't panic with valid logger
 	// We can't easily test the logger content without more setup
-}
\ No newline at end of file
+}
+func TestTool_DiscoverOverview_SkipsMissingEndpoints(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"pages": [{"title": "A", "url": "/posts/a/"}]}`))
+	}))
+	defer server.Close()
+
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		results, _, err := tool.discoverOverview(context.Background(), siteURL, 50)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+	}
+
+	// Endpoints that returned 404 are remembered by the cache
+	assert.Equal(t, 2, requests["/index.json"])
+	assert.Equal(t, 1, requests["/api/index.json"])
+	assert.Equal(t, 1, requests["/sitemap.xml"])
+}
+
+func TestTool_DiscoverOverview_Platform(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><head><meta name="generator" content="WordPress 6.4.2"></head><body>Blog</body></html>`))
+		case "/wp-json/":
+			w.Write([]byte(`{"namespaces": ["wp/v2"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	_, metadata, err := tool.discoverOverview(context.Background(), siteURL, 50)
+	require.NoError(t, err)
+
+	platform, ok := metadata["platform"].(capabilities.Platform)
+	require.True(t, ok)
+	assert.Equal(t, capabilities.PlatformWordPress, platform.Name)
+	assert.Len(t, platform.Evidence, 2)
+	assert.Contains(t, metadata["notice"], "looks like a WordPress site")
+	assert.Equal(t, []string{capabilities.WPJSONPath}, metadata["available_endpoints"])
+}
+
+func TestTool_DiscoverPages_Cursor(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		requests++
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write([]byte(`[{"title": "One", "url": "/one/"}, {"title": "Two", "url": "/two/"}, {"title": "Three", "url": "/three/"}]`))
+	}))
+	defer server.Close()
+
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &DiscoveryRequest{HugoSitePath: server.URL, DiscoveryType: "pages", Limit: 2})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["One","Two"]`, result.Get("results.#.title").Raw)
+	assert.Equal(t, int64(3), result.Get("metadata.total_pages").Int())
+	cursor := result.Get("metadata.next_cursor").String()
+	require.NotEmpty(t, cursor)
+
+	// The cursor resumes the listing without reading the index again
+	resp, err = tool.Execute(context.Background(), &DiscoveryRequest{HugoSitePath: server.URL, DiscoveryType: "pages", Limit: 2, Cursor: cursor})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["Three"]`, result.Get("results.#.title").Raw)
+	assert.Equal(t, int64(2), result.Get("metadata.offset").Int())
+	assert.True(t, result.Get("metadata.resumed").Bool())
+	assert.False(t, result.Get("metadata.next_cursor").Exists())
+	assert.Equal(t, 1, requests)
+
+	// Cursors only resume the request they were issued for
+	_, err = tool.Execute(context.Background(), &DiscoveryRequest{HugoSitePath: server.URL, DiscoveryType: "pages", DateRange: hugoindex.DateRange{DateFrom: "2024-01-01"}, Cursor: cursor})
+	require.ErrorContains(t, err, "different request")
+	_, err = tool.Execute(context.Background(), &DiscoveryRequest{HugoSitePath: server.URL, DiscoveryType: "sitemap", Cursor: cursor})
+	require.ErrorContains(t, err, "only supported")
+}
+
+func TestTool_Execute_Explain(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[{"title": "One", "url": "/one/"}, {"title": "Two", "url": "/two/"}]`))
+	}))
+	defer server.Close()
+
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+	resp, err := tool.Execute(context.Background(), &DiscoveryRequest{HugoSitePath: server.URL, DiscoveryType: "pages", Limit: 1})
+	require.NoError(t, err)
+	cursor := gjson.Get(resp.Content[0].TextContent.Text, "metadata.next_cursor").String()
+	before := requests
+
+	explain := tools.ExplainOptions{Explain: true}
+	resp, err = tool.Execute(context.Background(), &DiscoveryRequest{HugoSitePath: server.URL, DiscoveryType: "pages", Limit: 1, Cursor: cursor, ExplainOptions: explain})
+	require.NoError(t, err)
+	body := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, int64(1), body.Get("offset").Int())
+	assert.Equal(t, `["cursor_state","site_index","feed","feed"]`, body.Get("plan.steps.#.method").Raw)
+	assert.Equal(t, `["fresh","fresh","missing","missing"]`, body.Get("plan.steps.#.cache.state").Raw)
+
+	resp, err = tool.Execute(context.Background(), &DiscoveryRequest{HugoSitePath: server.URL, ExplainOptions: explain})
+	require.NoError(t, err)
+	steps := gjson.Get(resp.Content[0].TextContent.Text, "plan.steps").Array()
+	require.Len(t, steps, len(overviewEndpoints)+1)
+	assert.Equal(t, "/index.json", steps[0].Get("endpoint").String())
+	assert.Equal(t, MethodHomePage, steps[len(steps)-1].Get("method").String())
+
+	resp, err = tool.Execute(context.Background(), &DiscoveryRequest{HugoSitePath: server.URL, DiscoveryType: "crawl", ExplainOptions: explain})
+	require.NoError(t, err)
+	assert.Equal(t, `["robots","sitemap"]`, gjson.Get(resp.Content[0].TextContent.Text, "plan.steps.#.method").Raw)
+	assert.Equal(t, before, requests, "explain fetches nothing")
+}
+
+func TestTool_DiscoverCrawl(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		base := "http://" + r.Host
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			w.Write([]byte(`<sitemapindex><sitemap><loc>` + base + `/en/sitemap.xml</loc></sitemap><sitemap><loc>https://other.example/sitemap.xml</loc></sitemap></sitemapindex>`))
+		case "/en/sitemap.xml":
+			w.Write([]byte(`<urlset>
+<url><loc>` + base + `/posts/json/</loc><lastmod>2024-01-02</lastmod></url>
+<url><loc>` + base + `/posts/json/index.html</loc></url>
+<url><loc>` + base + `/posts/html/</loc></url>
+<url><loc>https://other.example/page/</loc></url>
+<url><loc>` + base + `/posts/missing/</loc></url>
+</urlset>`))
+		case "/posts/json/index.json":
+			w.Write([]byte(`{"title": "From JSON", "date": "2024-01-01", "section": "posts", "wordcount": 120}`))
+		case "/posts/html/":
+			w.Write([]byte(`<html lang="en"><head><title>From "HTML"</title></head><body>` + strings.Repeat("x", 2000) + `</body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &DiscoveryRequest{HugoSitePath: server.URL, DiscoveryType: "crawl", Limit: 2})
+	require.NoError(t, err)
+	text := resp.Content[0].TextContent.Text
+	require.True(t, gjson.Valid(text), text)
+	result := gjson.Parse(text)
+
+	assert.Equal(t, `["From JSON","From \"HTML\""]`, result.Get("results.#.title").Raw)
+	assert.Equal(t, "json", result.Get("results.0.source").String())
+	assert.Equal(t, int64(120), result.Get("results.0.word_count").Int())
+	assert.Equal(t, "2024-01-02", result.Get("results.0.lastmod").String())
+	assert.Equal(t, "en", result.Get("results.1.language").String())
+	assert.Equal(t, int64(3), result.Get("metadata.total_urls").Int())
+	assert.Equal(t, int64(1), result.Get("metadata.skipped_external").Int())
+	assert.Equal(t, int64(1), result.Get("metadata.duplicates_removed").Int())
+	assert.False(t, result.Get("metadata.complete").Bool())
+
+	// The cursor resumes the crawl where it stopped
+	resp, err = tool.Execute(context.Background(), &DiscoveryRequest{HugoSitePath: server.URL, DiscoveryType: "crawl", Cursor: result.Get("metadata.next_cursor").String()})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["/posts/missing/"]`, result.Get("results.#.path").Raw)
+	assert.True(t, result.Get("metadata.resumed").Bool())
+	assert.True(t, result.Get("results.0.error").Exists())
+	assert.True(t, result.Get("metadata.complete").Bool())
+	assert.False(t, result.Get("metadata.next_cursor").Exists())
+
+	// The byte budget ends a call early, after at least one page
+	resp, err = tool.Execute(context.Background(), &DiscoveryRequest{HugoSitePath: server.URL, DiscoveryType: "crawl", MaxBytes: 1})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, int64(1), result.Get("results.#").Int())
+	assert.True(t, result.Get("metadata.budget_exhausted").Bool())
+
+	_, err = tool.Execute(context.Background(), &DiscoveryRequest{HugoSitePath: server.URL, DiscoveryType: "pages", MaxBytes: 1})
+	require.Error(t, err)
+}
@@ -1,16 +1,25 @@
 package errors
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
 )
 
 // ErrorDetail represents a detailed error with context
 type ErrorDetail struct {
-	Code      string                 `json:"code"`
-	Message   string                 `json:"message"`
-	Context   map[string]interface{} `json:"context,omitempty"`
-	Timestamp string                 `json:"timestamp"`
+	Code        string                 `json:"code"`
+	Message     string                 `json:"message"`
+	UserMessage string                 `json:"user_message,omitempty"`
+	Context     map[string]interface{} `json:"context,omitempty"`
+	Timestamp   string                 `json:"timestamp"`
 }
 
 // ErrorResponse represents the standardized error handling structure
@@ -33,18 +42,26 @@ const (
 	ErrCodeInternalError      = "INTERNAL_ERROR"
 	ErrCodeCacheError         = "CACHE_ERROR"
 	ErrCodeParseError         = "PARSE_ERROR"
+	ErrCodeCancelled          = "CANCELLED"
 )
 
-// NewError creates a new ErrorDetail with timestamp
+// NewError creates a new ErrorDetail with timestamp and a user-friendly
+// message for its code
 func NewError(code, message string, context map[string]interface{}) ErrorDetail {
 	return ErrorDetail{
-		Code:      code,
-		Message:   message,
-		Context:   context,
-		Timestamp: getCurrentTimestamp(),
+		Code:        code,
+		Message:     message,
+		UserMessage: ToUserFriendlyMessage(code),
+		Context:     context,
+		Timestamp:   getCurrentTimestamp(),
 	}
 }
 
+// FromError creates an ErrorDetail for err, with the code returned by Code
+func FromError(err error, context map[string]interface{}) ErrorDetail {
+	return NewError(Code(err), err.Error(), context)
+}
+
 // NewErrorResponse creates a new ErrorResponse
 func NewErrorResponse(success bool, errors []ErrorDetail, data interface{}) ErrorResponse {
 	return ErrorResponse{
@@ -78,6 +95,8 @@ func FormatErrors(errors []ErrorDetail) string {
 // ToUserFriendlyMessage converts technical errors to user-friendly messages
 func ToUserFriendlyMessage(code string) string {
 	switch code {
+	case ErrCodeInvalidRequest:
+		return "The request parameters are not valid. Please check the required parameters and their values."
 	case ErrCodeInvalidURL:
 		return "The provided URL is not valid. Please check the Hugo site URL format."
 	case ErrCodeNetworkError:
@@ -98,6 +117,8 @@ func ToUserFriendlyMessage(code string) string {
 		return "There was an issue with the cache system."
 	case ErrCodeInternalError:
 		return "An internal error occurred while processing your request."
+	case ErrCodeCancelled:
+		return "The request was cancelled before it completed."
 	default:
 		return "An unexpected error occurred."
 	}
@@ -105,9 +126,7 @@ func ToUserFriendlyMessage(code string) string {
 
 // Helper function to get current timestamp
 func getCurrentTimestamp() string {
-	// Using a simple format for now
-	// In a real implementation, you might want to use time.Now().Format(time.RFC3339)
-	return "2024-01-01T00:00:00Z" // Placeholder for consistent testing
+	return time.Now().UTC().Format(time.RFC3339)
 }
 
 // ValidationError represents validation-specific errors
@@ -166,4 +185,129 @@ func CreateCacheError(key, operation, reason, message string) ErrorDetail {
 			Reason:    reason,
 		},
 	})
-}
\ No newline at end of file
+}
+
+// Error is an error carrying one of the error codes, and optionally data
+// for the Data of its ErrorResponse
+type Error struct {
+	Code string
+	Err  error
+	Data interface{}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap attaches an error code to err; a nil err stays nil
+func Wrap(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Errorf formats an error carrying an error code
+func Errorf(code, format string, args ...interface{}) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// Code returns the error code for err: the code of the outermost Error it
+// wraps, else one inferred from the failure it wraps, else
+// ErrCodeInternalError
+func Code(err error) string {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+
+	var status *cache.StatusError
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrCodeTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrCodeCancelled
+	case errors.Is(err, httpclient.ErrBudgetExceeded):
+		return ErrCodeRateLimited
+	case errors.Is(err, httpclient.ErrDisallowedByRobots), errors.Is(err, httpclient.ErrPrivateAddress):
+		return ErrCodeUnauthorized
+	case errors.Is(err, httpclient.ErrResponseTooLarge), errors.Is(err, cache.ErrInvalidResponse):
+		return ErrCodeValidationFailed
+	case errors.Is(err, cache.ErrEndpointMissing):
+		return ErrCodeNotFound
+	case errors.As(err, &status):
+		return statusCode(status.StatusCode)
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return ErrCodeTimeout
+		}
+		return ErrCodeNetworkError
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return ErrCodeParseError
+	default:
+		return ErrCodeInternalError
+	}
+}
+
+// statusCode returns the error code for an HTTP error status
+func statusCode(status int) string {
+	switch status {
+	case http.StatusNotFound, http.StatusGone:
+		return ErrCodeNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrCodeUnauthorized
+	case http.StatusTooManyRequests:
+		return ErrCodeRateLimited
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ErrCodeTimeout
+	default:
+		return ErrCodeNetworkError
+	}
+}
+
+// ResponseError is a failed tool call reported as an ErrorResponse: its
+// message is the envelope as JSON
+type ResponseError struct {
+	Response ErrorResponse
+	err      error
+}
+
+func (e *ResponseError) Error() string {
+	data, err := json.Marshal(e.Response)
+	if err != nil {
+		return e.err.Error()
+	}
+	return string(data)
+}
+
+func (e *ResponseError) Unwrap() error {
+	return e.err
+}
+
+// Envelope returns err as a ResponseError with a single ErrorDetail and
+// the Data of the outermost Error it wraps; a nil err stays nil
+func Envelope(err error, context map[string]interface{}) error {
+	if err == nil {
+		return nil
+	}
+	var envelope *ResponseError
+	if errors.As(err, &envelope) {
+		return err
+	}
+	var data interface{}
+	var coded *Error
+	if errors.As(err, &coded) {
+		data = coded.Data
+	}
+	return &ResponseError{
+		Response: NewErrorResponse(false, []ErrorDetail{FromError(err, context)}, data),
+		err:      err,
+	}
+}
@@ -1,9 +1,18 @@
 package errors
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
 	"testing"
+	"time"
 
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewError(t *testing.T) {
@@ -140,4 +149,76 @@ func TestCreateCacheError(t *testing.T) {
 	assert.Equal(t, "cache-key-123", cache.Key)
 	assert.Equal(t, "get", cache.Operation)
 	assert.Equal(t, "expired", cache.Reason)
-}
\ No newline at end of file
+}
+
+func TestNewError_Timestamp(t *testing.T) {
+	err := NewError(ErrCodeNotFound, "Content not found", nil)
+
+	timestamp, parseErr := time.Parse(time.RFC3339, err.Timestamp)
+	require.NoError(t, parseErr)
+	assert.WithinDuration(t, time.Now(), timestamp, time.Minute)
+	assert.Equal(t, ToUserFriendlyMessage(ErrCodeNotFound), err.UserMessage)
+}
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "explicit code", err: Errorf(ErrCodeInvalidURL, "invalid Hugo site URL: %w", &url.Error{Op: "parse", URL: "::", Err: fmt.Errorf("missing protocol scheme")}), want: ErrCodeInvalidURL},
+		{name: "wrapped explicit code", err: fmt.Errorf("search failed: %w", Errorf(ErrCodeNotFound, "no Hugo search endpoints available")), want: ErrCodeNotFound},
+		{name: "not found", err: fmt.Errorf("failed to read page: %w", &cache.StatusError{StatusCode: 404}), want: ErrCodeNotFound},
+		{name: "gone", err: &cache.StatusError{StatusCode: 410}, want: ErrCodeNotFound},
+		{name: "forbidden", err: &cache.StatusError{StatusCode: 403}, want: ErrCodeUnauthorized},
+		{name: "too many requests", err: &cache.StatusError{StatusCode: 429}, want: ErrCodeRateLimited},
+		{name: "server error", err: &cache.StatusError{StatusCode: 502}, want: ErrCodeNetworkError},
+		{name: "endpoint missing", err: fmt.Errorf("%w: /index.json", cache.ErrEndpointMissing), want: ErrCodeNotFound},
+		{name: "invalid response", err: cache.ErrInvalidResponse, want: ErrCodeValidationFailed},
+		{name: "too large", err: httpclient.ErrResponseTooLarge, want: ErrCodeValidationFailed},
+		{name: "budget", err: httpclient.ErrBudgetExceeded, want: ErrCodeRateLimited},
+		{name: "robots", err: httpclient.ErrDisallowedByRobots, want: ErrCodeUnauthorized},
+		{name: "private address", err: &url.Error{Op: "Get", URL: "http://10.0.0.1", Err: httpclient.ErrPrivateAddress}, want: ErrCodeUnauthorized},
+		{name: "deadline", err: fmt.Errorf("search cancelled: %w", context.DeadlineExceeded), want: ErrCodeTimeout},
+		{name: "cancelled", err: context.Canceled, want: ErrCodeCancelled},
+		{name: "connection refused", err: &url.Error{Op: "Get", URL: "http://127.0.0.1:1", Err: &net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}}, want: ErrCodeNetworkError},
+		{name: "parse", err: json.Unmarshal([]byte("{"), &struct{}{}), want: ErrCodeParseError},
+		{name: "unknown", err: fmt.Errorf("failed to marshal response"), want: ErrCodeInternalError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Code(tt.err))
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	assert.Nil(t, Wrap(ErrCodeInvalidRequest, nil))
+
+	cause := fmt.Errorf("hugo_site_path is required")
+	err := Wrap(ErrCodeInvalidRequest, cause)
+	assert.Equal(t, "hugo_site_path is required", err.Error())
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, ErrCodeInvalidRequest, Code(err))
+}
+
+func TestEnvelope(t *testing.T) {
+	assert.Nil(t, Envelope(nil, nil))
+
+	cause := &cache.StatusError{StatusCode: 404}
+	err := Envelope(fmt.Errorf("page not found: %w", cause), map[string]interface{}{"tool": "hugo_reader_extract_links"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, err.(*ResponseError).err)
+	assert.ErrorAs(t, err, &cause)
+	assert.Same(t, err, Envelope(err, nil))
+
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal([]byte(err.Error()), &response))
+	assert.False(t, response.Success)
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, ErrCodeNotFound, response.Errors[0].Code)
+	assert.Equal(t, "page not found: unexpected status: 404", response.Errors[0].Message)
+	assert.Equal(t, ToUserFriendlyMessage(ErrCodeNotFound), response.Errors[0].UserMessage)
+	assert.Equal(t, "hugo_reader_extract_links", response.Errors[0].Context["tool"])
+}
@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+)
+
+// ExplainOptions is embedded in requests that can describe what they would
+// do instead of doing it
+type ExplainOptions struct {
+	Explain bool `json:"explain,omitempty" jsonschema:"title=Return the plan (endpoints tried in order, cache keys consulted and filters applied) without fetching anything"`
+}
+
+// Plan is what a request made with explain would have done: the resources
+// it would read, in the order it would try them, and the filters it would
+// apply to what they return
+type Plan struct {
+	Steps   []PlanStep             `json:"steps"`
+	Filters map[string]interface{} `json:"filters"`
+
+	ctx   context.Context
+	cache *cache.Cache
+	site  string
+}
+
+// PlanStep is one resource a Plan would read
+type PlanStep struct {
+	// Method is the strategy the step belongs to, such as a search method
+	Method string `json:"method"`
+
+	// Path is the requested path the step is for, for requests naming
+	// several
+	Path string `json:"path,omitempty"`
+
+	Endpoint string            `json:"endpoint"`
+	URL      string            `json:"url"`
+	CacheKey string            `json:"cache_key,omitempty"`
+	Cache    *cache.Inspection `json:"cache,omitempty"`
+
+	// Skipped is true for endpoints recently found missing, which are not
+	// requested until the failure TTL passes
+	Skipped bool   `json:"skipped,omitempty"`
+	Note    string `json:"note,omitempty"`
+}
+
+// NewPlan returns an empty Plan for site, inspecting c for the state of
+// the cache keys its steps consult under the cache Policy of ctx
+func NewPlan(ctx context.Context, c *cache.Cache, site string) *Plan {
+	return &Plan{
+		Steps:   []PlanStep{},
+		Filters: make(map[string]interface{}),
+		ctx:     ctx,
+		cache:   c,
+		site:    site,
+	}
+}
+
+// Add appends a step reading rawURL under key, which is left out when the
+// step bypasses the cache, and returns it for the caller to annotate
+func (p *Plan) Add(method, endpoint, rawURL, key string) *PlanStep {
+	step := PlanStep{Method: method, Endpoint: endpoint, URL: rawURL, CacheKey: key}
+	if key != "" {
+		inspection := p.cache.Inspect(p.ctx, key)
+		step.Cache = &inspection
+	}
+	p.Steps = append(p.Steps, step)
+	return &p.Steps[len(p.Steps)-1]
+}
+
+// AddEndpoint is Add for an endpoint read with cache.FetchEndpoint, which
+// notes whether it would be skipped as recently missing
+func (p *Plan) AddEndpoint(method, endpoint, rawURL, key string) *PlanStep {
+	step := p.Add(method, endpoint, rawURL, key)
+	step.Skipped = !cache.PolicyFrom(p.ctx).Bypass && p.cache.SkipEndpoint(p.site, endpoint)
+	return step
+}
+
+// Filter records a filter the request would apply, unless value is its
+// zero value
+func (p *Plan) Filter(name string, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return
+		}
+	case int:
+		if v == 0 {
+			return
+		}
+	case bool:
+		if !v {
+			return
+		}
+	case []string:
+		if len(v) == 0 {
+			return
+		}
+	}
+	p.Filters[name] = value
+}
+
+// ExplainResponse returns plan as the response of a request made with
+// explain, alongside fields describing the request
+func ExplainResponse(plan *Plan, fields map[string]interface{}) (*mcp_golang.ToolResponse, error) {
+	response := map[string]interface{}{
+		"success": true,
+		"explain": true,
+		"plan":    plan,
+		"errors":  []interface{}{},
+	}
+	for key, value := range fields {
+		response[key] = value
+	}
+	policy := cache.PolicyFrom(plan.ctx)
+	response["cache_policy"] = map[string]interface{}{
+		"bypass": policy.Bypass,
+		"ttl":    policy.TTL.String(),
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plan: %w", err)
+	}
+	return TextResponse(data), nil
+}
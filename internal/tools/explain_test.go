@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestPlan(t *testing.T) {
+	const site = "https://example.com"
+	c := cache.New()
+	c.Set("cached", []byte("{}"), "", "")
+	c.RecordEndpoint(site, "/missing.json", &cache.StatusError{StatusCode: http.StatusNotFound})
+
+	plan := NewPlan(context.Background(), c, site)
+	plan.Add("index", "/index.json", site+"/index.json", "cached")
+	plan.AddEndpoint("native", "/missing.json", site+"/missing.json", "uncached")
+	plan.Add("robots", "/robots.txt", site+"/robots.txt", "").Note = "read by the HTTP client"
+	plan.Filter("content_type", "post")
+	plan.Filter("taxonomy", "")
+	plan.Filter("limit", 0)
+	plan.Filter("include_drafts", false)
+
+	resp, err := ExplainResponse(plan, map[string]interface{}{"query": "golang"})
+	require.NoError(t, err)
+	body := gjson.Parse(resp.Content[0].TextContent.Text)
+
+	assert.True(t, body.Get("explain").Bool())
+	assert.Equal(t, "golang", body.Get("query").String())
+	steps := body.Get("plan.steps").Array()
+	require.Len(t, steps, 3)
+	assert.Equal(t, cache.StateFresh, steps[0].Get("cache.state").String())
+	assert.False(t, steps[0].Get("skipped").Bool())
+	assert.Equal(t, cache.StateMissing, steps[1].Get("cache.state").String())
+	assert.True(t, steps[1].Get("skipped").Bool())
+	assert.False(t, steps[2].Get("cache").Exists())
+	assert.Equal(t, "read by the HTTP client", steps[2].Get("note").String())
+	assert.Equal(t, `{"content_type":"post"}`, body.Get("plan.filters").Raw)
+
+	// Bypassing the cache skips no endpoints
+	ctx := cache.ContextWithPolicy(context.Background(), cache.Policy{Bypass: true})
+	plan = NewPlan(ctx, c, site)
+	step := plan.AddEndpoint("native", "/missing.json", site+"/missing.json", "cached")
+	assert.False(t, step.Skipped)
+	assert.Equal(t, cache.StateBypassed, step.Cache.State)
+}
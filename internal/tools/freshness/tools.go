@@ -0,0 +1,357 @@
+package freshness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+)
+
+// Endpoint states
+const (
+	StateFirstCheck = "first_check"
+	StateUnchanged  = "unchanged"
+	StateChanged    = "changed"
+	StateUnknown    = "unknown"
+	StateMissing    = "missing"
+	StateError      = "error"
+)
+
+// endpoints are the site resources checked, which change whenever content
+// is published
+var endpoints = []string{"/index.json", "/sitemap.xml", "/index.xml"}
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool checks whether a Hugo site has changed since it was last checked,
+// without downloading its content.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// FreshnessRequest represents the request parameters for a freshness check.
+type FreshnessRequest struct {
+	HugoSitePath string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+
+	httpclient.RetryOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// EndpointStatus is the result of checking one endpoint
+type EndpointStatus struct {
+	Endpoint      string     `json:"endpoint"`
+	URL           string     `json:"url"`
+	State         string     `json:"state"`
+	StatusCode    int        `json:"status_code,omitempty"`
+	ETag          string     `json:"etag,omitempty"`
+	LastModified  string     `json:"last_modified,omitempty"`
+	ContentLength int64      `json:"content_length,omitempty"`
+	PreviousCheck *time.Time `json:"previous_check,omitempty"`
+	ChangedAt     *time.Time `json:"changed_at,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_site_freshness"),
+		name:        "hugo_reader_site_freshness",
+		description: "Quickly check whether a Hugo site has changed since the last check. Sends conditional HEAD requests for index.json, sitemap.xml and the RSS feed using the ETag and Last-Modified values seen before, without downloading any content, and reports which changed and when. The first call for a site records a baseline. Use it before re-reading a site to skip unchanged ones.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *FreshnessRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// Execute checks whether a site has changed.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	freshnessRequest, ok := req.(*FreshnessRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := freshnessRequest.SiteOptions.Apply(ctx, t.sites, &freshnessRequest.HugoSitePath, &freshnessRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := freshnessRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := freshnessRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(freshnessRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", freshnessRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = freshnessRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	now := time.Now().UTC()
+	statuses := make([]EndpointStatus, 0, len(endpoints))
+	var changed, available, firstCheck int
+	var lastChanged *time.Time
+	for _, endpoint := range endpoints {
+		status := t.check(ctx, siteURL.ResolveReference(&url.URL{Path: endpoint}), now)
+		status.Endpoint = endpoint
+		statuses = append(statuses, status)
+
+		switch status.State {
+		case StateChanged:
+			changed++
+		case StateFirstCheck:
+			firstCheck++
+		}
+		if status.State != StateMissing && status.State != StateError {
+			available++
+		}
+		if status.ChangedAt != nil && (lastChanged == nil || status.ChangedAt.After(*lastChanged)) {
+			lastChanged = status.ChangedAt
+		}
+	}
+	if available == 0 {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "no index, sitemap or feed available at Hugo site: %s", freshnessRequest.HugoSitePath)
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"changed":   changed > 0,
+		"baseline":  firstCheck == available,
+		"endpoints": statuses,
+		"metadata": map[string]interface{}{
+			"checked_at":          now,
+			"endpoints_checked":   len(statuses),
+			"endpoints_available": available,
+			"endpoints_changed":   changed,
+			"last_changed":        lastChanged,
+			"site_source":         sites.Source(ctx),
+		},
+		"errors": []string{},
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal freshness check", "error", err)
+		return nil, fmt.Errorf("failed to marshal freshness check: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Checked site freshness", "site", freshnessRequest.HugoSitePath, "changed", changed, "available", available)
+	return tools.TextResponse(responseJSON), nil
+}
+
+// check sends a conditional HEAD request for an endpoint using the
+// validators recorded by the last check, and records the new ones
+func (t *Tool) check(ctx context.Context, endpointURL *url.URL, now time.Time) EndpointStatus {
+	rawURL := endpointURL.String()
+	status := EndpointStatus{URL: rawURL}
+	previous, seen := t.cache.LastValidators(rawURL)
+	if seen {
+		checked := previous.CheckedAt
+		status.PreviousCheck = &checked
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		status.State = StateError
+		status.Error = err.Error()
+		return status
+	}
+	if seen && previous.ETag != "" {
+		req.Header.Set("If-None-Match", previous.ETag)
+	}
+	if seen && previous.LastModified != "" {
+		req.Header.Set("If-Modified-Since", previous.LastModified)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		t.log.DebugContext(ctx, "Freshness check failed", "url", rawURL, "error", err)
+		status.State = StateError
+		status.Error = err.Error()
+		return status
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	status.StatusCode = resp.StatusCode
+
+	current := cache.Validators{
+		ETag:          resp.Header.Get("ETag"),
+		LastModified:  resp.Header.Get("Last-Modified"),
+		ContentLength: resp.ContentLength,
+		CheckedAt:     now,
+		ChangedAt:     previous.ChangedAt,
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		// A 304 may omit validators that have not changed
+		if current.ETag == "" {
+			current.ETag = previous.ETag
+		}
+		if current.LastModified == "" {
+			current.LastModified = previous.LastModified
+		}
+		current.ContentLength = previous.ContentLength
+		status.State = StateUnchanged
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		status.State = StateMissing
+		return status
+	case resp.StatusCode >= 300:
+		status.State = StateError
+		status.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		return status
+	case !seen:
+		status.State = StateFirstCheck
+		if modified, err := http.ParseTime(current.LastModified); err == nil {
+			current.ChangedAt = modified.UTC()
+		}
+	default:
+		status.State = compare(previous, current)
+		if status.State == StateChanged {
+			current.ChangedAt = now
+			if modified, err := http.ParseTime(current.LastModified); err == nil {
+				current.ChangedAt = modified.UTC()
+			}
+		}
+	}
+
+	t.cache.SetValidators(rawURL, current)
+	status.ETag = current.ETag
+	status.LastModified = current.LastModified
+	if current.ContentLength > 0 {
+		status.ContentLength = current.ContentLength
+	}
+	if !current.ChangedAt.IsZero() {
+		changedAt := current.ChangedAt
+		status.ChangedAt = &changedAt
+	}
+	return status
+}
+
+// compare tells whether a resource changed between two checks, preferring
+// the ETag, then Last-Modified, then the content length. Without any of
+// them, whether it changed is unknown.
+func compare(previous, current cache.Validators) string {
+	if previous.ETag != "" && current.ETag != "" {
+		return changedIf(previous.ETag != current.ETag)
+	}
+	if previous.LastModified != "" && current.LastModified != "" {
+		return changedIf(previous.LastModified != current.LastModified)
+	}
+	if previous.ContentLength > 0 && current.ContentLength > 0 {
+		return changedIf(previous.ContentLength != current.ContentLength)
+	}
+	return StateUnknown
+}
+
+// changedIf returns the state for whether a resource changed
+func changedIf(changed bool) string {
+	if changed {
+		return StateChanged
+	}
+	return StateUnchanged
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*FreshnessRequest](registry, t, "Change monitoring", tools.Annotations{OpenWorldHint: true})
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
@@ -0,0 +1,100 @@
+package freshness
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNew(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+	assert.Equal(t, "hugo_reader_site_freshness", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+	assert.NotNil(t, tool.cache)
+	assert.NotNil(t, tool.httpClient)
+}
+
+func TestFreshnessRequest_Validate(t *testing.T) {
+	assert.NoError(t, (&FreshnessRequest{HugoSitePath: "https://example.com"}).Validate())
+	assert.Error(t, (&FreshnessRequest{}).Validate())
+}
+
+func TestTool_Execute(t *testing.T) {
+	etag := `"v1"`
+	methods := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods[r.Method]++
+		switch r.URL.Path {
+		case "/index.json":
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", etag)
+			w.Write([]byte(`[{"title": "Hello"}]`))
+		case "/sitemap.xml":
+			w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+			w.Write([]byte(`<urlset></urlset>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+	check := func() gjson.Result {
+		resp, err := tool.Execute(context.Background(), &FreshnessRequest{HugoSitePath: server.URL})
+		require.NoError(t, err)
+		return gjson.Parse(resp.Content[0].TextContent.Text)
+	}
+
+	// The first check records a baseline
+	result := check()
+	assert.True(t, result.Get("baseline").Bool())
+	assert.False(t, result.Get("changed").Bool())
+	assert.Equal(t, `["first_check","first_check","missing"]`, result.Get("endpoints.#.state").Raw)
+	assert.Equal(t, "2024-01-01T00:00:00Z", result.Get("endpoints.1.changed_at").String())
+
+	// Unchanged endpoints answer the conditional request with 304
+	result = check()
+	assert.False(t, result.Get("changed").Bool())
+	assert.Equal(t, int64(http.StatusNotModified), result.Get("endpoints.0.status_code").Int())
+	assert.Equal(t, `["unchanged","unchanged","missing"]`, result.Get("endpoints.#.state").Raw)
+	assert.True(t, result.Get("endpoints.0.previous_check").Exists())
+
+	// A new ETag means the index changed
+	etag = `"v2"`
+	result = check()
+	assert.True(t, result.Get("changed").Bool())
+	assert.Equal(t, "changed", result.Get("endpoints.0.state").String())
+	assert.Equal(t, `"v2"`, result.Get("endpoints.0.etag").String())
+	assert.True(t, result.Get("metadata.last_changed").Exists())
+
+	// Only HEAD requests are sent, so no content is downloaded
+	assert.Equal(t, map[string]int{http.MethodHead: 9}, methods)
+}
+
+func TestTool_Execute_NothingAvailable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+	_, err = tool.Execute(context.Background(), &FreshnessRequest{HugoSitePath: server.URL})
+	require.Error(t, err)
+}
+
+func TestCompare(t *testing.T) {
+	assert.Equal(t, StateChanged, compare(cache.Validators{ETag: `"a"`, LastModified: "x"}, cache.Validators{ETag: `"b"`, LastModified: "x"}))
+	assert.Equal(t, StateUnchanged, compare(cache.Validators{LastModified: "x"}, cache.Validators{LastModified: "x"}))
+	assert.Equal(t, StateChanged, compare(cache.Validators{ContentLength: 10}, cache.Validators{ContentLength: 12}))
+	assert.Equal(t, StateUnknown, compare(cache.Validators{}, cache.Validators{ContentLength: 12}))
+}
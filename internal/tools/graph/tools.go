@@ -0,0 +1,434 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/pagelinks"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/tidwall/gjson"
+)
+
+// maxConcurrency bounds how many pages are read at once
+const maxConcurrency = 8
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool builds the internal link graph of a Hugo site.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// GraphRequest represents the request parameters for the site graph tool.
+type GraphRequest struct {
+	HugoSitePath string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	Section      string `json:"section,omitempty" jsonschema:"title=Only include pages in this section (e.g. posts)"`
+	MaxPages     int    `json:"max_pages,omitempty" jsonschema:"title=Maximum pages to read,minimum=1,maximum=500"`
+	Concurrency  int    `json:"concurrency,omitempty" jsonschema:"title=Pages read at once,minimum=1,maximum=8"`
+	TopN         int    `json:"top_n,omitempty" jsonschema:"title=Most-linked pages to list,minimum=1,maximum=100"`
+	OmitEdges    bool   `json:"omit_edges,omitempty" jsonschema:"title=Leave out the list of edges to keep the response small"`
+
+	hugoindex.PublishOptions
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// node is a page in the graph
+type node struct {
+	Path     string `json:"path"`
+	Title    string `json:"title,omitempty"`
+	Inbound  int    `json:"inbound"`
+	Outbound int    `json:"outbound"`
+	Error    string `json:"error,omitempty"`
+}
+
+// edge is a link between two pages in the graph, counted once per page
+type edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_site_graph"),
+		name:        "hugo_reader_site_graph",
+		description: "Build the internal link graph of a Hugo site from the pages in its index, returning pages with inbound and outbound link counts, the links between them, orphan pages no other page links to, and the most-linked pages. Bounded by max_pages; use section to audit part of a site.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *GraphRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+
+	r.Section = strings.Trim(r.Section, "/")
+	for _, part := range strings.Split(r.Section, "/") {
+		if part == "." || part == ".." {
+			return fmt.Errorf("invalid section: %s", r.Section)
+		}
+	}
+
+	if r.MaxPages == 0 {
+		r.MaxPages = 100
+	} else if r.MaxPages < 1 || r.MaxPages > 500 {
+		return fmt.Errorf("max_pages must be between 1 and 500")
+	}
+
+	if r.Concurrency == 0 {
+		r.Concurrency = 4
+	} else if r.Concurrency < 1 || r.Concurrency > maxConcurrency {
+		return fmt.Errorf("concurrency must be between 1 and %d", maxConcurrency)
+	}
+
+	if r.TopN == 0 {
+		r.TopN = 10
+	} else if r.TopN < 1 || r.TopN > 100 {
+		return fmt.Errorf("top_n must be between 1 and 100")
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// Execute builds the link graph of a site.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	graphRequest, ok := req.(*GraphRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := graphRequest.SiteOptions.Apply(ctx, t.sites, &graphRequest.HugoSitePath, &graphRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := graphRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := graphRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = graphRequest.CacheOptions.Apply(ctx, 0)
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(graphRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", graphRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = graphRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	index, err := hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		t.log.ErrorContext(ctx, "No site index available", "site", graphRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "no site index available at Hugo site: %s", graphRequest.HugoSitePath)
+	}
+
+	nodes, totalPages, err := collectNodes(ctx, index, graphRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read site index: %w", err)
+	}
+
+	outbound := t.readLinks(ctx, index, siteURL, nodes, graphRequest.Concurrency)
+	if err := ctx.Err(); err != nil {
+		t.log.WarnContext(ctx, "Site graph cancelled", "site", graphRequest.HugoSitePath, "error", err)
+		return nil, fmt.Errorf("site graph cancelled: %w", err)
+	}
+
+	edges, unresolved := buildEdges(nodes, outbound)
+	failed := 0
+	for _, n := range nodes {
+		if n.Error != "" {
+			failed++
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"source_endpoint":  index.URL(),
+		"pages_in_index":   totalPages,
+		"pages_read":       len(nodes) - failed,
+		"failed_count":     failed,
+		"truncated":        totalPages > len(nodes),
+		"edge_count":       len(edges),
+		"unresolved_links": unresolved,
+		"max_pages":        graphRequest.MaxPages,
+		"site_source":      sites.Source(ctx),
+	}
+	if graphRequest.Section != "" {
+		metadata["section"] = graphRequest.Section
+	}
+
+	response := map[string]interface{}{
+		"success":     true,
+		"nodes":       nodes,
+		"orphans":     orphans(nodes),
+		"most_linked": mostLinked(nodes, graphRequest.TopN),
+		"metadata":    metadata,
+		"errors":      []string{},
+	}
+	if !graphRequest.OmitEdges {
+		response["edges"] = edges
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal site graph", "error", err)
+		return nil, fmt.Errorf("failed to marshal site graph: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Built site graph", "site", graphRequest.HugoSitePath, "pages", len(nodes), "edges", len(edges), "failed", failed)
+	return tools.TextResponse(responseJSON), nil
+}
+
+// collectNodes lists the published pages of the index, within the section
+// if one is given, up to the page limit. It also returns how many pages
+// qualified in total.
+func collectNodes(ctx context.Context, index *hugoindex.SiteIndex, req *GraphRequest) ([]*node, int, error) {
+	now := time.Now()
+	seen := make(map[string]bool)
+	var nodes []*node
+	total := 0
+
+	err := index.Pages(ctx, func(page gjson.Result) bool {
+		path := normalizePath(hugoindex.PageURL(page))
+		if path == "" || seen[path] || !req.PublishOptions.Allows(page, now) {
+			return true
+		}
+		if req.Section != "" && !strings.HasPrefix(path, "/"+req.Section+"/") {
+			return true
+		}
+		seen[path] = true
+		total++
+		if len(nodes) < req.MaxPages {
+			nodes = append(nodes, &node{Path: path, Title: page.Get("title").String()})
+		}
+		return true
+	})
+	return nodes, total, err
+}
+
+// readLinks reads the internal link targets of each page, a bounded number
+// of pages at a time. Pages that cannot be read are marked with an error.
+func (t *Tool) readLinks(ctx context.Context, index *hugoindex.SiteIndex, siteURL *url.URL, nodes []*node, concurrency int) [][]string {
+	outbound := make([][]string, len(nodes))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, n := range nodes {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return outbound
+		}
+
+		wg.Add(1)
+		go func(i int, n *node) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			pageURL := pagelinks.PageURL(siteURL, n.Path)
+			body, err := pagelinks.Fetch(ctx, t.cache, t.httpClient, index, siteURL, pageURL)
+			if err != nil {
+				t.log.DebugContext(ctx, "Failed to read page", "url", pageURL.String(), "error", err)
+				n.Error = "page could not be read"
+				return
+			}
+
+			for _, link := range pagelinks.Resolve(htmltext.Links(body.Content), siteURL, pageURL, true) {
+				if link.Type == pagelinks.Internal {
+					outbound[i] = append(outbound[i], normalizePath(link.Path))
+				}
+			}
+		}(i, n)
+	}
+	wg.Wait()
+	return outbound
+}
+
+// buildEdges links pages to the pages in the graph they link to, counting
+// inbound and outbound links once per pair. It also returns how many
+// internal links lead to pages outside the graph.
+func buildEdges(nodes []*node, outbound [][]string) ([]edge, int) {
+	byPath := make(map[string]*node, len(nodes))
+	for _, n := range nodes {
+		byPath[n.Path] = n
+	}
+
+	edges := []edge{}
+	unresolved := 0
+	for i, n := range nodes {
+		seen := make(map[string]bool)
+		for _, target := range outbound[i] {
+			if target == n.Path || seen[target] {
+				continue
+			}
+			seen[target] = true
+
+			to, ok := byPath[target]
+			if !ok {
+				unresolved++
+				continue
+			}
+			n.Outbound++
+			to.Inbound++
+			edges = append(edges, edge{From: n.Path, To: target})
+		}
+	}
+	return edges, unresolved
+}
+
+// orphans returns the pages no other page in the graph links to. The home
+// page is never an orphan.
+func orphans(nodes []*node) []string {
+	result := []string{}
+	for _, n := range nodes {
+		if n.Inbound == 0 && n.Path != "/" && n.Error == "" {
+			result = append(result, n.Path)
+		}
+	}
+	return result
+}
+
+// mostLinked returns up to n pages with the most inbound links
+func mostLinked(nodes []*node, n int) []*node {
+	sorted := make([]*node, 0, len(nodes))
+	for _, node := range nodes {
+		if node.Inbound > 0 {
+			sorted = append(sorted, node)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Inbound != sorted[j].Inbound {
+			return sorted[i].Inbound > sorted[j].Inbound
+		}
+		return sorted[i].Path < sorted[j].Path
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// normalizePath reduces a page URL to its path in Hugo's pretty URL form,
+// so that /posts/a, /posts/a/ and /posts/a/index.html are the same page
+func normalizePath(pageURL string) string {
+	if u, err := url.Parse(pageURL); err == nil {
+		pageURL = u.Path
+	}
+	if pageURL == "" {
+		return ""
+	}
+	pageURL = strings.TrimSuffix(pageURL, "index.html")
+	if !strings.HasPrefix(pageURL, "/") {
+		pageURL = "/" + pageURL
+	}
+	if last := pageURL[strings.LastIndex(pageURL, "/")+1:]; last != "" && !strings.Contains(last, ".") {
+		pageURL += "/"
+	}
+	return pageURL
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*GraphRequest](registry, t, "Content audits", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
@@ -0,0 +1,135 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNew(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+	assert.NotNil(t, tool)
+	assert.Equal(t, "hugo_reader_site_graph", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+	assert.NotNil(t, tool.cache)
+	assert.NotNil(t, tool.httpClient)
+}
+
+func TestGraphRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *GraphRequest
+		wantErr bool
+	}{
+		{
+			name:    "valid request with defaults",
+			req:     &GraphRequest{HugoSitePath: "https://example.com"},
+			wantErr: false,
+		},
+		{
+			name:    "section and limits",
+			req:     &GraphRequest{HugoSitePath: "https://example.com", Section: "/posts/", MaxPages: 500, Concurrency: 8, TopN: 100},
+			wantErr: false,
+		},
+		{
+			name:    "missing hugo_site_path",
+			req:     &GraphRequest{},
+			wantErr: true,
+		},
+		{
+			name:    "invalid section",
+			req:     &GraphRequest{HugoSitePath: "https://example.com", Section: "posts/.."},
+			wantErr: true,
+		},
+		{
+			name:    "max_pages too high",
+			req:     &GraphRequest{HugoSitePath: "https://example.com", MaxPages: 501},
+			wantErr: true,
+		},
+		{
+			name:    "concurrency too high",
+			req:     &GraphRequest{HugoSitePath: "https://example.com", Concurrency: 9},
+			wantErr: true,
+		},
+		{
+			name:    "top_n too high",
+			req:     &GraphRequest{HugoSitePath: "https://example.com", TopN: 101},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Greater(t, tt.req.MaxPages, 0)
+				assert.Greater(t, tt.req.Concurrency, 0)
+				assert.Greater(t, tt.req.TopN, 0)
+			}
+		})
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	assert.Equal(t, "/posts/a/", normalizePath("/posts/a"))
+	assert.Equal(t, "/posts/a/", normalizePath("https://example.com/posts/a/index.html"))
+	assert.Equal(t, "/feed.xml", normalizePath("/feed.xml"))
+	assert.Equal(t, "/", normalizePath("https://example.com/"))
+	assert.Equal(t, "", normalizePath(""))
+}
+
+func TestTool_Execute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			w.Write([]byte(`[
+				{"title": "Home", "url": "/"},
+				{"title": "A", "url": "/posts/a/", "content": "<p><a href=\"../b/\">B</a> <a href=\"/posts/b/index.html\">B again</a> <a href=\"#top\">Top</a> <a href=\"/gone/\">Gone</a></p>"},
+				{"title": "B", "url": "/posts/b/", "content": "Plain text"},
+				{"title": "Lonely", "url": "/posts/lonely/", "content": "<p><a href=\"https://gohugo.io/\">Hugo</a></p>"},
+				{"title": "Draft", "url": "/posts/draft/", "draft": true, "content": "<p><a href=\"/posts/a/\">A</a></p>"}
+			]`))
+		case "/":
+			w.Write([]byte(`<html><body><main><a href="/posts/a/">A</a> <a href="/posts/b/">B</a></main></body></html>`))
+		case "/posts/b/":
+			w.Write([]byte(`<html><body><main><a href="/posts/a">A</a></main></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &GraphRequest{HugoSitePath: server.URL, Concurrency: 2})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+
+	assert.Equal(t, `["/","/posts/a/","/posts/b/","/posts/lonely/"]`, result.Get("nodes.#.path").Raw)
+	assert.Equal(t, `[0,2,2,0]`, result.Get("nodes.#.inbound").Raw)
+	assert.Equal(t, `[2,1,1,0]`, result.Get("nodes.#.outbound").Raw)
+	assert.Equal(t, `["/posts/lonely/"]`, result.Get("orphans").Raw)
+	assert.Equal(t, `["/posts/a/","/posts/b/"]`, result.Get("most_linked.#.path").Raw)
+	assert.Equal(t, int64(4), result.Get("edges.#").Int())
+	assert.Equal(t, int64(1), result.Get("metadata.unresolved_links").Int())
+	assert.False(t, result.Get("metadata.truncated").Bool())
+
+	// Sections and page limits bound the graph
+	resp, err = tool.Execute(context.Background(), &GraphRequest{HugoSitePath: server.URL, Section: "posts", MaxPages: 2, OmitEdges: true})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["/posts/a/","/posts/b/"]`, result.Get("nodes.#.path").Raw)
+	assert.Equal(t, int64(3), result.Get("metadata.pages_in_index").Int())
+	assert.True(t, result.Get("metadata.truncated").Bool())
+	assert.False(t, result.Get("edges").Exists())
+}
@@ -0,0 +1,246 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/health"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+)
+
+// Health statuses
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+)
+
+// Recent tool calls count as degraded when at least minCalls were made and
+// at least maxErrorRate of them failed
+const (
+	minCalls     = 10
+	maxErrorRate = 0.5
+)
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool reports the health of the server for monitoring.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+	recorder    *health.Recorder
+}
+
+// HealthRequest represents the request parameters for the health tool.
+type HealthRequest struct {
+	SkipSite bool `json:"skip_site,omitempty" jsonschema:"title=Skip checking that the default site is reachable"`
+
+	httpclient.RetryOptions
+}
+
+// SiteHealth is the reachability of the default site
+type SiteHealth struct {
+	Configured bool   `json:"configured"`
+	Checked    bool   `json:"checked"`
+	URL        string `json:"url,omitempty"`
+	Reachable  bool   `json:"reachable"`
+	StatusCode int    `json:"status_code,omitempty"`
+	LatencyMs  int64  `json:"latency_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_health"),
+		name:        "hugo_reader_health",
+		description: "Check the health of the Hugo Reader server: uptime, cache statistics, whether the default site is reachable and the error rate of recent tool calls. Status is 'ok' or 'degraded'. Use for monitoring and to diagnose failing tools.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(10 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+		recorder:    health.NewRecorder(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache whose statistics are reported.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry the default site is resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// WithRecorder sets the recorder of server uptime and recent tool calls.
+func WithRecorder(r *health.Recorder) ToolOption {
+	return func(t *Tool) error {
+		t.recorder = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *HealthRequest) Validate() error {
+	return r.RetryOptions.Validate()
+}
+
+// Execute reports the health of the server.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	healthRequest, ok := req.(*HealthRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	if err := healthRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := healthRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+
+	site := t.checkSite(ctx, healthRequest.SkipSite)
+	calls := t.recorder.Stats()
+
+	status := StatusOK
+	if site.Checked && !site.Reachable {
+		status = StatusDegraded
+	}
+	if calls.Calls >= minCalls && calls.ErrorRate >= maxErrorRate {
+		status = StatusDegraded
+	}
+
+	uptime := t.recorder.Uptime()
+	response := map[string]interface{}{
+		"success":        true,
+		"status":         status,
+		"started_at":     t.recorder.Started().UTC().Format(time.RFC3339),
+		"uptime":         uptime.Round(time.Second).String(),
+		"uptime_seconds": int64(uptime.Seconds()),
+		"default_site":   site,
+		"tool_calls":     calls,
+		"cache":          t.cache.Stats(),
+		"errors":         []string{},
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal health report", "error", err)
+		return nil, fmt.Errorf("failed to marshal health report: %w", err)
+	}
+
+	t.log.DebugContext(ctx, "Checked health", "status", status, "site_reachable", site.Reachable, "error_rate", calls.ErrorRate)
+	return tools.TextResponse(responseJSON), nil
+}
+
+// checkSite requests the home page of the default site, if one is
+// configured, bypassing the cache
+func (t *Tool) checkSite(ctx context.Context, skip bool) SiteHealth {
+	var siteOptions sites.SiteOptions
+	var auth httpclient.AuthOptions
+	var sitePath string
+
+	ctx, err := siteOptions.Apply(ctx, t.sites, &sitePath, &auth)
+	site := SiteHealth{Configured: t.sites.Default() != "", URL: sitePath}
+	if !site.Configured || skip {
+		return site
+	}
+	site.Checked = true
+	if err != nil {
+		site.Error = err.Error()
+		return site
+	}
+
+	siteURL, err := url.Parse(sitePath)
+	if err != nil {
+		site.Error = fmt.Sprintf("invalid site URL: %v", err)
+		return site
+	}
+	ctx = auth.Apply(ctx, siteURL.Host)
+
+	start := time.Now()
+	resp, err := t.httpClient.Get(ctx, siteURL.String())
+	site.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		site.Error = err.Error()
+		t.log.WarnContext(ctx, "Default site unreachable", "site", sitePath, "error", err)
+		return site
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	site.StatusCode = resp.StatusCode
+	site.Reachable = resp.StatusCode < 400
+	if !site.Reachable {
+		site.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	}
+	return site
+}
+
+// Name returns the name of the Tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the Tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*HealthRequest](registry, t, "Monitoring", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/health"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNew(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+	assert.Equal(t, "hugo_reader_health", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+}
+
+func TestTool_Execute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		defaultSite   string
+		auth          *httpclient.Credentials
+		skipSite      bool
+		failures      int
+		wantStatus    string
+		wantChecked   bool
+		wantReachable bool
+	}{
+		{name: "no default site", wantStatus: StatusOK},
+		{name: "reachable default site", defaultSite: "blog", auth: &httpclient.Credentials{BearerToken: "secret"}, wantStatus: StatusOK, wantChecked: true, wantReachable: true},
+		{name: "unauthorized default site", defaultSite: "blog", wantStatus: StatusDegraded, wantChecked: true},
+		{name: "unregistered default site", defaultSite: "docs", wantStatus: StatusDegraded, wantChecked: true},
+		{name: "skipped site check", defaultSite: "blog", skipSite: true, wantStatus: StatusOK},
+		{name: "failing tool calls", failures: 10, wantStatus: StatusDegraded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := sites.New()
+			require.NoError(t, registry.Register(sites.Site{Alias: "blog", URL: server.URL, Auth: tt.auth}))
+			require.NoError(t, registry.SetDefault(tt.defaultSite))
+
+			recorder := health.NewRecorder()
+			recorder.Record("hugo_reader_search", time.Millisecond, nil)
+			for i := 0; i < tt.failures; i++ {
+				recorder.Record("hugo_reader_search", time.Millisecond, errors.New("search failed"))
+			}
+
+			tool, err := New(WithSites(registry), WithRecorder(recorder))
+			require.NoError(t, err)
+
+			resp, err := tool.Execute(context.Background(), &HealthRequest{SkipSite: tt.skipSite})
+			require.NoError(t, err)
+
+			text := resp.Content[0].TextContent.Text
+			assert.Equal(t, tt.wantStatus, gjson.Get(text, "status").String())
+			assert.Equal(t, tt.defaultSite != "", gjson.Get(text, "default_site.configured").Bool())
+			assert.Equal(t, tt.wantChecked, gjson.Get(text, "default_site.checked").Bool())
+			assert.Equal(t, tt.wantReachable, gjson.Get(text, "default_site.reachable").Bool())
+			assert.Equal(t, int64(tt.failures+1), gjson.Get(text, "tool_calls.calls").Int())
+			assert.True(t, gjson.Get(text, "cache.total_entries").Exists())
+			assert.True(t, gjson.Get(text, "uptime_seconds").Exists())
+			assert.NotContains(t, text, "secret")
+		})
+	}
+}
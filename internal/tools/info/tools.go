@@ -1,13 +1,19 @@
 package info
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"runtime"
 	"time"
 
 	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/audit"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/buildinfo"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
 )
 
 // ToolOption is a function that configures a Tool.
@@ -21,21 +27,29 @@ type Tool struct {
 	gitCommit  string
 	buildTime  string
 	version    string
+	module     string
+	modified   bool
+	registry   *tools.Registry
+	cache      *cache.Cache
+	config     any
 }
 
 // InfoRequest represents the request parameters for the info tool.
 type InfoRequest struct {
 	IncludeRuntime bool `json:"include_runtime,omitempty" jsonschema:"title=Include Runtime Info"`
 	IncludeTools   bool `json:"include_tools,omitempty" jsonschema:"title=Include Tools List"`
+	IncludeCache   bool `json:"include_cache,omitempty" jsonschema:"title=Include Cache Statistics"`
+	IncludeConfig  bool `json:"include_config,omitempty" jsonschema:"title=Include Active Config (secrets redacted)"`
 }
 
 // New creates a new Tool.
 func New(gitCommit string, opts ...ToolOption) (*Tool, error) {
 	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_info"),
 		name:        "hugo_reader_info",
 		description: "Get version, build, and runtime information about the Hugo Reader MCP server. Useful for debugging and version verification.",
 		gitCommit:   gitCommit,
-		buildTime:   time.Now().Format(time.RFC3339), // Will be set at build time
+		buildTime:   buildinfo.Unknown,
 		version:     "1.0.0",
 	}
 	for _, opt := range opts {
@@ -55,6 +69,31 @@ func WithLogger(logger *slog.Logger) ToolOption {
 	}
 }
 
+// WithRegistry sets the registry whose enabled tools include_tools lists.
+func WithRegistry(registry *tools.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.registry = registry
+		return nil
+	}
+}
+
+// WithCache sets the cache whose statistics include_cache reports.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithConfig sets the active config include_config reports. Secrets, such
+// as passwords, tokens, custom headers and cookies, are redacted.
+func WithConfig(settings map[string]interface{}) ToolOption {
+	return func(t *Tool) error {
+		t.config = audit.Redact(settings)
+		return nil
+	}
+}
+
 // WithBuildTime sets the build time for the Tool.
 func WithBuildTime(buildTime string) ToolOption {
 	return func(t *Tool) error {
@@ -63,6 +102,17 @@ func WithBuildTime(buildTime string) ToolOption {
 	}
 }
 
+// WithBuild sets the version, build time and module of the running build.
+func WithBuild(build buildinfo.Info) ToolOption {
+	return func(t *Tool) error {
+		t.version = build.Version
+		t.buildTime = build.BuildTime
+		t.module = build.Module
+		t.modified = build.Modified
+		return nil
+	}
+}
+
 // WithVersion sets the version for the Tool.
 func WithVersion(version string) ToolOption {
 	return func(t *Tool) error {
@@ -78,19 +128,14 @@ func (r *InfoRequest) Validate() error {
 }
 
 // Execute returns version and build information.
-func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
-	// Check if logger is initialized
-	if t.log == nil {
-		t.log = slog.Default().With("tool", t.name)
-	}
-
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
 	infoRequest, ok := req.(*InfoRequest)
 	if !ok {
-		return nil, fmt.Errorf("invalid request type: %T", req)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
 	}
 
 	if err := infoRequest.Validate(); err != nil {
-		return nil, err
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
 	}
 
 	// Build basic info
@@ -101,6 +146,10 @@ func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
 		"build_time":  t.buildTime,
 		"description": "Model Control Protocol server for Hugo static sites",
 		"repository":  "https://github.com/rmrfslashbin/mcp/hugo-reader",
+		"modified":    t.modified,
+	}
+	if t.module != "" {
+		info["module"] = t.module
 	}
 
 	// Add runtime info if requested
@@ -114,46 +163,34 @@ func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
 		}
 	}
 
-	// Add tools list if requested
+	// Add the registered tools, less those disabled, with how many times
+	// each was called, if requested
 	if infoRequest.IncludeTools {
-		tools := []map[string]interface{}{
-			{
-				"name":        "hugo_reader_get_taxonomies",
-				"description": "Get all taxonomies defined in a Hugo site",
-				"purpose":     "Site structure analysis",
-			},
-			{
-				"name":        "hugo_reader_get_taxonomy_terms",
-				"description": "Get all terms for a specific taxonomy",
-				"purpose":     "Content organization exploration",
-			},
-			{
-				"name":        "hugo_reader_get_content",
-				"description": "Get content from Hugo sites by path",
-				"purpose":     "Content retrieval",
-			},
-			{
-				"name":        "hugo_reader_search",
-				"description": "Search content across Hugo sites",
-				"purpose":     "Content discovery",
-			},
-			{
-				"name":        "hugo_reader_discover_site",
-				"description": "Discover available content and structure",
-				"purpose":     "Site exploration",
-			},
-			{
-				"name":        "hugo_reader_cache_manager",
-				"description": "Manage cache for performance",
-				"purpose":     "Performance optimization",
-			},
-			{
-				"name":        "hugo_reader_info",
-				"description": "Get version and build information",
-				"purpose":     "Version management",
-			},
+		toolList := []map[string]interface{}{}
+		if t.registry != nil {
+			invocations := t.registry.Invocations()
+			for _, registered := range t.registry.Tools() {
+				toolList = append(toolList, map[string]interface{}{
+					"name":        registered.Name,
+					"description": registered.Description,
+					"purpose":     registered.Purpose,
+					"mime_type":   tools.MimeTypeJSON,
+					"annotations": registered.Annotations,
+					"invocations": invocations[registered.Name],
+				})
+			}
 		}
-		info["tools"] = tools
+		info["tools"] = toolList
+	}
+
+	// Add live cache statistics if requested
+	if infoRequest.IncludeCache && t.cache != nil {
+		info["cache"] = t.cache.Stats()
+	}
+
+	// Add the active config, redacted, if requested
+	if infoRequest.IncludeConfig && t.config != nil {
+		info["config"] = t.config
 	}
 
 	// Add MCP protocol info
@@ -168,74 +205,31 @@ func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
 		},
 	}
 
+	infoJSON, err := formatInfoSimple(info)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInternalError, err)
+	}
+
 	// Format response
 	responseData := fmt.Sprintf(`{
   "success": true,
   "info": %s,
   "timestamp": "%s",
   "errors": []
-}`, formatInfoSimple(info), time.Now().Format(time.RFC3339))
+}`, infoJSON, time.Now().Format(time.RFC3339))
 
-	t.log.Info("Info request completed", "include_runtime", infoRequest.IncludeRuntime, "include_tools", infoRequest.IncludeTools)
-	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(responseData)), nil
+	t.log.InfoContext(ctx, "Info request completed", "include_runtime", infoRequest.IncludeRuntime, "include_tools", infoRequest.IncludeTools)
+	return tools.TextResponse([]byte(responseData)), nil
 }
 
-// formatInfoSimple formats the info map as JSON
-func formatInfoSimple(info map[string]interface{}) string {
-	result := "{\n"
-	
-	// Basic info
-	result += fmt.Sprintf(`    "name": "%s",`, info["name"])
-	result += fmt.Sprintf(`\n    "version": "%s",`, info["version"])
-	result += fmt.Sprintf(`\n    "git_commit": "%s",`, info["git_commit"])
-	result += fmt.Sprintf(`\n    "build_time": "%s",`, info["build_time"])
-	result += fmt.Sprintf(`\n    "description": "%s",`, info["description"])
-	result += fmt.Sprintf(`\n    "repository": "%s"`, info["repository"])
-	
-	// Runtime info if present
-	if runtime, exists := info["runtime"]; exists {
-		if runtimeMap, ok := runtime.(map[string]interface{}); ok {
-			result += `,\n    "runtime": {`
-			result += fmt.Sprintf(`\n      "go_version": "%s",`, runtimeMap["go_version"])
-			result += fmt.Sprintf(`\n      "go_os": "%s",`, runtimeMap["go_os"])
-			result += fmt.Sprintf(`\n      "go_arch": "%s",`, runtimeMap["go_arch"])
-			result += fmt.Sprintf(`\n      "num_cpu": %d,`, runtimeMap["num_cpu"])
-			result += fmt.Sprintf(`\n      "num_goroutine": %d`, runtimeMap["num_goroutine"])
-			result += `\n    }`
-		}
-	}
-	
-	// Tools list if present
-	if tools, exists := info["tools"]; exists {
-		if toolsList, ok := tools.([]map[string]interface{}); ok {
-			result += `,\n    "tools": [`
-			for i, tool := range toolsList {
-				if i > 0 {
-					result += `,`
-				}
-				result += fmt.Sprintf(`\n      {
-        "name": "%s",
-        "description": "%s",
-        "purpose": "%s"
-      }`, tool["name"], tool["description"], tool["purpose"])
-			}
-			result += `\n    ]`
-		}
+// formatInfoSimple formats the info map as JSON, indented to nest in the
+// response
+func formatInfoSimple(info map[string]interface{}) (string, error) {
+	data, err := json.MarshalIndent(info, "  ", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode info: %w", err)
 	}
-	
-	// MCP info
-	if mcp, exists := info["mcp"]; exists {
-		if mcpMap, ok := mcp.(map[string]interface{}); ok {
-			result += `,\n    "mcp": {`
-			result += fmt.Sprintf(`\n      "protocol_version": "%s",`, mcpMap["protocol_version"])
-			result += fmt.Sprintf(`\n      "transport": "%s",`, mcpMap["transport"])
-			result += `\n      "capabilities": ["tool_execution", "caching", "error_handling", "request_validation"]`
-			result += `\n    }`
-		}
-	}
-	
-	result += `\n  }`
-	return result
+	return string(data), nil
 }
 
 // Name returns the name of the tool.
@@ -248,6 +242,11 @@ func (t *Tool) Description() string {
 	return t.description
 }
 
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*InfoRequest](registry, t, "Version management", tools.Annotations{ReadOnlyHint: true, IdempotentHint: true})
+}
+
 // SetLogger sets the logger for the Tool.
 func (t *Tool) SetLogger(logger *slog.Logger) {
 	if logger == nil {
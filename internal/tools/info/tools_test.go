@@ -1,11 +1,18 @@
 package info
 
 import (
+	"context"
+	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/buildinfo"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 )
 
 func TestNew(t *testing.T) {
@@ -28,6 +35,25 @@ func TestNewWithOptions(t *testing.T) {
 	assert.Equal(t, "2023-01-01T12:00:00Z", tool.buildTime)
 }
 
+func TestNewWithBuild(t *testing.T) {
+	tool, err := New("abc123", WithBuild(buildinfo.Info{
+		Version:   "v1.2.3",
+		GitCommit: "abc123",
+		BuildTime: "2025-01-15T09:00:00Z",
+		Modified:  true,
+		Module:    "github.com/rmrfslashbin/mcp/hugo-reader",
+	}))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &InfoRequest{})
+	require.NoError(t, err)
+	text := resp.Content[0].TextContent.Text
+	assert.Equal(t, "v1.2.3", gjson.Get(text, "info.version").String())
+	assert.Equal(t, "2025-01-15T09:00:00Z", gjson.Get(text, "info.build_time").String())
+	assert.True(t, gjson.Get(text, "info.modified").Bool())
+	assert.Equal(t, "github.com/rmrfslashbin/mcp/hugo-reader", gjson.Get(text, "info.module").String())
+}
+
 func TestInfoRequest_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -90,7 +116,9 @@ func TestFormatInfoSimple(t *testing.T) {
 		"repository":  "https://github.com/test/repo",
 	}
 
-	result := formatInfoSimple(info)
+	result, err := formatInfoSimple(info)
+	require.NoError(t, err)
+	assert.True(t, json.Valid([]byte(result)))
 	
 	// Check that all basic fields are present
 	assert.Contains(t, result, `"name": "Test Server"`)
@@ -102,7 +130,7 @@ func TestFormatInfoSimple(t *testing.T) {
 	
 	// Check JSON structure
 	assert.True(t, strings.HasPrefix(result, "{\n"))
-	assert.True(t, strings.HasSuffix(result, "\\n  }"))
+	assert.True(t, strings.HasSuffix(result, "\n  }"))
 }
 
 func TestFormatInfoSimpleWithRuntime(t *testing.T) {
@@ -122,7 +150,9 @@ func TestFormatInfoSimpleWithRuntime(t *testing.T) {
 		},
 	}
 
-	result := formatInfoSimple(info)
+	result, err := formatInfoSimple(info)
+	require.NoError(t, err)
+	assert.True(t, json.Valid([]byte(result)))
 	
 	// Check runtime fields
 	assert.Contains(t, result, `"runtime": {`)
@@ -133,30 +163,53 @@ func TestFormatInfoSimpleWithRuntime(t *testing.T) {
 	assert.Contains(t, result, `"num_goroutine": 5`)
 }
 
-func TestFormatInfoSimpleWithTools(t *testing.T) {
-	info := map[string]interface{}{
-		"name":        "Test Server",
-		"version":     "1.0.0",
-		"git_commit":  "abc123",
-		"build_time":  "2023-01-01T12:00:00Z",
-		"description": "Test description",
-		"repository":  "https://github.com/test/repo",
-		"tools": []map[string]interface{}{
-			{
-				"name":        "test_tool",
-				"description": "Test tool description",
-				"purpose":     "Testing",
-			},
-		},
-	}
+func TestTool_Execute_Tools(t *testing.T) {
+	registry := tools.NewRegistry()
+	tool, err := New("abc123", WithRegistry(registry))
+	require.NoError(t, err)
+	tool.Register(registry)
+
+	resp, err := tool.Execute(context.Background(), &InfoRequest{IncludeTools: true})
+	require.NoError(t, err)
+	text := resp.Content[0].TextContent.Text
+	require.True(t, json.Valid([]byte(text)))
 
-	result := formatInfoSimple(info)
-	
 	// Check tools section
-	assert.Contains(t, result, `"tools": [`)
-	assert.Contains(t, result, `"name": "test_tool"`)
-	assert.Contains(t, result, `"description": "Test tool description"`)
-	assert.Contains(t, result, `"purpose": "Testing"`)
+	listed := gjson.Get(text, "info.tools.0")
+	assert.Equal(t, "hugo_reader_info", listed.Get("name").String())
+	assert.Equal(t, tool.Description(), listed.Get("description").String())
+	assert.Equal(t, "Version management", listed.Get("purpose").String())
+	assert.Equal(t, "application/json", listed.Get("mime_type").String())
+	assert.Equal(t, int64(0), listed.Get("invocations").Int())
+	assert.JSONEq(t, `{"readOnlyHint":true,"destructiveHint":false,"idempotentHint":true,"openWorldHint":false}`, listed.Get("annotations").Raw)
+}
+
+func TestTool_Execute_CacheAndConfig(t *testing.T) {
+	tool, err := New("abc123",
+		WithCache(cache.New(cache.WithTTL(time.Minute))),
+		WithConfig(map[string]interface{}{
+			"cache_ttl":   "5m",
+			"auth":        map[string]interface{}{"docs.example.com": map[string]interface{}{"bearer_token": "abc123"}},
+			"webhook_url": "https://hooks.slack.com/services/T0/B0/SECRETTOKEN",
+		}),
+	)
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &InfoRequest{})
+	require.NoError(t, err)
+	text := resp.Content[0].TextContent.Text
+	assert.False(t, gjson.Get(text, "info.cache").Exists())
+	assert.False(t, gjson.Get(text, "info.config").Exists())
+
+	resp, err = tool.Execute(context.Background(), &InfoRequest{IncludeCache: true, IncludeConfig: true})
+	require.NoError(t, err)
+	text = resp.Content[0].TextContent.Text
+	assert.Equal(t, int64(0), gjson.Get(text, "info.cache.total_entries").Int())
+	assert.Equal(t, "1m0s", gjson.Get(text, "info.cache.default_ttl").String())
+	assert.Equal(t, "5m", gjson.Get(text, "info.config.cache_ttl").String())
+	assert.Equal(t, "[REDACTED]", gjson.Get(text, "info.config.auth.docs\\.example\\.com.bearer_token").String())
+	assert.Equal(t, "[REDACTED]", gjson.Get(text, "info.config.webhook_url").String())
+	assert.NotContains(t, text, "SECRETTOKEN")
 }
 
 func TestTool_SetLogger(t *testing.T) {
@@ -169,4 +222,22 @@ func TestTool_SetLogger(t *testing.T) {
 
 	// Test that it doesn't panic with valid logger
 	// We can't easily test the logger content without more setup
-}
\ No newline at end of file
+}
+func TestTool_Execute_ListsEnabledTools(t *testing.T) {
+	registry := tools.NewRegistry("hugo_reader_info")
+	tool, err := New("abc123", WithRegistry(registry))
+	require.NoError(t, err)
+	tool.Register(registry)
+
+	resp, err := tool.Execute(context.Background(), &InfoRequest{IncludeTools: true})
+	require.NoError(t, err)
+	assert.Contains(t, resp.Content[0].TextContent.Text, `"tools": [`)
+	assert.NotContains(t, resp.Content[0].TextContent.Text, `"name": "hugo_reader_info"`)
+
+	registry = tools.NewRegistry()
+	tool.registry = registry
+	tool.Register(registry)
+	resp, err = tool.Execute(context.Background(), &InfoRequest{IncludeTools: true})
+	require.NoError(t, err)
+	assert.Contains(t, resp.Content[0].TextContent.Text, `"name": "hugo_reader_info"`)
+}
@@ -0,0 +1,488 @@
+package linkcheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/pagelinks"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+)
+
+const (
+	// maxConcurrency bounds how many links are checked at once
+	maxConcurrency = 16
+
+	// defaultHostDelay spaces requests to the same host
+	defaultHostDelay = 250 * time.Millisecond
+
+	// maxHostDelay bounds host_delay
+	maxHostDelay = 10 * time.Second
+)
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool checks the links on Hugo site pages for broken targets.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// CheckLinksRequest represents the request parameters for the link checker.
+type CheckLinksRequest struct {
+	HugoSitePath  string   `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	Paths         []string `json:"paths" jsonschema:"title=Page Paths,minItems=1,maxItems=20"`
+	CheckExternal bool     `json:"check_external,omitempty" jsonschema:"title=Also check links to other sites"`
+	OnlyBroken    bool     `json:"only_broken,omitempty" jsonschema:"title=Only report broken links"`
+	Limit         int      `json:"limit,omitempty" jsonschema:"title=Maximum links to check,minimum=1,maximum=1000"`
+	Concurrency   int      `json:"concurrency,omitempty" jsonschema:"title=Links checked at once,minimum=1,maximum=16"`
+	HostDelay     string   `json:"host_delay,omitempty" jsonschema:"title=Minimum delay between requests to the same host (e.g. 250ms)"`
+
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+
+	hostDelay time.Duration
+}
+
+// target is a link target and the pages that link to it
+type target struct {
+	url     string
+	kind    string
+	foundOn []string
+}
+
+// Result is the outcome of checking one link target
+type Result struct {
+	URL        string   `json:"url"`
+	Type       string   `json:"type"`
+	Status     int      `json:"status,omitempty"`
+	OK         bool     `json:"ok"`
+	Redirected bool     `json:"redirected,omitempty"`
+	FinalURL   string   `json:"final_url,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	FoundOn    []string `json:"found_on"`
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_check_links"),
+		name:        "hugo_reader_check_links",
+		description: "Check the links on one or more pages of a Hugo site for broken targets. Internal links are checked with HEAD requests (falling back to GET), and external links too with check_external. Reports status codes, redirects and errors per link, with a summary, spacing requests to each host.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *CheckLinksRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+	if len(r.Paths) == 0 {
+		return fmt.Errorf("at least one path is required")
+	}
+	if len(r.Paths) > 20 {
+		return fmt.Errorf("at most 20 paths can be checked at once")
+	}
+	for _, path := range r.Paths {
+		if u, err := url.Parse(path); err != nil || u.IsAbs() || u.Host != "" || strings.Contains(path, "..") {
+			return fmt.Errorf("invalid path: %s (must be relative to the site)", path)
+		}
+	}
+
+	if r.Limit == 0 {
+		r.Limit = 200
+	} else if r.Limit < 1 || r.Limit > 1000 {
+		return fmt.Errorf("limit must be between 1 and 1000")
+	}
+
+	if r.Concurrency == 0 {
+		r.Concurrency = 4
+	} else if r.Concurrency < 1 || r.Concurrency > maxConcurrency {
+		return fmt.Errorf("concurrency must be between 1 and %d", maxConcurrency)
+	}
+
+	r.hostDelay = defaultHostDelay
+	if r.HostDelay != "" {
+		delay, err := time.ParseDuration(r.HostDelay)
+		if err != nil {
+			return fmt.Errorf("invalid host_delay: %w", err)
+		}
+		if delay < 0 || delay > maxHostDelay {
+			return fmt.Errorf("host_delay must be between 0s and %s", maxHostDelay)
+		}
+		r.hostDelay = delay
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// Execute checks the links on the requested pages.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	checkRequest, ok := req.(*CheckLinksRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := checkRequest.SiteOptions.Apply(ctx, t.sites, &checkRequest.HugoSitePath, &checkRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := checkRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := checkRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = checkRequest.CacheOptions.Apply(ctx, 0)
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(checkRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", checkRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = checkRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	targets, skipped, errors := t.collectTargets(ctx, siteURL, checkRequest)
+	if len(errors) == len(checkRequest.Paths) {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "no pages could be read at Hugo site: %s", checkRequest.HugoSitePath)
+	}
+
+	truncated := len(targets) > checkRequest.Limit
+	if truncated {
+		targets = targets[:checkRequest.Limit]
+	}
+
+	results := t.checkTargets(ctx, targets, checkRequest.Concurrency, checkRequest.hostDelay)
+	if err := ctx.Err(); err != nil {
+		t.log.WarnContext(ctx, "Link check cancelled", "site", checkRequest.HugoSitePath, "error", err)
+		return nil, fmt.Errorf("link check cancelled: %w", err)
+	}
+
+	summary := summarize(results)
+	summary["skipped_external"] = skipped
+	summary["truncated"] = truncated
+
+	// Broken links first
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].OK != results[j].OK {
+			return !results[i].OK
+		}
+		return results[i].URL < results[j].URL
+	})
+	if checkRequest.OnlyBroken {
+		broken := []Result{}
+		for _, result := range results {
+			if !result.OK {
+				broken = append(broken, result)
+			}
+		}
+		results = broken
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"results": results,
+		"summary": summary,
+		"metadata": map[string]interface{}{
+			"pages_requested": len(checkRequest.Paths),
+			"pages_read":      len(checkRequest.Paths) - len(errors),
+			"check_external":  checkRequest.CheckExternal,
+			"limit":           checkRequest.Limit,
+			"site_source":     sites.Source(ctx),
+		},
+		"errors": errors,
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal link check results", "error", err)
+		return nil, fmt.Errorf("failed to marshal link check results: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Checked links", "site", checkRequest.HugoSitePath, "checked", summary["checked"], "broken", summary["broken"])
+	return tools.TextResponse(responseJSON), nil
+}
+
+// collectTargets reads the links of each page, returning each target to
+// check once in the order first found, how many external links were
+// skipped, and an error for each page that could not be read
+func (t *Tool) collectTargets(ctx context.Context, siteURL *url.URL, req *CheckLinksRequest) ([]*target, int, []toolerrors.ErrorDetail) {
+	// The site index is optional; without one rendered pages are read
+	index, err := hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		t.log.DebugContext(ctx, "Site index unavailable", "site", siteURL.String(), "error", err)
+		index = nil
+	}
+
+	var targets []*target
+	byURL := make(map[string]*target)
+	skipped := 0
+	errors := []toolerrors.ErrorDetail{}
+
+	for _, path := range req.Paths {
+		pageURL := pagelinks.PageURL(siteURL, path)
+		body, err := pagelinks.Fetch(ctx, t.cache, t.httpClient, index, siteURL, pageURL)
+		if err != nil {
+			t.log.WarnContext(ctx, "Failed to read page", "path", path, "error", err)
+			errors = append(errors, toolerrors.FromError(err, map[string]interface{}{"path": path}))
+			continue
+		}
+
+		for _, link := range pagelinks.Resolve(htmltext.Links(body.Content), siteURL, pageURL, true) {
+			linkURL, err := url.Parse(link.URL)
+			if err != nil || (linkURL.Scheme != "http" && linkURL.Scheme != "https") || link.Type == pagelinks.Anchor {
+				continue
+			}
+			if link.Type == pagelinks.External && !req.CheckExternal {
+				skipped++
+				continue
+			}
+
+			// Fragments are not sent to servers
+			linkURL.Fragment = ""
+			key := linkURL.String()
+			if existing, ok := byURL[key]; ok {
+				if existing.foundOn[len(existing.foundOn)-1] != pageURL.Path {
+					existing.foundOn = append(existing.foundOn, pageURL.Path)
+				}
+				continue
+			}
+			byURL[key] = &target{url: key, kind: link.Type, foundOn: []string{pageURL.Path}}
+			targets = append(targets, byURL[key])
+		}
+	}
+	return targets, skipped, errors
+}
+
+// checkTargets checks targets a bounded number at a time, spacing requests
+// to each host by delay
+func (t *Tool) checkTargets(ctx context.Context, targets []*target, concurrency int, delay time.Duration) []Result {
+	results := make([]Result, len(targets))
+	limiter := newHostLimiter(delay)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, tgt := range targets {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return results
+		}
+
+		wg.Add(1)
+		go func(i int, tgt *target) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = t.check(ctx, limiter, tgt)
+		}(i, tgt)
+	}
+	wg.Wait()
+	return results
+}
+
+// check requests a target with HEAD, retrying with GET for servers that
+// do not support HEAD
+func (t *Tool) check(ctx context.Context, limiter *hostLimiter, tgt *target) Result {
+	result := Result{URL: tgt.url, Type: tgt.kind, FoundOn: tgt.foundOn}
+
+	resp, err := t.request(ctx, limiter, http.MethodHead, tgt.url)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = t.request(ctx, limiter, http.MethodGet, tgt.url)
+	}
+	if err != nil {
+		t.log.DebugContext(ctx, "Link check failed", "url", tgt.url, "error", err)
+		result.Error = err.Error()
+		return result
+	}
+	resp.Body.Close()
+
+	result.Status = resp.StatusCode
+	result.OK = resp.StatusCode < 400
+	if final := resp.Request.URL.String(); final != tgt.url {
+		result.Redirected = true
+		result.FinalURL = final
+	}
+	return result
+}
+
+func (t *Tool) request(ctx context.Context, limiter *hostLimiter, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := limiter.wait(ctx, req.URL.Host); err != nil {
+		return nil, err
+	}
+	return t.httpClient.Do(req)
+}
+
+// summarize counts the results by outcome and status code
+func summarize(results []Result) map[string]interface{} {
+	ok, broken, redirected, failed := 0, 0, 0, 0
+	byStatus := make(map[string]int)
+	for _, result := range results {
+		if result.OK {
+			ok++
+		} else {
+			broken++
+		}
+		if result.Redirected {
+			redirected++
+		}
+		if result.Error != "" {
+			failed++
+		} else {
+			byStatus[strconv.Itoa(result.Status)]++
+		}
+	}
+	return map[string]interface{}{
+		"checked":    len(results),
+		"ok":         ok,
+		"broken":     broken,
+		"redirected": redirected,
+		"failed":     failed,
+		"by_status":  byStatus,
+	}
+}
+
+// hostLimiter spaces requests to each host by a minimum delay
+type hostLimiter struct {
+	mu    sync.Mutex
+	delay time.Duration
+	next  map[string]time.Time
+}
+
+func newHostLimiter(delay time.Duration) *hostLimiter {
+	return &hostLimiter{delay: delay, next: make(map[string]time.Time)}
+}
+
+// wait blocks until a request to host may be sent
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	at := l.next[host]
+	if now := time.Now(); at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(l.delay)
+	l.mu.Unlock()
+
+	wait := time.Until(at)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*CheckLinksRequest](registry, t, "Content audits", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
@@ -0,0 +1,185 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNew(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+	assert.NotNil(t, tool)
+	assert.Equal(t, "hugo_reader_check_links", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+	assert.NotNil(t, tool.cache)
+	assert.NotNil(t, tool.httpClient)
+}
+
+func TestCheckLinksRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *CheckLinksRequest
+		wantErr bool
+	}{
+		{
+			name:    "valid request with defaults",
+			req:     &CheckLinksRequest{HugoSitePath: "https://example.com", Paths: []string{"/posts/a/"}},
+			wantErr: false,
+		},
+		{
+			name:    "all options",
+			req:     &CheckLinksRequest{HugoSitePath: "https://example.com", Paths: []string{"about"}, CheckExternal: true, Limit: 1000, Concurrency: 16, HostDelay: "1s"},
+			wantErr: false,
+		},
+		{
+			name:    "missing hugo_site_path",
+			req:     &CheckLinksRequest{Paths: []string{"/posts/a/"}},
+			wantErr: true,
+		},
+		{
+			name:    "missing paths",
+			req:     &CheckLinksRequest{HugoSitePath: "https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "absolute path",
+			req:     &CheckLinksRequest{HugoSitePath: "https://example.com", Paths: []string{"https://other.example/"}},
+			wantErr: true,
+		},
+		{
+			name:    "concurrency too high",
+			req:     &CheckLinksRequest{HugoSitePath: "https://example.com", Paths: []string{"/a/"}, Concurrency: 17},
+			wantErr: true,
+		},
+		{
+			name:    "invalid host_delay",
+			req:     &CheckLinksRequest{HugoSitePath: "https://example.com", Paths: []string{"/a/"}, HostDelay: "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "host_delay too long",
+			req:     &CheckLinksRequest{HugoSitePath: "https://example.com", Paths: []string{"/a/"}, HostDelay: "1m"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Greater(t, tt.req.Limit, 0)
+				assert.Greater(t, tt.req.Concurrency, 0)
+			}
+		})
+	}
+}
+
+func TestHostLimiter(t *testing.T) {
+	limiter := newHostLimiter(50 * time.Millisecond)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.wait(context.Background(), "example.com"))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	// Other hosts are not held up
+	start = time.Now()
+	require.NoError(t, limiter.wait(context.Background(), "other.example"))
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Error(t, limiter.wait(ctx, "example.com"))
+}
+
+func TestTool_Execute(t *testing.T) {
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer external.Close()
+
+	var site *httptest.Server
+	site = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/posts/a/":
+			w.Write([]byte(`<html><body><main>
+				<a href="/posts/b/">B</a>
+				<a href="/posts/b/#part">B part</a>
+				<a href="/old/">Old</a>
+				<a href="/missing/">Missing</a>
+				<a href="/no-head/">No HEAD</a>
+				<a href="#top">Top</a>
+				<a href="mailto:me@example.com">Mail</a>
+				<a href="` + external.URL + `/ok">OK</a>
+				<a href="` + external.URL + `/gone">Gone</a>
+			</main></body></html>`))
+		case "/posts/b/":
+			w.Write([]byte(`<html><body><main><a href="/posts/a/">A</a></main></body></html>`))
+		case "/old/":
+			http.Redirect(w, r, "/posts/b/", http.StatusMovedPermanently)
+		case "/no-head/":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Write([]byte("ok"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer site.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &CheckLinksRequest{HugoSitePath: site.URL, Paths: []string{"/posts/a/", "/posts/b/"}, HostDelay: "0s"})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+
+	assert.Equal(t, int64(5), result.Get("summary.checked").Int())
+	assert.Equal(t, int64(1), result.Get("summary.broken").Int())
+	assert.Equal(t, int64(1), result.Get("summary.redirected").Int())
+	assert.Equal(t, int64(2), result.Get("summary.skipped_external").Int())
+	assert.Equal(t, site.URL+"/missing/", result.Get("results.0.url").String())
+	assert.Equal(t, int64(404), result.Get("results.0.status").Int())
+
+	old := result.Get(`results.#(url=="` + site.URL + `/old/")`)
+	assert.True(t, old.Get("ok").Bool())
+	assert.Equal(t, site.URL+"/posts/b/", old.Get("final_url").String())
+	assert.Equal(t, int64(200), result.Get(`results.#(url=="`+site.URL+`/no-head/").status`).Int())
+	assert.Equal(t, `["/posts/a/"]`, result.Get(`results.#(url=="`+site.URL+`/posts/b/").found_on`).Raw)
+	assert.Equal(t, `["/posts/b/"]`, result.Get(`results.#(url=="`+site.URL+`/posts/a/").found_on`).Raw)
+
+	// External links on request, reporting only broken ones
+	resp, err = tool.Execute(context.Background(), &CheckLinksRequest{HugoSitePath: site.URL, Paths: []string{"/posts/a/", "/missing-page/"}, CheckExternal: true, OnlyBroken: true, HostDelay: "0s"})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	var brokenURLs []string
+	for _, u := range result.Get("results.#.url").Array() {
+		brokenURLs = append(brokenURLs, u.String())
+	}
+	assert.ElementsMatch(t, []string{external.URL + "/gone", site.URL + "/missing/"}, brokenURLs)
+	assert.Equal(t, "external", result.Get(`results.#(url=="`+external.URL+`/gone").type`).String())
+	assert.Equal(t, int64(1), result.Get("errors.#").Int())
+	assert.Equal(t, toolerrors.ErrCodeNotFound, result.Get("errors.0.code").String())
+	assert.Equal(t, "/missing-page/", result.Get("errors.0.context.path").String())
+
+	_, err = tool.Execute(context.Background(), &CheckLinksRequest{HugoSitePath: site.URL, Paths: []string{"/missing-page/"}})
+	assert.Error(t, err)
+	assert.Equal(t, toolerrors.ErrCodeNotFound, toolerrors.Code(err))
+}
@@ -0,0 +1,255 @@
+package links
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/pagelinks"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+)
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool extracts the outbound links of a page on a Hugo site.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// LinksRequest represents the request parameters for extracting links.
+type LinksRequest struct {
+	HugoSitePath string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	Path         string `json:"path" jsonschema:"title=Page Path (e.g. /posts/my-post/)"`
+	Type         string `json:"type,omitempty" jsonschema:"title=Link Type Filter,enum=internal,enum=external,enum=anchor"`
+	Unique       bool   `json:"unique,omitempty" jsonschema:"title=List each target only once"`
+	Limit        int    `json:"limit,omitempty" jsonschema:"title=Result Limit,minimum=1,maximum=1000"`
+
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_extract_links"),
+		name:        "hugo_reader_extract_links",
+		description: "Extract the outbound links of a page on a Hugo site, classified as internal, external or anchor links, with their anchor text and, for internal links, the target section. Reads the page body from the site index when it holds HTML, otherwise the rendered page. Use it to follow a site's link graph.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *LinksRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+	if r.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if u, err := url.Parse(r.Path); err != nil || u.IsAbs() || u.Host != "" {
+		return fmt.Errorf("invalid path: %s (must be relative to the site)", r.Path)
+	}
+	for _, part := range strings.Split(r.Path, "/") {
+		if part == ".." {
+			return fmt.Errorf("invalid path: %s", r.Path)
+		}
+	}
+
+	switch r.Type {
+	case "", pagelinks.Internal, pagelinks.External, pagelinks.Anchor:
+	default:
+		return fmt.Errorf("invalid type: %s (must be: internal, external, or anchor)", r.Type)
+	}
+
+	if r.Limit == 0 {
+		r.Limit = 200
+	} else if r.Limit < 1 || r.Limit > 1000 {
+		return fmt.Errorf("limit must be between 1 and 1000")
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// Execute extracts the links of a page.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	linksRequest, ok := req.(*LinksRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := linksRequest.SiteOptions.Apply(ctx, t.sites, &linksRequest.HugoSitePath, &linksRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := linksRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := linksRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = linksRequest.CacheOptions.Apply(ctx, 0)
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(linksRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", linksRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = linksRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	pageURL := pagelinks.PageURL(siteURL, linksRequest.Path)
+
+	// The site index is optional; without one the rendered page is read
+	index, err := hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		t.log.DebugContext(ctx, "Site index unavailable", "site", linksRequest.HugoSitePath, "error", err)
+		index = nil
+	}
+
+	body, err := pagelinks.Fetch(ctx, t.cache, t.httpClient, index, siteURL, pageURL)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Page not found", "site", linksRequest.HugoSitePath, "path", linksRequest.Path, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "page '%s' not found at Hugo site: %s", linksRequest.Path, linksRequest.HugoSitePath)
+	}
+
+	links := pagelinks.Resolve(htmltext.Links(body.Content), siteURL, pageURL, linksRequest.Unique)
+	counts := pagelinks.Count(links)
+
+	filtered := []pagelinks.Link{}
+	for _, link := range links {
+		if linksRequest.Type != "" && link.Type != linksRequest.Type {
+			continue
+		}
+		filtered = append(filtered, link)
+	}
+	total := len(filtered)
+	if len(filtered) > linksRequest.Limit {
+		filtered = filtered[:linksRequest.Limit]
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"path":    linksRequest.Path,
+		"links":   filtered,
+		"metadata": map[string]interface{}{
+			"source":          body.Source,
+			"source_endpoint": body.Endpoint,
+			"cached":          body.Cached,
+			"total_links":     total,
+			"returned":        len(filtered),
+			"truncated":       total > len(filtered),
+			"counts":          counts,
+			"site_source":     sites.Source(ctx),
+		},
+		"errors": []string{},
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal links", "error", err)
+		return nil, fmt.Errorf("failed to marshal links: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Extracted links", "site", linksRequest.HugoSitePath, "path", linksRequest.Path, "source", body.Source, "total", total)
+	return tools.TextResponse(responseJSON), nil
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*LinksRequest](registry, t, "Site exploration", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
@@ -0,0 +1,123 @@
+package links
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/pagelinks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNew(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+	assert.NotNil(t, tool)
+	assert.Equal(t, "hugo_reader_extract_links", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+	assert.NotNil(t, tool.cache)
+	assert.NotNil(t, tool.httpClient)
+}
+
+func TestLinksRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *LinksRequest
+		wantErr bool
+	}{
+		{
+			name:    "valid request with defaults",
+			req:     &LinksRequest{HugoSitePath: "https://example.com", Path: "/posts/my-post/"},
+			wantErr: false,
+		},
+		{
+			name:    "type filter",
+			req:     &LinksRequest{HugoSitePath: "https://example.com", Path: "about", Type: pagelinks.External},
+			wantErr: false,
+		},
+		{
+			name:    "missing hugo_site_path",
+			req:     &LinksRequest{Path: "/about/"},
+			wantErr: true,
+		},
+		{
+			name:    "missing path",
+			req:     &LinksRequest{HugoSitePath: "https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "absolute URL path",
+			req:     &LinksRequest{HugoSitePath: "https://example.com", Path: "https://other.example/"},
+			wantErr: true,
+		},
+		{
+			name:    "path escaping the site",
+			req:     &LinksRequest{HugoSitePath: "https://example.com", Path: "/posts/../../etc/"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid type",
+			req:     &LinksRequest{HugoSitePath: "https://example.com", Path: "/about/", Type: "broken"},
+			wantErr: true,
+		},
+		{
+			name:    "limit too high",
+			req:     &LinksRequest{HugoSitePath: "https://example.com", Path: "/about/", Limit: 1001},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Greater(t, tt.req.Limit, 0)
+			}
+		})
+	}
+}
+
+func TestTool_Execute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			w.Write([]byte(`[
+				{"title": "Indexed", "url": "/posts/indexed/", "content": "<p>See <a href=\"/posts/rendered/\">rendered</a> and <a href=\"https://gohugo.io/\">Hugo</a>.</p>"},
+				{"title": "Rendered", "url": "/posts/rendered/", "content": "Plain text only"}
+			]`))
+		case "/posts/rendered/":
+			w.Write([]byte(`<html><body><nav><a href="/">Home</a></nav><article><p><a href="../indexed/">Back</a> <a href="#top">Top</a></p></article></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	// HTML in the site index is used directly
+	resp, err := tool.Execute(context.Background(), &LinksRequest{HugoSitePath: server.URL, Path: "/posts/indexed/"})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, pagelinks.SourceSiteIndex, result.Get("metadata.source").String())
+	assert.Equal(t, `["internal","external"]`, result.Get("links.#.type").Raw)
+	assert.Equal(t, "rendered", result.Get("links.0.text").String())
+
+	// Otherwise the rendered page's main content is read
+	resp, err = tool.Execute(context.Background(), &LinksRequest{HugoSitePath: server.URL, Path: "posts/rendered", Type: pagelinks.Internal})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, pagelinks.SourcePageHTML, result.Get("metadata.source").String())
+	assert.Equal(t, `["/posts/indexed/"]`, result.Get("links.#.path").Raw)
+	assert.Equal(t, int64(1), result.Get("metadata.counts.anchor").Int())
+
+	_, err = tool.Execute(context.Background(), &LinksRequest{HugoSitePath: server.URL, Path: "/missing/"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,131 @@
+package loglevel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/logging"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+)
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool changes the level the server logs at while it runs.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	level       *slog.LevelVar
+}
+
+// SetLogLevelRequest represents the request parameters for the log level tool.
+type SetLogLevelRequest struct {
+	Level string `json:"level,omitempty" jsonschema:"enum=debug,enum=info,enum=warn,enum=error,title=Log Level"`
+}
+
+// New creates a new Tool changing level.
+func New(level *slog.LevelVar, opts ...ToolOption) (*Tool, error) {
+	if level == nil {
+		return nil, fmt.Errorf("level is required")
+	}
+
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_set_log_level"),
+		name:        "hugo_reader_set_log_level",
+		description: "Change the level the server logs at (debug, info, warn or error) without restarting it, such as to log every fetch at debug while reproducing a failure against a site. Omit level to report the current level. The change lasts until the server restarts.",
+		level:       level,
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *SetLogLevelRequest) Validate() error {
+	if r.Level == "" {
+		return nil
+	}
+	_, err := logging.ParseLevel(r.Level)
+	return err
+}
+
+// Execute sets the log level, reporting the level it replaced.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	levelRequest, ok := req.(*SetLogLevelRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	if err := levelRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	previous := t.level.Level()
+	current := previous
+	if levelRequest.Level != "" {
+		current, _ = logging.ParseLevel(levelRequest.Level)
+		t.level.Set(current)
+	}
+
+	response := map[string]interface{}{
+		"success":        true,
+		"level":          logging.LevelName(current),
+		"previous_level": logging.LevelName(previous),
+		"changed":        current != previous,
+		"errors":         []string{},
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal log level", "error", err)
+		return nil, fmt.Errorf("failed to marshal log level: %w", err)
+	}
+
+	// Logged at warn so the change is seen at every level
+	if current != previous {
+		t.log.WarnContext(ctx, "Changed log level", "level", logging.LevelName(current), "previous_level", logging.LevelName(previous))
+	}
+	return tools.TextResponse(responseJSON), nil
+}
+
+// Name returns the name of the Tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the Tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*SetLogLevelRequest](registry, t, "Debugging", tools.Annotations{IdempotentHint: true})
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
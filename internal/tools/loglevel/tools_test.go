@@ -0,0 +1,53 @@
+package loglevel
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNew(t *testing.T) {
+	tool, err := New(&slog.LevelVar{})
+	require.NoError(t, err)
+	assert.Equal(t, "hugo_reader_set_log_level", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+
+	_, err = New(nil)
+	assert.Error(t, err)
+}
+
+func TestSetLogLevelRequest_Validate(t *testing.T) {
+	assert.NoError(t, (&SetLogLevelRequest{}).Validate())
+	assert.NoError(t, (&SetLogLevelRequest{Level: "debug"}).Validate())
+	assert.NoError(t, (&SetLogLevelRequest{Level: "WARN"}).Validate())
+	assert.Error(t, (&SetLogLevelRequest{Level: "verbose"}).Validate())
+}
+
+func TestTool_Execute(t *testing.T) {
+	level := &slog.LevelVar{}
+	tool, err := New(level, WithLogger(slog.New(slog.DiscardHandler)))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &SetLogLevelRequest{Level: "debug"})
+	require.NoError(t, err)
+	text := resp.Content[0].TextContent.Text
+	assert.Equal(t, "debug", gjson.Get(text, "level").String())
+	assert.Equal(t, "info", gjson.Get(text, "previous_level").String())
+	assert.True(t, gjson.Get(text, "changed").Bool())
+	assert.Equal(t, slog.LevelDebug, level.Level())
+
+	// Without a level the current one is reported
+	resp, err = tool.Execute(context.Background(), &SetLogLevelRequest{})
+	require.NoError(t, err)
+	text = resp.Content[0].TextContent.Text
+	assert.Equal(t, "debug", gjson.Get(text, "level").String())
+	assert.False(t, gjson.Get(text, "changed").Bool())
+
+	_, err = tool.Execute(context.Background(), &SetLogLevelRequest{Level: "verbose"})
+	assert.Error(t, err)
+	assert.Equal(t, slog.LevelDebug, level.Level())
+}
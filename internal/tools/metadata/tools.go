@@ -0,0 +1,346 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/enrich"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/tidwall/gjson"
+)
+
+// Metadata sources
+const (
+	SourceSiteIndex    = "site_index"
+	SourcePageEndpoint = "page_endpoint"
+)
+
+// maxPaths bounds how many pages one request may describe
+const maxPaths = 100
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool returns the metadata of Hugo pages without their bodies.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// MetadataRequest represents the request parameters for the metadata tool.
+type MetadataRequest struct {
+	HugoSitePath string   `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	Paths        []string `json:"paths" jsonschema:"title=Page Paths,minItems=1,maxItems=100"`
+
+	hugoindex.PublishOptions
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_get_page_metadata"),
+		name:        "hugo_reader_get_page_metadata",
+		description: "Get lightweight metadata for Hugo pages by path: title, date, lastmod, word count, section and taxonomies, without returning their bodies. Reads the site's index.json first and only fetches page endpoints for pages it does not list. Use it to check pages cheaply before retrieving their content.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *MetadataRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+	if len(r.Paths) == 0 {
+		return fmt.Errorf("at least one path is required")
+	}
+	if len(r.Paths) > maxPaths {
+		return fmt.Errorf("at most %d paths may be requested", maxPaths)
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// Execute returns the metadata of the requested pages.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	metadataRequest, ok := req.(*MetadataRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := metadataRequest.SiteOptions.Apply(ctx, t.sites, &metadataRequest.HugoSitePath, &metadataRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := metadataRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := metadataRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = metadataRequest.CacheOptions.Apply(ctx, 0)
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(metadataRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", metadataRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = metadataRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	// The index is loaded once and serves every page it lists
+	index, err := hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		t.log.DebugContext(ctx, "Site index unavailable, using page endpoints", "site", siteURL.String(), "error", err)
+		index = nil
+	}
+
+	pages := []map[string]interface{}{}
+	var errors []toolerrors.ErrorDetail
+	now := time.Now()
+
+	for _, path := range metadataRequest.Paths {
+		if err := ctx.Err(); err != nil {
+			t.log.WarnContext(ctx, "Metadata retrieval cancelled", "site", metadataRequest.HugoSitePath, "error", err)
+			return nil, fmt.Errorf("metadata retrieval cancelled: %w", err)
+		}
+
+		page, err := t.pageMetadata(ctx, siteURL, index, path, metadataRequest.PublishOptions, now)
+		if err != nil {
+			t.log.WarnContext(ctx, "Failed to retrieve metadata for path", "path", path, "error", err)
+			errors = append(errors, toolerrors.FromError(err, map[string]interface{}{"path": path}))
+			continue
+		}
+		pages = append(pages, page)
+	}
+
+	metadata := map[string]interface{}{
+		"requested_paths": len(metadataRequest.Paths),
+		"retrieved_count": len(pages),
+		"error_count":     len(errors),
+		"index_available": index != nil,
+		"site_source":     sites.Source(ctx),
+		"redirects":       httpclient.Redirects(ctx),
+	}
+	if index != nil {
+		metadata["index_endpoint"] = index.URL()
+	}
+	if errors == nil {
+		errors = []toolerrors.ErrorDetail{}
+	}
+
+	response := map[string]interface{}{
+		"success":  true,
+		"pages":    pages,
+		"metadata": metadata,
+		"errors":   errors,
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal page metadata", "error", err)
+		return nil, fmt.Errorf("failed to marshal page metadata: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Retrieved page metadata", "site", metadataRequest.HugoSitePath, "requested", len(metadataRequest.Paths), "retrieved", len(pages), "errors", len(errors))
+	return tools.TextResponse(responseJSON), nil
+}
+
+// pageMetadata describes the page at path from its index entry, or else
+// from the first page endpoint that serves it
+func (t *Tool) pageMetadata(ctx context.Context, siteURL *url.URL, index *hugoindex.SiteIndex, path string, publish hugoindex.PublishOptions, now time.Time) (map[string]interface{}, error) {
+	source, endpoint := SourceSiteIndex, ""
+	page, found := gjson.Result{}, false
+
+	if index != nil {
+		var err error
+		page, found, err = index.Page(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read index: %w", err)
+		}
+		endpoint = index.URL()
+	}
+	if !found {
+		var err error
+		page, endpoint, err = t.fetchPage(ctx, siteURL, path)
+		if err != nil {
+			return nil, err
+		}
+		source = SourcePageEndpoint
+	}
+
+	switch publish.Exclusion(page, now) {
+	case hugoindex.ExcludedDraft:
+		return nil, fmt.Errorf("page is a draft (set include_drafts to retrieve it)")
+	case hugoindex.ExcludedFuture:
+		return nil, fmt.Errorf("page is scheduled for future publication (set include_future to retrieve it)")
+	case hugoindex.ExcludedExpired:
+		return nil, fmt.Errorf("page has expired")
+	}
+
+	return describePage(page, path, source, endpoint), nil
+}
+
+// fetchPage fetches the JSON output of the page at path from the endpoints
+// Hugo sites commonly publish it at
+func (t *Tool) fetchPage(ctx context.Context, siteURL *url.URL, path string) (gjson.Result, string, error) {
+	cleanPath := strings.Trim(path, "/")
+	if cleanPath == "" {
+		cleanPath = "index"
+	}
+	underscorePath := strings.ReplaceAll(cleanPath, "-", "_")
+	endpoints := []string{
+		"/" + cleanPath + ".json",
+		"/" + cleanPath + "/index.json",
+		"/" + underscorePath + ".json",
+		"/" + underscorePath + "/index.json",
+	}
+
+	for _, endpoint := range endpoints {
+		if err := ctx.Err(); err != nil {
+			return gjson.Result{}, "", err
+		}
+
+		pageURL := siteURL.ResolveReference(&url.URL{Path: endpoint})
+		cacheKey := t.cache.BuildKey(siteURL.String(), endpoint, nil)
+		result, err := t.cache.Fetch(ctx, t.httpClient, cacheKey, pageURL.String(), validatePage)
+		if err != nil {
+			t.log.DebugContext(ctx, "Page endpoint unavailable", "url", pageURL.String(), "error", err)
+			continue
+		}
+		return gjson.ParseBytes(result.Data), pageURL.String(), nil
+	}
+	return gjson.Result{}, "", toolerrors.Errorf(toolerrors.ErrCodeNotFound, "page not found")
+}
+
+// validatePage checks that data is the JSON output of a single page
+func validatePage(data []byte) bool {
+	if !gjson.ValidBytes(data) {
+		return false
+	}
+	page := gjson.ParseBytes(data)
+	return page.IsObject() && (page.Get("title").Exists() || page.Get("date").Exists())
+}
+
+// describePage returns the metadata of page, leaving out its body
+func describePage(page gjson.Result, path, source, endpoint string) map[string]interface{} {
+	entry := map[string]interface{}{
+		"path":            path,
+		"title":           page.Get("title").String(),
+		"section":         hugoindex.PageSection(page),
+		"taxonomies":      hugoindex.PageTaxonomies(page),
+		"source":          source,
+		"source_endpoint": endpoint,
+	}
+	if pageURL := hugoindex.PageURL(page); pageURL != "" {
+		entry["url"] = pageURL
+	}
+	for _, field := range []string{"date", "lastmod"} {
+		if value := page.Get(field).String(); value != "" {
+			entry[field] = value
+		}
+	}
+	if words, ok := enrich.WordCount(page); ok {
+		entry["word_count"] = words
+	}
+	return entry
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*MetadataRequest](registry, t, "Content retrieval", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
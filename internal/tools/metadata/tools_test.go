@@ -0,0 +1,179 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNew(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+	assert.NotNil(t, tool)
+	assert.Equal(t, "hugo_reader_get_page_metadata", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+	assert.NotNil(t, tool.cache)
+	assert.NotNil(t, tool.httpClient)
+}
+
+func TestMetadataRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *MetadataRequest
+		wantErr bool
+	}{
+		{
+			name:    "valid request",
+			req:     &MetadataRequest{HugoSitePath: "https://example.com", Paths: []string{"/posts/first/"}},
+			wantErr: false,
+		},
+		{
+			name:    "missing hugo_site_path",
+			req:     &MetadataRequest{Paths: []string{"/posts/first/"}},
+			wantErr: true,
+		},
+		{
+			name:    "missing paths",
+			req:     &MetadataRequest{HugoSitePath: "https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "too many paths",
+			req:     &MetadataRequest{HugoSitePath: "https://example.com", Paths: make([]string, maxPaths+1)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTool_Execute(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		switch r.URL.Path {
+		case "/index.json":
+			w.Write([]byte(`{"pages": [
+				{"title": "First", "url": "/posts/first/", "date": "2024-01-01", "lastmod": "2024-02-01", "wordcount": 420, "tags": ["go"], "content": "<p>Long body</p>"},
+				{"title": "Second", "url": "/posts/second/", "section": "articles", "plain": "three short words", "categories": ["news"]},
+				{"title": "Unfinished", "url": "/posts/unfinished/", "draft": true}
+			]}`))
+		case "/about.json":
+			w.Write([]byte(`{"title": "About", "date": "2023-05-01", "content": "<p>About this site</p>"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	req := &MetadataRequest{HugoSitePath: server.URL, Paths: []string{"/posts/first/", "posts/second", "/about/", "/posts/unfinished/", "/missing/"}}
+	resp, err := tool.Execute(context.Background(), req)
+	require.NoError(t, err)
+	text := resp.Content[0].TextContent.Text
+	result := gjson.Parse(text)
+
+	// Bodies are never returned
+	assert.NotContains(t, text, "Long body")
+	assert.NotContains(t, text, "About this site")
+
+	assert.Equal(t, `["First","Second","About"]`, result.Get("pages.#.title").Raw)
+	first := result.Get("pages.0")
+	assert.Equal(t, SourceSiteIndex, first.Get("source").String())
+	assert.Equal(t, "2024-01-01", first.Get("date").String())
+	assert.Equal(t, "2024-02-01", first.Get("lastmod").String())
+	assert.Equal(t, int64(420), first.Get("word_count").Int())
+	assert.Equal(t, "posts", first.Get("section").String())
+	assert.Equal(t, `["go"]`, first.Get("taxonomies.tags").Raw)
+
+	second := result.Get("pages.1")
+	assert.Equal(t, "articles", second.Get("section").String())
+	assert.Equal(t, int64(3), second.Get("word_count").Int())
+	assert.Equal(t, `["news"]`, second.Get("taxonomies.categories").Raw)
+
+	about := result.Get("pages.2")
+	assert.Equal(t, SourcePageEndpoint, about.Get("source").String())
+	assert.Equal(t, server.URL+"/about.json", about.Get("source_endpoint").String())
+	assert.Equal(t, int64(3), about.Get("word_count").Int())
+
+	assert.True(t, result.Get("metadata.index_available").Bool())
+	assert.Equal(t, int64(2), result.Get("metadata.error_count").Int())
+	assert.Equal(t, "/posts/unfinished/", result.Get("errors.0.context.path").String())
+	assert.Equal(t, toolerrors.ErrCodeNotFound, result.Get("errors.1.code").String())
+
+	// Indexed pages need no page endpoint
+	for _, path := range requested {
+		assert.False(t, strings.HasPrefix(path, "/posts/"), "fetched %s", path)
+	}
+}
+
+func TestTool_Execute_NoIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/posts/first/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"title": "First", "url": "/posts/first/", "date": "2024-01-01", "tags": ["go"]}`))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &MetadataRequest{HugoSitePath: server.URL, Paths: []string{"/posts/first/"}})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.False(t, result.Get("metadata.index_available").Bool())
+	assert.Equal(t, SourcePageEndpoint, result.Get("pages.0.source").String())
+	assert.Equal(t, "posts", result.Get("pages.0.section").String())
+	assert.False(t, result.Get("pages.0.word_count").Exists())
+
+	_, err = tool.Execute(context.Background(), &MetadataRequest{HugoSitePath: server.URL})
+	assert.Error(t, err)
+}
+
+func TestTool_Execute_ReportsRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			http.Redirect(w, r, "/v2/index.json", http.StatusMovedPermanently)
+		case "/v2/index.json":
+			w.Write([]byte(`[{"title": "First", "url": "/posts/first/"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &MetadataRequest{HugoSitePath: server.URL, Paths: []string{"/posts/first/"}})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, "First", result.Get("pages.0.title").String())
+
+	redirects := result.Get("metadata.redirects")
+	require.Equal(t, int64(1), redirects.Get("#").Int())
+	assert.Equal(t, server.URL+"/index.json", redirects.Get("0.from").String())
+	assert.Equal(t, server.URL+"/v2/index.json", redirects.Get("0.to").String())
+	assert.Equal(t, int64(http.StatusMovedPermanently), redirects.Get("0.status").Int())
+}
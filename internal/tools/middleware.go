@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/logging"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/tidwall/gjson"
+)
+
+// Handler runs a tool call
+type Handler func(ctx context.Context, request Request) (*mcp_golang.ToolResponse, error)
+
+// Middleware wraps the Handler of the named tool with behavior every tool
+// shares
+type Middleware func(name string, next Handler) Handler
+
+// SiteResolver is implemented by requests naming their site through a
+// registered alias or the default site, which Execute resolves before it
+// validates them. sites.SiteOptions implements it for the requests that
+// embed it.
+type SiteResolver interface {
+	ResolvesSite()
+}
+
+// Chain returns the handler of tool wrapped in middlewares, the first of
+// them outermost, so Chain(tool, Recover(logger), Validate(), Log(logger))
+// recovers from panics in validation, logging and the tool itself
+func Chain(tool Tooler, middlewares ...Middleware) Handler {
+	handler := Handler(tool.Execute)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](tool.Name(), handler)
+	}
+	return handler
+}
+
+// RequestID gives every tool call a request ID, carried by its context so
+// the records logged with it are tagged with the ID, and reported in the
+// response's metadata as request_id. Later middlewares can add it to the
+// errors they report.
+func RequestID() Middleware {
+	return func(name string, next Handler) Handler {
+		return func(ctx context.Context, request Request) (*mcp_golang.ToolResponse, error) {
+			id := logging.NewRequestID()
+			resp, err := next(logging.WithRequestID(ctx, id), request)
+			return withRequestID(resp, id), err
+		}
+	}
+}
+
+// withRequestID adds id to the metadata object of a JSON response, adding
+// the object to responses that have none
+func withRequestID(resp *mcp_golang.ToolResponse, id string) *mcp_golang.ToolResponse {
+	if resp == nil || len(resp.Content) == 0 || resp.Content[0] == nil || resp.Content[0].TextContent == nil {
+		return resp
+	}
+	text := resp.Content[0].TextContent.Text
+	result := gjson.Parse(text)
+	if !result.IsObject() {
+		return resp
+	}
+
+	field := fmt.Sprintf(`"request_id":%q`, id)
+	switch metadata := result.Get("metadata"); {
+	case !metadata.Exists():
+		field = `"metadata":{` + field + "}"
+		if len(result.Map()) > 0 {
+			field = "," + field
+		}
+		end := strings.LastIndex(text, "}")
+		text = text[:end] + field + text[end:]
+	case metadata.IsObject() && metadata.Index > 0:
+		if len(metadata.Map()) > 0 {
+			field += ","
+		}
+		start := metadata.Index + 1
+		text = text[:start] + field + text[start:]
+	default:
+		return resp
+	}
+	resp.Content[0].TextContent.Text = text
+	return resp
+}
+
+// Recover turns a panic in a tool call into an INTERNAL_ERROR, logging the
+// panic and its stack trace, so one failing call can't take the server down
+func Recover(logger *slog.Logger) Middleware {
+	return func(name string, next Handler) Handler {
+		return func(ctx context.Context, request Request) (resp *mcp_golang.ToolResponse, err error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					logger.ErrorContext(ctx, "Tool call panicked", "tool", name, "panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+					resp, err = nil, toolerrors.Errorf(toolerrors.ErrCodeInternalError, "%s failed unexpectedly", name)
+				}
+			}()
+			return next(ctx, request)
+		}
+	}
+}
+
+// Validate rejects missing requests and, before the tool runs, those that
+// fail their own validation, as INVALID_REQUEST errors. Requests that are a
+// SiteResolver are left for the tool to validate once it has resolved their
+// site.
+func Validate() Middleware {
+	return func(name string, next Handler) Handler {
+		return func(ctx context.Context, request Request) (*mcp_golang.ToolResponse, error) {
+			if request == nil || (reflect.ValueOf(request).Kind() == reflect.Pointer && reflect.ValueOf(request).IsNil()) {
+				return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "missing request arguments")
+			}
+			if _, resolves := request.(SiteResolver); !resolves {
+				if err := request.Validate(); err != nil {
+					return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+				}
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// Log logs the start of every tool call at debug level and its outcome,
+// with its duration and, for failures, the error and its code
+func Log(logger *slog.Logger) Middleware {
+	return func(name string, next Handler) Handler {
+		return func(ctx context.Context, request Request) (*mcp_golang.ToolResponse, error) {
+			logger.DebugContext(ctx, "Tool call started", "tool", name)
+			start := time.Now()
+			resp, err := next(ctx, request)
+			duration := time.Since(start)
+			if err != nil {
+				logger.WarnContext(ctx, "Tool call failed", "tool", name, "duration", duration, "code", toolerrors.Code(err), "error", err)
+				return resp, err
+			}
+			logger.InfoContext(ctx, "Tool call completed", "tool", name, "duration", duration)
+			return resp, nil
+		}
+	}
+}
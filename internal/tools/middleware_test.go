@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/logging"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRequest fails validation when invalid is set
+type fakeRequest struct {
+	invalid bool
+}
+
+func (r *fakeRequest) Validate() error {
+	if r.invalid {
+		return errors.New("bad request")
+	}
+	return nil
+}
+
+// fakeSiteRequest resolves its site before it can be validated
+type fakeSiteRequest struct {
+	fakeRequest
+}
+
+func (r *fakeSiteRequest) ResolvesSite() {}
+
+// fakeTool runs execute for every call
+type fakeTool struct {
+	calls   int
+	execute func(request Request) (*mcp_golang.ToolResponse, error)
+}
+
+func (t *fakeTool) Execute(ctx context.Context, request Request) (*mcp_golang.ToolResponse, error) {
+	t.calls++
+	return t.execute(request)
+}
+
+func (t *fakeTool) Name() string                  { return "fake" }
+func (t *fakeTool) Description() string           { return "A fake tool" }
+func (t *fakeTool) SetLogger(logger *slog.Logger) {}
+
+func TestChain(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	tool := &fakeTool{execute: func(request Request) (*mcp_golang.ToolResponse, error) {
+		return TextResponse([]byte(`{}`)), nil
+	}}
+	handler := Chain(tool, Recover(logger), Validate(), Log(logger))
+
+	resp, err := handler(context.Background(), &fakeRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, resp.Content[0].TextContent.Text)
+	assert.Equal(t, 1, tool.calls)
+
+	// Invalid requests never reach the tool
+	_, err = handler(context.Background(), &fakeRequest{invalid: true})
+	assert.Equal(t, toolerrors.ErrCodeInvalidRequest, toolerrors.Code(err))
+	_, err = handler(context.Background(), (*fakeRequest)(nil))
+	assert.Equal(t, toolerrors.ErrCodeInvalidRequest, toolerrors.Code(err))
+	assert.Equal(t, 1, tool.calls)
+
+	// Requests resolving a site are validated by the tool
+	_, err = handler(context.Background(), &fakeSiteRequest{fakeRequest{invalid: true}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, tool.calls)
+}
+
+func TestRecover(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	tool := &fakeTool{execute: func(request Request) (*mcp_golang.ToolResponse, error) {
+		panic("boom")
+	}}
+	handler := Chain(tool, Recover(logger), Validate(), Log(logger))
+
+	resp, err := handler(context.Background(), &fakeRequest{})
+	assert.Nil(t, resp)
+	require.Error(t, err)
+	assert.Equal(t, toolerrors.ErrCodeInternalError, toolerrors.Code(err))
+	assert.Contains(t, err.Error(), "fake failed unexpectedly")
+}
+
+func TestRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(logging.ContextHandler(slog.NewJSONHandler(&logs, nil)))
+
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{name: "metadata added", response: `{"success":true}`, want: `{"success":true,"metadata":{"request_id":"ID"}}`},
+		{name: "metadata extended", response: `{"success":true,"metadata":{"total":2}}`, want: `{"success":true,"metadata":{"request_id":"ID","total":2}}`},
+		{name: "empty metadata", response: `{"metadata":{}}`, want: `{"metadata":{"request_id":"ID"}}`},
+		{name: "empty object", response: `{}`, want: `{"metadata":{"request_id":"ID"}}`},
+		{name: "not an object", response: `not json`, want: `not json`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			var requestID string
+			tool := &fakeTool{execute: func(request Request) (*mcp_golang.ToolResponse, error) {
+				return TextResponse([]byte(tt.response)), nil
+			}}
+			handler := Chain(tool, RequestID(), func(name string, next Handler) Handler {
+				return func(ctx context.Context, request Request) (*mcp_golang.ToolResponse, error) {
+					requestID = logging.RequestID(ctx)
+					return next(ctx, request)
+				}
+			}, Log(logger))
+
+			resp, err := handler(context.Background(), &fakeRequest{})
+			require.NoError(t, err)
+			require.Len(t, requestID, 16)
+			assert.Equal(t, strings.ReplaceAll(tt.want, "ID", requestID), resp.Content[0].TextContent.Text)
+			assert.Contains(t, logs.String(), `"request_id":"`+requestID+`"`)
+		})
+	}
+}
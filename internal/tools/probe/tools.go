@@ -0,0 +1,218 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/capabilities"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+)
+
+// profileEndpoint is the cache key path probe results are kept under
+const profileEndpoint = "/.hugo-reader/capabilities"
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool probes what a Hugo site offers.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// ProbeRequest represents the request parameters for probing a site.
+type ProbeRequest struct {
+	HugoSitePath string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	Refresh      bool   `json:"refresh,omitempty" jsonschema:"title=Probe again instead of returning a cached profile"`
+
+	httpclient.RetryOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_probe"),
+		name:        "hugo_reader_probe",
+		description: "Probe what a Hugo site offers: site index, search endpoints (OpenSearch, JSON search indexes, Pagefind), sitemap, feeds, taxonomy endpoints, robots.txt, the WordPress REST API, languages, Hugo version and the platform the site appears built with (Hugo, WordPress, Jekyll and other generators). Returns a capability matrix and the strategy each kind of tool will use. Run it first on an unfamiliar site to choose how to explore it.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *ProbeRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// Execute probes a site.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	probeRequest, ok := req.(*ProbeRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := probeRequest.SiteOptions.Apply(ctx, t.sites, &probeRequest.HugoSitePath, &probeRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := probeRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := probeRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(probeRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", probeRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = probeRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	// Profiles are cached like the resources they were probed from
+	cacheKey := cache.ScopedKey(ctx, t.cache.BuildKey(siteURL.String(), profileEndpoint, nil))
+	if probeRequest.Refresh {
+		t.cache.Delete(cacheKey)
+	}
+
+	var profile capabilities.Profile
+	cached := false
+	if data, hit := t.cache.Get(cacheKey); hit && json.Unmarshal(data, &profile) == nil {
+		cached = true
+	} else {
+		profile = *capabilities.Probe(ctx, t.cache, t.httpClient, siteURL)
+		if err := ctx.Err(); err != nil {
+			t.log.WarnContext(ctx, "Probe cancelled", "site", probeRequest.HugoSitePath, "error", err)
+			return nil, fmt.Errorf("probe cancelled: %w", err)
+		}
+		if data, err := json.Marshal(profile); err == nil {
+			t.cache.Set(cacheKey, data, "", "")
+		}
+	}
+
+	available := 0
+	for _, capability := range profile.Capabilities {
+		if capability.Available {
+			available++
+		}
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"data":    profile,
+		"metadata": map[string]interface{}{
+			"cached":                 cached,
+			"capabilities_checked":   len(profile.Capabilities),
+			"capabilities_available": available,
+			"missing_endpoints":      t.cache.MissingEndpoints(siteURL.String()),
+			"site_source":            sites.Source(ctx),
+		},
+		"errors": []string{},
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal probe results", "error", err)
+		return nil, fmt.Errorf("failed to marshal probe results: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Probed site", "site", probeRequest.HugoSitePath, "available", available, "cached", cached)
+	return tools.TextResponse(responseJSON), nil
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*ProbeRequest](registry, t, "Site exploration", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
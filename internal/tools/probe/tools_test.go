@@ -0,0 +1,101 @@
+package probe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNew(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+	assert.NotNil(t, tool)
+	assert.Equal(t, "hugo_reader_probe", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+	assert.NotNil(t, tool.cache)
+	assert.NotNil(t, tool.httpClient)
+}
+
+func TestProbeRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *ProbeRequest
+		wantErr bool
+	}{
+		{
+			name:    "valid request",
+			req:     &ProbeRequest{HugoSitePath: "https://example.com"},
+			wantErr: false,
+		},
+		{
+			name:    "missing hugo_site_path",
+			req:     &ProbeRequest{},
+			wantErr: true,
+		},
+		{
+			name:    "invalid retry backoff",
+			req:     &ProbeRequest{HugoSitePath: "https://example.com", RetryOptions: httpclient.RetryOptions{RetryBackoff: "later"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTool_Execute(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/index.json":
+			w.Write([]byte(`{"pages": [{"title": "A", "url": "/posts/a/"}]}`))
+		case "/feed.json":
+			w.Write([]byte(`{"version": "https://jsonfeed.org/version/1.1", "items": [{"id": "1", "url": "/posts/a/", "title": "A"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &ProbeRequest{HugoSitePath: server.URL})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.True(t, result.Get("success").Bool())
+	assert.True(t, result.Get("data.capabilities.index.available").Bool())
+	assert.Equal(t, "json_feed", result.Get("data.capabilities.feed.format").String())
+	assert.False(t, result.Get("data.capabilities.sitemap.available").Bool())
+	assert.Equal(t, "content_scan", result.Get("data.strategies.search").String())
+	assert.Equal(t, int64(2), result.Get("metadata.capabilities_available").Int())
+	assert.False(t, result.Get("metadata.cached").Bool())
+	assert.Contains(t, result.Get("metadata.missing_endpoints").Value(), "/search.json")
+
+	// The profile is served from the cache until a refresh is requested
+	probed := requests.Load()
+	resp, err = tool.Execute(context.Background(), &ProbeRequest{HugoSitePath: server.URL})
+	require.NoError(t, err)
+	assert.True(t, gjson.Get(resp.Content[0].TextContent.Text, "metadata.cached").Bool())
+	assert.Equal(t, probed, requests.Load())
+
+	resp, err = tool.Execute(context.Background(), &ProbeRequest{HugoSitePath: server.URL, Refresh: true})
+	require.NoError(t, err)
+	assert.False(t, gjson.Get(resp.Content[0].TextContent.Text, "metadata.cached").Bool())
+}
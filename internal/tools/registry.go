@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+)
+
+// Info describes a registered tool
+type Info struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Purpose     string      `json:"purpose"`
+	Annotations Annotations `json:"annotations"`
+}
+
+// entry is a tool added to a Registry
+type entry struct {
+	info Info
+	tool Tooler
+
+	// calls counts the tool's invocations since it was registered
+	calls atomic.Int64
+
+	// register registers the tool's calls, decoded into its request type,
+	// with an MCP server
+	register func(server *mcp_golang.Server, handler Handler) error
+}
+
+// Registry collects the server's tools, each adding itself with Add, and
+// registers those that are not disabled with the MCP server
+type Registry struct {
+	entries  []*entry
+	disabled map[string]bool
+}
+
+// NewRegistry creates a Registry that leaves out the named tools
+func NewRegistry(disabled ...string) *Registry {
+	r := &Registry{disabled: make(map[string]bool)}
+	for _, name := range disabled {
+		if name = strings.TrimSpace(name); name != "" {
+			r.disabled[name] = true
+		}
+	}
+	return r
+}
+
+// Add adds tool to r, for its calls to be decoded into the request type T,
+// with what it is for and its annotations
+func Add[T Request](r *Registry, tool Tooler, purpose string, annotations Annotations) {
+	r.entries = append(r.entries, &entry{
+		info: Info{
+			Name:        tool.Name(),
+			Description: tool.Description(),
+			Purpose:     purpose,
+			Annotations: annotations,
+		},
+		tool: tool,
+		register: func(server *mcp_golang.Server, handler Handler) error {
+			// mcp-golang derives the tool's input schema from T
+			return server.RegisterTool(tool.Name(), tool.Description(), func(ctx context.Context, args T) (*mcp_golang.ToolResponse, error) {
+				return handler(ctx, args)
+			})
+		},
+	})
+}
+
+// Enabled reports whether the named tool was added and is not disabled
+func (r *Registry) Enabled(name string) bool {
+	return r.added(name) && !r.disabled[name]
+}
+
+// Tools describes the enabled tools, in the order they were added
+func (r *Registry) Tools() []Info {
+	infos := []Info{}
+	for _, e := range r.entries {
+		if !r.disabled[e.info.Name] {
+			infos = append(infos, e.info)
+		}
+	}
+	return infos
+}
+
+// Disabled returns the names of the tools left out, sorted
+func (r *Registry) Disabled() []string {
+	names := make([]string, 0, len(r.disabled))
+	for name := range r.disabled {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Register registers every enabled tool with server, its calls handled by
+// Chain with middlewares. Disabling a tool that was never added is an
+// error, so a misspelled name doesn't leave it enabled unnoticed.
+func (r *Registry) Register(server *mcp_golang.Server, middlewares ...Middleware) error {
+	for _, name := range r.Disabled() {
+		if !r.added(name) {
+			return fmt.Errorf("cannot disable unknown tool %q", name)
+		}
+	}
+	for _, e := range r.entries {
+		if r.disabled[e.info.Name] {
+			continue
+		}
+		handler := Chain(e.tool, middlewares...)
+		calls := &e.calls
+		counted := func(ctx context.Context, request Request) (*mcp_golang.ToolResponse, error) {
+			calls.Add(1)
+			return handler(ctx, request)
+		}
+		if err := e.register(server, counted); err != nil {
+			return fmt.Errorf("failed to register %s: %w", e.info.Name, err)
+		}
+	}
+	return nil
+}
+
+// Invocations returns how many times each enabled tool has been called
+func (r *Registry) Invocations() map[string]int64 {
+	counts := make(map[string]int64)
+	for _, e := range r.entries {
+		if !r.disabled[e.info.Name] {
+			counts[e.info.Name] = e.calls.Load()
+		}
+	}
+	return counts
+}
+
+// added reports whether a tool of that name was added
+func (r *Registry) added(name string) bool {
+	for _, e := range r.entries {
+		if e.info.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// namedTool is a fakeTool under another name
+type namedTool struct {
+	fakeTool
+	name string
+}
+
+func (t *namedTool) Name() string { return t.name }
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(" second ", "")
+	Add[*fakeRequest](registry, &namedTool{name: "first"}, "First purpose", ReadOnly)
+	Add[*fakeRequest](registry, &namedTool{name: "second"}, "Second purpose", ReadOnly)
+
+	assert.True(t, registry.Enabled("first"))
+	assert.False(t, registry.Enabled("second"))
+	assert.False(t, registry.Enabled("third"))
+	assert.Equal(t, []string{"second"}, registry.Disabled())
+	assert.Equal(t, []Info{{Name: "first", Description: "A fake tool", Purpose: "First purpose", Annotations: ReadOnly}}, registry.Tools())
+
+	server := mcp_golang.NewServer(nil)
+	require.NoError(t, registry.Register(server))
+	assert.True(t, server.CheckToolRegistered("first"))
+	assert.False(t, server.CheckToolRegistered("second"))
+}
+
+func TestRegistry_Register_UnknownDisabledTool(t *testing.T) {
+	registry := NewRegistry("frist")
+	Add[*fakeRequest](registry, &namedTool{name: "first"}, "First purpose", ReadOnly)
+
+	err := registry.Register(mcp_golang.NewServer(nil))
+	assert.ErrorContains(t, err, `cannot disable unknown tool "frist"`)
+}
+
+func TestRegistry_Invocations(t *testing.T) {
+	registry := NewRegistry()
+	tool := &namedTool{name: "first", fakeTool: fakeTool{execute: func(request Request) (*mcp_golang.ToolResponse, error) {
+		return TextResponse([]byte(`{}`)), nil
+	}}}
+	Add[*fakeRequest](registry, tool, "First purpose", ReadOnly)
+
+	// Capture the handler the server would call
+	var handler Handler
+	registry.entries[0].register = func(server *mcp_golang.Server, h Handler) error {
+		handler = h
+		return nil
+	}
+	require.NoError(t, registry.Register(mcp_golang.NewServer(nil)))
+	assert.Equal(t, map[string]int64{"first": 0}, registry.Invocations())
+
+	for range 2 {
+		_, err := handler(context.Background(), &fakeRequest{})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, map[string]int64{"first": 2}, registry.Invocations())
+}
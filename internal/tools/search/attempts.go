@@ -0,0 +1,81 @@
+package search
+
+import (
+	"errors"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+)
+
+// Search methods, in the order they are tried. A registered site's search
+// backend comes first, reported by its name: MethodAlgolia, MethodMeilisearch
+// or MethodTypesense.
+const (
+	MethodAlgolia     = "algolia"
+	MethodMeilisearch = "meilisearch"
+	MethodTypesense   = "typesense"
+	MethodOpenSearch  = "opensearch"
+	MethodHugoNative  = "hugo_native"
+	MethodContentScan = "content_scan"
+	MethodFeedScan    = "feed_scan"
+	MethodSitemapScan = "sitemap_scan"
+)
+
+// Attempt outcomes
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailed  = "failed"
+	OutcomeInvalid = "invalid"
+	OutcomeSkipped = "skipped"
+)
+
+// Attempt is the outcome of trying one search endpoint
+type Attempt struct {
+	Method   string `json:"method"`
+	Endpoint string `json:"endpoint"`
+	Outcome  string `json:"outcome"`
+	Status   int    `json:"status,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// attempts collects the endpoints tried by one search. A nil *attempts
+// records nothing.
+type attempts struct {
+	list []Attempt
+}
+
+// record adds the outcome of trying endpoint with method: invalid when the
+// response lacked the expected data, skipped when the endpoint was recently
+// found missing, and failed for other errors
+func (a *attempts) record(method, endpoint string, err error) {
+	if a == nil {
+		return
+	}
+
+	attempt := Attempt{Method: method, Endpoint: endpoint, Outcome: OutcomeSuccess}
+	if err != nil {
+		attempt.Outcome = OutcomeFailed
+		attempt.Code = toolerrors.Code(err)
+		attempt.Error = err.Error()
+
+		var status *cache.StatusError
+		switch {
+		case errors.Is(err, cache.ErrInvalidResponse):
+			attempt.Outcome = OutcomeInvalid
+		case errors.Is(err, cache.ErrEndpointMissing):
+			attempt.Outcome = OutcomeSkipped
+		case errors.As(err, &status):
+			attempt.Status = status.StatusCode
+		}
+	}
+	a.list = append(a.list, attempt)
+}
+
+// all returns the attempts recorded, never nil
+func (a *attempts) all() []Attempt {
+	if a == nil || a.list == nil {
+		return []Attempt{}
+	}
+	return a.list
+}
@@ -0,0 +1,136 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+)
+
+// MaxBatchQueries bounds the queries of one batch search
+const MaxBatchQueries = 10
+
+// batchConcurrency bounds how many queries of a batch run at once
+const batchConcurrency = 4
+
+// batchResult is the outcome of one query of a batch search
+type batchResult struct {
+	Query    string                   `json:"query"`
+	Success  bool                     `json:"success"`
+	Results  []map[string]interface{} `json:"results"`
+	Metadata map[string]interface{}   `json:"metadata,omitempty"`
+	Attempts []Attempt                `json:"attempts"`
+	Error    *toolerrors.ErrorDetail  `json:"error,omitempty"`
+}
+
+// validateBatch checks the queries of a batch search, each in the
+// request's mode
+func (r *SearchRequest) validateBatch() error {
+	if r.Query != "" {
+		return fmt.Errorf("use either query or queries, not both")
+	}
+	if len(r.Queries) > MaxBatchQueries {
+		return fmt.Errorf("at most %d queries may be searched at once", MaxBatchQueries)
+	}
+	if r.Explain {
+		return fmt.Errorf("explain is not supported with queries")
+	}
+	for i, query := range r.Queries {
+		if strings.TrimSpace(query) == "" {
+			return fmt.Errorf("queries[%d] is empty", i)
+		}
+		if _, err := r.forQuery(query).matcher(); err != nil {
+			return fmt.Errorf("invalid queries[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// forQuery returns a copy of the request searching for query alone
+func (r *SearchRequest) forQuery(query string) *SearchRequest {
+	single := *r
+	single.Query = query
+	single.Queries = nil
+	return &single
+}
+
+// executeBatch searches for each of the request's queries and returns
+// their results side by side. The first query runs alone so the indexes it
+// loads are cached for the others, which then run concurrently. Queries
+// that fail are reported without failing the others.
+func (t *Tool) executeBatch(ctx context.Context, siteURL *url.URL, req *SearchRequest) (*mcp_golang.ToolResponse, error) {
+	results := make([]batchResult, len(req.Queries))
+	errs := make([]error, len(req.Queries))
+	run := func(i int) {
+		single := req.forQuery(req.Queries[i])
+		tried := &attempts{}
+		found, metadata, err := t.search(ctx, siteURL, single, tried)
+		results[i] = batchResult{Query: single.Query, Success: err == nil, Results: found, Metadata: metadata, Attempts: tried.all()}
+		if err != nil {
+			errs[i] = err
+			detail := toolerrors.FromError(err, map[string]interface{}{"query": single.Query})
+			results[i].Error = &detail
+			results[i].Results = []map[string]interface{}{}
+		}
+	}
+
+	run(0)
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	for i := 1; i < len(req.Queries); i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			run(i)
+		}(i)
+	}
+	wg.Wait()
+
+	errors := []toolerrors.ErrorDetail{}
+	for _, result := range results {
+		if result.Error != nil {
+			errors = append(errors, *result.Error)
+		}
+	}
+	if len(errors) == len(results) {
+		t.log.ErrorContext(ctx, "Every query of the batch failed", "queries", len(results), "error", errs[0])
+		return nil, &toolerrors.Error{
+			Code: toolerrors.Code(errs[0]),
+			Err:  fmt.Errorf("every query failed: %w", errs[0]),
+			Data: map[string]interface{}{"queries": results},
+		}
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"queries": results,
+		"metadata": map[string]interface{}{
+			"query_count":  len(results),
+			"failed_count": len(errors),
+			"sort":         req.Sort,
+			"offset":       req.Offset,
+			"limit":        req.Limit,
+			"site_source":  sites.Source(ctx),
+			"redirects":    httpclient.Redirects(ctx),
+		},
+		"errors": errors,
+	}
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal search results", "error", err)
+		return nil, fmt.Errorf("failed to marshal search results: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Batch search completed", "queries", len(results), "failed", len(errors), "site", req.HugoSitePath)
+	return tools.TextResponse(responseJSON), nil
+}
@@ -0,0 +1,89 @@
+package search
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestSearchRequest_ValidateBatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     SearchRequest
+		wantErr string
+	}{
+		{name: "valid", req: SearchRequest{Queries: []string{"golang", "rust"}}},
+		{name: "query and queries", req: SearchRequest{Query: "golang", Queries: []string{"rust"}}, wantErr: "use either query or queries, not both"},
+		{name: "too many", req: SearchRequest{Queries: strings.Fields("a b c d e f g h i j k")}, wantErr: "at most 10 queries may be searched at once"},
+		{name: "empty query", req: SearchRequest{Queries: []string{"golang", " "}}, wantErr: "queries[1] is empty"},
+		{name: "invalid regex", req: SearchRequest{Queries: []string{"v1", "v("}, Mode: ModeRegex}, wantErr: "invalid queries[1]: invalid regex: error parsing regexp: missing closing ): `v(`"},
+		{name: "explain", req: SearchRequest{Queries: []string{"golang"}, ExplainOptions: tools.ExplainOptions{Explain: true}}, wantErr: "explain is not supported with queries"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.HugoSitePath = "https://example.com"
+			err := tt.req.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTool_Execute_Batch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search.json" {
+			http.NotFound(w, r)
+			return
+		}
+		pages := map[string]string{
+			"golang": `{"title": "Learning golang", "url": "/posts/golang/"}`,
+			"rust":   `{"title": "Learning rust", "url": "/posts/rust/"}`,
+		}
+		w.Write([]byte(`{"results": [` + pages[r.URL.Query().Get("q")] + `]}`))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &SearchRequest{HugoSitePath: server.URL, Queries: []string{"golang", "rust", "cobol"}})
+	require.NoError(t, err)
+
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.True(t, result.Get("success").Bool())
+	assert.Equal(t, `["golang","rust","cobol"]`, result.Get("queries.#.query").Raw)
+	assert.Equal(t, `["/posts/golang/"]`, result.Get("queries.0.results.#.url").Raw)
+	assert.Equal(t, `["/posts/rust/"]`, result.Get("queries.1.results.#.url").Raw)
+	assert.Equal(t, `[]`, result.Get("queries.2.results").Raw)
+	assert.Equal(t, MethodHugoNative, result.Get("queries.0.metadata.search_method").String())
+	assert.Equal(t, int64(3), result.Get("metadata.query_count").Int())
+	assert.Equal(t, int64(0), result.Get("metadata.failed_count").Int())
+}
+
+func TestTool_Execute_BatchFailed(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	_, err = tool.Execute(context.Background(), &SearchRequest{HugoSitePath: server.URL, Queries: []string{"golang", "rust"}})
+	var toolErr *toolerrors.Error
+	require.ErrorAs(t, err, &toolErr)
+	assert.Contains(t, toolErr.Error(), "every query failed")
+	queries := toolErr.Data.(map[string]interface{})["queries"].([]batchResult)
+	require.Len(t, queries, 2)
+	assert.False(t, queries[1].Success)
+	assert.NotEmpty(t, queries[1].Attempts)
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/feed"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/generic"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/opensearch"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+)
+
+// explain returns the plan of a search: the endpoints of each method in the
+// order they would be tried, the cache keys they would be read from and the
+// filters applied to their results, without fetching any of them
+func (t *Tool) explain(ctx context.Context, siteURL *url.URL, req *SearchRequest) (*mcp_golang.ToolResponse, error) {
+	site := siteURL.String()
+	plan := tools.NewPlan(ctx, t.cache, site)
+	query := nativeQuery(req)
+
+	// Regex queries skip the native endpoints, which cannot run them
+	if !req.Generic && req.Mode != ModeRegex {
+		if backend, ok := sites.SearchBackend(ctx); ok {
+			queryURL, _ := url.Parse(backend.QueryURL())
+			plan.Add(backend.Name(), queryURL.Path, backend.QueryURL(), "").Note =
+				"the site's search backend is queried first with the native query; its hits are not cached"
+		}
+		plan.Add(MethodOpenSearch, opensearch.Path, resolve(siteURL, opensearch.Path), t.cache.BuildKey(site, opensearch.Path, nil)).Note =
+			"when the site declares a search URL, it is queried next with the native query"
+		for _, endpoint := range searchEndpoints(ctx, query) {
+			searchURL, cacheKey := t.endpointRequest(siteURL, endpoint, req)
+			plan.AddEndpoint(MethodHugoNative, endpoint.path, searchURL.String(), cacheKey)
+		}
+	}
+	if !req.Generic {
+		for _, path := range contentEndpoints(ctx) {
+			plan.Add(MethodContentScan, path, resolve(siteURL, path), t.cache.BuildKey(site, path, nil))
+		}
+		for _, path := range feed.Paths {
+			plan.Add(MethodFeedScan, path, resolve(siteURL, path), t.cache.BuildKey(site, path, nil))
+		}
+	}
+	indexKey := t.cache.BuildKey(site, generic.IndexEndpoint, map[string]string{"max_pages": strconv.Itoa(generic.MaxPages)})
+	plan.Add(MethodSitemapScan, generic.SitemapPath, resolve(siteURL, generic.SitemapPath), indexKey).Note =
+		fmt.Sprintf("unless the index built from them is kept, up to %d of the pages listed are read from their HTML", generic.MaxPages)
+
+	// Native endpoints are sent the plain terms of extended queries, whose
+	// syntax is then applied to the results
+	if query != req.Query {
+		plan.Filter("query", req.Query)
+	}
+	plan.Filter("content_type", req.ContentType)
+	if req.Taxonomy != "" && req.Term != "" {
+		plan.Filter("taxonomy", req.Taxonomy)
+		plan.Filter("term", req.Term)
+	}
+	plan.Filter("date_from", req.DateFrom)
+	plan.Filter("date_to", req.DateTo)
+	plan.Filter("scope_path", req.PathScope.Prefix())
+	plan.Filter("include_drafts", req.IncludeDrafts)
+	plan.Filter("include_future", req.IncludeFuture)
+
+	return tools.ExplainResponse(plan, map[string]interface{}{
+		"query":        req.Query,
+		"native_query": query,
+		"sort":         req.Sort,
+		"offset":       req.Offset,
+		"limit":        req.Limit,
+		"site":         site,
+		"generic":      req.Generic,
+		"mode":         req.Mode,
+		"match":        req.Match,
+		"fuzzy":        req.Fuzzy,
+		"site_source":  sites.Source(ctx),
+	})
+}
+
+// resolve returns the URL of path on the site
+func resolve(siteURL *url.URL, path string) string {
+	return siteURL.ResolveReference(&url.URL{Path: path}).String()
+}
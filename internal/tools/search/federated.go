@@ -0,0 +1,215 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+)
+
+// MaxFederatedSites bounds the sites of one federated search
+const MaxFederatedSites = 10
+
+// federatedConcurrency bounds how many sites a federated search searches
+// at once
+const federatedConcurrency = 4
+
+// siteSearch is one site of a federated search
+type siteSearch struct {
+	name    string
+	req     *SearchRequest
+	ctx     context.Context
+	siteURL *url.URL
+	cancel  context.CancelFunc
+}
+
+// siteResult is the outcome of searching one site of a federated search
+type siteResult struct {
+	Site         string                  `json:"site"`
+	Alias        string                  `json:"alias,omitempty"`
+	Success      bool                    `json:"success"`
+	SearchMethod string                  `json:"search_method,omitempty"`
+	TotalResults int                     `json:"total_results"`
+	Attempts     []Attempt               `json:"attempts"`
+	Error        *toolerrors.ErrorDetail `json:"error,omitempty"`
+}
+
+// validateFederation checks the options of a federated search, which
+// takes its sites from Sites alone
+func (r *SearchRequest) validateFederation() error {
+	switch {
+	case r.HugoSitePath != "" || r.Site != "":
+		return fmt.Errorf("use either sites or hugo_site_path and site, not both")
+	case len(r.Sites) > MaxFederatedSites:
+		return fmt.Errorf("at most %d sites may be searched at once", MaxFederatedSites)
+	case len(r.Queries) > 0:
+		return fmt.Errorf("queries can't be combined with sites")
+	case r.Explain:
+		return fmt.Errorf("explain is not supported with sites")
+	case r.Auth != nil:
+		return fmt.Errorf("auth can't be combined with sites; register the sites with their credentials instead")
+	}
+	seen := make(map[string]bool)
+	for i, name := range r.Sites {
+		if name == "" {
+			return fmt.Errorf("sites[%d] is empty", i)
+		}
+		if seen[name] {
+			return fmt.Errorf("sites[%d] repeats %s", i, name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// forSite returns a copy of the request searching the site named by a URL
+// or a registered alias
+func (r *SearchRequest) forSite(name string) *SearchRequest {
+	single := *r
+	single.Sites = nil
+	if sites.IsAlias(name) {
+		single.Site = name
+	} else {
+		single.HugoSitePath = name
+	}
+	return &single
+}
+
+// executeFederated searches each of the request's sites, a bounded number
+// at a time, and merges their results. Every site is resolved and
+// validated first, so a mistyped alias fails the call before any search.
+// Sites that fail are reported without failing the others.
+func (t *Tool) executeFederated(ctx context.Context, req *SearchRequest) (*mcp_golang.ToolResponse, error) {
+	if err := req.validateFederation(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	searches := make([]*siteSearch, 0, len(req.Sites))
+	defer func() {
+		for _, search := range searches {
+			search.cancel()
+		}
+	}()
+	for _, name := range req.Sites {
+		single := req.forSite(name)
+		siteCtx, siteURL, cancel, err := t.prepare(ctx, single)
+		if err != nil {
+			return nil, toolerrors.Wrap(toolerrors.Code(err), fmt.Errorf("site %s: %w", name, err))
+		}
+		searches = append(searches, &siteSearch{name: name, req: single, ctx: siteCtx, siteURL: siteURL, cancel: cancel})
+	}
+
+	// Each site returns the results up to the end of the requested page,
+	// which are all the merged page can hold
+	page := *searches[0].req
+	for _, search := range searches {
+		search.req.Offset, search.req.Page, search.req.Limit = 0, 0, page.Offset+page.Limit
+	}
+
+	summaries := make([]siteResult, len(searches))
+	found := make([][]map[string]interface{}, len(searches))
+	errs := make([]error, len(searches))
+	sem := make(chan struct{}, federatedConcurrency)
+	var wg sync.WaitGroup
+	for i, search := range searches {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, search *siteSearch) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			tried := &attempts{}
+			results, metadata, err := t.search(search.ctx, search.siteURL, search.req, tried)
+			summaries[i] = siteResult{Site: search.siteURL.String(), Alias: search.req.Site, Success: err == nil, Attempts: tried.all()}
+			if err != nil {
+				errs[i] = err
+				detail := toolerrors.FromError(err, map[string]interface{}{"site": search.name})
+				summaries[i].Error = &detail
+				return
+			}
+			summaries[i].SearchMethod, _ = metadata["search_method"].(string)
+			summaries[i].TotalResults, _ = metadata["total_results"].(int)
+			found[i] = results
+		}(i, search)
+	}
+	wg.Wait()
+
+	errors := []toolerrors.ErrorDetail{}
+	for _, summary := range summaries {
+		if summary.Error != nil {
+			errors = append(errors, *summary.Error)
+		}
+	}
+	if len(errors) == len(summaries) {
+		t.log.ErrorContext(ctx, "Every site of the federated search failed", "sites", len(summaries), "error", errs[0])
+		return nil, &toolerrors.Error{
+			Code: toolerrors.Code(errs[0]),
+			Err:  fmt.Errorf("every site failed: %w", errs[0]),
+			Data: map[string]interface{}{"sites": summaries},
+		}
+	}
+
+	merged, total := mergeSiteResults(summaries, found, page.Sort)
+	results := paginate(merged, page.Offset, page.Limit)
+	metadata := map[string]interface{}{
+		"search_method": "federated",
+		"site_count":    len(summaries),
+		"failed_count":  len(errors),
+		"sort":          page.Sort,
+	}
+	addPaginationMetadata(metadata, &page, total, len(results))
+
+	response := map[string]interface{}{
+		"success":  true,
+		"query":    req.Query,
+		"results":  results,
+		"sites":    summaries,
+		"metadata": metadata,
+		"errors":   errors,
+	}
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal search results", "error", err)
+		return nil, fmt.Errorf("failed to marshal search results: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Federated search completed", "query", req.Query, "sites", len(summaries), "failed", len(errors), "results", len(results))
+	return tools.TextResponse(responseJSON), nil
+}
+
+// mergeSiteResults attributes each site's results to it and merges them,
+// returning the merged results and the total across sites. Relevance
+// scores of different sites and search methods aren't comparable, so for
+// relevance the sites take turns: every site's best result comes first,
+// then every site's second, in the order the sites were given. Other sorts
+// order the merged results as they would one site's.
+func mergeSiteResults(summaries []siteResult, found [][]map[string]interface{}, sortBy string) ([]map[string]interface{}, int) {
+	var merged []map[string]interface{}
+	total := 0
+	for i, results := range found {
+		total += summaries[i].TotalResults
+		for rank, result := range results {
+			result["site"] = summaries[i].Site
+			if summaries[i].Alias != "" {
+				result["site_alias"] = summaries[i].Alias
+			}
+			result["site_rank"] = rank + 1
+			merged = append(merged, result)
+		}
+	}
+
+	if sortBy == SortRelevance {
+		sort.SliceStable(merged, func(i, j int) bool {
+			return merged[i]["site_rank"].(int) < merged[j]["site_rank"].(int)
+		})
+	} else {
+		sortResults(merged, sortBy)
+	}
+	return merged, total
+}
@@ -0,0 +1,97 @@
+package search
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// searchServer serves results at /search.json
+func searchServer(results string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"results": ` + results + `}`))
+	}))
+}
+
+func TestSearchRequest_ValidateFederation(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     SearchRequest
+		wantErr string
+	}{
+		{name: "valid", req: SearchRequest{Sites: []string{"blog", "https://example.com"}}},
+		{name: "site path", req: SearchRequest{HugoSitePath: "https://example.com", Sites: []string{"blog"}}, wantErr: "use either sites or hugo_site_path and site, not both"},
+		{name: "too many", req: SearchRequest{Sites: []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}}, wantErr: "at most 10 sites may be searched at once"},
+		{name: "queries", req: SearchRequest{Sites: []string{"blog"}, Queries: []string{"golang"}}, wantErr: "queries can't be combined with sites"},
+		{name: "auth", req: SearchRequest{Sites: []string{"blog"}, AuthOptions: httpclient.AuthOptions{Auth: &httpclient.Credentials{BearerToken: "secret"}}}, wantErr: "auth can't be combined with sites; register the sites with their credentials instead"},
+		{name: "repeated", req: SearchRequest{Sites: []string{"blog", "blog"}}, wantErr: "sites[1] repeats blog"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.validateFederation()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTool_Execute_Federated(t *testing.T) {
+	blog := searchServer(`[
+		{"title": "Golang tips", "url": "/posts/tips/", "score": 9},
+		{"title": "Golang errors", "url": "/posts/errors/", "score": 4}
+	]`)
+	defer blog.Close()
+	docs := searchServer(`[{"title": "Golang API", "url": "/docs/api/", "score": 1}]`)
+	defer docs.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	defer down.Close()
+
+	registry := sites.New()
+	require.NoError(t, registry.Register(sites.Site{Alias: "blog", URL: blog.URL}))
+	tool, err := New(WithSites(registry))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &SearchRequest{Sites: []string{"blog", docs.URL, down.URL}, Query: "golang"})
+	require.NoError(t, err)
+
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["/posts/tips/","/docs/api/","/posts/errors/"]`, result.Get("results.#.url").Raw, "sites take turns")
+	assert.Equal(t, `[1,1,2]`, result.Get("results.#.site_rank").Raw)
+	assert.Equal(t, blog.URL, result.Get("results.0.site").String())
+	assert.Equal(t, "blog", result.Get("results.0.site_alias").String())
+	assert.Equal(t, docs.URL, result.Get("results.1.site").String())
+	assert.False(t, result.Get("results.1.site_alias").Exists())
+
+	assert.Equal(t, `[true,true,false]`, result.Get("sites.#.success").Raw)
+	assert.Equal(t, MethodHugoNative, result.Get("sites.0.search_method").String())
+	assert.Equal(t, down.URL, result.Get("errors.0.context.site").String())
+	assert.Equal(t, int64(3), result.Get("metadata.total_results").Int())
+	assert.Equal(t, int64(1), result.Get("metadata.failed_count").Int())
+
+	resp, err = tool.Execute(context.Background(), &SearchRequest{Sites: []string{"blog", docs.URL}, Query: "golang", Sort: SortTitle, Limit: 2})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["Golang API","Golang errors"]`, result.Get("results.#.title").Raw)
+	assert.True(t, result.Get("metadata.has_more").Bool())
+
+	_, err = tool.Execute(context.Background(), &SearchRequest{Sites: []string{"blog", "unknown"}, Query: "golang"})
+	assert.Equal(t, toolerrors.ErrCodeInvalidRequest, toolerrors.Code(err))
+
+	_, err = tool.Execute(context.Background(), &SearchRequest{Sites: []string{down.URL}, Query: "golang"})
+	assert.ErrorContains(t, err, "every site failed")
+}
@@ -0,0 +1,74 @@
+package search
+
+import "unicode/utf8"
+
+// maxEdits returns how many edits a query word may be from a page word and
+// still match it fuzzily: none for words of up to 2 characters, 1 for up to
+// 5 and 2 for longer words, so short words don't match unrelated ones
+func maxEdits(token string) int {
+	switch n := utf8.RuneCountInString(token); {
+	case n <= 2:
+		return 0
+	case n <= 5:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// withinEdits reports whether a and b differ by at most k single character
+// insertions, deletions, substitutions or swaps of adjacent characters, the
+// most common typos
+func withinEdits(a, b string, k int) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra)-len(rb) > k || len(rb)-len(ra) > k {
+		return false
+	}
+
+	// Optimal string alignment distance by rows, stopping once every entry
+	// of a row exceeds k
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > k {
+			return false
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(rb)] <= k
+}
+
+// fuzzyMatches reports whether token is within the edits maxEdits allows
+// of word
+func fuzzyMatches(token, word string) bool {
+	return token == word || withinEdits(token, word, maxEdits(token))
+}
+
+// fuzzyFrequency returns how often the words fuzzily matching token occur,
+// given the frequencies of a field's words
+func fuzzyFrequency(freqs map[string]int, token string) int {
+	total := 0
+	for word, tf := range freqs {
+		if fuzzyMatches(token, word) {
+			total += tf
+		}
+	}
+	return total
+}
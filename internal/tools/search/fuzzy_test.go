@@ -0,0 +1,35 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithinEdits(t *testing.T) {
+	tests := []struct {
+		a, b string
+		k    int
+		want bool
+	}{
+		{a: "kubernet", b: "kubernete", k: 2, want: true},
+		{a: "golang", b: "golnag", k: 1, want: true},
+		{a: "golang", b: "glonag", k: 1, want: false},
+		{a: "golang", b: "glonag", k: 2, want: true},
+		{a: "rust", b: "rest", k: 1, want: true},
+		{a: "rust", b: "python", k: 2, want: false},
+		{a: "café", b: "cafe", k: 1, want: true},
+		{a: "", b: "ab", k: 1, want: false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, withinEdits(tt.a, tt.b, tt.k), "%s %s %d", tt.a, tt.b, tt.k)
+	}
+}
+
+func TestFuzzyMatches(t *testing.T) {
+	assert.True(t, fuzzyMatches("kubernet", "kubernete"))
+	assert.True(t, fuzzyMatches("rsut", "rust"))
+	assert.False(t, fuzzyMatches("go", "so"), "short words must match exactly")
+	assert.Equal(t, 3, fuzzyFrequency(map[string]int{"kubernete": 2, "kubernet": 1, "docker": 4}, "kubernets"))
+}
@@ -0,0 +1,258 @@
+package search
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/tidwall/gjson"
+)
+
+// maxInvertedIndexes bounds how many sites' inverted indexes are kept in
+// memory; the least recently searched is dropped first
+const maxInvertedIndexes = 8
+
+// indexedFields are the fields whose tokens are listed in an inverted
+// index. Terms scoped to other fields match against every page.
+var indexedFields = map[string]bool{
+	"": true, "title": true, "content": true, "body": true, "tags": true, "categories": true,
+}
+
+// invertedIndex is a site index tokenized once, listing the pages each
+// token occurs in, so repeated searches only score pages that can match
+type invertedIndex struct {
+	digest   string
+	pages    []gjson.Result
+	tokens   []map[string][]string
+	freqs    []map[string]map[string]int
+	postings map[string][]int
+	used     time.Time
+}
+
+// buildInvertedIndex tokenizes the searched fields of every page of index
+func buildInvertedIndex(ctx context.Context, index *hugoindex.SiteIndex) (*invertedIndex, error) {
+	inv := &invertedIndex{digest: index.Digest(), postings: make(map[string][]int)}
+	err := index.Pages(ctx, func(item gjson.Result) bool {
+		page := len(inv.pages)
+		d := newDocument(item)
+		seen := make(map[string]bool)
+		for _, field := range searchFields {
+			for _, token := range d.tokens(field.paths) {
+				if !seen[token] {
+					seen[token] = true
+					inv.postings[token] = append(inv.postings[token], page)
+				}
+			}
+			d.frequencies(field.paths)
+		}
+		inv.pages = append(inv.pages, item)
+		inv.tokens = append(inv.tokens, d.cache)
+		inv.freqs = append(inv.freqs, d.freqs)
+		return ctx.Err() == nil
+	})
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// document returns page for matching, with the tokens of its searched
+// fields already analyzed
+func (inv *invertedIndex) document(page int) *document {
+	return &document{
+		item:   inv.pages[page],
+		cache:  maps.Clone(inv.tokens[page]),
+		freqs:  maps.Clone(inv.freqs[page]),
+		scores: make(map[string]float64),
+	}
+}
+
+// search is searchIndex for the pages of the inverted index, scoring only
+// those holding the words the query requires
+func (inv *invertedIndex) search(ctx context.Context, req *SearchRequest) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+
+	query, err := req.matcher()
+	if err != nil {
+		return results, err
+	}
+
+	// Regular expressions may match any page
+	var pages []int
+	if keywords, ok := query.(*searchQuery); ok {
+		pages = inv.candidates(keywords.root)
+	}
+	if pages == nil {
+		pages = make([]int, len(inv.pages))
+		for i := range pages {
+			pages[i] = i
+		}
+	}
+
+	now := time.Now()
+	for _, page := range pages {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		if result, matched := matchPage(inv.document(page), query, req, now); matched {
+			results = append(results, result)
+		}
+	}
+
+	sortByRelevance(results)
+	return results, nil
+}
+
+// candidates returns the pages that may match node, in ascending order, or
+// nil when any page may
+func (inv *invertedIndex) candidates(node queryNode) []int {
+	switch n := node.(type) {
+	case *termNode:
+		if !indexedFields[n.field] {
+			return nil
+		}
+		if n.fuzzy {
+			return inv.fuzzyCandidates(n.tokens[0])
+		}
+		pages := inv.postings[n.tokens[0]]
+		for _, token := range n.tokens[1:] {
+			pages = intersect(pages, inv.postings[token])
+		}
+		if pages == nil {
+			pages = []int{}
+		}
+		return pages
+	case *andNode:
+		var pages []int
+		for _, child := range n.children {
+			if found := inv.candidates(child); found != nil {
+				if pages == nil {
+					pages = found
+				} else {
+					pages = intersect(pages, found)
+				}
+			}
+		}
+		return pages
+	case *orNode:
+		pages := []int{}
+		for _, child := range n.children {
+			found := inv.candidates(child)
+			if found == nil {
+				return nil
+			}
+			pages = union(pages, found)
+		}
+		return pages
+	default:
+		// Pages without a word may hold anything else
+		return nil
+	}
+}
+
+// fuzzyCandidates returns the pages holding a token that fuzzily matches
+// token, in ascending order
+func (inv *invertedIndex) fuzzyCandidates(token string) []int {
+	pages := []int{}
+	for indexed, found := range inv.postings {
+		if fuzzyMatches(token, indexed) {
+			pages = union(pages, found)
+		}
+	}
+	return pages
+}
+
+// intersect returns the pages in both ascending lists
+func intersect(a, b []int) []int {
+	pages := []int{}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			pages = append(pages, a[i])
+			i++
+			j++
+		}
+	}
+	return pages
+}
+
+// union returns the pages in either ascending list
+func union(a, b []int) []int {
+	pages := make([]int, 0, len(a)+len(b))
+	for i, j := 0, 0; i < len(a) || j < len(b); {
+		switch {
+		case j == len(b) || i < len(a) && a[i] < b[j]:
+			pages = append(pages, a[i])
+			i++
+		case i == len(a) || b[j] < a[i]:
+			pages = append(pages, b[j])
+			j++
+		default:
+			pages = append(pages, a[i])
+			i++
+			j++
+		}
+	}
+	return pages
+}
+
+// invertedIndexes holds the inverted indexes of the sites searched, each
+// kept while the index it was built from is unchanged
+type invertedIndexes struct {
+	mu    sync.Mutex
+	sites map[string]*invertedIndex
+}
+
+func newInvertedIndexes() *invertedIndexes {
+	return &invertedIndexes{sites: make(map[string]*invertedIndex)}
+}
+
+// get returns the inverted index of the site's index, building it unless
+// the one kept was built from the same data, and whether it was reused
+func (s *invertedIndexes) get(ctx context.Context, site string, index *hugoindex.SiteIndex) (*invertedIndex, bool, error) {
+	digest := index.Digest()
+	s.mu.Lock()
+	inv, ok := s.sites[site]
+	if ok && digest != "" && inv.digest == digest {
+		inv.used = time.Now()
+		s.mu.Unlock()
+		return inv, true, nil
+	}
+	s.mu.Unlock()
+
+	inv, err := buildInvertedIndex(ctx, index)
+	if err != nil || digest == "" {
+		return inv, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inv.used = time.Now()
+	s.sites[site] = inv
+	if len(s.sites) > maxInvertedIndexes {
+		sites := make([]string, 0, len(s.sites))
+		for site := range s.sites {
+			sites = append(sites, site)
+		}
+		sort.Slice(sites, func(i, j int) bool { return s.sites[sites[i]].used.Before(s.sites[sites[j]].used) })
+		for _, site := range sites[:len(sites)-maxInvertedIndexes] {
+			delete(s.sites, site)
+		}
+	}
+	return inv, false, nil
+}
+
+// terms returns how many distinct tokens the index lists
+func (inv *invertedIndex) terms() int {
+	return len(inv.postings)
+}
@@ -0,0 +1,104 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const invertedTestIndex = `[
+	{"title": "Learning Golang", "url": "/posts/golang/", "content": "Notes on goroutines and channels", "tags": ["go"], "author": "ann"},
+	{"title": "Rust for Gophers", "url": "/posts/rust/", "content": "Ownership explained for golang developers", "tags": ["rust"], "author": "bob"},
+	{"title": "Gardening", "url": "/posts/garden/", "content": "Tomatoes and web development weekends", "categories": ["life"], "author": "ann"},
+	{"title": "Web Development", "url": "/posts/web/", "content": "Building sites", "tags": ["web development"]}
+]`
+
+func TestInvertedIndex_Candidates(t *testing.T) {
+	inv, err := buildInvertedIndex(context.Background(), hugoindex.New("", []byte(invertedTestIndex)))
+	require.NoError(t, err)
+	assert.Len(t, inv.pages, 4)
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{query: "golang", want: []int{0, 1}},
+		{query: "golang rust", want: []int{1}},
+		{query: "golang OR tomatoes", want: []int{0, 1, 2}},
+		{query: `"web development"`, want: []int{2, 3}},
+		{query: "title:golang", want: []int{0, 1}},
+		{query: "missing", want: []int{}},
+		{query: "golang -rust", want: []int{0, 1}},
+		{query: "-rust", want: nil},
+		{query: "author:ann", want: nil},
+		{query: "golang OR author:ann", want: nil},
+		{query: "golang author:ann", want: []int{0, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := parseQuery(tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, inv.candidates(query.root))
+		})
+	}
+}
+
+func TestInvertedIndex_SearchMatchesScan(t *testing.T) {
+	ctx := context.Background()
+	index := hugoindex.New("", []byte(invertedTestIndex))
+	inv, err := buildInvertedIndex(ctx, index)
+	require.NoError(t, err)
+
+	for _, query := range []string{"golang", "golang -rust", `"web development"`, "author:ann", "tags:rust OR gardening", "developers"} {
+		for _, req := range []*SearchRequest{
+			{Query: query},
+			{Query: query, Match: MatchAny},
+			{Query: query, Fuzzy: true},
+		} {
+			scanned, err := searchIndex(ctx, index, req)
+			require.NoError(t, err)
+			indexed, err := inv.search(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, scanned, indexed, "%s (match %q, fuzzy %t)", query, req.Match, req.Fuzzy)
+		}
+	}
+
+	// Typos find the pages holding the words meant
+	indexed, err := inv.search(ctx, &SearchRequest{Query: "goroutnes", Fuzzy: true})
+	require.NoError(t, err)
+	require.Len(t, indexed, 1)
+	assert.Equal(t, "/posts/golang/", indexed[0]["url"])
+}
+
+func TestInvertedIndexes_Get(t *testing.T) {
+	ctx := context.Background()
+	indexes := newInvertedIndexes()
+
+	first, reused, err := indexes.get(ctx, "https://example.com", hugoindex.New("", []byte(invertedTestIndex)))
+	require.NoError(t, err)
+	assert.False(t, reused)
+
+	// The same data, loaded again, reuses the index built
+	again, reused, err := indexes.get(ctx, "https://example.com", hugoindex.New("", []byte(invertedTestIndex)))
+	require.NoError(t, err)
+	assert.True(t, reused)
+	assert.Same(t, first, again)
+
+	// Changed data is indexed again
+	changed, reused, err := indexes.get(ctx, "https://example.com", hugoindex.New("", []byte(`[{"title": "New"}]`)))
+	require.NoError(t, err)
+	assert.False(t, reused)
+	assert.Len(t, changed.pages, 1)
+
+	// The least recently searched sites are dropped
+	for i := 0; i < maxInvertedIndexes; i++ {
+		_, _, err := indexes.get(ctx, "https://example.com/"+string(rune('a'+i)), hugoindex.New("", []byte(invertedTestIndex)))
+		require.NoError(t, err)
+	}
+	assert.Len(t, indexes.sites, maxInvertedIndexes)
+	assert.NotContains(t, indexes.sites, "https://example.com")
+}
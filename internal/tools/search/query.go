@@ -0,0 +1,631 @@
+package search
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/tidwall/gjson"
+)
+
+// Query syntax
+//
+//	golang tutorial          both words (implicit AND)
+//	"hello world"            exact phrase
+//	title:golang             word in a specific field (title, tags, categories,
+//	                         content, or any other front matter field)
+//	tags:"web development"   phrase in a specific field
+//	golang AND NOT draft     explicit operators (upper case)
+//	golang OR rust           either word
+//	-draft                   exclude items containing draft
+//	(golang OR go) -draft    grouping
+//
+// With match "any", words joined without an operator need only one of them
+// to match; with fuzzy, words also match page words a typo or two away.
+
+// Relevance weights. A field's weight is applied per matching query term.
+const (
+	titleMatchWeight    = 10.0
+	titleExactWeight    = 20.0
+	titlePrefixWeight   = 5.0
+	phraseMatchWeight   = 5.0
+	taxonomyMatchWeight = 3.0
+	contentMatchWeight  = 1.0
+
+	// fuzzyMatchWeight scales the score of words matched despite typos
+	fuzzyMatchWeight = 0.5
+)
+
+// searchField is a part of an item that is tokenized and scored
+type searchField struct {
+	paths  []string
+	weight float64
+}
+
+var searchFields = []searchField{
+	{paths: []string{"title"}, weight: titleMatchWeight},
+	{paths: []string{"tags", "categories"}, weight: taxonomyMatchWeight},
+	{paths: []string{"content", "body", "summary"}, weight: contentMatchWeight},
+}
+
+// queryNode is a node of a parsed query. match reports whether the document
+// satisfies the node and how relevant it is.
+type queryNode interface {
+	match(d *document) (bool, float64)
+}
+
+type andNode struct{ children []queryNode }
+
+type orNode struct{ children []queryNode }
+
+type notNode struct{ child queryNode }
+
+// termNode matches a word, or several words as a phrase, optionally within a
+// single field. Fuzzy words also match words a few edits away.
+type termNode struct {
+	field  string
+	text   string
+	phrase bool
+	fuzzy  bool
+	tokens []string
+}
+
+func (n *andNode) match(d *document) (bool, float64) {
+	saved := d.saveScores()
+	total := 0.0
+	for _, child := range n.children {
+		ok, score := child.match(d)
+		if !ok {
+			d.scores = saved
+			return false, 0
+		}
+		total += score
+	}
+	return true, total
+}
+
+func (n *orNode) match(d *document) (bool, float64) {
+	matched := false
+	total := 0.0
+	for _, child := range n.children {
+		if ok, score := child.match(d); ok {
+			matched = true
+			total += score
+		}
+	}
+	return matched, total
+}
+
+func (n *notNode) match(d *document) (bool, float64) {
+	saved := d.saveScores()
+	ok, _ := n.child.match(d)
+	d.scores = saved
+	return !ok, 0
+}
+
+func (n *termNode) match(d *document) (bool, float64) {
+	matched := false
+	total := 0.0
+	for _, field := range fieldsFor(n.field) {
+		tokens := d.tokens(field.paths)
+		if len(n.tokens) > 1 {
+			if containsPhrase(tokens, n.tokens) {
+				matched = true
+				total += field.weight * phraseMatchWeight
+			}
+			continue
+		}
+		freqs := d.frequencies(field.paths)
+		if tf := freqs[n.tokens[0]]; tf > 0 {
+			matched = true
+			total += field.weight * (1 + math.Log(float64(tf)))
+		} else if n.fuzzy {
+			if tf := fuzzyFrequency(freqs, n.tokens[0]); tf > 0 {
+				matched = true
+				total += field.weight * (1 + math.Log(float64(tf))) * fuzzyMatchWeight
+			}
+		}
+	}
+	if matched && d.scores != nil {
+		d.scores[n.key()] += total
+	}
+	return matched, total
+}
+
+// key names the term in the score contributions of results
+func (n *termNode) key() string {
+	if n.field != "" {
+		return n.field + ":" + n.text
+	}
+	return n.text
+}
+
+// fieldsFor returns the parts of an item a term scoped to field is matched
+// against. Unscoped terms are matched against all searchFields.
+func fieldsFor(field string) []searchField {
+	switch field {
+	case "":
+		return searchFields
+	case "title":
+		return []searchField{{paths: []string{"title"}, weight: titleMatchWeight}}
+	case "content", "body":
+		return []searchField{{paths: []string{"content", "body", "summary"}, weight: contentMatchWeight}}
+	default:
+		// Taxonomies and other front matter fields
+		return []searchField{{paths: []string{field}, weight: taxonomyMatchWeight}}
+	}
+}
+
+// document lazily tokenizes the fields of an item being matched, and
+// collects how much each query term contributed to its score
+type document struct {
+	item   gjson.Result
+	cache  map[string][]string
+	freqs  map[string]map[string]int
+	scores map[string]float64
+}
+
+func newDocument(item gjson.Result) *document {
+	return &document{
+		item:   item,
+		cache:  make(map[string][]string),
+		freqs:  make(map[string]map[string]int),
+		scores: make(map[string]float64),
+	}
+}
+
+// saveScores returns a copy of the score contributions, for nodes to
+// restore when the terms matched within them don't count
+func (d *document) saveScores() map[string]float64 {
+	if d.scores == nil {
+		return nil
+	}
+	return maps.Clone(d.scores)
+}
+
+// tokens returns the analyzed tokens of the given paths, in order
+func (d *document) tokens(paths []string) []string {
+	key := strings.Join(paths, ",")
+	if tokens, ok := d.cache[key]; ok {
+		return tokens
+	}
+
+	var tokens []string
+	for _, path := range paths {
+		value := d.item.Get(path)
+		if value.IsArray() {
+			value.ForEach(func(_, v gjson.Result) bool {
+				tokens = append(tokens, analyze(v.String(), false)...)
+				return true
+			})
+		} else if value.Exists() {
+			tokens = append(tokens, analyze(value.String(), false)...)
+		}
+	}
+	d.cache[key] = tokens
+	return tokens
+}
+
+// frequencies returns the term frequencies of the given paths
+func (d *document) frequencies(paths []string) map[string]int {
+	key := strings.Join(paths, ",")
+	if freqs, ok := d.freqs[key]; ok {
+		return freqs
+	}
+	freqs := termFrequencies(d.tokens(paths))
+	d.freqs[key] = freqs
+	return freqs
+}
+
+// searchQuery is a parsed search query
+type searchQuery struct {
+	raw  string
+	root queryNode
+
+	// terms are the tokens highlighted in snippets of matching pages, and
+	// also page words a few edits away from them when fuzzy
+	terms map[string]bool
+	fuzzy bool
+
+	// simple queries use no operators, fields or grouping and can be sent to
+	// native search endpoints unchanged
+	simple bool
+}
+
+// Values of SearchRequest.Match
+const (
+	MatchAll = "all"
+	MatchAny = "any"
+)
+
+// queryOptions change how a query's words are matched
+type queryOptions struct {
+	// any joins words given without an operator with OR instead of AND
+	any bool
+
+	// fuzzy lets words match page words within maxEdits of them
+	fuzzy bool
+}
+
+// highlighted reports whether token is the analyzed form of a word
+// highlighted in snippets
+func (q *searchQuery) highlighted(token string) bool {
+	if q == nil {
+		return false
+	}
+	if q.terms[token] {
+		return true
+	}
+	if q.fuzzy {
+		for term := range q.terms {
+			if fuzzyMatches(term, token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseQuery parses a search query. Unscoped stop words are ignored unless the
+// query consists only of stop words.
+func parseQuery(query string) (*searchQuery, error) {
+	return parseQueryWith(query, queryOptions{})
+}
+
+// parseQueryWith is parseQuery with options
+func parseQueryWith(query string, opts queryOptions) (*searchQuery, error) {
+	tokens := lexQuery(query)
+
+	simple := true
+	for _, tok := range tokens {
+		if tok.kind != tokWord && tok.kind != tokPhrase || tok.field != "" {
+			simple = false
+			break
+		}
+	}
+
+	q := &searchQuery{
+		raw:    strings.ToLower(strings.TrimSpace(strings.ReplaceAll(query, `"`, ""))),
+		simple: simple,
+		fuzzy:  opts.fuzzy,
+	}
+
+	for _, keepStopWords := range []bool{false, true} {
+		p := &queryParser{tokens: tokens, keepStopWords: keepStopWords, opts: opts}
+		root, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		if root != nil {
+			q.root = root
+			q.terms = highlightTerms(root)
+			return q, nil
+		}
+	}
+
+	return nil, fmt.Errorf("query must contain at least one search term")
+}
+
+// nativeQuery returns the query to send to native search endpoints. Queries
+// using the extended syntax are reduced to their positive terms; results are
+// then filtered locally.
+func (q *searchQuery) nativeQuery(original string) string {
+	if q.simple {
+		return original
+	}
+
+	var words []string
+	var collect func(node queryNode)
+	collect = func(node queryNode) {
+		switch n := node.(type) {
+		case *andNode:
+			for _, child := range n.children {
+				collect(child)
+			}
+		case *orNode:
+			for _, child := range n.children {
+				collect(child)
+			}
+		case *termNode:
+			if n.phrase {
+				words = append(words, `"`+n.text+`"`)
+			} else {
+				words = append(words, n.text)
+			}
+		}
+	}
+	collect(q.root)
+	return strings.Join(words, " ")
+}
+
+// score returns the relevance of item and whether it matches the query
+func (q *searchQuery) score(item gjson.Result) (float64, bool) {
+	return q.scoreDocument(newDocument(item))
+}
+
+// scoreDocument is score for an item being matched
+func (q *searchQuery) scoreDocument(d *document) (float64, bool) {
+	matched, score := q.root.match(d)
+	if !matched {
+		return 0, false
+	}
+
+	// Reward titles that are, or start with, the whole query
+	if title := strings.ToLower(d.item.Get("title").String()); title != "" {
+		if title == q.raw {
+			score += titleExactWeight
+		} else if strings.HasPrefix(title, q.raw) {
+			score += titlePrefixWeight
+		}
+	}
+
+	return score, true
+}
+
+type queryTokenKind int
+
+const (
+	tokWord queryTokenKind = iota
+	tokPhrase
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type queryToken struct {
+	kind  queryTokenKind
+	text  string
+	field string
+}
+
+// lexQuery splits a query into tokens. It never fails: stray quotes run to
+// the end of the query and unknown characters are left to the analyzer.
+func lexQuery(query string) []queryToken {
+	var tokens []queryToken
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokLParen})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokRParen})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != '-':
+			tokens = append(tokens, queryToken{kind: tokNot})
+			i++
+		case r == '"':
+			text, next := readPhrase(runes, i)
+			tokens = append(tokens, queryToken{kind: tokPhrase, text: text})
+			i = next
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' && runes[i] != '"' {
+				i++
+			}
+			word := string(runes[start:i])
+
+			switch word {
+			case "AND", "&&":
+				tokens = append(tokens, queryToken{kind: tokAnd})
+				continue
+			case "OR", "||":
+				tokens = append(tokens, queryToken{kind: tokOr})
+				continue
+			case "NOT":
+				tokens = append(tokens, queryToken{kind: tokNot})
+				continue
+			}
+
+			// field:value and field:"a phrase"
+			if field, value, ok := strings.Cut(word, ":"); ok && isFieldName(field) {
+				field = strings.ToLower(field)
+				if value == "" && i < len(runes) && runes[i] == '"' {
+					text, next := readPhrase(runes, i)
+					tokens = append(tokens, queryToken{kind: tokPhrase, text: text, field: field})
+					i = next
+					continue
+				}
+				if value != "" {
+					tokens = append(tokens, queryToken{kind: tokWord, text: value, field: field})
+					continue
+				}
+			}
+
+			tokens = append(tokens, queryToken{kind: tokWord, text: word})
+		}
+	}
+
+	return tokens
+}
+
+// readPhrase reads a quoted phrase starting at the opening quote
+func readPhrase(runes []rune, start int) (string, int) {
+	end := start + 1
+	for end < len(runes) && runes[end] != '"' {
+		end++
+	}
+	text := string(runes[start+1 : end])
+	if end < len(runes) {
+		end++ // closing quote
+	}
+	return text, end
+}
+
+func isFieldName(s string) bool {
+	if s == "" || !unicode.IsLetter([]rune(s)[0]) {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// queryParser is a recursive descent parser over query tokens:
+//
+//	or    = and { OR and }
+//	and   = unary { [AND] unary }
+//	unary = NOT unary | primary
+//	primary = "(" or ")" | term
+type queryParser struct {
+	tokens        []queryToken
+	pos           int
+	keepStopWords bool
+	opts          queryOptions
+}
+
+func (p *queryParser) parse() (queryNode, error) {
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected ')' in query")
+	}
+	return node, nil
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return queryToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	var children []queryNode
+	for {
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			children = append(children, node)
+		}
+
+		tok, ok := p.peek()
+		if !ok || tok.kind != tokOr {
+			break
+		}
+		p.pos++
+		if next, ok := p.peek(); !ok || next.kind == tokRParen || next.kind == tokOr {
+			return nil, fmt.Errorf("OR must be followed by a search term")
+		}
+	}
+	return combine(children, func(c []queryNode) queryNode { return &orNode{children: c} }), nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	var children []queryNode
+	explicit := false
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind == tokOr || tok.kind == tokRParen {
+			break
+		}
+		if tok.kind == tokAnd {
+			explicit = true
+			p.pos++
+			if next, ok := p.peek(); !ok || next.kind == tokRParen || next.kind == tokOr || next.kind == tokAnd {
+				return nil, fmt.Errorf("AND must be followed by a search term")
+			}
+			continue
+		}
+
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			children = append(children, node)
+		}
+	}
+
+	// Words given without AND need only one of them to match when matching
+	// any, while exclusions still apply
+	if p.opts.any && !explicit {
+		var words, exclusions []queryNode
+		for _, child := range children {
+			if _, ok := child.(*notNode); ok {
+				exclusions = append(exclusions, child)
+			} else {
+				words = append(words, child)
+			}
+		}
+		if word := combine(words, func(c []queryNode) queryNode { return &orNode{children: c} }); word != nil {
+			children = append([]queryNode{word}, exclusions...)
+		}
+	}
+	return combine(children, func(c []queryNode) queryNode { return &andNode{children: c} }), nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	tok, _ := p.peek()
+	if tok.kind != tokNot {
+		return p.parsePrimary()
+	}
+
+	p.pos++
+	if next, ok := p.peek(); !ok || next.kind == tokRParen || next.kind == tokOr || next.kind == tokAnd {
+		return nil, fmt.Errorf("NOT must be followed by a search term")
+	}
+	child, err := p.parseUnary()
+	if err != nil || child == nil {
+		return nil, err
+	}
+	return &notNode{child: child}, nil
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	tok, _ := p.peek()
+	p.pos++
+
+	if tok.kind == tokLParen {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next, ok := p.peek(); !ok || next.kind != tokRParen {
+			return nil, fmt.Errorf("missing ')' in query")
+		}
+		p.pos++
+		return node, nil
+	}
+
+	// Stop words only carry meaning inside phrases or when scoped to a field
+	dropStopWords := !p.keepStopWords && tok.kind == tokWord && tok.field == ""
+	tokens := analyze(tok.text, dropStopWords)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return &termNode{
+		field:  tok.field,
+		text:   tok.text,
+		phrase: tok.kind == tokPhrase,
+		fuzzy:  p.opts.fuzzy && len(tokens) == 1,
+		tokens: tokens,
+	}, nil
+}
+
+// combine builds a node from children, collapsing trivial cases
+func combine(children []queryNode, build func([]queryNode) queryNode) queryNode {
+	switch len(children) {
+	case 0:
+		return nil
+	case 1:
+		return children[0]
+	default:
+		return build(children)
+	}
+}
@@ -0,0 +1,160 @@
+package search
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestLexQuery(t *testing.T) {
+	tokens := lexQuery(`title:go (a OR "b c") -draft tags:"web dev" AND NOT x`)
+
+	kinds := []queryTokenKind{}
+	for _, tok := range tokens {
+		kinds = append(kinds, tok.kind)
+	}
+	assert.Equal(t, []queryTokenKind{
+		tokWord, tokLParen, tokWord, tokOr, tokPhrase, tokRParen,
+		tokNot, tokWord, tokPhrase, tokAnd, tokNot, tokWord,
+	}, kinds)
+
+	assert.Equal(t, queryToken{kind: tokWord, text: "go", field: "title"}, tokens[0])
+	assert.Equal(t, queryToken{kind: tokPhrase, text: "b c"}, tokens[4])
+	assert.Equal(t, queryToken{kind: tokPhrase, text: "web dev", field: "tags"}, tokens[8])
+}
+
+func TestLexQuery_PlainWords(t *testing.T) {
+	// Hyphenated words, times and lower-case operators are ordinary words
+	tokens := lexQuery("well-known 10:30 and or")
+	require.Len(t, tokens, 4)
+	for _, tok := range tokens {
+		assert.Equal(t, tokWord, tok.kind)
+	}
+	assert.Equal(t, queryToken{kind: tokWord, text: "10:30"}, tokens[1])
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		simple  bool
+		wantErr bool
+	}{
+		{name: "single term", query: "golang", simple: true},
+		{name: "terms and phrase", query: `"hello world" go`, simple: true},
+		{name: "only stop words", query: "the", simple: true},
+		{name: "field", query: "title:go", simple: false},
+		{name: "operators", query: "go OR rust", simple: false},
+		{name: "exclusion", query: "go -draft", simple: false},
+		{name: "unclosed group", query: "(go", wantErr: true},
+		{name: "unexpected close", query: "go)", wantErr: true},
+		{name: "dangling and", query: "go AND", wantErr: true},
+		{name: "dangling or", query: "go OR", wantErr: true},
+		{name: "dangling not", query: "go NOT", wantErr: true},
+		{name: "empty", query: "  ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := parseQuery(tt.query)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.simple, q.simple)
+		})
+	}
+}
+
+func TestParseQuery_StopWords(t *testing.T) {
+	q, err := parseQuery("the best tutorials")
+	require.NoError(t, err)
+	and, ok := q.root.(*andNode)
+	require.True(t, ok)
+	assert.Len(t, and.children, 2)
+
+	// Stop words are kept when scoped to a field
+	q, err = parseQuery("title:the")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"the"}, q.root.(*termNode).tokens)
+}
+
+func TestSearchQuery_NativeQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "golang tutorial", expected: "golang tutorial"},
+		{query: `"hello world"`, expected: `"hello world"`},
+		{query: "title:golang AND tags:tutorial -draft", expected: "golang tutorial"},
+		{query: `(go OR rust) tags:"web dev"`, expected: `go rust "web dev"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := parseQuery(tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, q.nativeQuery(tt.query))
+		})
+	}
+}
+
+func TestParseQueryWith(t *testing.T) {
+	doc := func(content string) *document {
+		return newDocument(gjson.Parse(`{"title": "Notes", "content": "` + content + `"}`))
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		opts    queryOptions
+		content string
+		want    bool
+	}{
+		{name: "all needs every word", query: "golang rust", content: "golang only", want: false},
+		{name: "any needs one word", query: "golang rust", opts: queryOptions{any: true}, content: "golang only", want: true},
+		{name: "any keeps exclusions", query: "golang rust -draft", opts: queryOptions{any: true}, content: "golang draft", want: false},
+		{name: "any keeps explicit and", query: "golang AND rust", opts: queryOptions{any: true}, content: "golang only", want: false},
+		{name: "exact by default", query: "kubernets", content: "running kubernetes", want: false},
+		{name: "fuzzy", query: "kubernets", opts: queryOptions{fuzzy: true}, content: "running kubernetes", want: true},
+		{name: "fuzzy exclusion", query: "golang -kubernets", opts: queryOptions{fuzzy: true}, content: "golang on kubernetes", want: false},
+		{name: "phrases stay exact", query: `"kubernets cluster"`, opts: queryOptions{fuzzy: true}, content: "kubernetes cluster", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := parseQueryWith(tt.query, tt.opts)
+			require.NoError(t, err)
+			_, matched := q.scoreDocument(doc(tt.content))
+			assert.Equal(t, tt.want, matched)
+		})
+	}
+}
+
+func TestSearchQuery_TermScores(t *testing.T) {
+	q, err := parseQueryWith("golang kubernets (rust AND missing) -python", queryOptions{any: true, fuzzy: true})
+	require.NoError(t, err)
+
+	d := newDocument(gjson.Parse(`{"title": "Golang", "content": "golang on kubernetes with rust"}`))
+	score, matched := q.scoreDocument(d)
+	require.True(t, matched)
+
+	// Terms within groups that failed to match contribute nothing
+	assert.Equal(t, []string{"golang", "kubernets"}, sortedKeys(d.scores))
+	assert.Greater(t, d.scores["golang"], d.scores["kubernets"])
+	assert.LessOrEqual(t, d.scores["golang"]+d.scores["kubernets"], score)
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,126 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/tidwall/gjson"
+)
+
+// Limits of regex searches. Go's regular expressions run in time linear in
+// the text, so bounding the pattern's size and the search's duration bounds
+// the work a pattern can cause.
+const (
+	MaxRegexLength = 256
+	RegexTimeout   = 10 * time.Second
+
+	// maxRegexInstructions bounds the compiled program of a pattern, which
+	// repetition such as (\w+\s?){1000} makes far larger than its source
+	maxRegexInstructions = 5000
+
+	// maxRegexMatches bounds the matches counted in one field
+	maxRegexMatches = 1000
+)
+
+// errRegexTimeout stops regex searches running longer than RegexTimeout
+var errRegexTimeout = toolerrors.Errorf(toolerrors.ErrCodeTimeout, "regex search exceeded its time limit of %s", RegexTimeout)
+
+// matcher scores pages against the query of a search mode
+type matcher interface {
+	// scoreDocument returns the relevance of the page and whether it matches
+	scoreDocument(d *document) (float64, bool)
+
+	// snippet returns the part of text around the matches, with their spans
+	snippet(text string, length int) (string, []highlight)
+}
+
+// regexQuery matches pages whose title, taxonomies, content, body or
+// summary match a regular expression
+type regexQuery struct {
+	re *regexp.Regexp
+}
+
+// compileRegex compiles pattern, refusing patterns too large to search with
+// safely and patterns matching empty text, which would match every page
+func compileRegex(pattern string) (*regexQuery, error) {
+	if len(pattern) > MaxRegexLength {
+		return nil, fmt.Errorf("regex must be at most %d bytes", MaxRegexLength)
+	}
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+	if len(prog.Inst) > maxRegexInstructions {
+		return nil, fmt.Errorf("regex is too complex")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+	if re.MatchString("") {
+		return nil, fmt.Errorf("regex must not match empty text")
+	}
+	return &regexQuery{re: re}, nil
+}
+
+// scoreDocument implements matcher, weighting the matches in each field as
+// keyword searches weight words
+func (q *regexQuery) scoreDocument(d *document) (float64, bool) {
+	total := 0.0
+	for _, field := range searchFields {
+		if count := len(q.re.FindAllStringIndex(fieldText(d.item, field.paths), maxRegexMatches)); count > 0 {
+			total += field.weight * (1 + math.Log(float64(count)))
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	if d.scores != nil {
+		d.scores[q.re.String()] = total
+	}
+	return total, true
+}
+
+// snippet implements matcher
+func (q *regexQuery) snippet(text string, length int) (string, []highlight) {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+
+	// Convert the byte offsets of the matches into rune offsets
+	var matches []wordSpan
+	for _, loc := range q.re.FindAllStringIndex(text, maxRegexMatches) {
+		if loc[0] < loc[1] {
+			start := utf8.RuneCountInString(text[:loc[0]])
+			matches = append(matches, wordSpan{start: start, end: start + utf8.RuneCountInString(text[loc[0]:loc[1]])})
+		}
+	}
+	return window(runes, splitWords(runes), matches, length)
+}
+
+// fieldText returns the values of the given paths, array elements and
+// fields on lines of their own
+func fieldText(item gjson.Result, paths []string) string {
+	var values []string
+	for _, path := range paths {
+		value := item.Get(path)
+		if value.IsArray() {
+			value.ForEach(func(_, v gjson.Result) bool {
+				values = append(values, v.String())
+				return true
+			})
+		} else if value.Exists() {
+			values = append(values, value.String())
+		}
+	}
+	return strings.Join(values, "\n")
+}
@@ -0,0 +1,53 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestCompileRegex(t *testing.T) {
+	_, err := compileRegex(`v\d+\.\d+\.\d+`)
+	assert.NoError(t, err)
+
+	for pattern, want := range map[string]string{
+		strings.Repeat("a", MaxRegexLength+1): "regex must be at most 256 bytes",
+		`(\w+\s?){1000}`:                      "regex is too complex",
+		`a*`:                                  "regex must not match empty text",
+		`v\d+(`:                               "invalid regex: error parsing regexp: missing closing ): `v\\d+(`",
+	} {
+		_, err := compileRegex(pattern)
+		assert.EqualError(t, err, want, pattern)
+	}
+}
+
+func TestRegexQuery_ScoreDocument(t *testing.T) {
+	query, err := compileRegex(`\{\{<\s*youtube`)
+	require.NoError(t, err)
+
+	d := &document{item: gjson.Parse(`{"title": "Videos", "content": "{{< youtube a >}} {{<youtube b >}}"}`), scores: map[string]float64{}}
+	score, ok := query.scoreDocument(d)
+	assert.True(t, ok)
+	assert.Greater(t, score, 0.0)
+	assert.Equal(t, score, d.scores[`\{\{<\s*youtube`])
+
+	_, ok = query.scoreDocument(&document{item: gjson.Parse(`{"title": "Videos", "content": "youtube links"}`)})
+	assert.False(t, ok)
+}
+
+func TestRegexQuery_Snippet(t *testing.T) {
+	query, err := compileRegex(`v\d+\.\d+`)
+	require.NoError(t, err)
+
+	text, highlights := query.snippet("Upgrade to  v1.2 from v0.9 now", 200)
+	assert.Equal(t, "Upgrade to v1.2 from v0.9 now", text)
+	assert.Equal(t, []highlight{{Start: 11, End: 15}, {Start: 21, End: 25}}, highlights)
+
+	// Offsets are in characters, not bytes
+	text, highlights = query.snippet("Écrit en v2.0", 200)
+	assert.Equal(t, "Écrit en v2.0", text)
+	assert.Equal(t, []highlight{{Start: 9, End: 13}}, highlights)
+}
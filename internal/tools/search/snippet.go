@@ -0,0 +1,195 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/tidwall/gjson"
+)
+
+// Snippet lengths, in characters
+const (
+	DefaultSnippetLength = 200
+	MinSnippetLength     = 50
+	MaxSnippetLength     = 1000
+)
+
+// snippetEllipsis marks text cut from either end of a snippet
+const snippetEllipsis = "..."
+
+// snippetPaths are the fields snippets are taken from, in order of preference
+var snippetPaths = []string{"content", "body", "summary"}
+
+// highlight is the span of a matched word in a snippet, as offsets in
+// characters (Unicode code points) with end exclusive, for clients to wrap
+// in <mark> or similar
+type highlight struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// wordSpan is a word of a text, as rune offsets
+type wordSpan struct {
+	start, end int
+}
+
+// highlightTerms returns the analyzed tokens whose occurrences in page text
+// explain a match: the words of the query's positive terms that are not
+// scoped to a field other than the content
+func highlightTerms(root queryNode) map[string]bool {
+	terms := make(map[string]bool)
+	var collect func(node queryNode)
+	collect = func(node queryNode) {
+		switch n := node.(type) {
+		case *andNode:
+			for _, child := range n.children {
+				collect(child)
+			}
+		case *orNode:
+			for _, child := range n.children {
+				collect(child)
+			}
+		case *termNode:
+			if n.field == "" || n.field == "content" || n.field == "body" || n.field == "summary" {
+				for _, token := range n.tokens {
+					terms[token] = true
+				}
+			}
+		}
+	}
+	collect(root)
+	return terms
+}
+
+// addSnippet sets the snippet of result to the part of item's content,
+// body or summary around its best cluster of the query's matches, with the
+// highlights of the matches in it. Without a query, the snippet is the start
+// of the text. Lengths that are not set give DefaultSnippetLength.
+func addSnippet(result map[string]interface{}, item gjson.Result, query matcher, length int) {
+	if length <= 0 {
+		length = DefaultSnippetLength
+	}
+	if query == nil {
+		query = (*searchQuery)(nil)
+	}
+	for _, path := range snippetPaths {
+		if text := item.Get(path).String(); strings.TrimSpace(text) != "" {
+			text, highlights := query.snippet(text, length)
+			result["snippet"] = text
+			if len(highlights) > 0 {
+				result["highlights"] = highlights
+			}
+			return
+		}
+	}
+}
+
+// snippet implements matcher, highlighting the words whose analyzed form
+// the query highlights
+func (q *searchQuery) snippet(text string, length int) (string, []highlight) {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	words := splitWords(runes)
+
+	var matches []wordSpan
+	for _, word := range words {
+		if q.highlighted(stem(strings.ToLower(string(runes[word.start:word.end])))) {
+			matches = append(matches, word)
+		}
+	}
+	return window(runes, words, matches, length)
+}
+
+// window returns about length characters of runes centered on the part
+// holding the most matches, cut at word boundaries, with the spans of the
+// matches in it. Text without matches gives its start.
+func window(runes []rune, words, matches []wordSpan, length int) (string, []highlight) {
+	if len(runes) <= length {
+		return string(runes), highlightsIn(matches, 0, len(runes), 0)
+	}
+
+	// The window starting at the match followed by the most others within
+	// length holds the best cluster; it is centered on that cluster
+	start := 0
+	if len(matches) > 0 {
+		best, bestCount := 0, 0
+		for i, match := range matches {
+			count := 1
+			for _, other := range matches[i+1:] {
+				if other.end-match.start > length {
+					break
+				}
+				count++
+			}
+			if count > bestCount {
+				best, bestCount = i, count
+			}
+		}
+		clusterEnd := matches[best+bestCount-1].end
+		start = matches[best].start - (length-(clusterEnd-matches[best].start))/2
+	}
+	start = max(0, min(start, len(runes)-length))
+	end := start + length
+
+	// Cut at word boundaries, unless the window is within a single word
+	cutStart, cutEnd := start, end
+	for _, word := range words {
+		if word.start < cutStart && word.end > cutStart {
+			cutStart = word.end
+		}
+		if word.start < cutEnd && word.end > cutEnd {
+			cutEnd = word.start
+		}
+	}
+	for cutStart < cutEnd && runes[cutStart] == ' ' {
+		cutStart++
+	}
+	for cutEnd > cutStart && runes[cutEnd-1] == ' ' {
+		cutEnd--
+	}
+	if cutStart < cutEnd {
+		start, end = cutStart, cutEnd
+	}
+
+	var b strings.Builder
+	offset := 0
+	if start > 0 {
+		b.WriteString(snippetEllipsis)
+		offset = len(snippetEllipsis)
+	}
+	b.WriteString(string(runes[start:end]))
+	if end < len(runes) {
+		b.WriteString(snippetEllipsis)
+	}
+	return b.String(), highlightsIn(matches, start, end, offset)
+}
+
+// splitWords returns the words of runes as analyze splits them
+func splitWords(runes []rune) []wordSpan {
+	var words []wordSpan
+	start := -1
+	for i, r := range runes {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if inWord && start < 0 {
+			start = i
+		} else if !inWord && start >= 0 {
+			words = append(words, wordSpan{start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, wordSpan{start: start, end: len(runes)})
+	}
+	return words
+}
+
+// highlightsIn returns the spans of the matches within [start, end) of the
+// text, relative to a snippet holding that part of it from offset
+func highlightsIn(matches []wordSpan, start, end, offset int) []highlight {
+	var highlights []highlight
+	for _, match := range matches {
+		if match.start >= start && match.end <= end {
+			highlights = append(highlights, highlight{Start: match.start - start + offset, End: match.end - start + offset})
+		}
+	}
+	return highlights
+}
@@ -0,0 +1,102 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// marked returns text with its highlights wrapped in <mark>
+func marked(text string, highlights []highlight) string {
+	runes := []rune(text)
+	var b strings.Builder
+	last := 0
+	for _, h := range highlights {
+		b.WriteString(string(runes[last:h.Start]) + "<mark>" + string(runes[h.Start:h.End]) + "</mark>")
+		last = h.End
+	}
+	b.WriteString(string(runes[last:]))
+	return b.String()
+}
+
+func TestSnippet(t *testing.T) {
+	filler := strings.Repeat("lorem ipsum dolor ", 20)
+
+	tests := []struct {
+		name   string
+		query  string
+		text   string
+		length int
+		want   string
+	}{
+		{
+			name:   "short text",
+			query:  "golang",
+			text:   "Learning  Golang\nthe easy way",
+			length: 50,
+			want:   "Learning <mark>Golang</mark> the easy way",
+		},
+		{
+			name:   "match in the middle",
+			query:  "goroutines",
+			text:   filler + "Goroutines are cheap. " + filler,
+			length: 60,
+			want:   "...dolor lorem ipsum dolor <mark>Goroutines</mark> are cheap. lorem ipsum...",
+		},
+		{
+			name:   "best cluster",
+			query:  "go channels",
+			text:   "Go is a language. " + filler + "Go channels connect go routines. " + filler,
+			length: 60,
+			want:   "...lorem ipsum dolor <mark>Go</mark> <mark>channels</mark> connect <mark>go</mark> routines. lorem...",
+		},
+		{
+			name:   "stemmed",
+			query:  "running",
+			text:   filler + "He runs daily. " + filler,
+			length: 50,
+			want:   "...lorem ipsum dolor He <mark>runs</mark> daily. lorem ipsum...",
+		},
+		{
+			name:   "no match",
+			query:  "title:golang",
+			text:   "Golang " + filler,
+			length: 50,
+			want:   "Golang lorem ipsum dolor lorem ipsum dolor lorem...",
+		},
+		{
+			name:   "multibyte",
+			query:  "café",
+			text:   "Ünïcode " + filler + "café " + filler,
+			length: 50,
+			want:   "...lorem ipsum dolor <mark>café</mark> lorem ipsum dolor...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := parseQuery(tt.query)
+			require.NoError(t, err)
+			text, highlights := query.snippet(tt.text, tt.length)
+			assert.Equal(t, tt.want, marked(text, highlights))
+			assert.LessOrEqual(t, len([]rune(text)), tt.length+2*len(snippetEllipsis))
+		})
+	}
+}
+
+func TestAddSnippet(t *testing.T) {
+	query, err := parseQuery("golang -python")
+	require.NoError(t, err)
+
+	result := map[string]interface{}{}
+	addSnippet(result, gjson.Parse(`{"summary": "All about golang"}`), query, 0)
+	assert.Equal(t, "All about golang", result["snippet"])
+	assert.Equal(t, []highlight{{Start: 10, End: 16}}, result["highlights"])
+
+	result = map[string]interface{}{}
+	addSnippet(result, gjson.Parse(`{"title": "Golang"}`), query, 0)
+	assert.Empty(t, result)
+}
@@ -0,0 +1,108 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are common English words ignored when matching and scoring
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "from": true, "how": true,
+	"if": true, "in": true, "into": true, "is": true, "it": true, "its": true,
+	"of": true, "on": true, "or": true, "that": true, "the": true, "their": true,
+	"then": true, "there": true, "these": true, "this": true, "to": true,
+	"was": true, "were": true, "what": true, "when": true, "which": true,
+	"with": true, "will": true,
+}
+
+// analyze tokenizes text into lower-cased, stemmed tokens, optionally dropping stop words
+func analyze(text string, dropStopWords bool) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		if dropStopWords && stopWords[word] {
+			continue
+		}
+		tokens = append(tokens, stem(word))
+	}
+	return tokens
+}
+
+// stem reduces an English word to an approximate root by stripping common
+// inflectional suffixes, so "running" and "runs" both become "run". It is
+// deliberately much lighter than a full Porter stemmer.
+func stem(word string) string {
+	if len(word) <= 3 {
+		return word
+	}
+
+	switch {
+	case strings.HasSuffix(word, "sses"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		// "class", "status" and "analysis" are not plurals
+	case strings.HasSuffix(word, "s"):
+		word = word[:len(word)-1]
+	}
+
+	for _, suffix := range []string{"ingly", "edly", "ing", "ed"} {
+		if !strings.HasSuffix(word, suffix) {
+			continue
+		}
+		root := word[:len(word)-len(suffix)]
+		if len(root) < 3 || !hasVowel(root) {
+			break
+		}
+		return undouble(root)
+	}
+
+	return word
+}
+
+// undouble strips a doubled final consonant left behind by suffix removal ("runn" -> "run")
+func undouble(root string) string {
+	n := len(root)
+	if n >= 2 && root[n-1] == root[n-2] && !strings.ContainsRune("aeioulsz", rune(root[n-1])) {
+		return root[:n-1]
+	}
+	return root
+}
+
+func hasVowel(s string) bool {
+	return strings.ContainsAny(s, "aeiouy")
+}
+
+// containsPhrase reports whether phrase occurs as a contiguous run of tokens
+func containsPhrase(tokens, phrase []string) bool {
+	if len(phrase) == 0 || len(phrase) > len(tokens) {
+		return false
+	}
+	for i := 0; i+len(phrase) <= len(tokens); i++ {
+		match := true
+		for j, token := range phrase {
+			if tokens[i+j] != token {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// termFrequencies counts occurrences of each token
+func termFrequencies(tokens []string) map[string]int {
+	freqs := make(map[string]int, len(tokens))
+	for _, token := range tokens {
+		freqs[token]++
+	}
+	return freqs
+}
@@ -0,0 +1,45 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"run":         "run",
+		"runs":        "run",
+		"running":     "run",
+		"programming": "program",
+		"programmed":  "program",
+		"tutorials":   "tutorial",
+		"stories":     "story",
+		"classes":     "class",
+		"class":       "class",
+		"status":      "status",
+		"analysis":    "analysis",
+		"need":        "need",
+		"falling":     "fall",
+	}
+
+	for word, expected := range tests {
+		t.Run(word, func(t *testing.T) {
+			assert.Equal(t, expected, stem(word))
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	assert.Equal(t, []string{"learn", "go", "test"}, analyze("Learning Go: the tests!", true))
+	assert.Equal(t, []string{"learn", "go", "the", "test"}, analyze("Learning Go: the tests!", false))
+	assert.Empty(t, analyze("  ...  ", true))
+}
+
+func TestContainsPhrase(t *testing.T) {
+	tokens := []string{"a", "b", "c"}
+	assert.True(t, containsPhrase(tokens, []string{"b", "c"}))
+	assert.False(t, containsPhrase(tokens, []string{"c", "b"}))
+	assert.False(t, containsPhrase(tokens, []string{"a", "b", "c", "d"}))
+	assert.False(t, containsPhrase(tokens, nil))
+}
@@ -1,18 +1,29 @@
 package search
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	mcp_golang "github.com/metoro-io/mcp-golang"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/enrich"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/feed"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/generic"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/opensearch"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/searchbackend"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
 	"github.com/tidwall/gjson"
 )
 
@@ -24,20 +35,62 @@ type Tool struct {
 	log        *slog.Logger
 	name       string
 	description string
-	httpClient *http.Client
+	httpClient *httpclient.Client
 	cache      *cache.Cache
+	ttl        time.Duration
+	sites      *sites.Registry
+	indexes    *invertedIndexes
+	store      *generic.Store
 }
 
 // SearchRequest represents the request parameters for the search tool.
 type SearchRequest struct {
-	HugoSitePath string `json:"hugo_site_path" jsonschema:"title=Hugo Site Path"`
-	Query        string `json:"query" jsonschema:"title=Search Query"`
+	HugoSitePath string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	Query        string `json:"query,omitempty" jsonschema:"title=Search Query (required unless queries is set)"`
 	ContentType  string `json:"content_type,omitempty" jsonschema:"title=Content Type Filter"`
 	Taxonomy     string `json:"taxonomy,omitempty" jsonschema:"title=Taxonomy Filter"`
 	Term         string `json:"term,omitempty" jsonschema:"title=Taxonomy Term Filter"`
 	Limit        int    `json:"limit,omitempty" jsonschema:"title=Result Limit,minimum=1,maximum=100"`
+	Offset       int    `json:"offset,omitempty" jsonschema:"title=Result Offset,minimum=0,maximum=10000"`
+	Page         int    `json:"page,omitempty" jsonschema:"title=Page Number (1-based, uses limit as page size),minimum=1"`
+	Sort         string `json:"sort,omitempty" jsonschema:"title=Sort Order,enum=relevance,enum=date_desc,enum=date_asc,enum=title"`
+	Enrich       bool   `json:"enrich,omitempty" jsonschema:"title=Add word_count, reading_time_minutes and language to results, computing them when the page lacks them"`
+
+	// Queries are searched together in place of Query, each with the
+	// request's other parameters
+	Queries []string `json:"queries,omitempty" jsonschema:"title=Queries to run in one call instead of query,maxItems=10"`
+
+	// Sites are searched together in place of the request's site, each a
+	// URL or the alias of a registered site
+	Sites []string `json:"sites,omitempty" jsonschema:"title=Sites to search together: URLs or aliases of registered sites,maxItems=10"`
+
+	// Mode is how the query is read: as keywords in the query syntax, as
+	// one exact phrase, or as a regular expression
+	Mode string `json:"mode,omitempty" jsonschema:"title=Query Mode (default: keyword),enum=keyword,enum=phrase,enum=regex"`
+
+	// Match is whether multi-word queries need all their words to match
+	// or any one of them
+	Match string `json:"match,omitempty" jsonschema:"title=Match All Words or Any Word (default: all),enum=all,enum=any"`
+	Fuzzy bool   `json:"fuzzy,omitempty" jsonschema:"title=Tolerate Typos (words match words 1 or 2 edits away depending on length)"`
+
+	// SnippetLength is the length of the snippet of each result's content
+	// around its matches
+	SnippetLength int `json:"snippet_length,omitempty" jsonschema:"title=Snippet Length in Characters (default: 200),minimum=50,maximum=1000"`
+
+	tools.ExplainOptions
+	generic.Options
+	hugoindex.PublishOptions
+	hugoindex.DateRange
+	hugoindex.PathScope
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
 }
 
+// maxOffset bounds how deep into the result set clients may page
+const maxOffset = 10000
+
 // EndpointConfig represents an endpoint with its validation function
 type EndpointConfig struct {
 	path      string
@@ -45,15 +98,21 @@ type EndpointConfig struct {
 	validator func([]byte) bool
 }
 
+// DefaultCacheTTL is how long search endpoints and indexes are served from
+// the cache; search results go stale quickly
+const DefaultCacheTTL = 2 * time.Minute
+
 // New creates a new Tool.
 func New(opts ...ToolOption) (*Tool, error) {
 	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_search"),
 		name:        "hugo_reader_search",
 		description: "Search content across Hugo sites by keywords. Tries Hugo-native search endpoints first, then falls back to content scanning. Supports filters by content_type, taxonomy, and term. Use for finding content when you don't know exact paths.",
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		cache: cache.New(cache.WithTTL(2 * time.Minute)), // Shorter TTL for search results
+		httpClient: httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache: cache.New(cache.WithTTL(DefaultCacheTTL)),
+		ttl:   DefaultCacheTTL,
+		sites: sites.New(),
+		indexes: newInvertedIndexes(),
 	}
 	for _, opt := range opts {
 		if err := opt(tool); err != nil {
@@ -68,9 +127,6 @@ func New(opts ...ToolOption) (*Tool, error) {
 func WithLogger(logger *slog.Logger) ToolOption {
 	return func(t *Tool) error {
 		t.log = logger.With("tool", t.name)
-		if t.cache != nil {
-			t.cache = cache.New(cache.WithLogger(logger), cache.WithTTL(2*time.Minute))
-		}
 		return nil
 	}
 }
@@ -83,14 +139,69 @@ func WithCache(c *cache.Cache) ToolOption {
 	}
 }
 
+// WithTTL sets how long the Tool serves cached responses. Zero uses the
+// cache's default TTL.
+func WithTTL(ttl time.Duration) ToolOption {
+	return func(t *Tool) error {
+		t.ttl = ttl
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// WithIndexStore sets the store indexes built from sitemaps are kept in
+// across sessions. Without one, they are only kept in the cache.
+func WithIndexStore(s *generic.Store) ToolOption {
+	return func(t *Tool) error {
+		t.store = s
+		return nil
+	}
+}
+
 // Validate implements tools.Request
 func (r *SearchRequest) Validate() error {
-	if r.HugoSitePath == "" {
-		return fmt.Errorf("hugo_site_path is required")
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
 	}
-	if r.Query == "" {
+	if r.Query == "" && len(r.Queries) == 0 {
 		return fmt.Errorf("query is required")
 	}
+	switch r.Match {
+	case "":
+		r.Match = MatchAll
+	case MatchAll, MatchAny:
+	default:
+		return fmt.Errorf("match must be one of: all, any")
+	}
+	switch r.Mode {
+	case "":
+		r.Mode = ModeKeyword
+	case ModeKeyword, ModePhrase, ModeRegex:
+	default:
+		return fmt.Errorf("mode must be one of: keyword, phrase, regex")
+	}
+	if len(r.Queries) > 0 {
+		if err := r.validateBatch(); err != nil {
+			return err
+		}
+	} else if _, err := r.matcher(); err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
 	
 	// Set default limit if not specified or validate
 	if r.Limit == 0 {
@@ -98,31 +209,141 @@ func (r *SearchRequest) Validate() error {
 	} else if r.Limit < 1 || r.Limit > 100 {
 		return fmt.Errorf("limit must be between 1 and 100")
 	}
+
+	// Resolve page into an offset so the rest of the tool only deals with offsets
+	if r.Offset < 0 || r.Offset > maxOffset {
+		return fmt.Errorf("offset must be between 0 and %d", maxOffset)
+	}
+	if r.Page < 0 {
+		return fmt.Errorf("page must be 1 or greater")
+	}
+	if r.Page > 0 {
+		if r.Offset > 0 {
+			return fmt.Errorf("use either offset or page, not both")
+		}
+		r.Offset = (r.Page - 1) * r.Limit
+		if r.Offset > maxOffset {
+			return fmt.Errorf("page is too large (offset must not exceed %d)", maxOffset)
+		}
+	}
 	
-	return nil
-}
+	if r.SnippetLength == 0 {
+		r.SnippetLength = DefaultSnippetLength
+	} else if r.SnippetLength < MinSnippetLength || r.SnippetLength > MaxSnippetLength {
+		return fmt.Errorf("snippet_length must be between %d and %d", MinSnippetLength, MaxSnippetLength)
+	}
 
-// Execute performs search across Hugo site content.
-func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
-	// Check if logger is initialized
-	if t.log == nil {
-		t.log = slog.Default().With("tool", t.name)
+	switch r.Sort {
+	case "":
+		r.Sort = SortRelevance
+	case SortRelevance, SortDateDesc, SortDateAsc, SortTitle:
+	default:
+		return fmt.Errorf("sort must be one of: relevance, date_desc, date_asc, title")
 	}
 
+	if err := r.DateRange.Validate(); err != nil {
+		return err
+	}
+	if err := r.PathScope.Validate(); err != nil {
+		return err
+	}
+	
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// Execute performs search across Hugo site content.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
 	searchRequest, ok := req.(*SearchRequest)
 	if !ok {
-		return nil, fmt.Errorf("invalid request type: %T", req)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	if len(searchRequest.Sites) > 0 {
+		return t.executeFederated(ctx, searchRequest)
+	}
+
+	ctx, siteURL, cancel, err := t.prepare(ctx, searchRequest)
+	if err != nil {
+		return nil, err
 	}
+	defer cancel()
 
-	if err := searchRequest.Validate(); err != nil {
+	if searchRequest.Explain {
+		return t.explain(ctx, siteURL, searchRequest)
+	}
+
+	if len(searchRequest.Queries) > 0 {
+		return t.executeBatch(ctx, siteURL, searchRequest)
+	}
+
+	// Record every endpoint tried, to report why methods failed
+	tried := &attempts{}
+	searchResults, searchMetadata, err := t.search(ctx, siteURL, searchRequest, tried)
+	if err != nil {
 		return nil, err
 	}
+	searchMetadata["site_source"] = sites.Source(ctx)
+	searchMetadata["redirects"] = httpclient.Redirects(ctx)
+
+	response := map[string]interface{}{
+		"success":  true,
+		"query":    searchRequest.Query,
+		"results":  searchResults,
+		"metadata": searchMetadata,
+		"attempts": tried.all(),
+		"errors":   []toolerrors.ErrorDetail{},
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal search results", "error", err)
+		return nil, fmt.Errorf("failed to marshal search results: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Search completed", "query", searchRequest.Query, "results", len(searchResults), "site", searchRequest.HugoSitePath, "fallback", searchMetadata["fallback_used"])
+	return tools.TextResponse(responseJSON), nil
+}
+
+// prepare resolves the site of req and validates it, returning the context
+// its searches run with, bounded by its retry options and, for regexes,
+// RegexTimeout, and the site's URL. Call cancel once the searches are done.
+func (t *Tool) prepare(ctx context.Context, req *SearchRequest) (context.Context, *url.URL, context.CancelFunc, error) {
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := req.SiteOptions.Apply(ctx, t.sites, &req.HugoSitePath, &req.AuthOptions)
+	if err != nil {
+		return ctx, nil, nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return ctx, nil, nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := req.RetryOptions.Apply(ctx)
+	ctx = req.CacheOptions.Apply(ctx, t.ttl)
+
+	// Bound how long a regular expression can keep the server scanning
+	if req.Mode == ModeRegex && !req.Explain {
+		var cancelRegex context.CancelFunc
+		ctx, cancelRegex = context.WithTimeoutCause(ctx, RegexTimeout, errRegexTimeout)
+		cancelRetry := cancel
+		cancel = func() {
+			cancelRegex()
+			cancelRetry()
+		}
+	}
 
 	// Parse and validate the Hugo site URL
-	siteURL, err := url.Parse(searchRequest.HugoSitePath)
+	siteURL, err := url.Parse(req.HugoSitePath)
 	if err != nil {
-		t.log.Error("Invalid Hugo site URL", "url", searchRequest.HugoSitePath, "error", err)
-		return nil, fmt.Errorf("invalid Hugo site URL: %w", err)
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", req.HugoSitePath, "error", err)
+		cancel()
+		return ctx, nil, nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
 	}
 
 	// Ensure URL has scheme
@@ -130,220 +351,462 @@ func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
 		siteURL.Scheme = "https"
 	}
 
-	// Try Hugo-specific search endpoints first, then fallback to content scanning
-	searchResults, searchMetadata, err := t.performHugoSearch(siteURL, searchRequest)
-	if err != nil {
-		t.log.Debug("Hugo-specific search failed, falling back to content scanning", "error", err)
-		searchResults, searchMetadata, err = t.performContentScanSearch(siteURL, searchRequest)
+	// Send credentials, if any, only to the site itself
+	ctx = req.AuthOptions.Apply(ctx, siteURL.Host)
+	return ctx, siteURL, cancel, nil
+}
+
+// search runs req's query through the search methods in turn, recording
+// the endpoints tried, and returns the page of results it asks for
+func (t *Tool) search(ctx context.Context, siteURL *url.URL, req *SearchRequest, tried *attempts) ([]map[string]interface{}, map[string]interface{}, error) {
+	// Try Hugo-specific search endpoints first, then fallback to content
+	// scanning. Generic mode only scans the pages the sitemap lists.
+	var searchResults []map[string]interface{}
+	var searchMetadata map[string]interface{}
+	var err error
+	if req.Generic {
+		searchResults, searchMetadata, err = t.performSitemapSearch(ctx, siteURL, req, tried)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				t.log.WarnContext(ctx, "Search cancelled", "query", req.Query, "error", ctxErr)
+				return nil, nil, cancelled(ctx)
+			}
+			t.log.ErrorContext(ctx, "Sitemap scan failed", "error", err)
+			return nil, nil, &toolerrors.Error{
+				Code: toolerrors.Code(err),
+				Err:  fmt.Errorf("search failed: %w", err),
+				Data: map[string]interface{}{"attempts": tried.all()},
+			}
+		}
+		searchMetadata["fallback_used"] = false
+	} else if searchResults, searchMetadata, err = t.performHugoSearch(ctx, siteURL, req, tried); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			t.log.WarnContext(ctx, "Search cancelled", "query", req.Query, "error", ctxErr)
+			return nil, nil, cancelled(ctx)
+		}
+		t.log.DebugContext(ctx, "Hugo-specific search failed, falling back to content scanning", "error", err)
+		searchResults, searchMetadata, err = t.performContentScanSearch(ctx, siteURL, req, tried)
+		if err != nil && ctx.Err() == nil {
+			t.log.DebugContext(ctx, "Content scan failed, falling back to feed scanning", "error", err)
+			searchResults, searchMetadata, err = t.performFeedSearch(ctx, siteURL, req, tried)
+		}
+		if err != nil && ctx.Err() == nil {
+			t.log.DebugContext(ctx, "Feed scan failed, falling back to scanning the pages of the sitemap", "error", err)
+			searchResults, searchMetadata, err = t.performSitemapSearch(ctx, siteURL, req, tried)
+		}
+		if cause := context.Cause(ctx); errors.Is(cause, errRegexTimeout) {
+			err = cause
+		}
 		if err != nil {
-			t.log.Error("All search methods failed", "error", err)
-			return nil, fmt.Errorf("search failed: %w", err)
+			t.log.ErrorContext(ctx, "All search methods failed", "error", err, "attempts", len(tried.list))
+			return nil, nil, &toolerrors.Error{
+				Code: toolerrors.Code(err),
+				Err:  fmt.Errorf("search failed: %w", err),
+				Data: map[string]interface{}{"attempts": tried.all()},
+			}
 		}
 		searchMetadata["fallback_used"] = true
 	} else {
 		searchMetadata["fallback_used"] = false
 	}
 
-	// Apply limit
-	if len(searchResults) > searchRequest.Limit {
-		searchResults = searchResults[:searchRequest.Limit]
-		searchMetadata["limited"] = true
-	} else {
-		searchMetadata["limited"] = false
-	}
+	// Drop results that are aliases of one page, such as /posts/foo/ and
+	// /posts/foo/index.html
+	searchResults, duplicates := dedupeResults(searchResults, siteURL)
+	searchMetadata["duplicates_removed"] = duplicates
+
+	// Order the full result set before windowing so pages are consistent
+	sortResults(searchResults, req.Sort)
+	searchMetadata["sort"] = req.Sort
 
-	// Format response
-	responseData := fmt.Sprintf(`{
-  "success": true,
-  "query": "%s",
-  "results": %s,
-  "metadata": %s,
-  "errors": []
-}`, searchRequest.Query, formatSearchResults(searchResults), formatMetadata(searchMetadata))
+	// Apply pagination window
+	totalResults := len(searchResults)
+	searchResults = paginate(searchResults, req.Offset, req.Limit)
+	addPaginationMetadata(searchMetadata, req, totalResults, len(searchResults))
 
-	t.log.Info("Search completed", "query", searchRequest.Query, "results", len(searchResults), "site", searchRequest.HugoSitePath, "fallback", searchMetadata["fallback_used"])
-	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(responseData)), nil
+	return searchResults, searchMetadata, nil
 }
 
 // performHugoSearch attempts to use Hugo's built-in search indices
-func (t *Tool) performHugoSearch(siteURL *url.URL, req *SearchRequest) ([]map[string]interface{}, map[string]interface{}, error) {
-	// Try common Hugo search endpoint patterns
-	searchEndpoints := []EndpointConfig{
-		{path: "/search.json", params: map[string]string{"q": req.Query}, validator: validateSearchResults},
-		{path: "/api/search.json", params: map[string]string{"query": req.Query}, validator: validateSearchResults},
-		{path: "/search/index.json", params: map[string]string{"q": req.Query}, validator: validateSearchResults},
-		{path: "/index.json", params: map[string]string{"search": req.Query}, validator: validateHugoIndexForSearch},
+func (t *Tool) performHugoSearch(ctx context.Context, siteURL *url.URL, req *SearchRequest, tried *attempts) ([]map[string]interface{}, map[string]interface{}, error) {
+	// Native search can't match regular expressions, which pages are
+	// scanned for instead
+	if req.Mode == ModeRegex {
+		return nil, nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "native search endpoints do not support regex queries")
 	}
+	query := nativeQuery(req)
 
-	for _, endpoint := range searchEndpoints {
-		searchURL := siteURL.ResolveReference(&url.URL{Path: endpoint.path})
-		
-		// Add query parameters
-		params := url.Values{}
-		for key, value := range endpoint.params {
-			params.Add(key, value)
-		}
-		
-		// Add filter parameters if specified
-		if req.ContentType != "" {
-			params.Add("type", req.ContentType)
-		}
-		if req.Taxonomy != "" && req.Term != "" {
-			params.Add(req.Taxonomy, req.Term)
-		}
-		if req.Limit > 0 {
-			params.Add("limit", strconv.Itoa(req.Limit))
-		}
-		
-		searchURL.RawQuery = params.Encode()
-		
-		// Build cache key
-		cacheParams := make(map[string]string)
-		for key, values := range params {
-			if len(values) > 0 {
-				cacheParams[key] = values[0]
-			}
+	// A registered site's search backend answers before anything the site serves
+	if backend, ok := sites.SearchBackend(ctx); ok {
+		if results, metadata, err := t.performBackendSearch(ctx, req, query, backend, tried); err == nil {
+			return results, metadata, nil
+		} else if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		} else {
+			t.log.DebugContext(ctx, "Search backend unavailable", "backend", backend.Name(), "index", backend.Index(), "error", err)
 		}
-		cacheKey := t.cache.BuildKey(siteURL.String(), endpoint.path, cacheParams)
-		
-		t.log.Debug("Trying Hugo search endpoint", "url", searchURL.String(), "cache_key", cacheKey)
-
-		// Check cache first
-		if cachedData, hit := t.cache.Get(cacheKey); hit {
-			t.log.Debug("Cache hit for search endpoint", "url", searchURL.String())
-			if endpoint.validator(cachedData) {
-				results := extractSearchResults(cachedData, req)
-				metadata := map[string]interface{}{
-					"search_method":    "hugo_native",
-					"source_endpoint":  searchURL.String(),
-					"result_count":     len(results),
-					"cached":          true,
-				}
-				return results, metadata, nil
-			} else {
-				t.log.Debug("Cached search data failed validation, invalidating", "url", searchURL.String())
-				t.cache.Delete(cacheKey)
-			}
+	}
+
+	// A search URL the site declares comes before the conventional ones
+	if results, metadata, err := t.performOpenSearch(ctx, siteURL, req, query, tried); err == nil {
+		return results, metadata, nil
+	} else if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	} else {
+		t.log.DebugContext(ctx, "OpenSearch unavailable", "error", err)
+	}
+
+	// Try common Hugo search endpoint patterns
+	for _, endpoint := range searchEndpoints(ctx, query) {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
 		}
 
-		// Fetch from network
-		resp, err := t.httpClient.Get(searchURL.String())
+		searchURL, cacheKey := t.endpointRequest(siteURL, endpoint, req)
+		t.log.DebugContext(ctx, "Trying Hugo search endpoint", "url", searchURL.String(), "cache_key", cacheKey)
+
+		// Serve from cache, revalidating expired entries
+		result, err := t.cache.FetchEndpoint(ctx, t.httpClient, siteURL.String(), endpoint.path, cacheKey, searchURL.String(), endpoint.validator)
+		tried.record(MethodHugoNative, endpoint.path, err)
 		if err != nil {
-			t.log.Debug("Failed to fetch search endpoint", "url", searchURL.String(), "error", err)
+			t.log.DebugContext(ctx, "Search endpoint unavailable", "url", searchURL.String(), "error", err)
 			continue
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode == http.StatusOK {
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				t.log.Debug("Failed to read search response body", "url", searchURL.String(), "error", err)
-				continue
-			}
+		results := extractSearchResults(result.Data, req)
+		metadata := map[string]interface{}{
+			"search_method":    "hugo_native",
+			"source_endpoint":  searchURL.String(),
+			"result_count":     len(results),
+			"cached":          result.Cached || result.Revalidated,
+		}
 
-			// Validate response contains search results
-			if endpoint.validator(body) {
-				// Cache the validated response
-				etag := resp.Header.Get("ETag")
-				lastModified := resp.Header.Get("Last-Modified")
-				t.cache.Set(cacheKey, body, etag, lastModified)
-				
-				results := extractSearchResults(body, req)
-				metadata := map[string]interface{}{
-					"search_method":    "hugo_native",
-					"source_endpoint":  searchURL.String(),
-					"result_count":     len(results),
-					"cached":          false,
-				}
-				
-				t.log.Info("Hugo search successful", "url", searchURL.String(), "results", len(results))
-				return results, metadata, nil
-			} else {
-				t.log.Debug("Response failed search validation", "url", searchURL.String())
-			}
-		} else {
-			t.log.Debug("HTTP error from search endpoint", "url", searchURL.String(), "status", resp.StatusCode)
+		t.log.InfoContext(ctx, "Hugo search successful", "url", searchURL.String(), "results", len(results))
+		return results, metadata, nil
+	}
+
+	return nil, nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "no Hugo search endpoints available")
+}
+
+// cancelled returns the error of a search stopped by ctx: the regex time
+// limit when it was reached, else the cancellation
+func cancelled(ctx context.Context) error {
+	if cause := context.Cause(ctx); errors.Is(cause, errRegexTimeout) {
+		return cause
+	}
+	return fmt.Errorf("search cancelled: %w", ctx.Err())
+}
+
+// Supported values for SearchRequest.Mode
+const (
+	ModeKeyword = "keyword"
+	ModePhrase  = "phrase"
+	ModeRegex   = "regex"
+)
+
+// queryText returns the query in the keyword syntax: phrase queries are
+// quoted as a whole
+func (r *SearchRequest) queryText() string {
+	if r.Mode == ModePhrase {
+		return `"` + strings.ReplaceAll(r.Query, `"`, "") + `"`
+	}
+	return r.Query
+}
+
+// parseQuery parses the request's query with its match and fuzzy options
+func (r *SearchRequest) parseQuery() (*searchQuery, error) {
+	return parseQueryWith(r.queryText(), queryOptions{any: r.Match == MatchAny, fuzzy: r.Fuzzy})
+}
+
+// matcher returns the matcher of the request's query in its mode
+func (r *SearchRequest) matcher() (matcher, error) {
+	if r.Mode == ModeRegex {
+		return compileRegex(r.Query)
+	}
+	return r.parseQuery()
+}
+
+// nativeQuery returns the query sent to native search endpoints, which only
+// understand plain keywords; extended syntax is reduced to its terms and
+// the results filtered in extractSearchResults
+func nativeQuery(req *SearchRequest) string {
+	if query, err := req.parseQuery(); err == nil {
+		return query.nativeQuery(req.queryText())
+	}
+	return req.Query
+}
+
+// searchEndpoints returns the conventional Hugo search endpoints in the
+// order they are tried, after a registered site's own search endpoint
+func searchEndpoints(ctx context.Context, nativeQuery string) []EndpointConfig {
+	endpoints := []EndpointConfig{
+		{path: "/search.json", params: map[string]string{"q": nativeQuery}, validator: validateSearchResults},
+		{path: "/api/search.json", params: map[string]string{"query": nativeQuery}, validator: validateSearchResults},
+		{path: "/search/index.json", params: map[string]string{"q": nativeQuery}, validator: validateSearchResults},
+		{path: "/index.json", params: map[string]string{"search": nativeQuery}, validator: hugoindex.Valid},
+	}
+	if path, ok := sites.Endpoint(ctx, sites.EndpointSearch); ok {
+		endpoints = append([]EndpointConfig{
+			{path: path, params: map[string]string{"q": nativeQuery}, validator: validateSearchResults},
+		}, endpoints...)
+	}
+	return endpoints
+}
+
+// endpointRequest returns the URL a search endpoint is queried at, with
+// the request's filters as parameters, and the key its response is cached
+// under
+func (t *Tool) endpointRequest(siteURL *url.URL, endpoint EndpointConfig, req *SearchRequest) (*url.URL, string) {
+	searchURL := siteURL.ResolveReference(&url.URL{Path: endpoint.path})
+
+	params := url.Values{}
+	for key, value := range endpoint.params {
+		params.Add(key, value)
+	}
+	if req.ContentType != "" {
+		params.Add("type", req.ContentType)
+	}
+	if req.Taxonomy != "" && req.Term != "" {
+		params.Add(req.Taxonomy, req.Term)
+	}
+	// Endpoints ignoring the scope have their results filtered locally
+	if req.PathScope.IsSet() {
+		params.Add("path", req.PathScope.Prefix())
+	}
+	if req.Limit > 0 {
+		// Ask for enough results to cover the requested page
+		params.Add("limit", strconv.Itoa(req.Offset+req.Limit))
+	}
+	searchURL.RawQuery = params.Encode()
+
+	cacheParams := make(map[string]string)
+	for key, values := range params {
+		if len(values) > 0 {
+			cacheParams[key] = values[0]
 		}
 	}
+	return searchURL, t.cache.BuildKey(siteURL.String(), endpoint.path, cacheParams)
+}
 
-	return nil, nil, fmt.Errorf("no Hugo search endpoints available")
+// contentEndpoints returns the site indexes scanned when no search
+// endpoint answers, in the order they are tried
+func contentEndpoints(ctx context.Context) []string {
+	return []string{
+		hugoindex.IndexPath(ctx),
+		"/content/index.json",
+		"/posts/index.json",
+		"/api/content.json",
+		"/all.json",
+		"/site.json",
+	}
 }
 
-// performContentScanSearch falls back to scanning available content
-func (t *Tool) performContentScanSearch(siteURL *url.URL, req *SearchRequest) ([]map[string]interface{}, map[string]interface{}, error) {
-	// Try to get all content and search through it
-	contentEndpoints := []EndpointConfig{
-		{path: "/index.json", validator: validateHugoIndexForSearch},
-		{path: "/content/index.json", validator: validateSearchResults},
-		{path: "/posts/index.json", validator: validateSearchResults},
-		{path: "/api/content.json", validator: validateSearchResults},
-		{path: "/all.json", validator: validateSearchResults},
-		{path: "/site.json", validator: validateSearchResults},
-	}
-
-	for _, endpoint := range contentEndpoints {
-		contentURL := siteURL.ResolveReference(&url.URL{Path: endpoint.path})
-		cacheKey := t.cache.BuildKey(siteURL.String(), endpoint.path, nil)
-		
-		t.log.Debug("Trying content scan endpoint", "url", contentURL.String())
+// performBackendSearch queries the search backend registered for the site,
+// such as its Algolia index, with the native query. Hits are never cached,
+// as the backend is not the site's own.
+func (t *Tool) performBackendSearch(ctx context.Context, req *SearchRequest, query string, backend searchbackend.SearchBackend, tried *attempts) (_ []map[string]interface{}, _ map[string]interface{}, err error) {
+	queryURL := backend.QueryURL()
+	defer func() { tried.record(backend.Name(), queryURL, err) }()
 
-		var contentData []byte
-		
-		// Check cache first
-		if cachedData, hit := t.cache.Get(cacheKey); hit {
-			t.log.Debug("Cache hit for content scan", "url", contentURL.String())
-			if endpoint.validator(cachedData) {
-				contentData = cachedData
-			} else {
-				t.cache.Delete(cacheKey)
-				continue
-			}
-		} else {
-			// Fetch from network
-			resp, err := t.httpClient.Get(contentURL.String())
-			if err != nil {
-				t.log.Debug("Failed to fetch content endpoint", "url", contentURL.String(), "error", err)
-				continue
-			}
-			defer resp.Body.Close()
+	t.log.DebugContext(ctx, "Trying search backend", "backend", backend.Name(), "url", queryURL, "index", backend.Index())
+	result, err := backend.Search(ctx, t.httpClient, query, req.Offset+req.Limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := json.Marshal(result.Hits)
+	if err != nil {
+		return nil, nil, err
+	}
 
-			if resp.StatusCode != http.StatusOK {
-				t.log.Debug("HTTP error from content endpoint", "url", contentURL.String(), "status", resp.StatusCode)
-				continue
-			}
+	results := extractSearchResults(data, req)
+	metadata := map[string]interface{}{
+		"search_method":   backend.Name(),
+		"source_endpoint": queryURL,
+		"index":           backend.Index(),
+		"total_hits":      result.Total,
+		"result_count":    len(results),
+		"cached":          false,
+	}
 
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				t.log.Debug("Failed to read content response body", "url", contentURL.String(), "error", err)
-				continue
-			}
+	t.log.InfoContext(ctx, "Search backend successful", "backend", backend.Name(), "index", backend.Index(), "results", len(results))
+	return results, metadata, nil
+}
 
-			if !endpoint.validator(body) {
-				t.log.Debug("Content data failed validation", "url", contentURL.String())
-				continue
-			}
+// performOpenSearch searches through the URL template declared in the
+// site's OpenSearch description. Feed results are read as JSON results.
+func (t *Tool) performOpenSearch(ctx context.Context, siteURL *url.URL, req *SearchRequest, query string, tried *attempts) (_ []map[string]interface{}, _ map[string]interface{}, err error) {
+	endpoint := opensearch.Path
+	defer func() { tried.record(MethodOpenSearch, endpoint, err) }()
+
+	description, err := opensearch.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	template, err := description.SearchURL()
+	if err != nil {
+		return nil, nil, err
+	}
+	searchURL, err := template.Expand(siteURL, query, req.Offset+req.Limit)
+	if err != nil {
+		return nil, nil, err
+	}
 
-			// Cache the validated response
-			etag := resp.Header.Get("ETag")
-			lastModified := resp.Header.Get("Last-Modified")
-			t.cache.Set(cacheKey, body, etag, lastModified)
-			contentData = body
+	validator := validateSearchResults
+	if template.IsFeed() {
+		validator = func(data []byte) bool {
+			_, err := feed.Parse(data)
+			return err == nil
+		}
+	}
+
+	cacheParams := make(map[string]string)
+	for key, values := range searchURL.Query() {
+		cacheParams[key] = values[0]
+	}
+	cacheKey := t.cache.BuildKey(siteURL.String(), opensearch.Path+searchURL.Path, cacheParams)
+	endpoint = (&url.URL{Scheme: searchURL.Scheme, Host: searchURL.Host, Path: searchURL.Path}).String()
+
+	t.log.DebugContext(ctx, "Trying OpenSearch endpoint", "url", searchURL.String(), "cache_key", cacheKey)
+	result, err := t.cache.Fetch(ctx, t.httpClient, cacheKey, searchURL.String(), validator)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := result.Data
+	if template.IsFeed() {
+		siteFeed, err := feed.Parse(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if data, err = json.Marshal(siteFeed.Items); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	results := extractSearchResults(data, req)
+	metadata := map[string]interface{}{
+		"search_method":   "hugo_native",
+		"source_endpoint": searchURL.String(),
+		"opensearch":      description.URL,
+		"result_count":    len(results),
+		"cached":          result.Cached || result.Revalidated,
+	}
+
+	t.log.InfoContext(ctx, "OpenSearch search successful", "url", searchURL.String(), "results", len(results))
+	return results, metadata, nil
+}
+
+// performContentScanSearch falls back to scanning available content
+func (t *Tool) performContentScanSearch(ctx context.Context, siteURL *url.URL, req *SearchRequest, tried *attempts) ([]map[string]interface{}, map[string]interface{}, error) {
+	for _, endpoint := range contentEndpoints(ctx) {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		t.log.DebugContext(ctx, "Trying content scan endpoint", "site", siteURL.String(), "path", endpoint)
+
+		index, err := hugoindex.LoadPath(ctx, t.cache, t.httpClient, siteURL, endpoint)
+		if err != nil {
+			tried.record(MethodContentScan, endpoint, err)
+			t.log.DebugContext(ctx, "Content endpoint unavailable", "site", siteURL.String(), "path", endpoint, "error", err)
+			continue
 		}
 
 		// Perform client-side search
-		results := performClientSideSearch(contentData, req)
+		results, err := searchIndex(ctx, index, req)
+		tried.record(MethodContentScan, endpoint, err)
+		if err != nil {
+			t.log.DebugContext(ctx, "Failed to scan index", "url", index.URL(), "error", err)
+			continue
+		}
+
 		metadata := map[string]interface{}{
 			"search_method":    "content_scan",
-			"source_endpoint":  contentURL.String(),
+			"source_endpoint":  index.URL(),
 			"result_count":     len(results),
-			"cached":          contentData != nil,
+			"cached":           index.Cached(),
+			"streamed":         index.Streamed(),
 		}
 		
-		t.log.Info("Content scan search completed", "url", contentURL.String(), "results", len(results))
+		t.log.InfoContext(ctx, "Content scan search completed", "url", index.URL(), "results", len(results), "streamed", index.Streamed())
 		return results, metadata, nil
 	}
 
-	return nil, nil, fmt.Errorf("no content available for scanning")
+	return nil, nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "no content available for scanning")
+}
+
+// performFeedSearch searches the items of the site's RSS or JSON feed, for
+// minimal sites that publish no index. Feeds only list recent pages, with
+// their titles and summaries rather than full content.
+func (t *Tool) performFeedSearch(ctx context.Context, siteURL *url.URL, req *SearchRequest, tried *attempts) ([]map[string]interface{}, map[string]interface{}, error) {
+	siteFeed, err := feed.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		tried.record(MethodFeedScan, strings.Join(feed.Paths, ", "), err)
+		return nil, nil, err
+	}
+
+	results, err := searchIndex(ctx, hugoindex.FromFeed(siteFeed), req)
+	tried.record(MethodFeedScan, siteFeed.URL, err)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to scan feed: %w", err)
+	}
+
+	metadata := map[string]interface{}{
+		"search_method":   "feed_scan",
+		"source_endpoint": siteFeed.URL,
+		"feed_format":     siteFeed.Format,
+		"feed_items":      len(siteFeed.Items),
+		"result_count":    len(results),
+		"cached":          siteFeed.Cached,
+	}
+
+	t.log.InfoContext(ctx, "Feed scan search completed", "url", siteFeed.URL, "format", siteFeed.Format, "results", len(results))
+	return results, metadata, nil
+}
+
+// performSitemapSearch searches the pages the site's sitemap lists, read
+// from their rendered HTML, for sites that publish none of Hugo's JSON
+// outputs or feeds. Only the first generic.MaxPages pages are read, and
+// the inverted index built from them is kept in memory for later searches.
+func (t *Tool) performSitemapSearch(ctx context.Context, siteURL *url.URL, req *SearchRequest, tried *attempts) ([]map[string]interface{}, map[string]interface{}, error) {
+	index, stats, err := generic.Load(ctx, t.cache, t.httpClient, t.store, siteURL)
+	if err != nil {
+		tried.record(MethodSitemapScan, generic.SitemapPath, err)
+		return nil, nil, err
+	}
+	if stats.PersistErr != nil {
+		t.log.WarnContext(ctx, "Failed to store sitemap index", "site", siteURL.String(), "error", stats.PersistErr)
+	}
+
+	inv, reused, err := t.indexes.get(ctx, siteURL.String(), index)
+	if err != nil {
+		tried.record(MethodSitemapScan, generic.SitemapPath, err)
+		return nil, nil, fmt.Errorf("failed to index sitemap pages: %w", err)
+	}
+
+	results, err := inv.search(ctx, req)
+	tried.record(MethodSitemapScan, generic.SitemapPath, err)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to scan sitemap pages: %w", err)
+	}
+
+	metadata := map[string]interface{}{
+		"search_method":   MethodSitemapScan,
+		"source_endpoint": siteURL.ResolveReference(&url.URL{Path: generic.SitemapPath}).String(),
+		"source_format":   index.Format(),
+		"max_pages":       generic.MaxPages,
+		"indexed_pages":   len(inv.pages),
+		"indexed_terms":   inv.terms(),
+		"index_reused":    reused,
+		"pages_read":      stats.Read,
+		"pages_restored":  stats.Restored,
+		"index_persisted": stats.Persisted,
+		"result_count":    len(results),
+		"cached":          index.Cached(),
+	}
+
+	t.log.InfoContext(ctx, "Sitemap scan search completed", "site", siteURL.String(), "results", len(results), "cached", index.Cached(), "index_reused", reused)
+	return results, metadata, nil
 }
 
 // Validation functions
@@ -369,21 +832,6 @@ func validateSearchResults(data []byte) bool {
 	return false
 }
 
-func validateHugoIndexForSearch(data []byte) bool {
-	if !gjson.ValidBytes(data) {
-		return false
-	}
-
-	parsed := gjson.ParseBytes(data)
-	
-	// Check for pages that can be searched
-	if pages := parsed.Get("pages"); pages.Exists() && pages.IsArray() {
-		return len(pages.Array()) > 0
-	}
-	
-	return parsed.IsArray() && len(parsed.Array()) > 0
-}
-
 // Search result extraction
 func extractSearchResults(data []byte, req *SearchRequest) []map[string]interface{} {
 	var results []map[string]interface{}
@@ -400,8 +848,28 @@ func extractSearchResults(data []byte, req *SearchRequest) []map[string]interfac
 	} else {
 		return results
 	}
+
+	// Apply extended query syntax that native endpoints could not
+	var query *searchQuery
+	var highlights matcher
+	if parsed, err := req.parseQuery(); err == nil {
+		highlights = parsed
+		if !parsed.simple {
+			query = parsed
+		}
+	}
 	
+	now := time.Now()
 	resultsArray.ForEach(func(key, item gjson.Result) bool {
+		if !req.PublishOptions.Allows(item, now) || !req.DateRange.Contains(item) || !req.PathScope.Contains(item) {
+			return true
+		}
+		if query != nil {
+			if _, matched := query.score(item); !matched {
+				return true
+			}
+		}
+
 		result := make(map[string]interface{})
 		
 		// Extract common fields
@@ -433,6 +901,10 @@ func extractSearchResults(data []byte, req *SearchRequest) []map[string]interfac
 		if score := item.Get("score"); score.Exists() {
 			result["score"] = score.Float()
 		}
+		addSnippet(result, item, highlights, req.SnippetLength)
+		if req.Enrich {
+			addEnrichment(result, item)
+		}
 		
 		results = append(results, result)
 		return true
@@ -443,184 +915,259 @@ func extractSearchResults(data []byte, req *SearchRequest) []map[string]interfac
 
 // Client-side search implementation
 func performClientSideSearch(data []byte, req *SearchRequest) []map[string]interface{} {
+	results, _ := searchIndex(context.Background(), hugoindex.New("", data), req)
+	return results
+}
+
+// searchIndex searches the pages of a site index one at a time, returning
+// the matches found before any error
+func searchIndex(ctx context.Context, index *hugoindex.SiteIndex, req *SearchRequest) ([]map[string]interface{}, error) {
 	var results []map[string]interface{}
-	parsed := gjson.ParseBytes(data)
-	
-	query := strings.ToLower(req.Query)
 	
-	// Handle pages array
-	var itemsToSearch gjson.Result
-	if pages := parsed.Get("pages"); pages.Exists() && pages.IsArray() {
-		itemsToSearch = pages
-	} else if parsed.IsArray() {
-		itemsToSearch = parsed
-	} else {
-		return results
+	query, err := req.matcher()
+	if err != nil {
+		return results, err
 	}
 	
-	itemsToSearch.ForEach(func(key, item gjson.Result) bool {
-		// Check if item matches query
-		matched := false
-		relevanceScore := 0.0
-		
-		// Search in title (higher relevance)
-		if title := item.Get("title"); title.Exists() {
-			titleStr := strings.ToLower(title.String())
-			if strings.Contains(titleStr, query) {
-				matched = true
-				relevanceScore += 10.0
-				if titleStr == query {
-					relevanceScore += 20.0 // Exact match bonus
-				}
-			}
+	now := time.Now()
+	err = index.Pages(ctx, func(item gjson.Result) bool {
+		if result, matched := matchPage(newDocument(item), query, req, now); matched {
+			results = append(results, result)
 		}
-		
-		// Search in content/body
-		contentFields := []string{"content", "body", "summary"}
-		for _, field := range contentFields {
-			if content := item.Get(field); content.Exists() {
-				contentStr := strings.ToLower(content.String())
-				if strings.Contains(contentStr, query) {
-					matched = true
-					relevanceScore += 1.0
-					// Count number of matches for better scoring
-					relevanceScore += float64(strings.Count(contentStr, query))
+		return true
+	})
+	
+	sortByRelevance(results)
+	return results, err
+}
+
+// matchPage returns the search result for the page of d when it matches the
+// query and the filters of req
+func matchPage(d *document, query matcher, req *SearchRequest, now time.Time) (map[string]interface{}, bool) {
+	item := d.item
+	if !req.PublishOptions.Allows(item, now) || !req.DateRange.Contains(item) || !req.PathScope.Contains(item) {
+		return nil, false
+	}
+
+	// Check if item matches query
+	relevanceScore, matched := query.scoreDocument(d)
+	
+	// Apply filters
+	if matched {
+		// Content type filter
+		if req.ContentType != "" {
+			if contentType := item.Get("type"); contentType.Exists() {
+				if !strings.EqualFold(contentType.String(), req.ContentType) {
+					matched = false
 				}
 			}
 		}
 		
-		// Apply filters
-		if matched {
-			// Content type filter
-			if req.ContentType != "" {
-				if contentType := item.Get("type"); contentType.Exists() {
-					if !strings.EqualFold(contentType.String(), req.ContentType) {
-						matched = false
-					}
+		// Taxonomy filter
+		if req.Taxonomy != "" && req.Term != "" {
+			if taxonomy := item.Get(req.Taxonomy); taxonomy.Exists() {
+				found := false
+				if taxonomy.IsArray() {
+					taxonomy.ForEach(func(k, v gjson.Result) bool {
+						if strings.EqualFold(v.String(), req.Term) {
+							found = true
+							return false
+						}
+						return true
+					})
+				} else if strings.EqualFold(taxonomy.String(), req.Term) {
+					found = true
 				}
-			}
-			
-			// Taxonomy filter
-			if req.Taxonomy != "" && req.Term != "" {
-				if taxonomy := item.Get(req.Taxonomy); taxonomy.Exists() {
-					found := false
-					if taxonomy.IsArray() {
-						taxonomy.ForEach(func(k, v gjson.Result) bool {
-							if strings.EqualFold(v.String(), req.Term) {
-								found = true
-								return false
-							}
-							return true
-						})
-					} else if strings.EqualFold(taxonomy.String(), req.Term) {
-						found = true
-					}
-					if !found {
-						matched = false
-					}
-				} else {
+				if !found {
 					matched = false
 				}
+			} else {
+				matched = false
 			}
 		}
-		
-		if matched {
-			result := make(map[string]interface{})
-			
-			// Extract fields
-			if title := item.Get("title"); title.Exists() {
-				result["title"] = title.String()
-			}
-			if url := item.Get("url"); url.Exists() {
-				result["url"] = url.String()
-			}
-			if content := item.Get("content"); content.Exists() {
-				// Truncate content for search results
-				contentStr := content.String()
-				if len(contentStr) > 200 {
-					contentStr = contentStr[:200] + "..."
+	}
+	if !matched {
+		return nil, false
+	}
+
+	result := make(map[string]interface{})
+	
+	// Extract fields
+	if title := item.Get("title"); title.Exists() {
+		result["title"] = title.String()
+	}
+	if url := item.Get("url"); url.Exists() {
+		result["url"] = url.String()
+	}
+	if content := item.Get("content"); content.Exists() {
+		// Truncate content for search results
+		contentStr := content.String()
+		if len(contentStr) > 200 {
+			contentStr = contentStr[:200] + "..."
+		}
+		result["content"] = contentStr
+	}
+	if summary := item.Get("summary"); summary.Exists() {
+		result["summary"] = summary.String()
+	}
+	if date := item.Get("date"); date.Exists() {
+		result["date"] = date.String()
+	}
+	
+	// Add taxonomies
+	if categories := item.Get("categories"); categories.Exists() {
+		result["categories"] = categories.Value()
+	}
+	if tags := item.Get("tags"); tags.Exists() {
+		result["tags"] = tags.Value()
+	}
+	
+	result["score"] = relevanceScore
+	if len(d.scores) > 0 {
+		result["term_scores"] = d.scores
+	}
+	addSnippet(result, item, query, req.SnippetLength)
+	if req.Enrich {
+		addEnrichment(result, item)
+	}
+	return result, true
+}
+
+// addEnrichment adds the word count, reading time and language of item to
+// result, computing those the page lacks from its full content
+func addEnrichment(result map[string]interface{}, item gjson.Result) {
+	for field, value := range enrich.Page(item) {
+		result[field] = value
+	}
+}
+
+// Supported values for SearchRequest.Sort
+const (
+	SortRelevance = "relevance"
+	SortDateDesc  = "date_desc"
+	SortDateAsc   = "date_asc"
+	SortTitle     = "title"
+)
+
+// sortResults orders results according to the requested sort. Relevance
+// ordering is left to the search endpoint when it does not report scores.
+func sortResults(results []map[string]interface{}, sortBy string) {
+	switch sortBy {
+	case SortDateDesc, SortDateAsc:
+		sort.SliceStable(results, func(i, j int) bool {
+			dateI, okI := resultDate(results[i])
+			dateJ, okJ := resultDate(results[j])
+			switch {
+			case okI != okJ:
+				return okI // undated results sort last
+			case !dateI.Equal(dateJ):
+				if sortBy == SortDateAsc {
+					return dateI.Before(dateJ)
 				}
-				result["content"] = contentStr
+				return dateI.After(dateJ)
 			}
-			if summary := item.Get("summary"); summary.Exists() {
-				result["summary"] = summary.String()
+			return resultURL(results[i]) < resultURL(results[j])
+		})
+	case SortTitle:
+		sort.SliceStable(results, func(i, j int) bool {
+			titleI, _ := results[i]["title"].(string)
+			titleJ, _ := results[j]["title"].(string)
+			if !strings.EqualFold(titleI, titleJ) {
+				return strings.ToLower(titleI) < strings.ToLower(titleJ)
 			}
-			if date := item.Get("date"); date.Exists() {
-				result["date"] = date.String()
+			return resultURL(results[i]) < resultURL(results[j])
+		})
+	default:
+		for _, result := range results {
+			if _, ok := result["score"]; ok {
+				sortByRelevance(results)
+				return
 			}
-			
-			// Add taxonomies
-			if categories := item.Get("categories"); categories.Exists() {
-				result["categories"] = categories.Value()
-			}
-			if tags := item.Get("tags"); tags.Exists() {
-				result["tags"] = tags.Value()
-			}
-			
-			result["score"] = relevanceScore
-			results = append(results, result)
 		}
-		
-		return true
-	})
-	
-	return results
+	}
 }
 
-// Formatting functions
-func formatSearchResults(results []map[string]interface{}) string {
-	if len(results) == 0 {
-		return "[]"
-	}
-	
-	var parts []string
-	for _, result := range results {
-		parts = append(parts, fmt.Sprintf("    %s", formatSearchResult(result)))
-	}
-	
-	return "[\n" + strings.Join(parts, ",\n") + "\n  ]"
+// resultDate parses the result's date, if it has a recognisable one
+func resultDate(result map[string]interface{}) (time.Time, bool) {
+	date, _ := result["date"].(string)
+	return hugoindex.ParseDate(date)
 }
 
-func formatSearchResult(result map[string]interface{}) string {
-	var parts []string
-	
-	for key, value := range result {
-		switch v := value.(type) {
-		case string:
-			parts = append(parts, fmt.Sprintf(`"%s": "%s"`, key, strings.ReplaceAll(v, `"`, `\"`)))
-		case float64:
-			parts = append(parts, fmt.Sprintf(`"%s": %.2f`, key, v))
-		case []interface{}:
-			var items []string
-			for _, item := range v {
-				items = append(items, fmt.Sprintf(`"%v"`, item))
+func resultURL(result map[string]interface{}) string {
+	url, _ := result["url"].(string)
+	return url
+}
+
+// dedupeResults drops results whose URLs canonicalize to the URL of another
+// result, keeping the higher-scoring one, and returns how many were dropped.
+// Results without a URL are always kept.
+func dedupeResults(results []map[string]interface{}, siteURL *url.URL) ([]map[string]interface{}, int) {
+	kept := make([]map[string]interface{}, 0, len(results))
+	positions := make(map[string]int)
+	for _, result := range results {
+		key := hugoindex.CanonicalURL(siteURL, resultURL(result))
+		if key == "" {
+			kept = append(kept, result)
+			continue
+		}
+		if i, seen := positions[key]; seen {
+			score, _ := result["score"].(float64)
+			keptScore, _ := kept[i]["score"].(float64)
+			if score > keptScore {
+				kept[i] = result
 			}
-			parts = append(parts, fmt.Sprintf(`"%s": [%s]`, key, strings.Join(items, ", ")))
-		default:
-			parts = append(parts, fmt.Sprintf(`"%s": %v`, key, v))
+			continue
 		}
+		positions[key] = len(kept)
+		kept = append(kept, result)
 	}
-	
-	return "{" + strings.Join(parts, ", ") + "}"
+	return kept, len(results) - len(kept)
 }
 
-func formatMetadata(metadata map[string]interface{}) string {
-	var parts []string
-	
-	for key, value := range metadata {
-		switch v := value.(type) {
-		case string:
-			parts = append(parts, fmt.Sprintf(`"%s": "%s"`, key, v))
-		case bool:
-			parts = append(parts, fmt.Sprintf(`"%s": %t`, key, v))
-		case int:
-			parts = append(parts, fmt.Sprintf(`"%s": %d`, key, v))
-		default:
-			parts = append(parts, fmt.Sprintf(`"%s": %v`, key, v))
+// sortByRelevance orders results by descending score, breaking ties by URL so
+// that repeated searches (and therefore pages) are stable
+func sortByRelevance(results []map[string]interface{}) {
+	sort.SliceStable(results, func(i, j int) bool {
+		scoreI, _ := results[i]["score"].(float64)
+		scoreJ, _ := results[j]["score"].(float64)
+		if scoreI != scoreJ {
+			return scoreI > scoreJ
 		}
+		return resultURL(results[i]) < resultURL(results[j])
+	})
+}
+
+// paginate returns the window of results starting at offset
+func paginate(results []map[string]interface{}, offset, limit int) []map[string]interface{} {
+	if offset >= len(results) {
+		return []map[string]interface{}{}
+	}
+	end := offset + limit
+	if end > len(results) {
+		end = len(results)
+	}
+	return results[offset:end]
+}
+
+// addPaginationMetadata records the pagination window and how to fetch the next one
+func addPaginationMetadata(metadata map[string]interface{}, req *SearchRequest, totalResults, returned int) {
+	hasMore := req.Offset+returned < totalResults
+	metadata["total_results"] = totalResults
+	metadata["offset"] = req.Offset
+	metadata["limit"] = req.Limit
+	metadata["has_more"] = hasMore
+	metadata["limited"] = hasMore
+
+	if !hasMore {
+		return
+	}
+	nextOffset := req.Offset + returned
+	metadata["next_offset"] = nextOffset
+	if req.Page > 0 {
+		metadata["next_page"] = req.Page + 1
+	} else if nextOffset%req.Limit == 0 {
+		metadata["next_page"] = nextOffset/req.Limit + 1
 	}
-	
-	return "{\n    " + strings.Join(parts, ",\n    ") + "\n  }"
 }
 
 // Name returns the name of the tool.
@@ -633,6 +1180,11 @@ func (t *Tool) Description() string {
 	return t.description
 }
 
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*SearchRequest](registry, t, "Content discovery", tools.ReadOnly)
+}
+
 // SetLogger sets the logger for the Tool.
 func (t *Tool) SetLogger(logger *slog.Logger) {
 	if logger == nil {
@@ -1,10 +1,27 @@
 package search
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/generic"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/searchbackend"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 )
 
 func TestNew(t *testing.T) {
@@ -16,6 +33,28 @@ func TestNew(t *testing.T) {
 	assert.NotNil(t, tool.httpClient)
 }
 
+func TestWithCache_SharedWithLogger(t *testing.T) {
+	shared := cache.New()
+	logger := slog.New(slog.DiscardHandler)
+
+	// The shared cache is kept whichever order the options are given in
+	for _, opts := range [][]ToolOption{
+		{WithLogger(logger), WithCache(shared)},
+		{WithCache(shared), WithLogger(logger)},
+	} {
+		tool, err := New(opts...)
+		require.NoError(t, err)
+		assert.Same(t, shared, tool.cache)
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	client := httpclient.New()
+	tool, err := New(WithHTTPClient(client))
+	require.NoError(t, err)
+	assert.Same(t, client, tool.httpClient)
+}
+
 func TestSearchRequest_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -50,6 +89,22 @@ func TestSearchRequest_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "javascript scheme",
+			req: &SearchRequest{
+				HugoSitePath: "javascript:alert(1)",
+				Query:        "golang",
+			},
+			wantErr: true,
+		},
+		{
+			name: "file scheme",
+			req: &SearchRequest{
+				HugoSitePath: "file:///etc/passwd",
+				Query:        "golang",
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing query",
 			req: &SearchRequest{
@@ -76,6 +131,141 @@ func TestSearchRequest_Validate(t *testing.T) {
 			},
 			wantErr: false, // 0 gets set to default (20)
 		},
+		{
+			name: "invalid match",
+			req: &SearchRequest{
+				HugoSitePath: "https://example.com",
+				Query:        "golang",
+				Match:        "some",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid mode",
+			req: &SearchRequest{
+				HugoSitePath: "https://example.com",
+				Query:        "golang",
+				Mode:         "glob",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid regex",
+			req: &SearchRequest{
+				HugoSitePath: "https://example.com",
+				Query:        `v\d+(`,
+				Mode:         ModeRegex,
+			},
+			wantErr: true,
+		},
+		{
+			name: "regex mode",
+			req: &SearchRequest{
+				HugoSitePath: "https://example.com",
+				Query:        `v\d+\.\d+`,
+				Mode:         ModeRegex,
+			},
+			wantErr: false,
+		},
+		{
+			name: "scope path with query",
+			req: &SearchRequest{
+				HugoSitePath: "https://example.com",
+				Query:        "golang",
+				PathScope:    hugoindex.PathScope{ScopePath: "/docs/?lang=en"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "snippet length too short",
+			req: &SearchRequest{
+				HugoSitePath:  "https://example.com",
+				Query:         "golang",
+				SnippetLength: 10,
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid offset",
+			req: &SearchRequest{
+				HugoSitePath: "https://example.com",
+				Query:        "golang",
+				Offset:       20,
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative offset",
+			req: &SearchRequest{
+				HugoSitePath: "https://example.com",
+				Query:        "golang",
+				Offset:       -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "offset and page together",
+			req: &SearchRequest{
+				HugoSitePath: "https://example.com",
+				Query:        "golang",
+				Offset:       10,
+				Page:         2,
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid sort",
+			req: &SearchRequest{
+				HugoSitePath: "https://example.com",
+				Query:        "golang",
+				Sort:         "date_desc",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid sort",
+			req: &SearchRequest{
+				HugoSitePath: "https://example.com",
+				Query:        "golang",
+				Sort:         "random",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid query syntax",
+			req: &SearchRequest{
+				HugoSitePath: "https://example.com",
+				Query:        "(golang",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid retry backoff",
+			req: &SearchRequest{
+				HugoSitePath: "https://example.com",
+				Query:        "golang",
+				RetryOptions: httpclient.RetryOptions{RetryBackoff: "later"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "timeout too high",
+			req: &SearchRequest{
+				HugoSitePath: "https://example.com",
+				Query:        "golang",
+				RetryOptions: httpclient.RetryOptions{TimeoutSeconds: 1000},
+			},
+			wantErr: true,
+		},
+		{
+			name: "conflicting auth",
+			req: &SearchRequest{
+				HugoSitePath: "https://example.com",
+				Query:        "golang",
+				AuthOptions:  httpclient.AuthOptions{Auth: &httpclient.Credentials{Username: "user", BearerToken: "token"}},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -135,42 +325,6 @@ func TestValidateSearchResults(t *testing.T) {
 	}
 }
 
-func TestValidateHugoIndexForSearch(t *testing.T) {
-	tests := []struct {
-		name     string
-		data     string
-		expected bool
-	}{
-		{
-			name:     "hugo index with pages",
-			data:     `{"pages": [{"title": "Post 1", "content": "Content here"}]}`,
-			expected: true,
-		},
-		{
-			name:     "direct array of content",
-			data:     `[{"title": "Post 1", "content": "Content here"}]`,
-			expected: true,
-		},
-		{
-			name:     "empty pages array",
-			data:     `{"pages": []}`,
-			expected: false,
-		},
-		{
-			name:     "invalid JSON",
-			data:     `{invalid}`,
-			expected: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := validateHugoIndexForSearch([]byte(tt.data))
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 func TestExtractSearchResults(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -220,6 +374,92 @@ func TestExtractSearchResults(t *testing.T) {
 	}
 }
 
+func TestExtractSearchResults_FiltersExtendedQueries(t *testing.T) {
+	data := `{"results": [
+		{"title": "Go tutorial", "url": "/a", "tags": ["tutorial"]},
+		{"title": "Go draft", "url": "/b", "tags": ["draft"]}
+	]}`
+
+	results := extractSearchResults([]byte(data), &SearchRequest{Query: "go -tags:draft"})
+	require.Len(t, results, 1)
+	assert.Equal(t, "/a", results[0]["url"])
+
+	// Simple queries are trusted to the native endpoint
+	results = extractSearchResults([]byte(data), &SearchRequest{Query: "python"})
+	assert.Len(t, results, 2)
+}
+
+func TestSearch_Enrich(t *testing.T) {
+	long := strings.Repeat("the quick fox and the lazy dog ", 60)
+	data := `[
+		{"title": "Go tutorial", "url": "/a", "content": "` + long + `"},
+		{"title": "Go guide", "url": "/b", "wordcount": 900, "lang": "de", "content": "Go"}
+	]`
+
+	results := performClientSideSearch([]byte(data), &SearchRequest{Query: "go"})
+	require.Len(t, results, 2)
+	assert.NotContains(t, results[0], "word_count")
+
+	// Enrichment counts the full content, not the truncated excerpt
+	results = performClientSideSearch([]byte(data), &SearchRequest{Query: "go", Enrich: true})
+	require.Len(t, results, 2)
+	byURL := map[string]map[string]interface{}{}
+	for _, result := range results {
+		byURL[result["url"].(string)] = result
+	}
+	assert.Equal(t, 420, byURL["/a"]["word_count"])
+	assert.Equal(t, 2, byURL["/a"]["reading_time_minutes"])
+	assert.Equal(t, "en", byURL["/a"]["language"])
+	assert.Equal(t, 900, byURL["/b"]["word_count"])
+	assert.Equal(t, 5, byURL["/b"]["reading_time_minutes"])
+	assert.Equal(t, "de", byURL["/b"]["language"])
+
+	results = extractSearchResults([]byte(data), &SearchRequest{Query: "go", Enrich: true})
+	require.Len(t, results, 2)
+	assert.Equal(t, 420, results[0]["word_count"])
+}
+
+func TestSearch_Snippets(t *testing.T) {
+	content := strings.Repeat("lorem ipsum ", 30) + "Goroutines make golang concurrency cheap. " + strings.Repeat("dolor sit ", 30)
+	data := `[{"title": "Go tutorial", "url": "/a", "content": "` + content + `"}]`
+	req := &SearchRequest{Query: "golang", SnippetLength: 60}
+
+	for name, results := range map[string][]map[string]interface{}{
+		"scan":   performClientSideSearch([]byte(data), req),
+		"native": extractSearchResults([]byte(data), req),
+	} {
+		require.Len(t, results, 1, name)
+		snippet := results[0]["snippet"].(string)
+		assert.Contains(t, snippet, "Goroutines make golang concurrency cheap.", name)
+		require.Len(t, results[0]["highlights"], 1, name)
+		h := results[0]["highlights"].([]highlight)[0]
+		assert.Equal(t, "golang", string([]rune(snippet)[h.Start:h.End]), name)
+	}
+}
+
+func TestPerformClientSideSearch_FuzzyAndAny(t *testing.T) {
+	data := `[
+		{"title": "Kubernetes in Production", "url": "/k8s", "content": "Running kubernetes clusters"},
+		{"title": "Docker Basics", "url": "/docker", "content": "Containers with docker"},
+		{"title": "Golang", "url": "/go", "content": "Go programming"}
+	]`
+
+	results := performClientSideSearch([]byte(data), &SearchRequest{Query: "kubernets"})
+	assert.Empty(t, results)
+
+	results = performClientSideSearch([]byte(data), &SearchRequest{Query: "kubernets", Fuzzy: true})
+	require.Len(t, results, 1)
+	assert.Equal(t, "/k8s", results[0]["url"])
+	assert.Contains(t, results[0]["term_scores"], "kubernets")
+	assert.Equal(t, []highlight{{Start: 8, End: 18}}, results[0]["highlights"])
+
+	results = performClientSideSearch([]byte(data), &SearchRequest{Query: "kubernetes docker", Match: MatchAny})
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.Len(t, result["term_scores"], 1)
+	}
+}
+
 func TestPerformClientSideSearch(t *testing.T) {
 	data := `{
 		"pages": [
@@ -227,6 +467,7 @@ func TestPerformClientSideSearch(t *testing.T) {
 				"title": "Golang Tutorial",
 				"content": "Learn golang programming with this comprehensive tutorial",
 				"url": "/posts/golang-tutorial",
+				"date": "2024-03-01T10:00:00Z",
 				"categories": ["programming", "golang"],
 				"tags": ["tutorial", "beginner"]
 			},
@@ -234,8 +475,21 @@ func TestPerformClientSideSearch(t *testing.T) {
 				"title": "Python Guide",
 				"content": "Python programming guide for beginners",
 				"url": "/posts/python-guide",
+				"date": "2023-06-15",
 				"categories": ["programming", "python"],
 				"tags": ["guide", "beginner"]
+			},
+			{
+				"title": "Rust Notes",
+				"content": "Unfinished notes on rust programming",
+				"url": "/posts/rust-notes",
+				"draft": true
+			},
+			{
+				"title": "Zig Preview",
+				"content": "Upcoming zig programming post",
+				"url": "/posts/zig-preview",
+				"publishDate": "2999-01-01"
 			}
 		]
 	}`
@@ -246,6 +500,8 @@ func TestPerformClientSideSearch(t *testing.T) {
 		contentType   string
 		taxonomy      string
 		term          string
+		publish       hugoindex.PublishOptions
+		dates         hugoindex.DateRange
 		expectedCount int
 	}{
 		{
@@ -277,6 +533,31 @@ func TestPerformClientSideSearch(t *testing.T) {
 			term:          "java",
 			expectedCount: 0,
 		},
+		{
+			name:          "drafts included",
+			query:         "programming",
+			publish:       hugoindex.PublishOptions{IncludeDrafts: true},
+			expectedCount: 3,
+		},
+		{
+			name:          "drafts and future content included",
+			query:         "programming",
+			publish:       hugoindex.PublishOptions{IncludeDrafts: true, IncludeFuture: true},
+			expectedCount: 4,
+		},
+		{
+			name:          "date range",
+			query:         "programming",
+			dates:         hugoindex.DateRange{DateFrom: "2024-01-01"},
+			expectedCount: 1,
+		},
+		{
+			name:          "date range excludes undated pages",
+			query:         "programming",
+			publish:       hugoindex.PublishOptions{IncludeDrafts: true},
+			dates:         hugoindex.DateRange{DateTo: "2023-06-15"},
+			expectedCount: 1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -286,7 +567,10 @@ func TestPerformClientSideSearch(t *testing.T) {
 				ContentType: tt.contentType,
 				Taxonomy:    tt.taxonomy,
 				Term:        tt.term,
+				PublishOptions: tt.publish,
+				DateRange:      tt.dates,
 			}
+			require.NoError(t, req.DateRange.Validate())
 			
 			results := performClientSideSearch([]byte(data), req)
 			assert.Equal(t, tt.expectedCount, len(results))
@@ -300,49 +584,746 @@ func TestPerformClientSideSearch(t *testing.T) {
 	}
 }
 
-func TestFormatSearchResults(t *testing.T) {
+func TestSearchRequest_Validate_PageToOffset(t *testing.T) {
+	req := &SearchRequest{HugoSitePath: "https://example.com", Query: "golang", Limit: 10, Page: 3}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, 20, req.Offset)
+}
+
+func TestPerformContentScanSearch_StreamsLargeIndex(t *testing.T) {
+	var pages []string
+	for i := 0; i < 1200; i++ {
+		title := fmt.Sprintf("Post %d", i)
+		if i%100 == 0 {
+			title = fmt.Sprintf("Golang Post %d", i)
+		}
+		pages = append(pages, fmt.Sprintf(`{"title": %q, "url": "/posts/%d/"}`, title, i))
+	}
+	index := `{"pages": [` + strings.Join(pages, ",") + `]}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(index))
+	}))
+	defer server.Close()
+
+	tool, err := New(WithLogger(slog.Default()), WithHTTPClient(httpclient.New(httpclient.WithMaxResponseSize(1024))))
+	require.NoError(t, err)
+
+	siteURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	req := &SearchRequest{HugoSitePath: server.URL, Query: "golang", Limit: 20}
+	require.NoError(t, req.Validate())
+
+	results, metadata, err := tool.performContentScanSearch(context.Background(), siteURL, req, nil)
+	require.NoError(t, err)
+	assert.Len(t, results, 12)
+	assert.Equal(t, true, metadata["streamed"])
+}
+
+func TestTool_Execute_FeedFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?>
+<rss version="2.0"><channel><title>Minimal</title>
+<item><title>Learning Golang</title><link>https://example.com/posts/golang/</link><pubDate>Mon, 02 Jan 2023 15:04:05 -0700</pubDate><description>&lt;p&gt;Notes on Go&lt;/p&gt;</description></item>
+<item><title>Gardening</title><link>https://example.com/posts/garden/</link><description>Tomatoes and golang-free weekends</description></item>
+<item><title>Cooking</title><link>https://example.com/posts/cooking/</link><description>Pasta</description></item>
+</channel></rss>`))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &SearchRequest{HugoSitePath: server.URL, Query: "golang"})
+	require.NoError(t, err)
+
+	text := resp.Content[0].TextContent.Text
+	require.True(t, gjson.Valid(text), text)
+	result := gjson.Parse(text)
+	assert.Equal(t, "feed_scan", result.Get("metadata.search_method").String())
+	assert.Equal(t, "rss", result.Get("metadata.feed_format").String())
+	assert.Equal(t, server.URL+"/index.xml", result.Get("metadata.source_endpoint").String())
+	assert.True(t, result.Get("metadata.fallback_used").Bool())
+	assert.Equal(t, `["Learning Golang","Gardening"]`, result.Get("results.#.title").Raw)
+	assert.Equal(t, `{"method":"feed_scan","endpoint":"`+server.URL+`/index.xml","outcome":"success"}`, result.Get("attempts.@reverse.0").Raw)
+}
+
+func TestTool_Execute_SitemapScan(t *testing.T) {
+	var indexRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			indexRequests++
+			http.NotFound(w, r)
+		case "/sitemap.xml":
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+<url><loc>http://%[1]s/posts/golang/</loc><lastmod>2024-03-02</lastmod></url>
+<url><loc>http://%[1]s/posts/garden/</loc></url>
+<url><loc>https://elsewhere.example/posts/golang/</loc></url>
+</urlset>`, r.Host)
+		case "/posts/golang/":
+			w.Write([]byte(`<html><head><title>Learning Golang</title></head><body><main><p>Notes on golang.</p></main></body></html>`))
+		case "/posts/garden/":
+			w.Write([]byte(`<html><head><title>Gardening</title></head><body><main><p>Tomatoes.</p></main></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &SearchRequest{HugoSitePath: server.URL, Query: "golang"})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, MethodSitemapScan, result.Get("metadata.search_method").String())
+	assert.Equal(t, "sitemap_html", result.Get("metadata.source_format").String())
+	assert.False(t, result.Get("metadata.index_reused").Bool())
+	assert.Equal(t, int64(2), result.Get("metadata.pages_read").Int())
+	assert.True(t, result.Get("metadata.fallback_used").Bool())
+	assert.Equal(t, `["Learning Golang"]`, result.Get("results.#.title").Raw)
+	assert.Equal(t, "/posts/golang/", result.Get("results.0.url").String())
+
+	// Generic mode goes straight to the sitemap, reusing the index built
+	before := indexRequests
+	resp, err = tool.Execute(context.Background(), &SearchRequest{HugoSitePath: server.URL, Query: "tomatoes", Options: generic.Options{Generic: true}})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, before, indexRequests)
+	assert.False(t, result.Get("metadata.fallback_used").Bool())
+	assert.True(t, result.Get("metadata.cached").Bool())
+	assert.True(t, result.Get("metadata.index_reused").Bool())
+	assert.Equal(t, int64(2), result.Get("metadata.indexed_pages").Int())
+	assert.Equal(t, `["Gardening"]`, result.Get("results.#.title").Raw)
+	assert.Equal(t, `[{"method":"sitemap_scan","endpoint":"/sitemap.xml","outcome":"success"}]`, result.Get("attempts").Raw)
+}
+
+func TestTool_Execute_ReportsAttempts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search.json":
+			w.Write([]byte(`{"message": "not a search result"}`))
+		case "/api/search.json":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	noRetries := 0
+	_, err = tool.Execute(context.Background(), &SearchRequest{HugoSitePath: server.URL, Query: "golang", RetryOptions: httpclient.RetryOptions{MaxRetries: &noRetries}})
+	require.Error(t, err)
+
+	var coded *toolerrors.Error
+	require.True(t, errors.As(err, &coded))
+	assert.Equal(t, toolerrors.ErrCodeNotFound, coded.Code)
+	tried := coded.Data.(map[string]interface{})["attempts"].([]Attempt)
+
+	byEndpoint := make(map[string]Attempt)
+	for _, attempt := range tried {
+		byEndpoint[attempt.Method+" "+attempt.Endpoint] = attempt
+	}
+	assert.Equal(t, Attempt{Method: MethodOpenSearch, Endpoint: "/opensearch.xml", Outcome: OutcomeFailed, Status: 404, Code: toolerrors.ErrCodeNotFound, Error: "no OpenSearch description available: unexpected status: 404"}, byEndpoint["opensearch /opensearch.xml"])
+	assert.Equal(t, OutcomeInvalid, byEndpoint["hugo_native /search.json"].Outcome)
+	assert.Equal(t, toolerrors.ErrCodeValidationFailed, byEndpoint["hugo_native /search.json"].Code)
+	assert.Equal(t, 500, byEndpoint["hugo_native /api/search.json"].Status)
+	assert.Equal(t, toolerrors.ErrCodeNetworkError, byEndpoint["hugo_native /api/search.json"].Code)
+	assert.Equal(t, 404, byEndpoint["content_scan /posts/index.json"].Status)
+	assert.Equal(t, OutcomeFailed, byEndpoint["feed_scan /index.xml, /feed.json"].Outcome)
+	assert.Equal(t, OutcomeFailed, byEndpoint["sitemap_scan /sitemap.xml"].Outcome)
+
+	// Endpoints found missing are skipped by later searches
+	_, err = tool.Execute(context.Background(), &SearchRequest{HugoSitePath: server.URL, Query: "golang", RetryOptions: httpclient.RetryOptions{MaxRetries: &noRetries}})
+	require.True(t, errors.As(err, &coded))
+	for _, attempt := range coded.Data.(map[string]interface{})["attempts"].([]Attempt) {
+		if attempt.Method == MethodHugoNative && attempt.Endpoint == "/search.json" {
+			assert.Equal(t, OutcomeSkipped, attempt.Outcome)
+		}
+	}
+}
+
+func TestTool_Execute_SkipsMissingEndpoints(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[{"title": "Learning Golang", "url": "/posts/golang/", "content": "golang notes"}, {"title": "Python", "url": "/posts/python/", "content": "python notes"}]`))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	for _, query := range []string{"golang", "python"} {
+		resp, err := tool.Execute(context.Background(), &SearchRequest{HugoSitePath: server.URL, Query: query})
+		require.NoError(t, err)
+		assert.Equal(t, "hugo_native", gjson.Get(resp.Content[0].TextContent.Text, "metadata.search_method").String(), query)
+	}
+
+	// Endpoints found missing by the first search are not tried again
+	assert.Equal(t, 1, requests["/search.json"])
+	assert.Equal(t, 1, requests["/api/search.json"])
+	assert.Equal(t, 1, requests["/search/index.json"])
+}
+
+func TestTool_Execute_Regex(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[
+			{"title": "Release notes", "url": "/posts/release/", "content": "Hugo v0.120.4 fixes the build"},
+			{"title": "Videos", "url": "/posts/videos/", "content": "{{< youtube abc123 >}} and more"},
+			{"title": "Versions", "url": "/posts/versions/", "content": "Pick a version carefully"}
+		]`))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &SearchRequest{HugoSitePath: server.URL, Query: `v\d+\.\d+\.\d+`, Mode: ModeRegex})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, MethodContentScan, result.Get("metadata.search_method").String())
+	assert.True(t, result.Get("metadata.fallback_used").Bool())
+	assert.Equal(t, `["/posts/release/"]`, result.Get("results.#.url").Raw)
+	assert.Equal(t, `[{"start":5,"end":13}]`, result.Get("results.0.highlights").Raw)
+	assert.NotContains(t, paths, "/search.json", "native endpoints are skipped")
+
+	resp, err = tool.Execute(context.Background(), &SearchRequest{HugoSitePath: server.URL, Query: `\{\{<\s*youtube`, Mode: ModeRegex})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["/posts/videos/"]`, result.Get("results.#.url").Raw)
+
+	resp, err = tool.Execute(context.Background(), &SearchRequest{
+		HugoSitePath:   server.URL,
+		Query:          `v\d+`,
+		Mode:           ModeRegex,
+		ExplainOptions: tools.ExplainOptions{Explain: true},
+	})
+	require.NoError(t, err)
+	body := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, ModeRegex, body.Get("mode").String())
+	assert.Equal(t, MethodContentScan, body.Get("plan.steps.0.method").String())
+}
+
+func TestPerformClientSideSearch_Phrase(t *testing.T) {
+	data := `[
+		{"title": "Static sites", "url": "/posts/static/", "content": "A static site generator builds pages"},
+		{"title": "Generators", "url": "/posts/generators/", "content": "This site uses a static generator"}
+	]`
+
+	results := performClientSideSearch([]byte(data), &SearchRequest{Query: "static site generator"})
+	assert.Len(t, results, 2)
+
+	results = performClientSideSearch([]byte(data), &SearchRequest{Query: "static site generator", Mode: ModePhrase})
+	require.Len(t, results, 1)
+	assert.Equal(t, "/posts/static/", results[0]["url"])
+}
+
+func TestTool_Execute_ScopePath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search.json" {
+			http.NotFound(w, r)
+			return
+		}
+		assert.Equal(t, "/docs/", r.URL.Query().Get("path"))
+		w.Write([]byte(`{"results": [
+			{"title": "Installing Hugo", "url": "/docs/install/", "content": "Install hugo"},
+			{"title": "Hugo release", "url": "/posts/hugo-release/", "content": "A new hugo"}
+		]}`))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &SearchRequest{HugoSitePath: server.URL, Query: "hugo", PathScope: hugoindex.PathScope{ScopePath: "/docs"}})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, MethodHugoNative, result.Get("metadata.search_method").String())
+	assert.Equal(t, `["/docs/install/"]`, result.Get("results.#.url").Raw)
+}
+
+func TestPerformClientSideSearch_ScopePath(t *testing.T) {
+	data := `[
+		{"title": "Installing Hugo", "url": "https://example.com/docs/install/", "content": "Install hugo"},
+		{"title": "Docsify", "url": "https://example.com/docsify/", "content": "Not hugo"},
+		{"title": "Hugo release", "url": "/posts/hugo-release/", "content": "A new hugo"}
+	]`
+
+	req := &SearchRequest{Query: "hugo", PathScope: hugoindex.PathScope{ScopePath: "/docs/"}}
+	require.NoError(t, req.PathScope.Validate())
+	results := performClientSideSearch([]byte(data), req)
+	require.Len(t, results, 1)
+	assert.Equal(t, "https://example.com/docs/install/", results[0]["url"])
+}
+
+func TestTool_Execute_Explain(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[{"title": "Learning Golang", "url": "/posts/golang/", "content": "golang notes"}]`))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+	_, err = tool.Execute(context.Background(), &SearchRequest{HugoSitePath: server.URL, Query: "golang"})
+	require.NoError(t, err)
+	before := requests
+
+	resp, err := tool.Execute(context.Background(), &SearchRequest{
+		HugoSitePath:   server.URL,
+		Query:          `"learning golang" -python`,
+		ContentType:    "post",
+		ExplainOptions: tools.ExplainOptions{Explain: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, before, requests, "explain fetches nothing")
+
+	body := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.True(t, body.Get("explain").Bool())
+	assert.Equal(t, `"learning golang"`, body.Get("native_query").String())
+	assert.Equal(t, "post", body.Get("plan.filters.content_type").String())
+	assert.Equal(t, `"learning golang" -python`, body.Get("plan.filters.query").String())
+
+	steps := body.Get("plan.steps").Array()
+	require.Len(t, steps, 1+4+6+2+1)
+	assert.Equal(t, MethodOpenSearch, steps[0].Get("method").String())
+	assert.Equal(t, cache.StateNegative, steps[0].Get("cache.state").String())
+
+	// Endpoints found missing by the first search would be skipped
+	assert.Equal(t, "/search.json", steps[1].Get("endpoint").String())
+	assert.True(t, steps[1].Get("skipped").Bool())
+	assert.Contains(t, steps[1].Get("url").String(), "type=post")
+	assert.Equal(t, "/index.json", steps[4].Get("endpoint").String())
+	assert.False(t, steps[4].Get("skipped").Bool())
+	assert.Equal(t, MethodContentScan, steps[5].Get("method").String())
+	assert.Equal(t, cache.StateMissing, steps[5].Get("cache.state").String())
+	assert.Equal(t, MethodFeedScan, steps[12].Get("method").String())
+	assert.Equal(t, MethodSitemapScan, steps[13].Get("method").String())
+	assert.Equal(t, "/sitemap.xml", steps[13].Get("endpoint").String())
+}
+
+func TestTool_Execute_RegisteredSite(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		requests[r.URL.Path]++
+		if r.URL.Path != "/api/find.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"results": [{"title": "Learning Golang", "url": "/posts/golang/", "content": "golang notes"}]}`))
+	}))
+	defer server.Close()
+
+	registry := sites.New()
+	require.NoError(t, registry.Register(sites.Site{
+		Alias:     "blog",
+		URL:       server.URL,
+		Auth:      &httpclient.Credentials{BearerToken: "secret"},
+		Endpoints: map[string]string{sites.EndpointSearch: "/api/find.json"},
+	}))
+	tool, err := New(WithSites(registry))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &SearchRequest{SiteOptions: sites.SiteOptions{Site: "blog"}, Query: "golang"})
+	require.NoError(t, err)
+	assert.Equal(t, "hugo_native", gjson.Get(resp.Content[0].TextContent.Text, "metadata.search_method").String())
+	assert.Equal(t, "Learning Golang", gjson.Get(resp.Content[0].TextContent.Text, "results.0.title").String())
+
+	// The site's own search endpoint is tried before the conventional ones
+	assert.Equal(t, 1, requests["/api/find.json"])
+	assert.Zero(t, requests["/search.json"])
+
+	_, err = tool.Execute(context.Background(), &SearchRequest{SiteOptions: sites.SiteOptions{Site: "unknown"}, Query: "golang"})
+	assert.Error(t, err)
+}
+
+func TestTool_Execute_Algolia(t *testing.T) {
+	var queries int
+	algoliaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries++
+		if r.Header.Get("X-Algolia-API-Key") != "search-key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"nbHits": 2, "hits": [
+			{"objectID": "1", "title": "Learning Golang", "permalink": "/posts/golang/", "content": "golang notes"},
+			{"objectID": "2", "title": "Golang Draft", "uri": "/posts/draft/", "draft": true}
+		]}`))
+	}))
+	defer algoliaServer.Close()
+
+	var siteRequests int
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siteRequests++
+		http.NotFound(w, r)
+	}))
+	defer site.Close()
+
+	registry := sites.New()
+	config := &searchbackend.Algolia{AppID: "ABC123", SearchKey: "search-key", IndexName: "blog", Host: algoliaServer.URL}
+	require.NoError(t, registry.Register(sites.Site{Alias: "blog", URL: site.URL, Config: searchbackend.Config{Algolia: config}}))
+	tool, err := New(WithSites(registry))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &SearchRequest{SiteOptions: sites.SiteOptions{Site: "blog"}, Query: "golang"})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, MethodAlgolia, result.Get("metadata.search_method").String())
+	assert.Equal(t, config.QueryURL(), result.Get("metadata.source_endpoint").String())
+	assert.Equal(t, int64(2), result.Get("metadata.total_hits").Int())
+	assert.Equal(t, `["Learning Golang"]`, result.Get("results.#.title").Raw)
+	assert.Equal(t, "/posts/golang/", result.Get("results.0.url").String())
+	assert.Equal(t, 1, queries)
+	assert.Zero(t, siteRequests)
+
+	// Explaining the search lists the index first, without querying it
+	resp, err = tool.Execute(context.Background(), &SearchRequest{SiteOptions: sites.SiteOptions{Site: "blog"}, Query: "golang", ExplainOptions: tools.ExplainOptions{Explain: true}})
+	require.NoError(t, err)
+	step := gjson.Get(resp.Content[0].TextContent.Text, "plan.steps.0")
+	assert.Equal(t, MethodAlgolia, step.Get("method").String())
+	assert.Equal(t, config.QueryURL(), step.Get("url").String())
+	assert.Equal(t, 1, queries)
+
+	// A rejected key falls back to the site's own endpoints
+	config.SearchKey = "revoked"
+	_, err = tool.Execute(context.Background(), &SearchRequest{SiteOptions: sites.SiteOptions{Site: "blog"}, Query: "golang"})
+	var coded *toolerrors.Error
+	require.ErrorAs(t, err, &coded)
+	tried := coded.Data.(map[string]interface{})["attempts"].([]Attempt)
+	assert.Equal(t, MethodAlgolia, tried[0].Method)
+	assert.Equal(t, config.QueryURL(), tried[0].Endpoint)
+	assert.Equal(t, http.StatusForbidden, tried[0].Status)
+	assert.Equal(t, MethodOpenSearch, tried[1].Method)
+	assert.NotZero(t, siteRequests)
+}
+
+func TestTool_Execute_SearchBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/indexes/pages/search":
+			w.Write([]byte(`{"estimatedTotalHits": 1, "hits": [{"id": "1", "title": "Learning Golang", "uri": "/posts/golang/"}]}`))
+		case "/collections/pages/documents/search":
+			w.Write([]byte(`{"found": 1, "hits": [{"document": {"id": "1", "title": "Learning Golang", "uri": "/posts/golang/"}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
 	tests := []struct {
-		name     string
-		results  []map[string]interface{}
-		expected string
+		name   string
+		config searchbackend.Config
+	}{
+		{name: MethodMeilisearch, config: searchbackend.Config{Meilisearch: &searchbackend.Meilisearch{Host: server.URL, IndexName: "pages"}}},
+		{name: MethodTypesense, config: searchbackend.Config{Typesense: &searchbackend.Typesense{Host: server.URL, SearchKey: "key", Collection: "pages"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := sites.New()
+			require.NoError(t, registry.Register(sites.Site{Alias: "blog", URL: server.URL, Config: tt.config}))
+			tool, err := New(WithSites(registry))
+			require.NoError(t, err)
+
+			resp, err := tool.Execute(context.Background(), &SearchRequest{SiteOptions: sites.SiteOptions{Site: "blog"}, Query: "golang"})
+			require.NoError(t, err)
+			result := gjson.Parse(resp.Content[0].TextContent.Text)
+			assert.Equal(t, tt.name, result.Get("metadata.search_method").String())
+			assert.Equal(t, "pages", result.Get("metadata.index").String())
+			assert.Equal(t, `["/posts/golang/"]`, result.Get("results.#.url").Raw)
+		})
+	}
+}
+
+func TestTool_Execute_OpenSearch(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		wantTitles  string
 	}{
 		{
-			name:     "empty results",
-			results:  []map[string]interface{}{},
-			expected: "[]",
+			name:        "JSON results",
+			description: `<Url type="text/html" template="/find/?q={searchTerms}"/><Url type="application/json" template="/api/find?q={searchTerms}&amp;n={count?}&amp;lang={language?}"/>`,
+			wantTitles:  `["Learning Golang"]`,
 		},
 		{
-			name: "single result",
-			results: []map[string]interface{}{
-				{"title": "Test Post", "score": 1.5},
-			},
-			expected: "[\n    {\"title\": \"Test Post\", \"score\": 1.50}\n  ]",
+			name:        "feed results",
+			description: `<Url type="application/rss+xml" template="/find.xml?q={searchTerms}"/>`,
+			wantTitles:  `["Golang Feed"]`,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := formatSearchResults(tt.results)
-			assert.Equal(t, tt.expected, result)
+			var query url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/opensearch.xml":
+					w.Write([]byte(`<?xml version="1.0"?><OpenSearchDescription xmlns="http://a9.com/-/spec/opensearch/1.1/"><ShortName>Site</ShortName>` + tt.description + `</OpenSearchDescription>`))
+				case "/api/find":
+					query = r.URL.Query()
+					w.Write([]byte(`{"results": [{"title": "Learning Golang", "url": "/posts/golang/"}]}`))
+				case "/find.xml":
+					query = r.URL.Query()
+					w.Write([]byte(`<rss version="2.0"><channel><item><title>Golang Feed</title><link>/posts/golang/</link></item></channel></rss>`))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			tool, err := New()
+			require.NoError(t, err)
+
+			resp, err := tool.Execute(context.Background(), &SearchRequest{HugoSitePath: server.URL, Query: "golang"})
+			require.NoError(t, err)
+
+			result := gjson.Parse(resp.Content[0].TextContent.Text)
+			assert.Equal(t, "hugo_native", result.Get("metadata.search_method").String())
+			assert.Equal(t, server.URL+"/opensearch.xml", result.Get("metadata.opensearch").String())
+			assert.False(t, result.Get("metadata.fallback_used").Bool())
+			assert.Equal(t, tt.wantTitles, result.Get("results.#.title").Raw)
+			assert.Equal(t, "golang", query.Get("q"))
+		})
+	}
+}
+
+func TestDedupeResults(t *testing.T) {
+	siteURL, err := url.Parse("https://example.com")
+	require.NoError(t, err)
+
+	results := []map[string]interface{}{
+		{"url": "/posts/foo/", "score": 1.0},
+		{"url": "/posts/bar/", "score": 2.0},
+		{"url": "https://EXAMPLE.com/posts/foo/index.html", "score": 3.0},
+		{"url": "/posts/bar", "score": 1.0},
+		{"title": "No URL"},
+		{"title": "Also no URL"},
+	}
+
+	kept, duplicates := dedupeResults(results, siteURL)
+	assert.Equal(t, 2, duplicates)
+	require.Len(t, kept, 4)
+	assert.Equal(t, "https://EXAMPLE.com/posts/foo/index.html", kept[0]["url"])
+	assert.Equal(t, "/posts/bar/", kept[1]["url"])
+}
+
+func TestSortByRelevance(t *testing.T) {
+	results := []map[string]interface{}{
+		{"url": "/b", "score": 1.0},
+		{"url": "/c", "score": 2.0},
+		{"url": "/a", "score": 1.0},
+	}
+
+	sortByRelevance(results)
+
+	assert.Equal(t, "/c", results[0]["url"])
+	assert.Equal(t, "/a", results[1]["url"])
+	assert.Equal(t, "/b", results[2]["url"])
+}
+
+func TestSearchQuery_Score(t *testing.T) {
+	score := func(item, query string) float64 {
+		q, err := parseQuery(query)
+		require.NoError(t, err)
+		s, _ := q.score(gjson.Parse(item))
+		return s
+	}
+
+	exact := `{"title": "Golang"}`
+	prefix := `{"title": "Golang Tutorial"}`
+	contentOnly := `{"title": "Other", "content": "golang golang golang golang"}`
+	tagged := `{"title": "Other", "content": "golang", "tags": ["golang"]}`
+
+	assert.Greater(t, score(exact, "golang"), score(prefix, "golang"))
+	assert.Greater(t, score(prefix, "golang"), score(contentOnly, "golang"))
+	assert.Greater(t, score(tagged, "golang"), score(`{"content": "golang"}`, "golang"))
+	assert.Greater(t, score(contentOnly, "golang"), score(`{"content": "golang"}`, "golang"))
+}
+
+func TestSearchQuery_Match(t *testing.T) {
+	item := gjson.Parse(`{
+		"title": "Running Go programs",
+		"content": "Tips for running tests quickly",
+		"tags": ["golang", "testing"],
+		"draft": false
+	}`)
+
+	tests := []struct {
+		name    string
+		query   string
+		matched bool
+	}{
+		{name: "stemmed term", query: "run", matched: true},
+		{name: "all terms required", query: "run python", matched: false},
+		{name: "terms in different fields", query: "programs tests", matched: true},
+		{name: "stop words ignored", query: "the tests", matched: true},
+		{name: "phrase match", query: `"running tests"`, matched: true},
+		{name: "phrase out of order", query: `"tests running"`, matched: false},
+		{name: "phrase with term", query: `"go programs" tips`, matched: true},
+		{name: "field scoped", query: "title:go", matched: true},
+		{name: "field scoped miss", query: "title:tips", matched: false},
+		{name: "field scoped phrase", query: `content:"running tests"`, matched: true},
+		{name: "and", query: "title:golang AND tags:testing", matched: false},
+		{name: "and across fields", query: "title:go AND tags:testing", matched: true},
+		{name: "or", query: "python OR rust", matched: false},
+		{name: "or match", query: "python OR go", matched: true},
+		{name: "exclusion", query: "go -python", matched: true},
+		{name: "exclusion miss", query: "go -tips", matched: false},
+		{name: "not operator", query: "go AND NOT tags:testing", matched: false},
+		{name: "grouping", query: "(python OR rust) go", matched: false},
+		{name: "grouping match", query: "(python OR tips) go", matched: true},
+		{name: "only exclusion", query: "-python", matched: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := parseQuery(tt.query)
+			require.NoError(t, err)
+			_, matched := q.score(item)
+			assert.Equal(t, tt.matched, matched)
+		})
+	}
+}
+
+func TestSortResults(t *testing.T) {
+	newResults := func() []map[string]interface{} {
+		return []map[string]interface{}{
+			{"url": "/b", "title": "beta", "date": "2023-01-01", "score": 1.0},
+			{"url": "/a", "title": "Alpha", "date": "2024-06-01T10:00:00Z", "score": 3.0},
+			{"url": "/c", "title": "gamma", "score": 2.0},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		sort         string
+		expectedURLs []string
+	}{
+		{name: "relevance", sort: SortRelevance, expectedURLs: []string{"/a", "/c", "/b"}},
+		{name: "date descending", sort: SortDateDesc, expectedURLs: []string{"/a", "/b", "/c"}},
+		{name: "date ascending", sort: SortDateAsc, expectedURLs: []string{"/b", "/a", "/c"}},
+		{name: "title", sort: SortTitle, expectedURLs: []string{"/a", "/b", "/c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := newResults()
+			sortResults(results, tt.sort)
+
+			urls := []string{}
+			for _, result := range results {
+				urls = append(urls, result["url"].(string))
+			}
+			assert.Equal(t, tt.expectedURLs, urls)
 		})
 	}
 }
 
-func TestFormatMetadata(t *testing.T) {
-	metadata := map[string]interface{}{
-		"search_method":   "hugo_native",
-		"result_count":    5,
-		"cached":         true,
-		"fallback_used":  false,
+func TestSortResults_RelevanceWithoutScoresKeepsOrder(t *testing.T) {
+	results := []map[string]interface{}{{"url": "/z"}, {"url": "/a"}}
+	sortResults(results, SortRelevance)
+	assert.Equal(t, "/z", results[0]["url"])
+}
+
+func TestPaginate(t *testing.T) {
+	results := []map[string]interface{}{
+		{"url": "/1"}, {"url": "/2"}, {"url": "/3"}, {"url": "/4"}, {"url": "/5"},
+	}
+
+	tests := []struct {
+		name         string
+		offset       int
+		page         int
+		limit        int
+		expectedURLs []string
+		hasMore      bool
+		nextPage     interface{}
+	}{
+		{name: "first page", offset: 0, limit: 2, expectedURLs: []string{"/1", "/2"}, hasMore: true, nextPage: 2},
+		{name: "second page", offset: 2, page: 2, limit: 2, expectedURLs: []string{"/3", "/4"}, hasMore: true, nextPage: 3},
+		{name: "last partial page", offset: 4, limit: 2, expectedURLs: []string{"/5"}, hasMore: false},
+		{name: "past the end", offset: 10, limit: 2, expectedURLs: []string{}, hasMore: false},
+		{name: "unaligned offset", offset: 1, limit: 2, expectedURLs: []string{"/2", "/3"}, hasMore: true},
 	}
 
-	result := formatMetadata(metadata)
-	
-	// Check that all keys are present in the formatted result
-	assert.Contains(t, result, "search_method")
-	assert.Contains(t, result, "result_count")
-	assert.Contains(t, result, "cached")
-	assert.Contains(t, result, "fallback_used")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &SearchRequest{Offset: tt.offset, Page: tt.page, Limit: tt.limit}
+			page := paginate(results, tt.offset, tt.limit)
+
+			urls := []string{}
+			for _, result := range page {
+				urls = append(urls, result["url"].(string))
+			}
+			assert.Equal(t, tt.expectedURLs, urls)
+
+			metadata := map[string]interface{}{}
+			addPaginationMetadata(metadata, req, len(results), len(page))
+			assert.Equal(t, len(results), metadata["total_results"])
+			assert.Equal(t, tt.hasMore, metadata["has_more"])
+			assert.Equal(t, tt.nextPage, metadata["next_page"])
+		})
+	}
+}
+
+func TestTool_Execute_PhraseQueryIsValidJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[
+			{"title": "Getting Started", "url": "/docs/getting-started/", "content": "Getting started with \"quotes\" and a backslash \\"},
+			{"title": "Started Over", "url": "/posts/over/", "content": "We started getting somewhere"}
+		]`))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &SearchRequest{HugoSitePath: server.URL, Query: `"getting started"`})
+	require.NoError(t, err)
+
+	text := resp.Content[0].TextContent.Text
+	require.True(t, gjson.Valid(text), text)
+	result := gjson.Parse(text)
+	assert.Equal(t, `"getting started"`, result.Get("query").String())
+	assert.Equal(t, int64(2), result.Get("results.#").Int())
 }
 
 func TestTool_SetLogger(t *testing.T) {
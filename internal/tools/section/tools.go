@@ -0,0 +1,500 @@
+package section
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/tidwall/gjson"
+)
+
+// Page sources
+const (
+	SourceSectionIndex = "section_index"
+	SourceSiteIndex    = "site_index"
+)
+
+// maxDepth bounds how many levels of nested sections are listed
+const maxDepth = 10
+
+// maxOffset bounds how deep into the page list clients may page
+const maxOffset = 10000
+
+// pageFields are the page metadata fields returned for each page
+var pageFields = []string{"title", "url", "date", "lastmod", "summary", "description", "section", "type", "kind", "tags", "categories"}
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool lists the pages within a section of a Hugo site.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// SectionRequest represents the request parameters for listing a section.
+type SectionRequest struct {
+	HugoSitePath string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	Section      string `json:"section" jsonschema:"title=Section (e.g. posts or docs/guides)"`
+	Depth        int    `json:"depth,omitempty" jsonschema:"title=Levels of nested sections to include (1 lists only the section's own pages),minimum=1,maximum=10"`
+	Limit        int    `json:"limit,omitempty" jsonschema:"title=Result Limit,minimum=1,maximum=200"`
+	Offset       int    `json:"offset,omitempty" jsonschema:"title=Result Offset,minimum=0,maximum=10000"`
+	Page         int    `json:"page,omitempty" jsonschema:"title=Page Number (1-based, uses limit as page size),minimum=1"`
+	Cursor       string `json:"cursor,omitempty" jsonschema:"title=Cursor from a previous response's next_cursor"`
+
+	hugoindex.PublishOptions
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_get_section"),
+		name:        "hugo_reader_get_section",
+		description: "List the pages within a section of a Hugo site, such as 'posts' or 'docs', with their metadata. Reads the section's own index.json when available, otherwise filters the site index. Use depth to include pages of nested sections.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *SectionRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+
+	r.Section = strings.Trim(r.Section, "/")
+	if r.Section == "" {
+		return fmt.Errorf("section is required")
+	}
+	for _, part := range strings.Split(r.Section, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid section: %s", r.Section)
+		}
+	}
+
+	if r.Depth == 0 {
+		r.Depth = 1
+	} else if r.Depth < 1 || r.Depth > maxDepth {
+		return fmt.Errorf("depth must be between 1 and %d", maxDepth)
+	}
+
+	if r.Limit == 0 {
+		r.Limit = 50
+	} else if r.Limit < 1 || r.Limit > 200 {
+		return fmt.Errorf("limit must be between 1 and 200")
+	}
+
+	// Resolve page into an offset so the rest of the tool only deals with offsets
+	if r.Offset < 0 || r.Offset > maxOffset {
+		return fmt.Errorf("offset must be between 0 and %d", maxOffset)
+	}
+	if r.Page < 0 {
+		return fmt.Errorf("page must be 1 or greater")
+	}
+	if r.Cursor != "" {
+		if r.Offset > 0 || r.Page > 0 {
+			return fmt.Errorf("use either cursor or offset and page, not both")
+		}
+		offset, err := cache.DecodeCursor(r.Cursor, r.requestHash())
+		if err != nil {
+			return err
+		}
+		r.Offset = offset
+	}
+	if r.Page > 0 {
+		if r.Offset > 0 {
+			return fmt.Errorf("use either offset or page, not both")
+		}
+		r.Offset = (r.Page - 1) * r.Limit
+		if r.Offset > maxOffset {
+			return fmt.Errorf("page is too large (offset must not exceed %d)", maxOffset)
+		}
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// requestHash identifies the pages the request lists, ignoring how they are
+// paginated
+func (r *SectionRequest) requestHash() string {
+	return cache.RequestHash("section", strings.TrimRight(r.HugoSitePath, "/"), r.Section, strconv.Itoa(r.Depth), strconv.FormatBool(r.IncludeDrafts), strconv.FormatBool(r.IncludeFuture))
+}
+
+// listingState is a section listing, kept for its cursors to resume
+type listingState struct {
+	Pages          []map[string]interface{} `json:"pages"`
+	Source         string                   `json:"source"`
+	SourceEndpoint string                   `json:"source_endpoint"`
+	Excluded       int                      `json:"excluded"`
+	Duplicates     int                      `json:"duplicates"`
+}
+
+// Execute lists the pages within a section.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	sectionRequest, ok := req.(*SectionRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := sectionRequest.SiteOptions.Apply(ctx, t.sites, &sectionRequest.HugoSitePath, &sectionRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := sectionRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := sectionRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = sectionRequest.CacheOptions.Apply(ctx, 0)
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(sectionRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", sectionRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = sectionRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	// A cursor resumes the listing it was issued for without recomputing it
+	hash := sectionRequest.requestHash()
+	var state listingState
+	resumed := false
+	if data, ok := t.cache.CursorState(hash); ok && sectionRequest.Cursor != "" {
+		resumed = json.Unmarshal(data, &state) == nil
+	}
+
+	metadata := map[string]interface{}{}
+	if !resumed {
+		state, err = t.listSection(ctx, siteURL, sectionRequest, metadata)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pages := paginate(state.Pages, sectionRequest.Offset, sectionRequest.Limit)
+	metadata["source"] = state.Source
+	metadata["source_endpoint"] = state.SourceEndpoint
+	metadata["depth"] = sectionRequest.Depth
+	metadata["excluded_count"] = state.Excluded
+	metadata["duplicates_removed"] = state.Duplicates
+	metadata["resumed"] = resumed
+	addPaginationMetadata(metadata, sectionRequest, len(state.Pages), len(pages))
+	if metadata["has_more"] == true {
+		metadata["next_cursor"] = cache.EncodeCursor(hash, sectionRequest.Offset+len(pages))
+		if !resumed {
+			if data, err := json.Marshal(state); err == nil {
+				t.cache.SetCursorState(hash, data)
+			}
+		}
+	}
+	metadata["site_source"] = sites.Source(ctx)
+
+	response := map[string]interface{}{
+		"success":  true,
+		"section":  sectionRequest.Section,
+		"pages":    pages,
+		"metadata": metadata,
+		"errors":   []string{},
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal section listing", "error", err)
+		return nil, fmt.Errorf("failed to marshal section listing: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Listed section", "site", sectionRequest.HugoSitePath, "section", sectionRequest.Section, "source", state.Source, "total", len(state.Pages), "returned", len(pages))
+	return tools.TextResponse(responseJSON), nil
+}
+
+// listSection lists the published pages of the requested section, from the
+// section's own index when available and the site index otherwise, recording
+// how the index was read in metadata
+func (t *Tool) listSection(ctx context.Context, siteURL *url.URL, sectionRequest *SectionRequest, metadata map[string]interface{}) (listingState, error) {
+	listing := &sectionListing{
+		section: sectionRequest.Section,
+		depth:   sectionRequest.Depth,
+		publish: sectionRequest.PublishOptions,
+		now:     time.Now(),
+		seen:    make(map[string]bool),
+	}
+
+	// Prefer the section's own index, falling back to filtering the site index
+	source := SourceSectionIndex
+	index, err := t.walkSectionIndex(ctx, siteURL, sectionRequest.Section, listing)
+	if err != nil {
+		t.log.DebugContext(ctx, "Section index unavailable, filtering the site index", "section", sectionRequest.Section, "error", err)
+
+		source = SourceSiteIndex
+		index, err = hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+		if err == nil {
+			err = index.Pages(ctx, func(page gjson.Result) bool {
+				listing.add(page, 0)
+				return true
+			})
+		}
+		if err != nil {
+			t.log.ErrorContext(ctx, "No index available for section", "site", sectionRequest.HugoSitePath, "section", sectionRequest.Section, "error", err)
+			return listingState{}, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "no index available for section '%s' at Hugo site: %s", sectionRequest.Section, sectionRequest.HugoSitePath)
+		}
+	}
+
+	if len(listing.pages) == 0 && listing.excluded == 0 && source == SourceSiteIndex {
+		return listingState{}, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "section '%s' not found at Hugo site: %s", sectionRequest.Section, sectionRequest.HugoSitePath)
+	}
+
+	metadata["cached"] = index.Cached()
+	metadata["streamed"] = index.Streamed()
+	return listingState{
+		Pages:          listing.pages,
+		Source:         source,
+		SourceEndpoint: index.URL(),
+		Excluded:       listing.excluded,
+		Duplicates:     listing.duplicates,
+	}, nil
+}
+
+// walkSectionIndex lists the pages in a section's own index, descending into
+// the indexes of nested sections while they are within the requested depth.
+// It returns the top-level section index.
+func (t *Tool) walkSectionIndex(ctx context.Context, siteURL *url.URL, section string, listing *sectionListing) (*hugoindex.SiteIndex, error) {
+	index, err := hugoindex.LoadPath(ctx, t.cache, t.httpClient, siteURL, "/"+section+"/index.json")
+	if err != nil {
+		return nil, err
+	}
+
+	// Pages a section index lists belong to it even when their URLs do not
+	// follow the section path, as with custom permalinks
+	level := listing.level(section) + 1
+
+	var nested []string
+	err = index.Pages(ctx, func(page gjson.Result) bool {
+		if pagePath(page) == section {
+			return true
+		}
+		if listing.add(page, level) && page.Get("kind").String() == "section" {
+			if path := pagePath(page); listing.level(path) < listing.depth {
+				nested = append(nested, path)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, child := range nested {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if _, err := t.walkSectionIndex(ctx, siteURL, child, listing); err != nil {
+			t.log.DebugContext(ctx, "Nested section index unavailable", "section", child, "error", err)
+		}
+	}
+	return index, nil
+}
+
+// sectionListing collects the published pages of a section up to a depth,
+// once each
+type sectionListing struct {
+	section    string
+	depth      int
+	publish    hugoindex.PublishOptions
+	now        time.Time
+	seen       map[string]bool
+	pages      []map[string]interface{}
+	excluded   int
+	duplicates int
+}
+
+// add lists page if it lies within the section and depth, reporting whether
+// it was added. Pages outside the section path are listed at defaultLevel
+// when it is non-zero.
+func (l *sectionListing) add(page gjson.Result, defaultLevel int) bool {
+	path := pagePath(page)
+	if path == "" {
+		return false
+	}
+	level := l.level(path)
+	if level == 0 {
+		level = defaultLevel
+	}
+	if level < 1 || level > l.depth {
+		return false
+	}
+	if l.seen[path] {
+		l.duplicates++
+		return false
+	}
+	l.seen[path] = true
+
+	if !l.publish.Allows(page, l.now) {
+		l.excluded++
+		return false
+	}
+
+	entry := map[string]interface{}{
+		"path":  "/" + path + "/",
+		"depth": level,
+	}
+	for _, field := range pageFields {
+		if value := page.Get(field); value.Exists() {
+			entry[field] = value.Value()
+		}
+	}
+	l.pages = append(l.pages, entry)
+	return true
+}
+
+// level returns how many levels below the section a page path lies, or 0 if
+// it is not within the section
+func (l *sectionListing) level(path string) int {
+	rel, ok := strings.CutPrefix(path, l.section+"/")
+	if !ok || rel == "" {
+		return 0
+	}
+	return strings.Count(rel, "/") + 1
+}
+
+// pagePath returns the path of a page's canonical URL without surrounding
+// slashes, so aliases such as /posts/foo/index.html list as one page
+func pagePath(page gjson.Result) string {
+	pageURL := hugoindex.CanonicalURL(nil, hugoindex.PageURL(page))
+	if u, err := url.Parse(pageURL); err == nil {
+		pageURL = u.Path
+	}
+	return strings.Trim(pageURL, "/")
+}
+
+// paginate returns the window of pages starting at offset
+func paginate(pages []map[string]interface{}, offset, limit int) []map[string]interface{} {
+	if offset >= len(pages) {
+		return []map[string]interface{}{}
+	}
+	end := offset + limit
+	if end > len(pages) {
+		end = len(pages)
+	}
+	return pages[offset:end]
+}
+
+// addPaginationMetadata records the pagination window and how to fetch the next one
+func addPaginationMetadata(metadata map[string]interface{}, req *SectionRequest, total, returned int) {
+	hasMore := req.Offset+returned < total
+	metadata["total_pages"] = total
+	metadata["offset"] = req.Offset
+	metadata["limit"] = req.Limit
+	metadata["has_more"] = hasMore
+
+	if !hasMore {
+		return
+	}
+	nextOffset := req.Offset + returned
+	metadata["next_offset"] = nextOffset
+	if req.Page > 0 {
+		metadata["next_page"] = req.Page + 1
+	} else if nextOffset%req.Limit == 0 {
+		metadata["next_page"] = nextOffset/req.Limit + 1
+	}
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*SectionRequest](registry, t, "Site exploration", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
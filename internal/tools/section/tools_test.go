@@ -0,0 +1,237 @@
+package section
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNew(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+	assert.NotNil(t, tool)
+	assert.Equal(t, "hugo_reader_get_section", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+	assert.NotNil(t, tool.cache)
+	assert.NotNil(t, tool.httpClient)
+}
+
+func TestSectionRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *SectionRequest
+		wantErr bool
+	}{
+		{
+			name:    "valid request with defaults",
+			req:     &SectionRequest{HugoSitePath: "https://example.com", Section: "/posts/"},
+			wantErr: false,
+		},
+		{
+			name:    "nested section with depth and page",
+			req:     &SectionRequest{HugoSitePath: "https://example.com", Section: "docs/guides", Depth: 3, Page: 2},
+			wantErr: false,
+		},
+		{
+			name:    "missing hugo_site_path",
+			req:     &SectionRequest{Section: "posts"},
+			wantErr: true,
+		},
+		{
+			name:    "missing section",
+			req:     &SectionRequest{HugoSitePath: "https://example.com", Section: "/"},
+			wantErr: true,
+		},
+		{
+			name:    "section escaping the site",
+			req:     &SectionRequest{HugoSitePath: "https://example.com", Section: "posts/../admin"},
+			wantErr: true,
+		},
+		{
+			name:    "depth too high",
+			req:     &SectionRequest{HugoSitePath: "https://example.com", Section: "posts", Depth: 11},
+			wantErr: true,
+		},
+		{
+			name:    "offset and page",
+			req:     &SectionRequest{HugoSitePath: "https://example.com", Section: "posts", Offset: 10, Page: 2},
+			wantErr: true,
+		},
+		{
+			name:    "cursor and offset",
+			req:     &SectionRequest{HugoSitePath: "https://example.com", Section: "posts", Offset: 10, Cursor: cache.EncodeCursor("x", 10)},
+			wantErr: true,
+		},
+		{
+			name:    "cursor for another request",
+			req:     &SectionRequest{HugoSitePath: "https://example.com", Section: "posts", Cursor: cache.EncodeCursor("x", 10)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotContains(t, tt.req.Section, "//")
+				assert.GreaterOrEqual(t, tt.req.Depth, 1)
+				assert.Greater(t, tt.req.Limit, 0)
+			}
+		})
+	}
+}
+
+func TestTool_Execute_SectionIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/docs/index.json":
+			w.Write([]byte(`[
+				{"title": "Docs", "url": "/docs/", "kind": "section"},
+				{"title": "Intro", "url": "/docs/intro/", "kind": "page"},
+				{"title": "Guides", "url": "/docs/guides/", "kind": "section"},
+				{"title": "Changelog", "url": "/changelog/", "kind": "page"}
+			]`))
+		case "/docs/guides/index.json":
+			w.Write([]byte(`[{"title": "Setup", "url": "/docs/guides/setup/", "kind": "page"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	// Only the section's own pages by default
+	resp, err := tool.Execute(context.Background(), &SectionRequest{HugoSitePath: server.URL, Section: "docs"})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, SourceSectionIndex, result.Get("metadata.source").String())
+	assert.Equal(t, `["Intro","Guides","Changelog"]`, result.Get("pages.#.title").Raw)
+
+	// Nested section indexes are read within the requested depth
+	resp, err = tool.Execute(context.Background(), &SectionRequest{HugoSitePath: server.URL, Section: "docs", Depth: 2})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["Intro","Guides","Changelog","Setup"]`, result.Get("pages.#.title").Raw)
+	assert.Equal(t, int64(2), result.Get("pages.3.depth").Int())
+}
+
+func TestTool_Execute_SiteIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"pages": [
+			{"title": "Home", "url": "/"},
+			{"title": "First", "url": "/posts/first/", "date": "2024-01-01"},
+			{"title": "Second", "url": "/posts/second/"},
+			{"title": "Deep", "url": "/posts/2024/deep/"},
+			{"title": "Unfinished", "url": "/posts/unfinished/", "draft": true},
+			{"title": "About", "url": "/about/"}
+		]}`))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &SectionRequest{HugoSitePath: server.URL, Section: "posts", Depth: 2, Limit: 2})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, SourceSiteIndex, result.Get("metadata.source").String())
+	assert.Equal(t, `["/posts/first/","/posts/second/"]`, result.Get("pages.#.path").Raw)
+	assert.Equal(t, "2024-01-01", result.Get("pages.0.date").String())
+	assert.Equal(t, int64(3), result.Get("metadata.total_pages").Int())
+	assert.True(t, result.Get("metadata.has_more").Bool())
+	assert.Equal(t, int64(2), result.Get("metadata.next_page").Int())
+
+	resp, err = tool.Execute(context.Background(), &SectionRequest{HugoSitePath: server.URL, Section: "posts", Depth: 2, Limit: 2, Page: 2})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["/posts/2024/deep/"]`, result.Get("pages.#.path").Raw)
+	assert.False(t, result.Get("metadata.has_more").Bool())
+	assert.Equal(t, int64(1), result.Get("metadata.excluded_count").Int())
+
+	// Drafts are listed on request
+	req := &SectionRequest{HugoSitePath: server.URL, Section: "posts", PublishOptions: hugoindex.PublishOptions{IncludeDrafts: true}}
+	resp, err = tool.Execute(context.Background(), req)
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["First","Second","Unfinished"]`, result.Get("pages.#.title").Raw)
+
+	_, err = tool.Execute(context.Background(), &SectionRequest{HugoSitePath: server.URL, Section: "recipes"})
+	assert.Error(t, err)
+}
+
+func TestTool_Execute_DedupesAliases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[
+			{"title": "Foo", "url": "/posts/foo/"},
+			{"title": "Foo", "url": "/posts/foo/index.html"},
+			{"title": "Bar", "url": "/posts/bar"}
+		]`))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &SectionRequest{HugoSitePath: server.URL, Section: "posts"})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["/posts/foo/","/posts/bar/"]`, result.Get("pages.#.path").Raw)
+	assert.Equal(t, int64(1), result.Get("metadata.duplicates_removed").Int())
+}
+
+func TestTool_Execute_Cursor(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/posts/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		requests++
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write([]byte(`[{"title": "One", "url": "/posts/one/"}, {"title": "Two", "url": "/posts/two/"}, {"title": "Three", "url": "/posts/three/"}]`))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &SectionRequest{HugoSitePath: server.URL, Section: "posts", Limit: 2})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["One","Two"]`, result.Get("pages.#.title").Raw)
+	cursor := result.Get("metadata.next_cursor").String()
+	require.NotEmpty(t, cursor)
+
+	// The cursor resumes the listing without reading the index again
+	resp, err = tool.Execute(context.Background(), &SectionRequest{HugoSitePath: server.URL, Section: "posts", Limit: 2, Cursor: cursor})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["Three"]`, result.Get("pages.#.title").Raw)
+	assert.True(t, result.Get("metadata.resumed").Bool())
+	assert.False(t, result.Get("metadata.has_more").Bool())
+	assert.Equal(t, 1, requests)
+
+	// A cursor for a different depth is rejected
+	_, err = tool.Execute(context.Background(), &SectionRequest{HugoSitePath: server.URL, Section: "posts", Depth: 2, Cursor: cursor})
+	require.ErrorContains(t, err, "different request")
+}
@@ -0,0 +1,377 @@
+package series
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultTaxonomy is the taxonomy Hugo sites conventionally group series in
+const DefaultTaxonomy = "series"
+
+// orderFields are the page fields giving a page's place in its series, in
+// order of preference; pages without one are ordered by date
+var orderFields = []string{"series_weight", "seriesWeight", "part", "weight"}
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool lists the pages of a series in order, with the neighbors of a page.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// SeriesRequest represents the request parameters for the series tool.
+type SeriesRequest struct {
+	HugoSitePath string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	Series       string `json:"series,omitempty" jsonschema:"title=Series Name (e.g. 'Building a Blog')"`
+	Path         string `json:"path,omitempty" jsonschema:"title=Path of a page in the series, to return its previous and next pages"`
+	Taxonomy     string `json:"taxonomy,omitempty" jsonschema:"title=Taxonomy series are grouped in (default: series)"`
+
+	hugoindex.PublishOptions
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// Entry is a page of a series
+type Entry struct {
+	Position int    `json:"position"`
+	Title    string `json:"title"`
+	URL      string `json:"url,omitempty"`
+	Date     string `json:"date,omitempty"`
+
+	key      string
+	order    float64
+	hasOrder bool
+	date     time.Time
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_get_series"),
+		name:        "hugo_reader_get_series",
+		description: "Get the pages of a Hugo series in reading order, from the site index. Give a series name to list its parts, or a page path to also get the previous and next pages around it, so multi-part tutorials can be walked in order.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *SeriesRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+	r.Series = strings.TrimSpace(r.Series)
+	r.Path = strings.TrimSpace(r.Path)
+	if r.Series == "" && r.Path == "" {
+		return fmt.Errorf("series or path is required")
+	}
+	if r.Taxonomy = strings.TrimSpace(r.Taxonomy); r.Taxonomy == "" {
+		r.Taxonomy = DefaultTaxonomy
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// Execute lists the pages of a series.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	seriesRequest, ok := req.(*SeriesRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := seriesRequest.SiteOptions.Apply(ctx, t.sites, &seriesRequest.HugoSitePath, &seriesRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := seriesRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := seriesRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = seriesRequest.CacheOptions.Apply(ctx, 0)
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(seriesRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", seriesRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = seriesRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	index, err := hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Site index unavailable", "site", seriesRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.Code(err), "site index not available at Hugo site %s: %w", seriesRequest.HugoSitePath, err)
+	}
+
+	// A page's series is found from the page itself unless one is named
+	var current string
+	var pageSeries []string
+	if seriesRequest.Path != "" {
+		page, found, err := index.Page(ctx, seriesRequest.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read site index: %w", err)
+		}
+		if !found {
+			return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "page not found: %s", seriesRequest.Path)
+		}
+		current = hugoindex.CanonicalURL(siteURL, hugoindex.PageURL(page))
+		pageSeries = hugoindex.PageTaxonomies(page)[seriesRequest.Taxonomy]
+		if len(pageSeries) == 0 {
+			return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "page %s is not part of any %s", seriesRequest.Path, seriesRequest.Taxonomy)
+		}
+	}
+
+	name := seriesRequest.Series
+	if name == "" {
+		name = pageSeries[0]
+	} else if seriesRequest.Path != "" && !containsFold(pageSeries, name) {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "page %s is not part of %s '%s'", seriesRequest.Path, seriesRequest.Taxonomy, name)
+	}
+
+	entries, excluded, err := seriesEntries(ctx, index, siteURL, seriesRequest.Taxonomy, name, seriesRequest.PublishOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read site index: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "no pages in %s '%s' at Hugo site: %s", seriesRequest.Taxonomy, name, seriesRequest.HugoSitePath)
+	}
+
+	response := map[string]interface{}{
+		"success":  true,
+		"series":   name,
+		"taxonomy": seriesRequest.Taxonomy,
+		"pages":    entries,
+	}
+	if seriesRequest.Path != "" {
+		position := 0
+		for _, entry := range entries {
+			if current != "" && entry.key == current {
+				position = entry.Position
+				break
+			}
+		}
+		if position == 0 {
+			return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "page %s is excluded from %s '%s'", seriesRequest.Path, seriesRequest.Taxonomy, name)
+		}
+		response["current"] = entries[position-1]
+		response["previous"] = nil
+		response["next"] = nil
+		if position > 1 {
+			response["previous"] = entries[position-2]
+		}
+		if position < len(entries) {
+			response["next"] = entries[position]
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"source_endpoint": index.URL(),
+		"cached":          index.Cached(),
+		"page_count":      len(entries),
+		"excluded_count":  excluded,
+		"site_source":     sites.Source(ctx),
+	}
+	if len(pageSeries) > 1 {
+		metadata["page_series"] = pageSeries
+	}
+	response["metadata"] = metadata
+	response["errors"] = []string{}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal series", "error", err)
+		return nil, fmt.Errorf("failed to marshal series: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Retrieved series", "site", seriesRequest.HugoSitePath, "series", name, "pages", len(entries))
+	return tools.TextResponse(responseJSON), nil
+}
+
+// seriesEntries returns the published pages of a series in reading order,
+// once each however many aliases the index lists them under, with the
+// number of pages excluded
+func seriesEntries(ctx context.Context, index *hugoindex.SiteIndex, siteURL *url.URL, taxonomy, name string, publish hugoindex.PublishOptions) ([]*Entry, int, error) {
+	entries := []*Entry{}
+	seen := make(map[string]bool)
+	excluded := 0
+	now := time.Now()
+
+	err := index.Pages(ctx, func(page gjson.Result) bool {
+		if !containsFold(hugoindex.PageTaxonomies(page)[taxonomy], name) {
+			return true
+		}
+		entry := newEntry(page, siteURL)
+		if entry.key != "" {
+			if seen[entry.key] {
+				return true
+			}
+			seen[entry.key] = true
+		}
+		if !publish.Allows(page, now) {
+			excluded++
+			return true
+		}
+		entries = append(entries, entry)
+		return true
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sortEntries(entries)
+	for i, entry := range entries {
+		entry.Position = i + 1
+	}
+	return entries, excluded, nil
+}
+
+// newEntry describes a page of a series
+func newEntry(page gjson.Result, siteURL *url.URL) *Entry {
+	entry := &Entry{
+		Title: page.Get("title").String(),
+		URL:   hugoindex.PageURL(page),
+		Date:  page.Get("date").String(),
+	}
+	entry.key = hugoindex.CanonicalURL(siteURL, entry.URL)
+	entry.date, _ = hugoindex.ParseDate(entry.Date)
+	for _, field := range orderFields {
+		if value := page.Get(field); value.Type == gjson.Number {
+			entry.order, entry.hasOrder = value.Float(), true
+			break
+		}
+	}
+	return entry
+}
+
+// sortEntries orders entries by their place in the series, putting those
+// that give none last, then by date and title
+func sortEntries(entries []*Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.hasOrder != b.hasOrder {
+			return a.hasOrder
+		}
+		if a.order != b.order {
+			return a.order < b.order
+		}
+		if !a.date.Equal(b.date) {
+			return a.date.Before(b.date)
+		}
+		return a.Title < b.Title
+	})
+}
+
+// containsFold reports whether terms contains term, ignoring case
+func containsFold(terms []string, term string) bool {
+	for _, t := range terms {
+		if strings.EqualFold(t, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*SeriesRequest](registry, t, "Site exploration", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
@@ -0,0 +1,122 @@
+package series
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+const siteIndex = `{"pages": [
+	{"title": "Part 2", "url": "/blog/part-2/", "date": "2024-01-10", "series": ["Build a Blog"]},
+	{"title": "Part 1", "url": "/blog/part-1/", "date": "2024-01-20", "series": ["Build a Blog"], "series_weight": 1},
+	{"title": "Part 1 alias", "url": "/blog/part-1/index.html", "series": ["Build a Blog"]},
+	{"title": "Part 3", "url": "/blog/part-3/", "date": "2024-02-01", "series": "build a blog"},
+	{"title": "Part 4", "url": "/blog/part-4/", "date": "2024-03-01", "series": ["Build a Blog"], "draft": true},
+	{"title": "Other", "url": "/blog/other/", "date": "2024-01-01", "tags": ["go"]}
+]}`
+
+func newServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(siteIndex))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSeriesRequest_Validate(t *testing.T) {
+	req := &SeriesRequest{HugoSitePath: "https://example.com", Series: " Build a Blog "}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, "Build a Blog", req.Series)
+	assert.Equal(t, DefaultTaxonomy, req.Taxonomy)
+
+	assert.EqualError(t, (&SeriesRequest{Series: "a"}).Validate(), "hugo_site_path is required")
+	assert.EqualError(t, (&SeriesRequest{HugoSitePath: "https://example.com"}).Validate(), "series or path is required")
+}
+
+func TestSortEntries(t *testing.T) {
+	entries := []*Entry{
+		{Title: "B"},
+		{Title: "A"},
+		{Title: "Second", order: 2, hasOrder: true},
+		{Title: "First", order: 1, hasOrder: true},
+	}
+	sortEntries(entries)
+
+	var titles []string
+	for _, entry := range entries {
+		titles = append(titles, entry.Title)
+	}
+	assert.Equal(t, []string{"First", "Second", "A", "B"}, titles)
+}
+
+func TestTool_Execute(t *testing.T) {
+	server := newServer(t)
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &SeriesRequest{HugoSitePath: server.URL, Series: "build a blog"})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, `["Part 1","Part 2","Part 3"]`, result.Get("pages.#.title").Raw)
+	assert.Equal(t, `[1,2,3]`, result.Get("pages.#.position").Raw)
+	assert.Equal(t, int64(1), result.Get("metadata.excluded_count").Int())
+	assert.False(t, result.Get("current").Exists())
+
+	// A path finds its series and neighbors
+	resp, err = tool.Execute(context.Background(), &SeriesRequest{HugoSitePath: server.URL, Path: "/blog/part-2/"})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, "Build a Blog", result.Get("series").String())
+	assert.Equal(t, int64(2), result.Get("current.position").Int())
+	assert.Equal(t, "/blog/part-1/", result.Get("previous.url").String())
+	assert.Equal(t, "/blog/part-3/", result.Get("next.url").String())
+
+	resp, err = tool.Execute(context.Background(), &SeriesRequest{HugoSitePath: server.URL, Path: "blog/part-1"})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, "null", result.Get("previous").Raw)
+	assert.Equal(t, "Part 2", result.Get("next.title").String())
+
+	// Drafts join the series when requested
+	resp, err = tool.Execute(context.Background(), &SeriesRequest{HugoSitePath: server.URL, Path: "/blog/part-3/", PublishOptions: hugoindex.PublishOptions{IncludeDrafts: true}})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, "Part 4", result.Get("next.title").String())
+}
+
+func TestTool_Execute_NotFound(t *testing.T) {
+	server := newServer(t)
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		req  *SeriesRequest
+	}{
+		{name: "unknown series", req: &SeriesRequest{HugoSitePath: server.URL, Series: "Nope"}},
+		{name: "unknown page", req: &SeriesRequest{HugoSitePath: server.URL, Path: "/blog/missing/"}},
+		{name: "page without series", req: &SeriesRequest{HugoSitePath: server.URL, Path: "/blog/other/"}},
+		{name: "page in another series", req: &SeriesRequest{HugoSitePath: server.URL, Path: "/blog/part-1/", Series: "Nope"}},
+		{name: "draft page", req: &SeriesRequest{HugoSitePath: server.URL, Path: "/blog/part-4/"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tool.Execute(context.Background(), tt.req)
+			require.Error(t, err)
+			assert.Equal(t, toolerrors.ErrCodeNotFound, toolerrors.Code(err))
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package sites
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/searchbackend"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+)
+
+// Tool registers Hugo sites under aliases other tools accept as site
+type Tool struct {
+	log      *slog.Logger
+	registry *sites.Registry
+}
+
+// RegisterSiteRequest represents the request parameters for registering sites
+type RegisterSiteRequest struct {
+	Action    string            `json:"action,omitempty" jsonschema:"enum=register,enum=list,enum=remove,title=Action (default: register)"`
+	Alias     string            `json:"alias,omitempty" jsonschema:"title=Site Alias (e.g. blog)"`
+	URL       string            `json:"url,omitempty" jsonschema:"title=Site URL"`
+	Endpoints map[string]string `json:"endpoints,omitempty" jsonschema:"title=Custom Endpoint Paths (index and search)"`
+	Headers   map[string]string `json:"headers,omitempty" jsonschema:"title=Headers Sent With Every Request to the Site"`
+	Cookies   map[string]string `json:"cookies,omitempty" jsonschema:"title=Cookies Sent With Every Request to the Site"`
+
+	searchbackend.Config
+	httpclient.AuthOptions
+}
+
+// siteSummary is a registered site as reported to clients, without its
+// credentials or the values of its headers and cookies
+type siteSummary struct {
+	Alias     string            `json:"alias"`
+	URL       string            `json:"url"`
+	Endpoints map[string]string `json:"endpoints,omitempty"`
+	HasAuth   bool              `json:"has_auth"`
+	Headers   []string          `json:"headers,omitempty"`
+	Cookies   []string          `json:"cookies,omitempty"`
+
+	searchbackend.Config
+}
+
+// New creates a new site registration tool
+func New(registry *sites.Registry, opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		registry: registry,
+		log:      slog.Default().With("tool", "hugo_reader_register_site"),
+	}
+
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// ToolOption configures the site registration tool
+type ToolOption func(*Tool) error
+
+// WithLogger sets the logger for the tool
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", "hugo_reader_register_site")
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *RegisterSiteRequest) Validate() error {
+	switch r.Action {
+	case "":
+		r.Action = "register"
+		fallthrough
+	case "register":
+		if r.Alias == "" || r.URL == "" {
+			return fmt.Errorf("alias and url are required for the register action")
+		}
+	case "remove":
+		if r.Alias == "" {
+			return fmt.Errorf("alias is required for the remove action")
+		}
+	case "list":
+	default:
+		return fmt.Errorf("invalid action: %s (must be: register, list, or remove)", r.Action)
+	}
+
+	return r.AuthOptions.Validate()
+}
+
+// Execute registers, lists or removes sites
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	siteRequest, ok := req.(*RegisterSiteRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	if err := siteRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	var response map[string]interface{}
+	switch siteRequest.Action {
+	case "register":
+		site := sites.Site{
+			Alias:     siteRequest.Alias,
+			URL:       siteRequest.URL,
+			Auth:      siteRequest.Auth,
+			Endpoints: siteRequest.Endpoints,
+			Headers:   siteRequest.Headers,
+			Cookies:   siteRequest.Cookies,
+			Config:    siteRequest.Config,
+		}
+		if err := site.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid site: %w", err)
+		}
+		_, replaced := t.registry.Get(site.Alias)
+		if err := t.registry.Register(site); err != nil {
+			return nil, fmt.Errorf("invalid site: %w", err)
+		}
+		t.log.InfoContext(ctx, "Registered site", "alias", site.Alias, "url", site.URL, "replaced", replaced)
+
+		response = map[string]interface{}{
+			"success":  true,
+			"action":   "register",
+			"site":     summarize(site),
+			"replaced": replaced,
+			"message":  fmt.Sprintf("Pass site %q instead of hugo_site_path to use %s", site.Alias, site.URL),
+		}
+	case "list":
+		list := t.registry.List()
+		summaries := make([]siteSummary, 0, len(list))
+		for _, site := range list {
+			summaries = append(summaries, summarize(site))
+		}
+		response = map[string]interface{}{
+			"success": true,
+			"action":  "list",
+			"sites":   summaries,
+			"count":   len(summaries),
+		}
+	case "remove":
+		removed := t.registry.Remove(siteRequest.Alias)
+		t.log.InfoContext(ctx, "Removed site", "alias", siteRequest.Alias, "removed", removed)
+
+		response = map[string]interface{}{
+			"success": true,
+			"action":  "remove",
+			"alias":   siteRequest.Alias,
+			"removed": removed,
+		}
+	default:
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "unknown action: %s", siteRequest.Action)
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	return tools.TextResponse(responseJSON), nil
+}
+
+// summarize reports a site without its credentials
+func summarize(site sites.Site) siteSummary {
+	return siteSummary{
+		Alias:     site.Alias,
+		URL:       site.URL,
+		Endpoints: site.Endpoints,
+		HasAuth:   site.Auth != nil,
+		Headers:   sortedKeys(site.Headers),
+		Cookies:   sortedKeys(site.Cookies),
+		Config:    site.Config.Redacted(),
+	}
+}
+
+// sortedKeys returns the names of headers or cookies in order
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Name returns the tool name
+func (t *Tool) Name() string {
+	return "hugo_reader_register_site"
+}
+
+// Description returns the tool description
+func (t *Tool) Description() string {
+	return "Register a Hugo site under an alias such as 'blog', with optional auth, headers and cookies sent with every request to it, custom index or search endpoint paths, and the Algolia, Meilisearch or Typesense index its search uses, so other tools can be called with site: \"blog\" instead of hugo_site_path. Actions: 'register' (default), 'list' (registered sites, without credentials), 'remove'. Registrations last until the server stops."
+}
+
+// Register adds the tool to registry
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*RegisterSiteRequest](registry, t, "Session configuration", tools.Annotations{DestructiveHint: true, IdempotentHint: true})
+}
+
+// SetLogger sets the logger for the tool
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", "hugo_reader_register_site")
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", "hugo_reader_register_site")
+}
@@ -0,0 +1,104 @@
+package sites
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/searchbackend"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNew(t *testing.T) {
+	tool, err := New(sites.New())
+	require.NoError(t, err)
+	assert.Equal(t, "hugo_reader_register_site", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+}
+
+func TestRegisterSiteRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *RegisterSiteRequest
+		wantErr bool
+	}{
+		{name: "register by default", req: &RegisterSiteRequest{Alias: "blog", URL: "https://example.com"}},
+		{name: "list", req: &RegisterSiteRequest{Action: "list"}},
+		{name: "remove", req: &RegisterSiteRequest{Action: "remove", Alias: "blog"}},
+		{name: "register without url", req: &RegisterSiteRequest{Alias: "blog"}, wantErr: true},
+		{name: "remove without alias", req: &RegisterSiteRequest{Action: "remove"}, wantErr: true},
+		{name: "invalid action", req: &RegisterSiteRequest{Action: "rename"}, wantErr: true},
+		{name: "invalid auth", req: &RegisterSiteRequest{Alias: "blog", URL: "https://example.com", AuthOptions: httpclient.AuthOptions{Auth: &httpclient.Credentials{Password: "secret"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTool_Execute(t *testing.T) {
+	registry := sites.New()
+	tool, err := New(registry)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	resp, err := tool.Execute(ctx, &RegisterSiteRequest{
+		Alias:       "Blog",
+		URL:         "example.com",
+		Endpoints:   map[string]string{"index": "/api/pages.json"},
+		AuthOptions: httpclient.AuthOptions{Auth: &httpclient.Credentials{BearerToken: "secret"}},
+	})
+	require.NoError(t, err)
+	text := resp.Content[0].TextContent.Text
+	assert.Equal(t, "blog", gjson.Get(text, "site.alias").String())
+	assert.Equal(t, "https://example.com", gjson.Get(text, "site.url").String())
+	assert.True(t, gjson.Get(text, "site.has_auth").Bool())
+	assert.False(t, gjson.Get(text, "replaced").Bool())
+
+	site, ok := registry.Get("blog")
+	require.True(t, ok)
+	assert.Equal(t, "secret", site.Auth.BearerToken)
+
+	resp, err = tool.Execute(ctx, &RegisterSiteRequest{Alias: "blog", URL: "https://blog.example.com"})
+	require.NoError(t, err)
+	assert.True(t, gjson.Get(resp.Content[0].TextContent.Text, "replaced").Bool())
+
+	_, err = tool.Execute(ctx, &RegisterSiteRequest{Alias: "docs", URL: "ftp://example.com"})
+	assert.Error(t, err)
+
+	// Listing never reveals credentials
+	_, err = tool.Execute(ctx, &RegisterSiteRequest{
+		Alias:       "docs",
+		URL:         "https://docs.example.com",
+		Headers:     map[string]string{"X-Api-Key": "s3cret-key"},
+		Cookies:     map[string]string{"nf_jwt": "s3cret-cookie"},
+		Config:      searchbackend.Config{Algolia: &searchbackend.Algolia{AppID: "ABC123", SearchKey: "s3cret-algolia", IndexName: "docs"}},
+		AuthOptions: httpclient.AuthOptions{Auth: &httpclient.Credentials{Username: "user", Password: "hunter2"}},
+	})
+	require.NoError(t, err)
+	resp, err = tool.Execute(ctx, &RegisterSiteRequest{Action: "list"})
+	require.NoError(t, err)
+	text = resp.Content[0].TextContent.Text
+	assert.Equal(t, int64(2), gjson.Get(text, "count").Int())
+	assert.Equal(t, `["blog","docs"]`, gjson.Get(text, "sites.#.alias").Raw)
+	assert.NotContains(t, text, "hunter2")
+	assert.Equal(t, `["X-Api-Key"]`, gjson.Get(text, "sites.1.headers").Raw)
+	assert.Equal(t, `["nf_jwt"]`, gjson.Get(text, "sites.1.cookies").Raw)
+	assert.Equal(t, `{"app_id":"ABC123","index":"docs"}`, gjson.Get(text, "sites.1.algolia").Raw)
+	assert.NotContains(t, text, "s3cret")
+
+	resp, err = tool.Execute(ctx, &RegisterSiteRequest{Action: "remove", Alias: "docs"})
+	require.NoError(t, err)
+	assert.True(t, gjson.Get(resp.Content[0].TextContent.Text, "removed").Bool())
+	assert.Len(t, registry.List(), 1)
+}
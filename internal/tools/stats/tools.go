@@ -0,0 +1,401 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/enrich"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/tidwall/gjson"
+)
+
+// dateFields are the page fields a page's publication date is read from, in
+// order of preference
+var dateFields = []string{"date", "publishDate", "publishdate"}
+
+// summaryFields are the page fields that hold a page's summary
+var summaryFields = []string{"summary", "Summary"}
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool reports an inventory of the content of a Hugo site.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// StatsRequest represents the request parameters for the site statistics
+// tool.
+type StatsRequest struct {
+	HugoSitePath     string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	TermsPerTaxonomy int    `json:"terms_per_taxonomy,omitempty" jsonschema:"title=Most used terms listed per taxonomy (default: 25),minimum=1,maximum=1000"`
+
+	hugoindex.PublishOptions
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// TermCount is the number of pages assigned a term
+type TermCount struct {
+	Term  string `json:"term"`
+	Count int    `json:"count"`
+}
+
+// TaxonomyStats summarizes the terms of a taxonomy
+type TaxonomyStats struct {
+	TermCount  int         `json:"term_count"`
+	PageCount  int         `json:"page_count"`
+	Terms      []TermCount `json:"terms"`
+	Truncated  bool        `json:"truncated"`
+	termCounts map[string]int
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_site_stats"),
+		name:        "hugo_reader_site_stats",
+		description: "Get a content inventory of a Hugo site in one call: total pages, pages per section and per taxonomy term, the date range of the content, average word count and the share of pages with summaries, computed from the site index.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *StatsRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+	if r.TermsPerTaxonomy == 0 {
+		r.TermsPerTaxonomy = 25
+	} else if r.TermsPerTaxonomy < 1 || r.TermsPerTaxonomy > 1000 {
+		return fmt.Errorf("terms_per_taxonomy must be between 1 and 1000")
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// Execute reports the content inventory of a Hugo site.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	statsRequest, ok := req.(*StatsRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := statsRequest.SiteOptions.Apply(ctx, t.sites, &statsRequest.HugoSitePath, &statsRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := statsRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := statsRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = statsRequest.CacheOptions.Apply(ctx, 0)
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(statsRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", statsRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = statsRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	index, err := hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Site index unavailable", "site", statsRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.Code(err), "site index not available at Hugo site %s: %w", statsRequest.HugoSitePath, err)
+	}
+
+	inv := newInventory(siteURL, statsRequest.PublishOptions)
+	if err := index.Pages(ctx, func(page gjson.Result) bool {
+		inv.add(page)
+		return true
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read site index: %w", err)
+	}
+
+	response := map[string]interface{}{
+		"success":    true,
+		"stats":      inv.report(),
+		"sections":   inv.sections,
+		"taxonomies": inv.taxonomyStats(statsRequest.TermsPerTaxonomy),
+		"metadata": map[string]interface{}{
+			"source_endpoint":    index.URL(),
+			"cached":             index.Cached(),
+			"streamed":           index.Streamed(),
+			"excluded_count":     inv.excluded,
+			"duplicates_removed": inv.duplicates,
+			"site_source":        sites.Source(ctx),
+		},
+		"errors": []string{},
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal site statistics", "error", err)
+		return nil, fmt.Errorf("failed to marshal site statistics: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Computed site statistics", "site", statsRequest.HugoSitePath, "pages", inv.pages)
+	return tools.TextResponse(responseJSON), nil
+}
+
+// inventory tallies the published pages of a site, once each however many
+// aliases the index lists them under
+type inventory struct {
+	siteURL *url.URL
+	publish hugoindex.PublishOptions
+	now     time.Time
+	seen    map[string]bool
+
+	pages         int
+	excluded      int
+	duplicates    int
+	sections      map[string]int
+	unsectioned   int
+	taxonomies    map[string]*TaxonomyStats
+	untagged      int
+	dated         int
+	first, last   time.Time
+	counted       int
+	words         int
+	withSummaries int
+}
+
+func newInventory(siteURL *url.URL, publish hugoindex.PublishOptions) *inventory {
+	return &inventory{
+		siteURL:    siteURL,
+		publish:    publish,
+		now:        time.Now(),
+		seen:       make(map[string]bool),
+		sections:   make(map[string]int),
+		taxonomies: make(map[string]*TaxonomyStats),
+	}
+}
+
+// add counts page
+func (inv *inventory) add(page gjson.Result) {
+	if key := hugoindex.CanonicalURL(inv.siteURL, hugoindex.PageURL(page)); key != "" {
+		if inv.seen[key] {
+			inv.duplicates++
+			return
+		}
+		inv.seen[key] = true
+	}
+	if !inv.publish.Allows(page, inv.now) {
+		inv.excluded++
+		return
+	}
+	inv.pages++
+
+	if section := hugoindex.PageSection(page); section != "" {
+		inv.sections[section]++
+	} else {
+		inv.unsectioned++
+	}
+
+	taxonomies := hugoindex.PageTaxonomies(page)
+	if len(taxonomies) == 0 {
+		inv.untagged++
+	}
+	for taxonomy, terms := range taxonomies {
+		stats, ok := inv.taxonomies[taxonomy]
+		if !ok {
+			stats = &TaxonomyStats{termCounts: make(map[string]int)}
+			inv.taxonomies[taxonomy] = stats
+		}
+		stats.PageCount++
+		seen := make(map[string]bool, len(terms))
+		for _, term := range terms {
+			if !seen[term] {
+				seen[term] = true
+				stats.termCounts[term]++
+			}
+		}
+	}
+
+	for _, field := range dateFields {
+		if date, ok := hugoindex.ParseDate(page.Get(field).String()); ok {
+			inv.dated++
+			if inv.first.IsZero() || date.Before(inv.first) {
+				inv.first = date
+			}
+			if date.After(inv.last) {
+				inv.last = date
+			}
+			break
+		}
+	}
+
+	if words, ok := enrich.WordCount(page); ok {
+		inv.counted++
+		inv.words += words
+	}
+
+	for _, field := range summaryFields {
+		if strings.TrimSpace(page.Get(field).String()) != "" {
+			inv.withSummaries++
+			break
+		}
+	}
+}
+
+// report returns the totals of the inventory
+func (inv *inventory) report() map[string]interface{} {
+	report := map[string]interface{}{
+		"total_pages":              inv.pages,
+		"section_count":            len(inv.sections),
+		"pages_without_section":    inv.unsectioned,
+		"taxonomy_count":           len(inv.taxonomies),
+		"pages_without_taxonomies": inv.untagged,
+		"dated_pages":              inv.dated,
+		"pages_with_word_count":    inv.counted,
+		"pages_with_summary":       inv.withSummaries,
+		"summary_percentage":       percentage(inv.withSummaries, inv.pages),
+	}
+	if inv.dated > 0 {
+		report["date_range"] = map[string]string{
+			"first": inv.first.UTC().Format(time.RFC3339),
+			"last":  inv.last.UTC().Format(time.RFC3339),
+		}
+	}
+	if inv.counted > 0 {
+		report["total_words"] = inv.words
+		report["average_word_count"] = inv.words / inv.counted
+		report["average_reading_time_minutes"] = enrich.ReadingTime(inv.words / inv.counted)
+	}
+	return report
+}
+
+// taxonomyStats returns the statistics of each taxonomy, listing up to
+// limit of its most used terms
+func (inv *inventory) taxonomyStats(limit int) map[string]*TaxonomyStats {
+	for _, stats := range inv.taxonomies {
+		stats.TermCount = len(stats.termCounts)
+		stats.Terms = make([]TermCount, 0, len(stats.termCounts))
+		for term, count := range stats.termCounts {
+			stats.Terms = append(stats.Terms, TermCount{Term: term, Count: count})
+		}
+		sort.Slice(stats.Terms, func(i, j int) bool {
+			if stats.Terms[i].Count != stats.Terms[j].Count {
+				return stats.Terms[i].Count > stats.Terms[j].Count
+			}
+			return stats.Terms[i].Term < stats.Terms[j].Term
+		})
+		if len(stats.Terms) > limit {
+			stats.Terms = stats.Terms[:limit]
+			stats.Truncated = true
+		}
+	}
+	return inv.taxonomies
+}
+
+// percentage returns part as a percentage of total, to one decimal place
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*1000/float64(total)) / 10
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*StatsRequest](registry, t, "Site exploration", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
@@ -0,0 +1,80 @@
+package stats
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+const siteIndex = `{"pages": [
+	{"title": "A", "url": "/posts/a/", "date": "2024-01-05", "wordcount": 300, "summary": "About A", "tags": ["go", "hugo"]},
+	{"title": "A alias", "url": "/posts/a/index.html", "date": "2024-01-05", "wordcount": 300, "tags": ["go"]},
+	{"title": "B", "url": "/posts/b/", "date": "2024-03-01", "content": "one two three four five", "tags": ["go", "go"], "categories": "dev"},
+	{"title": "C", "url": "/notes/c/", "publishDate": "2023-06-01", "summary": " "},
+	{"title": "Home", "url": "/"},
+	{"title": "Draft", "url": "/posts/d/", "date": "2025-01-01", "draft": true, "tags": ["rust"]}
+]}`
+
+func TestStatsRequest_Validate(t *testing.T) {
+	req := &StatsRequest{HugoSitePath: "https://example.com"}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, 25, req.TermsPerTaxonomy)
+
+	assert.EqualError(t, (&StatsRequest{}).Validate(), "hugo_site_path is required")
+	assert.EqualError(t, (&StatsRequest{HugoSitePath: "https://example.com", TermsPerTaxonomy: 1001}).Validate(), "terms_per_taxonomy must be between 1 and 1000")
+}
+
+func TestPercentage(t *testing.T) {
+	assert.Equal(t, 0.0, percentage(1, 0))
+	assert.Equal(t, 33.3, percentage(1, 3))
+	assert.Equal(t, 100.0, percentage(4, 4))
+}
+
+func TestTool_Execute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(siteIndex))
+	}))
+	defer server.Close()
+
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &StatsRequest{HugoSitePath: server.URL, TermsPerTaxonomy: 1})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+
+	stats := result.Get("stats")
+	assert.Equal(t, int64(4), stats.Get("total_pages").Int())
+	assert.Equal(t, int64(1), stats.Get("pages_without_section").Int())
+	assert.Equal(t, int64(2), stats.Get("pages_without_taxonomies").Int())
+	assert.Equal(t, int64(3), stats.Get("dated_pages").Int())
+	assert.Equal(t, "2023-06-01T00:00:00Z", stats.Get("date_range.first").String())
+	assert.Equal(t, "2024-03-01T00:00:00Z", stats.Get("date_range.last").String())
+	assert.Equal(t, int64(2), stats.Get("pages_with_word_count").Int())
+	assert.Equal(t, int64(152), stats.Get("average_word_count").Int())
+	assert.Equal(t, int64(1), stats.Get("pages_with_summary").Int())
+	assert.Equal(t, 25.0, stats.Get("summary_percentage").Float())
+
+	assert.Equal(t, int64(2), result.Get("sections.posts").Int())
+	assert.Equal(t, int64(1), result.Get("sections.notes").Int())
+
+	tags := result.Get("taxonomies.tags")
+	assert.Equal(t, int64(2), tags.Get("term_count").Int())
+	assert.Equal(t, int64(2), tags.Get("page_count").Int())
+	assert.Equal(t, `[{"term":"go","count":2}]`, tags.Get("terms").Raw)
+	assert.True(t, tags.Get("truncated").Bool())
+	assert.Equal(t, int64(1), result.Get("taxonomies.categories.page_count").Int())
+
+	assert.Equal(t, int64(1), result.Get("metadata.excluded_count").Int())
+	assert.Equal(t, int64(1), result.Get("metadata.duplicates_removed").Int())
+}
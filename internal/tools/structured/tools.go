@@ -0,0 +1,378 @@
+package structured
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/pagelinks"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/tidwall/gjson"
+)
+
+// Kinds of structured data
+const (
+	KindJSONLD    = "json_ld"
+	KindOpenGraph = "opengraph"
+	KindTwitter   = "twitter"
+	KindMicrodata = "microdata"
+)
+
+// kinds are every kind of structured data, in response order
+var kinds = []string{KindJSONLD, KindOpenGraph, KindTwitter, KindMicrodata}
+
+// maxInvalidExcerpt bounds the source of an invalid JSON-LD block quoted in
+// an error
+const maxInvalidExcerpt = 100
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool extracts the structured data embedded in a page of a Hugo site.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+}
+
+// StructuredDataRequest represents the request parameters for extracting
+// structured data.
+type StructuredDataRequest struct {
+	HugoSitePath string   `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	Path         string   `json:"path" jsonschema:"title=Page Path (e.g. /posts/my-post/)"`
+	Include      []string `json:"include,omitempty" jsonschema:"title=Kinds of structured data to return (default: all),enum=json_ld,enum=opengraph,enum=twitter,enum=microdata"`
+
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_get_structured_data"),
+		name:        "hugo_reader_get_structured_data",
+		description: "Extract the structured data a page of a Hugo site embeds in its HTML: parsed JSON-LD blocks with their schema.org types, Open Graph and Twitter card properties, and microdata items. Reads the rendered page, so it works for sites whose JSON outputs are sparse but whose templates describe pages with schema.org data. Use it for a page's author, dates, images, breadcrumbs or product, recipe and event details.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *StructuredDataRequest) Validate() error {
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+	if r.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if u, err := url.Parse(r.Path); err != nil || u.IsAbs() || u.Host != "" {
+		return fmt.Errorf("invalid path: %s (must be relative to the site)", r.Path)
+	}
+	for _, part := range strings.Split(r.Path, "/") {
+		if part == ".." {
+			return fmt.Errorf("invalid path: %s", r.Path)
+		}
+	}
+
+	for _, kind := range r.Include {
+		switch kind {
+		case KindJSONLD, KindOpenGraph, KindTwitter, KindMicrodata:
+		default:
+			return fmt.Errorf("invalid include: %s (must be: json_ld, opengraph, twitter, or microdata)", kind)
+		}
+	}
+	if len(r.Include) == 0 {
+		r.Include = kinds
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
+}
+
+// includes reports whether the request asks for a kind of structured data
+func (r *StructuredDataRequest) includes(kind string) bool {
+	for _, included := range r.Include {
+		if included == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Execute extracts the structured data of a page.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	dataRequest, ok := req.(*StructuredDataRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := dataRequest.SiteOptions.Apply(ctx, t.sites, &dataRequest.HugoSitePath, &dataRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := dataRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := dataRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = dataRequest.CacheOptions.Apply(ctx, 0)
+
+	// Parse and validate the Hugo site URL
+	siteURL, err := url.Parse(dataRequest.HugoSitePath)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", dataRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
+	}
+
+	// Ensure URL has scheme
+	if siteURL.Scheme == "" {
+		siteURL.Scheme = "https"
+	}
+
+	// Send credentials, if any, only to the site itself
+	ctx = dataRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	// Structured data is in the head of the rendered page, which the site
+	// index never holds
+	pageURL := pagelinks.PageURL(siteURL, dataRequest.Path)
+	result, err := t.cache.Fetch(ctx, t.httpClient, t.cache.BuildKey(siteURL.String(), pageURL.Path, nil), pageURL.String(), func(data []byte) bool {
+		return htmltext.LooksLikeHTML(string(data))
+	})
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to fetch page", "site", dataRequest.HugoSitePath, "path", dataRequest.Path, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.Code(err), "failed to fetch page '%s' of Hugo site %s: %w", dataRequest.Path, dataRequest.HugoSitePath, err)
+	}
+
+	data := htmltext.ParseStructuredData(string(result.Data))
+	counts := map[string]int{}
+	errs := []string{}
+	response := map[string]interface{}{
+		"success": true,
+		"path":    dataRequest.Path,
+		"url":     pageURL.String(),
+	}
+	if dataRequest.includes(KindJSONLD) {
+		blocks := data.JSONLD
+		if blocks == nil {
+			blocks = []json.RawMessage{}
+		}
+		response[KindJSONLD] = blocks
+		response["types"] = jsonLDTypes(blocks)
+		counts[KindJSONLD] = len(blocks)
+		for _, source := range data.InvalidJSONLD {
+			errs = append(errs, fmt.Sprintf("invalid JSON-LD block skipped: %s", excerpt(source)))
+		}
+	}
+	if dataRequest.includes(KindOpenGraph) {
+		response[KindOpenGraph] = compact(data.OpenGraph)
+		counts[KindOpenGraph] = len(data.OpenGraph)
+	}
+	if dataRequest.includes(KindTwitter) {
+		response[KindTwitter] = compact(data.Twitter)
+		counts[KindTwitter] = len(data.Twitter)
+	}
+	if dataRequest.includes(KindMicrodata) {
+		items := []interface{}{}
+		for _, item := range data.Microdata {
+			items = append(items, microdataJSON(item))
+		}
+		response[KindMicrodata] = items
+		counts[KindMicrodata] = len(items)
+	}
+	response["metadata"] = map[string]interface{}{
+		"source_endpoint": pageURL.String(),
+		"cached":          result.Cached,
+		"counts":          counts,
+		"site_source":     sites.Source(ctx),
+	}
+	response["errors"] = errs
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal structured data", "error", err)
+		return nil, fmt.Errorf("failed to marshal structured data: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Extracted structured data", "site", dataRequest.HugoSitePath, "path", dataRequest.Path, "counts", counts)
+	return tools.TextResponse(responseJSON), nil
+}
+
+// jsonLDTypes returns the distinct schema.org types of the JSON-LD blocks
+// and the nodes of their @graph, sorted
+func jsonLDTypes(blocks []json.RawMessage) []string {
+	seen := map[string]bool{}
+	var collect func(node gjson.Result)
+	collect = func(node gjson.Result) {
+		if node.IsArray() {
+			for _, child := range node.Array() {
+				collect(child)
+			}
+			return
+		}
+		types := node.Get("@type")
+		if types.IsArray() {
+			for _, t := range types.Array() {
+				seen[t.String()] = true
+			}
+		} else if types.String() != "" {
+			seen[types.String()] = true
+		}
+		if graph := node.Get("@graph"); graph.Exists() {
+			collect(graph)
+		}
+	}
+	for _, block := range blocks {
+		collect(gjson.ParseBytes(block))
+	}
+
+	result := make([]string, 0, len(seen))
+	for t := range seen {
+		result = append(result, t)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// compact returns properties with single values as strings and repeated
+// ones as arrays
+func compact(properties map[string][]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(properties))
+	for name, values := range properties {
+		if len(values) == 1 {
+			result[name] = values[0]
+		} else {
+			result[name] = values
+		}
+	}
+	return result
+}
+
+// microdataJSON returns a microdata item in the shape of its JSON
+// representation, with single property values compacted as compact does
+func microdataJSON(item htmltext.MicrodataItem) map[string]interface{} {
+	properties := make(map[string]interface{}, len(item.Properties))
+	for name, values := range item.Properties {
+		converted := make([]interface{}, len(values))
+		for i, value := range values {
+			if nested, ok := value.(htmltext.MicrodataItem); ok {
+				converted[i] = microdataJSON(nested)
+			} else {
+				converted[i] = value
+			}
+		}
+		if len(converted) == 1 {
+			properties[name] = converted[0]
+		} else {
+			properties[name] = converted
+		}
+	}
+
+	result := map[string]interface{}{"properties": properties}
+	if len(item.Type) == 1 {
+		result["type"] = item.Type[0]
+	} else if len(item.Type) > 1 {
+		result["type"] = item.Type
+	}
+	if item.ID != "" {
+		result["id"] = item.ID
+	}
+	return result
+}
+
+// excerpt returns the start of source, on one line
+func excerpt(source string) string {
+	runes := []rune(strings.Join(strings.Fields(source), " "))
+	if len(runes) > maxInvalidExcerpt {
+		return string(runes[:maxInvalidExcerpt]) + "..."
+	}
+	return string(runes)
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*StructuredDataRequest](registry, t, "Content retrieval", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
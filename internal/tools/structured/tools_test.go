@@ -0,0 +1,113 @@
+package structured
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+const postHTML = `<!DOCTYPE html>
+<html><head>
+<title>Pancakes</title>
+<meta property="og:title" content="Pancakes">
+<meta property="og:image" content="https://example.com/a.png">
+<meta property="og:image" content="https://example.com/b.png">
+<meta name="twitter:card" content="summary">
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@graph": [
+  {"@type": "BlogPosting", "headline": "Pancakes"},
+  {"@type": ["BreadcrumbList", "ItemList"]}
+]}
+</script>
+<script type="application/ld+json">{"@type": </script>
+</head><body><main>
+<div itemscope itemtype="https://schema.org/Recipe">
+  <span itemprop="name">Pancakes</span>
+  <span itemprop="recipeIngredient">Flour</span>
+  <span itemprop="recipeIngredient">Milk</span>
+</div>
+</main></body></html>`
+
+func TestNew(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+	assert.Equal(t, "hugo_reader_get_structured_data", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+}
+
+func TestStructuredDataRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *StructuredDataRequest
+		wantErr bool
+	}{
+		{name: "valid", req: &StructuredDataRequest{HugoSitePath: "https://example.com", Path: "/posts/pancakes/"}},
+		{name: "include", req: &StructuredDataRequest{HugoSitePath: "https://example.com", Path: "/", Include: []string{KindJSONLD}}},
+		{name: "missing path", req: &StructuredDataRequest{HugoSitePath: "https://example.com"}, wantErr: true},
+		{name: "absolute URL path", req: &StructuredDataRequest{HugoSitePath: "https://example.com", Path: "https://other.example/"}, wantErr: true},
+		{name: "path escaping the site", req: &StructuredDataRequest{HugoSitePath: "https://example.com", Path: "/../etc/"}, wantErr: true},
+		{name: "invalid include", req: &StructuredDataRequest{HugoSitePath: "https://example.com", Path: "/", Include: []string{"rdfa"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, tt.req.Include)
+			}
+		})
+	}
+}
+
+func TestTool_Execute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/posts/pancakes/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(postHTML))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &StructuredDataRequest{HugoSitePath: server.URL, Path: "posts/pancakes"})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+
+	assert.True(t, result.Get("success").Bool())
+	assert.Equal(t, server.URL+"/posts/pancakes/", result.Get("url").String())
+	assert.Equal(t, "BlogPosting", result.Get("json_ld.0.@graph.0.@type").String())
+	assert.Equal(t, `["BlogPosting","BreadcrumbList","ItemList"]`, result.Get("types").Raw)
+	assert.Equal(t, "Pancakes", result.Get("opengraph.og:title").String())
+	assert.Equal(t, int64(2), result.Get("opengraph.og:image.#").Int())
+	assert.Equal(t, "summary", result.Get("twitter.twitter:card").String())
+	assert.Equal(t, "https://schema.org/Recipe", result.Get("microdata.0.type").String())
+	assert.Equal(t, "Pancakes", result.Get("microdata.0.properties.name").String())
+	assert.Equal(t, `["Flour","Milk"]`, result.Get("microdata.0.properties.recipeIngredient").Raw)
+	assert.Equal(t, int64(1), result.Get("metadata.counts.json_ld").Int())
+	assert.Equal(t, int64(1), result.Get("errors.#").Int(), "the invalid block is reported")
+
+	resp, err = tool.Execute(context.Background(), &StructuredDataRequest{HugoSitePath: server.URL, Path: "/posts/pancakes/", Include: []string{KindOpenGraph}})
+	require.NoError(t, err)
+	var only map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal([]byte(resp.Content[0].TextContent.Text), &only))
+	assert.Contains(t, only, KindOpenGraph)
+	assert.NotContains(t, only, KindJSONLD)
+	assert.NotContains(t, only, KindMicrodata)
+
+	_, err = tool.Execute(context.Background(), &StructuredDataRequest{HugoSitePath: server.URL, Path: "/missing/"})
+	require.Error(t, err)
+	assert.Equal(t, toolerrors.ErrCodeNotFound, toolerrors.Code(err))
+}
@@ -1,20 +1,29 @@
 package taxonomies
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
 	mcp_golang "github.com/metoro-io/mcp-golang"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
 	"github.com/tidwall/gjson"
 )
 
+// DefaultCandidates are the taxonomies probed for at their own endpoints
+// when a site publishes no list of its taxonomies
+var DefaultCandidates = []string{"categories", "tags", "themes", "methods", "authors", "series", "topics"}
+
 // ToolOption is a function that configures a Tool.
 type ToolOption func(*Tool) error
 
@@ -23,24 +32,38 @@ type Tool struct {
 	log         *slog.Logger
 	name        string
 	description string
-	httpClient  *http.Client
+	httpClient  *httpclient.Client
 	cache       *cache.Cache
+	ttl         time.Duration
+	sites       *sites.Registry
+	candidates  []string
 }
 
 // TaxonomiesRequest represents the request parameters for the taxonomies tool.
 type TaxonomiesRequest struct {
-	HugoSitePath string `json:"hugo_site_path" jsonschema:"title=Hugo Site Path"`
+	HugoSitePath string   `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
+	Candidates   []string `json:"candidates,omitempty" jsonschema:"title=Taxonomy names to probe for (default: the server's list)"`
+
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
 }
 
+// DefaultCacheTTL is how long taxonomy listings are served from the cache
+const DefaultCacheTTL = 5 * time.Minute
+
 // New creates a new Tool.
 func New(opts ...ToolOption) (*Tool, error) {
 	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_get_taxonomies"),
 		name:        "hugo_reader_get_taxonomies",
 		description: "Get all taxonomies defined in a Hugo site (e.g., categories, tags, authors). Returns the taxonomy names and their configuration. Use this first to understand the site's content organization.",
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		cache: cache.New(cache.WithTTL(5 * time.Minute)),
+		httpClient: httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache: cache.New(cache.WithTTL(DefaultCacheTTL)),
+		ttl:   DefaultCacheTTL,
+		sites: sites.New(),
+		candidates: DefaultCandidates,
 	}
 	for _, opt := range opts {
 		if err := opt(tool); err != nil {
@@ -55,9 +78,6 @@ func New(opts ...ToolOption) (*Tool, error) {
 func WithLogger(logger *slog.Logger) ToolOption {
 	return func(t *Tool) error {
 		t.log = logger.With("tool", t.name)
-		if t.cache != nil {
-			t.cache = cache.New(cache.WithLogger(logger), cache.WithTTL(5*time.Minute))
-		}
 		return nil
 	}
 }
@@ -70,36 +90,96 @@ func WithCache(c *cache.Cache) ToolOption {
 	}
 }
 
+// WithTTL sets how long the Tool serves cached responses. Zero uses the
+// cache's default TTL.
+func WithTTL(ttl time.Duration) ToolOption {
+	return func(t *Tool) error {
+		t.ttl = ttl
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// WithCandidates sets the taxonomies probed for when a request names none.
+func WithCandidates(names []string) ToolOption {
+	return func(t *Tool) error {
+		if len(names) > 0 {
+			t.candidates = names
+		}
+		return nil
+	}
+}
+
+// Candidates parses a comma-separated list of taxonomy names
+func Candidates(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
+
 // Validate implements tools.Request
 func (r *TaxonomiesRequest) Validate() error {
 	if r.HugoSitePath == "" {
 		return &ErrHugoSitePathRequired{}
 	}
-	return nil
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
+	}
+	for _, name := range r.Candidates {
+		if name == "" || strings.ContainsAny(name, "/?#") {
+			return fmt.Errorf("invalid taxonomy candidate %q", name)
+		}
+	}
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
 }
 
 // Execute retrieves taxonomies from a Hugo site.
-func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
-	// Check if logger is initialized
-	if t.log == nil {
-		// Default to standard logger if not set
-		t.log = slog.Default().With("tool", t.name)
-	}
-
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
 	taxonomiesRequest, ok := req.(*TaxonomiesRequest)
 	if !ok {
-		return nil, &ErrInvalidRequest{Err: fmt.Errorf("invalid request type: %T", req)}
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := taxonomiesRequest.SiteOptions.Apply(ctx, t.sites, &taxonomiesRequest.HugoSitePath, &taxonomiesRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
 	}
 
 	if err := taxonomiesRequest.Validate(); err != nil {
-		return nil, err
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
 	}
 
+	ctx, cancel := taxonomiesRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = taxonomiesRequest.CacheOptions.Apply(ctx, t.ttl)
+
 	// Parse and validate the Hugo site URL
 	siteURL, err := url.Parse(taxonomiesRequest.HugoSitePath)
 	if err != nil {
-		t.log.Error("Invalid Hugo site URL", "url", taxonomiesRequest.HugoSitePath, "error", err)
-		return nil, &ErrInvalidRequest{Err: fmt.Errorf("invalid Hugo site URL: %w", err)}
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", taxonomiesRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
 	}
 
 	// Ensure URL has scheme
@@ -107,123 +187,91 @@ func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
 		siteURL.Scheme = "https"
 	}
 
+	// Send credentials, if any, only to the site itself
+	ctx = taxonomiesRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
+	candidates := t.candidates
+	if len(taxonomiesRequest.Candidates) > 0 {
+		candidates = taxonomiesRequest.Candidates
+	}
+
 	// Try common Hugo taxonomy endpoints with caching
 	taxonomyEndpoints := []EndpointConfig{
-		{path: "/taxonomies/index.json", validator: validateTaxonomyStructure},
-		{path: "/index.json", validator: validateHugoIndex},
-		{path: "/api/taxonomies.json", validator: validateTaxonomyStructure},
+		{path: "/taxonomies/index.json", validator: validateTaxonomyStructure(candidates)},
+		{path: "/api/taxonomies.json", validator: validateTaxonomyStructure(candidates)},
 	}
 	
 	// Try individual taxonomy endpoints to discover what's available
-	individualTaxonomyEndpoints := []string{
-		"/categories/index.json",
-		"/tags/index.json", 
-		"/themes/index.json",
-		"/methods/index.json",
-		"/authors/index.json",
-		"/series/index.json",
-		"/topics/index.json",
+	individualTaxonomyEndpoints := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		individualTaxonomyEndpoints[i] = "/" + candidate + "/index.json"
 	}
 
 	var taxonomiesData []byte
+	var taxonomies map[string]string
+	var inferred []string
 	var found bool
 	var usedEndpoint string
 
 	for _, endpointConfig := range taxonomyEndpoints {
+		if err := ctx.Err(); err != nil {
+			t.log.WarnContext(ctx, "Taxonomies retrieval cancelled", "site", taxonomiesRequest.HugoSitePath, "error", err)
+			return nil, fmt.Errorf("taxonomies retrieval cancelled: %w", err)
+		}
+
 		taxonomyURL := siteURL.ResolveReference(&url.URL{Path: endpointConfig.path})
 		cacheKey := t.cache.BuildKey(siteURL.String(), endpointConfig.path, nil)
 		
-		t.log.Debug("Trying taxonomy endpoint", "url", taxonomyURL.String(), "cache_key", cacheKey)
-
-		// Check cache first
-		if cachedData, hit := t.cache.Get(cacheKey); hit {
-			t.log.Debug("Cache hit for endpoint", "url", taxonomyURL.String())
-			if endpointConfig.validator(cachedData) {
-				taxonomiesData = cachedData
-				found = true
-				usedEndpoint = taxonomyURL.String()
-				break
-			} else {
-				t.log.Debug("Cached data failed validation, invalidating", "url", taxonomyURL.String())
-				t.cache.Delete(cacheKey)
-			}
-		}
+		t.log.DebugContext(ctx, "Trying taxonomy endpoint", "url", taxonomyURL.String(), "cache_key", cacheKey)
 
-		// Fetch from network
-		resp, err := t.httpClient.Get(taxonomyURL.String())
+		// Serve from cache, revalidating expired entries
+		result, err := t.cache.FetchEndpoint(ctx, t.httpClient, siteURL.String(), endpointConfig.path, cacheKey, taxonomyURL.String(), endpointConfig.validator)
 		if err != nil {
-			t.log.Debug("Failed to fetch endpoint", "url", taxonomyURL.String(), "error", err)
+			t.log.DebugContext(ctx, "Taxonomy endpoint unavailable", "url", taxonomyURL.String(), "error", err)
 			continue
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode == http.StatusOK {
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				t.log.Debug("Failed to read response body", "url", taxonomyURL.String(), "error", err)
-				continue
-			}
+		taxonomiesData = result.Data
+		found = true
+		usedEndpoint = taxonomyURL.String()
+		t.log.InfoContext(ctx, "Found taxonomies", "url", taxonomyURL.String(), "cached", result.Cached, "revalidated", result.Revalidated)
+		break
+	}
 
-			// Validate response contains taxonomy data
-			if endpointConfig.validator(body) {
-				// Cache the validated response
-				etag := resp.Header.Get("ETag")
-				lastModified := resp.Header.Get("Last-Modified")
-				t.cache.Set(cacheKey, body, etag, lastModified)
-				
-				taxonomiesData = body
-				found = true
-				usedEndpoint = taxonomyURL.String()
-				t.log.Info("Found and cached taxonomies", "url", taxonomyURL.String())
-				break
-			} else {
-				t.log.Debug("Response failed taxonomy validation", "url", taxonomyURL.String())
-			}
-		} else {
-			t.log.Debug("HTTP error from endpoint", "url", taxonomyURL.String(), "status", resp.StatusCode)
+	// Fall back to the taxonomies assigned to pages in the site index
+	if !found {
+		indexTaxonomies, indexInferred, indexURL, err := t.taxonomiesFromIndex(ctx, siteURL, candidates)
+		if err != nil {
+			t.log.DebugContext(ctx, "No taxonomies found in site index", "site", taxonomiesRequest.HugoSitePath, "error", err)
+		} else if len(indexTaxonomies) > 0 {
+			taxonomies = indexTaxonomies
+			inferred = indexInferred
+			found = true
+			usedEndpoint = indexURL
 		}
 	}
 
 	// If main endpoints failed, try individual taxonomy endpoints to discover what's available
 	if !found {
-		t.log.Debug("Main taxonomy endpoints failed, trying individual endpoints")
+		t.log.DebugContext(ctx, "Main taxonomy endpoints failed, trying individual endpoints")
 		discoveredTaxonomies := make(map[string]string)
 		
 		for _, endpoint := range individualTaxonomyEndpoints {
+			if err := ctx.Err(); err != nil {
+				t.log.WarnContext(ctx, "Taxonomies retrieval cancelled", "site", taxonomiesRequest.HugoSitePath, "error", err)
+				return nil, fmt.Errorf("taxonomies retrieval cancelled: %w", err)
+			}
+
 			taxonomyURL := siteURL.ResolveReference(&url.URL{Path: endpoint})
 			cacheKey := t.cache.BuildKey(siteURL.String(), endpoint, nil)
 			
-			// Check cache first
-			var responseData []byte
-			if cachedData, hit := t.cache.Get(cacheKey); hit {
-				responseData = cachedData
-				t.log.Debug("Cache hit for individual taxonomy", "url", taxonomyURL.String())
-			} else {
-				// Try fetching from network
-				resp, err := t.httpClient.Get(taxonomyURL.String())
-				if err != nil {
-					t.log.Debug("Failed to fetch individual taxonomy", "url", taxonomyURL.String(), "error", err)
-					continue
-				}
-				defer resp.Body.Close()
-				
-				if resp.StatusCode == http.StatusOK {
-					body, err := io.ReadAll(resp.Body)
-					if err != nil {
-						t.log.Debug("Failed to read individual taxonomy response", "url", taxonomyURL.String(), "error", err)
-						continue
-					}
-					
-					// Cache the response
-					etag := resp.Header.Get("ETag")
-					lastModified := resp.Header.Get("Last-Modified") 
-					t.cache.Set(cacheKey, body, etag, lastModified)
-					responseData = body
-				} else {
-					t.log.Debug("HTTP error from individual taxonomy", "url", taxonomyURL.String(), "status", resp.StatusCode)
-					continue
-				}
+			// Serve from cache, revalidating expired entries
+			result, err := t.cache.FetchEndpoint(ctx, t.httpClient, siteURL.String(), endpoint, cacheKey, taxonomyURL.String(), nil)
+			if err != nil {
+				t.log.DebugContext(ctx, "Individual taxonomy unavailable", "url", taxonomyURL.String(), "error", err)
+				continue
 			}
+			responseData := result.Data
 			
 			// Check if this looks like a valid taxonomy endpoint
 			if gjson.ValidBytes(responseData) {
@@ -232,7 +280,7 @@ func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
 					// Extract taxonomy name from endpoint path
 					taxonomyName := strings.TrimSuffix(strings.TrimPrefix(endpoint, "/"), "/index.json")
 					discoveredTaxonomies[taxonomyName] = taxonomyName
-					t.log.Debug("Discovered taxonomy", "name", taxonomyName, "url", taxonomyURL.String())
+					t.log.DebugContext(ctx, "Discovered taxonomy", "name", taxonomyName, "url", taxonomyURL.String())
 				}
 			}
 		}
@@ -243,17 +291,26 @@ func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
 			usedEndpoint = "individual_discovery"
 			// Create a simple taxonomies JSON from discovered ones
 			taxonomiesData = []byte(fmt.Sprintf(`{"taxonomies": %s}`, formatTaxonomiesMap(discoveredTaxonomies)))
-			t.log.Info("Successfully discovered taxonomies via individual endpoints", "count", len(discoveredTaxonomies))
+			t.log.InfoContext(ctx, "Successfully discovered taxonomies via individual endpoints", "count", len(discoveredTaxonomies))
 		}
 	}
 
 	if !found {
-		t.log.Error("No valid taxonomy data found", "site", taxonomiesRequest.HugoSitePath)
-		return nil, &ErrInvalidRequest{Err: fmt.Errorf("no valid taxonomy data found at Hugo site: %s", taxonomiesRequest.HugoSitePath)}
+		t.log.ErrorContext(ctx, "No valid taxonomy data found", "site", taxonomiesRequest.HugoSitePath)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "no valid taxonomy data found at Hugo site: %s", taxonomiesRequest.HugoSitePath)
 	}
 
-	// Parse taxonomies from validated JSON
-	taxonomies := extractTaxonomies(taxonomiesData)
+	// Parse taxonomies from validated JSON, unless they came from the index
+	if taxonomiesData != nil {
+		taxonomies = extractTaxonomies(taxonomiesData, candidates)
+	}
+	if inferred == nil {
+		inferred = []string{}
+	}
+	inferredJSON, err := json.Marshal(inferred)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal inferred taxonomies: %w", err)
+	}
 
 	// Format response with detailed error information
 	responseData := fmt.Sprintf(`{
@@ -262,13 +319,15 @@ func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
   "metadata": {
     "source_endpoint": "%s",
     "taxonomy_count": %d,
-    "cached": %s
+    "cached": %s,
+    "inferred_taxonomies": %s,
+    "site_source": "%s"
   },
   "errors": []
-}`, formatTaxonomies(taxonomies), usedEndpoint, len(taxonomies), "false")
+}`, formatTaxonomies(taxonomies), usedEndpoint, len(taxonomies), "false", inferredJSON, sites.Source(ctx))
 
-	t.log.Info("Successfully retrieved taxonomies", "count", len(taxonomies), "site", taxonomiesRequest.HugoSitePath, "endpoint", usedEndpoint)
-	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(responseData)), nil
+	t.log.InfoContext(ctx, "Successfully retrieved taxonomies", "count", len(taxonomies), "site", taxonomiesRequest.HugoSitePath, "endpoint", usedEndpoint)
+	return tools.TextResponse([]byte(responseData)), nil
 }
 
 // EndpointConfig represents an endpoint with its validation function
@@ -277,64 +336,68 @@ type EndpointConfig struct {
 	validator func([]byte) bool
 }
 
-// validateTaxonomyStructure checks if the JSON contains taxonomy-like data
-func validateTaxonomyStructure(data []byte) bool {
-	if !gjson.ValidBytes(data) {
-		return false
-	}
+// validateTaxonomyStructure returns a validator checking if the JSON
+// contains taxonomy-like data
+func validateTaxonomyStructure(candidates []string) func([]byte) bool {
+	return func(data []byte) bool {
+		if !gjson.ValidBytes(data) {
+			return false
+		}
 
-	parsed := gjson.ParseBytes(data)
-	
-	// Check for direct taxonomies object
-	if taxonomies := parsed.Get("taxonomies"); taxonomies.Exists() && taxonomies.IsObject() {
-		return taxonomies.Map() != nil && len(taxonomies.Map()) > 0
-	}
-	
-	// Check for common taxonomy keys
-	commonTaxonomies := []string{"categories", "tags", "series", "authors", "topics"}
-	foundTaxonomies := 0
-	
-	for _, tax := range commonTaxonomies {
-		if parsed.Get(tax).Exists() {
-			foundTaxonomies++
+		parsed := gjson.ParseBytes(data)
+
+		// Check for direct taxonomies object
+		if taxonomies := parsed.Get("taxonomies"); taxonomies.Exists() && taxonomies.IsObject() {
+			return taxonomies.Map() != nil && len(taxonomies.Map()) > 0
+		}
+
+		// Consider it valid if we found at least one candidate taxonomy
+		for _, tax := range candidates {
+			if parsed.Get(tax).Exists() {
+				return true
+			}
 		}
+		return false
 	}
-	
-	// Consider it valid if we found at least one common taxonomy
-	return foundTaxonomies > 0
 }
 
-// validateHugoIndex checks if the JSON is a Hugo index with potential taxonomy references
-func validateHugoIndex(data []byte) bool {
-	if !gjson.ValidBytes(data) {
-		return false
+// taxonomiesFromIndex lists the taxonomies assigned to pages in the site
+// index, returning them with those inferred from the fields of its pages
+// rather than known taxonomy fields, and the index URL
+func (t *Tool) taxonomiesFromIndex(ctx context.Context, siteURL *url.URL, candidates []string) (map[string]string, []string, string, error) {
+	index, err := hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		return nil, nil, "", err
 	}
 
-	parsed := gjson.ParseBytes(data)
-	
-	// Look for Hugo-specific structures that might contain taxonomies
-	// Check for pages array with taxonomy metadata
-	if pages := parsed.Get("pages"); pages.Exists() && pages.IsArray() {
-		// If we have pages, check if any contain taxonomy information
-		hasTaxonomyData := false
-		pages.ForEach(func(key, page gjson.Result) bool {
-			if page.Get("taxonomies").Exists() || 
-			   page.Get("categories").Exists() || 
-			   page.Get("tags").Exists() {
-				hasTaxonomyData = true
-				return false // Stop iteration
-			}
-			return true
-		})
-		return hasTaxonomyData
+	counts, err := index.Taxonomies(ctx)
+	if err != nil {
+		return nil, nil, "", err
 	}
-	
-	// Fall back to basic taxonomy validation
-	return validateTaxonomyStructure(data)
+	fields, err := index.InferTaxonomies(ctx, candidates)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	taxonomies := make(map[string]string, len(counts)+len(fields))
+	for taxonomy := range counts {
+		taxonomies[taxonomy] = taxonomy
+	}
+	var inferred []string
+	for field := range fields {
+		if _, ok := taxonomies[field]; !ok {
+			taxonomies[field] = field
+			inferred = append(inferred, field)
+		}
+	}
+	sort.Strings(inferred)
+
+	t.log.InfoContext(ctx, "Found taxonomies in site index", "url", index.URL(), "cached", index.Cached(), "streamed", index.Streamed(), "inferred", len(inferred))
+	return taxonomies, inferred, index.URL(), nil
 }
 
 // extractTaxonomies parses taxonomies from validated JSON data
-func extractTaxonomies(data []byte) map[string]string {
+func extractTaxonomies(data []byte, candidates []string) map[string]string {
 	taxonomies := make(map[string]string)
 	parsed := gjson.ParseBytes(data)
 
@@ -346,9 +409,8 @@ func extractTaxonomies(data []byte) map[string]string {
 			return true
 		})
 	} else {
-		// Look for common taxonomy keys in the root
-		commonTaxonomies := []string{"categories", "tags", "series", "authors", "topics", "types"}
-		for _, tax := range commonTaxonomies {
+		// Look for candidate taxonomy keys in the root
+		for _, tax := range candidates {
 			if result := parsed.Get(tax); result.Exists() {
 				taxonomies[tax] = tax
 			}
@@ -392,6 +454,11 @@ func (t *Tool) Description() string {
 	return t.description
 }
 
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*TaxonomiesRequest](registry, t, "Site structure analysis", tools.ReadOnly)
+}
+
 // formatTaxonomiesMap formats the discovered taxonomies map as a JSON string
 func formatTaxonomiesMap(taxonomies map[string]string) string {
 	if len(taxonomies) == 0 {
@@ -1,10 +1,17 @@
 package taxonomies
 
 import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 )
 
 func TestNew(t *testing.T) {
@@ -16,6 +23,21 @@ func TestNew(t *testing.T) {
 	assert.NotNil(t, tool.httpClient)
 }
 
+func TestWithCache_SharedWithLogger(t *testing.T) {
+	shared := cache.New()
+	logger := slog.New(slog.DiscardHandler)
+
+	// The shared cache is kept whichever order the options are given in
+	for _, opts := range [][]ToolOption{
+		{WithLogger(logger), WithCache(shared)},
+		{WithCache(shared), WithLogger(logger)},
+	} {
+		tool, err := New(opts...)
+		require.NoError(t, err)
+		assert.Same(t, shared, tool.cache)
+	}
+}
+
 func TestTaxonomiesRequest_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -127,43 +149,7 @@ func TestValidateTaxonomyStructure(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := validateTaxonomyStructure([]byte(tt.data))
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
-func TestValidateHugoIndex(t *testing.T) {
-	tests := []struct {
-		name     string
-		data     string
-		expected bool
-	}{
-		{
-			name:     "hugo index with pages containing taxonomies",
-			data:     `{"pages": [{"title": "Post 1", "categories": ["tech"]}, {"title": "Post 2", "tags": ["go"]}]}`,
-			expected: true,
-		},
-		{
-			name:     "hugo index without taxonomy data in pages",
-			data:     `{"pages": [{"title": "Post 1", "content": "Some content"}]}`,
-			expected: false,
-		},
-		{
-			name:     "fallback to direct taxonomies",
-			data:     `{"categories": ["tech", "go"], "tags": ["programming"]}`,
-			expected: true,
-		},
-		{
-			name:     "invalid JSON",
-			data:     `{invalid}`,
-			expected: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := validateHugoIndex([]byte(tt.data))
+			result := validateTaxonomyStructure(DefaultCandidates)([]byte(tt.data))
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -209,8 +195,92 @@ func TestExtractTaxonomies(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := extractTaxonomies([]byte(tt.data))
+			result := extractTaxonomies([]byte(tt.data), DefaultCandidates)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestTool_Execute_FromSiteIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[{"title": "Post 1", "tags": ["go"]}, {"title": "Post 2", "taxonomies": {"series": ["intro"]}}]`))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &TaxonomiesRequest{HugoSitePath: server.URL})
+	require.NoError(t, err)
+
+	text := resp.Content[0].TextContent.Text
+	assert.Equal(t, "tags", gjson.Get(text, "taxonomies.tags").String())
+	assert.Equal(t, "series", gjson.Get(text, "taxonomies.series").String())
+	assert.Equal(t, server.URL+"/index.json", gjson.Get(text, "metadata.source_endpoint").String())
+	assert.Equal(t, "hugo_site_path", gjson.Get(text, "metadata.site_source").String())
+
+	// Requests naming no site use the default
+	registry := sites.New()
+	require.NoError(t, registry.SetDefault(server.URL))
+	tool, err = New(WithSites(registry))
+	require.NoError(t, err)
+
+	resp, err = tool.Execute(context.Background(), &TaxonomiesRequest{})
+	require.NoError(t, err)
+	text = resp.Content[0].TextContent.Text
+	assert.Equal(t, "tags", gjson.Get(text, "taxonomies.tags").String())
+	assert.Equal(t, "default", gjson.Get(text, "metadata.site_source").String())
+}
+
+func TestTool_Execute_Candidates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/moods/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"taxonomies": [{"name": "calm"}]}`))
+	}))
+	defer server.Close()
+
+	// Sites without an index are probed at each candidate's endpoint
+	tool, err := New(WithCandidates([]string{"moods"}))
+	require.NoError(t, err)
+	resp, err := tool.Execute(context.Background(), &TaxonomiesRequest{HugoSitePath: server.URL})
+	require.NoError(t, err)
+	text := resp.Content[0].TextContent.Text
+	assert.Equal(t, "moods", gjson.Get(text, "taxonomies.moods").String())
+	assert.False(t, gjson.Get(text, "taxonomies.tags").Exists())
+
+	// Request candidates replace the tool's
+	_, err = tool.Execute(context.Background(), &TaxonomiesRequest{HugoSitePath: server.URL, Candidates: []string{"tags"}})
+	require.Error(t, err)
+
+	_, err = tool.Execute(context.Background(), &TaxonomiesRequest{HugoSitePath: server.URL, Candidates: []string{"../tags"}})
+	require.Error(t, err)
+}
+
+func TestTool_Execute_InferredFromSiteIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[{"title": "Post 1", "tags": ["go"], "moods": ["calm"], "aliases": ["/old/"]}, {"title": "Post 2", "themes": "dark"}]`))
+	}))
+	defer server.Close()
+
+	tool, err := New()
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &TaxonomiesRequest{HugoSitePath: server.URL})
+	require.NoError(t, err)
+
+	text := resp.Content[0].TextContent.Text
+	require.True(t, gjson.Valid(text))
+	assert.Equal(t, int64(3), gjson.Get(text, "metadata.taxonomy_count").Int())
+	assert.Equal(t, `["moods","themes"]`, gjson.Get(text, "metadata.inferred_taxonomies").Raw)
+	assert.False(t, gjson.Get(text, "taxonomies.aliases").Exists())
+}
@@ -1,17 +1,22 @@
 package terms
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
 	mcp_golang "github.com/metoro-io/mcp-golang"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
 	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
 	"github.com/tidwall/gjson"
 )
 
@@ -23,14 +28,22 @@ type Tool struct {
 	log        *slog.Logger
 	name       string
 	description string
-	httpClient *http.Client
+	httpClient *httpclient.Client
 	cache      *cache.Cache
+	ttl        time.Duration
+	sites      *sites.Registry
 }
 
 // TaxonomyTermsRequest represents the request parameters for the taxonomy terms tool.
 type TaxonomyTermsRequest struct {
-	HugoSitePath string `json:"hugo_site_path" jsonschema:"title=Hugo Site Path"`
+	HugoSitePath string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path"`
 	Taxonomy     string `json:"taxonomy" jsonschema:"title=Taxonomy Name"`
+	Tree         bool   `json:"tree,omitempty" jsonschema:"title=Also return slash-delimited terms such as food/dessert as a nested tree with per-node counts"`
+
+	httpclient.RetryOptions
+	cache.CacheOptions
+	httpclient.AuthOptions
+	sites.SiteOptions
 }
 
 // EndpointConfig represents an endpoint with its validation function
@@ -39,15 +52,20 @@ type EndpointConfig struct {
 	validator func([]byte, string) bool
 }
 
+// DefaultCacheTTL is how long taxonomy term listings are served from the
+// cache
+const DefaultCacheTTL = 5 * time.Minute
+
 // New creates a new Tool.
 func New(opts ...ToolOption) (*Tool, error) {
 	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_get_taxonomy_terms"),
 		name:        "hugo_reader_get_taxonomy_terms",
 		description: "Get all terms (values) for a specific taxonomy from a Hugo site. For example, get all 'categories' or 'tags' used on the site. Use after getting taxonomies to explore available terms.",
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		cache: cache.New(cache.WithTTL(5 * time.Minute)),
+		httpClient: httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache: cache.New(cache.WithTTL(DefaultCacheTTL)),
+		ttl:   DefaultCacheTTL,
+		sites: sites.New(),
 	}
 	for _, opt := range opts {
 		if err := opt(tool); err != nil {
@@ -62,9 +80,6 @@ func New(opts ...ToolOption) (*Tool, error) {
 func WithLogger(logger *slog.Logger) ToolOption {
 	return func(t *Tool) error {
 		t.log = logger.With("tool", t.name)
-		if t.cache != nil {
-			t.cache = cache.New(cache.WithLogger(logger), cache.WithTTL(5*time.Minute))
-		}
 		return nil
 	}
 }
@@ -77,38 +92,74 @@ func WithCache(c *cache.Cache) ToolOption {
 	}
 }
 
+// WithTTL sets how long the Tool serves cached responses. Zero uses the
+// cache's default TTL.
+func WithTTL(ttl time.Duration) ToolOption {
+	return func(t *Tool) error {
+		t.ttl = ttl
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
 // Validate implements tools.Request
 func (r *TaxonomyTermsRequest) Validate() error {
-	if r.HugoSitePath == "" {
-		return fmt.Errorf("hugo_site_path is required")
+	if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+		return err
 	}
 	if r.Taxonomy == "" {
 		return fmt.Errorf("taxonomy is required")
 	}
-	return nil
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	if err := r.CacheOptions.Validate(); err != nil {
+		return err
+	}
+	return r.AuthOptions.Validate()
 }
 
 // Execute retrieves terms for a specific taxonomy from a Hugo site.
-func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
-	// Check if logger is initialized
-	if t.log == nil {
-		t.log = slog.Default().With("tool", t.name)
-	}
-
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
 	termsRequest, ok := req.(*TaxonomyTermsRequest)
 	if !ok {
-		return nil, fmt.Errorf("invalid request type: %T", req)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL and credentials
+	ctx, err := termsRequest.SiteOptions.Apply(ctx, t.sites, &termsRequest.HugoSitePath, &termsRequest.AuthOptions)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
 	}
 
 	if err := termsRequest.Validate(); err != nil {
-		return nil, err
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
 	}
 
+	ctx, cancel := termsRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = termsRequest.CacheOptions.Apply(ctx, t.ttl)
+
 	// Parse and validate the Hugo site URL
 	siteURL, err := url.Parse(termsRequest.HugoSitePath)
 	if err != nil {
-		t.log.Error("Invalid Hugo site URL", "url", termsRequest.HugoSitePath, "error", err)
-		return nil, fmt.Errorf("invalid Hugo site URL: %w", err)
+		t.log.ErrorContext(ctx, "Invalid Hugo site URL", "url", termsRequest.HugoSitePath, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid Hugo site URL: %w", err)
 	}
 
 	// Ensure URL has scheme
@@ -116,96 +167,101 @@ func (t *Tool) Execute(req tools.Request) (*mcp_golang.ToolResponse, error) {
 		siteURL.Scheme = "https"
 	}
 
+	// Send credentials, if any, only to the site itself
+	ctx = termsRequest.AuthOptions.Apply(ctx, siteURL.Host)
+
 	// Try common Hugo taxonomy terms endpoints
 	taxonomyEndpoints := []EndpointConfig{
 		{path: fmt.Sprintf("/taxonomies/%s/index.json", termsRequest.Taxonomy), validator: validateTermsStructure},
 		{path: fmt.Sprintf("/%s/index.json", termsRequest.Taxonomy), validator: validateTermsStructure},
 		{path: fmt.Sprintf("/api/taxonomies/%s.json", termsRequest.Taxonomy), validator: validateTermsStructure},
-		{path: "/index.json", validator: validateHugoIndexForTerms},
 	}
 
 	var termsData []byte
+	var terms []string
+	var counts map[string]int
 	var found bool
 	var usedEndpoint string
 
 	for _, endpointConfig := range taxonomyEndpoints {
+		if err := ctx.Err(); err != nil {
+			t.log.WarnContext(ctx, "Taxonomy terms retrieval cancelled", "site", termsRequest.HugoSitePath, "error", err)
+			return nil, fmt.Errorf("taxonomy terms retrieval cancelled: %w", err)
+		}
+
 		taxonomyURL := siteURL.ResolveReference(&url.URL{Path: endpointConfig.path})
 		cacheKey := t.cache.BuildKey(siteURL.String(), endpointConfig.path, map[string]string{"taxonomy": termsRequest.Taxonomy})
 		
-		t.log.Debug("Trying taxonomy terms endpoint", "url", taxonomyURL.String(), "cache_key", cacheKey)
-
-		// Check cache first
-		if cachedData, hit := t.cache.Get(cacheKey); hit {
-			t.log.Debug("Cache hit for terms endpoint", "url", taxonomyURL.String())
-			if endpointConfig.validator(cachedData, termsRequest.Taxonomy) {
-				termsData = cachedData
-				found = true
-				usedEndpoint = taxonomyURL.String()
-				break
-			} else {
-				t.log.Debug("Cached terms data failed validation, invalidating", "url", taxonomyURL.String())
-				t.cache.Delete(cacheKey)
-			}
-		}
+		t.log.DebugContext(ctx, "Trying taxonomy terms endpoint", "url", taxonomyURL.String(), "cache_key", cacheKey)
 
-		// Fetch from network
-		resp, err := t.httpClient.Get(taxonomyURL.String())
+		// Serve from cache, revalidating expired entries
+		validator := func(data []byte) bool { return endpointConfig.validator(data, termsRequest.Taxonomy) }
+		result, err := t.cache.FetchEndpoint(ctx, t.httpClient, siteURL.String(), endpointConfig.path, cacheKey, taxonomyURL.String(), validator)
 		if err != nil {
-			t.log.Debug("Failed to fetch terms endpoint", "url", taxonomyURL.String(), "error", err)
+			t.log.DebugContext(ctx, "Terms endpoint unavailable", "url", taxonomyURL.String(), "error", err)
 			continue
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode == http.StatusOK {
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				t.log.Debug("Failed to read terms response body", "url", taxonomyURL.String(), "error", err)
-				continue
-			}
+		termsData = result.Data
+		found = true
+		usedEndpoint = taxonomyURL.String()
+		t.log.InfoContext(ctx, "Found taxonomy terms", "url", taxonomyURL.String(), "taxonomy", termsRequest.Taxonomy, "cached", result.Cached, "revalidated", result.Revalidated)
+		break
+	}
 
-			// Validate response contains taxonomy terms data
-			if endpointConfig.validator(body, termsRequest.Taxonomy) {
-				// Cache the validated response
-				etag := resp.Header.Get("ETag")
-				lastModified := resp.Header.Get("Last-Modified")
-				t.cache.Set(cacheKey, body, etag, lastModified)
-				
-				termsData = body
-				found = true
-				usedEndpoint = taxonomyURL.String()
-				t.log.Info("Found and cached taxonomy terms", "url", taxonomyURL.String(), "taxonomy", termsRequest.Taxonomy)
-				break
-			} else {
-				t.log.Debug("Response failed terms validation", "url", taxonomyURL.String(), "taxonomy", termsRequest.Taxonomy)
-			}
-		} else {
-			t.log.Debug("HTTP error from terms endpoint", "url", taxonomyURL.String(), "status", resp.StatusCode)
+	// Fall back to collecting the terms assigned to pages in the site index
+	if !found {
+		indexCounts, indexURL, err := t.termsFromIndex(ctx, siteURL, termsRequest.Taxonomy)
+		if err != nil {
+			t.log.DebugContext(ctx, "No terms found in site index", "site", termsRequest.HugoSitePath, "error", err)
+		} else if len(indexCounts) > 0 {
+			terms = sortedTerms(indexCounts)
+			counts = indexCounts
+			found = true
+			usedEndpoint = indexURL
 		}
 	}
 
 	if !found {
-		t.log.Error("No valid taxonomy terms data found", "site", termsRequest.HugoSitePath, "taxonomy", termsRequest.Taxonomy)
-		return nil, fmt.Errorf("no valid taxonomy terms data found for taxonomy '%s' at Hugo site: %s", termsRequest.Taxonomy, termsRequest.HugoSitePath)
+		t.log.ErrorContext(ctx, "No valid taxonomy terms data found", "site", termsRequest.HugoSitePath, "taxonomy", termsRequest.Taxonomy)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeNotFound, "no valid taxonomy terms data found for taxonomy '%s' at Hugo site: %s", termsRequest.Taxonomy, termsRequest.HugoSitePath)
+	}
+
+	// Extract terms from validated JSON, unless they came from the index
+	if termsData != nil {
+		terms = extractTerms(termsData, termsRequest.Taxonomy)
+		counts = extractCounts(termsData, termsRequest.Taxonomy)
 	}
 
-	// Extract terms from validated JSON
-	terms := extractTerms(termsData, termsRequest.Taxonomy)
+	// Nest slash-delimited terms on request
+	var treeField string
+	if termsRequest.Tree {
+		treeJSON, err := json.Marshal(buildTree(terms, counts))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal term tree: %w", err)
+		}
+		treeField = fmt.Sprintf(`
+  "tree": %s,`, treeJSON)
+	}
 
 	// Format response with detailed metadata
 	responseData := fmt.Sprintf(`{
   "success": true,
   "taxonomy": "%s",
-  "terms": %s,
+  "terms": %s,%s
   "metadata": {
     "source_endpoint": "%s",
     "term_count": %d,
-    "cached": %s
+    "hierarchical": %t,
+    "counts_available": %t,
+    "cached": %s,
+    "site_source": "%s"
   },
   "errors": []
-}`, termsRequest.Taxonomy, formatTerms(terms), usedEndpoint, len(terms), "false")
+}`, termsRequest.Taxonomy, formatTerms(terms), treeField, usedEndpoint, len(terms), isHierarchical(terms), counts != nil, "false", sites.Source(ctx))
 
-	t.log.Info("Successfully retrieved taxonomy terms", "count", len(terms), "site", termsRequest.HugoSitePath, "taxonomy", termsRequest.Taxonomy, "endpoint", usedEndpoint)
-	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(responseData)), nil
+	t.log.InfoContext(ctx, "Successfully retrieved taxonomy terms", "count", len(terms), "site", termsRequest.HugoSitePath, "taxonomy", termsRequest.Taxonomy, "endpoint", usedEndpoint)
+	return tools.TextResponse([]byte(responseData)), nil
 }
 
 // validateTermsStructure checks if the JSON contains valid taxonomy terms data
@@ -251,31 +307,6 @@ func validateTermsStructure(data []byte, taxonomy string) bool {
 	return false
 }
 
-// validateHugoIndexForTerms checks if the Hugo index contains terms for the specified taxonomy
-func validateHugoIndexForTerms(data []byte, taxonomy string) bool {
-	if !gjson.ValidBytes(data) {
-		return false
-	}
-
-	parsed := gjson.ParseBytes(data)
-	
-	// Look for pages with the specific taxonomy
-	if pages := parsed.Get("pages"); pages.Exists() && pages.IsArray() {
-		hasTermsData := false
-		pages.ForEach(func(key, page gjson.Result) bool {
-			if page.Get(taxonomy).Exists() {
-				hasTermsData = true
-				return false // Stop iteration
-			}
-			return true
-		})
-		return hasTermsData
-	}
-	
-	// Check if taxonomy exists in root
-	return parsed.Get(taxonomy).Exists()
-}
-
 // extractTerms parses terms from validated JSON data for a specific taxonomy
 func extractTerms(data []byte, taxonomy string) []string {
 	var terms []string
@@ -325,29 +356,40 @@ func extractTerms(data []byte, taxonomy string) []string {
 		})
 	} else if pages := parsed.Get("pages"); pages.Exists() && pages.IsArray() {
 		// Extract terms from pages
-		termMap := make(map[string]bool)
-		pages.ForEach(func(key, page gjson.Result) bool {
-			if pageTaxonomy := page.Get(taxonomy); pageTaxonomy.Exists() {
-				if pageTaxonomy.IsArray() {
-					pageTaxonomy.ForEach(func(k, term gjson.Result) bool {
-						termMap[term.String()] = true
-						return true
-					})
-				} else if pageTaxonomy.Type == gjson.String {
-					termMap[pageTaxonomy.String()] = true
-				}
-			}
-			return true
-		})
-		
-		for term := range termMap {
-			terms = append(terms, term)
-		}
+		counts, _ := hugoindex.New("", data).Terms(context.Background(), taxonomy)
+		terms = sortedTerms(counts)
 	}
 
 	return terms
 }
 
+// termsFromIndex counts the pages assigned each term of a taxonomy in the
+// site index, returning the counts with the index URL
+func (t *Tool) termsFromIndex(ctx context.Context, siteURL *url.URL, taxonomy string) (map[string]int, string, error) {
+	index, err := hugoindex.Load(ctx, t.cache, t.httpClient, siteURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	counts, err := index.Terms(ctx, taxonomy)
+	if err != nil {
+		return nil, "", err
+	}
+
+	t.log.InfoContext(ctx, "Found taxonomy terms in site index", "url", index.URL(), "taxonomy", taxonomy, "cached", index.Cached(), "streamed", index.Streamed())
+	return counts, index.URL(), nil
+}
+
+// sortedTerms returns the terms of a term count map in alphabetical order
+func sortedTerms(counts map[string]int) []string {
+	terms := make([]string, 0, len(counts))
+	for term := range counts {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	return terms
+}
+
 // formatTerms formats the terms slice as a JSON array string
 func formatTerms(terms []string) string {
 	if len(terms) == 0 {
@@ -372,6 +414,11 @@ func (t *Tool) Description() string {
 	return t.description
 }
 
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*TaxonomyTermsRequest](registry, t, "Content organization exploration", tools.ReadOnly)
+}
+
 // SetLogger sets the logger for the Tool.
 func (t *Tool) SetLogger(logger *slog.Logger) {
 	if logger == nil {
@@ -1,10 +1,19 @@
 package terms
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 )
 
 func TestNew(t *testing.T) {
@@ -16,6 +25,21 @@ func TestNew(t *testing.T) {
 	assert.NotNil(t, tool.httpClient)
 }
 
+func TestWithCache_SharedWithLogger(t *testing.T) {
+	shared := cache.New()
+	logger := slog.New(slog.DiscardHandler)
+
+	// The shared cache is kept whichever order the options are given in
+	for _, opts := range [][]ToolOption{
+		{WithLogger(logger), WithCache(shared)},
+		{WithCache(shared), WithLogger(logger)},
+	} {
+		tool, err := New(opts...)
+		require.NoError(t, err)
+		assert.Same(t, shared, tool.cache)
+	}
+}
+
 func TestTaxonomyTermsRequest_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -125,47 +149,6 @@ func TestValidateTermsStructure(t *testing.T) {
 	}
 }
 
-func TestValidateHugoIndexForTerms(t *testing.T) {
-	tests := []struct {
-		name     string
-		data     string
-		taxonomy string
-		expected bool
-	}{
-		{
-			name:     "hugo index with pages containing taxonomy",
-			data:     `{"pages": [{"title": "Post 1", "categories": ["tech"]}, {"title": "Post 2", "tags": ["go"]}]}`,
-			taxonomy: "categories",
-			expected: true,
-		},
-		{
-			name:     "hugo index without taxonomy in pages",
-			data:     `{"pages": [{"title": "Post 1", "content": "Some content"}]}`,
-			taxonomy: "categories",
-			expected: false,
-		},
-		{
-			name:     "taxonomy in root",
-			data:     `{"categories": ["tech", "go"], "tags": ["programming"]}`,
-			taxonomy: "categories",
-			expected: true,
-		},
-		{
-			name:     "invalid JSON",
-			data:     `{invalid}`,
-			taxonomy: "categories",
-			expected: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := validateHugoIndexForTerms([]byte(tt.data), tt.taxonomy)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 func TestExtractTerms(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -232,6 +215,35 @@ func TestExtractTerms(t *testing.T) {
 	}
 }
 
+func TestTool_Execute_StreamsLargeIndex(t *testing.T) {
+	var pages []string
+	for i := 0; i < 500; i++ {
+		pages = append(pages, fmt.Sprintf(`{"title": "Post %d", "tags": ["tag-%d"]}`, i, i%3))
+	}
+	index := `{"pages": [` + strings.Join(pages, ",") + `]}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(index))
+	}))
+	defer server.Close()
+
+	tool, err := New(WithLogger(slog.Default()), WithHTTPClient(httpclient.New(httpclient.WithMaxResponseSize(1024))))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &TaxonomyTermsRequest{HugoSitePath: server.URL, Taxonomy: "tags"})
+	require.NoError(t, err)
+
+	var terms []string
+	for _, term := range gjson.Get(resp.Content[0].TextContent.Text, "terms").Array() {
+		terms = append(terms, term.String())
+	}
+	assert.ElementsMatch(t, []string{"tag-0", "tag-1", "tag-2"}, terms)
+}
+
 func TestFormatTerms(t *testing.T) {
 	tests := []struct {
 		name     string
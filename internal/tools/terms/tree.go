@@ -0,0 +1,157 @@
+package terms
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/tidwall/gjson"
+)
+
+// TermSeparator separates the levels of hierarchical terms, as in
+// "food/dessert"
+const TermSeparator = "/"
+
+// TermNode is one level of a hierarchical taxonomy term
+type TermNode struct {
+	Name     string      `json:"name"`
+	Term     string      `json:"term"`
+	Count    int         `json:"count"`
+	Total    int         `json:"total"`
+	Children []*TermNode `json:"children,omitempty"`
+}
+
+// isHierarchical reports whether any term has more than one level
+func isHierarchical(terms []string) bool {
+	for _, term := range terms {
+		if len(termPath(term)) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// termPath splits a term into its levels, ignoring empty levels and space
+// around each
+func termPath(term string) []string {
+	var path []string
+	for _, level := range strings.Split(term, TermSeparator) {
+		if level = strings.TrimSpace(level); level != "" {
+			path = append(path, level)
+		}
+	}
+	return path
+}
+
+// buildTree arranges terms into a tree by their levels. Each node counts
+// the pages assigned its own term, from counts, and in total those of its
+// descendants too; levels that are not terms themselves count none of
+// their own. Nodes are sorted by name.
+func buildTree(terms []string, counts map[string]int) []*TermNode {
+	root := &TermNode{}
+	index := make(map[string]*TermNode)
+
+	for _, term := range terms {
+		path := termPath(term)
+		parent := root
+		for i, level := range path {
+			key := strings.Join(path[:i+1], TermSeparator)
+			node, ok := index[key]
+			if !ok {
+				node = &TermNode{Name: level, Term: key}
+				index[key] = node
+				parent.Children = append(parent.Children, node)
+			}
+			parent = node
+		}
+		if parent != root {
+			parent.Count += counts[term]
+		}
+	}
+
+	sortTree(root.Children)
+	return root.Children
+}
+
+// sortTree sorts nodes by name and sets their totals
+func sortTree(nodes []*TermNode) int {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	sum := 0
+	for _, node := range nodes {
+		node.Total = node.Count + sortTree(node.Children)
+		sum += node.Total
+	}
+	return sum
+}
+
+// extractCounts returns the page counts of the terms in taxonomy data that
+// reports them, or nil when it does not
+func extractCounts(data []byte, taxonomy string) map[string]int {
+	parsed := gjson.ParseBytes(data)
+	counts := make(map[string]int)
+
+	// Objects keyed by term hold a count, a list of pages, or an object
+	// with either
+	addObject := func(result gjson.Result) {
+		result.ForEach(func(key, value gjson.Result) bool {
+			if count, ok := termCount(value); ok {
+				counts[key.String()] = count
+			}
+			return true
+		})
+	}
+
+	if result := parsed.Get("terms"); result.IsObject() {
+		addObject(result)
+	} else if result := parsed.Get(taxonomy); result.Exists() {
+		if result.IsObject() {
+			addObject(result)
+		} else if result.IsArray() {
+			result.ForEach(func(_, value gjson.Result) bool {
+				name := value.Get("name")
+				if !name.Exists() {
+					name = value.Get("title")
+				}
+				if count, ok := termCount(value); ok && name.Exists() {
+					counts[name.String()] = count
+				}
+				return true
+			})
+		}
+	} else if taxonomies := parsed.Get("taxonomies"); taxonomies.IsArray() {
+		taxonomies.ForEach(func(_, value gjson.Result) bool {
+			if count := value.Get("count"); count.Exists() {
+				counts[value.Get("name").String()] = int(count.Int())
+			}
+			return true
+		})
+	} else if parsed.Get("pages").IsArray() {
+		counts, _ = hugoindex.New("", data).Terms(context.Background(), taxonomy)
+	}
+
+	if len(counts) == 0 {
+		return nil
+	}
+	return counts
+}
+
+// termCount returns the page count a term's value reports
+func termCount(value gjson.Result) (int, bool) {
+	switch {
+	case value.Type == gjson.Number:
+		return int(value.Int()), true
+	case value.IsArray():
+		return len(value.Array()), true
+	case value.IsObject():
+		if count := value.Get("count"); count.Exists() {
+			return int(count.Int()), true
+		}
+		if pages := value.Get("pages"); pages.IsArray() {
+			return len(pages.Array()), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,130 @@
+package terms
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestBuildTree(t *testing.T) {
+	terms := []string{"food/dessert", "food", "food/dessert/cake", "travel", "food/ main "}
+	counts := map[string]int{"food/dessert": 3, "food": 1, "food/dessert/cake": 2, "travel": 4, "food/ main ": 5}
+
+	tree := buildTree(terms, counts)
+	require.Len(t, tree, 2)
+
+	food := tree[0]
+	assert.Equal(t, "food", food.Name)
+	assert.Equal(t, 1, food.Count)
+	assert.Equal(t, 11, food.Total)
+	require.Len(t, food.Children, 2)
+	assert.Equal(t, "dessert", food.Children[0].Name)
+	assert.Equal(t, "food/dessert", food.Children[0].Term)
+	assert.Equal(t, 5, food.Children[0].Total)
+	assert.Equal(t, "food/dessert/cake", food.Children[0].Children[0].Term)
+	assert.Equal(t, "main", food.Children[1].Name)
+	assert.Equal(t, "food/main", food.Children[1].Term)
+
+	assert.Equal(t, "travel", tree[1].Name)
+	assert.Empty(t, tree[1].Children)
+
+	// Levels that are not terms count only their descendants
+	tree = buildTree([]string{"a/b", "a/c"}, nil)
+	require.Len(t, tree, 1)
+	assert.Equal(t, 0, tree[0].Count)
+	assert.Equal(t, 0, tree[0].Total)
+	assert.Len(t, tree[0].Children, 2)
+}
+
+func TestIsHierarchical(t *testing.T) {
+	assert.True(t, isHierarchical([]string{"go", "food/dessert"}))
+	assert.False(t, isHierarchical([]string{"go", "/rust/"}))
+	assert.False(t, isHierarchical(nil))
+}
+
+func TestExtractCounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		taxonomy string
+		expected map[string]int
+	}{
+		{
+			name:     "terms object of page lists",
+			data:     `{"terms": {"go": [{"title": "A"}, {"title": "B"}], "rust": 3}}`,
+			taxonomy: "tags",
+			expected: map[string]int{"go": 2, "rust": 3},
+		},
+		{
+			name:     "taxonomy array of objects",
+			data:     `{"tags": [{"name": "go", "count": 4}, {"title": "rust", "pages": [{}]}]}`,
+			taxonomy: "tags",
+			expected: map[string]int{"go": 4, "rust": 1},
+		},
+		{
+			name:     "hugo taxonomies array",
+			data:     `{"taxonomies": [{"name": "go", "count": 2, "url": "/tags/go/"}]}`,
+			taxonomy: "tags",
+			expected: map[string]int{"go": 2},
+		},
+		{
+			name:     "pages",
+			data:     `{"pages": [{"tags": ["go", "food/dessert"]}, {"tags": ["go"]}]}`,
+			taxonomy: "tags",
+			expected: map[string]int{"go": 2, "food/dessert": 1},
+		},
+		{
+			name:     "terms array without counts",
+			data:     `{"terms": ["go", "rust"]}`,
+			taxonomy: "tags",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, extractCounts([]byte(tt.data), tt.taxonomy))
+		})
+	}
+}
+
+func TestTool_Execute_Tree(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"pages": [
+			{"title": "Cake", "categories": ["food/dessert"]},
+			{"title": "Pie", "categories": ["food/dessert", "food"]},
+			{"title": "Trip", "categories": ["travel"]}
+		]}`))
+	}))
+	defer server.Close()
+
+	tool, err := New(WithLogger(slog.Default()))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &TaxonomyTermsRequest{HugoSitePath: server.URL, Taxonomy: "categories"})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.True(t, result.Get("metadata.hierarchical").Bool())
+	assert.False(t, result.Get("tree").Exists())
+
+	resp, err = tool.Execute(context.Background(), &TaxonomyTermsRequest{HugoSitePath: server.URL, Taxonomy: "categories", Tree: true})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	require.True(t, gjson.Valid(resp.Content[0].TextContent.Text))
+	assert.True(t, result.Get("metadata.counts_available").Bool())
+	assert.Equal(t, `["food","travel"]`, result.Get("tree.#.name").Raw)
+	assert.Equal(t, int64(1), result.Get("tree.0.count").Int())
+	assert.Equal(t, int64(3), result.Get("tree.0.total").Int())
+	assert.Equal(t, "food/dessert", result.Get("tree.0.children.0.term").String())
+	assert.Equal(t, int64(2), result.Get("tree.0.children.0.count").Int())
+}
@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"log/slog"
 
 	mcp_golang "github.com/metoro-io/mcp-golang"
@@ -13,8 +14,11 @@ type Request interface {
 
 // Tooler is the interface that all tools must implement
 type Tooler interface {
-	// Execute runs the tool with the given request and returns a response
-	Execute(request Request) (*mcp_golang.ToolResponse, error)
+	// Execute runs the tool with the given request and returns a response.
+	// Implementations must abandon outstanding work when ctx is cancelled.
+	// Errors the code can't be inferred from should carry one with the
+	// errors package; the server reports them in its ErrorResponse.
+	Execute(ctx context.Context, request Request) (*mcp_golang.ToolResponse, error)
 
 	// Name returns the name of the tool
 	Name() string
@@ -25,3 +29,38 @@ type Tooler interface {
 	// SetLogger sets the logger for the tool
 	SetLogger(logger *slog.Logger)
 }
+
+// MimeTypeJSON is the type of the documents tool results hold. Results are
+// sent as text content, which carries no MIME type, so it is only reported
+// by hugo_reader_info.
+const MimeTypeJSON = "application/json"
+
+// Annotations are hints about a tool's behavior, named as in the MCP
+// specification, for clients deciding whether a call needs confirmation.
+// mcp-golang v0.14.0 has no way to attach annotations to a registered tool,
+// so clients only see them in hugo_reader_info's tool list.
+type Annotations struct {
+	// ReadOnlyHint is true if the tool does not modify its environment
+	ReadOnlyHint bool `json:"readOnlyHint"`
+
+	// DestructiveHint is true if the tool may discard state, such as
+	// cached data
+	DestructiveHint bool `json:"destructiveHint"`
+
+	// IdempotentHint is true if repeating a call has no further effect
+	IdempotentHint bool `json:"idempotentHint"`
+
+	// OpenWorldHint is true if the tool reaches external sites
+	OpenWorldHint bool `json:"openWorldHint"`
+}
+
+// ReadOnly are the annotations of tools that only read sites
+var ReadOnly = Annotations{ReadOnlyHint: true, IdempotentHint: true, OpenWorldHint: true}
+
+// TextResponse returns a tool response holding a JSON result as plain
+// text content. mcp-golang v0.14.0 does not support structured content,
+// and encodes embedded resources, the only content with a MIME type, in a
+// form clients validating against the MCP schema reject.
+func TextResponse(data []byte) *mcp_golang.ToolResponse {
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(string(data)))
+}
@@ -0,0 +1,24 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextResponse(t *testing.T) {
+	data := []byte(`{"success": true}`)
+	resp := TextResponse(data)
+	require.Len(t, resp.Content, 1)
+	assert.Equal(t, mcp_golang.ContentTypeText, resp.Content[0].Type)
+	assert.Equal(t, string(data), resp.Content[0].TextContent.Text)
+}
+
+func TestAnnotations(t *testing.T) {
+	encoded, err := json.Marshal(ReadOnly)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"readOnlyHint": true, "destructiveHint": false, "idempotentHint": true, "openWorldHint": true}`, string(encoded))
+}
@@ -0,0 +1,480 @@
+package wayback
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	mcp_golang "github.com/metoro-io/mcp-golang"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/cache"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/htmltext"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/httpclient"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/hugoindex"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/sites"
+	"github.com/rmrfslashbin/mcp/hugo-reader/internal/tools"
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultArchiveURL is the Wayback Machine of the Internet Archive
+const DefaultArchiveURL = "https://web.archive.org"
+
+// Snapshot content formats
+const (
+	FormatMarkdown = "markdown"
+	FormatText     = "text"
+	FormatHTML     = "html"
+)
+
+// timestampLayout is the layout of Wayback timestamps, in UTC
+const timestampLayout = "20060102150405"
+
+// periodStart completes a timestamp prefix of a year or more to the first
+// moment of the period it names
+const periodStart = "0101000000"
+
+// cdxFields are the fields of each capture requested from the CDX API
+var cdxFields = []string{"timestamp", "original", "statuscode", "digest", "length"}
+
+// ToolOption is a function that configures a Tool.
+type ToolOption func(*Tool) error
+
+// Tool lists the snapshots the Wayback Machine holds of a page and reads
+// the content of one of them.
+type Tool struct {
+	log         *slog.Logger
+	name        string
+	description string
+	httpClient  *httpclient.Client
+	cache       *cache.Cache
+	sites       *sites.Registry
+	archiveURL  string
+}
+
+// PageHistoryRequest represents the request parameters for a page's history.
+type PageHistoryRequest struct {
+	HugoSitePath string `json:"hugo_site_path,omitempty" jsonschema:"title=Hugo Site Path (not needed when path is a full URL)"`
+	Path         string `json:"path" jsonschema:"title=Path of the page on the site or its full URL (e.g. /posts/my-post/)"`
+	From         string `json:"from,omitempty" jsonschema:"title=Only list snapshots taken from this date (YYYY, YYYY-MM, YYYY-MM-DD or a Wayback timestamp)"`
+	To           string `json:"to,omitempty" jsonschema:"title=Only list snapshots taken up to this date (YYYY, YYYY-MM, YYYY-MM-DD or a Wayback timestamp)"`
+	Limit        int    `json:"limit,omitempty" jsonschema:"title=Maximum snapshots listed, newest first (default: 50),minimum=1,maximum=500"`
+	Snapshot     string `json:"snapshot,omitempty" jsonschema:"title=Timestamp of a snapshot whose content to return instead of the list; the nearest snapshot is read"`
+	Format       string `json:"format,omitempty" jsonschema:"title=Snapshot Content Format,enum=markdown,enum=text,enum=html"`
+	MaxLength    int    `json:"max_length,omitempty" jsonschema:"title=Maximum snapshot content length in characters; longer content is truncated,minimum=100,maximum=1000000"`
+
+	httpclient.RetryOptions
+	cache.CacheOptions
+	sites.SiteOptions
+
+	from, to, snapshot string
+}
+
+// New creates a new Tool.
+func New(opts ...ToolOption) (*Tool, error) {
+	tool := &Tool{
+		log:         slog.Default().With("tool", "hugo_reader_page_history"),
+		name:        "hugo_reader_page_history",
+		description: "List the snapshots of a page the Internet Archive's Wayback Machine has captured, newest first, optionally between two dates. Identical consecutive captures are listed once. Given the timestamp of a snapshot, returns that version's title and content instead, as Markdown, text or HTML. Use it to find out what a post said in the past, or when it changed. Pages are looked up on web.archive.org, so only public pages have history.",
+		httpClient:  httpclient.New(httpclient.WithTimeout(30 * time.Second)),
+		cache:       cache.New(),
+		sites:       sites.New(),
+		archiveURL:  DefaultArchiveURL,
+	}
+	for _, opt := range opts {
+		if err := opt(tool); err != nil {
+			return nil, err
+		}
+	}
+
+	return tool, nil
+}
+
+// WithLogger sets the logger for the Tool.
+func WithLogger(logger *slog.Logger) ToolOption {
+	return func(t *Tool) error {
+		t.log = logger.With("tool", t.name)
+		return nil
+	}
+}
+
+// WithCache sets the cache for the Tool.
+func WithCache(c *cache.Cache) ToolOption {
+	return func(t *Tool) error {
+		t.cache = c
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the Tool.
+func WithHTTPClient(c *httpclient.Client) ToolOption {
+	return func(t *Tool) error {
+		t.httpClient = c
+		return nil
+	}
+}
+
+// WithSites sets the registry site aliases are resolved against.
+func WithSites(r *sites.Registry) ToolOption {
+	return func(t *Tool) error {
+		t.sites = r
+		return nil
+	}
+}
+
+// WithArchiveURL sets the URL of the Wayback Machine the Tool queries.
+func WithArchiveURL(rawURL string) ToolOption {
+	return func(t *Tool) error {
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid archive URL: %s", rawURL)
+		}
+		t.archiveURL = strings.TrimSuffix(u.String(), "/")
+		return nil
+	}
+}
+
+// Validate implements tools.Request
+func (r *PageHistoryRequest) Validate() error {
+	r.Path = strings.TrimSpace(r.Path)
+	if r.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if !isAbsolute(r.Path) {
+		if err := sites.ValidateSitePath(&r.HugoSitePath); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if r.from, err = timestamp(r.From); err != nil {
+		return fmt.Errorf("invalid from: %w", err)
+	}
+	if r.to, err = timestamp(r.To); err != nil {
+		return fmt.Errorf("invalid to: %w", err)
+	}
+	if r.snapshot, err = timestamp(r.Snapshot); err != nil {
+		return fmt.Errorf("invalid snapshot: %w", err)
+	}
+
+	if r.Limit == 0 {
+		r.Limit = 50
+	} else if r.Limit < 1 || r.Limit > 500 {
+		return fmt.Errorf("limit must be between 1 and 500")
+	}
+
+	switch r.Format {
+	case "":
+		r.Format = FormatMarkdown
+	case FormatMarkdown, FormatText, FormatHTML:
+	default:
+		return fmt.Errorf("invalid format: %s (must be: markdown, text, or html)", r.Format)
+	}
+	if r.MaxLength != 0 && (r.MaxLength < 100 || r.MaxLength > 1000000) {
+		return fmt.Errorf("max_length must be between 100 and 1000000")
+	}
+
+	if err := r.RetryOptions.Validate(); err != nil {
+		return err
+	}
+	return r.CacheOptions.Validate()
+}
+
+// timestamp returns a date as the Wayback timestamp prefix it names: the
+// digits of YYYY, YYYY-MM, YYYY-MM-DD, or a timestamp of up to 14 digits
+func timestamp(value string) (string, error) {
+	digits := strings.ReplaceAll(strings.TrimSpace(value), "-", "")
+	if digits == "" {
+		return "", nil
+	}
+	if len(digits) < 4 || len(digits) > len(timestampLayout) || len(digits)%2 != 0 || strings.Trim(digits, "0123456789") != "" {
+		return "", fmt.Errorf("%q is not a date (YYYY, YYYY-MM, YYYY-MM-DD) or Wayback timestamp", value)
+	}
+	return digits, nil
+}
+
+// isAbsolute reports whether a page path is a full URL
+func isAbsolute(path string) bool {
+	u, err := url.Parse(path)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// Execute lists the snapshots of the page, or reads one of them.
+func (t *Tool) Execute(ctx context.Context, req tools.Request) (*mcp_golang.ToolResponse, error) {
+	historyRequest, ok := req.(*PageHistoryRequest)
+	if !ok {
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidRequest, "invalid request type: %T", req)
+	}
+
+	// Resolve a registered site alias into its URL. Credentials are not
+	// used: only the archive is requested, and it holds public pages alone.
+	var auth httpclient.AuthOptions
+	ctx, err := historyRequest.SiteOptions.Apply(ctx, t.sites, &historyRequest.HugoSitePath, &auth)
+	if err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	if err := historyRequest.Validate(); err != nil {
+		return nil, toolerrors.Wrap(toolerrors.ErrCodeInvalidRequest, err)
+	}
+
+	ctx, cancel := historyRequest.RetryOptions.Apply(ctx)
+	defer cancel()
+	ctx = historyRequest.CacheOptions.Apply(ctx, 0)
+
+	pageURL, err := t.pageURL(historyRequest)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Invalid page URL", "path", historyRequest.Path, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.ErrCodeInvalidURL, "invalid page URL: %w", err)
+	}
+
+	var response map[string]interface{}
+	if historyRequest.snapshot != "" {
+		response, err = t.readSnapshot(ctx, historyRequest, pageURL)
+	} else {
+		response, err = t.listSnapshots(ctx, historyRequest, pageURL)
+	}
+	if err != nil {
+		t.log.ErrorContext(ctx, "Wayback Machine request failed", "url", pageURL, "error", err)
+		return nil, toolerrors.Errorf(toolerrors.Code(err), "page history unavailable: %w", err)
+	}
+	response["success"] = true
+	response["url"] = pageURL
+	if historyRequest.HugoSitePath != "" {
+		response["site_source"] = sites.Source(ctx)
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		t.log.ErrorContext(ctx, "Failed to marshal page history", "error", err)
+		return nil, fmt.Errorf("failed to marshal page history: %w", err)
+	}
+
+	t.log.InfoContext(ctx, "Page history retrieved", "url", pageURL, "snapshot", historyRequest.snapshot)
+	return tools.TextResponse(responseJSON), nil
+}
+
+// pageURL returns the canonical URL of the requested page, resolving paths
+// against the site's URL
+func (t *Tool) pageURL(req *PageHistoryRequest) (string, error) {
+	var base *url.URL
+	if !isAbsolute(req.Path) {
+		siteURL, err := url.Parse(req.HugoSitePath)
+		if err != nil {
+			return "", err
+		}
+		if siteURL.Scheme == "" {
+			siteURL.Scheme = "https"
+		}
+		base = siteURL
+	}
+	pageURL := hugoindex.CanonicalURL(base, req.Path)
+	if !isAbsolute(pageURL) {
+		return "", fmt.Errorf("%s is not a page URL", req.Path)
+	}
+	return pageURL, nil
+}
+
+// snapshot is a capture of a page listed by the CDX API
+type snapshot struct {
+	Timestamp  string `json:"timestamp"`
+	CapturedAt string `json:"captured_at,omitempty"`
+	URL        string `json:"url"`
+	ArchiveURL string `json:"archive_url"`
+	Status     int    `json:"status,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+	Length     int    `json:"length,omitempty"`
+}
+
+// listSnapshots queries the CDX API for the newest successful captures of
+// the page, collapsing consecutive captures of identical content
+func (t *Tool) listSnapshots(ctx context.Context, req *PageHistoryRequest, pageURL string) (map[string]interface{}, error) {
+	params := url.Values{}
+	params.Set("url", pageURL)
+	params.Set("output", "json")
+	params.Set("fl", strings.Join(cdxFields, ","))
+	params.Set("filter", "statuscode:200")
+	params.Set("collapse", "digest")
+	// A negative limit returns the last captures, which are the newest
+	params.Set("limit", strconv.Itoa(-req.Limit))
+	if req.from != "" {
+		params.Set("from", req.from)
+	}
+	if req.to != "" {
+		params.Set("to", req.to)
+	}
+
+	cdxURL := t.archiveURL + "/cdx/search/cdx?" + params.Encode()
+	result, err := t.cache.Fetch(ctx, t.httpClient, t.cache.BuildKey(t.archiveURL, "/cdx/search/cdx?"+params.Encode(), nil), cdxURL, validCDX)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := t.parseCDX(result.Data)
+	return map[string]interface{}{
+		"snapshots": snapshots,
+		"count":     len(snapshots),
+		"limited":   len(snapshots) >= req.Limit,
+		"cached":    result.Cached,
+	}, nil
+}
+
+// parseCDX returns the captures of a CDX JSON response, newest first. Its
+// first row names the fields of the rows following it.
+func (t *Tool) parseCDX(data []byte) []snapshot {
+	rows := gjson.ParseBytes(data).Array()
+	snapshots := []snapshot{}
+	if len(rows) < 2 {
+		return snapshots
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0].Array() {
+		columns[name.String()] = i
+	}
+	field := func(row []gjson.Result, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i].String()
+		}
+		return ""
+	}
+
+	for i := len(rows) - 1; i >= 1; i-- {
+		row := rows[i].Array()
+		s := snapshot{Timestamp: field(row, "timestamp"), URL: field(row, "original"), Digest: field(row, "digest")}
+		if s.Timestamp == "" || s.URL == "" {
+			continue
+		}
+		if captured, err := time.Parse(timestampLayout, s.Timestamp); err == nil {
+			s.CapturedAt = captured.Format(time.RFC3339)
+		}
+		s.Status, _ = strconv.Atoi(field(row, "statuscode"))
+		s.Length, _ = strconv.Atoi(field(row, "length"))
+		s.ArchiveURL = t.archiveURL + "/web/" + s.Timestamp + "/" + s.URL
+		snapshots = append(snapshots, s)
+	}
+	return snapshots
+}
+
+// readSnapshot reads the snapshot of the page nearest the requested
+// timestamp, as the page was captured rather than with the archive's
+// banner and rewritten links. The archive serves the nearest capture for
+// any timestamp, so the capture is first looked up through the CDX API,
+// and the timestamp it was taken at is the one read and reported.
+func (t *Tool) readSnapshot(ctx context.Context, req *PageHistoryRequest, pageURL string) (map[string]interface{}, error) {
+	captured, err := t.closestSnapshot(ctx, req.snapshot, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := "/web/" + captured.Timestamp + "id_/" + pageURL
+	result, err := t.cache.Fetch(ctx, t.httpClient, t.cache.BuildKey(t.archiveURL, endpoint, nil), t.archiveURL+endpoint, validHTML)
+	if err != nil {
+		return nil, err
+	}
+
+	page := htmltext.ParsePage(string(result.Data))
+	content := page.Content
+	switch req.Format {
+	case FormatMarkdown:
+		content = htmltext.Markdown(content)
+	case FormatText:
+		content = htmltext.Text(content)
+	}
+	truncated := false
+	if runes := []rune(content); req.MaxLength > 0 && len(runes) > req.MaxLength {
+		content = string(runes[:req.MaxLength])
+		truncated = true
+	}
+
+	snap := map[string]interface{}{
+		"timestamp":   captured.Timestamp,
+		"requested":   req.snapshot,
+		"captured_at": captured.CapturedAt,
+		"archive_url": t.archiveURL + "/web/" + captured.Timestamp + "/" + pageURL,
+		"title":       page.Title,
+		"format":      req.Format,
+		"content":     content,
+		"truncated":   truncated,
+	}
+	for name, value := range map[string]string{
+		"summary": page.Description,
+		"date":    page.Date,
+		"lastmod": page.Lastmod,
+	} {
+		if value != "" {
+			snap[name] = value
+		}
+	}
+	return map[string]interface{}{
+		"snapshot": snap,
+		"cached":   result.Cached,
+	}, nil
+}
+
+// closestSnapshot looks up the successful capture of the page nearest a
+// timestamp prefix, which stands for the start of the period it names
+func (t *Tool) closestSnapshot(ctx context.Context, prefix, pageURL string) (*snapshot, error) {
+	params := url.Values{}
+	params.Set("url", pageURL)
+	params.Set("output", "json")
+	params.Set("fl", strings.Join(cdxFields, ","))
+	params.Set("filter", "statuscode:200")
+	params.Set("closest", prefix+periodStart[len(prefix)-4:])
+	params.Set("sort", "closest")
+	params.Set("limit", "1")
+
+	cdxURL := t.archiveURL + "/cdx/search/cdx?" + params.Encode()
+	result, err := t.cache.Fetch(ctx, t.httpClient, t.cache.BuildKey(t.archiveURL, "/cdx/search/cdx?"+params.Encode(), nil), cdxURL, validCDX)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := t.parseCDX(result.Data)
+	if len(snapshots) == 0 {
+		return nil, &cache.StatusError{StatusCode: http.StatusNotFound}
+	}
+	return &snapshots[0], nil
+}
+
+// validCDX accepts a JSON array of rows, or the empty body the CDX API
+// answers with when it has no captures
+func validCDX(data []byte) bool {
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return true
+	}
+	return gjson.ValidBytes(data) && gjson.ParseBytes(data).IsArray()
+}
+
+func validHTML(data []byte) bool {
+	return htmltext.LooksLikeHTML(string(data))
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.name
+}
+
+// Description returns the description of the tool.
+func (t *Tool) Description() string {
+	return t.description
+}
+
+// Register adds the Tool to registry.
+func (t *Tool) Register(registry *tools.Registry) {
+	tools.Add[*PageHistoryRequest](registry, t, "Content retrieval", tools.ReadOnly)
+}
+
+// SetLogger sets the logger for the Tool.
+func (t *Tool) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		t.log = slog.Default().With("tool", t.name)
+		t.log.Warn("nil logger provided, using default")
+		return
+	}
+	t.log = logger.With("tool", t.name)
+}
@@ -0,0 +1,186 @@
+package wayback
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	toolerrors "github.com/rmrfslashbin/mcp/hugo-reader/internal/tools/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// archiveServer serves a CDX API answering with cdx, and snapshots whose
+// content is snapshot
+func archiveServer(t *testing.T, cdx, snapshot string) (*httptest.Server, *[]*http.Request) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		switch {
+		case r.URL.Path == "/cdx/search/cdx":
+			w.Write([]byte(cdx))
+		case snapshot != "" && len(r.URL.Path) > len("/web/"):
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(snapshot))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestNew(t *testing.T) {
+	tool, err := New()
+	require.NoError(t, err)
+	assert.Equal(t, "hugo_reader_page_history", tool.Name())
+	assert.NotEmpty(t, tool.Description())
+
+	_, err = New(WithArchiveURL("web.archive.org"))
+	assert.Error(t, err)
+}
+
+func TestPageHistoryRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *PageHistoryRequest
+		wantErr bool
+	}{
+		{name: "path on site", req: &PageHistoryRequest{HugoSitePath: "https://example.com", Path: "/posts/a/"}},
+		{name: "full URL without site", req: &PageHistoryRequest{Path: "https://example.com/posts/a/"}},
+		{name: "missing path", req: &PageHistoryRequest{HugoSitePath: "https://example.com"}, wantErr: true},
+		{name: "path without site", req: &PageHistoryRequest{Path: "/posts/a/"}, wantErr: true},
+		{name: "dates", req: &PageHistoryRequest{Path: "https://example.com/a/", From: "2023-01", To: "2024-06-30"}},
+		{name: "invalid date", req: &PageHistoryRequest{Path: "https://example.com/a/", From: "last year"}, wantErr: true},
+		{name: "snapshot", req: &PageHistoryRequest{Path: "https://example.com/a/", Snapshot: "20230115093000"}},
+		{name: "snapshot too long", req: &PageHistoryRequest{Path: "https://example.com/a/", Snapshot: "2023011509300000"}, wantErr: true},
+		{name: "invalid format", req: &PageHistoryRequest{Path: "https://example.com/a/", Format: "pdf"}, wantErr: true},
+		{name: "limit too high", req: &PageHistoryRequest{Path: "https://example.com/a/", Limit: 1000}, wantErr: true},
+		{name: "max_length too low", req: &PageHistoryRequest{Path: "https://example.com/a/", MaxLength: 10}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	for value, want := range map[string]string{
+		"":               "",
+		"2023":           "2023",
+		"2023-05":        "202305",
+		"2023-05-09":     "20230509",
+		"20230509120000": "20230509120000",
+	} {
+		got, err := timestamp(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+	}
+
+	for _, value := range []string{"23", "20235", "2023-05-09T12:00", "yesterday"} {
+		_, err := timestamp(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestTool_Execute_List(t *testing.T) {
+	server, requests := archiveServer(t, `[
+		["timestamp","original","statuscode","digest","length"],
+		["20220301080000","https://example.com/posts/a/","200","AAA","1200"],
+		["20230415120000","https://example.com/posts/a/","200","BBB","1350"]
+	]`, "")
+	tool, err := New(WithArchiveURL(server.URL))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &PageHistoryRequest{HugoSitePath: "https://example.com", Path: "posts/a", From: "2022", Limit: 10})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+
+	assert.True(t, result.Get("success").Bool())
+	assert.Equal(t, "https://example.com/posts/a/", result.Get("url").String())
+	assert.Equal(t, int64(2), result.Get("count").Int())
+	assert.False(t, result.Get("limited").Bool())
+	assert.Equal(t, "20230415120000", result.Get("snapshots.0.timestamp").String(), "newest first")
+	assert.Equal(t, "2023-04-15T12:00:00Z", result.Get("snapshots.0.captured_at").String())
+	assert.Equal(t, server.URL+"/web/20230415120000/https://example.com/posts/a/", result.Get("snapshots.0.archive_url").String())
+	assert.Equal(t, int64(1350), result.Get("snapshots.0.length").Int())
+	assert.Equal(t, "20220301080000", result.Get("snapshots.1.timestamp").String())
+
+	require.Len(t, *requests, 1)
+	query := (*requests)[0].URL.Query()
+	assert.Equal(t, "https://example.com/posts/a/", query.Get("url"))
+	assert.Equal(t, "-10", query.Get("limit"))
+	assert.Equal(t, "2022", query.Get("from"))
+	assert.Equal(t, "digest", query.Get("collapse"))
+}
+
+func TestTool_Execute_NoSnapshots(t *testing.T) {
+	server, _ := archiveServer(t, "", "")
+	tool, err := New(WithArchiveURL(server.URL))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &PageHistoryRequest{Path: "https://example.com/new/"})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Equal(t, int64(0), result.Get("count").Int())
+	assert.True(t, result.Get("snapshots").IsArray())
+}
+
+func TestTool_Execute_Snapshot(t *testing.T) {
+	// The capture nearest the requested month was taken ten days into it
+	server, requests := archiveServer(t, `[
+		["timestamp","original","statuscode","digest","length"],
+		["20220310101500","https://example.com/posts/a/","200","AAA","1200"]
+	]`, `<html><head><title>Hello</title>
+		<meta property="article:published_time" content="2022-02-01"></head>
+		<body><nav>Menu</nav><main><h1>Hello</h1><p>The <strong>old</strong> text.</p></main></body></html>`)
+	tool, err := New(WithArchiveURL(server.URL))
+	require.NoError(t, err)
+
+	resp, err := tool.Execute(context.Background(), &PageHistoryRequest{Path: "https://example.com/posts/a/", Snapshot: "2022-03"})
+	require.NoError(t, err)
+	result := gjson.Parse(resp.Content[0].TextContent.Text)
+
+	assert.Equal(t, "20220310101500", result.Get("snapshot.timestamp").String())
+	assert.Equal(t, "202203", result.Get("snapshot.requested").String())
+	assert.Equal(t, "2022-03-10T10:15:00Z", result.Get("snapshot.captured_at").String())
+	assert.Equal(t, server.URL+"/web/20220310101500/https://example.com/posts/a/", result.Get("snapshot.archive_url").String())
+	assert.Equal(t, "Hello", result.Get("snapshot.title").String())
+	assert.Equal(t, "2022-02-01", result.Get("snapshot.date").String())
+	assert.Contains(t, result.Get("snapshot.content").String(), "The **old** text.")
+	assert.NotContains(t, result.Get("snapshot.content").String(), "Menu")
+	assert.False(t, result.Get("snapshot.truncated").Bool())
+	require.Len(t, *requests, 2)
+	query := (*requests)[0].URL.Query()
+	assert.Equal(t, "20220301000000", query.Get("closest"))
+	assert.Equal(t, "closest", query.Get("sort"))
+	assert.Equal(t, "1", query.Get("limit"))
+	assert.Equal(t, "/web/20220310101500id_/https://example.com/posts/a/", (*requests)[1].URL.Path)
+
+	resp, err = tool.Execute(context.Background(), &PageHistoryRequest{Path: "https://example.com/posts/a/", Snapshot: "2022-03", Format: FormatText, MaxLength: 100})
+	require.NoError(t, err)
+	result = gjson.Parse(resp.Content[0].TextContent.Text)
+	assert.Contains(t, result.Get("snapshot.content").String(), "The old text.")
+}
+
+func TestTool_Execute_Errors(t *testing.T) {
+	server, _ := archiveServer(t, "", "")
+	tool, err := New(WithArchiveURL(server.URL))
+	require.NoError(t, err)
+
+	_, err = tool.Execute(context.Background(), &PageHistoryRequest{Path: "/posts/a/"})
+	require.Error(t, err)
+	assert.Equal(t, toolerrors.ErrCodeInvalidRequest, toolerrors.Code(err))
+
+	_, err = tool.Execute(context.Background(), &PageHistoryRequest{Path: "https://example.com/posts/a/", Snapshot: "2022"})
+	require.Error(t, err)
+	assert.Equal(t, toolerrors.ErrCodeNotFound, toolerrors.Code(err))
+}
@@ -0,0 +1,36 @@
+package tracing
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// FromConfig creates a Tracer exporting to the viper setting otlp_endpoint,
+// falling back to the standard OTEL_EXPORTER_OTLP_ENDPOINT and
+// OTEL_SERVICE_NAME environment variables. It returns nil, which records
+// nothing, when no endpoint is configured. Explicit options are applied
+// last and take precedence over configuration.
+func FromConfig(opts ...Option) (*Tracer, error) {
+	endpoint := viper.GetString("otlp_endpoint")
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	if endpoint == "" {
+		return nil, nil
+	}
+
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil || (endpointURL.Scheme != "http" && endpointURL.Scheme != "https") || endpointURL.Host == "" {
+		return nil, fmt.Errorf("invalid otlp_endpoint: %s", endpoint)
+	}
+
+	var configOpts []Option
+	if serviceName := os.Getenv("OTEL_SERVICE_NAME"); serviceName != "" {
+		configOpts = append(configOpts, WithServiceName(serviceName))
+	}
+
+	return New(endpoint, append(configOpts, opts...)...), nil
+}
@@ -0,0 +1,196 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// TracesPath is where an OTLP/HTTP collector accepts spans
+const TracesPath = "/v1/traces"
+
+// scopeName identifies the instrumentation that recorded the spans
+const scopeName = "github.com/rmrfslashbin/mcp/hugo-reader"
+
+// OTLP status codes
+const (
+	statusUnset = 0
+	statusError = 2
+)
+
+// The OTLP/HTTP JSON encoding of an export request
+type (
+	exportRequest struct {
+		ResourceSpans []resourceSpans `json:"resourceSpans"`
+	}
+
+	resourceSpans struct {
+		Resource   resource     `json:"resource"`
+		ScopeSpans []scopeSpans `json:"scopeSpans"`
+	}
+
+	resource struct {
+		Attributes []keyValue `json:"attributes"`
+	}
+
+	scopeSpans struct {
+		Scope scope      `json:"scope"`
+		Spans []spanData `json:"spans"`
+	}
+
+	scope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+
+	spanData struct {
+		TraceID           string     `json:"traceId"`
+		SpanID            string     `json:"spanId"`
+		ParentSpanID      string     `json:"parentSpanId,omitempty"`
+		Name              string     `json:"name"`
+		Kind              int        `json:"kind"`
+		StartTimeUnixNano string     `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+		Attributes        []keyValue `json:"attributes,omitempty"`
+		Status            status     `json:"status"`
+	}
+
+	status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+
+	keyValue struct {
+		Key   string   `json:"key"`
+		Value anyValue `json:"value"`
+	}
+
+	anyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// export sends the queued spans to the collector. Failed exports are
+// logged and the spans dropped.
+func (t *Tracer) export() {
+	t.mu.Lock()
+	spans := t.queue
+	dropped := t.dropped
+	t.queue = nil
+	t.dropped = 0
+	t.mu.Unlock()
+
+	if dropped > 0 {
+		t.log.Warn("Dropped spans, the export queue was full", "spans", dropped)
+	}
+	if len(spans) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(t.encode(spans))
+	if err != nil {
+		t.log.Warn("Failed to encode spans", "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	if err := t.post(ctx, body); err != nil {
+		t.log.WarnContext(ctx, "Failed to export spans", "spans", len(spans), "error", err)
+	}
+}
+
+// post sends an encoded export request to the collector
+func (t *Tracer) post(ctx context.Context, body []byte) error {
+	url := strings.TrimRight(t.endpoint, "/") + TracesPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// encode builds the export request for spans
+func (t *Tracer) encode(spans []*Span) exportRequest {
+	attrs := []keyValue{encodeAttribute(String("service.name", t.serviceName))}
+	if t.version != "" {
+		attrs = append(attrs, encodeAttribute(String("service.version", t.version)))
+	}
+
+	data := make([]spanData, 0, len(spans))
+	for _, span := range spans {
+		data = append(data, span.encode())
+	}
+
+	return exportRequest{ResourceSpans: []resourceSpans{{
+		Resource:   resource{Attributes: attrs},
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: scopeName, Version: t.version}, Spans: data}},
+	}}}
+}
+
+// encode converts an ended span to its OTLP form
+func (s *Span) encode() spanData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := spanData{
+		TraceID:           hex.EncodeToString(s.traceID[:]),
+		SpanID:            hex.EncodeToString(s.spanID[:]),
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		Status:            status{Code: statusUnset},
+	}
+	if s.parentID != [8]byte{} {
+		data.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	if s.failed {
+		data.Status = status{Code: statusError, Message: s.err}
+	}
+	for _, attr := range s.attrs {
+		data.Attributes = append(data.Attributes, encodeAttribute(attr))
+	}
+	return data
+}
+
+// encodeAttribute converts an attribute to its OTLP form
+func encodeAttribute(attr Attribute) keyValue {
+	kv := keyValue{Key: attr.Key}
+	switch v := attr.Value.(type) {
+	case string:
+		kv.Value.StringValue = &v
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		kv.Value.IntValue = &s
+	case bool:
+		kv.Value.BoolValue = &v
+	case float64:
+		kv.Value.DoubleValue = &v
+	default:
+		s := fmt.Sprint(v)
+		kv.Value.StringValue = &s
+	}
+	return kv
+}
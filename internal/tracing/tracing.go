@@ -0,0 +1,287 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultServiceName is the service.name reported with every span
+const DefaultServiceName = "hugo-reader"
+
+// Span kinds, as numbered by OTLP
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+// Export defaults
+const (
+	defaultBatchInterval = 5 * time.Second
+	defaultBatchSize     = 512
+	maxQueuedSpans       = 2048
+	exportTimeout        = 10 * time.Second
+)
+
+// Option configures a Tracer
+type Option func(*Tracer)
+
+// Tracer records spans and exports them in batches to an OTLP/HTTP
+// collector. A nil *Tracer records nothing.
+type Tracer struct {
+	log           *slog.Logger
+	endpoint      string
+	serviceName   string
+	version       string
+	httpClient    *http.Client
+	batchInterval time.Duration
+	batchSize     int
+
+	mu      sync.Mutex
+	queue   []*Span
+	dropped int
+
+	flush chan struct{}
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// New creates a Tracer exporting to the OTLP/HTTP collector at endpoint,
+// such as http://localhost:4318, and starts exporting in the background
+func New(endpoint string, opts ...Option) *Tracer {
+	t := &Tracer{
+		log:           slog.Default(),
+		endpoint:      endpoint,
+		serviceName:   DefaultServiceName,
+		httpClient:    &http.Client{Timeout: exportTimeout},
+		batchInterval: defaultBatchInterval,
+		batchSize:     defaultBatchSize,
+		flush:         make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	go t.run()
+	return t
+}
+
+// WithLogger sets the logger export failures are reported to
+func WithLogger(logger *slog.Logger) Option {
+	return func(t *Tracer) {
+		t.log = logger
+	}
+}
+
+// WithServiceName sets the service.name reported with every span
+func WithServiceName(name string) Option {
+	return func(t *Tracer) {
+		t.serviceName = name
+	}
+}
+
+// WithServiceVersion sets the service.version reported with every span
+func WithServiceVersion(version string) Option {
+	return func(t *Tracer) {
+		t.version = version
+	}
+}
+
+// WithHTTPClient sets the client spans are exported with. It should not be
+// the client being traced.
+func WithHTTPClient(client *http.Client) Option {
+	return func(t *Tracer) {
+		t.httpClient = client
+	}
+}
+
+// WithBatchInterval sets how often queued spans are exported
+func WithBatchInterval(interval time.Duration) Option {
+	return func(t *Tracer) {
+		t.batchInterval = interval
+	}
+}
+
+// Attribute is a key/value pair describing a span
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// String returns a string attribute
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int returns an integer attribute
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+// Bool returns a boolean attribute
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is one timed operation within a trace. The methods of a nil *Span
+// do nothing, so callers need not check whether tracing is enabled.
+type Span struct {
+	tracer   *Tracer
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	kind     int
+	start    time.Time
+	end      time.Time
+
+	mu     sync.Mutex
+	attrs  []Attribute
+	err    string
+	failed bool
+	ended  bool
+}
+
+type spanKey struct{}
+
+// FromContext returns the span carried by ctx, or nil
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Start starts a span of the given kind, a child of the span carried by ctx
+// if there is one, and returns a context carrying it
+func (t *Tracer) Start(ctx context.Context, name string, kind int, attrs ...Attribute) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	span := &Span{tracer: t, name: name, kind: kind, start: time.Now(), attrs: attrs}
+	if parent := FromContext(ctx); parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else {
+		rand.Read(span.traceID[:])
+	}
+	rand.Read(span.spanID[:])
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Start starts a child of the span carried by ctx. When ctx carries no
+// span, tracing is disabled for the call and the returned span is nil.
+func Start(ctx context.Context, name string, kind int, attrs ...Attribute) (context.Context, *Span) {
+	parent := FromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	return parent.tracer.Start(ctx, name, kind, attrs...)
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// RecordError marks the span failed with err, if err is not nil
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = true
+	s.err = err.Error()
+}
+
+// TraceID returns the span's trace ID in hex, or "" for a nil span
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.traceID[:])
+}
+
+// End ends the span and queues it for export. Only the first call counts.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	s.tracer.enqueue(s)
+}
+
+// enqueue queues an ended span, dropping it if the queue is full
+func (t *Tracer) enqueue(span *Span) {
+	t.mu.Lock()
+	if len(t.queue) >= maxQueuedSpans {
+		t.dropped++
+		t.mu.Unlock()
+		return
+	}
+	t.queue = append(t.queue, span)
+	full := len(t.queue) >= t.batchSize
+	t.mu.Unlock()
+
+	if full {
+		select {
+		case t.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run exports queued spans every batch interval, whenever a batch fills,
+// and once more when the Tracer is shut down
+func (t *Tracer) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(t.batchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-t.flush:
+		case <-t.stop:
+			t.export()
+			return
+		}
+		t.export()
+	}
+}
+
+// Shutdown exports the spans still queued and stops the Tracer, waiting
+// until ctx is done at the latest
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	t.once.Do(func() { close(t.stop) })
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
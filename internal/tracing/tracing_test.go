@@ -0,0 +1,120 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collector records the export requests it receives
+type collector struct {
+	mu       sync.Mutex
+	requests []exportRequest
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req exportRequest
+	if r.URL.Path != TracesPath || r.Header.Get("Content-Type") != "application/json" || json.NewDecoder(r.Body).Decode(&req) != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	c.mu.Unlock()
+}
+
+func (c *collector) spans() []spanData {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var spans []spanData
+	for _, req := range c.requests {
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+	}
+	return spans
+}
+
+func TestTracer_Export(t *testing.T) {
+	c := &collector{}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	tracer := New(server.URL, WithServiceVersion("abc123"), WithBatchInterval(time.Hour))
+
+	ctx, root := tracer.Start(context.Background(), "tools/call hugo_reader_search", KindServer, String("gen_ai.tool.name", "hugo_reader_search"))
+	_, child := Start(ctx, "HTTP GET", KindClient, String("url.full", "https://example.com/index.json"))
+	child.SetAttributes(Int("http.response.status_code", 404), Bool("hugo_reader.cache.hit", false))
+	child.RecordError(errors.New("HTTP 404"))
+	child.End()
+	child.End()
+	root.End()
+
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	require.Len(t, c.requests, 1)
+	resource := c.requests[0].ResourceSpans[0].Resource
+	assert.Equal(t, "service.name", resource.Attributes[0].Key)
+	assert.Equal(t, DefaultServiceName, *resource.Attributes[0].Value.StringValue)
+	assert.Equal(t, "abc123", *resource.Attributes[1].Value.StringValue)
+
+	spans := c.spans()
+	require.Len(t, spans, 2)
+	http, tool := spans[0], spans[1]
+
+	assert.Equal(t, "tools/call hugo_reader_search", tool.Name)
+	assert.Equal(t, KindServer, tool.Kind)
+	assert.Empty(t, tool.ParentSpanID)
+	assert.Equal(t, statusUnset, tool.Status.Code)
+	assert.Len(t, tool.TraceID, 32)
+	assert.Equal(t, root.TraceID(), tool.TraceID)
+
+	assert.Equal(t, "HTTP GET", http.Name)
+	assert.Equal(t, tool.TraceID, http.TraceID)
+	assert.Equal(t, tool.SpanID, http.ParentSpanID)
+	assert.Equal(t, status{Code: statusError, Message: "HTTP 404"}, http.Status)
+	require.Len(t, http.Attributes, 3)
+	assert.Equal(t, "404", *http.Attributes[1].Value.IntValue)
+	assert.False(t, *http.Attributes[2].Value.BoolValue)
+}
+
+func TestTracer_Disabled(t *testing.T) {
+	var tracer *Tracer
+
+	ctx, span := tracer.Start(context.Background(), "tools/call hugo_reader_info", KindServer)
+	assert.Nil(t, span)
+	assert.Nil(t, FromContext(ctx))
+
+	_, child := Start(ctx, "HTTP GET", KindClient)
+	assert.Nil(t, child)
+	child.SetAttributes(Int("http.response.status_code", 200))
+	child.RecordError(errors.New("failed"))
+	child.End()
+	assert.Empty(t, child.TraceID())
+	assert.NoError(t, tracer.Shutdown(context.Background()))
+}
+
+func TestTracer_ExportFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tracer := New(server.URL, WithBatchInterval(time.Hour))
+	_, span := tracer.Start(context.Background(), "tools/call hugo_reader_info", KindServer)
+	span.End()
+
+	// Failures are logged, not returned, and the spans dropped
+	require.NoError(t, tracer.Shutdown(context.Background()))
+	assert.Empty(t, tracer.queue)
+}